@@ -0,0 +1,412 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+var (
+	_ afero.Fs   = &ManagerFs{}
+	_ afero.File = &managerFile{}
+)
+
+// ManagerFs adapts a Manager to afero.Fs so tools expecting a writable filesystem write
+// through to the configured provider. Files are buffered in memory and uploaded on Close or
+// Sync. Directories are virtual: they exist whenever an object lives under their prefix.
+// Permission, ownership and timestamp changes are accepted but ignored.
+type ManagerFs struct {
+	manager *Manager
+	ctx     context.Context
+	reader  *UploaderFS
+}
+
+// NewManagerFs returns an afero.Fs backed by manager.
+func NewManagerFs(manager *Manager) *ManagerFs {
+	return &ManagerFs{
+		manager: manager,
+		ctx:     context.Background(),
+		reader:  NewUploaderFS(manager),
+	}
+}
+
+// WithContext sets the context used for provider calls issued through the filesystem.
+func (a *ManagerFs) WithContext(ctx context.Context) *ManagerFs {
+	if ctx != nil {
+		a.ctx = ctx
+		a.reader.WithContext(ctx)
+	}
+	return a
+}
+
+func (a *ManagerFs) Name() string {
+	return "ManagerFs"
+}
+
+func (a *ManagerFs) Create(name string) (afero.File, error) {
+	return a.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+}
+
+func (a *ManagerFs) Mkdir(string, os.FileMode) error {
+	return nil
+}
+
+func (a *ManagerFs) MkdirAll(string, os.FileMode) error {
+	return nil
+}
+
+func (a *ManagerFs) Open(name string) (afero.File, error) {
+	return a.OpenFile(name, os.O_RDONLY, 0)
+}
+
+func (a *ManagerFs) OpenFile(name string, flag int, _ os.FileMode) (afero.File, error) {
+	key := aferoKey(name)
+	if key == "." {
+		return a.openDir(name, key)
+	}
+
+	writable := flag&(os.O_WRONLY|os.O_RDWR|os.O_APPEND|os.O_CREATE|os.O_TRUNC) != 0
+	file := &managerFile{
+		fs:       a,
+		name:     name,
+		key:      key,
+		writable: writable,
+	}
+
+	if flag&os.O_TRUNC != 0 {
+		file.dirty = true
+		return file, nil
+	}
+
+	content, err := a.manager.GetFile(a.ctx, key)
+	switch {
+	case err == nil:
+		if flag&os.O_CREATE != 0 && flag&os.O_EXCL != 0 {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrExist}
+		}
+		file.data = content
+	case errors.Is(toFSError(err), fs.ErrNotExist) && flag&os.O_CREATE != 0:
+		file.dirty = true
+	default:
+		if !writable {
+			if dir, dirErr := a.openDir(name, key); dirErr == nil {
+				return dir, nil
+			}
+		}
+		return nil, &fs.PathError{Op: "open", Path: name, Err: toFSError(err)}
+	}
+
+	if flag&os.O_APPEND != 0 {
+		file.offset = int64(len(file.data))
+	}
+
+	return file, nil
+}
+
+func (a *ManagerFs) Remove(name string) error {
+	if err := a.manager.DeleteFile(a.ctx, aferoKey(name)); err != nil {
+		return &fs.PathError{Op: "remove", Path: name, Err: toFSError(err)}
+	}
+	return nil
+}
+
+func (a *ManagerFs) RemoveAll(name string) error {
+	key := aferoKey(name)
+	prefix := ""
+	if key != "." {
+		prefix = key
+	}
+
+	objects, err := a.manager.List(a.ctx, prefix)
+	if err != nil {
+		return &fs.PathError{Op: "removeall", Path: name, Err: err}
+	}
+
+	for _, obj := range objects {
+		if prefix != "" && obj.Key != key && !strings.HasPrefix(obj.Key, key+"/") {
+			continue
+		}
+		if err := a.manager.DeleteFile(a.ctx, obj.Key); err != nil && !errors.Is(toFSError(err), fs.ErrNotExist) {
+			return &fs.PathError{Op: "removeall", Path: obj.Key, Err: err}
+		}
+	}
+
+	return nil
+}
+
+func (a *ManagerFs) Rename(oldname, newname string) error {
+	oldKey, newKey := aferoKey(oldname), aferoKey(newname)
+
+	content, err := a.manager.GetFile(a.ctx, oldKey)
+	if err != nil {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: toFSError(err)}
+	}
+
+	if _, err := a.manager.UploadFile(a.ctx, newKey, content, WithContentType(detectContentType(newKey, content))); err != nil {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: err}
+	}
+
+	if err := a.manager.DeleteFile(a.ctx, oldKey); err != nil {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: err}
+	}
+
+	return nil
+}
+
+func (a *ManagerFs) Stat(name string) (os.FileInfo, error) {
+	return a.reader.Stat(aferoKey(name))
+}
+
+func (a *ManagerFs) Chmod(string, os.FileMode) error {
+	return nil
+}
+
+func (a *ManagerFs) Chown(string, int, int) error {
+	return nil
+}
+
+func (a *ManagerFs) Chtimes(string, time.Time, time.Time) error {
+	return nil
+}
+
+func (a *ManagerFs) openDir(name, key string) (afero.File, error) {
+	entries, err := a.reader.ReadDir(key)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]os.FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+
+	return &managerFile{
+		fs:      a,
+		name:    name,
+		key:     key,
+		dir:     true,
+		entries: infos,
+	}, nil
+}
+
+type managerFile struct {
+	fs       *ManagerFs
+	name     string
+	key      string
+	data     []byte
+	offset   int64
+	writable bool
+	dirty    bool
+	closed   bool
+	dir      bool
+	entries  []os.FileInfo
+	dirPos   int
+}
+
+func (f *managerFile) Name() string {
+	return f.name
+}
+
+func (f *managerFile) Read(p []byte) (int, error) {
+	n, err := f.ReadAt(p, f.offset)
+	f.offset += int64(n)
+	return n, err
+}
+
+func (f *managerFile) ReadAt(p []byte, off int64) (int, error) {
+	if err := f.check("read"); err != nil {
+		return 0, err
+	}
+	if f.dir {
+		return 0, &fs.PathError{Op: "read", Path: f.name, Err: fs.ErrInvalid}
+	}
+	if off >= int64(len(f.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, f.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (f *managerFile) Seek(offset int64, whence int) (int64, error) {
+	if err := f.check("seek"); err != nil {
+		return 0, err
+	}
+
+	var next int64
+	switch whence {
+	case io.SeekStart:
+		next = offset
+	case io.SeekCurrent:
+		next = f.offset + offset
+	case io.SeekEnd:
+		next = int64(len(f.data)) + offset
+	default:
+		return 0, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrInvalid}
+	}
+
+	if next < 0 {
+		return 0, &fs.PathError{Op: "seek", Path: f.name, Err: fs.ErrInvalid}
+	}
+
+	f.offset = next
+	return next, nil
+}
+
+func (f *managerFile) Write(p []byte) (int, error) {
+	n, err := f.WriteAt(p, f.offset)
+	f.offset += int64(n)
+	return n, err
+}
+
+func (f *managerFile) WriteAt(p []byte, off int64) (int, error) {
+	if err := f.check("write"); err != nil {
+		return 0, err
+	}
+	if !f.writable || f.dir {
+		return 0, &fs.PathError{Op: "write", Path: f.name, Err: fs.ErrPermission}
+	}
+
+	end := off + int64(len(p))
+	if end > int64(len(f.data)) {
+		grown := make([]byte, end)
+		copy(grown, f.data)
+		f.data = grown
+	}
+	copy(f.data[off:], p)
+	f.dirty = true
+	return len(p), nil
+}
+
+func (f *managerFile) WriteString(s string) (int, error) {
+	return f.Write([]byte(s))
+}
+
+func (f *managerFile) Truncate(size int64) error {
+	if err := f.check("truncate"); err != nil {
+		return err
+	}
+	if !f.writable || size < 0 {
+		return &fs.PathError{Op: "truncate", Path: f.name, Err: fs.ErrInvalid}
+	}
+
+	if size <= int64(len(f.data)) {
+		f.data = f.data[:size]
+	} else {
+		grown := make([]byte, size)
+		copy(grown, f.data)
+		f.data = grown
+	}
+	f.dirty = true
+	return nil
+}
+
+func (f *managerFile) Readdir(count int) ([]os.FileInfo, error) {
+	if !f.dir {
+		return nil, &fs.PathError{Op: "readdir", Path: f.name, Err: fs.ErrInvalid}
+	}
+
+	remaining := f.entries[f.dirPos:]
+	if count <= 0 {
+		f.dirPos = len(f.entries)
+		return remaining, nil
+	}
+
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+
+	if count > len(remaining) {
+		count = len(remaining)
+	}
+	f.dirPos += count
+	return remaining[:count], nil
+}
+
+func (f *managerFile) Readdirnames(n int) ([]string, error) {
+	infos, err := f.Readdir(n)
+	names := make([]string, 0, len(infos))
+	for _, info := range infos {
+		names = append(names, info.Name())
+	}
+	return names, err
+}
+
+func (f *managerFile) Stat() (os.FileInfo, error) {
+	if f.dir {
+		return uploaderFileInfo{name: path.Base(f.key), dir: true}, nil
+	}
+	return uploaderFileInfo{
+		name:    path.Base(f.key),
+		size:    int64(len(f.data)),
+		modTime: time.Now(),
+	}, nil
+}
+
+func (f *managerFile) Sync() error {
+	if err := f.check("sync"); err != nil {
+		return err
+	}
+	return f.flush()
+}
+
+func (f *managerFile) Close() error {
+	if f.closed {
+		return &fs.PathError{Op: "close", Path: f.name, Err: fs.ErrClosed}
+	}
+	err := f.flush()
+	f.closed = true
+	return err
+}
+
+func (f *managerFile) flush() error {
+	if !f.dirty || !f.writable {
+		return nil
+	}
+
+	if _, err := f.fs.manager.UploadFile(f.fs.ctx, f.key, f.data, WithContentType(detectContentType(f.key, f.data))); err != nil {
+		return &fs.PathError{Op: "write", Path: f.name, Err: err}
+	}
+
+	f.dirty = false
+	return nil
+}
+
+func (f *managerFile) check(op string) error {
+	if f.closed {
+		return &fs.PathError{Op: op, Path: f.name, Err: fs.ErrClosed}
+	}
+	return nil
+}
+
+func aferoKey(name string) string {
+	key := path.Clean("/" + filepath.ToSlash(name))
+	key = strings.TrimPrefix(key, "/")
+	if key == "" {
+		return "."
+	}
+	return key
+}
+
+func detectContentType(key string, content []byte) string {
+	if byExt := mime.TypeByExtension(path.Ext(key)); byExt != "" {
+		return byExt
+	}
+	return http.DetectContentType(content)
+}
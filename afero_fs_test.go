@@ -0,0 +1,121 @@
+package uploader
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"sort"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func newTestManagerFs(t *testing.T) *ManagerFs {
+	t.Helper()
+	manager := NewManager()
+	WithProvider(NewFSProvider(t.TempDir()))(manager)
+	return NewManagerFs(manager)
+}
+
+func TestManagerFsWriteReadRoundTrip(t *testing.T) {
+	afs := newTestManagerFs(t)
+
+	if err := afs.MkdirAll("/site/css", 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+
+	if err := afero.WriteFile(afs, "/site/index.html", []byte("<h1>hi</h1>"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if err := afero.WriteFile(afs, "/site/css/app.css", []byte("body{}"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	data, err := afero.ReadFile(afs, "/site/index.html")
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(data) != "<h1>hi</h1>" {
+		t.Fatalf("unexpected content: %s", data)
+	}
+
+	f, err := afs.OpenFile("/site/index.html", os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatalf("OpenFile append: %v", err)
+	}
+	if _, err := f.WriteString("<p>more</p>"); err != nil {
+		t.Fatalf("WriteString: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	data, _ = afero.ReadFile(afs, "/site/index.html")
+	if string(data) != "<h1>hi</h1><p>more</p>" {
+		t.Fatalf("unexpected appended content: %s", data)
+	}
+
+	var walked []string
+	err = afero.Walk(afs, "/site", func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			walked = append(walked, p)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk: %v", err)
+	}
+	sort.Strings(walked)
+	if len(walked) != 2 || walked[0] != "/site/css/app.css" || walked[1] != "/site/index.html" {
+		t.Fatalf("unexpected walk result: %v", walked)
+	}
+}
+
+func TestManagerFsRenameAndRemoveAll(t *testing.T) {
+	afs := newTestManagerFs(t)
+
+	if err := afero.WriteFile(afs, "backup/a.txt", []byte("a"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := afero.WriteFile(afs, "backup/nested/b.txt", []byte("b"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if err := afs.Rename("backup/a.txt", "backup/renamed.txt"); err != nil {
+		t.Fatalf("Rename: %v", err)
+	}
+
+	if _, err := afs.Stat("backup/a.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected old name to be gone, got %v", err)
+	}
+
+	if err := afs.RemoveAll("backup"); err != nil {
+		t.Fatalf("RemoveAll: %v", err)
+	}
+
+	if exists, _ := afero.Exists(afs, "backup/nested/b.txt"); exists {
+		t.Fatalf("expected nested file to be removed")
+	}
+}
+
+func TestManagerFsReadOnlyHandleRejectsWrites(t *testing.T) {
+	afs := newTestManagerFs(t)
+
+	if err := afero.WriteFile(afs, "file.txt", []byte("data"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	f, err := afs.Open("file.txt")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write([]byte("x")); !errors.Is(err, fs.ErrPermission) {
+		t.Fatalf("expected permission error writing read-only handle, got %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package uploader
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	gerrors "github.com/goliatone/go-errors"
+)
+
+var _ AppendOnlyProvider = &FSProvider{}
+
+// AppendOnlyProvider is implemented by providers that can add data to the end of an object, for
+// logs and exports built up incrementally. AppendFile creates missing objects and returns the
+// object size after the append.
+type AppendOnlyProvider interface {
+	AppendFile(ctx context.Context, path string, chunk []byte) (int64, error)
+}
+
+// AppendFile adds chunk to the end of the object stored under key, creating it when missing, and
+// returns the new object size. Providers without AppendOnlyProvider fail with ErrNotImplemented.
+// Appends bypass transactions: a rolled back transaction does not undo them.
+func (m *Manager) AppendFile(ctx context.Context, key string, chunk []byte) (int64, error) {
+	if err := m.ensureWritable(); err != nil {
+		return 0, err
+	}
+	if err := m.validateKey(key); err != nil {
+		return 0, err
+	}
+	if len(chunk) == 0 {
+		return 0, gerrors.NewValidation("append failed", gerrors.FieldError{
+			Field:   "chunk",
+			Message: "chunk is empty",
+		})
+	}
+	if err := m.ensureProvider(ctx); err != nil {
+		return 0, err
+	}
+
+	appender, ok := m.providerFor(ctx, key).(AppendOnlyProvider)
+	if !ok {
+		return 0, ErrNotImplemented
+	}
+
+	size, err := observeCall(ctx, m, "append", key, func() (int64, error) {
+		return appender.AppendFile(ctx, key, chunk)
+	})
+	if err != nil {
+		return 0, err
+	}
+	m.recordTransfer("append", int64(len(chunk)), 0)
+	m.usageCache.forget(key)
+	return size, nil
+}
+
+// AppendFile opens path with O_APPEND and writes chunk in a single write, so appends from
+// concurrent writers on the same host do not interleave. Missing files are created public-read,
+// like uploads without a visibility.
+func (p *FSProvider) AppendFile(_ context.Context, path string, chunk []byte) (int64, error) {
+	fullPath := filepath.Join(p.base, filepath.Clean(path))
+	p.etags.forget(filepath.Clean(path))
+
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return 0, fmt.Errorf("%w: %w", ErrPermissionDenied, err)
+	}
+
+	file, err := os.OpenFile(fullPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, fileMode(""))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrPermissionDenied, err)
+	}
+
+	if _, err := file.Write(chunk); err != nil {
+		_ = file.Close()
+		return 0, fmt.Errorf("fs provider: append: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return 0, fmt.Errorf("fs provider: append: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		return 0, fmt.Errorf("fs provider: append: %w", err)
+	}
+	return info.Size(), nil
+}
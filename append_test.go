@@ -0,0 +1,111 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	gerrors "github.com/goliatone/go-errors"
+)
+
+func TestManagerAppendFile(t *testing.T) {
+	ctx := context.Background()
+	manager := NewManager(WithProvider(NewFSProvider(t.TempDir())))
+
+	for i, line := range []string{"first\n", "second\n"} {
+		size, err := manager.AppendFile(ctx, "logs/audit.log", []byte(line))
+		if err != nil {
+			t.Fatalf("AppendFile %d: %v", i, err)
+		}
+		if want := []int64{6, 13}[i]; size != want {
+			t.Fatalf("expected size %d after append %d, got %d", want, i, size)
+		}
+	}
+
+	content, err := manager.GetFile(ctx, "logs/audit.log")
+	if err != nil || string(content) != "first\nsecond\n" {
+		t.Fatalf("unexpected log content %q, %v", content, err)
+	}
+
+	if _, err := manager.AppendFile(ctx, "logs/audit.log", nil); !gerrors.IsValidation(err) {
+		t.Fatalf("expected a validation error for an empty chunk, got %v", err)
+	}
+
+	manager = NewManager(WithProvider(newMemoryProvider()))
+	if _, err := manager.AppendFile(ctx, "logs/audit.log", []byte("x")); !errors.Is(err, ErrNotImplemented) {
+		t.Fatalf("expected ErrNotImplemented, got %v", err)
+	}
+}
+
+func TestAWSProviderAppendFile(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("missing object", func(t *testing.T) {
+		client := &fakeS3Client{headErr: &types.NotFound{}}
+		provider := &AWSProvider{client: client, bucket: "test-bucket", logger: &DefaultLogger{}}
+
+		size, err := provider.AppendFile(ctx, "audit.log", []byte("line\n"))
+		if err != nil || size != 5 {
+			t.Fatalf("expected the object to be created, got %d, %v", size, err)
+		}
+		if len(client.putInputs) != 1 || aws.ToString(client.putInputs[0].IfNoneMatch) != "*" {
+			t.Fatalf("expected a conditional create, got %#v", client.putInputs)
+		}
+	})
+
+	t.Run("small object", func(t *testing.T) {
+		client := &fakeS3Client{headOutput: &s3.HeadObjectOutput{ContentLength: aws.Int64(4), ETag: aws.String(`"v1"`), ContentType: aws.String("text/plain")}}
+		provider := &AWSProvider{client: client, bucket: "test-bucket", logger: &DefaultLogger{}}
+
+		size, err := provider.AppendFile(ctx, "audit.log", []byte("+more"))
+		if err != nil || size != 9 {
+			t.Fatalf("AppendFile: %d, %v", size, err)
+		}
+		put := client.putInputs[0]
+		body, _ := io.ReadAll(put.Body)
+		if string(body) != "data+more" || aws.ToString(put.IfMatch) != `"v1"` || aws.ToString(put.ContentType) != "text/plain" {
+			t.Fatalf("unexpected rewrite %q %#v", body, put)
+		}
+		if aws.ToString(client.getInputs[0].IfMatch) != `"v1"` {
+			t.Fatalf("expected the read to be pinned to the ETag")
+		}
+
+		client.putInputs = nil
+		client.putErr = &smithy.GenericAPIError{Code: "PreconditionFailed"}
+		if _, err := provider.AppendFile(ctx, "audit.log", []byte("+more")); !errors.Is(err, ErrAppendConflict) {
+			t.Fatalf("expected ErrAppendConflict, got %v", err)
+		}
+	})
+
+	t.Run("large object", func(t *testing.T) {
+		client := &fakeS3Client{
+			headOutput:              &s3.HeadObjectOutput{ContentLength: aws.Int64(MinS3ChunkPartSize), ETag: aws.String(`"v1"`)},
+			createMultipartOutput:   &s3.CreateMultipartUploadOutput{UploadId: aws.String("upload")},
+			uploadPartOutput:        &s3.UploadPartOutput{ETag: aws.String("tail")},
+			completeMultipartOutput: &s3.CompleteMultipartUploadOutput{},
+		}
+		provider := &AWSProvider{client: client, bucket: "test-bucket", logger: &DefaultLogger{}}
+
+		size, err := provider.AppendFile(ctx, "audit.log", []byte("+more"))
+		if err != nil || size != MinS3ChunkPartSize+5 {
+			t.Fatalf("AppendFile: %d, %v", size, err)
+		}
+		if len(client.putInputs) != 0 || len(client.partCopyInputs) != 1 {
+			t.Fatalf("expected a server side copy, got %d puts and %d copies", len(client.putInputs), len(client.partCopyInputs))
+		}
+		copied := client.partCopyInputs[0]
+		if aws.ToString(copied.CopySource) != "test-bucket/audit.log" || aws.ToString(copied.CopySourceIfMatch) != `"v1"` ||
+			aws.ToString(copied.CopySourceRange) != "bytes=0-5242879" {
+			t.Fatalf("unexpected part copy %#v", copied)
+		}
+		parts := client.lastCompletedParts
+		if len(parts) != 2 || aws.ToString(parts[0].ETag) != "copy-1" || aws.ToString(parts[1].ETag) != "tail" {
+			t.Fatalf("unexpected completed parts %#v", parts)
+		}
+	})
+}
@@ -0,0 +1,182 @@
+package uploader
+
+import (
+	"context"
+	"time"
+)
+
+// ArchiveState describes where an object sits in the archive lifecycle.
+type ArchiveState string
+
+const (
+	// ArchiveStateLive objects are readable directly.
+	ArchiveStateLive ArchiveState = "live"
+	// ArchiveStateArchived objects must be restored before GetFile succeeds.
+	ArchiveStateArchived ArchiveState = "archived"
+	// ArchiveStateRestoring objects have a restore request in progress.
+	ArchiveStateRestoring ArchiveState = "restoring"
+	// ArchiveStateRestored objects are archived but readable until RestoreExpiry.
+	ArchiveStateRestored ArchiveState = "restored"
+)
+
+// RestoreTier trades restore speed for cost. The values match the S3 Glacier retrieval tiers.
+type RestoreTier string
+
+const (
+	RestoreTierExpedited RestoreTier = "Expedited"
+	RestoreTierStandard  RestoreTier = "Standard"
+	RestoreTierBulk      RestoreTier = "Bulk"
+)
+
+// ArchiveStatus reports the archive state of an object.
+type ArchiveStatus struct {
+	Key           string       `json:"key"`
+	State         ArchiveState `json:"state"`
+	StorageClass  string       `json:"storage_class,omitempty"`
+	RestoreExpiry time.Time    `json:"restore_expiry,omitempty"`
+}
+
+// Readable reports whether GetFile can read the object in this state.
+func (s *ArchiveStatus) Readable() bool {
+	return s.State == ArchiveStateLive || s.State == ArchiveStateRestored
+}
+
+// Archiver is implemented by providers that can move objects to cold storage and bring them
+// back. Reading an archived object that is not restored fails with ErrObjectArchived.
+type Archiver interface {
+	ArchiveObject(ctx context.Context, path, storageClass string) error
+	RestoreObject(ctx context.Context, path string, days int, tier RestoreTier) error
+	ArchiveStatus(ctx context.Context, path string) (*ArchiveStatus, error)
+}
+
+// WithArchiveStorageClass sets the storage class Archive moves objects to,
+// DefaultArchiveStorageClass by default (e.g. "DEEP_ARCHIVE" for the cheapest S3 tier).
+func WithArchiveStorageClass(class string) Option {
+	return func(m *Manager) {
+		m.archiveClass = class
+	}
+}
+
+// WithRestorePolicy sets how long Restore keeps restored copies readable and which retrieval
+// tier it requests. Zero values keep DefaultRestoreDays and RestoreTierStandard.
+func WithRestorePolicy(days int, tier RestoreTier) Option {
+	return func(m *Manager) {
+		m.restoreDays = days
+		m.restoreTier = tier
+	}
+}
+
+// Archive moves key to the archive storage class. The object stays listed, but GetFile fails
+// with ErrObjectArchived until it is restored.
+func (m *Manager) Archive(ctx context.Context, key string) error {
+	if err := m.ensureWritable(); err != nil {
+		return err
+	}
+
+	archiver, err := m.archiver(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	class := m.archiveClass
+	if class == "" {
+		class = DefaultArchiveStorageClass
+	}
+
+	return m.observeErr(ctx, "archive", key, func() error {
+		return archiver.ArchiveObject(ctx, key, class)
+	})
+}
+
+// Restore requests a temporary readable copy of an archived key and returns its status. Restores
+// complete asynchronously; poll ArchiveStatus or call WaitRestored. Keys that are live or
+// already restoring are returned as they are; restoring a restored key extends its copy.
+func (m *Manager) Restore(ctx context.Context, key string) (*ArchiveStatus, error) {
+	if err := m.ensureWritable(); err != nil {
+		return nil, err
+	}
+
+	archiver, err := m.archiver(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := archiver.ArchiveStatus(ctx, key)
+	if err != nil || status.State == ArchiveStateLive || status.State == ArchiveStateRestoring {
+		return status, err
+	}
+
+	days := m.restoreDays
+	if days <= 0 {
+		days = DefaultRestoreDays
+	}
+	tier := m.restoreTier
+	if tier == "" {
+		tier = RestoreTierStandard
+	}
+
+	err = m.observeErr(ctx, "restore", key, func() error {
+		return archiver.RestoreObject(ctx, key, days, tier)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return archiver.ArchiveStatus(ctx, key)
+}
+
+// ArchiveStatus reports whether key is live, archived, being restored or restored.
+func (m *Manager) ArchiveStatus(ctx context.Context, key string) (*ArchiveStatus, error) {
+	archiver, err := m.archiver(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return observeCall(ctx, m, "archive_status", key, func() (*ArchiveStatus, error) {
+		return archiver.ArchiveStatus(ctx, key)
+	})
+}
+
+// WaitRestored polls ArchiveStatus every interval until key is readable or ctx ends. It does not
+// request the restore; call Restore first.
+func (m *Manager) WaitRestored(ctx context.Context, key string, interval time.Duration) (*ArchiveStatus, error) {
+	if interval <= 0 {
+		interval = DefaultRestorePollInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		status, err := m.ArchiveStatus(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if status.Readable() {
+			return status, nil
+		}
+		if status.State == ArchiveStateArchived {
+			return status, ErrObjectArchived
+		}
+
+		select {
+		case <-ctx.Done():
+			return status, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *Manager) archiver(ctx context.Context, key string) (Archiver, error) {
+	if err := m.validateKey(key); err != nil {
+		return nil, err
+	}
+
+	if err := m.ensureProvider(ctx); err != nil {
+		return nil, err
+	}
+
+	if archiver, ok := m.providerFor(ctx, key).(Archiver); ok {
+		return archiver, nil
+	}
+	return nil, ErrNotImplemented
+}
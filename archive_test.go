@@ -0,0 +1,104 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+func TestAWSArchiveStatus(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		class   types.StorageClass
+		restore string
+		want    ArchiveState
+	}{
+		{name: "standard", class: types.StorageClassStandard, want: ArchiveStateLive},
+		{name: "instant retrieval", class: types.StorageClassGlacierIr, want: ArchiveStateLive},
+		{name: "archived", class: types.StorageClassGlacier, want: ArchiveStateArchived},
+		{name: "restoring", class: types.StorageClassDeepArchive, restore: `ongoing-request="true"`, want: ArchiveStateRestoring},
+		{name: "restored", class: types.StorageClassGlacier, restore: `ongoing-request="false", expiry-date="Fri, 21 Dec 2012 00:00:00 GMT"`, want: ArchiveStateRestored},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &fakeS3Client{headOutput: &s3.HeadObjectOutput{StorageClass: tc.class}}
+			if tc.restore != "" {
+				client.headOutput.Restore = aws.String(tc.restore)
+			}
+			provider := &AWSProvider{client: client, bucket: "bucket", logger: &DefaultLogger{}}
+
+			status, err := provider.ArchiveStatus(context.Background(), "docs/a.pdf")
+			if err != nil {
+				t.Fatalf("ArchiveStatus: %v", err)
+			}
+			if status.State != tc.want {
+				t.Fatalf("expected %s, got %s", tc.want, status.State)
+			}
+			if tc.want == ArchiveStateRestored && !status.RestoreExpiry.Equal(time.Date(2012, 12, 21, 0, 0, 0, 0, time.UTC)) {
+				t.Fatalf("unexpected restore expiry %v", status.RestoreExpiry)
+			}
+		})
+	}
+}
+
+func TestManagerArchiveAndRestore(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeS3Client{headOutput: &s3.HeadObjectOutput{StorageClass: types.StorageClassGlacier}}
+	provider := &AWSProvider{client: client, bucket: "bucket", logger: &DefaultLogger{}}
+	manager := NewManager(WithProvider(provider), WithRestorePolicy(3, RestoreTierBulk))
+
+	if err := manager.Archive(ctx, "docs/a b.pdf"); err != nil {
+		t.Fatalf("Archive: %v", err)
+	}
+	copied := client.copyInputs[0]
+	if copied.StorageClass != types.StorageClassGlacier || aws.ToString(copied.CopySource) != "bucket/docs%2Fa%20b.pdf" {
+		t.Fatalf("unexpected copy: %s %s", copied.StorageClass, aws.ToString(copied.CopySource))
+	}
+
+	if _, err := manager.Restore(ctx, "docs/a b.pdf"); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	restore := client.restoreInputs[0].RestoreRequest
+	if aws.ToInt32(restore.Days) != 3 || restore.GlacierJobParameters.Tier != types.TierBulk {
+		t.Fatalf("unexpected restore request: %#v", restore)
+	}
+
+	client.restoreErr = &smithy.GenericAPIError{Code: "RestoreAlreadyInProgress"}
+	if err := provider.RestoreObject(ctx, "docs/a b.pdf", 1, RestoreTierStandard); err != nil {
+		t.Fatalf("expected restore in progress to succeed, got %v", err)
+	}
+
+	client.getErr = &types.InvalidObjectState{}
+	if _, err := manager.GetFile(ctx, "docs/a b.pdf"); !errors.Is(err, ErrObjectArchived) {
+		t.Fatalf("expected ErrObjectArchived, got %v", err)
+	}
+}
+
+func TestManagerWaitRestored(t *testing.T) {
+	client := &fakeS3Client{headOutput: &s3.HeadObjectOutput{
+		StorageClass: types.StorageClassGlacier,
+		Restore:      aws.String(`ongoing-request="true"`),
+	}}
+	manager := NewManager(WithProvider(&AWSProvider{client: client, bucket: "bucket", logger: &DefaultLogger{}}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := manager.WaitRestored(ctx, "docs/a.pdf", time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline while restoring, got %v", err)
+	}
+
+	client.headOutput.Restore = aws.String(`ongoing-request="false", expiry-date="Fri, 21 Dec 2012 00:00:00 GMT"`)
+	status, err := manager.WaitRestored(context.Background(), "docs/a.pdf", time.Millisecond)
+	if err != nil || status.State != ArchiveStateRestored {
+		t.Fatalf("expected restored status, got %#v (%v)", status, err)
+	}
+
+	if err := NewManager(WithProvider(newMemoryProvider())).Archive(context.Background(), "docs/a.pdf"); !errors.Is(err, ErrNotImplemented) {
+		t.Fatalf("expected ErrNotImplemented, got %v", err)
+	}
+}
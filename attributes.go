@@ -0,0 +1,53 @@
+package uploader
+
+import "context"
+
+type attributesContextKey struct{}
+
+// ContextWithAttributes returns a copy of ctx carrying request scoped attributes (user ID,
+// request ID, tenant, ...). Attributes already present in ctx are preserved unless overridden.
+func ContextWithAttributes(ctx context.Context, attrs map[string]string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	merged := mergeAttributes(AttributesFromContext(ctx), attrs)
+	return context.WithValue(ctx, attributesContextKey{}, merged)
+}
+
+// AttributesFromContext returns the attributes attached with ContextWithAttributes.
+func AttributesFromContext(ctx context.Context) map[string]string {
+	if ctx == nil {
+		return nil
+	}
+	attrs, _ := ctx.Value(attributesContextKey{}).(map[string]string)
+	return attrs
+}
+
+// WithAttributes attaches attributes to a single upload. They are merged on top of any
+// attributes found in the request context.
+func WithAttributes(attrs map[string]string) UploadOption {
+	return func(m *Metadata) {
+		m.Attributes = mergeAttributes(m.Attributes, attrs)
+	}
+}
+
+func mergeAttributes(sets ...map[string]string) map[string]string {
+	var out map[string]string
+	for _, set := range sets {
+		for k, v := range set {
+			if out == nil {
+				out = make(map[string]string)
+			}
+			out[k] = v
+		}
+	}
+	return out
+}
+
+func (m *Manager) attachAttributes(ctx context.Context, meta *FileMeta, extra ...map[string]string) {
+	if meta == nil {
+		return
+	}
+	sets := append([]map[string]string{AttributesFromContext(ctx), meta.Attributes}, extra...)
+	meta.Attributes = mergeAttributes(sets...)
+}
@@ -0,0 +1,85 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestAttributesPropagateToCallbacks(t *testing.T) {
+	provider := newMemoryProvider()
+	manager := NewManager()
+	WithProvider(provider)(manager)
+
+	var got map[string]string
+	WithOnUploadComplete(func(ctx context.Context, meta *FileMeta) error {
+		got = meta.Attributes
+		return nil
+	})(manager)
+
+	ctx := ContextWithAttributes(context.Background(), map[string]string{
+		"user_id":    "u-1",
+		"request_id": "req-1",
+	})
+	ctx = ContextWithAttributes(ctx, map[string]string{"tenant": "acme"})
+
+	header := newTestFileHeader(t, "file", "sample.png", "image/png", createTestPNG(10, 10))
+	meta, err := manager.HandleFile(ctx, header, "images")
+	if err != nil {
+		t.Fatalf("HandleFile: %v", err)
+	}
+
+	for _, attrs := range []map[string]string{meta.Attributes, got} {
+		if attrs["user_id"] != "u-1" || attrs["request_id"] != "req-1" || attrs["tenant"] != "acme" {
+			t.Fatalf("expected attributes to propagate, got %v", attrs)
+		}
+	}
+}
+
+func TestAttributesPersistAcrossChunkedSession(t *testing.T) {
+	provider := newMemoryProvider()
+	manager := NewManager()
+	WithProvider(provider)(manager)
+
+	initCtx := ContextWithAttributes(context.Background(), map[string]string{"user_id": "u-1"})
+	session, err := manager.InitiateChunked(initCtx, "chunks/file.bin", 4,
+		WithAttributes(map[string]string{"tenant": "acme"}),
+	)
+	if err != nil {
+		t.Fatalf("InitiateChunked: %v", err)
+	}
+
+	ctx := context.Background()
+	if err := manager.UploadChunk(ctx, session.ID, 0, bytes.NewReader([]byte("abcd"))); err != nil {
+		t.Fatalf("UploadChunk: %v", err)
+	}
+
+	meta, err := manager.CompleteChunked(ctx, session.ID)
+	if err != nil {
+		t.Fatalf("CompleteChunked: %v", err)
+	}
+
+	if meta.Attributes["user_id"] != "u-1" || meta.Attributes["tenant"] != "acme" {
+		t.Fatalf("expected session attributes on completion, got %v", meta.Attributes)
+	}
+}
+
+func TestAttributesFromPresignedResult(t *testing.T) {
+	manager := NewManager()
+	WithProvider(newMemoryProvider())(manager)
+
+	ctx := ContextWithAttributes(context.Background(), map[string]string{"request_id": "req-2"})
+	meta, err := manager.ConfirmPresignedUpload(ctx, &PresignedUploadResult{
+		Key:         "uploads/direct.jpg",
+		Size:        4,
+		ContentType: "image/jpeg",
+		Metadata:    map[string]string{"source": "spa"},
+	})
+	if err != nil {
+		t.Fatalf("ConfirmPresignedUpload: %v", err)
+	}
+
+	if meta.Attributes["request_id"] != "req-2" || meta.Attributes["source"] != "spa" {
+		t.Fatalf("unexpected attributes: %v", meta.Attributes)
+	}
+}
@@ -0,0 +1,147 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"time"
+)
+
+// maxThrottledRead caps a single read so the limiter smooths traffic instead of letting one large
+// read through and then stalling.
+const maxThrottledRead = 32 * 1024
+
+type bandwidthLimitContextKey struct{}
+
+// WithBandwidthLimit caps how fast each upload streams to the provider, in bytes per second.
+// The limit applies per call, so one large upload cannot starve other traffic on a shared link.
+// Zero or negative disables throttling.
+func WithBandwidthLimit(bytesPerSec int64) Option {
+	return func(m *Manager) {
+		m.bandwidthLimit = bytesPerSec
+	}
+}
+
+// ContextWithBandwidthLimit overrides the manager bandwidth limit for calls made with ctx.
+// A negative value disables throttling for those calls.
+func ContextWithBandwidthLimit(ctx context.Context, bytesPerSec int64) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, bandwidthLimitContextKey{}, bytesPerSec)
+}
+
+func (m *Manager) bandwidthLimitFor(ctx context.Context) int64 {
+	if ctx != nil {
+		if limit, ok := ctx.Value(bandwidthLimitContextKey{}).(int64); ok && limit != 0 {
+			return limit
+		}
+	}
+	return m.bandwidthLimit
+}
+
+// throttle wraps r with a token bucket when a bandwidth limit applies to ctx. Seekable readers
+// stay seekable so providers that rewind the body (S3 signing) keep working.
+func (m *Manager) throttle(ctx context.Context, r io.Reader) io.Reader {
+	limit := m.bandwidthLimitFor(ctx)
+	if limit <= 0 {
+		return r
+	}
+
+	throttled := &throttledReader{
+		ctx:    ctx,
+		r:      r,
+		bucket: newTokenBucket(limit),
+	}
+	if seeker, ok := r.(io.Seeker); ok {
+		return &throttledReadSeeker{throttledReader: throttled, seeker: seeker}
+	}
+	return throttled
+}
+
+// uploadThrottled sends content through the provider stream API when a bandwidth limit applies;
+// providers without StreamUploader receive the bytes unthrottled.
+func (m *Manager) uploadThrottled(ctx context.Context, provider Uploader, path string, content []byte, opts ...UploadOption) (string, bool, error) {
+	if m.bandwidthLimitFor(ctx) <= 0 {
+		return "", false, nil
+	}
+
+	streamer, ok := provider.(StreamUploader)
+	if !ok {
+		return "", false, nil
+	}
+
+	url, err := streamer.UploadStream(ctx, path, m.throttle(ctx, bytes.NewReader(content)), int64(len(content)), opts...)
+	return url, true, err
+}
+
+type throttledReader struct {
+	ctx    context.Context
+	r      io.Reader
+	bucket *tokenBucket
+}
+
+func (t *throttledReader) Read(p []byte) (int, error) {
+	if len(p) > maxThrottledRead {
+		p = p[:maxThrottledRead]
+	}
+
+	n, err := t.r.Read(p)
+	if n > 0 {
+		if waitErr := t.bucket.wait(t.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+type throttledReadSeeker struct {
+	*throttledReader
+	seeker io.Seeker
+}
+
+func (t *throttledReadSeeker) Seek(offset int64, whence int) (int64, error) {
+	return t.seeker.Seek(offset, whence)
+}
+
+// tokenBucket is a single-reader token bucket that starts empty, so throughput never exceeds the
+// configured rate, not even for the first second.
+type tokenBucket struct {
+	rate   float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+func newTokenBucket(bytesPerSec int64) *tokenBucket {
+	return &tokenBucket{
+		rate: float64(bytesPerSec),
+		last: time.Now(),
+		now:  time.Now,
+	}
+}
+
+// wait spends n tokens and blocks until the bucket is no longer in debt.
+func (b *tokenBucket) wait(ctx context.Context, n int) error {
+	now := b.now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.rate {
+		b.tokens = b.rate
+	}
+	b.last = now
+	b.tokens -= float64(n)
+
+	if b.tokens >= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(time.Duration(-b.tokens / b.rate * float64(time.Second)))
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
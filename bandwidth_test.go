@@ -0,0 +1,68 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestThrottledReaderRespectsLimit(t *testing.T) {
+	manager := NewManager(WithBandwidthLimit(100 * 1024))
+	payload := bytes.Repeat([]byte("x"), 20*1024)
+
+	start := time.Now()
+	read, err := io.ReadAll(manager.throttle(context.Background(), bytes.NewReader(payload)))
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+
+	if !bytes.Equal(read, payload) {
+		t.Fatalf("throttled reader altered payload")
+	}
+
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Fatalf("expected ~200ms at 100KiB/s, took %v", elapsed)
+	}
+}
+
+func TestThrottleContextOverride(t *testing.T) {
+	manager := NewManager(WithBandwidthLimit(1))
+	r := bytes.NewReader([]byte("data"))
+
+	if got := manager.throttle(ContextWithBandwidthLimit(context.Background(), -1), r); got != r {
+		t.Fatalf("expected negative per-call limit to disable throttling")
+	}
+
+	throttled := manager.throttle(ContextWithBandwidthLimit(context.Background(), 1024), r)
+	if _, ok := throttled.(io.Seeker); !ok {
+		t.Fatalf("expected throttled reader to stay seekable")
+	}
+}
+
+func TestThrottledReaderHonoursCancellation(t *testing.T) {
+	manager := NewManager(WithBandwidthLimit(1))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := io.ReadAll(manager.throttle(ctx, bytes.NewReader([]byte("data"))))
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context cancellation, got %v", err)
+	}
+}
+
+func TestUploadFileThrottlesThroughStreamUploader(t *testing.T) {
+	dir := t.TempDir()
+	manager := NewManager(WithProvider(NewFSProvider(dir)), WithBandwidthLimit(100*1024))
+
+	start := time.Now()
+	if _, err := manager.UploadFile(context.Background(), "docs/big.bin", bytes.Repeat([]byte("x"), 20*1024)); err != nil {
+		t.Fatalf("UploadFile failed: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Fatalf("expected throttled upload, took %v", elapsed)
+	}
+}
@@ -0,0 +1,127 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+)
+
+// discardProvider accepts writes without keeping them, so benchmarks measure the manager rather
+// than a growing in-memory store.
+type discardProvider struct {
+	*memoryProvider
+}
+
+func (p *discardProvider) UploadFile(ctx context.Context, path string, content []byte, opts ...UploadOption) (string, error) {
+	return path, nil
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Info(msg string, args ...any)  {}
+func (nopLogger) Error(msg string, args ...any) {}
+
+var benchmarkImageSizes = []struct {
+	name          string
+	width, height int
+}{
+	{"small", 64, 64},
+	{"medium", 512, 384},
+	{"large", 2048, 1536},
+}
+
+func BenchmarkHandleFile(b *testing.B) {
+	ctx := context.Background()
+	manager := NewManager(WithProvider(&discardProvider{newMemoryProvider()}), WithLogger(nopLogger{}))
+
+	for _, size := range benchmarkImageSizes {
+		content := createTestPNG(size.width, size.height)
+		file := newTestFileHeader(b, "file", "photo.png", "image/png", content)
+
+		b.Run(size.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(content)))
+			for i := 0; i < b.N; i++ {
+				if _, err := manager.HandleFile(ctx, file, "images"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkGenerateThumbnail(b *testing.B) {
+	ctx := context.Background()
+	processor := NewLocalImageProcessor()
+	thumb := ThumbnailSize{Name: "small", Width: 200, Height: 200, Fit: "cover"}
+
+	for _, size := range benchmarkImageSizes {
+		source := createTestPNG(size.width, size.height)
+
+		b.Run(size.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(source)))
+			for i := 0; i < b.N; i++ {
+				if _, _, err := processor.Generate(ctx, source, thumb, "image/png"); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkChunkedAssembly(b *testing.B) {
+	ctx := context.Background()
+	const partSize = 1 << 20
+
+	for _, parts := range []int{4, 16} {
+		payload := bytes.Repeat([]byte("c"), partSize)
+		total := int64(parts * partSize)
+
+		b.Run(fmt.Sprintf("%dparts", parts), func(b *testing.B) {
+			manager := NewManager(
+				WithProvider(NewFSProvider(b.TempDir(), WithFSLogger(nopLogger{}))),
+				WithLogger(nopLogger{}),
+				WithChunkPartSize(partSize),
+			)
+
+			b.ReportAllocs()
+			b.SetBytes(total)
+			for i := 0; i < b.N; i++ {
+				session, err := manager.InitiateChunked(ctx, "bench.bin", total)
+				if err != nil {
+					b.Fatal(err)
+				}
+				for idx := 0; idx < parts; idx++ {
+					if err := manager.UploadChunk(ctx, session.ID, idx, bytes.NewReader(payload)); err != nil {
+						b.Fatal(err)
+					}
+				}
+				if _, err := manager.CompleteChunked(ctx, session.ID); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkMultiProviderWrite(b *testing.B) {
+	ctx := context.Background()
+	local := NewFSProvider(b.TempDir(), WithFSLogger(nopLogger{}))
+	provider := NewMultiProvider(local, &discardProvider{newMemoryProvider()}, WithMultiLogger(nopLogger{}))
+
+	for _, size := range []int{4 << 10, 1 << 20} {
+		content := bytes.Repeat([]byte("m"), size)
+
+		b.Run(fmt.Sprintf("%dKiB", size>>10), func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(size))
+			for i := 0; i < b.N; i++ {
+				if _, err := provider.UploadFile(ctx, "bench/object.bin", content); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package uploader
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// copyBufferSize matches the buffer io.Copy allocates on every call.
+const copyBufferSize = 32 * 1024
+
+// DefaultBufferPool is shared by the manager and providers unless they are given their own pool.
+var DefaultBufferPool = NewBufferPool(DefaultBufferPoolMaxRetained)
+
+// BufferPool recycles byte buffers used while reading uploads, downloads and chunk payloads.
+// A nil *BufferPool uses DefaultBufferPool.
+type BufferPool struct {
+	pool        sync.Pool
+	maxRetained int
+}
+
+// NewBufferPool creates a pool that keeps buffers up to maxRetained bytes; maxRetained <= 0
+// keeps every buffer.
+func NewBufferPool(maxRetained int) *BufferPool {
+	return &BufferPool{
+		pool: sync.Pool{
+			New: func() any { return new(bytes.Buffer) },
+		},
+		maxRetained: maxRetained,
+	}
+}
+
+// WithBufferPool sets the pool the manager reads uploads into.
+func WithBufferPool(pool *BufferPool) Option {
+	return func(m *Manager) {
+		m.buffers = pool
+	}
+}
+
+func (p *BufferPool) orDefault() *BufferPool {
+	if p == nil {
+		return DefaultBufferPool
+	}
+	return p
+}
+
+// Get returns an empty buffer. Return it with Put once nothing references its bytes.
+func (p *BufferPool) Get() *bytes.Buffer {
+	return p.orDefault().pool.Get().(*bytes.Buffer)
+}
+
+// Put resets buf and makes it available for reuse.
+func (p *BufferPool) Put(buf *bytes.Buffer) {
+	if buf == nil {
+		return
+	}
+
+	p = p.orDefault()
+	if p.maxRetained > 0 && buf.Cap() > p.maxRetained {
+		return
+	}
+
+	buf.Reset()
+	p.pool.Put(buf)
+}
+
+// ReadAll behaves like io.ReadAll but grows a pooled buffer, so the only allocation per call is
+// the returned slice, sized exactly to the content.
+func (p *BufferPool) ReadAll(r io.Reader) ([]byte, error) {
+	buf := p.Get()
+	defer p.Put(buf)
+
+	if _, err := buf.ReadFrom(r); err != nil {
+		return nil, err
+	}
+
+	return bytes.Clone(buf.Bytes()), nil
+}
+
+// Copy behaves like io.Copy using a pooled copy buffer.
+func (p *BufferPool) Copy(dst io.Writer, src io.Reader) (int64, error) {
+	buf := p.Get()
+	defer p.Put(buf)
+
+	buf.Grow(copyBufferSize)
+	return io.CopyBuffer(dst, src, buf.AvailableBuffer()[:copyBufferSize])
+}
@@ -0,0 +1,116 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+func TestBufferPoolReadAll(t *testing.T) {
+	pool := NewBufferPool(0)
+
+	first, err := pool.ReadAll(bytes.NewReader([]byte("first payload")))
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+
+	second, err := pool.ReadAll(bytes.NewReader([]byte("second")))
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+
+	if string(first) != "first payload" || string(second) != "second" {
+		t.Fatalf("expected results to survive buffer reuse, got %q and %q", first, second)
+	}
+}
+
+func TestBufferPoolDropsOversizedBuffers(t *testing.T) {
+	pool := NewBufferPool(16)
+
+	buf := pool.Get()
+	buf.Write(make([]byte, 64))
+	pool.Put(buf)
+
+	if got := pool.Get(); got == buf {
+		t.Fatalf("expected oversized buffer to be discarded")
+	}
+}
+
+func TestNilBufferPoolUsesDefault(t *testing.T) {
+	var pool *BufferPool
+
+	content, err := pool.ReadAll(bytes.NewReader([]byte("data")))
+	if err != nil || string(content) != "data" {
+		t.Fatalf("expected nil pool to read via default pool, got %q (%v)", content, err)
+	}
+}
+
+var benchmarkPayload = bytes.Repeat([]byte("go-uploader"), 100*1024)
+
+func BenchmarkReadAll(b *testing.B) {
+	b.Run("io", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := io.ReadAll(bytes.NewReader(benchmarkPayload)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("pool", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := DefaultBufferPool.ReadAll(bytes.NewReader(benchmarkPayload)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkChecksumCopy(b *testing.B) {
+	b.Run("io", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := io.Copy(sha256.New(), io.NewSectionReader(bytes.NewReader(benchmarkPayload), 0, int64(len(benchmarkPayload)))); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("pool", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := DefaultBufferPool.Copy(sha256.New(), io.NewSectionReader(bytes.NewReader(benchmarkPayload), 0, int64(len(benchmarkPayload)))); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkAWSProviderUploadChunk(b *testing.B) {
+	ctx := context.Background()
+	provider := &AWSProvider{
+		client:  &fakeS3Client{uploadPartOutput: &s3.UploadPartOutput{ETag: aws.String("etag")}},
+		bucket:  "bench-bucket",
+		logger:  &DefaultLogger{},
+		buffers: NewBufferPool(0),
+	}
+	session := &ChunkSession{
+		ID:           "bench",
+		Key:          "bench.bin",
+		ProviderData: map[string]any{awsUploadIDKey: "upload-1"},
+	}
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(benchmarkPayload)))
+	for i := 0; i < b.N; i++ {
+		if _, err := provider.UploadChunk(ctx, session, 0, bytes.NewReader(benchmarkPayload)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package uploader
+
+import (
+	"mime/multipart"
+	"path/filepath"
+	"strings"
+
+	gerrors "github.com/goliatone/go-errors"
+)
+
+// CachePolicy supplies Cache-Control values for uploads that do not set one with
+// WithCacheControl. ByContentType keys are exact MIME types ("image/png") or type wildcards
+// ("image/*"); Default applies when nothing matches. Immutable is used for content-addressed keys
+// (NameStrategyContentHash) and defaults to DefaultImmutableCacheControl.
+type CachePolicy struct {
+	Default       string
+	ByContentType map[string]string
+	Immutable     string
+}
+
+// WithCachePolicy sets the Cache-Control defaults applied to uploads, chunked sessions and
+// presigned posts.
+func WithCachePolicy(policy CachePolicy) Option {
+	return func(m *Manager) {
+		m.cachePolicy = policy
+	}
+}
+
+// NameStrategy controls how HandleFile names stored objects.
+type NameStrategy string
+
+const (
+	// NameStrategyTimestamp names objects after the upload time (the default).
+	NameStrategyTimestamp NameStrategy = "timestamp"
+	// NameStrategyContentHash names objects after the SHA-256 of their content, so a key always
+	// maps to the same bytes and can be cached as immutable.
+	NameStrategyContentHash NameStrategy = "content_hash"
+)
+
+// WithNameStrategy selects how HandleFile names stored objects.
+func WithNameStrategy(strategy NameStrategy) Option {
+	return func(m *Manager) {
+		m.nameStrategy = strategy
+	}
+}
+
+func (m *Manager) contentAddressed() bool {
+	return m.nameStrategy == NameStrategyContentHash
+}
+
+// objectName returns the key for an uploaded file. checksum is only read for content-hash names.
+func (m *Manager) objectName(file *multipart.FileHeader, path, checksum string) (string, error) {
+	if !m.contentAddressed() {
+		return timestampName(file, m.now(), path)
+	}
+
+	ext := filepath.Ext(file.Filename)
+	if ext == "" {
+		return "", gerrors.NewValidation("file validation failed",
+			gerrors.FieldError{
+				Field:   "file_extension",
+				Message: "file extension not found",
+				Value:   file.Filename,
+			},
+		).WithCode(400).WithTextCode("FILE_EXTENSION_NOT_FOUND")
+	}
+
+	name := checksum + strings.ToLower(ext)
+	if path != "" {
+		return path + "/" + name, nil
+	}
+	return name, nil
+}
+
+// cacheControlFor resolves the policy value for contentType, preferring an exact match over a
+// type wildcard over the default.
+func (m *Manager) cacheControlFor(contentType string) string {
+	policy := m.cachePolicy
+	if contentType != "" {
+		if value, ok := policy.ByContentType[contentType]; ok {
+			return value
+		}
+		if major, _, ok := strings.Cut(contentType, "/"); ok {
+			if value, ok := policy.ByContentType[major+"/*"]; ok {
+				return value
+			}
+		}
+	}
+	return policy.Default
+}
+
+func (m *Manager) immutableCacheControl() string {
+	if m.cachePolicy.Immutable != "" {
+		return m.cachePolicy.Immutable
+	}
+	return DefaultImmutableCacheControl
+}
+
+// storedFileCacheControl is the Cache-Control HandleFile applies to the objects it names.
+func (m *Manager) storedFileCacheControl(contentType string) string {
+	if m.contentAddressed() {
+		return m.immutableCacheControl()
+	}
+	return m.cacheControlFor(contentType)
+}
+
+// applyCachePolicy fills in Cache-Control when the caller did not set one.
+func (m *Manager) applyCachePolicy(md *Metadata) {
+	if md.CacheControl == "" {
+		md.CacheControl = m.cacheControlFor(md.ContentType)
+	}
+}
@@ -0,0 +1,101 @@
+package uploader
+
+import (
+	"context"
+	"testing"
+)
+
+func captureUploadMetadata(captured *Metadata) *mockUploader {
+	return &mockUploader{
+		uploadFunc: func(ctx context.Context, path string, content []byte, opts ...UploadOption) (string, error) {
+			*captured = Metadata{}
+			for _, opt := range opts {
+				opt(captured)
+			}
+			return "http://example.com/" + path, nil
+		},
+	}
+}
+
+func TestCachePolicyResolution(t *testing.T) {
+	manager := NewManager(WithCachePolicy(CachePolicy{
+		Default: "no-cache",
+		ByContentType: map[string]string{
+			"image/*":       "public, max-age=86400",
+			"image/svg+xml": "no-store",
+		},
+	}))
+
+	cases := map[string]string{
+		"image/png":       "public, max-age=86400",
+		"image/svg+xml":   "no-store",
+		"application/pdf": "no-cache",
+		"":                "no-cache",
+	}
+
+	for contentType, expected := range cases {
+		if got := manager.cacheControlFor(contentType); got != expected {
+			t.Fatalf("cacheControlFor(%q) = %q, expected %q", contentType, got, expected)
+		}
+	}
+}
+
+func TestUploadFileAppliesCachePolicy(t *testing.T) {
+	var captured Metadata
+	manager := NewManager(
+		WithProvider(captureUploadMetadata(&captured)),
+		WithCachePolicy(CachePolicy{ByContentType: map[string]string{"image/*": "public, max-age=60"}}),
+	)
+
+	if _, err := manager.UploadFile(context.Background(), "a.png", []byte("data"), WithContentType("image/png")); err != nil {
+		t.Fatalf("UploadFile failed: %v", err)
+	}
+	if captured.CacheControl != "public, max-age=60" {
+		t.Fatalf("expected policy cache control, got %q", captured.CacheControl)
+	}
+
+	if _, err := manager.UploadFile(context.Background(), "a.png", []byte("data"), WithContentType("image/png"), WithCacheControl("private")); err != nil {
+		t.Fatalf("UploadFile failed: %v", err)
+	}
+	if captured.CacheControl != "private" {
+		t.Fatalf("expected explicit cache control to win, got %q", captured.CacheControl)
+	}
+}
+
+func TestHandleFileContentHashNames(t *testing.T) {
+	var captured Metadata
+	manager := NewManager(
+		WithProvider(captureUploadMetadata(&captured)),
+		WithNameStrategy(NameStrategyContentHash),
+	)
+
+	png := createTestPNG(4, 4)
+	meta, err := manager.HandleFile(context.Background(), newTestFileHeader(t, "file", "Photo.PNG", "image/png", png), "images")
+	if err != nil {
+		t.Fatalf("HandleFile failed: %v", err)
+	}
+
+	if expected := "images/" + checksumSHA256(png) + ".png"; meta.Name != expected {
+		t.Fatalf("expected content-addressed name %q, got %q", expected, meta.Name)
+	}
+
+	if captured.CacheControl != DefaultImmutableCacheControl {
+		t.Fatalf("expected immutable cache control, got %q", captured.CacheControl)
+	}
+}
+
+func TestHandleFileTimestampNamesUsePolicy(t *testing.T) {
+	var captured Metadata
+	manager := NewManager(
+		WithProvider(captureUploadMetadata(&captured)),
+		WithCachePolicy(CachePolicy{Default: "public, max-age=300"}),
+	)
+
+	if _, err := manager.HandleFile(context.Background(), newTestFileHeader(t, "file", "photo.png", "image/png", createTestPNG(4, 4)), "images"); err != nil {
+		t.Fatalf("HandleFile failed: %v", err)
+	}
+
+	if captured.CacheControl != "public, max-age=300" {
+		t.Fatalf("expected policy cache control, got %q", captured.CacheControl)
+	}
+}
@@ -1,6 +1,9 @@
 package uploader
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 type CallbackExecutor interface {
 	Execute(ctx context.Context, cb UploadCallback, meta *FileMeta) error
@@ -12,15 +15,37 @@ func (syncCallbackExecutor) Execute(ctx context.Context, cb UploadCallback, meta
 	return cb(ctx, meta)
 }
 
+// AsyncCallbackExecutor runs callbacks in a goroutine. The request context that triggered the
+// upload is usually cancelled as soon as the handler returns, so callbacks run on a detached
+// context bounded by their own timeout.
 type AsyncCallbackExecutor struct {
-	logger Logger
+	logger           Logger
+	timeout          time.Duration
+	dropParentValues bool
 }
 
 func NewAsyncCallbackExecutor(logger Logger) *AsyncCallbackExecutor {
 	if logger == nil {
 		logger = &DefaultLogger{}
 	}
-	return &AsyncCallbackExecutor{logger: logger}
+	return &AsyncCallbackExecutor{
+		logger:  logger,
+		timeout: DefaultAsyncCallbackTimeout,
+	}
+}
+
+// WithTimeout bounds each callback run. Zero or negative disables the deadline.
+func (e *AsyncCallbackExecutor) WithTimeout(timeout time.Duration) *AsyncCallbackExecutor {
+	e.timeout = timeout
+	return e
+}
+
+// WithParentValues controls whether values stored on the triggering context (request IDs,
+// tenants, tracing spans) remain visible to callbacks. Enabled by default; cancellation and
+// deadlines of the parent are never propagated.
+func (e *AsyncCallbackExecutor) WithParentValues(enabled bool) *AsyncCallbackExecutor {
+	e.dropParentValues = !enabled
+	return e
 }
 
 func (e *AsyncCallbackExecutor) Execute(ctx context.Context, cb UploadCallback, meta *FileMeta) error {
@@ -28,11 +53,26 @@ func (e *AsyncCallbackExecutor) Execute(ctx context.Context, cb UploadCallback,
 		return nil
 	}
 
+	ctx = e.detach(ctx)
+
 	go func() {
+		cancel := func() {}
+		if e.timeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, e.timeout)
+		}
+		defer cancel()
+
 		if err := cb(ctx, meta); err != nil && e.logger != nil {
-			e.logger.Error("async upload callback failed", err, "key", meta.Name)
+			contextLogger(ctx, e.logger).Error("async upload callback failed", err, "key", meta.Name)
 		}
 	}()
 
 	return nil
 }
+
+func (e *AsyncCallbackExecutor) detach(ctx context.Context) context.Context {
+	if ctx == nil || e.dropParentValues {
+		return context.Background()
+	}
+	return context.WithoutCancel(ctx)
+}
@@ -0,0 +1,162 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CallbackOperation identifies which manager entry point stored the file a callback runs for.
+type CallbackOperation string
+
+const (
+	// CallbackOperationUpload covers HandleFile, including spooled uploads.
+	CallbackOperationUpload CallbackOperation = "upload"
+	// CallbackOperationImage covers HandleImageWithThumbnails, once every derivative is stored.
+	CallbackOperationImage CallbackOperation = "image"
+	// CallbackOperationChunked covers CompleteChunked.
+	CallbackOperationChunked CallbackOperation = "chunked"
+	// CallbackOperationPresigned covers ConfirmPresignedUpload.
+	CallbackOperationPresigned CallbackOperation = "presigned"
+)
+
+// CallbackFilter limits a registered callback to matching uploads. Empty fields match everything.
+// ContentTypes entries are exact MIME types ("image/png") or type wildcards ("image/*").
+type CallbackFilter struct {
+	PathPrefix   string
+	ContentTypes []string
+	Operations   []CallbackOperation
+}
+
+func (f CallbackFilter) matches(op CallbackOperation, meta *FileMeta) bool {
+	if f.PathPrefix != "" && !strings.HasPrefix(meta.Name, f.PathPrefix) {
+		return false
+	}
+
+	if len(f.Operations) > 0 && !containsOperation(f.Operations, op) {
+		return false
+	}
+
+	return len(f.ContentTypes) == 0 || matchesContentType(f.ContentTypes, meta.ContentType)
+}
+
+// matchesContentType reports whether contentType equals one of patterns or falls under a type
+// wildcard such as "image/*".
+func matchesContentType(patterns []string, contentType string) bool {
+	major, _, _ := strings.Cut(contentType, "/")
+	for _, pattern := range patterns {
+		if pattern == contentType || (major != "" && pattern == major+"/*") {
+			return true
+		}
+	}
+	return false
+}
+
+func containsOperation(ops []CallbackOperation, op CallbackOperation) bool {
+	for _, candidate := range ops {
+		if candidate == op {
+			return true
+		}
+	}
+	return false
+}
+
+// CallbackOption configures a callback registered with WithUploadCallback.
+type CallbackOption func(*registeredCallback)
+
+// WithCallbackPriority orders callbacks: higher priorities run first, equal priorities run in
+// registration order. The WithOnUploadComplete callback has priority 0.
+func WithCallbackPriority(priority int) CallbackOption {
+	return func(c *registeredCallback) {
+		c.priority = priority
+	}
+}
+
+// WithCallbackFilter restricts the callback to uploads matching filter.
+func WithCallbackFilter(filter CallbackFilter) CallbackOption {
+	return func(c *registeredCallback) {
+		c.filter = filter
+	}
+}
+
+type registeredCallback struct {
+	cb       UploadCallback
+	priority int
+	filter   CallbackFilter
+}
+
+// WithUploadCallback registers an additional post-upload callback. Unlike WithOnUploadComplete it
+// can be used several times; every matching callback runs through the CallbackExecutor.
+func WithUploadCallback(cb UploadCallback, opts ...CallbackOption) Option {
+	return func(m *Manager) {
+		if cb == nil {
+			return
+		}
+		entry := registeredCallback{cb: cb}
+		for _, opt := range opts {
+			opt(&entry)
+		}
+		m.callbacks = append(m.callbacks, entry)
+	}
+}
+
+// matchingCallbacks returns the callbacks that apply to meta, in execution order.
+func (m *Manager) matchingCallbacks(op CallbackOperation, meta *FileMeta) []registeredCallback {
+	all := m.callbacks
+	if m.callback != nil {
+		all = append([]registeredCallback{{cb: m.callback}}, all...)
+	}
+
+	var out []registeredCallback
+	for _, entry := range all {
+		if entry.filter.matches(op, meta) {
+			out = append(out, entry)
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].priority > out[j].priority
+	})
+	return out
+}
+
+func (m *Manager) runUploadCallback(ctx context.Context, op CallbackOperation, meta *FileMeta) error {
+	if meta == nil {
+		return nil
+	}
+
+	callbacks := m.matchingCallbacks(op, meta)
+	if len(callbacks) == 0 {
+		return nil
+	}
+
+	exec := m.ensureCallbackExecutor()
+	if m.callbackMode == CallbackModeStrict {
+		if _, ok := exec.(*AsyncCallbackExecutor); ok {
+			m.log(ctx).Info("async callback executor cannot enforce strict mode; treating as best effort")
+		}
+	}
+
+	start := time.Now()
+	var errs []error
+	for _, entry := range callbacks {
+		if err := exec.Execute(ctx, entry.cb, meta); err != nil {
+			m.log(ctx).Error("upload callback failed", err, "key", meta.Name, "operation", string(op))
+			errs = append(errs, err)
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		if m.callbackMode == CallbackModeStrict {
+			m.cleanupFiles(ctx, meta.Name)
+			return fmt.Errorf("upload callback failed: %w", err)
+		}
+		return nil
+	}
+
+	m.log(ctx).Info("upload callback completed", "key", meta.Name, "callbacks", len(callbacks), "duration", time.Since(start))
+	return nil
+}
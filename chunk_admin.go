@@ -0,0 +1,197 @@
+package uploader
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ChunkSessionFilter narrows ListChunkSessions results. Zero values match everything.
+type ChunkSessionFilter struct {
+	States    []ChunkSessionState
+	KeyPrefix string
+	Limit     int
+}
+
+// ChunkSessionInfo is an admin view of a chunked session and its progress.
+type ChunkSessionInfo struct {
+	ID            string            `json:"id"`
+	Key           string            `json:"key"`
+	State         ChunkSessionState `json:"state"`
+	TotalSize     int64             `json:"total_size"`
+	PartSize      int64             `json:"part_size"`
+	UploadedBytes int64             `json:"uploaded_bytes"`
+	UploadedParts int               `json:"uploaded_parts"`
+	// Parts lists the indexes of the stored parts in ascending order, so clients can resume.
+	Parts      []int             `json:"parts,omitempty"`
+	Progress   float64           `json:"progress"`
+	CreatedAt  time.Time         `json:"created_at"`
+	ExpiresAt  time.Time         `json:"expires_at"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// ListChunkSessions reports the sessions tracked by the manager, oldest first. Sessions past
+// their expiry are reported with ChunkSessionStateExpired until the store cleans them up.
+func (m *Manager) ListChunkSessions(ctx context.Context, filter ChunkSessionFilter) ([]ChunkSessionInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	store := m.ensureChunkStore()
+	now := store.timeNow()
+
+	var out []ChunkSessionInfo
+	for _, session := range store.List() {
+		info := chunkSessionInfo(session, now)
+		if !filter.matches(info) {
+			continue
+		}
+		out = append(out, info)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].CreatedAt.Before(out[j].CreatedAt)
+	})
+
+	if filter.Limit > 0 && len(out) > filter.Limit {
+		out = out[:filter.Limit]
+	}
+
+	return out, nil
+}
+
+// ChunkSession reports a single session with the indexes of its stored parts, or
+// ErrChunkSessionNotFound once it is unknown or cleaned up.
+func (m *Manager) ChunkSession(ctx context.Context, sessionID string) (*ChunkSessionInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	session, err := m.getChunkSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	info := chunkSessionInfo(session, m.ensureChunkStore().timeNow())
+	return &info, nil
+}
+
+// WithChunkSessionMaxLifetime caps how long after creation ExtendChunkSession may keep a session
+// alive, DefaultChunkSessionMaxLifetime by default.
+func WithChunkSessionMaxLifetime(d time.Duration) Option {
+	return func(m *Manager) {
+		if d > 0 {
+			m.chunkMaxLifetime = d
+		}
+	}
+}
+
+// ExtendChunkSession keeps an active session alive for ttl from now (the session TTL when
+// ttl <= 0), so slow transfers do not expire mid-upload. Extensions stop at the maximum
+// lifetime set with WithChunkSessionMaxLifetime; past it ErrChunkSessionLifetimeExceeded is
+// returned.
+func (m *Manager) ExtendChunkSession(ctx context.Context, sessionID string, ttl time.Duration) (*ChunkSessionInfo, error) {
+	if err := m.ensureWritable(); err != nil {
+		return nil, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	maxLifetime := m.chunkMaxLifetime
+	if maxLifetime <= 0 {
+		maxLifetime = DefaultChunkSessionMaxLifetime
+	}
+
+	store := m.ensureChunkStore()
+	session, err := store.Extend(sessionID, ttl, maxLifetime)
+	if err != nil {
+		return nil, err
+	}
+
+	info := chunkSessionInfo(session, store.timeNow())
+	return &info, nil
+}
+
+// ForceAbort aborts a session whatever its state, including sessions stuck completing or already
+// expired, and asks the provider to release any staged parts.
+func (m *Manager) ForceAbort(ctx context.Context, sessionID string) error {
+	if err := m.ensureWritable(); err != nil {
+		return err
+	}
+
+	if err := m.ensureProvider(ctx); err != nil {
+		return err
+	}
+
+	session, err := m.ensureChunkStore().ForceAbort(sessionID)
+	if err != nil {
+		return err
+	}
+
+	chunkProvider, err := m.chunkedProvider(sessionRouteContext(ctx, session), session.Key)
+	if err != nil {
+		return err
+	}
+
+	err = m.observeErr(ctx, "abort_chunked", session.Key, func() error {
+		return chunkProvider.AbortChunked(ctx, session)
+	})
+	m.emitEvent(ctx, &ChunkSessionAborted{SessionID: session.ID, Key: session.Key})
+	return err
+}
+
+func (f ChunkSessionFilter) matches(info ChunkSessionInfo) bool {
+	if f.KeyPrefix != "" && !strings.HasPrefix(info.Key, f.KeyPrefix) {
+		return false
+	}
+
+	if len(f.States) == 0 {
+		return true
+	}
+
+	for _, state := range f.States {
+		if state == info.State {
+			return true
+		}
+	}
+	return false
+}
+
+func chunkSessionInfo(session *ChunkSession, now time.Time) ChunkSessionInfo {
+	info := ChunkSessionInfo{
+		ID:            session.ID,
+		Key:           session.Key,
+		State:         session.State,
+		TotalSize:     session.TotalSize,
+		PartSize:      session.PartSize,
+		UploadedParts: len(session.UploadedParts),
+		CreatedAt:     session.CreatedAt,
+		ExpiresAt:     session.ExpiresAt,
+	}
+
+	if session.Metadata != nil {
+		info.Attributes = mergeAttributes(session.Metadata.Attributes)
+	}
+
+	for index, part := range session.UploadedParts {
+		info.UploadedBytes += part.Size
+		info.Parts = append(info.Parts, index)
+	}
+	sort.Ints(info.Parts)
+
+	if session.TotalSize > 0 {
+		info.Progress = float64(info.UploadedBytes) / float64(session.TotalSize)
+		if info.Progress > 1 {
+			info.Progress = 1
+		}
+	}
+
+	if now.After(session.ExpiresAt) && session.State != ChunkSessionStateAborted {
+		info.State = ChunkSessionStateExpired
+	}
+
+	return info
+}
@@ -0,0 +1,154 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestListChunkSessions(t *testing.T) {
+	ctx := context.Background()
+	provider := newMockChunkUploader()
+	store := NewChunkSessionStore(time.Hour)
+	now := time.Now()
+	store.timeNowFn = func() time.Time { return now }
+	manager := NewManager(WithProvider(provider), WithChunkSessionStore(store))
+
+	active, err := manager.InitiateChunked(ctx, "videos/active.bin", 10)
+	if err != nil {
+		t.Fatalf("InitiateChunked failed: %v", err)
+	}
+	if err := manager.UploadChunk(ctx, active.ID, 0, bytes.NewReader([]byte("12345"))); err != nil {
+		t.Fatalf("UploadChunk failed: %v", err)
+	}
+
+	now = now.Add(time.Minute)
+	aborted, err := manager.InitiateChunked(ctx, "videos/aborted.bin", 10)
+	if err != nil {
+		t.Fatalf("InitiateChunked failed: %v", err)
+	}
+	if err := manager.AbortChunked(ctx, aborted.ID); err != nil {
+		t.Fatalf("AbortChunked failed: %v", err)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := store.Create(&ChunkSession{ID: "stale", Key: "images/stale.bin", ExpiresAt: now.Add(-time.Second)}); err != nil {
+		t.Fatalf("create stale session: %v", err)
+	}
+
+	all, err := manager.ListChunkSessions(ctx, ChunkSessionFilter{})
+	if err != nil {
+		t.Fatalf("ListChunkSessions failed: %v", err)
+	}
+
+	if len(all) != 3 || all[0].ID != active.ID || all[1].ID != aborted.ID || all[2].ID != "stale" {
+		t.Fatalf("unexpected sessions: %#v", all)
+	}
+
+	if all[0].State != ChunkSessionStateActive || all[0].UploadedBytes != 5 || all[0].Progress != 0.5 {
+		t.Fatalf("unexpected active progress: %#v", all[0])
+	}
+
+	if all[1].State != ChunkSessionStateAborted || all[2].State != ChunkSessionStateExpired {
+		t.Fatalf("unexpected states: %s %s", all[1].State, all[2].State)
+	}
+
+	filtered, err := manager.ListChunkSessions(ctx, ChunkSessionFilter{
+		States:    []ChunkSessionState{ChunkSessionStateActive, ChunkSessionStateAborted},
+		KeyPrefix: "videos/",
+		Limit:     1,
+	})
+	if err != nil {
+		t.Fatalf("ListChunkSessions failed: %v", err)
+	}
+
+	if len(filtered) != 1 || filtered[0].ID != active.ID {
+		t.Fatalf("unexpected filtered sessions: %#v", filtered)
+	}
+
+	status, err := manager.ChunkSession(ctx, active.ID)
+	if err != nil || len(status.Parts) != 1 || status.Parts[0] != 0 || status.PartSize != active.PartSize {
+		t.Fatalf("unexpected session status %#v: %v", status, err)
+	}
+	if _, err := manager.ChunkSession(ctx, "stale"); !errors.Is(err, ErrChunkSessionNotFound) {
+		t.Fatalf("expected expired session to be unknown, got %v", err)
+	}
+}
+
+func TestForceAbort(t *testing.T) {
+	ctx := context.Background()
+	provider := newMockChunkUploader()
+	manager := NewManager(WithProvider(provider))
+
+	session, err := manager.InitiateChunked(ctx, "stuck.bin", 10)
+	if err != nil {
+		t.Fatalf("InitiateChunked failed: %v", err)
+	}
+
+	if _, err := manager.ensureChunkStore().Transition(session.ID, session.Version, ChunkSessionStateCompleting); err != nil {
+		t.Fatalf("Transition failed: %v", err)
+	}
+
+	if err := manager.AbortChunked(ctx, session.ID); !errors.Is(err, ErrChunkSessionClosed) {
+		t.Fatalf("expected regular abort to be rejected, got %v", err)
+	}
+
+	if err := manager.ForceAbort(ctx, session.ID); err != nil {
+		t.Fatalf("ForceAbort failed: %v", err)
+	}
+
+	if !provider.isAborted(session.ID) {
+		t.Fatalf("expected provider abort")
+	}
+
+	if err := manager.ForceAbort(ctx, "missing"); !errors.Is(err, ErrChunkSessionNotFound) {
+		t.Fatalf("expected ErrChunkSessionNotFound, got %v", err)
+	}
+}
+
+func TestExtendChunkSession(t *testing.T) {
+	ctx := context.Background()
+	store := NewChunkSessionStore(30 * time.Minute)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	store.timeNowFn = func() time.Time { return now }
+	manager := NewManager(
+		WithProvider(newMockChunkUploader()),
+		WithChunkSessionStore(store),
+		WithChunkSessionMaxLifetime(2*time.Hour),
+	)
+
+	session, err := manager.InitiateChunked(ctx, "videos/slow.bin", 10)
+	if err != nil {
+		t.Fatalf("InitiateChunked failed: %v", err)
+	}
+
+	now = now.Add(25 * time.Minute)
+	info, err := manager.ExtendChunkSession(ctx, session.ID, 0)
+	if err != nil {
+		t.Fatalf("ExtendChunkSession failed: %v", err)
+	}
+	if want := now.Add(30 * time.Minute); !info.ExpiresAt.Equal(want) {
+		t.Fatalf("expected expiry %s, got %s", want, info.ExpiresAt)
+	}
+
+	info, err = manager.ExtendChunkSession(ctx, session.ID, 3*time.Hour)
+	if err != nil {
+		t.Fatalf("ExtendChunkSession failed: %v", err)
+	}
+	if want := session.CreatedAt.Add(2 * time.Hour); !info.ExpiresAt.Equal(want) {
+		t.Fatalf("expected expiry capped at %s, got %s", want, info.ExpiresAt)
+	}
+
+	if _, err := manager.ExtendChunkSession(ctx, session.ID, time.Hour); !errors.Is(err, ErrChunkSessionLifetimeExceeded) {
+		t.Fatalf("expected ErrChunkSessionLifetimeExceeded, got %v", err)
+	}
+
+	if err := manager.AbortChunked(ctx, session.ID); err != nil {
+		t.Fatalf("AbortChunked failed: %v", err)
+	}
+	if _, err := manager.ExtendChunkSession(ctx, session.ID, time.Hour); !errors.Is(err, ErrChunkSessionClosed) {
+		t.Fatalf("expected ErrChunkSessionClosed, got %v", err)
+	}
+}
@@ -0,0 +1,141 @@
+package uploader
+
+import (
+	gerrors "github.com/goliatone/go-errors"
+)
+
+const (
+	// MaxChunkParts mirrors the S3 limit on parts per multipart upload and is applied to every
+	// provider unless it declares its own limits.
+	MaxChunkParts = 10000
+
+	// MinS3ChunkPartSize is the smallest non-final part S3 accepts when completing an upload.
+	MinS3ChunkPartSize int64 = 5 * 1024 * 1024
+)
+
+// ChunkLimits describes the part constraints enforced for chunked sessions. Zero values
+// disable the corresponding check.
+type ChunkLimits struct {
+	MaxParts    int
+	MinPartSize int64
+}
+
+// ChunkLimiter is implemented by providers whose backend rejects completions that violate part
+// count or size rules, so the manager can fail early with structured errors.
+type ChunkLimiter interface {
+	ChunkLimits() ChunkLimits
+}
+
+// WithChunkLimits overrides the limits reported by the provider.
+func WithChunkLimits(limits ChunkLimits) Option {
+	return func(m *Manager) {
+		m.chunkLimits = &limits
+	}
+}
+
+func (m *Manager) resolveChunkLimits(provider ChunkedUploader) ChunkLimits {
+	if m.chunkLimits != nil {
+		return *m.chunkLimits
+	}
+
+	limits := ChunkLimits{MaxParts: MaxChunkParts}
+	if limiter, ok := provider.(ChunkLimiter); ok {
+		limits = limiter.ChunkLimits()
+	}
+	return limits
+}
+
+// validateChunkPlan checks that a session of totalSize split into partSize pieces stays within
+// limits.
+func validateChunkPlan(limits ChunkLimits, totalSize, partSize int64) error {
+	if limits.MinPartSize > 0 && partSize < limits.MinPartSize && totalSize > partSize {
+		return gerrors.NewValidation("chunked upload initialization failed",
+			gerrors.FieldError{
+				Field:   "part_size",
+				Message: "part size is below the provider minimum for non-final parts",
+				Value:   partSize,
+			},
+		).WithCode(400).WithTextCode("CHUNK_PART_TOO_SMALL")
+	}
+
+	if parts := chunkPartCount(totalSize, partSize); limits.MaxParts > 0 && parts > limits.MaxParts {
+		return gerrors.NewValidation("chunked upload initialization failed",
+			gerrors.FieldError{
+				Field:   "total_size",
+				Message: "too many parts for configured part size",
+				Value:   parts,
+			},
+		).WithCode(400).WithTextCode("CHUNK_PART_LIMIT_EXCEEDED")
+	}
+
+	return nil
+}
+
+// maxChunkIndex returns the highest part index a session can legitimately use. Clients may pick
+// their own chunk size, so the bound comes from the smallest part the provider accepts.
+func maxChunkIndex(limits ChunkLimits, totalSize int64) int {
+	minPart := limits.MinPartSize
+	if minPart <= 0 {
+		minPart = 1
+	}
+
+	parts := chunkPartCount(totalSize, minPart)
+	if limits.MaxParts > 0 && parts > limits.MaxParts {
+		parts = limits.MaxParts
+	}
+	return parts - 1
+}
+
+// validateChunkPart rejects parts that would make the provider fail the completion call: indexes
+// beyond what the session can hold and undersized parts that are known not to be the last one.
+func validateChunkPart(limits ChunkLimits, session *ChunkSession, index int, size int64) error {
+	if index > maxChunkIndex(limits, session.TotalSize) {
+		return ErrChunkPartOutOfRange
+	}
+
+	if limits.MinPartSize <= 0 || size <= 0 || size >= limits.MinPartSize {
+		return nil
+	}
+
+	for existing := range session.UploadedParts {
+		if existing > index {
+			return chunkPartTooSmall(index, size)
+		}
+	}
+
+	return nil
+}
+
+// validateChunkParts runs the final size check before completion: every part except the highest
+// index must meet the provider minimum. Parts with unknown size (reported by presigned clients)
+// are skipped.
+func validateChunkParts(limits ChunkLimits, session *ChunkSession) error {
+	if limits.MinPartSize <= 0 || len(session.UploadedParts) < 2 {
+		return nil
+	}
+
+	last := -1
+	for idx := range session.UploadedParts {
+		if idx > last {
+			last = idx
+		}
+	}
+
+	for idx, part := range session.UploadedParts {
+		if idx != last && part.Size > 0 && part.Size < limits.MinPartSize {
+			return chunkPartTooSmall(idx, part.Size)
+		}
+	}
+
+	return nil
+}
+
+func chunkPartTooSmall(index int, size int64) error {
+	return gerrors.NewValidation("chunk upload failed",
+		gerrors.FieldError{
+			Field:   "size",
+			Message: "non-final part is below the provider minimum part size",
+			Value:   map[string]any{"index": index, "size": size},
+		},
+	).WithCode(400).WithTextCode("CHUNK_PART_TOO_SMALL")
+}
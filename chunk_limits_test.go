@@ -0,0 +1,96 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	gerrors "github.com/goliatone/go-errors"
+)
+
+type limitedChunkUploader struct {
+	*mockChunkUploader
+	limits ChunkLimits
+}
+
+func (l *limitedChunkUploader) ChunkLimits() ChunkLimits {
+	return l.limits
+}
+
+func newLimitedManager(limits ChunkLimits, partSize int64) *Manager {
+	provider := &limitedChunkUploader{mockChunkUploader: newMockChunkUploader(), limits: limits}
+	return NewManager(WithProvider(provider), WithChunkPartSize(partSize))
+}
+
+func TestInitiateChunkedEnforcesLimits(t *testing.T) {
+	ctx := context.Background()
+	limits := ChunkLimits{MaxParts: 3, MinPartSize: 4}
+
+	_, err := newLimitedManager(limits, 4).InitiateChunked(ctx, "file.bin", 20)
+	if !gerrors.IsValidation(err) {
+		t.Fatalf("expected part limit validation error, got %v", err)
+	}
+
+	_, err = newLimitedManager(limits, 2).InitiateChunked(ctx, "file.bin", 8)
+	if !gerrors.IsValidation(err) {
+		t.Fatalf("expected part size validation error, got %v", err)
+	}
+
+	if _, err := newLimitedManager(limits, 4).InitiateChunked(ctx, "file.bin", 12); err != nil {
+		t.Fatalf("expected session within limits, got %v", err)
+	}
+}
+
+func TestUploadChunkEnforcesLimits(t *testing.T) {
+	ctx := context.Background()
+	manager := newLimitedManager(ChunkLimits{MaxParts: 10, MinPartSize: 4}, 4)
+
+	session, err := manager.InitiateChunked(ctx, "file.bin", 10)
+	if err != nil {
+		t.Fatalf("InitiateChunked failed: %v", err)
+	}
+
+	if err := manager.UploadChunk(ctx, session.ID, 3, bytes.NewReader([]byte("ab"))); !errors.Is(err, ErrChunkPartOutOfRange) {
+		t.Fatalf("expected out of range index, got %v", err)
+	}
+
+	if err := manager.UploadChunk(ctx, session.ID, 1, bytes.NewReader([]byte("abcd"))); err != nil {
+		t.Fatalf("UploadChunk failed: %v", err)
+	}
+
+	if err := manager.UploadChunk(ctx, session.ID, 0, bytes.NewReader([]byte("ab"))); !gerrors.IsValidation(err) {
+		t.Fatalf("expected undersized non-final part to be rejected, got %v", err)
+	}
+}
+
+func TestCompleteChunkedRejectsUndersizedParts(t *testing.T) {
+	ctx := context.Background()
+	manager := newLimitedManager(ChunkLimits{MaxParts: 10, MinPartSize: 4}, 4)
+
+	session, err := manager.InitiateChunked(ctx, "file.bin", 10)
+	if err != nil {
+		t.Fatalf("InitiateChunked failed: %v", err)
+	}
+
+	if err := manager.UploadChunk(ctx, session.ID, 0, bytes.NewReader([]byte("ab"))); err != nil {
+		t.Fatalf("UploadChunk failed: %v", err)
+	}
+
+	if err := manager.UploadChunk(ctx, session.ID, 1, bytes.NewReader([]byte("abcdefgh"))); err != nil {
+		t.Fatalf("UploadChunk failed: %v", err)
+	}
+
+	if _, err := manager.CompleteChunked(ctx, session.ID); !gerrors.IsValidation(err) {
+		t.Fatalf("expected completion to be rejected, got %v", err)
+	}
+}
+
+func TestWithChunkLimitsOverridesProvider(t *testing.T) {
+	manager := newLimitedManager(ChunkLimits{MaxParts: 1, MinPartSize: 1024}, 4)
+	WithChunkLimits(ChunkLimits{})(manager)
+
+	if _, err := manager.InitiateChunked(context.Background(), "file.bin", 100); err != nil {
+		t.Fatalf("expected override to disable limits, got %v", err)
+	}
+}
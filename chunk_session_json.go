@@ -0,0 +1,163 @@
+package uploader
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ChunkSessionSchemaVersion is the encoding version written by ChunkSession.MarshalJSON. Bump it
+// when a change to the session would be misread by older decoders.
+//
+// Version 0 is the untagged encoding json.Marshal produced before sessions had a schema (Go
+// field names such as "TotalSize"); it is still accepted when decoding.
+const ChunkSessionSchemaVersion = 1
+
+// chunkSessionFields has ChunkSession's fields and tags without its methods, so the JSON methods
+// can encode it without recursing.
+type chunkSessionFields ChunkSession
+
+type chunkSessionJSON struct {
+	SchemaVersion int `json:"schema_version"`
+	chunkSessionFields
+}
+
+// legacyChunkSession is the schema 0 layout.
+type legacyChunkSession struct {
+	ID            string
+	Key           string
+	TotalSize     int64
+	PartSize      int64
+	Metadata      *Metadata
+	CreatedAt     time.Time
+	ExpiresAt     time.Time
+	State         ChunkSessionState
+	Version       int64
+	UploadedParts map[int]ChunkPart
+	ProviderData  map[string]any
+}
+
+// MarshalJSON encodes the session with the current schema version.
+func (s ChunkSession) MarshalJSON() ([]byte, error) {
+	return json.Marshal(chunkSessionJSON{
+		SchemaVersion:      ChunkSessionSchemaVersion,
+		chunkSessionFields: chunkSessionFields(s),
+	})
+}
+
+// UnmarshalJSON decodes any schema up to ChunkSessionSchemaVersion and fails with
+// ErrChunkSessionSchemaUnsupported for newer ones, rather than dropping fields it does not know.
+func (s *ChunkSession) UnmarshalJSON(data []byte) error {
+	var probe struct {
+		SchemaVersion int `json:"schema_version"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return err
+	}
+
+	switch {
+	case probe.SchemaVersion == 0:
+		var legacy legacyChunkSession
+		if err := json.Unmarshal(data, &legacy); err != nil {
+			return err
+		}
+		*s = ChunkSession(legacy)
+		return nil
+	case probe.SchemaVersion > ChunkSessionSchemaVersion:
+		return fmt.Errorf("%w: version %d, newest known %d", ErrChunkSessionSchemaUnsupported, probe.SchemaVersion, ChunkSessionSchemaVersion)
+	}
+
+	var wire chunkSessionJSON
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+	*s = ChunkSession(wire.chunkSessionFields)
+	return nil
+}
+
+type chunkPartFields ChunkPart
+
+// MarshalJSON encodes the part, leaving out an unset upload time.
+func (p ChunkPart) MarshalJSON() ([]byte, error) {
+	var uploadedAt *time.Time
+	if !p.UploadedAt.IsZero() {
+		uploadedAt = &p.UploadedAt
+	}
+	return json.Marshal(struct {
+		chunkPartFields
+		UploadedAt *time.Time `json:"uploaded_at,omitempty"`
+	}{chunkPartFields: chunkPartFields(p), UploadedAt: uploadedAt})
+}
+
+// UnmarshalJSON decodes a part in the current layout or the schema 0 layout, whose only
+// differently named field is "UploadedAt".
+func (p *ChunkPart) UnmarshalJSON(data []byte) error {
+	var wire struct {
+		chunkPartFields
+		LegacyUploadedAt time.Time `json:"UploadedAt"`
+	}
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+
+	*p = ChunkPart(wire.chunkPartFields)
+	if p.UploadedAt.IsZero() {
+		p.UploadedAt = wire.LegacyUploadedAt
+	}
+	return nil
+}
+
+// MarshalChunkSession encodes session as JSON for a session store shared between instances.
+// ProviderData values that would not decode back to the same value are rejected rather than
+// silently changed.
+func MarshalChunkSession(session *ChunkSession) ([]byte, error) {
+	if session == nil {
+		return nil, fmt.Errorf("chunk session is nil")
+	}
+
+	for key, value := range session.ProviderData {
+		if !jsonSafe(value) {
+			return nil, fmt.Errorf("chunk session %s: provider data %q holds %T, which does not survive JSON encoding", session.ID, key, value)
+		}
+	}
+
+	return json.Marshal(session)
+}
+
+// UnmarshalChunkSession decodes a session encoded with MarshalChunkSession. Pass the result to
+// ChunkSessionStore.Restore to continue the upload on this instance.
+func UnmarshalChunkSession(data []byte) (*ChunkSession, error) {
+	var session ChunkSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, fmt.Errorf("decode chunk session: %w", err)
+	}
+
+	if session.ID == "" || session.Key == "" {
+		return nil, fmt.Errorf("decode chunk session: id and key are required")
+	}
+	return &session, nil
+}
+
+// jsonSafe reports whether v decodes from JSON into an equal value of the same type.
+func jsonSafe(v any) bool {
+	switch v := v.(type) {
+	case nil, string, bool, float64:
+		return true
+	case []any:
+		for _, item := range v {
+			if !jsonSafe(item) {
+				return false
+			}
+		}
+		return true
+	case map[string]any:
+		for _, item := range v {
+			if !jsonSafe(item) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
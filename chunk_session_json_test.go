@@ -0,0 +1,143 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+func TestChunkSessionContinuesOnAnotherInstance(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeS3Client{
+		createMultipartOutput:   &s3.CreateMultipartUploadOutput{UploadId: aws.String("upload-123")},
+		uploadPartOutput:        &s3.UploadPartOutput{ETag: aws.String("etag-0")},
+		completeMultipartOutput: &s3.CompleteMultipartUploadOutput{},
+	}
+
+	// Two instances with their own in-memory stores, talking to the same bucket.
+	newInstance := func() (*Manager, *ChunkSessionStore) {
+		provider := &AWSProvider{client: client, bucket: "test-bucket", logger: &DefaultLogger{}}
+		store := NewChunkSessionStore(time.Hour)
+		return NewManager(WithProvider(provider), WithChunkSessionStore(store)), store
+	}
+	first, firstStore := newInstance()
+	second, secondStore := newInstance()
+
+	shared := map[string][]byte{}
+	save := func(store *ChunkSessionStore, id string) {
+		t.Helper()
+		session, ok := store.Get(id)
+		if !ok {
+			t.Fatalf("session %s missing", id)
+		}
+		data, err := MarshalChunkSession(session)
+		if err != nil {
+			t.Fatalf("MarshalChunkSession: %v", err)
+		}
+		shared[id] = data
+	}
+	load := func(store *ChunkSessionStore, id string) {
+		t.Helper()
+		session, err := UnmarshalChunkSession(shared[id])
+		if err != nil {
+			t.Fatalf("UnmarshalChunkSession: %v", err)
+		}
+		if err := store.Restore(session); err != nil {
+			t.Fatalf("Restore: %v", err)
+		}
+	}
+
+	session, err := first.InitiateChunked(ctx, "videos/a.bin", 4, WithContentType("video/mp4"))
+	if err != nil {
+		t.Fatalf("InitiateChunked: %v", err)
+	}
+	save(firstStore, session.ID)
+
+	load(secondStore, session.ID)
+	if err := second.UploadChunk(ctx, session.ID, 0, bytes.NewReader([]byte("data"))); err != nil {
+		t.Fatalf("UploadChunk on second instance: %v", err)
+	}
+	save(secondStore, session.ID)
+
+	load(firstStore, session.ID)
+	meta, err := first.CompleteChunked(ctx, session.ID)
+	if err != nil {
+		t.Fatalf("CompleteChunked on first instance: %v", err)
+	}
+	if meta.ContentType != "video/mp4" {
+		t.Fatalf("expected metadata to survive the round trip, got %#v", meta)
+	}
+	if len(client.lastCompletedParts) != 1 || aws.ToString(client.lastCompletedParts[0].ETag) != "etag-0" {
+		t.Fatalf("expected part ETag carried across instances, got %#v", client.lastCompletedParts)
+	}
+}
+
+func TestMarshalChunkSessionRejectsLossyProviderData(t *testing.T) {
+	session := &ChunkSession{ID: "s1", Key: "a.bin", ProviderData: map[string]any{"parts": 3}}
+	if _, err := MarshalChunkSession(session); err == nil || !strings.Contains(err.Error(), `"parts"`) {
+		t.Fatalf("expected int provider data to be rejected, got %v", err)
+	}
+
+	session.ProviderData = map[string]any{"upload_id": "abc", "tags": []any{"x", 1.5}}
+	data, err := MarshalChunkSession(session)
+	if err != nil {
+		t.Fatalf("MarshalChunkSession: %v", err)
+	}
+	decoded, err := UnmarshalChunkSession(data)
+	if err != nil || decoded.ProviderData["upload_id"] != "abc" {
+		t.Fatalf("unexpected round trip %#v: %v", decoded, err)
+	}
+
+	if _, err := UnmarshalChunkSession([]byte(`{"id":"s1"}`)); err == nil {
+		t.Fatalf("expected missing key to be rejected")
+	}
+}
+
+func TestChunkSessionJSONSchema(t *testing.T) {
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	session := ChunkSession{
+		ID:            "s1",
+		Key:           "videos/a.bin",
+		TotalSize:     10,
+		CreatedAt:     created,
+		State:         ChunkSessionStateActive,
+		UploadedParts: map[int]ChunkPart{0: {Index: 0, Size: 5, ETag: "etag-0"}},
+	}
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if !strings.Contains(string(data), `"schema_version":1`) || strings.Contains(string(data), "uploaded_at") {
+		t.Fatalf("unexpected encoding %s", data)
+	}
+
+	var decoded ChunkSession
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if decoded.TotalSize != 10 || !decoded.CreatedAt.Equal(created) || decoded.UploadedParts[0].ETag != "etag-0" {
+		t.Fatalf("unexpected round trip %#v", decoded)
+	}
+
+	// Sessions persisted before the schema existed used Go field names.
+	legacy := `{"ID":"s0","Key":"old.bin","TotalSize":4,"State":"active","UploadedParts":{"0":{"Index":0,"Size":4,"ETag":"e","UploadedAt":"2024-01-01T12:00:00Z"}},"ProviderData":{"aws_upload_id":"u1"}}`
+	var old ChunkSession
+	if err := json.Unmarshal([]byte(legacy), &old); err != nil {
+		t.Fatalf("Unmarshal legacy: %v", err)
+	}
+	if old.TotalSize != 4 || old.ProviderData["aws_upload_id"] != "u1" || !old.UploadedParts[0].UploadedAt.Equal(created) {
+		t.Fatalf("unexpected legacy decode %#v", old)
+	}
+
+	if _, err := UnmarshalChunkSession([]byte(`{"schema_version":99,"id":"s2","key":"a"}`)); !errors.Is(err, ErrChunkSessionSchemaUnsupported) {
+		t.Fatalf("expected ErrChunkSessionSchemaUnsupported, got %v", err)
+	}
+}
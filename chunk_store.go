@@ -13,33 +13,60 @@ type ChunkSessionState string
 const (
 	// ChunkSessionStateActive indicates chunks may still be uploaded.
 	ChunkSessionStateActive ChunkSessionState = "active"
+	// ChunkSessionStateCompleting is set while the provider assembles the parts. No parts may be
+	// added and the session cannot be aborted until it completes or rolls back to active.
+	ChunkSessionStateCompleting ChunkSessionState = "completing"
 	// ChunkSessionStateCompleted is set after the finalization step succeeds.
 	ChunkSessionStateCompleted ChunkSessionState = "completed"
 	// ChunkSessionStateAborted is set when the session is canceled by the client or due to errors.
 	ChunkSessionStateAborted ChunkSessionState = "aborted"
+	// ChunkSessionStateExpired is reported by ListChunkSessions for sessions past ExpiresAt. It is
+	// never stored.
+	ChunkSessionStateExpired ChunkSessionState = "expired"
 )
 
+// chunkSessionTransitions enumerates the allowed state changes. Active may jump straight to
+// completed to keep MarkCompleted working for callers that do not use the completing stage.
+var chunkSessionTransitions = map[ChunkSessionState]map[ChunkSessionState]bool{
+	ChunkSessionStateActive: {
+		ChunkSessionStateCompleting: true,
+		ChunkSessionStateCompleted:  true,
+		ChunkSessionStateAborted:    true,
+	},
+	ChunkSessionStateCompleting: {
+		ChunkSessionStateCompleted: true,
+		ChunkSessionStateActive:    true,
+	},
+}
+
 // ChunkPart captures metadata for an uploaded chunk.
 type ChunkPart struct {
-	Index      int
-	Size       int64
-	Checksum   string
-	ETag       string
-	UploadedAt time.Time
+	Index      int       `json:"index"`
+	Size       int64     `json:"size"`
+	Checksum   string    `json:"checksum,omitempty"`
+	ETag       string    `json:"etag,omitempty"`
+	UploadedAt time.Time `json:"uploaded_at"`
 }
 
 // ChunkSession keeps track of multipart upload progress and provider-specific details.
+//
+// A session carries everything needed to continue the upload, so it can be persisted outside
+// the process (see MarshalChunkSession) and any instance can accept the next chunk. Providers
+// keep their continuation state, such as the S3 upload ID, in ProviderData and part receipts
+// such as ETags in UploadedParts. ProviderData values must survive a JSON round trip: strings,
+// booleans, float64 and nil, or slices and string-keyed maps of those.
 type ChunkSession struct {
-	ID            string
-	Key           string
-	TotalSize     int64
-	PartSize      int64
-	Metadata      *Metadata
-	CreatedAt     time.Time
-	ExpiresAt     time.Time
-	State         ChunkSessionState
-	UploadedParts map[int]ChunkPart
-	ProviderData  map[string]any
+	ID            string            `json:"id"`
+	Key           string            `json:"key"`
+	TotalSize     int64             `json:"total_size"`
+	PartSize      int64             `json:"part_size"`
+	Metadata      *Metadata         `json:"metadata,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+	ExpiresAt     time.Time         `json:"expires_at"`
+	State         ChunkSessionState `json:"state"`
+	Version       int64             `json:"version"`
+	UploadedParts map[int]ChunkPart `json:"uploaded_parts,omitempty"`
+	ProviderData  map[string]any    `json:"provider_data,omitempty"`
 }
 
 // ChunkSessionStore is an in-memory registry backed by a RWMutex. Implementation can be swapped later.
@@ -128,11 +155,43 @@ func (s *ChunkSessionStore) Create(session *ChunkSession) (*ChunkSession, error)
 	}
 
 	stored := cloneChunkSession(session)
+	stored.Version = 1
 	s.sessions[session.ID] = stored
 
 	return cloneChunkSession(stored), nil
 }
 
+// Restore stores a session loaded from an external store, replacing any local copy. Unlike
+// Create it keeps the session's state, version and expiry, so an instance can pick up a session
+// another instance started.
+func (s *ChunkSessionStore) Restore(session *ChunkSession) error {
+	if session == nil || session.ID == "" || session.Key == "" {
+		return gerrors.NewValidation("chunk session definition invalid",
+			gerrors.FieldError{
+				Field:   "session",
+				Message: "must have an id and a key",
+			},
+		)
+	}
+
+	// The clone shares empty maps with the caller; give the stored copy its own.
+	stored := cloneChunkSession(session)
+	if len(stored.UploadedParts) == 0 {
+		stored.UploadedParts = make(map[int]ChunkPart)
+	}
+	if len(stored.ProviderData) == 0 {
+		stored.ProviderData = make(map[string]any)
+	}
+	if stored.State == "" {
+		stored.State = ChunkSessionStateActive
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[stored.ID] = stored
+	return nil
+}
+
 // Get returns a copy of the session if it exists and has not expired.
 func (s *ChunkSessionStore) Get(id string) (*ChunkSession, bool) {
 	s.mu.RLock()
@@ -189,11 +248,48 @@ func (s *ChunkSessionStore) AddPart(id string, part ChunkPart) (*ChunkSession, e
 	}
 
 	session.UploadedParts[part.Index] = part
+	session.Version++
+
+	return cloneChunkSession(session), nil
+}
+
+// Extend pushes the expiry of an active session to ttl from now (the store TTL when ttl <= 0),
+// capped at maxLifetime after creation when maxLifetime > 0. It returns
+// ErrChunkSessionLifetimeExceeded when the cap leaves nothing to extend.
+func (s *ChunkSessionStore) Extend(id string, ttl, maxLifetime time.Duration) (*ChunkSession, error) {
+	if ttl <= 0 {
+		ttl = s.ttl
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	now := s.timeNow()
+	if !ok || now.After(session.ExpiresAt) {
+		return nil, ErrChunkSessionNotFound
+	}
+
+	if session.State != ChunkSessionStateActive {
+		return nil, ErrChunkSessionClosed
+	}
 
+	expiresAt := now.Add(ttl)
+	if maxLifetime > 0 {
+		if limit := session.CreatedAt.Add(maxLifetime); expiresAt.After(limit) {
+			expiresAt = limit
+		}
+	}
+	if !expiresAt.After(session.ExpiresAt) {
+		return nil, ErrChunkSessionLifetimeExceeded
+	}
+
+	session.ExpiresAt = expiresAt
+	session.Version++
 	return cloneChunkSession(session), nil
 }
 
-// MarkCompleted flags a session as completed if it is active.
+// MarkCompleted flags a session as completed if it is active or completing.
 func (s *ChunkSessionStore) MarkCompleted(id string) (*ChunkSession, error) {
 	return s.updateState(id, ChunkSessionStateCompleted)
 }
@@ -203,6 +299,25 @@ func (s *ChunkSessionStore) MarkAborted(id string) (*ChunkSession, error) {
 	return s.updateState(id, ChunkSessionStateAborted)
 }
 
+// Transition moves a session to state when its current version matches version (compare and
+// swap). It returns ErrChunkSessionConflict when the session changed since it was read and
+// ErrChunkSessionClosed when the state machine does not allow the change.
+func (s *ChunkSessionStore) Transition(id string, version int64, state ChunkSessionState) (*ChunkSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok || s.timeNow().After(session.ExpiresAt) {
+		return nil, ErrChunkSessionNotFound
+	}
+
+	if session.Version != version {
+		return nil, ErrChunkSessionConflict
+	}
+
+	return s.transitionLocked(session, state)
+}
+
 func (s *ChunkSessionStore) updateState(id string, newState ChunkSessionState) (*ChunkSession, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -212,11 +327,45 @@ func (s *ChunkSessionStore) updateState(id string, newState ChunkSessionState) (
 		return nil, ErrChunkSessionNotFound
 	}
 
-	if session.State != ChunkSessionStateActive {
+	return s.transitionLocked(session, newState)
+}
+
+func (s *ChunkSessionStore) transitionLocked(session *ChunkSession, state ChunkSessionState) (*ChunkSession, error) {
+	if !chunkSessionTransitions[session.State][state] {
 		return nil, ErrChunkSessionClosed
 	}
 
-	session.State = newState
+	session.State = state
+	session.Version++
+	return cloneChunkSession(session), nil
+}
+
+// List returns copies of every stored session, including expired ones that have not been
+// cleaned up yet.
+func (s *ChunkSessionStore) List() []*ChunkSession {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]*ChunkSession, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		out = append(out, cloneChunkSession(session))
+	}
+	return out
+}
+
+// ForceAbort marks a session aborted regardless of its state, version or expiry. It is meant for
+// operators cleaning up stuck sessions.
+func (s *ChunkSessionStore) ForceAbort(id string) (*ChunkSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions[id]
+	if !ok {
+		return nil, ErrChunkSessionNotFound
+	}
+
+	session.State = ChunkSessionStateAborted
+	session.Version++
 	return cloneChunkSession(session), nil
 }
 
@@ -244,6 +393,7 @@ func cloneChunkSession(in *ChunkSession) *ChunkSession {
 	out := *in
 	if in.Metadata != nil {
 		metaCopy := *in.Metadata
+		metaCopy.Attributes = mergeAttributes(in.Metadata.Attributes)
 		out.Metadata = &metaCopy
 	}
 	if len(in.UploadedParts) > 0 {
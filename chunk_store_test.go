@@ -1,6 +1,7 @@
 package uploader
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 	"time"
@@ -131,3 +132,47 @@ func TestChunkSessionStoreCleanupExpired(t *testing.T) {
 		t.Fatalf("expected active session to remain")
 	}
 }
+
+func TestChunkSessionStoreTransition(t *testing.T) {
+	store := NewChunkSessionStore(time.Hour)
+
+	session, err := store.Create(&ChunkSession{ID: "s", Key: "file"})
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+
+	if session.Version != 1 {
+		t.Fatalf("expected initial version 1, got %d", session.Version)
+	}
+
+	updated, err := store.AddPart("s", ChunkPart{Index: 0, Size: 1})
+	if err != nil {
+		t.Fatalf("AddPart failed: %v", err)
+	}
+
+	if updated.Version != 2 {
+		t.Fatalf("expected AddPart to bump version, got %d", updated.Version)
+	}
+
+	if _, err := store.Transition("s", session.Version, ChunkSessionStateCompleting); !errors.Is(err, ErrChunkSessionConflict) {
+		t.Fatalf("expected conflict for stale version, got %v", err)
+	}
+
+	completing, err := store.Transition("s", updated.Version, ChunkSessionStateCompleting)
+	if err != nil {
+		t.Fatalf("Transition failed: %v", err)
+	}
+
+	if _, err := store.AddPart("s", ChunkPart{Index: 1}); !errors.Is(err, ErrChunkSessionClosed) {
+		t.Fatalf("expected parts to be rejected while completing, got %v", err)
+	}
+
+	if _, err := store.Transition("s", completing.Version, ChunkSessionStateAborted); !errors.Is(err, ErrChunkSessionClosed) {
+		t.Fatalf("expected abort to be rejected while completing, got %v", err)
+	}
+
+	done, err := store.Transition("s", completing.Version, ChunkSessionStateCompleted)
+	if err != nil || done.State != ChunkSessionStateCompleted {
+		t.Fatalf("expected completed session, got %#v (%v)", done, err)
+	}
+}
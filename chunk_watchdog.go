@@ -0,0 +1,98 @@
+package uploader
+
+import (
+	"context"
+	"time"
+)
+
+// WithAbortOnCancel ties a chunked session to the context passed to InitiateChunked: if that
+// context ends before the session completes, the session is aborted and the provider releases
+// its staged parts. Use it when one caller drives the whole upload; sessions spread over several
+// requests should rely on RunChunkWatchdog instead.
+func WithAbortOnCancel() UploadOption {
+	return func(m *Metadata) { m.AbortOnCancel = true }
+}
+
+// AbortChunkedOnCancel aborts the session when ctx ends, unless it has completed or been aborted
+// by then. Call the returned function to unlink the session; it reports whether the abort was
+// still pending.
+func (m *Manager) AbortChunkedOnCancel(ctx context.Context, sessionID string) (stop func() bool) {
+	return context.AfterFunc(ctx, func() {
+		m.abortLinkedSession(context.WithoutCancel(ctx), sessionID)
+	})
+}
+
+func (m *Manager) abortLinkedSession(ctx context.Context, sessionID string) {
+	session, ok := m.ensureChunkStore().Get(sessionID)
+	if !ok || session.State != ChunkSessionStateActive {
+		return
+	}
+
+	if err := m.AbortChunked(ctx, sessionID); err != nil {
+		m.log(ctx).Error("abort cancelled chunk session failed", err, "session_id", sessionID, "key", session.Key)
+	}
+}
+
+// AbortExpiredChunks aborts sessions past their expiry that never completed, asking the provider
+// to release staged parts (such as S3 multipart uploads), and drops every expired session from
+// the store. It returns how many sessions were aborted.
+func (m *Manager) AbortExpiredChunks(ctx context.Context) (int, error) {
+	if err := m.ensureProvider(ctx); err != nil {
+		return 0, err
+	}
+
+	store := m.ensureChunkStore()
+	now := store.timeNow()
+
+	aborted := 0
+	for _, session := range store.List() {
+		if err := ctx.Err(); err != nil {
+			return aborted, err
+		}
+		if now.Before(session.ExpiresAt) {
+			continue
+		}
+
+		if session.State == ChunkSessionStateActive || session.State == ChunkSessionStateCompleting {
+			chunkProvider, err := m.chunkedProvider(sessionRouteContext(ctx, session), session.Key)
+			if err != nil {
+				return aborted, err
+			}
+
+			err = m.observeErr(ctx, "abort_chunked", session.Key, func() error {
+				return chunkProvider.AbortChunked(ctx, session)
+			})
+			if err != nil {
+				// Keep the session so the next sweep retries.
+				m.log(ctx).Error("abort expired chunk session failed", err, "session_id", session.ID, "key", session.Key)
+				continue
+			}
+			m.emitEvent(ctx, &ChunkSessionAborted{SessionID: session.ID, Key: session.Key})
+			aborted++
+		}
+		store.Delete(session.ID)
+	}
+	return aborted, nil
+}
+
+// RunChunkWatchdog calls AbortExpiredChunks every interval until ctx ends. Run it in its own
+// goroutine on one instance per session store.
+func (m *Manager) RunChunkWatchdog(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultChunkWatchdogInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := m.AbortExpiredChunks(ctx); err != nil && ctx.Err() == nil {
+				m.log(ctx).Error("chunk watchdog sweep failed", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWithAbortOnCancel(t *testing.T) {
+	provider := newMemoryProvider()
+	aborted := make(chan string, 1)
+	manager := NewManager(WithProvider(provider), WithEventHandler(func(_ context.Context, event Event) {
+		if e, ok := event.(*ChunkSessionAborted); ok {
+			aborted <- e.SessionID
+		}
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	session, err := manager.InitiateChunked(ctx, "chunks/file.bin", 8, WithAbortOnCancel())
+	if err != nil {
+		t.Fatalf("InitiateChunked: %v", err)
+	}
+	if err := manager.UploadChunk(ctx, session.ID, 0, bytes.NewReader([]byte("abcd"))); err != nil {
+		t.Fatalf("UploadChunk: %v", err)
+	}
+
+	cancel()
+	select {
+	case id := <-aborted:
+		if id != session.ID {
+			t.Fatalf("unexpected aborted session %q", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("expected session aborted after cancellation")
+	}
+
+	if _, ok := provider.sessions[session.ID]; ok {
+		t.Fatalf("expected provider session released")
+	}
+	info, err := manager.ChunkSession(context.Background(), session.ID)
+	if err != nil || info.State != ChunkSessionStateAborted {
+		t.Fatalf("expected aborted session, got %#v (%v)", info, err)
+	}
+}
+
+func TestAbortChunkedOnCancelSkipsCompletedSessions(t *testing.T) {
+	provider := newMemoryProvider()
+	manager := NewManager(WithProvider(provider))
+
+	session, err := manager.InitiateChunked(context.Background(), "chunks/file.bin", 4)
+	if err != nil {
+		t.Fatalf("InitiateChunked: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stop := manager.AbortChunkedOnCancel(ctx, session.ID)
+	defer stop()
+
+	if err := manager.UploadChunk(ctx, session.ID, 0, bytes.NewReader([]byte("abcd"))); err != nil {
+		t.Fatalf("UploadChunk: %v", err)
+	}
+	if _, err := manager.CompleteChunked(ctx, session.ID); err != nil {
+		t.Fatalf("CompleteChunked: %v", err)
+	}
+
+	// The abort runs synchronously here: the session is already completed.
+	manager.abortLinkedSession(context.Background(), session.ID)
+	cancel()
+
+	if string(provider.files["chunks/file.bin"]) != "abcd" {
+		t.Fatalf("expected completed upload kept")
+	}
+}
+
+func TestAbortExpiredChunks(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	provider := newMemoryProvider()
+	manager := NewManager(WithProvider(provider), WithClock(func() time.Time { return now }))
+
+	stale, err := manager.InitiateChunked(ctx, "chunks/stale.bin", 8)
+	if err != nil {
+		t.Fatalf("InitiateChunked: %v", err)
+	}
+	now = now.Add(DefaultChunkSessionTTL + time.Minute)
+	fresh, err := manager.InitiateChunked(ctx, "chunks/fresh.bin", 8)
+	if err != nil {
+		t.Fatalf("InitiateChunked: %v", err)
+	}
+
+	aborted, err := manager.AbortExpiredChunks(ctx)
+	if err != nil {
+		t.Fatalf("AbortExpiredChunks: %v", err)
+	}
+	if aborted != 1 {
+		t.Fatalf("expected one aborted session, got %d", aborted)
+	}
+	if _, ok := provider.sessions[stale.ID]; ok {
+		t.Fatalf("expected provider session of expired upload released")
+	}
+	if _, ok := provider.sessions[fresh.ID]; !ok {
+		t.Fatalf("expected live session untouched")
+	}
+	if sessions, _ := manager.ListChunkSessions(ctx, ChunkSessionFilter{}); len(sessions) != 1 {
+		t.Fatalf("expected expired session dropped from the store, got %#v", sessions)
+	}
+}
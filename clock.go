@@ -0,0 +1,44 @@
+package uploader
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WithClock replaces time.Now for object names, upload timestamps and expiry checks, including
+// those of the built-in chunk session and idempotency stores. Providers keep their own clocks.
+func WithClock(clock func() time.Time) Option {
+	return func(m *Manager) {
+		m.clock = clock
+	}
+}
+
+// WithIDGenerator replaces the random UUIDs used as chunk session IDs. Generated IDs must be
+// unique across the sessions a store holds.
+func WithIDGenerator(generate func() string) Option {
+	return func(m *Manager) {
+		m.idGenerator = generate
+	}
+}
+
+func (m *Manager) now() time.Time {
+	if m.clock != nil {
+		return m.clock()
+	}
+	return time.Now()
+}
+
+func (m *Manager) newID() string {
+	if m.idGenerator != nil {
+		return m.idGenerator()
+	}
+	return uuid.NewString()
+}
+
+// bindChunkStoreClock points the chunk store at the manager clock when one is configured.
+func (m *Manager) bindChunkStoreClock() {
+	if m.clock != nil && m.chunkStore != nil {
+		m.chunkStore.timeNowFn = m.clock
+	}
+}
@@ -0,0 +1,48 @@
+package uploader
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestManagerClockAndIDGenerator(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	manager := NewManager(
+		WithProvider(NewFSProvider(t.TempDir())),
+		WithClock(func() time.Time { return now }),
+		WithIDGenerator(func() string { return "session-1" }),
+	)
+
+	meta, err := manager.HandleFile(ctx, newTestFileHeader(t, "file", "a.png", "image/png", createTestPNG(2, 2)), "images")
+	if err != nil {
+		t.Fatalf("HandleFile failed: %v", err)
+	}
+
+	if want := "images/" + strconv.FormatInt(now.UnixMicro(), 10) + ".png"; meta.Name != want {
+		t.Fatalf("expected name %s, got %s", want, meta.Name)
+	}
+	if !meta.UploadedAt.Equal(now) {
+		t.Fatalf("expected upload time from clock, got %s", meta.UploadedAt)
+	}
+
+	session, err := manager.InitiateChunked(ctx, "big.bin", 1024)
+	if err != nil {
+		t.Fatalf("InitiateChunked failed: %v", err)
+	}
+
+	if session.ID != "session-1" {
+		t.Fatalf("expected generated session id, got %s", session.ID)
+	}
+	if !session.ExpiresAt.Equal(now.Add(DefaultChunkSessionTTL)) {
+		t.Fatalf("expected expiry from clock, got %s", session.ExpiresAt)
+	}
+
+	now = now.Add(DefaultChunkSessionTTL + time.Minute)
+	if _, err := manager.getChunkSession(session.ID); err == nil {
+		t.Fatalf("expected session to expire with the injected clock")
+	}
+}
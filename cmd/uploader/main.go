@@ -0,0 +1,368 @@
+// Command uploader exercises a go-uploader provider from the command line so operators can
+// test buckets, move objects between providers and clean up abandoned uploads.
+//
+// Providers are configured with a DSN, passed via -dsn or the UPLOADER_DSN env var:
+//
+//	uploader -dsn fs:///var/uploads ls images/
+//	uploader -dsn s3://bucket/base?region=eu-west-1 upload ./logo.png brand/logo.png
+//	uploader -dsn fs:///var/uploads migrate -to s3://bucket/base images/
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/goliatone/go-uploader"
+)
+
+const usage = `usage: uploader [-dsn DSN] <command> [args]
+
+commands:
+  upload [-content-type TYPE] <file> <key>   upload a local file
+  get <key> [output]                         download an object (stdout when output is omitted)
+  rm <key>...                                delete objects
+  ls [prefix]                                list objects
+  presign [-ttl DURATION] <key>              print a presigned download URL
+  migrate -to DSN [prefix]                   copy objects to another provider
+  gc [-older-than DURATION]                  abort stale chunked uploads
+  thumbs -size NAME:WxH[:FIT]... [prefix]    regenerate missing or outdated thumbnails
+`
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "uploader:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("uploader", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() { fmt.Fprint(stderr, usage) }
+	dsn := flags.String("dsn", os.Getenv("UPLOADER_DSN"), "provider DSN")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return errors.New("missing command")
+	}
+
+	manager, err := newManager(ctx, *dsn)
+	if err != nil {
+		return err
+	}
+
+	cmd, cmdArgs := flags.Arg(0), flags.Args()[1:]
+	switch cmd {
+	case "upload":
+		return runUpload(ctx, manager, cmdArgs, stdout, stderr)
+	case "get":
+		return runGet(ctx, manager, cmdArgs, stdout)
+	case "rm":
+		return runRemove(ctx, manager, cmdArgs)
+	case "ls":
+		return runList(ctx, manager, cmdArgs, stdout)
+	case "presign":
+		return runPresign(ctx, manager, cmdArgs, stdout, stderr)
+	case "migrate":
+		return runMigrate(ctx, manager, cmdArgs, stdout, stderr)
+	case "gc":
+		return runGC(ctx, manager, cmdArgs, stdout, stderr)
+	case "thumbs":
+		return runThumbnails(ctx, manager, cmdArgs, stdout, stderr)
+	}
+
+	flags.Usage()
+	return fmt.Errorf("unknown command %q", cmd)
+}
+
+func newManager(ctx context.Context, dsn string) (*uploader.Manager, error) {
+	if dsn == "" {
+		return nil, errors.New("provider DSN required (-dsn or UPLOADER_DSN)")
+	}
+
+	provider, err := uploader.NewProviderFromDSN(dsn, s3ClientFactory(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	return uploader.NewManager(uploader.WithProvider(provider)), nil
+}
+
+func s3ClientFactory(ctx context.Context) uploader.S3ClientFactory {
+	return func(dsn *uploader.ProviderDSN) (*s3.Client, error) {
+		var opts []func(*config.LoadOptions) error
+		if region := dsn.Region(); region != "" {
+			opts = append(opts, config.WithRegion(region))
+		}
+
+		cfg, err := config.LoadDefaultConfig(ctx, opts...)
+		if err != nil {
+			return nil, err
+		}
+
+		return s3.NewFromConfig(cfg, func(o *s3.Options) {
+			if endpoint := dsn.Params.Get("endpoint"); endpoint != "" {
+				o.BaseEndpoint = aws.String(endpoint)
+				o.UsePathStyle = true
+			}
+		}), nil
+	}
+}
+
+func runUpload(ctx context.Context, manager *uploader.Manager, args []string, stdout, stderr io.Writer) error {
+	flags := newCommandFlags("upload", stderr)
+	contentType := flags.String("content-type", "", "content type (detected when empty)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 2 {
+		return errors.New("upload: expected <file> <key>")
+	}
+
+	content, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("upload: %w", err)
+	}
+
+	key := flags.Arg(1)
+	if *contentType == "" {
+		*contentType = detectContentType(key, content)
+	}
+
+	url, err := manager.UploadFile(ctx, key, content, uploader.WithContentType(*contentType))
+	if err != nil {
+		return fmt.Errorf("upload: %w", err)
+	}
+
+	fmt.Fprintln(stdout, url)
+	return nil
+}
+
+func runGet(ctx context.Context, manager *uploader.Manager, args []string, stdout io.Writer) error {
+	if len(args) < 1 || len(args) > 2 {
+		return errors.New("get: expected <key> [output]")
+	}
+
+	content, err := manager.GetFile(ctx, args[0])
+	if err != nil {
+		return fmt.Errorf("get: %w", err)
+	}
+
+	if len(args) == 1 || args[1] == "-" {
+		_, err = stdout.Write(content)
+		return err
+	}
+
+	return os.WriteFile(args[1], content, 0o644)
+}
+
+func runRemove(ctx context.Context, manager *uploader.Manager, args []string) error {
+	if len(args) == 0 {
+		return errors.New("rm: expected at least one key")
+	}
+
+	for _, key := range args {
+		if err := manager.DeleteFile(ctx, key); err != nil {
+			return fmt.Errorf("rm %s: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+func runList(ctx context.Context, manager *uploader.Manager, args []string, stdout io.Writer) error {
+	if len(args) > 1 {
+		return errors.New("ls: expected [prefix]")
+	}
+
+	prefix := ""
+	if len(args) == 1 {
+		prefix = args[0]
+	}
+
+	objects, err := manager.List(ctx, prefix)
+	if err != nil {
+		return fmt.Errorf("ls: %w", err)
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	for _, obj := range objects {
+		fmt.Fprintf(w, "%d\t%s\t%s\n", obj.Size, obj.ModTime.UTC().Format(time.RFC3339), obj.Key)
+	}
+	return w.Flush()
+}
+
+func runPresign(ctx context.Context, manager *uploader.Manager, args []string, stdout, stderr io.Writer) error {
+	flags := newCommandFlags("presign", stderr)
+	ttl := flags.Duration("ttl", 15*time.Minute, "URL lifetime")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return errors.New("presign: expected <key>")
+	}
+
+	url, err := manager.GetPresignedURL(ctx, flags.Arg(0), *ttl)
+	if err != nil {
+		return fmt.Errorf("presign: %w", err)
+	}
+
+	fmt.Fprintln(stdout, url)
+	return nil
+}
+
+func runMigrate(ctx context.Context, manager *uploader.Manager, args []string, stdout, stderr io.Writer) error {
+	flags := newCommandFlags("migrate", stderr)
+	to := flags.String("to", "", "destination provider DSN")
+	dryRun := flags.Bool("dry-run", false, "list objects without copying")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *to == "" || flags.NArg() > 1 {
+		return errors.New("migrate: expected -to DSN [prefix]")
+	}
+
+	dest, err := newManager(ctx, *to)
+	if err != nil {
+		return fmt.Errorf("migrate: %w", err)
+	}
+
+	objects, err := manager.List(ctx, flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("migrate: %w", err)
+	}
+
+	for _, obj := range objects {
+		if *dryRun {
+			fmt.Fprintln(stdout, obj.Key)
+			continue
+		}
+
+		content, err := manager.GetFile(ctx, obj.Key)
+		if err != nil {
+			return fmt.Errorf("migrate %s: %w", obj.Key, err)
+		}
+
+		if _, err := dest.UploadFile(ctx, obj.Key, content, uploader.WithContentType(detectContentType(obj.Key, content))); err != nil {
+			return fmt.Errorf("migrate %s: %w", obj.Key, err)
+		}
+
+		fmt.Fprintln(stdout, obj.Key)
+	}
+
+	return nil
+}
+
+func runGC(ctx context.Context, manager *uploader.Manager, args []string, stdout, stderr io.Writer) error {
+	flags := newCommandFlags("gc", stderr)
+	olderThan := flags.Duration("older-than", 24*time.Hour, "minimum age of uploads to reclaim")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	removed, err := manager.CollectGarbage(ctx, time.Now().Add(-*olderThan))
+	if err != nil {
+		return fmt.Errorf("gc: %w", err)
+	}
+
+	fmt.Fprintf(stdout, "reclaimed %d incomplete uploads\n", removed)
+	return nil
+}
+
+func runThumbnails(ctx context.Context, manager *uploader.Manager, args []string, stdout, stderr io.Writer) error {
+	flags := newCommandFlags("thumbs", stderr)
+	var sizes sizeFlags
+	flags.Var(&sizes, "size", "thumbnail size as NAME:WxH[:FIT] (repeatable)")
+	workers := flags.Int("workers", uploader.DefaultThumbnailWorkers, "concurrent originals")
+	force := flags.Bool("force", false, "regenerate existing derivatives")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() > 1 {
+		return errors.New("thumbs: expected [prefix]")
+	}
+
+	result, err := manager.RegenerateThumbnails(ctx, flags.Arg(0), sizes, uploader.RegenerateThumbnailsOptions{
+		Workers: *workers,
+		Force:   *force,
+		Progress: func(p uploader.ThumbnailProgress) {
+			if p.Err != nil {
+				fmt.Fprintf(stderr, "[%d/%d] %s: %v\n", p.Processed, p.Total, p.Key, p.Err)
+				return
+			}
+			fmt.Fprintf(stdout, "[%d/%d] %s: %d generated\n", p.Processed, p.Total, p.Key, len(p.Generated))
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("thumbs: %w", err)
+	}
+
+	fmt.Fprintf(stdout, "scanned %d, processed %d, generated %d, failed %d\n",
+		result.Scanned, result.Processed, result.Generated, len(result.Failures))
+	if len(result.Failures) > 0 {
+		return fmt.Errorf("thumbs: %d originals failed", len(result.Failures))
+	}
+	return nil
+}
+
+type sizeFlags []uploader.ThumbnailSize
+
+func (s *sizeFlags) String() string {
+	return fmt.Sprint(*s)
+}
+
+func (s *sizeFlags) Set(value string) error {
+	parts := strings.Split(value, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return fmt.Errorf("invalid size %q, expected NAME:WxH[:FIT]", value)
+	}
+
+	size := uploader.ThumbnailSize{Name: parts[0], Fit: "cover"}
+	if _, err := fmt.Sscanf(parts[1], "%dx%d", &size.Width, &size.Height); err != nil {
+		return fmt.Errorf("invalid dimensions %q: %w", parts[1], err)
+	}
+	if len(parts) == 3 {
+		size.Fit = parts[2]
+	}
+
+	*s = append(*s, size)
+	return nil
+}
+
+func newCommandFlags(name string, stderr io.Writer) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	return flags
+}
+
+func detectContentType(key string, content []byte) string {
+	if byExt := mime.TypeByExtension(filepath.Ext(key)); byExt != "" {
+		return byExt
+	}
+	return http.DetectContentType(content)
+}
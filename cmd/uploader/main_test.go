@@ -0,0 +1,82 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	src := t.TempDir()
+	dst := t.TempDir()
+
+	local := filepath.Join(t.TempDir(), "note.txt")
+	if err := os.WriteFile(local, []byte("hello"), 0o644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	dsn := "fs://" + src
+	exec := func(args ...string) string {
+		t.Helper()
+		var stdout, stderr bytes.Buffer
+		if err := run(ctx, append([]string{"-dsn", dsn}, args...), &stdout, &stderr); err != nil {
+			t.Fatalf("run %v failed: %v (%s)", args, err, stderr.String())
+		}
+		return stdout.String()
+	}
+
+	exec("upload", local, "docs/note.txt")
+
+	if out := exec("get", "docs/note.txt"); out != "hello" {
+		t.Fatalf("expected file content, got %q", out)
+	}
+
+	if out := exec("ls", "docs/"); !strings.Contains(out, "docs/note.txt") {
+		t.Fatalf("expected listing to include key, got %q", out)
+	}
+
+	exec("migrate", "-to", "fs://"+dst, "docs/")
+	if content, err := os.ReadFile(filepath.Join(dst, "docs", "note.txt")); err != nil || string(content) != "hello" {
+		t.Fatalf("expected migrated file, got %q (%v)", content, err)
+	}
+
+	exec("rm", "docs/note.txt")
+	if _, err := os.Stat(filepath.Join(src, "docs", "note.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected file to be removed")
+	}
+
+	if out := exec("gc"); !strings.Contains(out, "reclaimed 0") {
+		t.Fatalf("unexpected gc output: %q", out)
+	}
+}
+
+func TestSizeFlags(t *testing.T) {
+	var sizes sizeFlags
+	if err := sizes.Set("small:64x48"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := sizes.Set("wide:320x200:contain"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	if len(sizes) != 2 || sizes[0].Width != 64 || sizes[0].Height != 48 || sizes[0].Fit != "cover" || sizes[1].Fit != "contain" {
+		t.Fatalf("unexpected sizes: %#v", sizes)
+	}
+
+	if err := sizes.Set("broken"); err == nil {
+		t.Fatalf("expected error for malformed size")
+	}
+}
+
+func TestRunRequiresDSN(t *testing.T) {
+	t.Setenv("UPLOADER_DSN", "")
+
+	var stdout, stderr bytes.Buffer
+	if err := run(context.Background(), []string{"ls"}, &stdout, &stderr); err == nil {
+		t.Fatalf("expected error without dsn")
+	}
+}
@@ -0,0 +1,146 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"path/filepath"
+	"strings"
+)
+
+// CollisionPolicy decides what happens when an upload targets a key that already exists.
+type CollisionPolicy string
+
+const (
+	// CollisionOverwrite replaces the existing object (the default).
+	CollisionOverwrite CollisionPolicy = "overwrite"
+	// CollisionError rejects the upload with ErrFileExists.
+	CollisionError CollisionPolicy = "error"
+	// CollisionSuffix stores the upload under the first free "name-N.ext" key.
+	CollisionSuffix CollisionPolicy = "suffix"
+	// CollisionVersion copies the existing object to "name.vUNIXNANO.ext" before replacing it.
+	CollisionVersion CollisionPolicy = "version"
+)
+
+// WithCollisionPolicy sets how UploadFile, UploadLocalFile and HandleFile treat existing keys.
+// Existence is checked with conditional writes when the provider implements ConditionalWriter,
+// with StatFile otherwise. Thumbnails and content-addressed names always overwrite, since their
+// keys are derived from the original.
+func WithCollisionPolicy(policy CollisionPolicy) Option {
+	return func(m *Manager) {
+		m.collisionPolicy = policy
+	}
+}
+
+// storeFunc writes the payload being uploaded under key.
+type storeFunc func(key string, opts ...UploadOption) (string, error)
+
+// storeWithCollisionPolicy writes through store under key, or under the key picked by the
+// collision policy. The returned result carries the key, URL, retries and previous version.
+func (m *Manager) storeWithCollisionPolicy(ctx context.Context, key string, store storeFunc, opts ...UploadOption) (*UploadResult, error) {
+	result := &UploadResult{Key: key}
+
+	var err error
+	switch m.collisionPolicy {
+	case CollisionError:
+		result.URL, err = m.storeIfNotExists(ctx, key, store, opts)
+		return result, err
+	case CollisionSuffix:
+		return m.storeWithSuffix(ctx, key, store, opts)
+	case CollisionVersion:
+		if result.PreviousVersion, err = m.versionExisting(ctx, key); err != nil {
+			return nil, err
+		}
+	}
+
+	result.URL, err = store(key, opts...)
+	return result, err
+}
+
+// storeGenerated stores an object under a generated name. Content-addressed names skip the
+// policy: an existing key already holds the same bytes.
+func (m *Manager) storeGenerated(ctx context.Context, key string, store storeFunc, opts ...UploadOption) (*UploadResult, error) {
+	if m.contentAddressed() {
+		url, err := store(key, opts...)
+		return &UploadResult{Key: key, URL: url}, err
+	}
+	return m.storeWithCollisionPolicy(ctx, key, store, opts...)
+}
+
+func (m *Manager) storeWithSuffix(ctx context.Context, key string, store storeFunc, opts []UploadOption) (*UploadResult, error) {
+	ext := filepath.Ext(key)
+	base := strings.TrimSuffix(key, ext)
+
+	candidate := key
+	for attempt := 1; attempt <= DefaultCollisionMaxSuffix; attempt++ {
+		url, err := m.storeIfNotExists(ctx, candidate, store, opts)
+		if !errors.Is(err, ErrFileExists) {
+			return &UploadResult{Key: candidate, URL: url, Retries: attempt - 1}, err
+		}
+		candidate = fmt.Sprintf("%s-%d%s", base, attempt, ext)
+	}
+
+	return nil, ErrFileExists
+}
+
+// storeIfNotExists writes key only when it is free. Providers without conditional writes are
+// checked first, which leaves a small window for a concurrent upload to win.
+func (m *Manager) storeIfNotExists(ctx context.Context, key string, store storeFunc, opts []UploadOption) (string, error) {
+	if writer, ok := m.providerFor(ctx, key).(ConditionalWriter); ok && writer.ConditionalWrites() {
+		return store(key, append(opts, WithIfNotExists())...)
+	}
+
+	exists, err := m.fileExists(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	if exists {
+		return "", ErrFileExists
+	}
+
+	return store(key, opts...)
+}
+
+// versionExisting copies the current object at key, if any, to a timestamped key so the upload
+// can replace it, and returns the version key.
+func (m *Manager) versionExisting(ctx context.Context, key string) (string, error) {
+	content, err := observeCall(ctx, m, "get", key, func() ([]byte, error) {
+		return m.providerFor(ctx, key).GetFile(ctx, key)
+	})
+	if errors.Is(err, ErrImageNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("version %s: %w", key, err)
+	}
+
+	ext := filepath.Ext(key)
+	versionKey := fmt.Sprintf("%s.v%d%s", strings.TrimSuffix(key, ext), m.now().UnixNano(), ext)
+	// The version is the only copy of the replaced object, so a transaction rollback keeps it.
+	if _, err := m.putFile(withoutUploadTx(ctx), versionKey, content, WithContentType(mime.TypeByExtension(ext)), WithStorageClass(m.storageClass)); err != nil {
+		return "", fmt.Errorf("version %s: %w", key, err)
+	}
+
+	return versionKey, nil
+}
+
+// fileExists prefers StatFile and falls back to downloading the object.
+func (m *Manager) fileExists(ctx context.Context, key string) (bool, error) {
+	var err error
+	provider := m.providerFor(ctx, key)
+	if statter, ok := provider.(FileStatter); ok {
+		_, err = observeCall(ctx, m, "stat", key, func() (*ObjectInfo, error) {
+			return statter.StatFile(ctx, key)
+		})
+	} else {
+		_, err = observeCall(ctx, m, "get", key, func() ([]byte, error) {
+			return provider.GetFile(ctx, key)
+		})
+	}
+
+	if errors.Is(err, ErrImageNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,152 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCollisionPolicies(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("overwrite", func(t *testing.T) {
+		dir := t.TempDir()
+		manager := NewManager(WithProvider(NewFSProvider(dir)))
+
+		for _, content := range []string{"first", "second"} {
+			if _, err := manager.UploadFile(ctx, "docs/a.txt", []byte(content)); err != nil {
+				t.Fatalf("UploadFile failed: %v", err)
+			}
+		}
+
+		if stored, _ := os.ReadFile(filepath.Join(dir, "docs", "a.txt")); string(stored) != "second" {
+			t.Fatalf("expected overwrite, got %q", stored)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		dir := t.TempDir()
+		manager := NewManager(WithProvider(NewFSProvider(dir)), WithCollisionPolicy(CollisionError))
+
+		if _, err := manager.UploadFile(ctx, "docs/a.txt", []byte("first")); err != nil {
+			t.Fatalf("UploadFile failed: %v", err)
+		}
+
+		if _, err := manager.UploadFile(ctx, "docs/a.txt", []byte("second")); !errors.Is(err, ErrFileExists) {
+			t.Fatalf("expected ErrFileExists, got %v", err)
+		}
+
+		if stored, _ := os.ReadFile(filepath.Join(dir, "docs", "a.txt")); string(stored) != "first" {
+			t.Fatalf("expected original content to be kept, got %q", stored)
+		}
+	})
+
+	t.Run("suffix", func(t *testing.T) {
+		dir := t.TempDir()
+		manager := NewManager(WithProvider(NewFSProvider(dir)), WithCollisionPolicy(CollisionSuffix))
+
+		var urls []string
+		for _, content := range []string{"first", "second", "third"} {
+			url, err := manager.UploadFile(ctx, "docs/a.txt", []byte(content))
+			if err != nil {
+				t.Fatalf("UploadFile failed: %v", err)
+			}
+			urls = append(urls, url)
+		}
+
+		if !strings.HasSuffix(urls[1], "a-1.txt") || !strings.HasSuffix(urls[2], "a-2.txt") {
+			t.Fatalf("expected suffixed keys, got %v", urls)
+		}
+
+		if stored, _ := os.ReadFile(filepath.Join(dir, "docs", "a-2.txt")); string(stored) != "third" {
+			t.Fatalf("unexpected suffixed content %q", stored)
+		}
+	})
+
+	t.Run("version", func(t *testing.T) {
+		dir := t.TempDir()
+		manager := NewManager(WithProvider(NewFSProvider(dir)), WithCollisionPolicy(CollisionVersion))
+
+		for _, content := range []string{"first", "second"} {
+			if _, err := manager.UploadFile(ctx, "docs/a.txt", []byte(content)); err != nil {
+				t.Fatalf("UploadFile failed: %v", err)
+			}
+		}
+
+		versions, _ := filepath.Glob(filepath.Join(dir, "docs", "a.v*.txt"))
+		if len(versions) != 1 {
+			t.Fatalf("expected one version, got %v", versions)
+		}
+
+		if stored, _ := os.ReadFile(versions[0]); string(stored) != "first" {
+			t.Fatalf("expected previous content in version, got %q", stored)
+		}
+		if stored, _ := os.ReadFile(filepath.Join(dir, "docs", "a.txt")); string(stored) != "second" {
+			t.Fatalf("expected new content at key, got %q", stored)
+		}
+	})
+}
+
+func TestCollisionPolicyWithoutConditionalWrites(t *testing.T) {
+	stored := map[string][]byte{"a.txt": []byte("first")}
+	provider := &mockUploader{
+		uploadFunc: func(_ context.Context, path string, content []byte, _ ...UploadOption) (string, error) {
+			stored[path] = content
+			return path, nil
+		},
+		getFunc: func(_ context.Context, path string) ([]byte, error) {
+			if content, ok := stored[path]; ok {
+				return content, nil
+			}
+			return nil, ErrImageNotFound
+		},
+	}
+
+	manager := NewManager(WithProvider(provider), WithCollisionPolicy(CollisionSuffix))
+
+	url, err := manager.UploadFile(context.Background(), "a.txt", []byte("second"))
+	if err != nil {
+		t.Fatalf("UploadFile failed: %v", err)
+	}
+
+	if url != "a-1.txt" || string(stored["a.txt"]) != "first" {
+		t.Fatalf("expected suffixed key, got %s", url)
+	}
+}
+
+func TestFSProviderUploadIfNotExists(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	provider := NewFSProvider(dir)
+
+	if _, err := provider.UploadFile(ctx, "a.txt", []byte("first"), WithIfNotExists()); err != nil {
+		t.Fatalf("UploadFile failed: %v", err)
+	}
+
+	if _, err := provider.UploadFile(ctx, "a.txt", []byte("second"), WithIfNotExists()); !errors.Is(err, ErrFileExists) {
+		t.Fatalf("expected ErrFileExists, got %v", err)
+	}
+
+	src := filepath.Join(t.TempDir(), "src.txt")
+	if err := os.WriteFile(src, []byte("moved"), 0o600); err != nil {
+		t.Fatalf("write source: %v", err)
+	}
+	if _, err := provider.UploadLocalFile(ctx, "a.txt", src, WithMoveSource(), WithIfNotExists()); !errors.Is(err, ErrFileExists) {
+		t.Fatalf("expected ErrFileExists for local file, got %v", err)
+	}
+	if _, err := os.Stat(src); err != nil {
+		t.Fatalf("expected source to be kept after a rejected move: %v", err)
+	}
+
+	info, err := provider.StatFile(ctx, "a.txt")
+	if err != nil || info.Size != int64(len("first")) {
+		t.Fatalf("unexpected stat result %#v (%v)", info, err)
+	}
+
+	if _, err := provider.StatFile(ctx, "missing.txt"); !errors.Is(err, ErrImageNotFound) {
+		t.Fatalf("expected ErrImageNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package uploader
+
+import (
+	"context"
+	"mime"
+	"path"
+	"strings"
+)
+
+// WithCompletionThumbnails generates sizes for images finished through ConfirmPresignedUpload
+// and CompleteChunked, whose bytes never pass through HandleImageWithThumbnails. The stored
+// object is downloaded once when it is an image no larger than maxBytes
+// (DefaultCompletionThumbnailMaxBytes when maxBytes <= 0). Derivative keys are added to the
+// returned FileMeta attributes as "thumbnail_<size>". Failures are logged rather than returned,
+// since the original is already stored; RegenerateThumbnails can backfill them.
+func WithCompletionThumbnails(sizes []ThumbnailSize, maxBytes int64) Option {
+	return func(m *Manager) {
+		m.completionThumbs = sizes
+		m.completionThumbMax = maxBytes
+	}
+}
+
+func (m *Manager) warmCompletionThumbnails(ctx context.Context, meta *FileMeta) {
+	if len(m.completionThumbs) == 0 || meta == nil {
+		return
+	}
+
+	contentType := mediaType(meta.ContentType)
+	if contentType == "" {
+		contentType = mediaType(mime.TypeByExtension(path.Ext(meta.Name)))
+	}
+	if !strings.HasPrefix(contentType, "image/") {
+		return
+	}
+
+	limit := m.completionThumbMax
+	if limit <= 0 {
+		limit = DefaultCompletionThumbnailMaxBytes
+	}
+
+	size := meta.Size
+	if size <= 0 {
+		info, err := m.StatFile(ctx, meta.Name)
+		if err != nil {
+			m.log(ctx).Error("completion thumbnails: stat failed", err, "key", meta.Name)
+			return
+		}
+		size = info.Size
+	}
+	if size > limit {
+		return
+	}
+
+	if err := ValidateThumbnailSizes(m.completionThumbs); err != nil {
+		m.log(ctx).Error("completion thumbnails: invalid sizes", err, "key", meta.Name)
+		return
+	}
+
+	generated, err := m.regenerateThumbnails(ctx, thumbnailJob{
+		original:    ObjectInfo{Key: meta.Name, Size: size},
+		sizes:       m.completionThumbs,
+		contentType: contentType,
+	})
+	if err != nil {
+		m.log(ctx).Error("completion thumbnails: generation failed", err, "key", meta.Name)
+	}
+
+	attrs := make(map[string]string, len(generated))
+	for i, key := range generated {
+		attrs["thumbnail_"+m.completionThumbs[i].Name] = key
+	}
+	meta.Attributes = mergeAttributes(meta.Attributes, attrs)
+}
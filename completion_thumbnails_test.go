@@ -0,0 +1,88 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestCompletionThumbnails(t *testing.T) {
+	ctx := context.Background()
+	sizes := []ThumbnailSize{{Name: "small", Width: 8, Height: 8, Fit: "cover"}}
+	png := createTestPNG(32, 32)
+
+	provider := newMemoryProvider()
+	manager := NewManager(WithProvider(provider), WithCompletionThumbnails(sizes, int64(len(png))))
+
+	if _, err := manager.UploadFile(ctx, "uploads/direct.png", png); err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	meta, err := manager.ConfirmPresignedUpload(ctx, &PresignedUploadResult{
+		Key:         "uploads/direct.png",
+		Size:        int64(len(png)),
+		ContentType: "image/png",
+	})
+	if err != nil {
+		t.Fatalf("ConfirmPresignedUpload: %v", err)
+	}
+	if thumb := meta.Attributes["thumbnail_small"]; thumb != "uploads/direct__small.png" {
+		t.Fatalf("expected thumbnail attribute, got %#v", meta.Attributes)
+	}
+	if _, err := manager.GetFile(ctx, "uploads/direct__small.png"); err != nil {
+		t.Fatalf("expected stored thumbnail: %v", err)
+	}
+
+	session, err := manager.InitiateChunked(ctx, "uploads/chunked.png", int64(len(png)))
+	if err != nil {
+		t.Fatalf("InitiateChunked: %v", err)
+	}
+	if err := manager.UploadChunk(ctx, session.ID, 0, bytes.NewReader(png)); err != nil {
+		t.Fatalf("UploadChunk: %v", err)
+	}
+	meta, err = manager.CompleteChunked(ctx, session.ID)
+	if err != nil {
+		t.Fatalf("CompleteChunked: %v", err)
+	}
+	if thumb := meta.Attributes["thumbnail_small"]; thumb != "uploads/chunked__small.png" {
+		t.Fatalf("expected thumbnail attribute on chunked completion, got %#v", meta.Attributes)
+	}
+}
+
+func TestCompletionThumbnailsSkipsLargeAndNonImages(t *testing.T) {
+	ctx := context.Background()
+	sizes := []ThumbnailSize{{Name: "small", Width: 8, Height: 8, Fit: "cover"}}
+	png := createTestPNG(32, 32)
+
+	provider := newMemoryProvider()
+	manager := NewManager(WithProvider(provider), WithCompletionThumbnails(sizes, int64(len(png))-1))
+
+	if _, err := manager.UploadFile(ctx, "uploads/large.png", png); err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	meta, err := manager.ConfirmPresignedUpload(ctx, &PresignedUploadResult{
+		Key:         "uploads/large.png",
+		Size:        int64(len(png)),
+		ContentType: "image/png",
+	})
+	if err != nil {
+		t.Fatalf("ConfirmPresignedUpload: %v", err)
+	}
+	if _, ok := meta.Attributes["thumbnail_small"]; ok {
+		t.Fatalf("expected images over the cap to be skipped")
+	}
+
+	session, err := manager.InitiateChunked(ctx, "uploads/archive.bin", 8)
+	if err != nil {
+		t.Fatalf("InitiateChunked: %v", err)
+	}
+	if err := manager.UploadChunk(ctx, session.ID, 0, bytes.NewReader([]byte("abcdefgh"))); err != nil {
+		t.Fatalf("UploadChunk: %v", err)
+	}
+	meta, err = manager.CompleteChunked(ctx, session.ID)
+	if err != nil {
+		t.Fatalf("CompleteChunked: %v", err)
+	}
+	if _, ok := meta.Attributes["thumbnail_small"]; ok {
+		t.Fatalf("expected non-images to be skipped")
+	}
+}
@@ -0,0 +1,138 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	gerrors "github.com/goliatone/go-errors"
+)
+
+var _ Composer = &FSProvider{}
+
+// Composer is implemented by providers that can concatenate stored objects into a new one
+// without the data passing through the application. ComposeObjects fills Key, URL, Size and
+// ContentType of the result.
+type Composer interface {
+	ComposeObjects(ctx context.Context, dst string, srcs []string, opts ...UploadOption) (*UploadResult, error)
+}
+
+// Compose concatenates srcs, in order, into dst on the provider dst routes to, so clients that
+// uploaded a file as separate segments can stitch it server side. dst may be one of srcs, and
+// the sources are kept; delete them once dst is in place. Providers without Composer fail with
+// ErrNotImplemented.
+func (m *Manager) Compose(ctx context.Context, dst string, srcs []string, opts ...UploadOption) (*UploadResult, error) {
+	if err := m.ensureWritable(); err != nil {
+		return nil, err
+	}
+	if len(srcs) == 0 {
+		return nil, gerrors.NewValidation("compose failed", gerrors.FieldError{
+			Field:   "srcs",
+			Message: "at least one source is required",
+		})
+	}
+	for _, key := range append([]string{dst}, srcs...) {
+		if err := m.validateKey(key); err != nil {
+			return nil, err
+		}
+	}
+	if err := m.ensureProvider(ctx); err != nil {
+		return nil, err
+	}
+
+	composer, ok := m.providerFor(ctx, dst).(Composer)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+
+	md := &Metadata{}
+	for _, opt := range opts {
+		opt(md)
+	}
+	if md.CacheControl == "" {
+		if value := m.cacheControlFor(md.ContentType); value != "" {
+			opts = append(opts, WithCacheControl(value))
+		}
+	}
+	opts, err := m.visibilityOptions(ctx, md, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	started := time.Now()
+	result, err := observeCall(ctx, m, "compose", dst, func() (*UploadResult, error) {
+		return composer.ComposeObjects(ctx, dst, srcs, opts...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	recordWrite(ctx, dst)
+	m.recordUpload(ctx, result, started)
+	return result, nil
+}
+
+// ComposeObjects streams srcs into a temporary file next to dst and renames it into place, so
+// readers never see a partial object and dst may be one of the sources.
+func (p *FSProvider) ComposeObjects(_ context.Context, dst string, srcs []string, opts ...UploadOption) (*UploadResult, error) {
+	md := &Metadata{}
+	for _, opt := range opts {
+		opt(md)
+	}
+
+	fullPath := filepath.Join(p.base, filepath.Clean(dst))
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPermissionDenied, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(fullPath), ".compose-*")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrPermissionDenied, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	var size int64
+	for _, src := range srcs {
+		n, err := p.appendObject(tmp, src)
+		if err != nil {
+			_ = tmp.Close()
+			return nil, err
+		}
+		size += n
+	}
+
+	if err := tmp.Chmod(fileMode(md.resolvedVisibility())); err != nil {
+		_ = tmp.Close()
+		return nil, fmt.Errorf("fs provider: chmod %s: %w", dst, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("fs provider: compose: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), fullPath); err != nil {
+		return nil, fmt.Errorf("fs provider: compose: %w", err)
+	}
+	p.etags.forget(filepath.Clean(dst))
+
+	return &UploadResult{Key: dst, URL: p.url(dst), Size: size, ContentType: md.ContentType}, nil
+}
+
+func (p *FSProvider) appendObject(dst io.Writer, src string) (int64, error) {
+	file, err := os.Open(filepath.Join(p.base, filepath.Clean(src)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, fmt.Errorf("%w: %s", ErrImageNotFound, src)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("fs provider: open %s: %w", src, err)
+	}
+	defer file.Close()
+
+	n, err := io.Copy(dst, file)
+	if err != nil {
+		return 0, fmt.Errorf("fs provider: compose %s: %w", src, err)
+	}
+	return n, nil
+}
@@ -0,0 +1,130 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+	gerrors "github.com/goliatone/go-errors"
+)
+
+func TestManagerCompose(t *testing.T) {
+	ctx := context.Background()
+	manager := NewManager(WithProvider(NewFSProvider(t.TempDir(), WithFSURLPrefix("/files"))))
+
+	for _, key := range []string{"parts/0", "parts/1", "parts/2"} {
+		if _, err := manager.UploadFile(ctx, key, []byte(key+";")); err != nil {
+			t.Fatalf("UploadFile: %v", err)
+		}
+	}
+
+	result, err := manager.Compose(ctx, "video.bin", []string{"parts/0", "parts/1", "parts/2"}, WithContentType("application/octet-stream"))
+	if err != nil {
+		t.Fatalf("Compose: %v", err)
+	}
+	content, err := manager.GetFile(ctx, "video.bin")
+	if err != nil || string(content) != "parts/0;parts/1;parts/2;" {
+		t.Fatalf("unexpected composed object %q, %v", content, err)
+	}
+	if result.Size != int64(len(content)) || result.URL != "/files/video.bin" || result.Provider == "" {
+		t.Fatalf("unexpected result %#v", result)
+	}
+
+	if _, err := manager.Compose(ctx, "parts/0", []string{"parts/0", "parts/0"}); err != nil {
+		t.Fatalf("Compose onto a source: %v", err)
+	}
+	if content, _ := manager.GetFile(ctx, "parts/0"); string(content) != "parts/0;parts/0;" {
+		t.Fatalf("expected the source to be doubled, got %q", content)
+	}
+
+	if _, err := manager.Compose(ctx, "video.bin", []string{"parts/0", "parts/missing"}); !errors.Is(err, ErrImageNotFound) {
+		t.Fatalf("expected ErrImageNotFound for a missing source, got %v", err)
+	}
+	if content, _ := manager.GetFile(ctx, "video.bin"); string(content) != "parts/0;parts/1;parts/2;" {
+		t.Fatalf("expected a failed compose to leave dst untouched, got %q", content)
+	}
+	if _, err := manager.Compose(ctx, "video.bin", nil); !gerrors.IsValidation(err) {
+		t.Fatalf("expected a validation error without sources, got %v", err)
+	}
+
+	manager = NewManager(WithProvider(newMemoryProvider()))
+	if _, err := manager.Compose(ctx, "video.bin", []string{"a"}); !errors.Is(err, ErrNotImplemented) {
+		t.Fatalf("expected ErrNotImplemented, got %v", err)
+	}
+}
+
+func TestAWSProviderComposeObjects(t *testing.T) {
+	ctx := context.Background()
+	newClient := func(size int64) *fakeS3Client {
+		return &fakeS3Client{
+			headOutput:              &s3.HeadObjectOutput{ContentLength: aws.Int64(size), ETag: aws.String(`"v1"`), ContentType: aws.String("video/mp4")},
+			createMultipartOutput:   &s3.CreateMultipartUploadOutput{UploadId: aws.String("upload")},
+			uploadPartOutput:        &s3.UploadPartOutput{ETag: aws.String("uploaded")},
+			completeMultipartOutput: &s3.CompleteMultipartUploadOutput{},
+		}
+	}
+
+	t.Run("small sources are merged", func(t *testing.T) {
+		client := newClient(4)
+		provider := &AWSProvider{client: client, bucket: "test-bucket", logger: &DefaultLogger{}}
+
+		result, err := provider.ComposeObjects(ctx, "out.mp4", []string{"a", "b", "c"})
+		if err != nil {
+			t.Fatalf("ComposeObjects: %v", err)
+		}
+		if result.Size != 12 || result.ContentType != "video/mp4" {
+			t.Fatalf("unexpected result %#v", result)
+		}
+		if len(client.partCopyInputs) != 0 || len(client.uploadPartBodies) != 1 || string(client.uploadPartBodies[0]) != "datadatadata" {
+			t.Fatalf("expected one uploaded part, got %d copies and parts %q", len(client.partCopyInputs), client.uploadPartBodies)
+		}
+		for _, get := range client.getInputs {
+			if aws.ToString(get.IfMatch) != `"v1"` {
+				t.Fatalf("expected reads pinned to the source ETag, got %#v", get)
+			}
+		}
+	})
+
+	t.Run("large sources are copied", func(t *testing.T) {
+		client := newClient(MinS3ChunkPartSize)
+		provider := &AWSProvider{client: client, bucket: "test-bucket", logger: &DefaultLogger{}}
+
+		if _, err := provider.ComposeObjects(ctx, "out.mp4", []string{"segments/a", "segments/b"}); err != nil {
+			t.Fatalf("ComposeObjects: %v", err)
+		}
+		if len(client.partCopyInputs) != 2 || len(client.uploadPartBodies) != 0 || len(client.lastCompletedParts) != 2 {
+			t.Fatalf("expected two copied parts, got %d copies, %d uploads", len(client.partCopyInputs), len(client.uploadPartBodies))
+		}
+		if source := aws.ToString(client.partCopyInputs[1].CopySource); source != "test-bucket/segments%2Fb" {
+			t.Fatalf("unexpected copy source %q", source)
+		}
+	})
+
+	t.Run("modified source", func(t *testing.T) {
+		client := newClient(4)
+		client.getErr = &smithy.GenericAPIError{Code: "PreconditionFailed"}
+		provider := &AWSProvider{client: client, bucket: "test-bucket", logger: &DefaultLogger{}}
+
+		if _, err := provider.ComposeObjects(ctx, "out.mp4", []string{"a", "b"}); !errors.Is(err, ErrComposeConflict) {
+			t.Fatalf("expected ErrComposeConflict, got %v", err)
+		}
+		if !client.abortCalled {
+			t.Fatalf("expected the multipart upload to be aborted")
+		}
+	})
+}
+
+func TestCopyPartRanges(t *testing.T) {
+	ranges := copyPartRanges(0, MaxS3CopyPartSize+1)
+	if len(ranges) != 2 || ranges[1][1] != MaxS3CopyPartSize || ranges[1][1]-ranges[1][0]+1 < MinS3ChunkPartSize {
+		t.Fatalf("expected two balanced ranges, got %v", ranges)
+	}
+
+	ranges = copyPartRanges(10, 20)
+	if len(ranges) != 1 || ranges[0] != [2]int64{10, 19} {
+		t.Fatalf("expected the offset to be kept, got %v", ranges)
+	}
+}
@@ -0,0 +1,130 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ExtractedContent is the text pulled out of an uploaded document or image.
+type ExtractedContent struct {
+	Key         string            `json:"key"`
+	ContentType string            `json:"content_type"`
+	Text        string            `json:"text"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+// ContentExtractor pulls text out of stored files, e.g. via OCR or a document parsing API, so
+// uploads can be indexed for search.
+type ContentExtractor interface {
+	// Supports reports whether the extractor handles contentType; unsupported uploads are skipped.
+	Supports(contentType string) bool
+	Extract(ctx context.Context, meta *FileMeta, content []byte) (*ExtractedContent, error)
+}
+
+// ExtractionCallback receives the outcome of an extraction. result is nil when err is set.
+type ExtractionCallback func(ctx context.Context, meta *FileMeta, result *ExtractedContent, err error)
+
+// WithContentExtractor runs extractor in the background after each successful upload and
+// delivers the outcome to onExtracted. Extraction never fails or delays the upload itself.
+func WithContentExtractor(extractor ContentExtractor, onExtracted ExtractionCallback) Option {
+	return func(m *Manager) {
+		m.extractor = extractor
+		m.onExtracted = onExtracted
+	}
+}
+
+func (m *Manager) maybeExtractContent(ctx context.Context, meta *FileMeta) {
+	if m.extractor == nil || m.onExtracted == nil || meta == nil || !m.extractor.Supports(meta.ContentType) {
+		return
+	}
+
+	// The request that stored the file may finish before extraction does.
+	ctx = context.WithoutCancel(ctx)
+	snapshot := *meta
+
+	go func() {
+		result, err := m.extractContent(ctx, &snapshot)
+		if err != nil {
+			m.log(ctx).Error("content extraction failed", err, "key", snapshot.Name)
+		}
+		m.onExtracted(ctx, &snapshot, result, err)
+	}()
+}
+
+func (m *Manager) extractContent(ctx context.Context, meta *FileMeta) (*ExtractedContent, error) {
+	content := meta.Content
+	if content == nil {
+		// Spooled, chunked and presigned uploads do not keep content in memory.
+		var err error
+		if content, err = m.GetFile(ctx, meta.Name); err != nil {
+			return nil, fmt.Errorf("content extraction: load %s: %w", meta.Name, err)
+		}
+	}
+
+	result, err := m.extractor.Extract(ctx, meta, content)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Key == "" {
+		result.Key = meta.Name
+	}
+	if result.ContentType == "" {
+		result.ContentType = meta.ContentType
+	}
+	return result, nil
+}
+
+// CommandExtractor runs an external program that reads the file on stdin and writes the
+// extracted text to stdout, such as `tesseract stdin stdout` or `pdftotext - -`.
+type CommandExtractor struct {
+	contentTypes []string
+	name         string
+	args         []string
+}
+
+var _ ContentExtractor = &CommandExtractor{}
+
+// NewCommandExtractor creates an extractor for contentTypes, which may use type wildcards such
+// as "image/*".
+func NewCommandExtractor(contentTypes []string, name string, args ...string) *CommandExtractor {
+	return &CommandExtractor{
+		contentTypes: contentTypes,
+		name:         name,
+		args:         args,
+	}
+}
+
+func (e *CommandExtractor) Supports(contentType string) bool {
+	for _, candidate := range e.contentTypes {
+		if candidate == contentType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(candidate, "/*"); ok && strings.HasPrefix(contentType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *CommandExtractor) Extract(ctx context.Context, meta *FileMeta, content []byte) (*ExtractedContent, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, e.name, e.args...)
+	cmd.Stdin = bytes.NewReader(content)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("content extraction: %s: %w: %s", e.name, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return &ExtractedContent{
+		Key:         meta.Name,
+		ContentType: meta.ContentType,
+		Text:        strings.TrimSpace(stdout.String()),
+	}, nil
+}
@@ -0,0 +1,124 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+type stubExtractor struct {
+	text string
+	err  error
+}
+
+func (s *stubExtractor) Supports(contentType string) bool {
+	return contentType == "image/png"
+}
+
+func (s *stubExtractor) Extract(ctx context.Context, meta *FileMeta, content []byte) (*ExtractedContent, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if len(content) == 0 {
+		return nil, errors.New("missing content")
+	}
+	return &ExtractedContent{Text: s.text}, nil
+}
+
+type extraction struct {
+	meta   *FileMeta
+	result *ExtractedContent
+	err    error
+}
+
+func waitForExtraction(t *testing.T, results <-chan extraction) extraction {
+	t.Helper()
+	select {
+	case res := <-results:
+		return res
+	case <-time.After(2 * time.Second):
+		t.Fatalf("timed out waiting for extraction")
+		return extraction{}
+	}
+}
+
+func TestHandleFileRunsContentExtractor(t *testing.T) {
+	results := make(chan extraction, 1)
+	manager := NewManager(
+		WithProvider(NewFSProvider(t.TempDir())),
+		WithContentExtractor(&stubExtractor{text: "hello"}, func(ctx context.Context, meta *FileMeta, result *ExtractedContent, err error) {
+			results <- extraction{meta: meta, result: result, err: err}
+		}),
+	)
+
+	meta, err := manager.HandleFile(context.Background(), newTestFileHeader(t, "file", "scan.png", "image/png", createTestPNG(4, 4)), "scans")
+	if err != nil {
+		t.Fatalf("HandleFile failed: %v", err)
+	}
+
+	res := waitForExtraction(t, results)
+	if res.err != nil {
+		t.Fatalf("extraction failed: %v", res.err)
+	}
+	if res.result.Text != "hello" || res.result.Key != meta.Name || res.result.ContentType != "image/png" {
+		t.Fatalf("unexpected extraction result: %#v", res.result)
+	}
+}
+
+func TestContentExtractorLoadsSpooledContent(t *testing.T) {
+	results := make(chan extraction, 1)
+	manager := NewManager(
+		WithProvider(NewFSProvider(t.TempDir())),
+		WithSpoolThreshold(1),
+		WithContentExtractor(&stubExtractor{text: "spooled"}, func(ctx context.Context, meta *FileMeta, result *ExtractedContent, err error) {
+			results <- extraction{result: result, err: err}
+		}),
+	)
+
+	if _, err := manager.HandleFile(context.Background(), newTestFileHeader(t, "file", "scan.png", "image/png", createTestPNG(4, 4)), "scans"); err != nil {
+		t.Fatalf("HandleFile failed: %v", err)
+	}
+
+	if res := waitForExtraction(t, results); res.err != nil || res.result.Text != "spooled" {
+		t.Fatalf("expected extraction from stored file, got %#v (%v)", res.result, res.err)
+	}
+}
+
+func TestContentExtractorReportsErrors(t *testing.T) {
+	results := make(chan extraction, 1)
+	manager := NewManager(
+		WithProvider(NewFSProvider(t.TempDir())),
+		WithContentExtractor(&stubExtractor{err: errors.New("ocr down")}, func(ctx context.Context, meta *FileMeta, result *ExtractedContent, err error) {
+			results <- extraction{result: result, err: err}
+		}),
+	)
+
+	if _, err := manager.HandleFile(context.Background(), newTestFileHeader(t, "file", "scan.png", "image/png", createTestPNG(4, 4)), "scans"); err != nil {
+		t.Fatalf("expected upload to succeed despite extractor failure, got %v", err)
+	}
+
+	if res := waitForExtraction(t, results); res.err == nil || res.result != nil {
+		t.Fatalf("expected extraction error, got %#v", res)
+	}
+}
+
+func TestCommandExtractor(t *testing.T) {
+	if _, err := exec.LookPath("cat"); err != nil {
+		t.Skip("cat not available")
+	}
+
+	extractor := NewCommandExtractor([]string{"text/*"}, "cat")
+	if !extractor.Supports("text/plain") || extractor.Supports("image/png") {
+		t.Fatalf("unexpected Supports results")
+	}
+
+	result, err := extractor.Extract(context.Background(), &FileMeta{Name: "notes.txt", ContentType: "text/plain"}, []byte(" indexed text \n"))
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if result.Text != "indexed text" || result.Key != "notes.txt" {
+		t.Fatalf("unexpected result: %#v", result)
+	}
+}
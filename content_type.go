@@ -0,0 +1,72 @@
+package uploader
+
+import (
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+type forcedContentTypeContextKey struct{}
+
+// ContextWithForcedContentType returns a copy of ctx that makes HandleFile and
+// HandleImageWithThumbnails store and validate the part as contentType, ignoring the multipart
+// header, the file extension and the content itself. Use it when the caller knows the type better
+// than the client that sent it.
+func ContextWithForcedContentType(ctx context.Context, contentType string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, forcedContentTypeContextKey{}, contentType)
+}
+
+// ForcedContentTypeFromContext returns the type set with ContextWithForcedContentType.
+func ForcedContentTypeFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	contentType, _ := ctx.Value(forcedContentTypeContextKey{}).(string)
+	return contentType
+}
+
+// resolveContentType picks the type of an uploaded part: a type forced through ctx, the part's
+// Content-Type header, the type registered for the file extension, and finally one sniffed from
+// the first 512 bytes of src. Generic octet-stream headers are treated as missing. An unreadable
+// part resolves to "", which the validator rejects.
+func resolveContentType(ctx context.Context, file *multipart.FileHeader, src io.ReaderAt) string {
+	if forced := mediaType(ForcedContentTypeFromContext(ctx)); forced != "" {
+		return forced
+	}
+
+	if header := mediaType(file.Header.Get("Content-Type")); header != "" && header != "application/octet-stream" {
+		return header
+	}
+
+	if byExt := mediaType(mime.TypeByExtension(filepath.Ext(file.Filename))); byExt != "" {
+		return byExt
+	}
+
+	head := make([]byte, 512)
+	n, err := src.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return ""
+	}
+	return mediaType(http.DetectContentType(head[:n]))
+}
+
+// mediaType strips parameters from a Content-Type value and lowercases it. Malformed values
+// yield "".
+func mediaType(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ""
+	}
+	parsed, _, err := mime.ParseMediaType(value)
+	if err != nil {
+		return ""
+	}
+	return parsed
+}
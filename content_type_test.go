@@ -0,0 +1,60 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"testing"
+)
+
+func TestHandleFileContentTypeFallback(t *testing.T) {
+	ctx := context.Background()
+	png := createTestPNG(4, 4)
+
+	var stored []string
+	manager := NewManager(WithProvider(&mockUploader{
+		uploadFunc: func(ctx context.Context, path string, content []byte, opts ...UploadOption) (string, error) {
+			md := &Metadata{}
+			for _, opt := range opts {
+				opt(md)
+			}
+			stored = append(stored, md.ContentType)
+			return path, nil
+		},
+	}))
+
+	missing := createMultipartFileHeader("photo.png", "", png)
+	missing.Header.Del("Content-Type")
+	meta, err := manager.HandleFile(ctx, missing, "uploads")
+	if err != nil {
+		t.Fatalf("HandleFile without Content-Type failed: %v", err)
+	}
+	if meta.ContentType != "image/png" {
+		t.Fatalf("expected extension fallback, got %q", meta.ContentType)
+	}
+
+	withParams := createMultipartFileHeader("photo.png", "Image/PNG; charset=binary", png)
+	if meta, err = manager.HandleFile(ctx, withParams, "uploads"); err != nil || meta.ContentType != "image/png" {
+		t.Fatalf("expected parameters to be stripped, got %v (%v)", meta, err)
+	}
+
+	forced := ContextWithForcedContentType(ctx, "image/webp")
+	if meta, err = manager.HandleFile(forced, createMultipartFileHeader("photo.png", "image/png", png), "uploads"); err != nil {
+		t.Fatalf("HandleFile with forced type failed: %v", err)
+	}
+	if meta.ContentType != "image/webp" || stored[len(stored)-1] != "image/webp" {
+		t.Fatalf("expected forced content type, got %q stored as %v", meta.ContentType, stored)
+	}
+
+	if _, err := manager.HandleFile(ContextWithForcedContentType(ctx, "text/plain"), createMultipartFileHeader("photo.png", "image/png", png), "uploads"); err == nil {
+		t.Fatalf("expected forced content type to be validated")
+	}
+}
+
+func TestResolveContentTypeSniffsContent(t *testing.T) {
+	png := createTestPNG(2, 2)
+	file := createMultipartFileHeader("upload.unknownext", "application/octet-stream", png)
+
+	if got := resolveContentType(context.Background(), file, bytes.NewReader(png)); got != "image/png" {
+		t.Fatalf("expected sniffed image/png, got %q", got)
+	}
+}
@@ -0,0 +1,120 @@
+package uploader
+
+import (
+	"context"
+	"fmt"
+
+	gerrors "github.com/goliatone/go-errors"
+)
+
+type correlationIDContextKey struct{}
+
+// ContextWithCorrelationID returns a copy of ctx carrying id, typically the ID of the HTTP request
+// that started the operation. The manager and providers add it to their log lines, and event and
+// result handlers can read it back with CorrelationIDFromContext.
+func ContextWithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDContextKey{}, id)
+}
+
+// CorrelationIDFromContext returns the ID stored with ContextWithCorrelationID, or "".
+func CorrelationIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(correlationIDContextKey{}).(string)
+	return id
+}
+
+// WithCorrelationIDKey makes the manager read correlation IDs stored under key, for applications
+// whose middleware already keeps a request ID in the context. Values may be strings or
+// fmt.Stringers. IDs stored with ContextWithCorrelationID are still honoured.
+//
+// Providers are handed the context as is and only log IDs stored with ContextWithCorrelationID.
+func WithCorrelationIDKey(key any) Option {
+	return func(m *Manager) {
+		m.correlationKey = key
+	}
+}
+
+// correlationID returns the correlation ID of ctx, preferring the configured key.
+func (m *Manager) correlationID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if m.correlationKey != nil {
+		switch v := ctx.Value(m.correlationKey).(type) {
+		case string:
+			if v != "" {
+				return v
+			}
+		case fmt.Stringer:
+			if id := v.String(); id != "" {
+				return id
+			}
+		}
+	}
+	return CorrelationIDFromContext(ctx)
+}
+
+// correlationContext stores the correlation ID read through the configured key where handlers
+// and CorrelationIDFromContext find it.
+func (m *Manager) correlationContext(ctx context.Context) context.Context {
+	id := m.correlationID(ctx)
+	if id == "" || CorrelationIDFromContext(ctx) == id {
+		return ctx
+	}
+	return ContextWithCorrelationID(ctx, id)
+}
+
+// log returns the manager logger, redacting lines and tagging them with the correlation ID of
+// ctx.
+func (m *Manager) log(ctx context.Context) Logger {
+	logger := redactLogger(m.logger)
+	if m.logRedaction != nil {
+		logger = NewRedactingLogger(m.logger, *m.logRedaction)
+	}
+	return correlatedLogger(logger, m.correlationID(ctx))
+}
+
+// correlateError records the correlation ID of ctx on a structured error built for this call, such
+// as a validation failure. Other errors are returned as is; shared sentinels must not be passed in.
+func (m *Manager) correlateError(ctx context.Context, err error) error {
+	structured, ok := err.(*gerrors.Error)
+	if !ok {
+		return err
+	}
+	if id := m.correlationID(ctx); id != "" {
+		structured.WithRequestID(id).WithMetadata(map[string]any{"correlation_id": id})
+	}
+	return structured
+}
+
+// contextLogger returns logger for provider log lines: redacted, and tagged with the ID stored by
+// ContextWithCorrelationID.
+func contextLogger(ctx context.Context, logger Logger) Logger {
+	return correlatedLogger(redactLogger(logger), CorrelationIDFromContext(ctx))
+}
+
+func correlatedLogger(logger Logger, id string) Logger {
+	if id == "" {
+		return logger
+	}
+	return &correlationLogger{logger: logger, id: id}
+}
+
+type correlationLogger struct {
+	logger Logger
+	id     string
+}
+
+func (l *correlationLogger) Info(msg string, args ...any) {
+	l.logger.Info(msg, append(args, "correlation_id", l.id)...)
+}
+
+func (l *correlationLogger) Error(msg string, args ...any) {
+	l.logger.Error(msg, append(args, "correlation_id", l.id)...)
+}
+
+func (l *correlationLogger) Debug(msg string, args ...any) {
+	logDebug(l.logger, msg, append(args, "correlation_id", l.id)...)
+}
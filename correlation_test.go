@@ -0,0 +1,93 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	gerrors "github.com/goliatone/go-errors"
+)
+
+type argsLogger struct {
+	lines [][]any
+}
+
+func (l *argsLogger) Info(msg string, args ...any) {
+	l.lines = append(l.lines, append([]any{msg}, args...))
+}
+
+func (l *argsLogger) Error(msg string, args ...any) {
+	l.lines = append(l.lines, append([]any{msg}, args...))
+}
+
+func (l *argsLogger) correlated(msg, id string) bool {
+	for _, line := range l.lines {
+		if line[0] != msg {
+			continue
+		}
+		for i := 1; i+1 < len(line); i++ {
+			if line[i] == "correlation_id" && line[i+1] == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type requestIDKey struct{}
+
+func TestCorrelationID(t *testing.T) {
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-1")
+	logger := &argsLogger{}
+	var eventIDs, resultIDs []string
+	manager := NewManager(
+		WithProvider(NewFSProvider(t.TempDir())),
+		WithLogger(logger),
+		WithValidator(NewValidator(WithUploadMaxFileSize(8))),
+		WithCorrelationIDKey(requestIDKey{}),
+		WithEventHandler(func(ctx context.Context, _ Event) {
+			eventIDs = append(eventIDs, CorrelationIDFromContext(ctx))
+		}),
+		WithResultSink(func(ctx context.Context, _ OperationResult) {
+			resultIDs = append(resultIDs, CorrelationIDFromContext(ctx))
+		}),
+	)
+
+	if _, err := manager.UploadFile(ctx, "a.txt", []byte("a")); err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	if _, err := manager.GetPresignedURL(ctx, "a.txt", time.Minute); err != nil {
+		t.Fatalf("GetPresignedURL: %v", err)
+	}
+	if len(resultIDs) != 1 || resultIDs[0] != "req-1" || len(eventIDs) != 1 || eventIDs[0] != "req-1" {
+		t.Fatalf("expected handlers to see the correlation ID, got results %v events %v", resultIDs, eventIDs)
+	}
+
+	if err := manager.SwapProvider(ctx, NewFSProvider(t.TempDir())); err != nil {
+		t.Fatalf("SwapProvider: %v", err)
+	}
+	if !logger.correlated("provider swapped", "req-1") {
+		t.Fatalf("expected log line tagged with the correlation ID, got %v", logger.lines)
+	}
+
+	file := newTestFileHeader(t, "file", "big.png", "image/png", createTestPNG(8, 8))
+	_, err := manager.HandleFile(ctx, file, "images")
+	var structured *gerrors.Error
+	if !errors.As(err, &structured) || structured.RequestID != "req-1" || structured.Metadata["correlation_id"] != "req-1" {
+		t.Fatalf("expected validation error to carry the correlation ID, got %#v", err)
+	}
+}
+
+func TestContextLogger(t *testing.T) {
+	logger := &argsLogger{}
+	contextLogger(context.Background(), logger).Info("plain")
+	if len(logger.lines[0]) != 1 {
+		t.Fatalf("expected no correlation ID without one in the context, got %v", logger.lines[0])
+	}
+
+	contextLogger(ContextWithCorrelationID(context.Background(), "req-2"), logger).Error("failed", errors.New("boom"))
+	if !logger.correlated("failed", "req-2") {
+		t.Fatalf("expected log line tagged with the correlation ID, got %v", logger.lines)
+	}
+}
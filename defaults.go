@@ -1,27 +1,151 @@
 package uploader
 
-import "time"
+import (
+	"time"
+	"unicode"
+)
 
 var (
 	// DefaultChunkSessionTTL is the fallback expiration applied to chunked upload sessions
 	// when a custom TTL is not provided.
 	DefaultChunkSessionTTL = 30 * time.Minute
 
+	// DefaultChunkSessionMaxLifetime caps how far ExtendChunkSession can push a session's expiry,
+	// measured from its creation.
+	DefaultChunkSessionMaxLifetime = 24 * time.Hour
+
+	// DefaultChunkWatchdogInterval is how often RunChunkWatchdog sweeps expired sessions when no
+	// interval is given.
+	DefaultChunkWatchdogInterval = time.Minute
+
 	// DefaultChunkPartSize defines the default size (bytes) used for chunked uploads when
 	// callers do not provide a custom size.
 	DefaultChunkPartSize int64 = 5 * 1024 * 1024
 
-	// DefaultPresignedPostTTL controls how long presigned posts remain valid when a custom TTL is not supplied.
-	DefaultPresignedPostTTL = 15 * time.Minute
-
-	// MaxPresignedPostTTL caps presigned post lifetimes to avoid long-lived public upload surfaces.
-	MaxPresignedPostTTL = 24 * time.Hour
-
-	// DefaultPresignedURLTTL determines how long confirmation URLs remain valid when returned via ConfirmPresignedUpload.
-	DefaultPresignedURLTTL = 10 * time.Minute
+	// DefaultPresignPolicy bounds presigned lifetimes unless WithPresignPolicy is set. MaxTTL caps
+	// them to avoid long-lived public upload surfaces; URLTTL also covers the download URLs
+	// returned by ConfirmPresignedUpload.
+	DefaultPresignPolicy = PresignPolicy{
+		MaxTTL:            24 * time.Hour,
+		PostTTL:           15 * time.Minute,
+		ChunkTTL:          15 * time.Minute,
+		URLTTL:            10 * time.Minute,
+		ConfirmationGrace: 15 * time.Minute,
+	}
 
 	// DefaultPresignedMaxFileSize enforces the default max payload accepted via presigned uploads (matches validator default).
 	DefaultPresignedMaxFileSize = DefaultMaxFileSize
+
+	// DefaultThumbnailWorkers bounds concurrent originals processed by RegenerateThumbnails.
+	DefaultThumbnailWorkers = 4
+
+	// DefaultPresignWorkers bounds concurrent signatures made by GetPresignedURLs.
+	DefaultPresignWorkers = 8
+
+	// DefaultCompletionThumbnailMaxBytes is the largest image WithCompletionThumbnails downloads to
+	// generate derivatives when no cap is given.
+	DefaultCompletionThumbnailMaxBytes int64 = 20 * 1024 * 1024
+
+	// DefaultIdempotencyTTL controls how long idempotency keys replay the original result.
+	DefaultIdempotencyTTL = 24 * time.Hour
+
+	// DefaultReservationTTL is how long a ReserveUpload token stays valid when no TTL is given.
+	DefaultReservationTTL = 15 * time.Minute
+
+	// DefaultStagingPrefix is where StageFile holds uploads until they are committed.
+	DefaultStagingPrefix = "staging/"
+
+	// DefaultArchiveStorageClass is the storage class Archive moves objects to.
+	DefaultArchiveStorageClass = "GLACIER"
+
+	// DefaultRestoreDays is how long Restore keeps a temporary copy of an archived object readable.
+	DefaultRestoreDays = 7
+
+	// DefaultRestorePollInterval is how often WaitRestored checks a pending restore when no
+	// interval is given. Standard Glacier restores take hours.
+	DefaultRestorePollInterval = time.Minute
+
+	// DefaultSpamWindow is how far back WithSpamHeuristics looks for repeated content when
+	// SpamHeuristics.Window is not set.
+	DefaultSpamWindow = 10 * time.Minute
+
+	// DefaultDeletePrefixMaxObjects is how many objects DeletePrefix removes before it asks the
+	// caller to confirm a larger MaxObjects.
+	DefaultDeletePrefixMaxObjects = 1000
+
+	// DefaultBufferPoolMaxRetained is the largest buffer the shared pool keeps for reuse; bigger
+	// buffers are left to the GC so one huge upload does not pin memory. It fits a default chunk part.
+	DefaultBufferPoolMaxRetained = 8 * 1024 * 1024
+
+	// DefaultImmutableCacheControl is applied to content-addressed keys, whose bytes never change.
+	DefaultImmutableCacheControl = "public, max-age=31536000, immutable"
+
+	// DefaultCollisionMaxSuffix bounds the "name-N" keys CollisionSuffix tries before giving up.
+	DefaultCollisionMaxSuffix = 100
+
+	// DefaultShardVirtualNodes is the number of points each unit of shard weight places on the
+	// ShardedProvider hash ring. More points spread keys more evenly.
+	DefaultShardVirtualNodes = 64
+
+	// DefaultGeoReplicationLag is how long after a write GeoProvider reads that key from the
+	// primary instead of a replica.
+	DefaultGeoReplicationLag = 15 * time.Minute
+
+	// DefaultGeoUnhealthyCooldown is how long GeoProvider skips a replica after a failed read.
+	DefaultGeoUnhealthyCooldown = 30 * time.Second
+
+	// DefaultAsyncCallbackTimeout bounds callbacks dispatched by AsyncCallbackExecutor, which run
+	// detached from the request that triggered them.
+	DefaultAsyncCallbackTimeout = 5 * time.Minute
+
+	// DefaultInventoryPageSize is the number of keys ExportInventory requests per listing call,
+	// matching the S3 page size.
+	DefaultInventoryPageSize = 1000
+
+	// DefaultExtractMaxEntries is how many files ExtractStored writes from one archive unless
+	// ExtractOptions.MaxEntries says otherwise.
+	DefaultExtractMaxEntries = 1000
+
+	// DefaultExtractMaxTotalSize caps the uncompressed bytes ExtractStored writes from one archive,
+	// so a small compressed archive cannot fill the store.
+	DefaultExtractMaxTotalSize int64 = 1024 * 1024 * 1024
+
+	// DefaultMinFileSize is the smallest upload, in bytes, the validator accepts, so empty files are
+	// rejected unless WithMinFileSize(0) is set.
+	DefaultMinFileSize int64 = 1
+
+	// DefaultMaxKeyLength is the longest object key, in bytes, the validator accepts. It matches
+	// the S3 key limit.
+	DefaultMaxKeyLength = 1024
+
+	// DefaultMaxFilenameLength is the longest key segment, in bytes, the validator accepts. Most
+	// local filesystems cap file names at 255 bytes.
+	DefaultMaxFilenameLength = 255
+
+	// DefaultDisallowedUnicode are the character classes rejected in keys and file names: control
+	// characters, and bidi controls that make "photo<RLO>gnp.exe" display as "photoexe.png".
+	DefaultDisallowedUnicode = []*unicode.RangeTable{unicode.Cc, unicode.Bidi_Control}
+
+	// DefaultReservedNames are key segments rejected by the validator because Windows reserves them
+	// as device names, with or without an extension.
+	DefaultReservedNames = []string{
+		"CON", "PRN", "AUX", "NUL",
+		"COM1", "COM2", "COM3", "COM4", "COM5", "COM6", "COM7", "COM8", "COM9",
+		"LPT1", "LPT2", "LPT3", "LPT4", "LPT5", "LPT6", "LPT7", "LPT8", "LPT9",
+	}
+
+	// DefaultLatencyBuckets are the upper bounds of the latency histogram kept per provider
+	// operation. Slower calls land in a final overflow bucket.
+	DefaultLatencyBuckets = []time.Duration{
+		10 * time.Millisecond,
+		50 * time.Millisecond,
+		100 * time.Millisecond,
+		250 * time.Millisecond,
+		500 * time.Millisecond,
+		time.Second,
+		5 * time.Second,
+		30 * time.Second,
+	}
 )
 
 // CallbackMode describes how the manager should react when post-upload callbacks fail.
@@ -0,0 +1,86 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+// WithDerivativeProvider stores thumbnails and other derivatives on provider instead of the
+// default one, e.g. originals in a private bucket and derivatives in a public bucket behind a CDN.
+//
+// Keys are routed by the thumbnail key template, so reads, deletes and presigned URLs for a
+// derivative key reach provider as well. Pair it with a template that cannot collide with
+// original keys, such as DerivativesFolderKeyTemplate: with the default template any original
+// named like "<base>__<variant><ext>" next to its "<base><ext>" would be routed to provider.
+// Like routed providers, provider is not validated by the manager.
+func WithDerivativeProvider(provider Uploader) Option {
+	return func(m *Manager) {
+		m.derivativeProvider = provider
+	}
+}
+
+// isDerivativeKey reports whether key was named by the thumbnail key template.
+func (m *Manager) isDerivativeKey(key string) bool {
+	m.derivativeOnce.Do(func() {
+		m.derivativePattern = m.thumbnailKeyTemplate().pattern()
+	})
+	original, ok := thumbnailOriginal(m.derivativePattern, key)
+	return ok && original != key
+}
+
+// derivativeProviderFor returns the derivative provider when key names a derivative.
+func (m *Manager) derivativeProviderFor(key string) (Uploader, bool) {
+	if m.derivativeProvider == nil || !m.isDerivativeKey(key) {
+		return nil, false
+	}
+	return m.derivativeProvider, true
+}
+
+// DerivativeURL returns a URL for the variant derivative of name. Derivatives on a provider that
+// builds public URLs (a bucket with WithAWSPublicBaseURL, a directory with WithFSURLPrefix) get
+// that unsigned URL; otherwise a presigned URL valid for expires is returned.
+func (m *Manager) DerivativeURL(ctx context.Context, name, variant string, expires time.Duration) (string, error) {
+	return m.derivativeURL(ctx, m.ThumbnailKey(name, variant), expires)
+}
+
+func (m *Manager) derivativeURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	if provider, ok := m.derivativeProviderFor(key); ok {
+		if builder, ok := provider.(PublicURLBuilder); ok {
+			url, err := builder.PublicURL(key)
+			if !errors.Is(err, ErrNotImplemented) {
+				return url, err
+			}
+		}
+	}
+	return m.GetPresignedURL(ctx, key, expires)
+}
+
+// listDerivatives lists the derivatives of originals under prefix held by the derivative
+// provider. It returns nothing when derivatives share the default provider.
+func (m *Manager) listDerivatives(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	if m.derivativeProvider == nil {
+		return nil, nil
+	}
+	lister, ok := m.derivativeProvider.(Lister)
+	if !ok {
+		return nil, nil
+	}
+	return lister.List(ctx, m.thumbnailKeyTemplate().listPrefix(prefix))
+}
+
+// listPrefix returns the prefix shared by every derivative of originals under prefix: the literal
+// text before the first placeholder, followed by prefix when that placeholder is {key} or {base}.
+func (t ThumbnailKeyTemplate) listPrefix(prefix string) string {
+	s := string(t)
+	i := strings.Index(s, "{")
+	if i < 0 {
+		return s
+	}
+	literal := s[:i]
+	if strings.HasPrefix(s[i:], "{key}") || strings.HasPrefix(s[i:], "{base}") {
+		return literal + prefix
+	}
+	return literal
+}
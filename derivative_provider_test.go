@@ -0,0 +1,72 @@
+package uploader
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDerivativeProvider(t *testing.T) {
+	ctx := context.Background()
+	originals, derivatives := t.TempDir(), t.TempDir()
+	manager := NewManager(
+		WithProvider(NewFSProvider(originals, WithFSURLPrefix("/private"))),
+		WithDerivativeProvider(NewFSProvider(derivatives, WithFSURLPrefix("https://cdn.example.com"))),
+		WithThumbnailKeyTemplate(DerivativesFolderKeyTemplate),
+	)
+	sizes := []ThumbnailSize{{Name: "small", Width: 8, Height: 8, Fit: "cover"}}
+
+	file := newTestFileHeader(t, "file", "photo.png", "image/png", createTestPNG(32, 32))
+	meta, err := manager.HandleImageWithThumbnails(ctx, file, "images", sizes)
+	if err != nil {
+		t.Fatalf("HandleImageWithThumbnails: %v", err)
+	}
+
+	key := meta.ThumbnailKey("small")
+	if _, err := os.Stat(filepath.Join(derivatives, filepath.FromSlash(key))); err != nil {
+		t.Fatalf("expected thumbnail on the derivative provider: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(originals, filepath.FromSlash(key))); !os.IsNotExist(err) {
+		t.Fatalf("expected no thumbnail next to the originals, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(originals, filepath.FromSlash(meta.Name))); err != nil {
+		t.Fatalf("expected original on the default provider: %v", err)
+	}
+	if want := "https://cdn.example.com/" + key; meta.ThumbnailURL("small", "") != want {
+		t.Fatalf("expected thumbnail URL %q, got %q", want, meta.ThumbnailURL("small", ""))
+	}
+
+	if _, err := manager.GetFile(ctx, key); err != nil {
+		t.Fatalf("expected derivative reads routed to the derivative provider: %v", err)
+	}
+	url, err := manager.DerivativeURL(ctx, meta.Name, "small", time.Minute)
+	if err != nil || url != "https://cdn.example.com/"+key {
+		t.Fatalf("unexpected derivative URL %q (%v)", url, err)
+	}
+	url, err = manager.GetPresignedURL(ctx, meta.Name, time.Minute)
+	if err != nil || url != "/private/"+meta.Name {
+		t.Fatalf("unexpected original URL %q (%v)", url, err)
+	}
+
+	result, err := manager.RegenerateThumbnails(ctx, "images", sizes, RegenerateThumbnailsOptions{})
+	if err != nil {
+		t.Fatalf("RegenerateThumbnails: %v", err)
+	}
+	if result.Generated != 0 {
+		t.Fatalf("expected derivatives on the derivative provider to be found, got %+v", result)
+	}
+}
+
+func TestThumbnailKeyTemplateListPrefix(t *testing.T) {
+	for template, want := range map[ThumbnailKeyTemplate]string{
+		DerivativesFolderKeyTemplate:   "derivatives/images",
+		DefaultThumbnailKeyTemplate:    "images",
+		"thumbs/{variant}/{base}{ext}": "thumbs/",
+	} {
+		if got := template.listPrefix("images"); got != want {
+			t.Fatalf("%s: expected list prefix %q, got %q", template, want, got)
+		}
+	}
+}
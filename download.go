@@ -0,0 +1,51 @@
+package uploader
+
+import (
+	"context"
+	"mime"
+	"path"
+)
+
+// StatFile describes the object stored under key without downloading it when the provider
+// implements FileStatter; other providers fall back to a download and report only the size.
+// Content-Type and Cache-Control the provider did not record are derived from the key extension
+// and the cache policy, so the result can drive download response headers directly.
+func (m *Manager) StatFile(ctx context.Context, key string) (*ObjectInfo, error) {
+	if err := m.ensureProvider(ctx); err != nil {
+		return nil, err
+	}
+
+	var info *ObjectInfo
+	if statter, ok := m.providerFor(ctx, key).(FileStatter); ok {
+		stat, err := observeCall(ctx, m, "stat", key, func() (*ObjectInfo, error) {
+			return statter.StatFile(ctx, key)
+		})
+		if err != nil {
+			return nil, err
+		}
+		copied := *stat
+		info = &copied
+	} else {
+		content, err := m.GetFile(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		info = &ObjectInfo{Key: key, Size: int64(len(content))}
+	}
+
+	if info.ContentType == "" {
+		info.ContentType = mime.TypeByExtension(path.Ext(key))
+	}
+	if info.CacheControl == "" {
+		info.CacheControl = m.cacheControlFor(mediaType(info.ContentType))
+	}
+	if _, native := m.retentionController(ctx, key); !native {
+		retention, err := m.ensureRetentionStore().GetRetention(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		info.LegalHold, info.RetainUntil = retention.LegalHold, retention.RetainUntil
+	}
+
+	return info, nil
+}
@@ -0,0 +1,120 @@
+package uploader
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// ProviderDSN is the parsed form of a provider connection string:
+//
+//	fs:///var/uploads?url_prefix=/static&chunk_dir=/var/tmp/uploads
+//	s3://bucket/base/path?region=eu-west-1
+//	multi://?local=fs:///var/cache&remote=s3://bucket
+type ProviderDSN struct {
+	Scheme string
+	Bucket string
+	Path   string
+	Params url.Values
+}
+
+// ParseProviderDSN parses raw into a ProviderDSN without building the provider.
+func ParseProviderDSN(raw string) (*ProviderDSN, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, fmt.Errorf("provider dsn: empty dsn")
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("provider dsn: %w", err)
+	}
+
+	dsn := &ProviderDSN{
+		Scheme: strings.ToLower(u.Scheme),
+		Params: u.Query(),
+	}
+
+	switch dsn.Scheme {
+	case "fs", "file":
+		dsn.Scheme = "fs"
+		dsn.Path = u.Host + u.Path
+		if dsn.Path == "" {
+			return nil, fmt.Errorf("provider dsn: fs dsn requires a base path")
+		}
+	case "s3":
+		dsn.Bucket = u.Host
+		dsn.Path = strings.Trim(u.Path, "/")
+		if dsn.Bucket == "" {
+			return nil, fmt.Errorf("provider dsn: s3 dsn requires a bucket")
+		}
+	case "multi":
+		if dsn.Params.Get("local") == "" || dsn.Params.Get("remote") == "" {
+			return nil, fmt.Errorf("provider dsn: multi dsn requires local and remote params")
+		}
+	default:
+		return nil, fmt.Errorf("provider dsn: unsupported scheme %q", u.Scheme)
+	}
+
+	return dsn, nil
+}
+
+// Region returns the region requested in the DSN, if any.
+func (d *ProviderDSN) Region() string {
+	return d.Params.Get("region")
+}
+
+// S3ClientFactory builds an S3 client for the given DSN. Callers own credential loading so the
+// library does not depend on the AWS config package.
+type S3ClientFactory func(dsn *ProviderDSN) (*s3.Client, error)
+
+// NewProviderFromDSN builds a provider from a DSN string. newS3 is only required for s3 and
+// multi DSNs that reference S3.
+func NewProviderFromDSN(raw string, newS3 S3ClientFactory) (Uploader, error) {
+	dsn, err := ParseProviderDSN(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	switch dsn.Scheme {
+	case "fs":
+		var opts []FSProviderOption
+		if prefix := dsn.Params.Get("url_prefix"); prefix != "" {
+			opts = append(opts, WithFSURLPrefix(prefix))
+		}
+		if dir := dsn.Params.Get("chunk_dir"); dir != "" {
+			opts = append(opts, WithFSChunkDir(dir))
+		}
+		return NewFSProvider(dsn.Path, opts...), nil
+	case "s3":
+		if newS3 == nil {
+			return nil, fmt.Errorf("provider dsn: s3 client factory required for %s", dsn.Scheme)
+		}
+		client, err := newS3(dsn)
+		if err != nil {
+			return nil, fmt.Errorf("provider dsn: build s3 client: %w", err)
+		}
+		var opts []AWSProviderOption
+		if dsn.Path != "" {
+			opts = append(opts, WithAWSBasePath(dsn.Path))
+		}
+		return NewAWSProvider(client, dsn.Bucket, opts...), nil
+	case "multi":
+		local, err := NewProviderFromDSN(dsn.Params.Get("local"), newS3)
+		if err != nil {
+			return nil, err
+		}
+		localFS, ok := local.(*FSProvider)
+		if !ok {
+			return nil, fmt.Errorf("provider dsn: multi local provider must be fs")
+		}
+		remote, err := NewProviderFromDSN(dsn.Params.Get("remote"), newS3)
+		if err != nil {
+			return nil, err
+		}
+		return NewMultiProvider(localFS, remote), nil
+	}
+
+	return nil, fmt.Errorf("provider dsn: unsupported scheme %q", dsn.Scheme)
+}
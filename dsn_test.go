@@ -0,0 +1,67 @@
+package uploader
+
+import (
+	"testing"
+)
+
+func TestParseProviderDSN(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		scheme  string
+		bucket  string
+		path    string
+		wantErr bool
+	}{
+		{name: "fs absolute", raw: "fs:///var/uploads", scheme: "fs", path: "/var/uploads"},
+		{name: "file alias", raw: "file://./uploads", scheme: "fs", path: "./uploads"},
+		{name: "s3 with base path", raw: "s3://bucket/base/path?region=eu-west-1", scheme: "s3", bucket: "bucket", path: "base/path"},
+		{name: "multi", raw: "multi://?local=fs:///tmp/a&remote=s3://bucket", scheme: "multi"},
+		{name: "empty", raw: "", wantErr: true},
+		{name: "s3 without bucket", raw: "s3:///path", wantErr: true},
+		{name: "multi without remote", raw: "multi://?local=fs:///tmp/a", wantErr: true},
+		{name: "unknown scheme", raw: "ftp://host/path", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dsn, err := ParseProviderDSN(tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error for %q", tt.raw)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParseProviderDSN failed: %v", err)
+			}
+
+			if dsn.Scheme != tt.scheme || dsn.Bucket != tt.bucket || dsn.Path != tt.path {
+				t.Fatalf("unexpected dsn: %#v", dsn)
+			}
+		})
+	}
+}
+
+func TestNewProviderFromDSN(t *testing.T) {
+	dir := t.TempDir()
+
+	provider, err := NewProviderFromDSN("fs://"+dir+"?url_prefix=/static&chunk_dir=/var/tmp/parts", nil)
+	if err != nil {
+		t.Fatalf("NewProviderFromDSN failed: %v", err)
+	}
+
+	fsProvider, ok := provider.(*FSProvider)
+	if !ok {
+		t.Fatalf("expected *FSProvider, got %T", provider)
+	}
+
+	if fsProvider.base != dir || fsProvider.urlPrefix != "/static/" || fsProvider.chunkRoot != "/var/tmp/parts" {
+		t.Fatalf("unexpected provider config: %#v", fsProvider)
+	}
+
+	if _, err := NewProviderFromDSN("s3://bucket", nil); err == nil {
+		t.Fatalf("expected error when s3 client factory is missing")
+	}
+}
@@ -9,6 +9,10 @@ var (
 				WithCode(404).
 				WithTextCode("IMAGE_NOT_FOUND")
 
+	ErrFileExists = gerrors.New("file already exists", gerrors.CategoryConflict).
+			WithCode(409).
+			WithTextCode("FILE_EXISTS")
+
 	ErrPermissionDenied = gerrors.New("permission denied", gerrors.CategoryAuthz).
 				WithCode(403).
 				WithTextCode("PERMISSION_DENIED")
@@ -37,6 +41,18 @@ var (
 				WithCode(409).
 				WithTextCode("CHUNK_SESSION_CLOSED")
 
+	ErrChunkSessionConflict = gerrors.New("chunk session was modified concurrently", gerrors.CategoryConflict).
+				WithCode(409).
+				WithTextCode("CHUNK_SESSION_CONFLICT")
+
+	ErrChunkSessionLifetimeExceeded = gerrors.New("chunk session reached its maximum lifetime", gerrors.CategoryConflict).
+					WithCode(409).
+					WithTextCode("CHUNK_SESSION_LIFETIME_EXCEEDED")
+
+	ErrChunkSessionSchemaUnsupported = gerrors.New("chunk session schema version is not supported", gerrors.CategoryBadInput).
+						WithCode(400).
+						WithTextCode("CHUNK_SESSION_SCHEMA_UNSUPPORTED")
+
 	ErrChunkPartOutOfRange = gerrors.New("chunk part index is out of range", gerrors.CategoryBadInput).
 				WithCode(400).
 				WithTextCode("CHUNK_PART_OUT_OF_RANGE")
@@ -44,4 +60,52 @@ var (
 	ErrChunkPartDuplicate = gerrors.New("chunk part already uploaded", gerrors.CategoryConflict).
 				WithCode(409).
 				WithTextCode("CHUNK_PART_DUPLICATE")
+
+	ErrConfirmationSecretNotConfigured = gerrors.New("confirmation secret not configured", gerrors.CategoryInternal).
+						WithCode(500).
+						WithTextCode("CONFIRMATION_SECRET_NOT_CONFIGURED")
+
+	ErrInvalidConfirmationToken = gerrors.New("invalid confirmation token", gerrors.CategoryAuthz).
+					WithCode(403).
+					WithTextCode("INVALID_CONFIRMATION_TOKEN")
+
+	ErrConfirmationTokenExpired = gerrors.New("confirmation token expired", gerrors.CategoryAuthz).
+					WithCode(403).
+					WithTextCode("CONFIRMATION_TOKEN_EXPIRED")
+
+	ErrInvalidReservation = gerrors.New("invalid or expired upload reservation", gerrors.CategoryAuthz).
+				WithCode(403).
+				WithTextCode("INVALID_UPLOAD_RESERVATION")
+
+	ErrReservationMismatch = gerrors.New("upload does not match its reservation", gerrors.CategoryAuthz).
+				WithCode(403).
+				WithTextCode("UPLOAD_RESERVATION_MISMATCH")
+
+	ErrInvalidStagingToken = gerrors.New("invalid staging token", gerrors.CategoryBadInput).
+				WithCode(400).
+				WithTextCode("INVALID_STAGING_TOKEN")
+
+	ErrObjectArchived = gerrors.New("object is archived; restore it before reading", gerrors.CategoryConflict).
+				WithCode(409).
+				WithTextCode("OBJECT_ARCHIVED")
+
+	ErrInvalidVisibility = gerrors.New("invalid visibility", gerrors.CategoryBadInput).
+				WithCode(400).
+				WithTextCode("INVALID_VISIBILITY")
+
+	ErrServiceReadOnly = gerrors.New("service is read-only", gerrors.CategoryOperation).
+				WithCode(503).
+				WithTextCode("SERVICE_READ_ONLY")
+
+	ErrProviderPanic = gerrors.New("storage provider panicked", gerrors.CategoryInternal).
+				WithCode(500).
+				WithTextCode("PROVIDER_PANIC")
+
+	ErrAppendConflict = gerrors.New("object was modified during append", gerrors.CategoryConflict).
+				WithCode(409).
+				WithTextCode("APPEND_CONFLICT")
+
+	ErrComposeConflict = gerrors.New("source object was modified during compose", gerrors.CategoryConflict).
+				WithCode(409).
+				WithTextCode("COMPOSE_CONFLICT")
 )
@@ -0,0 +1,123 @@
+package uploader
+
+import (
+	"context"
+	"time"
+)
+
+// Event is a lifecycle notification delivered to EventHandler. Switch on the concrete type to
+// read its payload.
+type Event interface {
+	EventName() string
+}
+
+// ThumbnailGenerated is emitted for every derivative stored by HandleImageWithThumbnails or
+// RegenerateThumbnails.
+type ThumbnailGenerated struct {
+	Original    string `json:"original"`
+	Key         string `json:"key"`
+	Size        string `json:"size"`
+	ContentType string `json:"content_type"`
+	Bytes       int64  `json:"bytes"`
+}
+
+func (e *ThumbnailGenerated) EventName() string { return "thumbnail.generated" }
+
+// ChunkSessionStarted is emitted once a chunked session is registered with the provider.
+type ChunkSessionStarted struct {
+	SessionID string    `json:"session_id"`
+	Key       string    `json:"key"`
+	TotalSize int64     `json:"total_size"`
+	PartSize  int64     `json:"part_size"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (e *ChunkSessionStarted) EventName() string { return "chunk.session_started" }
+
+// ChunkCompleted is emitted for every part UploadChunk stores. Uploaded counts the parts the
+// session holds so far, letting consumers report progress without querying the session.
+type ChunkCompleted struct {
+	SessionID     string `json:"session_id"`
+	Key           string `json:"key"`
+	Index         int    `json:"index"`
+	Size          int64  `json:"size"`
+	Uploaded      int    `json:"uploaded"`
+	UploadedBytes int64  `json:"uploaded_bytes"`
+	TotalSize     int64  `json:"total_size"`
+}
+
+func (e *ChunkCompleted) EventName() string { return "chunk.completed" }
+
+// ChunkSessionCompleted is emitted once CompleteChunked has assembled the object.
+type ChunkSessionCompleted struct {
+	SessionID string `json:"session_id"`
+	Key       string `json:"key"`
+	Size      int64  `json:"size"`
+}
+
+func (e *ChunkSessionCompleted) EventName() string { return "chunk.session_completed" }
+
+// ChunkSessionAborted is emitted when AbortChunked or ForceAbort closes a session, even if the
+// provider then fails to release its staged parts.
+type ChunkSessionAborted struct {
+	SessionID string `json:"session_id"`
+	Key       string `json:"key"`
+}
+
+func (e *ChunkSessionAborted) EventName() string { return "chunk.session_aborted" }
+
+// PresignKind names the kind of presigned access handed to a client.
+type PresignKind string
+
+const (
+	// PresignKindURL is a download URL from GetPresignedURL.
+	PresignKindURL PresignKind = "url"
+	// PresignKindPost is a browser upload form from CreatePresignedPost.
+	PresignKindPost PresignKind = "post"
+	// PresignKindChunked is the set of part requests from InitiatePresignedChunked.
+	PresignKindChunked PresignKind = "chunked"
+)
+
+// PresignIssued is emitted whenever the manager hands out presigned access to a key.
+type PresignIssued struct {
+	Key       string      `json:"key"`
+	Kind      PresignKind `json:"kind"`
+	ExpiresAt time.Time   `json:"expires_at"`
+}
+
+func (e *PresignIssued) EventName() string { return "presign.issued" }
+
+// EventHandler receives lifecycle events. It runs synchronously on the calling goroutine, so slow
+// handlers should hand events off. CorrelationIDFromContext(ctx) returns the ID of the request
+// that caused the event.
+type EventHandler func(ctx context.Context, event Event)
+
+// WithEventHandler registers a handler for lifecycle events. It can be used several times;
+// handlers run in registration order.
+func WithEventHandler(handler EventHandler) Option {
+	return func(m *Manager) {
+		if handler != nil {
+			m.eventHandlers = append(m.eventHandlers, handler)
+		}
+	}
+}
+
+func (m *Manager) emitEvent(ctx context.Context, event Event) {
+	if len(m.eventHandlers) == 0 {
+		return
+	}
+	ctx = m.correlationContext(ctx)
+	for _, handler := range m.eventHandlers {
+		handler(ctx, event)
+	}
+}
+
+func (m *Manager) emitThumbnailGenerated(ctx context.Context, original, key, size, contentType string, content []byte) {
+	m.emitEvent(ctx, &ThumbnailGenerated{
+		Original:    original,
+		Key:         key,
+		Size:        size,
+		ContentType: contentType,
+		Bytes:       int64(len(content)),
+	})
+}
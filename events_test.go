@@ -0,0 +1,65 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestEventHandlerReceivesLifecycleEvents(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	var events []Event
+	manager := NewManager(
+		WithProvider(newMemoryProvider()),
+		WithClock(func() time.Time { return now }),
+		WithEventHandler(func(ctx context.Context, event Event) {
+			events = append(events, event)
+		}),
+	)
+
+	session, err := manager.InitiateChunked(ctx, "chunks/file.bin", 8)
+	if err != nil {
+		t.Fatalf("InitiateChunked: %v", err)
+	}
+	if err := manager.UploadChunk(ctx, session.ID, 0, bytes.NewReader([]byte("abcd"))); err != nil {
+		t.Fatalf("UploadChunk: %v", err)
+	}
+	if _, err := manager.GetPresignedURL(ctx, "chunks/file.bin", time.Minute); err != nil {
+		t.Fatalf("GetPresignedURL: %v", err)
+	}
+
+	header := newTestFileHeader(t, "file", "sample.png", "image/png", createTestPNG(20, 20))
+	if _, err := manager.HandleImageWithThumbnails(ctx, header, "images", []ThumbnailSize{{Name: "small", Width: 8, Height: 8, Fit: "cover"}}); err != nil {
+		t.Fatalf("HandleImageWithThumbnails: %v", err)
+	}
+
+	if len(events) != 4 {
+		t.Fatalf("expected 4 events, got %d: %#v", len(events), events)
+	}
+
+	started, ok := events[0].(*ChunkSessionStarted)
+	if !ok || started.SessionID != session.ID || started.TotalSize != 8 || !started.ExpiresAt.Equal(now.Add(DefaultChunkSessionTTL)) {
+		t.Fatalf("unexpected session started event: %#v", events[0])
+	}
+
+	chunk, ok := events[1].(*ChunkCompleted)
+	if !ok || chunk.Index != 0 || chunk.Size != 4 || chunk.Uploaded != 1 || chunk.Key != "chunks/file.bin" {
+		t.Fatalf("unexpected chunk completed event: %#v", events[1])
+	}
+
+	presign, ok := events[2].(*PresignIssued)
+	if !ok || presign.Kind != PresignKindURL || !presign.ExpiresAt.Equal(now.Add(time.Minute)) {
+		t.Fatalf("unexpected presign event: %#v", events[2])
+	}
+
+	thumb, ok := events[3].(*ThumbnailGenerated)
+	if !ok || thumb.Size != "small" || thumb.Key != BuildThumbnailKey(thumb.Original, "small") || thumb.Bytes == 0 {
+		t.Fatalf("unexpected thumbnail event: %#v", events[3])
+	}
+	if thumb.EventName() != "thumbnail.generated" {
+		t.Fatalf("unexpected event name %q", thumb.EventName())
+	}
+}
@@ -17,7 +17,7 @@ func main() {
 		panic(err)
 	}
 
-	provider := uploader.NewFSProvider(baseDir)
+	provider := uploader.NewFSProvider(baseDir, uploader.WithFSChunkDir("./.example-chunk-parts"))
 	manager := uploader.NewManager(uploader.WithProvider(provider))
 
 	data := bytes.Repeat([]byte("chunked-upload-"), 32)
@@ -21,10 +21,12 @@ func main() {
 		panic(err)
 	}
 
-	fmt.Println("Upload form action:", post.URL)
-	for field, value := range post.Fields {
-		fmt.Printf("Field %s=%s\n", field, value)
+	form, err := post.HTMLForm("text/plain")
+	if err != nil {
+		panic(err)
 	}
+	fmt.Println("Upload form:")
+	fmt.Println(form)
 
 	meta, err := manager.ConfirmPresignedUpload(ctx, &uploader.PresignedUploadResult{
 		Key:         "uploads/demo.txt",
@@ -97,12 +97,14 @@ func WithUploadService(ctx context.Context, app *App) error {
 	}
 
 	client := s3.NewFromConfig(s3Cfg, opts)
-	awsProvider := uploader.NewAWSProvider(client, cfg.S3.Bucket)
-	awsProvider.WithLogger(app.Logger("svc.img.aws"))
-	awsProvider.WithBasePath(cfg.S3.BasePath)
+	awsProvider := uploader.NewAWSProvider(client, cfg.S3.Bucket,
+		uploader.WithAWSLogger(app.Logger("svc.img.aws")),
+		uploader.WithAWSBasePath(cfg.S3.BasePath),
+	)
 
-	localProvider := uploader.NewFSProvider(cfg.Fs.BasePath)
-	localProvider.WithLogger(app.Logger("svc.img.fs"))
+	localProvider := uploader.NewFSProvider(cfg.Fs.BasePath,
+		uploader.WithFSLogger(app.Logger("svc.img.fs")),
+	)
 
 	multi := uploader.NewMultiProvider(localProvider, awsProvider)
 
@@ -140,6 +142,9 @@ func createRoutes(server router.Server[*fiber.App], app *App) {
 	// Root route with upload form and gallery
 	server.Router().Get("/", homeHandler(app))
 
+	// Read-only gallery rendered by the uploader index generator
+	server.Router().Get("/gallery", galleryHandler(app))
+
 	// API routes
 	api := server.Router().Group("/api")
 	api.Use(router.ToMiddleware(func(c router.Context) error {
@@ -252,6 +257,13 @@ func getFileHandler(app *App) router.HandlerFunc {
 		// Build file path
 		filePath := "uploads/" + filename
 
+		// Describe the stored object so the response carries its own headers
+		info, err := app.UploadsManager().StatFile(ctx.Context(), filePath)
+		if err != nil {
+			app.Logger("upload").Error("failed to stat file", err)
+			return err
+		}
+
 		// Get file content
 		content, err := app.UploadsManager().GetFile(ctx.Context(), filePath)
 		if err != nil {
@@ -259,8 +271,21 @@ func getFileHandler(app *App) router.HandlerFunc {
 			return err
 		}
 
-		// Set appropriate headers and return file content
-		ctx.SetHeader("Content-Type", "application/octet-stream")
+		// Set headers from the stored metadata and return file content
+		contentType := info.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		ctx.SetHeader("Content-Type", contentType)
+		if info.CacheControl != "" {
+			ctx.SetHeader("Cache-Control", info.CacheControl)
+		}
+		if info.ETag != "" {
+			ctx.SetHeader("ETag", `"`+info.ETag+`"`)
+		}
+		if !info.ModTime.IsZero() {
+			ctx.SetHeader("Last-Modified", info.ModTime.UTC().Format(http.TimeFormat))
+		}
 		ctx.SetHeader("Content-Disposition", "attachment; filename="+filename)
 
 		return ctx.Send(content)
@@ -347,6 +372,17 @@ func homeHandler(app *App) router.HandlerFunc {
 	}
 }
 
+func galleryHandler(app *App) router.HandlerFunc {
+	return func(ctx router.Context) error {
+		html, err := app.UploadsManager().GenerateIndex(ctx.Context(), "uploads/", uploader.DefaultIndexTemplate)
+		if err != nil {
+			return err
+		}
+		ctx.SetHeader("Content-Type", "text/html")
+		return ctx.SendString(string(html))
+	}
+}
+
 func getUploadedFiles(app *App) ([]string, error) {
 	// Read files from S3 filesystem instead of local directory
 	files := []string{}
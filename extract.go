@@ -0,0 +1,354 @@
+package uploader
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"math"
+	"mime/multipart"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	gerrors "github.com/goliatone/go-errors"
+)
+
+// ExtractFormat selects how ExtractStored decodes a stored archive.
+type ExtractFormat string
+
+const (
+	ExtractZip   ExtractFormat = "zip"
+	ExtractTar   ExtractFormat = "tar"
+	ExtractTarGz ExtractFormat = "tar.gz"
+)
+
+// ExtractOptions guards an ExtractStored call.
+type ExtractOptions struct {
+	// Format overrides detection from the archive key's extension (.zip, .tar, .tar.gz, .tgz).
+	Format ExtractFormat
+	// MaxEntries is the most files written from the archive. Zero means
+	// DefaultExtractMaxEntries; a negative value disables the guard.
+	MaxEntries int
+	// MaxTotalSize is the most uncompressed bytes written from the archive. Zero means
+	// DefaultExtractMaxTotalSize; a negative value disables the guard.
+	MaxTotalSize int64
+	// SkipInvalid leaves out entries that fail validation and lists them in
+	// ExtractResult.Skipped instead of failing the extraction.
+	SkipInvalid bool
+}
+
+// ExtractResult describes an extraction. Keys lists the stored objects in archive order; they
+// may differ from the entry names when a collision policy picked other keys.
+type ExtractResult struct {
+	Archive  string        `json:"archive"`
+	Prefix   string        `json:"prefix"`
+	Keys     []string      `json:"keys"`
+	Skipped  []string      `json:"skipped,omitempty"`
+	Size     int64         `json:"size"`
+	Duration time.Duration `json:"duration"`
+}
+
+// ExtractStored unpacks the zip or tar archive stored under archiveKey into objects under
+// destPrefix, keeping the entry paths. Every file is validated like an upload to HandleFile and
+// stored with UploadFileResult; directories, links and other special entries are ignored, and
+// entry paths that are absolute or climb out with ".." are rejected. Tar archives are streamed
+// from the provider. Zip archives need random access, which providers implementing RangeReader
+// and FileStatter serve with ranged reads; others are downloaded first. Entries are buffered one
+// at a time, up to the validator's maximum file size. When the extraction fails, the objects
+// already written are handled by the partial upload policy.
+func (m *Manager) ExtractStored(ctx context.Context, archiveKey, destPrefix string, opts ExtractOptions) (*ExtractResult, error) {
+	if err := m.ensureWritable(); err != nil {
+		return nil, err
+	}
+	if err := m.validateKey(archiveKey); err != nil {
+		return nil, err
+	}
+	if err := m.ensureProvider(ctx); err != nil {
+		return nil, err
+	}
+
+	format := opts.Format
+	if format == "" {
+		format = detectExtractFormat(archiveKey)
+	}
+
+	x := &extractor{
+		m:          m,
+		ctx:        ctx,
+		skip:       opts.SkipInvalid,
+		maxEntries: opts.MaxEntries,
+		maxSize:    opts.MaxTotalSize,
+		result:     &ExtractResult{Archive: archiveKey, Prefix: strings.TrimPrefix(destPrefix, "/")},
+	}
+	if x.maxEntries == 0 {
+		x.maxEntries = DefaultExtractMaxEntries
+	}
+	if x.maxSize == 0 {
+		x.maxSize = DefaultExtractMaxTotalSize
+	}
+
+	started := time.Now()
+	var err error
+	switch format {
+	case ExtractZip:
+		err = m.extractZip(ctx, archiveKey, x.add)
+	case ExtractTar, ExtractTarGz:
+		err = m.extractTar(ctx, archiveKey, format == ExtractTarGz, x.add)
+	default:
+		err = gerrors.NewValidation("extract failed", gerrors.FieldError{
+			Field:   "format",
+			Message: "unsupported archive format; use zip, tar or tar.gz",
+			Value:   string(format),
+		})
+	}
+	if err != nil {
+		if len(x.result.Keys) > 0 {
+			m.rollbackPartial(ctx, x.result.Keys...)
+		}
+		return nil, m.correlateError(ctx, err)
+	}
+
+	x.result.Duration = time.Since(started)
+	return x.result, nil
+}
+
+func detectExtractFormat(key string) ExtractFormat {
+	lower := strings.ToLower(key)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return ExtractZip
+	case strings.HasSuffix(lower, ".tar"):
+		return ExtractTar
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return ExtractTarGz
+	default:
+		return ""
+	}
+}
+
+func (m *Manager) extractTar(ctx context.Context, key string, gzipped bool, add func(name string, r io.Reader) error) error {
+	rc, err := m.OpenRange(ctx, key, 0, -1)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	var src io.Reader = rc
+	if gzipped {
+		gz, err := gzip.NewReader(rc)
+		if err != nil {
+			return errInvalidArchive(key, err)
+		}
+		defer gz.Close()
+		src = gz
+	}
+
+	tr := tar.NewReader(src)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return errInvalidArchive(key, err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := add(header.Name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+func (m *Manager) extractZip(ctx context.Context, key string, add func(name string, r io.Reader) error) error {
+	var src io.ReaderAt
+	var size int64
+
+	provider := m.providerFor(ctx, key)
+	_, ranged := provider.(RangeReader)
+	if _, stats := provider.(FileStatter); ranged && stats {
+		info, err := m.StatFile(ctx, key)
+		if err != nil {
+			return err
+		}
+		ra := &objectReaderAt{ctx: ctx, m: m, key: key}
+		defer ra.Close()
+		src, size = ra, info.Size
+	} else {
+		content, err := m.GetFile(ctx, key)
+		if err != nil {
+			return err
+		}
+		src, size = bytes.NewReader(content), int64(len(content))
+	}
+
+	zr, err := zip.NewReader(src, size)
+	if err != nil {
+		return errInvalidArchive(key, err)
+	}
+	for _, file := range zr.File {
+		if !file.Mode().IsRegular() {
+			continue
+		}
+		r, err := file.Open()
+		if err != nil {
+			return errInvalidArchive(key, err)
+		}
+		err = add(file.Name, r)
+		_ = r.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func errInvalidArchive(key string, err error) error {
+	return gerrors.NewValidation("extract failed", gerrors.FieldError{
+		Field:   "archive",
+		Message: "archive is corrupt or not in the expected format: " + err.Error(),
+		Value:   key,
+	})
+}
+
+// extractor validates and stores the entries of one ExtractStored call.
+type extractor struct {
+	m          *Manager
+	ctx        context.Context
+	skip       bool
+	maxEntries int
+	maxSize    int64
+	result     *ExtractResult
+}
+
+func (x *extractor) add(name string, r io.Reader) error {
+	if x.maxEntries > 0 && len(x.result.Keys) >= x.maxEntries {
+		return x.limitError("max_entries", x.maxEntries)
+	}
+
+	key, err := extractKey(x.result.Prefix, name)
+	if err == nil {
+		err = x.m.validateKey(key)
+	}
+	if err != nil {
+		return x.invalid(name, err)
+	}
+
+	// One byte past the limit is enough for the validator to report the entry as too large.
+	limit := x.m.validator.MaxFileSize()
+	if limit < math.MaxInt64 {
+		limit++
+	}
+	content, err := x.m.buffers.ReadAll(io.LimitReader(r, limit))
+	if err != nil {
+		return errInvalidArchive(x.result.Archive, err)
+	}
+	if x.maxSize > 0 && x.result.Size+int64(len(content)) > x.maxSize {
+		return x.limitError("max_total_size", x.maxSize)
+	}
+
+	file := &multipart.FileHeader{Filename: path.Base(key), Size: int64(len(content))}
+	contentType := resolveContentType(x.ctx, file, bytes.NewReader(content))
+	if err := x.m.validator.validateFile(file, contentType); err != nil {
+		return x.invalid(name, err)
+	}
+	if err := x.m.validator.validateContent(contentType, int64(len(content)), content, bytes.NewReader(content)); err != nil {
+		return x.invalid(name, err)
+	}
+
+	stored, err := x.m.UploadFileResult(x.ctx, key, content, WithContentType(contentType))
+	if err != nil {
+		return err
+	}
+	x.result.Keys = append(x.result.Keys, stored.Key)
+	x.result.Size += stored.Size
+	return nil
+}
+
+func (x *extractor) invalid(name string, err error) error {
+	if !x.skip {
+		return err
+	}
+	x.m.log(x.ctx).Info("skipping archive entry", "archive", x.result.Archive, "entry", name, "error", err)
+	x.result.Skipped = append(x.result.Skipped, name)
+	return nil
+}
+
+func (x *extractor) limitError(limit string, value any) error {
+	return gerrors.New("archive exceeds extraction limits", gerrors.CategoryBadInput).
+		WithCode(400).
+		WithTextCode("EXTRACT_LIMIT_EXCEEDED").
+		WithMetadata(map[string]any{
+			"archive": x.result.Archive,
+			"limit":   limit,
+			"value":   value,
+		})
+}
+
+// extractKey places an entry name under prefix, rejecting names that are absolute or climb out
+// of it.
+func extractKey(prefix, name string) (string, error) {
+	name = strings.ReplaceAll(name, "\\", "/")
+	if name == "" || path.IsAbs(name) {
+		return "", ErrInvalidPath
+	}
+	for _, segment := range strings.Split(name, "/") {
+		if segment == ".." {
+			return "", ErrInvalidPath
+		}
+	}
+	return path.Join(prefix, path.Clean(name)), nil
+}
+
+// objectReaderAt serves ReadAt from ranged reads of a stored object. Sequential reads, the bulk
+// of decompressing a zip entry, continue on the open stream instead of issuing a request each.
+type objectReaderAt struct {
+	ctx context.Context
+	m   *Manager
+	key string
+
+	mu  sync.Mutex
+	rc  io.ReadCloser
+	pos int64
+}
+
+func (r *objectReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.rc == nil || off != r.pos {
+		r.close()
+		rc, err := r.m.OpenRange(r.ctx, r.key, off, -1)
+		if err != nil {
+			return 0, err
+		}
+		r.rc, r.pos = rc, off
+	}
+
+	n, err := io.ReadFull(r.rc, p)
+	r.pos += int64(n)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		err = io.EOF
+	}
+	return n, err
+}
+
+func (r *objectReaderAt) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.close()
+	return nil
+}
+
+func (r *objectReaderAt) close() {
+	if r.rc != nil {
+		_ = r.rc.Close()
+		r.rc = nil
+	}
+}
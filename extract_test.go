@@ -0,0 +1,151 @@
+package uploader
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"testing"
+
+	gerrors "github.com/goliatone/go-errors"
+)
+
+type archiveEntry struct {
+	name string
+	data []byte
+}
+
+func buildZip(t *testing.T, entries []archiveEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, entry := range entries {
+		w, err := zw.Create(entry.name)
+		if err != nil {
+			t.Fatalf("zip create: %v", err)
+		}
+		w.Write(entry.data)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zip close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func buildTarGz(t *testing.T, entries []archiveEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, entry := range entries {
+		if err := tw.WriteHeader(&tar.Header{Name: entry.name, Mode: 0o644, Size: int64(len(entry.data)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatalf("tar header: %v", err)
+		}
+		tw.Write(entry.data)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("tar close: %v", err)
+	}
+	gz.Close()
+	return buf.Bytes()
+}
+
+func TestManagerExtractStored(t *testing.T) {
+	ctx := context.Background()
+	png := createTestPNG(8, 8)
+	entries := []archiveEntry{
+		{name: "photos/", data: nil},
+		{name: "photos/a.png", data: png},
+		{name: "notes.txt", data: []byte("not an image")},
+		{name: "../escape.png", data: png},
+		{name: "photos/nested/b.png", data: png},
+	}
+
+	for name, provider := range map[string]Uploader{
+		"ranged reads": NewFSProvider(t.TempDir()),
+		"download":     newMemoryProvider(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			manager := NewManager(WithProvider(provider))
+			if _, err := manager.UploadFile(ctx, "imports/batch.zip", buildZip(t, entries)); err != nil {
+				t.Fatalf("UploadFile: %v", err)
+			}
+
+			result, err := manager.ExtractStored(ctx, "imports/batch.zip", "gallery", ExtractOptions{SkipInvalid: true})
+			if err != nil {
+				t.Fatalf("ExtractStored: %v", err)
+			}
+			if len(result.Keys) != 2 || result.Keys[0] != "gallery/photos/a.png" || result.Keys[1] != "gallery/photos/nested/b.png" {
+				t.Fatalf("unexpected keys %v", result.Keys)
+			}
+			if len(result.Skipped) != 2 || result.Size != int64(2*len(png)) {
+				t.Fatalf("unexpected result %#v", result)
+			}
+			if content, err := manager.GetFile(ctx, "gallery/photos/nested/b.png"); err != nil || !bytes.Equal(content, png) {
+				t.Fatalf("expected the entry to be stored, got %v", err)
+			}
+		})
+	}
+}
+
+func TestManagerExtractStoredFailures(t *testing.T) {
+	ctx := context.Background()
+	png := createTestPNG(8, 8)
+	manager := NewManager(WithProvider(NewFSProvider(t.TempDir())))
+
+	zipped := buildZip(t, []archiveEntry{{name: "a.png", data: png}, {name: "notes.txt", data: []byte("text")}})
+	if _, err := manager.UploadFile(ctx, "batch.zip", zipped); err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	if _, err := manager.ExtractStored(ctx, "batch.zip", "out", ExtractOptions{}); !gerrors.IsValidation(err) {
+		t.Fatalf("expected the invalid entry to fail the extraction, got %v", err)
+	}
+	if _, err := manager.GetFile(ctx, "out/a.png"); err == nil {
+		t.Fatalf("expected entries stored before the failure to be cleaned up")
+	}
+
+	tarred := buildTarGz(t, []archiveEntry{{name: "a.png", data: png}, {name: "b.png", data: png}})
+	if _, err := manager.UploadFile(ctx, "batch.tgz", tarred); err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	_, err := manager.ExtractStored(ctx, "batch.tgz", "out", ExtractOptions{MaxEntries: 1})
+	assertTextCode(t, err, "EXTRACT_LIMIT_EXCEEDED")
+
+	result, err := manager.ExtractStored(ctx, "batch.tgz", "out", ExtractOptions{})
+	if err != nil || len(result.Keys) != 2 {
+		t.Fatalf("expected the tar archive to extract, got %#v, %v", result, err)
+	}
+
+	if _, err := manager.ExtractStored(ctx, "batch.tgz", "out", ExtractOptions{Format: ExtractZip}); !gerrors.IsValidation(err) {
+		t.Fatalf("expected a corrupt archive error, got %v", err)
+	}
+	if _, err := manager.UploadFile(ctx, "batch.rar", []byte("rar")); err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	if _, err := manager.ExtractStored(ctx, "batch.rar", "out", ExtractOptions{}); !gerrors.IsValidation(err) {
+		t.Fatalf("expected an unsupported format error, got %v", err)
+	}
+}
+
+func TestExtractKey(t *testing.T) {
+	for name, want := range map[string]string{
+		"a.png":           "imports/a.png",
+		"dir/./b.png":     "imports/dir/b.png",
+		`win\dir\c.png`:   "imports/win/dir/c.png",
+		"../d.png":        "",
+		"dir/../../e.png": "",
+		"/etc/passwd":     "",
+	} {
+		key, err := extractKey("imports", name)
+		if want == "" {
+			if err == nil {
+				t.Fatalf("expected %q to be rejected, got %q", name, key)
+			}
+			continue
+		}
+		if err != nil || key != want {
+			t.Fatalf("extractKey(%q) = %q, %v; want %q", name, key, err, want)
+		}
+	}
+}
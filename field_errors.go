@@ -0,0 +1,96 @@
+package uploader
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"maps"
+
+	gerrors "github.com/goliatone/go-errors"
+)
+
+// WithAggregatedErrors makes ValidateFile and ValidateFileContent run every rule and return one
+// VALIDATION_FAILED error listing all failing fields, instead of stopping at the first. A single
+// failure is returned as is, keeping its own text code.
+func WithAggregatedErrors(enabled bool) ValidatorOption {
+	return func(uv *Validator) {
+		uv.aggregateErrors = enabled
+	}
+}
+
+// WithMaxImageDimensions rejects images wider than width or taller than height pixels. Zero leaves
+// that dimension unchecked; content whose dimensions cannot be decoded is not checked.
+func WithMaxImageDimensions(width, height int) ValidatorOption {
+	return func(uv *Validator) {
+		uv.maxImageWidth = width
+		uv.maxImageHeight = height
+	}
+}
+
+func (u *Validator) validateDimensions(r io.Reader) error {
+	if u.maxImageWidth <= 0 && u.maxImageHeight <= 0 {
+		return nil
+	}
+
+	var width, height int
+	if cfg, _, err := image.DecodeConfig(r); err == nil {
+		width, height = cfg.Width, cfg.Height
+	}
+	if (u.maxImageWidth <= 0 || width <= u.maxImageWidth) && (u.maxImageHeight <= 0 || height <= u.maxImageHeight) {
+		return nil
+	}
+
+	return u.fieldError("dimensions", "IMAGE_TOO_LARGE", fmt.Sprintf("%dx%d", width, height), map[string]any{
+		"max_width":  u.maxImageWidth,
+		"max_height": u.maxImageHeight,
+	})
+}
+
+// check runs rules in order, stopping at the first failure unless errors are aggregated.
+func (u *Validator) check(rules ...func() error) error {
+	var failed []error
+	for _, rule := range rules {
+		if err := rule(); err != nil {
+			if !u.aggregateErrors {
+				return err
+			}
+			failed = append(failed, err)
+		}
+	}
+	return mergeValidationErrors(failed)
+}
+
+// mergeValidationErrors folds the field errors and metadata of failed into a single validation
+// error. The individual text codes are kept in the "text_codes" metadata entry.
+func mergeValidationErrors(failed []error) error {
+	switch len(failed) {
+	case 0:
+		return nil
+	case 1:
+		return failed[0]
+	}
+
+	var fields []gerrors.FieldError
+	var messages []ValidationMessage
+	metadata := map[string]any{}
+	codes := make([]string, 0, len(failed))
+	for _, err := range failed {
+		var validationErr *gerrors.Error
+		if !errors.As(err, &validationErr) {
+			fields = append(fields, gerrors.FieldError{Message: err.Error()})
+			continue
+		}
+		fields = append(fields, validationErr.ValidationErrors...)
+		messages = append(messages, ValidationMessages(err)...)
+		maps.Copy(metadata, validationErr.Metadata)
+		codes = append(codes, validationErr.TextCode)
+	}
+	metadata["text_codes"] = codes
+	metadata["messages"] = messages
+
+	return gerrors.NewValidation("file validation failed", fields...).
+		WithCode(400).
+		WithTextCode("VALIDATION_FAILED").
+		WithMetadata(metadata)
+}
@@ -0,0 +1,79 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"image"
+)
+
+// ProviderDescriber is implemented by providers that can report their name and the key an
+// object is stored under, which may differ from the logical path (base paths, prefixes).
+type ProviderDescriber interface {
+	ProviderName() string
+	ProviderKey(path string) string
+}
+
+// enrichFileMeta populates derived facts about a stored asset. Content may be nil when the
+// payload never passed through the manager (chunked or presigned uploads).
+func (m *Manager) enrichFileMeta(ctx context.Context, meta *FileMeta, content []byte, storageClass string) {
+	if meta == nil {
+		return
+	}
+
+	if meta.UploadedAt.IsZero() {
+		meta.UploadedAt = m.now()
+	}
+
+	if meta.StorageClass == "" {
+		meta.StorageClass = storageClass
+	}
+
+	provider := m.providerFor(withRouteInfo(ctx, meta.ContentType, meta.Size), meta.Name)
+	if describer, ok := provider.(ProviderDescriber); ok {
+		if meta.StorageProvider == "" {
+			meta.StorageProvider = describer.ProviderName()
+		}
+		if meta.ProviderKey == "" {
+			meta.ProviderKey = describer.ProviderKey(meta.Name)
+		}
+	}
+
+	if meta.ProviderKey == "" {
+		meta.ProviderKey = meta.Name
+	}
+
+	if meta.Ref == nil {
+		meta.Ref = m.objectRef(provider, meta.Name)
+	}
+
+	if meta.PublicKey == "" && m.keyCodec != nil {
+		meta.PublicKey = m.keyCodec.Encode(meta.Name)
+	}
+
+	if len(content) == 0 {
+		return
+	}
+
+	if meta.Checksum == "" {
+		meta.Checksum = checksumSHA256(content)
+	}
+
+	if meta.Width == 0 && meta.Height == 0 {
+		meta.Width, meta.Height = imageDimensions(content)
+	}
+}
+
+func checksumSHA256(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func imageDimensions(content []byte) (int, int) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return 0, 0
+	}
+	return cfg.Width, cfg.Height
+}
@@ -0,0 +1,64 @@
+package uploader
+
+import "encoding/json"
+
+// fileMetaFields has FileMeta's fields and tags without its methods, so the JSON methods can
+// encode it without recursing.
+type fileMetaFields FileMeta
+
+// MarshalJSON encodes the metadata for clients. When a KeyCodec set PublicKey, the storage key
+// is not disclosed: name carries PublicKey, the url, provider_key and ref fields, which embed
+// the storage key, are left empty, and the original_key attribute is dropped.
+func (m FileMeta) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.public())
+}
+
+// MarshalJSON encodes the original and its thumbnails, keeping the FileMeta redaction. Without
+// it, the MarshalJSON promoted from the embedded FileMeta would drop the thumbnails.
+func (m ImageMeta) MarshalJSON() ([]byte, error) {
+	var original fileMetaFields
+	if m.FileMeta != nil {
+		original = m.FileMeta.public()
+	}
+	return json.Marshal(struct {
+		fileMetaFields
+		Thumbnails map[string]*FileMeta `json:"thumbnails"`
+	}{original, m.Thumbnails})
+}
+
+func (m FileMeta) public() fileMetaFields {
+	fields := fileMetaFields(m)
+	if fields.PublicKey != "" {
+		fields.Name = fields.PublicKey
+		fields.URL = ""
+		fields.ProviderKey = ""
+		fields.Ref = nil
+		if _, ok := fields.Attributes["original_key"]; ok {
+			attrs := make(map[string]string, len(fields.Attributes))
+			for k, v := range fields.Attributes {
+				if k != "original_key" {
+					attrs[k] = v
+				}
+			}
+			fields.Attributes = attrs
+		}
+	}
+	return fields
+}
+
+// uploadResultFields has UploadResult's fields and tags without its methods.
+type uploadResultFields UploadResult
+
+// MarshalJSON encodes the result for clients. When a KeyCodec set PublicKey, key and
+// previous_version carry the encoded keys and the url and ref fields are left empty, like
+// FileMeta's.
+func (r UploadResult) MarshalJSON() ([]byte, error) {
+	fields := uploadResultFields(r)
+	if fields.PublicKey != "" {
+		fields.Key = fields.PublicKey
+		fields.PreviousVersion = r.publicPreviousVersion
+		fields.URL = ""
+		fields.Ref = nil
+	}
+	return json.Marshal(fields)
+}
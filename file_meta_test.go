@@ -0,0 +1,64 @@
+package uploader
+
+import (
+	"context"
+	"testing"
+)
+
+func TestHandleFileEnrichesMeta(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	manager := NewManager(WithDefaultStorageClass("STANDARD_IA"))
+	WithProvider(NewFSProvider(dir))(manager)
+
+	data := createTestPNG(12, 7)
+	header := newTestFileHeader(t, "file", "sample.png", "image/png", data)
+
+	meta, err := manager.HandleFile(ctx, header, "images")
+	if err != nil {
+		t.Fatalf("HandleFile: %v", err)
+	}
+
+	if meta.Width != 12 || meta.Height != 7 {
+		t.Fatalf("expected 12x7 dimensions, got %dx%d", meta.Width, meta.Height)
+	}
+
+	if meta.Checksum != checksumSHA256(data) {
+		t.Fatalf("unexpected checksum: %s", meta.Checksum)
+	}
+
+	if meta.StorageProvider != "fs" || meta.StorageClass != "STANDARD_IA" {
+		t.Fatalf("unexpected storage info: provider=%s class=%s", meta.StorageProvider, meta.StorageClass)
+	}
+
+	if meta.ProviderKey != meta.Name {
+		t.Fatalf("unexpected provider key: %s", meta.ProviderKey)
+	}
+
+	if meta.UploadedAt.IsZero() {
+		t.Fatalf("expected UploadedAt to be set")
+	}
+}
+
+func TestHandleImageWithThumbnailsEnrichesThumbnails(t *testing.T) {
+	ctx := context.Background()
+	manager := NewManager()
+	WithProvider(newMemoryProvider())(manager)
+
+	header := newTestFileHeader(t, "file", "sample.png", "image/png", createTestPNG(20, 20))
+	sizes := []ThumbnailSize{{Name: "small", Width: 8, Height: 6, Fit: "fill"}}
+
+	meta, err := manager.HandleImageWithThumbnails(ctx, header, "images", sizes)
+	if err != nil {
+		t.Fatalf("HandleImageWithThumbnails: %v", err)
+	}
+
+	thumb := meta.Thumbnails["small"]
+	if thumb.Width != 8 || thumb.Height != 6 {
+		t.Fatalf("expected 8x6 thumbnail dimensions, got %dx%d", thumb.Width, thumb.Height)
+	}
+
+	if thumb.Checksum == "" || thumb.ProviderKey != thumb.Name {
+		t.Fatalf("expected checksum and provider key fallback, got %#v", thumb)
+	}
+}
@@ -0,0 +1,128 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func FuzzValidateObjectKey(f *testing.F) {
+	for _, seed := range []string{"images/a.png", "", "..", "a/../../etc/passwd", "/abs", "a/./b", `..\win`, "a//b", "a/\x00b"} {
+		f.Add(seed)
+	}
+
+	base := filepath.Join(string(filepath.Separator), "srv", "uploads")
+	f.Fuzz(func(t *testing.T, key string) {
+		if validateObjectKey(key) != nil {
+			return
+		}
+		// The FS provider resolves keys this way; accepted keys must stay under the base.
+		resolved := filepath.Join(base, filepath.Clean(key))
+		rel, err := filepath.Rel(base, resolved)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			t.Fatalf("key %q escapes the base directory: %q", key, resolved)
+		}
+	})
+}
+
+func FuzzIsValidFileContent(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte{0xFF, 0xD8, 0xFF})
+	f.Add(createTestPNG(2, 2))
+	f.Add([]byte("%PDF-1.7"))
+	f.Add([]byte("\x00\x00\x00\x18ftypheic"))
+
+	f.Fuzz(func(t *testing.T, content []byte) {
+		if isValidFileContent(content) && len(content) < 4 {
+			t.Fatalf("accepted %d bytes, shorter than any signature", len(content))
+		}
+	})
+}
+
+func FuzzAWSProviderGetURL(f *testing.F) {
+	for _, seed := range []string{"", "a", "/", "images/a.png", "/a.png"} {
+		f.Add(seed, "")
+		f.Add(seed, "media")
+	}
+
+	f.Fuzz(func(t *testing.T, key, basePath string) {
+		provider := &AWSProvider{bucket: "bucket", basePath: basePath}
+		if url := provider.getURL(key); url == "" {
+			t.Fatalf("getURL(%q) with base %q returned an empty path", key, basePath)
+		}
+	})
+}
+
+func FuzzThumbnailKeyTemplate(f *testing.F) {
+	for _, template := range []ThumbnailKeyTemplate{DefaultThumbnailKeyTemplate, DerivativesFolderKeyTemplate, "thumbs/{variant}/{base}{ext}", "{base}.{variant}.{format}"} {
+		f.Add(string(template), "images/a.png", "small")
+	}
+	f.Add("{base}{base}{variant}", "a.b.c", "x")
+	f.Add("{key}(?{variant}[", "dir/file\nname.tar.gz", "v1")
+
+	f.Fuzz(func(t *testing.T, template, name, variant string) {
+		tmpl := ThumbnailKeyTemplate(template)
+		if tmpl.Validate() != nil || name == "" || variant == "" || strings.Contains(variant, "/") {
+			return
+		}
+		// Object keys are UTF-8; invalid halves can join into a rune no capture group can split.
+		if !utf8.ValidString(template) || !utf8.ValidString(name) || !utf8.ValidString(variant) {
+			return
+		}
+		key := tmpl.Key(name, variant)
+		if _, ok := thumbnailOriginal(tmpl.pattern(), key); !ok {
+			t.Fatalf("template %q does not match its own key %q", template, key)
+		}
+	})
+}
+
+func FuzzHandleFile(f *testing.F) {
+	f.Add("photo.png", createTestPNG(4, 4))
+	f.Add("../../etc/passwd.png", createTestPNG(4, 4))
+	f.Add("a\x00b.png", []byte{0x89, 'P', 'N', 'G'})
+	f.Add("", []byte("not an image"))
+
+	manager := NewManager(WithProvider(&discardProvider{newMemoryProvider()}), WithLogger(nopLogger{}))
+	f.Fuzz(func(t *testing.T, filename string, content []byte) {
+		file, ok := fuzzFileHeader(filename, content)
+		if !ok {
+			return
+		}
+		meta, err := manager.HandleFile(context.Background(), file, "uploads")
+		if err != nil {
+			return
+		}
+		if err := validateObjectKey(meta.Name); err != nil || !strings.HasPrefix(meta.Name, "uploads/") {
+			t.Fatalf("filename %q stored under unsafe key %q", filename, meta.Name)
+		}
+	})
+}
+
+// fuzzFileHeader is newTestFileHeader for arbitrary filenames, reporting false when the
+// multipart form cannot carry them instead of failing the test.
+func fuzzFileHeader(filename string, data []byte) (*multipart.FileHeader, bool) {
+	buf := &bytes.Buffer{}
+	writer := multipart.NewWriter(buf)
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		return nil, false
+	}
+	part.Write(data)
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/", buf)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	if err := req.ParseMultipartForm(int64(buf.Len())); err != nil || len(req.MultipartForm.File["file"]) == 0 {
+		return nil, false
+	}
+
+	fh := req.MultipartForm.File["file"][0]
+	fh.Header.Set("Content-Type", "image/png")
+	return fh, true
+}
@@ -0,0 +1,109 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"maps"
+	"os/exec"
+	"strings"
+)
+
+// heicBrands are the ISO BMFF major brands used by HEIC/HEIF still images.
+var heicBrands = map[string]bool{
+	"heic": true,
+	"heix": true,
+	"hevc": true,
+	"hevx": true,
+	"heim": true,
+	"heis": true,
+	"mif1": true,
+	"msf1": true,
+}
+
+// IsHEIC reports whether content starts with a HEIC/HEIF file type box.
+func IsHEIC(content []byte) bool {
+	if len(content) < 12 || string(content[4:8]) != "ftyp" {
+		return false
+	}
+	return heicBrands[string(content[8:12])]
+}
+
+// WithHEICSupport accepts .heic/.heif files with image/heic or image/heif content types, as sent by
+// iOS devices. Pair it with a HEICConverter transform unless downstream consumers read HEIC.
+func WithHEICSupport() ValidatorOption {
+	return func(uv *Validator) {
+		uv.allowedImageFormats = maps.Clone(uv.allowedImageFormats)
+		uv.allowedImageFormats[".heic"] = true
+		uv.allowedImageFormats[".heif"] = true
+
+		uv.allowedMimeTypes = maps.Clone(uv.allowedMimeTypes)
+		uv.allowedMimeTypes["image/heic"] = true
+		uv.allowedMimeTypes["image/heif"] = true
+	}
+}
+
+// HEICDecoder decodes HEIC content. The standard library has no HEIC support, so decoding is
+// delegated to libheif bindings, libvips or an external tool (see NewCommandHEICDecoder).
+type HEICDecoder func(ctx context.Context, content []byte) (image.Image, error)
+
+// NewCommandHEICDecoder runs an external converter that reads HEIC on stdin and writes JPEG or
+// PNG to stdout, e.g. NewCommandHEICDecoder("magick", "heic:-", "jpeg:-").
+func NewCommandHEICDecoder(name string, args ...string) HEICDecoder {
+	return func(ctx context.Context, content []byte) (image.Image, error) {
+		var stdout, stderr bytes.Buffer
+
+		cmd := exec.CommandContext(ctx, name, args...)
+		cmd.Stdin = bytes.NewReader(content)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("heic decoder: %s: %w: %s", name, err, strings.TrimSpace(stderr.String()))
+		}
+
+		img, _, err := image.Decode(&stdout)
+		if err != nil {
+			return nil, fmt.Errorf("heic decoder: decode %s output: %w", name, err)
+		}
+		return img, nil
+	}
+}
+
+// HEICConverter is an UploadTransform that re-encodes HEIC uploads as JPEG so browsers and the
+// thumbnail pipeline can read them. Other content passes through. Combine it with
+// WithKeepTransformedOriginals to store the HEIC file next to the JPEG.
+type HEICConverter struct {
+	decode  HEICDecoder
+	quality int
+}
+
+var _ UploadTransform = &HEICConverter{}
+
+// NewHEICConverter creates a converter producing JPEGs at quality 90.
+func NewHEICConverter(decode HEICDecoder) *HEICConverter {
+	return &HEICConverter{decode: decode, quality: 90}
+}
+
+func (c *HEICConverter) Transform(ctx context.Context, content []byte, contentType string) ([]byte, string, error) {
+	if !IsHEIC(content) {
+		return content, contentType, nil
+	}
+
+	if c.decode == nil {
+		return nil, "", fmt.Errorf("heic converter: decoder not configured")
+	}
+
+	img, err := c.decode(ctx, content)
+	if err != nil {
+		return nil, "", err
+	}
+
+	buf := &bytes.Buffer{}
+	if err := jpeg.Encode(buf, img, &jpeg.Options{Quality: c.quality}); err != nil {
+		return nil, "", fmt.Errorf("heic converter: encode jpeg: %w", err)
+	}
+	return buf.Bytes(), "image/jpeg", nil
+}
@@ -0,0 +1,82 @@
+package uploader
+
+import (
+	"context"
+	"image"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testHEICContent() []byte {
+	content := []byte{0x00, 0x00, 0x00, 0x18}
+	content = append(content, "ftypheic"...)
+	content = append(content, 0x00, 0x00, 0x00, 0x00)
+	content = append(content, "mif1heic"...)
+	return content
+}
+
+func TestIsHEIC(t *testing.T) {
+	if !IsHEIC(testHEICContent()) {
+		t.Fatalf("expected heic content to be detected")
+	}
+
+	mp4 := append([]byte{0x00, 0x00, 0x00, 0x18}, "ftypisom"...)
+	if IsHEIC(mp4) || IsHEIC(createTestPNG(2, 2)) {
+		t.Fatalf("expected non-heic content to be rejected")
+	}
+}
+
+func TestWithHEICSupport(t *testing.T) {
+	header := newTestFileHeader(t, "file", "IMG_0001.HEIC", "image/heic", testHEICContent())
+
+	if err := NewValidator().ValidateFile(header); err == nil {
+		t.Fatalf("expected default validator to reject heic")
+	}
+
+	validator := NewValidator(WithHEICSupport())
+	if err := validator.ValidateFile(header); err != nil {
+		t.Fatalf("expected heic to be accepted: %v", err)
+	}
+	if err := validator.ValidateFileContent(testHEICContent()); err != nil {
+		t.Fatalf("expected heic content to be accepted: %v", err)
+	}
+
+	if NewValidator().IsAllowedMimeType("image/heic") {
+		t.Fatalf("WithHEICSupport must not change the defaults")
+	}
+}
+
+func TestHandleFileConvertsHEICAndKeepsOriginal(t *testing.T) {
+	dir := t.TempDir()
+	decode := func(_ context.Context, content []byte) (image.Image, error) {
+		return image.NewRGBA(image.Rect(0, 0, 4, 3)), nil
+	}
+
+	manager := NewManager(
+		WithProvider(NewFSProvider(dir)),
+		WithValidator(NewValidator(WithHEICSupport())),
+		WithUploadTransforms(NewHEICConverter(decode)),
+		WithKeepTransformedOriginals(),
+	)
+
+	header := newTestFileHeader(t, "file", "IMG_0001.HEIC", "image/heic", testHEICContent())
+	meta, err := manager.HandleFile(context.Background(), header, "photos")
+	if err != nil {
+		t.Fatalf("HandleFile failed: %v", err)
+	}
+
+	if !strings.HasSuffix(meta.Name, ".jpg") || meta.ContentType != "image/jpeg" || meta.Width != 4 || meta.Height != 3 {
+		t.Fatalf("expected converted jpeg, got %s (%s) %dx%d", meta.Name, meta.ContentType, meta.Width, meta.Height)
+	}
+
+	originalKey := meta.Attributes["original_key"]
+	if originalKey != strings.TrimSuffix(meta.Name, ".jpg")+".heic" {
+		t.Fatalf("unexpected original key %q for %s", originalKey, meta.Name)
+	}
+
+	if stored, err := os.ReadFile(filepath.Join(dir, originalKey)); err != nil || string(stored) != string(testHEICContent()) {
+		t.Fatalf("expected original heic to be stored: %v", err)
+	}
+}
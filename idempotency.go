@@ -0,0 +1,194 @@
+package uploader
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// IdempotencyRecord is the stored outcome of an operation performed under an idempotency key.
+// FileMeta is stored without its Content to keep records small.
+type IdempotencyRecord struct {
+	FileMeta  *FileMeta `json:"file_meta,omitempty"`
+	SessionID string    `json:"session_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// IdempotencyStore persists idempotency records. Implementations must drop records once their
+// TTL elapses; Get reports false for missing or expired keys.
+type IdempotencyStore interface {
+	Get(ctx context.Context, key string) (*IdempotencyRecord, bool, error)
+	Put(ctx context.Context, key string, record *IdempotencyRecord, ttl time.Duration) error
+}
+
+type idempotencyKeyContextKey struct{}
+
+// WithIdempotencyKey makes retried calls carrying the same key return the original result
+// instead of creating a new object or session.
+func WithIdempotencyKey(key string) UploadOption {
+	return func(m *Metadata) { m.IdempotencyKey = key }
+}
+
+// ContextWithIdempotencyKey attaches an idempotency key to ctx for APIs that do not accept
+// upload options, such as HandleFile.
+func ContextWithIdempotencyKey(ctx context.Context, key string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, idempotencyKeyContextKey{}, key)
+}
+
+// IdempotencyKeyFromContext returns the key attached with ContextWithIdempotencyKey.
+func IdempotencyKeyFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	key, _ := ctx.Value(idempotencyKeyContextKey{}).(string)
+	return key
+}
+
+// MemoryIdempotencyStore is an in-process IdempotencyStore. Use a shared store (Redis, SQL)
+// when several instances serve the same clients.
+type MemoryIdempotencyStore struct {
+	mu        sync.Mutex
+	records   map[string]memoryIdempotencyEntry
+	timeNowFn func() time.Time
+}
+
+type memoryIdempotencyEntry struct {
+	record    IdempotencyRecord
+	expiresAt time.Time
+}
+
+// NewMemoryIdempotencyStore creates an empty in-memory store.
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		records: make(map[string]memoryIdempotencyEntry),
+		timeNowFn: func() time.Time {
+			return time.Now()
+		},
+	}
+}
+
+func (s *MemoryIdempotencyStore) Get(_ context.Context, key string) (*IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.records[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	if s.timeNowFn().After(entry.expiresAt) {
+		delete(s.records, key)
+		return nil, false, nil
+	}
+
+	record := entry.record
+	return &record, true, nil
+}
+
+func (s *MemoryIdempotencyStore) Put(_ context.Context, key string, record *IdempotencyRecord, ttl time.Duration) error {
+	if record == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.timeNowFn()
+	s.records[key] = memoryIdempotencyEntry{
+		record:    *record,
+		expiresAt: now.Add(ttl),
+	}
+
+	for k, entry := range s.records {
+		if now.After(entry.expiresAt) {
+			delete(s.records, k)
+		}
+	}
+
+	return nil
+}
+
+// idempotencyLocks serializes concurrent calls sharing an idempotency key so only the first one
+// performs the operation.
+type idempotencyLocks struct {
+	mu    sync.Mutex
+	locks map[string]*idempotencyLock
+}
+
+type idempotencyLock struct {
+	sync.Mutex
+	refs int
+}
+
+func (l *idempotencyLocks) lock(key string) func() {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*idempotencyLock)
+	}
+	entry, ok := l.locks[key]
+	if !ok {
+		entry = &idempotencyLock{}
+		l.locks[key] = entry
+	}
+	entry.refs++
+	l.mu.Unlock()
+
+	entry.Lock()
+
+	return func() {
+		entry.Unlock()
+
+		l.mu.Lock()
+		entry.refs--
+		if entry.refs == 0 {
+			delete(l.locks, key)
+		}
+		l.mu.Unlock()
+	}
+}
+
+func (m *Manager) ensureIdempotencyStore() IdempotencyStore {
+	if m.idempotencyStore == nil {
+		store := NewMemoryIdempotencyStore()
+		if m.clock != nil {
+			store.timeNowFn = m.clock
+		}
+		m.idempotencyStore = store
+	}
+	return m.idempotencyStore
+}
+
+func (m *Manager) idempotencyTTLOrDefault() time.Duration {
+	if m.idempotencyTTL > 0 {
+		return m.idempotencyTTL
+	}
+	return DefaultIdempotencyTTL
+}
+
+func (m *Manager) lookupIdempotent(ctx context.Context, key string) (*IdempotencyRecord, error) {
+	record, ok, err := m.ensureIdempotencyStore().Get(ctx, key)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return record, nil
+}
+
+func (m *Manager) storeIdempotent(ctx context.Context, key string, record *IdempotencyRecord) {
+	record.CreatedAt = m.now()
+	if err := m.ensureIdempotencyStore().Put(ctx, key, record, m.idempotencyTTLOrDefault()); err != nil {
+		m.log(ctx).Error("store idempotency record failed", err, "key", key)
+	}
+}
+
+func idempotentFileMeta(meta *FileMeta) *FileMeta {
+	if meta == nil {
+		return nil
+	}
+	out := *meta
+	out.Content = nil
+	out.Attributes = mergeAttributes(meta.Attributes)
+	return &out
+}
@@ -0,0 +1,107 @@
+package uploader
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHandleFileIdempotencyKey(t *testing.T) {
+	dir := t.TempDir()
+	manager := NewManager(WithProvider(NewFSProvider(dir)))
+	ctx := ContextWithIdempotencyKey(context.Background(), "req-1")
+
+	var wg sync.WaitGroup
+	results := make([]*FileMeta, 3)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			fh := newTestFileHeader(t, "file", "sample.png", "image/png", createTestPNG(10, 10))
+			meta, err := manager.HandleFile(ctx, fh, "images")
+			if err != nil {
+				t.Errorf("HandleFile failed: %v", err)
+				return
+			}
+			results[i] = meta
+		}(i)
+	}
+	wg.Wait()
+
+	for _, meta := range results[1:] {
+		if meta == nil || meta.Name != results[0].Name {
+			t.Fatalf("expected retries to return the original object, got %#v", meta)
+		}
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, "images"))
+	if err != nil {
+		t.Fatalf("ReadDir failed: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected a single stored object, got %d", len(entries))
+	}
+
+	other, err := manager.HandleFile(context.Background(), newTestFileHeader(t, "file", "sample.png", "image/png", createTestPNG(10, 10)), "images")
+	if err != nil {
+		t.Fatalf("HandleFile failed: %v", err)
+	}
+	if other.Name == results[0].Name {
+		t.Fatalf("expected calls without a key to create new objects")
+	}
+}
+
+func TestInitiateChunkedIdempotencyKey(t *testing.T) {
+	ctx := context.Background()
+	manager := NewManager(WithProvider(newMockChunkUploader()))
+
+	first, err := manager.InitiateChunked(ctx, "videos/a.mp4", 10, WithIdempotencyKey("upload-1"))
+	if err != nil {
+		t.Fatalf("InitiateChunked failed: %v", err)
+	}
+
+	retry, err := manager.InitiateChunked(ctx, "videos/a.mp4", 10, WithIdempotencyKey("upload-1"))
+	if err != nil {
+		t.Fatalf("InitiateChunked retry failed: %v", err)
+	}
+
+	if retry.ID != first.ID {
+		t.Fatalf("expected retry to return session %s, got %s", first.ID, retry.ID)
+	}
+
+	if err := manager.AbortChunked(ctx, first.ID); err != nil {
+		t.Fatalf("AbortChunked failed: %v", err)
+	}
+
+	fresh, err := manager.InitiateChunked(ctx, "videos/a.mp4", 10, WithIdempotencyKey("upload-1"))
+	if err != nil {
+		t.Fatalf("InitiateChunked after abort failed: %v", err)
+	}
+
+	if fresh.ID == first.ID {
+		t.Fatalf("expected a new session once the original is gone")
+	}
+}
+
+func TestMemoryIdempotencyStoreExpiry(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryIdempotencyStore()
+	now := time.Now()
+	store.timeNowFn = func() time.Time { return now }
+
+	if err := store.Put(ctx, "k", &IdempotencyRecord{SessionID: "s"}, time.Minute); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	if record, ok, _ := store.Get(ctx, "k"); !ok || record.SessionID != "s" {
+		t.Fatalf("expected record before expiry")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, ok, _ := store.Get(ctx, "k"); ok {
+		t.Fatalf("expected record to expire")
+	}
+}
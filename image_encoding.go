@@ -0,0 +1,159 @@
+package uploader
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image/png"
+
+	gerrors "github.com/goliatone/go-errors"
+)
+
+// ImageEncoding tunes how processors encode derivatives. Set it on a processor for defaults and
+// on ThumbnailSize to override them per derivative; zero values keep the processor default.
+//
+// LocalImageProcessor honors Quality, PNGCompression and StripICC. The standard library only
+// writes baseline 4:2:0 JPEGs, so Progressive and ChromaSubsampling need the libvips processor.
+// Remote processors forward Quality only.
+type ImageEncoding struct {
+	// Quality is the JPEG quality, 1-100.
+	Quality int
+	// Progressive writes progressive JPEGs and interlaced PNGs.
+	Progressive bool
+	// PNGCompression is the zlib level, 1 (fastest) to 9 (smallest).
+	PNGCompression int
+	// ChromaSubsampling is "4:2:0" or "4:4:4". Full chroma keeps text and logos crisp at the
+	// cost of larger files.
+	ChromaSubsampling string
+	// StripICC ignores the source color profile. By default LocalImageProcessor copies it into
+	// JPEG derivatives and libvips converts to sRGB, so wide-gamut photos (Display P3 from
+	// phones) do not look washed out.
+	StripICC bool
+}
+
+var allowedChromaSubsampling = map[string]bool{
+	"":      true,
+	"4:2:0": true,
+	"4:4:4": true,
+}
+
+// merge returns e with the non-zero fields of override applied.
+func (e ImageEncoding) merge(override ImageEncoding) ImageEncoding {
+	if override.Quality > 0 {
+		e.Quality = override.Quality
+	}
+	if override.PNGCompression > 0 {
+		e.PNGCompression = override.PNGCompression
+	}
+	if override.ChromaSubsampling != "" {
+		e.ChromaSubsampling = override.ChromaSubsampling
+	}
+	e.Progressive = e.Progressive || override.Progressive
+	e.StripICC = e.StripICC || override.StripICC
+	return e
+}
+
+func (e ImageEncoding) jpegQuality(fallback int) int {
+	if e.Quality > 0 {
+		return e.Quality
+	}
+	return fallback
+}
+
+func (e ImageEncoding) pngEncoder() *png.Encoder {
+	level := png.DefaultCompression
+	switch {
+	case e.PNGCompression <= 0:
+	case e.PNGCompression <= 3:
+		level = png.BestSpeed
+	case e.PNGCompression >= 7:
+		level = png.BestCompression
+	}
+	return &png.Encoder{CompressionLevel: level}
+}
+
+func validateImageEncoding(field string, e ImageEncoding) error {
+	if e.Quality < 0 || e.Quality > 100 {
+		return gerrors.NewValidation("thumbnail sizes invalid",
+			gerrors.FieldError{
+				Field:   field + ".quality",
+				Message: "quality must be between 1 and 100",
+				Value:   e.Quality,
+			},
+		)
+	}
+
+	if e.PNGCompression < 0 || e.PNGCompression > 9 {
+		return gerrors.NewValidation("thumbnail sizes invalid",
+			gerrors.FieldError{
+				Field:   field + ".png_compression",
+				Message: "png compression must be between 1 and 9",
+				Value:   e.PNGCompression,
+			},
+		)
+	}
+
+	if !allowedChromaSubsampling[e.ChromaSubsampling] {
+		return gerrors.NewValidation("thumbnail sizes invalid",
+			gerrors.FieldError{
+				Field:   field + ".chroma_subsampling",
+				Message: "unsupported chroma subsampling (4:2:0, 4:4:4)",
+				Value:   e.ChromaSubsampling,
+			},
+		)
+	}
+
+	return nil
+}
+
+var iccProfileTag = []byte("ICC_PROFILE\x00")
+
+// jpegICCSegments returns the APP2 segments carrying an ICC profile, marker included. Large
+// profiles are split across several segments and are returned in file order.
+func jpegICCSegments(content []byte) []byte {
+	if len(content) < 4 || content[0] != 0xFF || content[1] != 0xD8 {
+		return nil
+	}
+
+	var out []byte
+	for pos := 2; pos+4 <= len(content); {
+		if content[pos] != 0xFF {
+			return out
+		}
+
+		marker := content[pos+1]
+		if marker == 0xDA || marker == 0xD9 {
+			return out
+		}
+
+		length := int(binary.BigEndian.Uint16(content[pos+2 : pos+4]))
+		end := pos + 2 + length
+		if length < 2 || end > len(content) {
+			return out
+		}
+
+		if marker == 0xE2 && bytes.HasPrefix(content[pos+4:end], iccProfileTag) {
+			out = append(out, content[pos:end]...)
+		}
+		pos = end
+	}
+	return out
+}
+
+// embedJPEGICC inserts ICC segments right after the SOI marker of the JPEG encoded in buf.
+func embedJPEGICC(buf *bytes.Buffer, segments []byte) error {
+	if len(segments) == 0 {
+		return nil
+	}
+
+	encoded := bytes.Clone(buf.Bytes())
+	if len(encoded) < 2 || encoded[0] != 0xFF || encoded[1] != 0xD8 {
+		return fmt.Errorf("image processor: embed icc: output is not a jpeg")
+	}
+
+	buf.Reset()
+	buf.Write(encoded[:2])
+	buf.Write(segments)
+	buf.Write(encoded[2:])
+	return nil
+}
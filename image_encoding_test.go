@@ -0,0 +1,107 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"image"
+	"image/jpeg"
+	"testing"
+)
+
+func encodeTestJPEG(t *testing.T, w, h int, icc []byte) []byte {
+	t.Helper()
+
+	img, _, err := image.Decode(bytes.NewReader(createTestPNG(w, h)))
+	if err != nil {
+		t.Fatalf("decode fixture: %v", err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := jpeg.Encode(buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatalf("encode fixture: %v", err)
+	}
+
+	if len(icc) == 0 {
+		return buf.Bytes()
+	}
+
+	payload := append(append([]byte{}, iccProfileTag...), 1, 1)
+	payload = append(payload, icc...)
+	segment := []byte{0xFF, 0xE2, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	segment = append(segment, payload...)
+
+	if err := embedJPEGICC(buf, segment); err != nil {
+		t.Fatalf("embed icc: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestLocalImageProcessorQuality(t *testing.T) {
+	src := encodeTestJPEG(t, 64, 64, nil)
+	size := ThumbnailSize{Name: "thumb", Width: 32, Height: 32, Fit: "cover"}
+
+	low, _, err := NewLocalImageProcessor().WithEncoding(ImageEncoding{Quality: 10}).Generate(context.Background(), src, size, "image/jpeg")
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	size.Encoding = ImageEncoding{Quality: 100}
+	high, _, err := NewLocalImageProcessor().WithEncoding(ImageEncoding{Quality: 10}).Generate(context.Background(), src, size, "image/jpeg")
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	if len(high) <= len(low) {
+		t.Fatalf("expected per-size quality to override the processor default: %d <= %d", len(high), len(low))
+	}
+}
+
+func TestLocalImageProcessorKeepsICCProfile(t *testing.T) {
+	profile := []byte("display-p3-profile")
+	src := encodeTestJPEG(t, 32, 32, profile)
+	size := ThumbnailSize{Name: "thumb", Width: 16, Height: 16, Fit: "cover"}
+
+	thumb, _, err := NewLocalImageProcessor().Generate(context.Background(), src, size, "image/jpeg")
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	if !bytes.Contains(jpegICCSegments(thumb), profile) {
+		t.Fatalf("expected icc profile to be copied into the derivative")
+	}
+	if _, err := jpeg.Decode(bytes.NewReader(thumb)); err != nil {
+		t.Fatalf("derivative is not a valid jpeg: %v", err)
+	}
+
+	size.Encoding.StripICC = true
+	thumb, _, err = NewLocalImageProcessor().Generate(context.Background(), src, size, "image/jpeg")
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	if len(jpegICCSegments(thumb)) != 0 {
+		t.Fatalf("expected icc profile to be stripped")
+	}
+}
+
+func TestLocalImageProcessorPNGCompression(t *testing.T) {
+	src := createTestPNG(64, 64)
+	size := ThumbnailSize{Name: "thumb", Width: 64, Height: 64, Fit: "fill", Encoding: ImageEncoding{PNGCompression: 1}}
+
+	fast, _, err := NewLocalImageProcessor().Generate(context.Background(), src, size, "image/png")
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	size.Encoding.PNGCompression = 9
+	small, _, err := NewLocalImageProcessor().Generate(context.Background(), src, size, "image/png")
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	if len(small) > len(fast) {
+		t.Fatalf("expected best compression to produce a smaller file: %d > %d", len(small), len(fast))
+	}
+}
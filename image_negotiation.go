@@ -0,0 +1,170 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"mime"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ImageFormat is a rendition NegotiateImage may serve in place of an original.
+type ImageFormat struct {
+	// ContentType is the rendition's media type, e.g. "image/avif".
+	ContentType string
+	// Variant names the derivative in the thumbnail key template. Empty uses the media subtype,
+	// so AVIF renditions of "a.png" are stored as "a__avif.avif" by default.
+	Variant string
+	// Transform encodes the original into ContentType when the rendition is missing. Without
+	// one, only renditions already stored (e.g. by a post-processor) are served.
+	Transform UploadTransform
+}
+
+// DefaultImageFormats are negotiated when WithImageFormats is not set: AVIF, then WebP, then
+// JPEG. They have no transforms, so they only pick up renditions stored ahead of time.
+var DefaultImageFormats = []ImageFormat{
+	{ContentType: "image/avif"},
+	{ContentType: "image/webp"},
+	{ContentType: "image/jpeg"},
+}
+
+// WithImageFormats sets the renditions NegotiateImage considers, most preferred first.
+func WithImageFormats(formats ...ImageFormat) Option {
+	return func(m *Manager) {
+		m.imageFormats = append(m.imageFormats, formats...)
+	}
+}
+
+// NegotiatedImage is the rendition NegotiateImage picked.
+type NegotiatedImage struct {
+	Key         string `json:"key"`
+	URL         string `json:"url"`
+	ContentType string `json:"content_type"`
+	// Generated reports that the rendition was encoded and stored by this call.
+	Generated bool `json:"generated,omitempty"`
+}
+
+// NegotiateImage picks the best rendition of the image stored under key for a client sending
+// accept, its HTTP Accept header. Formats are tried in the configured order and only when accept
+// names their media type explicitly, since browsers advertise AVIF and WebP support that way
+// while their "image/*" covers neither reliably. A missing rendition is encoded from the
+// original with the format's transform and stored next to it as a derivative; formats that
+// cannot be produced are skipped. When no format applies, or the original already is the best
+// format, the original is returned.
+//
+// Derivative URLs follow DerivativeURL and originals are presigned for expires. Responses that
+// redirect to the result must send "Vary: Accept".
+func (m *Manager) NegotiateImage(ctx context.Context, key, accept string, expires time.Duration) (*NegotiatedImage, error) {
+	if err := m.validateKey(key); err != nil {
+		return nil, err
+	}
+	if err := m.ensureProvider(ctx); err != nil {
+		return nil, err
+	}
+
+	formats := m.imageFormats
+	if len(formats) == 0 {
+		formats = DefaultImageFormats
+	}
+
+	accepted := parseAccept(accept)
+	original := mediaType(mime.TypeByExtension(path.Ext(key)))
+
+	var content []byte
+	for _, format := range formats {
+		contentType := mediaType(format.ContentType)
+		if contentType == "" || accepted[contentType] <= 0 {
+			continue
+		}
+		if contentType == original {
+			break
+		}
+
+		image, err := m.negotiatedRendition(ctx, key, format, contentType, &content)
+		if err != nil {
+			return nil, err
+		}
+		if image == nil {
+			continue
+		}
+		if image.URL, err = m.derivativeURL(ctx, image.Key, expires); err != nil {
+			return nil, err
+		}
+		return image, nil
+	}
+
+	url, err := m.GetPresignedURL(ctx, key, expires)
+	if err != nil {
+		return nil, err
+	}
+	return &NegotiatedImage{Key: key, URL: url, ContentType: original}, nil
+}
+
+// negotiatedRendition returns the stored rendition of key in format, generating it when
+// possible. It returns nil when the rendition cannot be served. content caches the original
+// across formats.
+func (m *Manager) negotiatedRendition(ctx context.Context, key string, format ImageFormat, contentType string, content *[]byte) (*NegotiatedImage, error) {
+	variant := format.Variant
+	if variant == "" {
+		variant = contentType[strings.Index(contentType, "/")+1:]
+	}
+	ext := extensionForContentType(contentType)
+	renditionKey := m.thumbnailKeyTemplate().derivativeKey(key, variant, ext)
+
+	_, err := m.StatFile(ctx, renditionKey)
+	if err == nil {
+		return &NegotiatedImage{Key: renditionKey, ContentType: contentType}, nil
+	}
+	if !errors.Is(err, ErrImageNotFound) {
+		return nil, err
+	}
+	if format.Transform == nil || m.ensureWritable() != nil {
+		return nil, nil
+	}
+
+	if *content == nil {
+		original, err := m.GetFile(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		*content = original
+	}
+
+	encoded, encodedType, err := format.Transform.Transform(ctx, *content, mime.TypeByExtension(path.Ext(key)))
+	if err != nil {
+		m.log(ctx).Error("image rendition failed", "key", key, "content_type", contentType, "error", err)
+		return nil, nil
+	}
+	if mediaType(encodedType) != contentType {
+		// The transform passed the original through, e.g. a format it cannot encode from.
+		return nil, nil
+	}
+
+	if _, err := m.putFile(ctx, renditionKey, encoded, WithContentType(contentType), WithStorageClass(m.storageClass)); err != nil {
+		return nil, err
+	}
+	m.log(ctx).Info("image rendition generated", "key", key, "rendition", renditionKey, "size", len(encoded))
+	return &NegotiatedImage{Key: renditionKey, ContentType: contentType, Generated: true}, nil
+}
+
+// parseAccept maps the media types of an Accept header to their quality values. Entries
+// without a q parameter have quality 1.
+func parseAccept(accept string) map[string]float64 {
+	accepted := map[string]float64{}
+	for _, entry := range strings.Split(accept, ",") {
+		value, params, err := mime.ParseMediaType(strings.TrimSpace(entry))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if raw, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(raw, 64); err != nil {
+				continue
+			}
+		}
+		accepted[value] = q
+	}
+	return accepted
+}
@@ -0,0 +1,79 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestManagerNegotiateImage(t *testing.T) {
+	ctx := context.Background()
+	provider := newMemoryProvider()
+	provider.files["photos/a.png"] = createTestPNG(8, 8)
+
+	encoded := 0
+	manager := NewManager(
+		WithProvider(provider),
+		WithImageFormats(
+			ImageFormat{ContentType: "image/avif", Transform: UploadTransformFunc(func(context.Context, []byte, string) ([]byte, string, error) {
+				return nil, "", errors.New("avif encoder unavailable")
+			})},
+			ImageFormat{ContentType: "image/webp", Transform: UploadTransformFunc(func(_ context.Context, content []byte, contentType string) ([]byte, string, error) {
+				if contentType != "image/png" {
+					t.Fatalf("expected the original content type, got %q", contentType)
+				}
+				encoded++
+				return []byte("webp"), "image/webp", nil
+			})},
+		),
+	)
+
+	chrome := "image/avif,image/webp,image/apng,image/*,*/*;q=0.8"
+	image, err := manager.NegotiateImage(ctx, "photos/a.png", chrome, time.Minute)
+	if err != nil {
+		t.Fatalf("NegotiateImage: %v", err)
+	}
+	if image.Key != "photos/a__webp.webp" || image.ContentType != "image/webp" || !image.Generated || image.URL != "mem://photos/a__webp.webp" {
+		t.Fatalf("unexpected rendition %+v", image)
+	}
+	if string(provider.files["photos/a__webp.webp"]) != "webp" {
+		t.Fatalf("expected the rendition to be stored")
+	}
+
+	if image, err = manager.NegotiateImage(ctx, "photos/a.png", chrome, time.Minute); err != nil || image.Generated || encoded != 1 {
+		t.Fatalf("expected the stored rendition to be reused, got %+v (%v), %d encodes", image, err, encoded)
+	}
+
+	provider.files["photos/a__avif.avif"] = []byte("avif")
+	if image, err = manager.NegotiateImage(ctx, "photos/a.png", chrome, time.Minute); err != nil || image.Key != "photos/a__avif.avif" {
+		t.Fatalf("expected the stored AVIF rendition, got %+v (%v)", image, err)
+	}
+
+	for _, accept := range []string{"", "*/*", "image/*", "image/avif;q=0,image/webp;q=0,*/*"} {
+		image, err := manager.NegotiateImage(ctx, "photos/a.png", accept, time.Minute)
+		if err != nil || image.Key != "photos/a.png" || image.ContentType != "image/png" || image.URL != "mem://photos/a.png" {
+			t.Fatalf("expected the original for Accept %q, got %+v (%v)", accept, image, err)
+		}
+	}
+}
+
+func TestManagerNegotiateImageDefaultFormats(t *testing.T) {
+	ctx := context.Background()
+	provider := newMemoryProvider()
+	provider.files["a.jpg"] = []byte("jpeg")
+	manager := NewManager(WithProvider(provider))
+
+	image, err := manager.NegotiateImage(ctx, "a.jpg", "image/webp,image/jpeg", time.Minute)
+	if err != nil || image.Key != "a.jpg" || image.Generated {
+		t.Fatalf("expected the original without a stored rendition, got %+v (%v)", image, err)
+	}
+
+	provider.files["a__webp.webp"] = []byte("webp")
+	if image, err = manager.NegotiateImage(ctx, "a.jpg", "image/webp,image/jpeg", time.Minute); err != nil || image.Key != "a__webp.webp" {
+		t.Fatalf("expected the stored WebP rendition, got %+v (%v)", image, err)
+	}
+	if image, err = manager.NegotiateImage(ctx, "a.jpg", "image/jpeg,image/webp", time.Minute); err != nil || image.Key != "a__webp.webp" {
+		t.Fatalf("expected server preference over header order, got %+v (%v)", image, err)
+	}
+}
@@ -0,0 +1,115 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"math"
+
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
+)
+
+// ImageNormalizer is an UploadTransform that makes stored images render consistently: CMYK and
+// 16-bit images and formats browsers handle poorly (TIFF, BMP, WebP) are re-encoded as 8-bit
+// RGB JPEG, or PNG when they carry transparency, and oversized images are scaled down to fit
+// MaxWidth x MaxHeight. GIFs and formats that cannot be decoded (e.g. SVG) pass through.
+//
+// Color conversion uses the standard library color models; embedded ICC profiles are not
+// applied.
+type ImageNormalizer struct {
+	MaxWidth  int
+	MaxHeight int
+	Quality   int
+}
+
+var _ UploadTransform = &ImageNormalizer{}
+
+// NewImageNormalizer creates a normalizer capping images at maxWidth x maxHeight; zero leaves
+// that dimension uncapped.
+func NewImageNormalizer(maxWidth, maxHeight int) *ImageNormalizer {
+	return &ImageNormalizer{
+		MaxWidth:  maxWidth,
+		MaxHeight: maxHeight,
+		Quality:   85,
+	}
+}
+
+func (n *ImageNormalizer) Transform(ctx context.Context, content []byte, contentType string) ([]byte, string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, "", err
+	}
+
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil || format == "gif" {
+		return content, contentType, nil
+	}
+
+	width, height := n.fit(cfg.Width, cfg.Height)
+	resize := width != cfg.Width || height != cfg.Height
+	if !resize && !needsColorNormalization(cfg.ColorModel) && (format == "jpeg" || format == "png") {
+		return content, contentType, nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(content))
+	if err != nil {
+		return nil, "", fmt.Errorf("image normalizer: decode %s: %w", format, err)
+	}
+
+	var out *image.NRGBA
+	if resize {
+		out = resizeNearest(img, width, height)
+	} else {
+		out = image.NewNRGBA(img.Bounds())
+		draw.Draw(out, out.Bounds(), img, img.Bounds().Min, draw.Src)
+	}
+
+	buf := &bytes.Buffer{}
+	if format == "png" || !out.Opaque() {
+		if err := png.Encode(buf, out); err != nil {
+			return nil, "", fmt.Errorf("image normalizer: encode png: %w", err)
+		}
+		return buf.Bytes(), "image/png", nil
+	}
+
+	if err := jpeg.Encode(buf, out, &jpeg.Options{Quality: n.quality()}); err != nil {
+		return nil, "", fmt.Errorf("image normalizer: encode jpeg: %w", err)
+	}
+	return buf.Bytes(), "image/jpeg", nil
+}
+
+// fit scales width x height down to the configured caps, preserving the aspect ratio.
+func (n *ImageNormalizer) fit(width, height int) (int, int) {
+	scale := 1.0
+	if n.MaxWidth > 0 && width > n.MaxWidth {
+		scale = math.Min(scale, float64(n.MaxWidth)/float64(width))
+	}
+	if n.MaxHeight > 0 && height > n.MaxHeight {
+		scale = math.Min(scale, float64(n.MaxHeight)/float64(height))
+	}
+	if scale == 1 {
+		return width, height
+	}
+	return max(1, int(math.Round(float64(width)*scale))), max(1, int(math.Round(float64(height)*scale)))
+}
+
+func (n *ImageNormalizer) quality() int {
+	if n.Quality <= 0 || n.Quality > 100 {
+		return 85
+	}
+	return n.Quality
+}
+
+func needsColorNormalization(model color.Model) bool {
+	switch model {
+	case color.CMYKModel, color.RGBA64Model, color.NRGBA64Model, color.Gray16Model:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+
+	"golang.org/x/image/tiff"
+)
+
+func encodeTestTIFF(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	if err := tiff.Encode(buf, img, nil); err != nil {
+		t.Fatalf("encode tiff: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func newTest16BitImage(w, h int, alpha uint16) *image.NRGBA64 {
+	img := image.NewNRGBA64(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.SetNRGBA64(x, y, color.NRGBA64{R: 0xffff, G: 0x8000, B: 0x1000, A: alpha})
+		}
+	}
+	return img
+}
+
+func TestImageNormalizerConvertsSixteenBitTIFF(t *testing.T) {
+	normalizer := NewImageNormalizer(0, 0)
+
+	out, contentType, err := normalizer.Transform(context.Background(), encodeTestTIFF(t, newTest16BitImage(8, 6, 0xffff)), "image/tiff")
+	if err != nil {
+		t.Fatalf("Transform failed: %v", err)
+	}
+
+	if contentType != "image/jpeg" {
+		t.Fatalf("expected jpeg output, got %s", contentType)
+	}
+
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(out))
+	if err != nil || cfg.Width != 8 || cfg.Height != 6 {
+		t.Fatalf("unexpected output config %+v (%v)", cfg, err)
+	}
+}
+
+func TestImageNormalizerKeepsTransparencyAsPNG(t *testing.T) {
+	out, contentType, err := NewImageNormalizer(0, 0).Transform(context.Background(), encodeTestTIFF(t, newTest16BitImage(4, 4, 0x8000)), "image/tiff")
+	if err != nil {
+		t.Fatalf("Transform failed: %v", err)
+	}
+
+	if contentType != "image/png" {
+		t.Fatalf("expected png output, got %s", contentType)
+	}
+
+	if _, err := png.Decode(bytes.NewReader(out)); err != nil {
+		t.Fatalf("expected valid png: %v", err)
+	}
+}
+
+func TestImageNormalizerCapsDimensions(t *testing.T) {
+	out, contentType, err := NewImageNormalizer(40, 40).Transform(context.Background(), createTestPNG(100, 50), "image/png")
+	if err != nil {
+		t.Fatalf("Transform failed: %v", err)
+	}
+
+	cfg, err := png.DecodeConfig(bytes.NewReader(out))
+	if err != nil || contentType != "image/png" || cfg.Width != 40 || cfg.Height != 20 {
+		t.Fatalf("expected 40x20 png, got %s %+v (%v)", contentType, cfg, err)
+	}
+}
+
+func TestImageNormalizerPassesThroughNormalizedImages(t *testing.T) {
+	src := createTestPNG(10, 10)
+
+	out, contentType, err := NewImageNormalizer(40, 40).Transform(context.Background(), src, "image/png")
+	if err != nil {
+		t.Fatalf("Transform failed: %v", err)
+	}
+
+	if !bytes.Equal(out, src) || contentType != "image/png" {
+		t.Fatalf("expected untouched content")
+	}
+}
+
+func TestHandleFileNormalizesBeforeStorage(t *testing.T) {
+	var captured Metadata
+	manager := NewManager(
+		WithProvider(captureUploadMetadata(&captured)),
+		WithUploadTransforms(NewImageNormalizer(0, 0)),
+	)
+
+	src := encodeTestTIFF(t, newTest16BitImage(8, 6, 0xffff))
+	meta, err := manager.HandleFile(context.Background(), newTestFileHeader(t, "file", "scan.tiff", "image/tiff", src), "scans")
+	if err != nil {
+		t.Fatalf("HandleFile failed: %v", err)
+	}
+
+	if !strings.HasSuffix(meta.Name, ".jpg") || meta.ContentType != "image/jpeg" || captured.ContentType != "image/jpeg" {
+		t.Fatalf("expected stored jpeg, got name %s type %s (provider saw %s)", meta.Name, meta.ContentType, captured.ContentType)
+	}
+
+	if meta.OriginalName != "scan.tiff" || meta.Size != int64(len(meta.Content)) {
+		t.Fatalf("unexpected meta: original %s size %d", meta.OriginalName, meta.Size)
+	}
+}
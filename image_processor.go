@@ -9,19 +9,26 @@ import (
 	"image/draw"
 	"image/gif"
 	"image/jpeg"
-	"image/png"
 	"io"
 	"math"
 	"strings"
 )
 
 // LocalImageProcessor resizes images using a simple nearest-neighbor algorithm.
-type LocalImageProcessor struct{}
+type LocalImageProcessor struct {
+	encoding ImageEncoding
+}
 
 func NewLocalImageProcessor() *LocalImageProcessor {
 	return &LocalImageProcessor{}
 }
 
+// WithEncoding sets the default encoder options; ThumbnailSize.Encoding overrides them.
+func (p *LocalImageProcessor) WithEncoding(encoding ImageEncoding) *LocalImageProcessor {
+	p.encoding = encoding
+	return p
+}
+
 func (p *LocalImageProcessor) Generate(ctx context.Context, source []byte, size ThumbnailSize, contentType string) ([]byte, string, error) {
 	if err := ctx.Err(); err != nil {
 		return nil, "", err
@@ -37,8 +44,11 @@ func (p *LocalImageProcessor) Generate(ctx context.Context, source []byte, size
 	}
 
 	target := resizeImage(img, size)
+	encoding := p.encoding.merge(size.Encoding)
+
+	buf := DefaultBufferPool.Get()
+	defer DefaultBufferPool.Put(buf)
 
-	buf := &bytes.Buffer{}
 	mime := contentType
 	if mime == "" {
 		mime = "image/" + format
@@ -46,14 +56,19 @@ func (p *LocalImageProcessor) Generate(ctx context.Context, source []byte, size
 
 	switch format {
 	case "jpeg", "jpg":
-		if err := jpeg.Encode(buf, target, &jpeg.Options{Quality: 85}); err != nil {
+		if err := jpeg.Encode(buf, target, &jpeg.Options{Quality: encoding.jpegQuality(85)}); err != nil {
 			return nil, "", err
 		}
+		if !encoding.StripICC {
+			if err := embedJPEGICC(buf, jpegICCSegments(source)); err != nil {
+				return nil, "", err
+			}
+		}
 		if mime == "" {
 			mime = "image/jpeg"
 		}
 	case "png":
-		if err := png.Encode(buf, target); err != nil {
+		if err := encoding.pngEncoder().Encode(buf, target); err != nil {
 			return nil, "", err
 		}
 		if mime == "" {
@@ -67,13 +82,13 @@ func (p *LocalImageProcessor) Generate(ctx context.Context, source []byte, size
 			mime = "image/gif"
 		}
 	default:
-		if err := png.Encode(buf, target); err != nil {
+		if err := encoding.pngEncoder().Encode(buf, target); err != nil {
 			return nil, "", err
 		}
 		mime = "image/png"
 	}
 
-	return buf.Bytes(), mime, nil
+	return bytes.Clone(buf.Bytes()), mime, nil
 }
 
 func resizeImage(src image.Image, size ThumbnailSize) *image.NRGBA {
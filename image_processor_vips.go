@@ -0,0 +1,161 @@
+//go:build vips
+
+package uploader
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/davidbyttow/govips/v2/vips"
+)
+
+var vipsStartup sync.Once
+
+// VipsImageProcessor resizes images with libvips, which is considerably faster and lighter on
+// memory than the pure Go processor and decodes more formats (HEIC, TIFF, AVIF). It is only
+// compiled with the "vips" build tag and needs libvips installed:
+//
+//	go build -tags vips ./...
+type VipsImageProcessor struct {
+	quality  int
+	encoding ImageEncoding
+}
+
+var _ ImageProcessor = &VipsImageProcessor{}
+
+// NewVipsImageProcessor starts libvips on first use. quality applies to JPEG output; values
+// outside 1-100 fall back to 85.
+func NewVipsImageProcessor(quality int) *VipsImageProcessor {
+	vipsStartup.Do(func() {
+		vips.LoggingSettings(nil, vips.LogLevelError)
+		vips.Startup(nil)
+	})
+
+	if quality <= 0 || quality > 100 {
+		quality = 85
+	}
+	return &VipsImageProcessor{quality: quality}
+}
+
+// WithEncoding sets the default encoder options; ThumbnailSize.Encoding overrides them. A
+// non-zero Quality takes precedence over the constructor value.
+func (p *VipsImageProcessor) WithEncoding(encoding ImageEncoding) *VipsImageProcessor {
+	p.encoding = encoding
+	return p
+}
+
+func (p *VipsImageProcessor) Generate(ctx context.Context, source []byte, size ThumbnailSize, contentType string) ([]byte, string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, "", err
+	}
+
+	if len(source) == 0 {
+		return nil, "", fmt.Errorf("vips processor: source is empty")
+	}
+
+	img, err := vips.NewImageFromBuffer(source)
+	if err != nil {
+		return nil, "", fmt.Errorf("vips processor: decode image: %w", err)
+	}
+	defer img.Close()
+
+	if err := img.AutoRotate(); err != nil {
+		return nil, "", fmt.Errorf("vips processor: rotate: %w", err)
+	}
+
+	if err := p.resize(img, size); err != nil {
+		return nil, "", fmt.Errorf("vips processor: resize: %w", err)
+	}
+
+	encoding := p.encoding.merge(size.Encoding)
+	if err := p.normalizeColor(img, encoding); err != nil {
+		return nil, "", fmt.Errorf("vips processor: color profile: %w", err)
+	}
+
+	return p.export(img, encoding)
+}
+
+// normalizeColor converts images with an embedded profile to sRGB, since exports strip metadata
+// (EXIF and GPS included) and browsers assume sRGB for untagged images.
+func (p *VipsImageProcessor) normalizeColor(img *vips.ImageRef, encoding ImageEncoding) error {
+	if encoding.StripICC || !img.HasICCProfile() {
+		return nil
+	}
+	return img.TransformICCProfile(vips.SRGBIEC6196621ICCProfilePath)
+}
+
+// resize mirrors the local processor fits: cover crops, fill stretches and contain pads the
+// scaled image onto a transparent canvas of the exact size.
+func (p *VipsImageProcessor) resize(img *vips.ImageRef, size ThumbnailSize) error {
+	switch strings.ToLower(size.Fit) {
+	case "cover", "outside":
+		return img.Thumbnail(size.Width, size.Height, vips.InterestingCentre)
+	case "fill":
+		return img.ThumbnailWithSize(size.Width, size.Height, vips.InterestingNone, vips.SizeForce)
+	}
+
+	if err := img.ThumbnailWithSize(size.Width, size.Height, vips.InterestingNone, vips.SizeDown); err != nil {
+		return err
+	}
+	if !img.HasAlpha() {
+		if err := img.AddAlpha(); err != nil {
+			return err
+		}
+	}
+	left := (size.Width - img.Width()) / 2
+	top := (size.Height - img.Height()) / 2
+	return img.EmbedBackgroundRGBA(left, top, size.Width, size.Height, &vips.ColorRGBA{})
+}
+
+// export keeps web formats as they are and converts everything else (HEIC, TIFF, ...) to JPEG,
+// or PNG when the image has transparency.
+func (p *VipsImageProcessor) export(img *vips.ImageRef, encoding ImageEncoding) ([]byte, string, error) {
+	switch img.Format() {
+	case vips.ImageTypeJPEG:
+		return p.exportJPEG(img, encoding)
+	case vips.ImageTypeGIF, vips.ImageTypeWEBP:
+		out, meta, err := img.ExportNative()
+		if err != nil {
+			return nil, "", fmt.Errorf("vips processor: encode: %w", err)
+		}
+		return out, "image/" + vips.ImageTypes[meta.Format], nil
+	}
+
+	if img.Format() == vips.ImageTypePNG || img.HasAlpha() {
+		params := vips.NewPngExportParams()
+		params.Interlace = encoding.Progressive
+		if encoding.PNGCompression > 0 {
+			params.Compression = encoding.PNGCompression
+		}
+
+		out, _, err := img.ExportPng(params)
+		if err != nil {
+			return nil, "", fmt.Errorf("vips processor: encode png: %w", err)
+		}
+		return out, "image/png", nil
+	}
+
+	return p.exportJPEG(img, encoding)
+}
+
+func (p *VipsImageProcessor) exportJPEG(img *vips.ImageRef, encoding ImageEncoding) ([]byte, string, error) {
+	params := vips.NewJpegExportParams()
+	params.Quality = encoding.jpegQuality(p.quality)
+	params.Interlace = encoding.Progressive
+	params.StripMetadata = true
+
+	switch encoding.ChromaSubsampling {
+	case "4:2:0":
+		params.SubsampleMode = vips.VipsForeignSubsampleOn
+	case "4:4:4":
+		params.SubsampleMode = vips.VipsForeignSubsampleOff
+	}
+
+	out, _, err := img.ExportJpeg(params)
+	if err != nil {
+		return nil, "", fmt.Errorf("vips processor: encode jpeg: %w", err)
+	}
+	return out, "image/jpeg", nil
+}
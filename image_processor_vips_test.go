@@ -0,0 +1,182 @@
+//go:build vips
+
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"testing"
+
+	"golang.org/x/image/tiff"
+)
+
+func TestVipsImageProcessorFit(t *testing.T) {
+	src := createTestPNG(40, 20)
+
+	for _, fit := range []string{"cover", "outside", "fill", "contain", ""} {
+		size := ThumbnailSize{Name: "thumb", Width: 10, Height: 10, Fit: fit}
+		thumb, mime, err := NewVipsImageProcessor(0).Generate(context.Background(), src, size, "image/png")
+		if err != nil {
+			t.Fatalf("%q: Generate failed: %v", fit, err)
+		}
+		if mime != "image/png" {
+			t.Fatalf("%q: expected image/png, got %s", fit, mime)
+		}
+
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(thumb))
+		if err != nil {
+			t.Fatalf("%q: decode thumbnail: %v", fit, err)
+		}
+		if cfg.Width != 10 || cfg.Height != 10 {
+			t.Fatalf("%q: expected 10x10 thumbnail, got %dx%d", fit, cfg.Width, cfg.Height)
+		}
+	}
+
+	thumb, _, err := NewVipsImageProcessor(0).Generate(context.Background(), src, ThumbnailSize{Name: "thumb", Width: 10, Height: 10, Fit: "contain"}, "image/png")
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(thumb))
+	if err != nil {
+		t.Fatalf("decode thumbnail: %v", err)
+	}
+	if _, _, _, a := img.At(0, 0).RGBA(); a != 0 {
+		t.Fatalf("expected contain to pad with transparency, got alpha %d", a)
+	}
+	if _, _, _, a := img.At(5, 5).RGBA(); a == 0 {
+		t.Fatalf("expected contain to keep the image centred")
+	}
+}
+
+func TestVipsImageProcessorQuality(t *testing.T) {
+	if got := NewVipsImageProcessor(0).quality; got != 85 {
+		t.Fatalf("expected out of range quality to fall back to 85, got %d", got)
+	}
+
+	src := encodeTestJPEG(t, 64, 64, nil)
+	size := ThumbnailSize{Name: "thumb", Width: 32, Height: 32, Fit: "cover"}
+
+	low, mime, err := NewVipsImageProcessor(90).WithEncoding(ImageEncoding{Quality: 10}).Generate(context.Background(), src, size, "image/jpeg")
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if mime != "image/jpeg" {
+		t.Fatalf("expected image/jpeg, got %s", mime)
+	}
+
+	size.Encoding = ImageEncoding{Quality: 100}
+	high, _, err := NewVipsImageProcessor(90).WithEncoding(ImageEncoding{Quality: 10}).Generate(context.Background(), src, size, "image/jpeg")
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	if len(high) <= len(low) {
+		t.Fatalf("expected per-size quality to override the processor default: %d <= %d", len(high), len(low))
+	}
+}
+
+func TestVipsImageProcessorEncoding(t *testing.T) {
+	src := encodeTestJPEG(t, 64, 64, nil)
+	size := ThumbnailSize{Name: "thumb", Width: 32, Height: 32, Fit: "cover"}
+
+	for _, tc := range []struct {
+		encoding    ImageEncoding
+		progressive bool
+		sampling    byte
+	}{
+		{ImageEncoding{ChromaSubsampling: "4:2:0"}, false, 0x22},
+		{ImageEncoding{ChromaSubsampling: "4:4:4", Progressive: true}, true, 0x11},
+	} {
+		thumb, _, err := NewVipsImageProcessor(0).WithEncoding(tc.encoding).Generate(context.Background(), src, size, "image/jpeg")
+		if err != nil {
+			t.Fatalf("%+v: Generate failed: %v", tc.encoding, err)
+		}
+
+		progressive, sampling := jpegFrame(t, thumb)
+		if progressive != tc.progressive || sampling != tc.sampling {
+			t.Fatalf("%+v: expected progressive %v and sampling %#x, got %v and %#x", tc.encoding, tc.progressive, tc.sampling, progressive, sampling)
+		}
+	}
+}
+
+func TestVipsImageProcessorExportFormat(t *testing.T) {
+	size := ThumbnailSize{Name: "thumb", Width: 8, Height: 8, Fit: "cover"}
+
+	opaque := image.NewGray(image.Rect(0, 0, 16, 16))
+	transparent := image.NewNRGBA(image.Rect(0, 0, 16, 16))
+	transparent.Set(4, 4, color.NRGBA{R: 0xff, A: 0x80})
+
+	for _, tc := range []struct {
+		name   string
+		source []byte
+		want   string
+	}{
+		{"png", createTestPNG(16, 16), "image/png"},
+		{"jpeg", encodeTestJPEG(t, 16, 16, nil), "image/jpeg"},
+		{"gif", encodeTestImage(t, opaque, func(buf *bytes.Buffer, img image.Image) error { return gif.Encode(buf, img, nil) }), "image/gif"},
+		{"opaque tiff", encodeTestImage(t, opaque, func(buf *bytes.Buffer, img image.Image) error { return tiff.Encode(buf, img, nil) }), "image/jpeg"},
+		{"transparent tiff", encodeTestImage(t, transparent, func(buf *bytes.Buffer, img image.Image) error { return tiff.Encode(buf, img, nil) }), "image/png"},
+	} {
+		thumb, mime, err := NewVipsImageProcessor(0).Generate(context.Background(), tc.source, size, "")
+		if err != nil {
+			t.Fatalf("%s: Generate failed: %v", tc.name, err)
+		}
+		if mime != tc.want {
+			t.Fatalf("%s: expected %s, got %s", tc.name, tc.want, mime)
+		}
+		if _, format, err := image.DecodeConfig(bytes.NewReader(thumb)); err != nil || "image/"+format != tc.want {
+			t.Fatalf("%s: expected %s output, got %q (%v)", tc.name, tc.want, format, err)
+		}
+	}
+}
+
+func TestVipsImageProcessorErrors(t *testing.T) {
+	size := ThumbnailSize{Name: "thumb", Width: 8, Height: 8, Fit: "cover"}
+
+	if _, _, err := NewVipsImageProcessor(0).Generate(context.Background(), nil, size, "image/png"); err == nil {
+		t.Fatalf("expected empty source to fail")
+	}
+	if _, _, err := NewVipsImageProcessor(0).Generate(context.Background(), []byte("not an image"), size, "image/png"); err == nil {
+		t.Fatalf("expected undecodable source to fail")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, _, err := NewVipsImageProcessor(0).Generate(ctx, createTestPNG(16, 16), size, "image/png"); err != context.Canceled {
+		t.Fatalf("expected canceled context, got %v", err)
+	}
+}
+
+func encodeTestImage(t *testing.T, img image.Image, encode func(*bytes.Buffer, image.Image) error) []byte {
+	t.Helper()
+
+	buf := &bytes.Buffer{}
+	if err := encode(buf, img); err != nil {
+		t.Fatalf("encode fixture: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// jpegFrame reports whether data is a progressive JPEG and the sampling factors of its first
+// component: 0x22 for 4:2:0, 0x11 for 4:4:4.
+func jpegFrame(t *testing.T, data []byte) (bool, byte) {
+	t.Helper()
+
+	for i := 2; i+3 < len(data); {
+		if data[i] != 0xFF {
+			break
+		}
+		marker := data[i+1]
+		length := int(data[i+2])<<8 | int(data[i+3])
+		if (marker == 0xC0 || marker == 0xC2) && i+11 < len(data) {
+			return marker == 0xC2, data[i+11]
+		}
+		i += 2 + length
+	}
+	t.Fatalf("no frame header found")
+	return false, 0
+}
@@ -0,0 +1,178 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"html/template"
+	"mime"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Index lists the objects under a prefix by key, as rendered by GenerateIndex.
+type Index struct {
+	Prefix      string       `json:"prefix"`
+	GeneratedAt time.Time    `json:"generated_at"`
+	Entries     []IndexEntry `json:"entries"`
+}
+
+// IndexEntry is one original object of an Index. Derivatives are listed under Thumbnails rather
+// than as entries of their own.
+type IndexEntry struct {
+	// Key is the external key, encoded with the KeyCodec when one is configured.
+	Key         string            `json:"key"`
+	Name        string            `json:"name"`
+	Size        int64             `json:"size"`
+	ModTime     time.Time         `json:"mod_time"`
+	ContentType string            `json:"content_type,omitempty"`
+	URL         string            `json:"url"`
+	Thumbnails  map[string]string `json:"thumbnails,omitempty"`
+}
+
+// Preview returns the URL of the entry's first thumbnail by variant name, the entry URL for
+// images without thumbnails, and "" otherwise.
+func (e IndexEntry) Preview() string {
+	if len(e.Thumbnails) > 0 {
+		variants := make([]string, 0, len(e.Thumbnails))
+		for variant := range e.Thumbnails {
+			variants = append(variants, variant)
+		}
+		sort.Strings(variants)
+		return e.Thumbnails[variants[0]]
+	}
+	if strings.HasPrefix(e.ContentType, "image/") {
+		return e.URL
+	}
+	return ""
+}
+
+// DefaultIndexTemplate renders an Index as a plain HTML gallery.
+var DefaultIndexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="UTF-8">
+  <title>{{ .Prefix }}</title>
+</head>
+<body>
+  <h1>{{ .Prefix }}</h1>
+  <ul>
+  {{- range .Entries }}
+    <li>
+      <a href="{{ .URL }}">{{ with .Preview }}<img src="{{ . }}" alt="" loading="lazy"><br>{{ end }}{{ .Name }}</a>
+      <small>{{ .Size }} bytes</small>
+    </li>
+  {{- end }}
+  </ul>
+</body>
+</html>
+`))
+
+// GenerateIndex lists the objects under prefix and renders them with tmpl, which receives an
+// *Index; a nil tmpl renders the Index as JSON. Thumbnails are grouped under their originals,
+// including those on a WithDerivativeProvider provider. Links use the provider's public URL
+// when it can build one and a presigned URL valid for the presign policy's URLTTL otherwise, so
+// indexes meant to be published should live on providers with public URLs.
+func (m *Manager) GenerateIndex(ctx context.Context, prefix string, tmpl *template.Template) ([]byte, error) {
+	index, err := m.buildIndex(ctx, prefix, "")
+	if err != nil {
+		return nil, err
+	}
+	return renderIndex(index, tmpl)
+}
+
+// PublishIndex renders the index of prefix like GenerateIndex and uploads it under key, leaving
+// key itself out of the listing. It returns the URL of the stored index.
+func (m *Manager) PublishIndex(ctx context.Context, prefix, key string, tmpl *template.Template, opts ...UploadOption) (string, error) {
+	index, err := m.buildIndex(ctx, prefix, key)
+	if err != nil {
+		return "", err
+	}
+	content, err := renderIndex(index, tmpl)
+	if err != nil {
+		return "", err
+	}
+
+	contentType := "text/html; charset=utf-8"
+	if tmpl == nil {
+		contentType = "application/json"
+	}
+	return m.UploadFile(ctx, key, content, append([]UploadOption{WithContentType(contentType)}, opts...)...)
+}
+
+func renderIndex(index *Index, tmpl *template.Template) ([]byte, error) {
+	if tmpl == nil {
+		return json.MarshalIndent(index, "", "  ")
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, index); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (m *Manager) buildIndex(ctx context.Context, prefix, exclude string) (*Index, error) {
+	objects, err := m.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].Key < objects[j].Key
+	})
+	derivatives, err := m.listDerivatives(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	pattern := m.thumbnailKeyTemplate().pattern()
+	byKey := make(map[string]ObjectInfo, len(objects))
+	for _, obj := range objects {
+		byKey[obj.Key] = obj
+	}
+
+	thumbnails := make(map[string]map[string]string)
+	for _, obj := range append(objects[:len(objects):len(objects)], derivatives...) {
+		original, variant, ok := parseDerivativeKey(pattern, obj.Key)
+		if !ok || original == obj.Key || variant == "" {
+			continue
+		}
+		if _, listed := byKey[original]; !listed {
+			continue
+		}
+		url, err := m.URLFor(ctx, obj.Key, VisibilityPublicRead, 0)
+		if err != nil {
+			return nil, err
+		}
+		if thumbnails[original] == nil {
+			thumbnails[original] = make(map[string]string)
+		}
+		thumbnails[original][variant] = url
+	}
+
+	index := &Index{Prefix: prefix, GeneratedAt: m.now()}
+	for _, obj := range objects {
+		if obj.Key == exclude || isThumbnailKey(pattern, obj.Key, byKey) {
+			continue
+		}
+		url, err := m.URLFor(ctx, obj.Key, VisibilityPublicRead, 0)
+		if err != nil {
+			return nil, err
+		}
+		contentType := obj.ContentType
+		if contentType == "" {
+			contentType = mime.TypeByExtension(path.Ext(obj.Key))
+		}
+		index.Entries = append(index.Entries, IndexEntry{
+			Key:         m.EncodeKey(obj.Key),
+			Name:        path.Base(obj.Key),
+			Size:        obj.Size,
+			ModTime:     obj.ModTime,
+			ContentType: contentType,
+			URL:         url,
+			Thumbnails:  thumbnails[obj.Key],
+		})
+	}
+	return index, nil
+}
@@ -0,0 +1,67 @@
+package uploader
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestGenerateIndex(t *testing.T) {
+	ctx := context.Background()
+	sizes := []ThumbnailSize{{Name: "small", Width: 8, Height: 8, Fit: "cover"}}
+
+	for name, opts := range map[string][]Option{
+		"shared provider": {WithProvider(NewFSProvider(t.TempDir(), WithFSURLPrefix("/files")))},
+		"derivative provider": {
+			WithProvider(NewFSProvider(t.TempDir(), WithFSURLPrefix("/files"))),
+			WithDerivativeProvider(NewFSProvider(t.TempDir(), WithFSURLPrefix("https://cdn.example.com"))),
+			WithThumbnailKeyTemplate(DerivativesFolderKeyTemplate),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			manager := NewManager(opts...)
+			meta, err := manager.HandleImageWithThumbnails(ctx, newTestFileHeader(t, "file", "photo.png", "image/png", createTestPNG(32, 32)), "gallery", sizes)
+			if err != nil {
+				t.Fatalf("HandleImageWithThumbnails: %v", err)
+			}
+			if _, err := manager.UploadFile(ctx, "gallery/notes.txt", []byte("notes")); err != nil {
+				t.Fatalf("UploadFile: %v", err)
+			}
+
+			raw, err := manager.GenerateIndex(ctx, "gallery/", nil)
+			if err != nil {
+				t.Fatalf("GenerateIndex: %v", err)
+			}
+			var index Index
+			if err := json.Unmarshal(raw, &index); err != nil {
+				t.Fatalf("decode index: %v", err)
+			}
+			if len(index.Entries) != 2 {
+				t.Fatalf("expected the image and the text file only, got %+v", index.Entries)
+			}
+
+			var image IndexEntry
+			for _, entry := range index.Entries {
+				if entry.Key == meta.Name {
+					image = entry
+				}
+			}
+			if image.URL != "/files/"+meta.Name || image.Thumbnails["small"] != meta.ThumbnailURL("small", "") {
+				t.Fatalf("expected image links to match the upload, got %+v (thumbnail %q)", image, meta.ThumbnailURL("small", ""))
+			}
+
+			url, err := manager.PublishIndex(ctx, "gallery/", "gallery/index.html", DefaultIndexTemplate)
+			if err != nil {
+				t.Fatalf("PublishIndex: %v", err)
+			}
+			html, err := manager.GetFile(ctx, "gallery/index.html")
+			if err != nil || url == "" {
+				t.Fatalf("expected the index to be stored, got %q, %v", url, err)
+			}
+			if !strings.Contains(string(html), `<img src="`+image.Thumbnails["small"]) || !strings.Contains(string(html), "notes.txt") || strings.Contains(string(html), "index.html") {
+				t.Fatalf("unexpected published index:\n%s", html)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+package uploader
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"mime"
+	"path"
+	"sort"
+	"strconv"
+	"time"
+
+	gerrors "github.com/goliatone/go-errors"
+)
+
+// InventoryFormat selects how ExportInventory encodes records.
+type InventoryFormat string
+
+const (
+	// InventoryCSV writes a header row followed by one row per object. Resumed exports skip the
+	// header so their output can be appended to the first file.
+	InventoryCSV InventoryFormat = "csv"
+	// InventoryJSON writes one JSON object per line (JSON Lines).
+	InventoryJSON InventoryFormat = "json"
+)
+
+// InventoryRecord is one exported object. Checksum is the provider ETag when it reports one and
+// ContentType is derived from the key extension, since listings do not carry either reliably.
+type InventoryRecord struct {
+	Key         string    `json:"key"`
+	Size        int64     `json:"size"`
+	ContentType string    `json:"content_type,omitempty"`
+	Checksum    string    `json:"checksum,omitempty"`
+	UploadedAt  time.Time `json:"uploaded_at"`
+}
+
+// InventoryOptions scopes an export.
+type InventoryOptions struct {
+	Prefix string
+	// ResumeToken continues from the NextToken returned by an earlier export.
+	ResumeToken string
+	// Limit stops the export after that many records. Zero exports everything.
+	Limit int
+	// PageSize is the number of keys requested per listing call; defaults to
+	// DefaultInventoryPageSize.
+	PageSize int
+}
+
+// InventoryResult reports an export. NextToken is empty once every object under the prefix has
+// been written; otherwise pass it as ResumeToken to continue.
+type InventoryResult struct {
+	Exported  int    `json:"exported"`
+	NextToken string `json:"next_token,omitempty"`
+}
+
+// ObjectPager is implemented by providers that can list objects a page at a time in key order,
+// starting after a given key, so exports of large buckets never hold every key in memory.
+type ObjectPager interface {
+	ListPage(ctx context.Context, prefix, startAfter string, limit int) (objects []ObjectInfo, more bool, err error)
+}
+
+// ExportInventory streams the objects under opts.Prefix to w in key order. Providers implementing
+// ObjectPager are read page by page; other Listers are listed once and sorted. When the export
+// stops early, because of opts.Limit, a cancelled ctx or a failed write, the result carries a
+// NextToken that resumes right after the last record written.
+func (m *Manager) ExportInventory(ctx context.Context, w io.Writer, format InventoryFormat, opts InventoryOptions) (*InventoryResult, error) {
+	encoder, err := newInventoryEncoder(w, format)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := decodeInventoryToken(opts.ResumeToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.ensureProvider(ctx); err != nil {
+		return nil, err
+	}
+
+	pager, ok := m.currentProvider().(ObjectPager)
+	if !ok {
+		lister, ok := m.currentProvider().(Lister)
+		if !ok {
+			return nil, ErrNotImplemented
+		}
+		pager = &listPager{lister: lister}
+	}
+
+	pageSize := opts.PageSize
+	if pageSize <= 0 {
+		pageSize = DefaultInventoryPageSize
+	}
+
+	result := &InventoryResult{}
+	stop := func(err error) (*InventoryResult, error) {
+		if flushErr := encoder.flush(); err == nil {
+			err = flushErr
+		}
+		if err != nil && after != "" {
+			result.NextToken = encodeInventoryToken(after)
+		}
+		return result, err
+	}
+
+	if opts.ResumeToken == "" {
+		if err := encoder.header(); err != nil {
+			return stop(err)
+		}
+	}
+
+	for {
+		limit := pageSize
+		if opts.Limit > 0 && opts.Limit-result.Exported < limit {
+			limit = opts.Limit - result.Exported
+		}
+
+		var more bool
+		objects, err := observeCall(ctx, m, "list", opts.Prefix, func() (objs []ObjectInfo, err error) {
+			objs, more, err = pager.ListPage(ctx, opts.Prefix, after, limit)
+			return objs, err
+		})
+		if err != nil {
+			return stop(err)
+		}
+
+		for _, obj := range objects {
+			if err := ctx.Err(); err != nil {
+				return stop(err)
+			}
+			if err := encoder.write(inventoryRecord(obj)); err != nil {
+				return stop(err)
+			}
+			result.Exported++
+			after = obj.Key
+		}
+
+		if !more || len(objects) == 0 {
+			return stop(nil)
+		}
+
+		if opts.Limit > 0 && result.Exported >= opts.Limit {
+			result.NextToken = encodeInventoryToken(after)
+			return stop(nil)
+		}
+	}
+}
+
+func inventoryRecord(obj ObjectInfo) InventoryRecord {
+	return InventoryRecord{
+		Key:         obj.Key,
+		Size:        obj.Size,
+		ContentType: mime.TypeByExtension(path.Ext(obj.Key)),
+		Checksum:    obj.ETag,
+		UploadedAt:  obj.ModTime.UTC(),
+	}
+}
+
+func encodeInventoryToken(key string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key))
+}
+
+func decodeInventoryToken(token string) (string, error) {
+	if token == "" {
+		return "", nil
+	}
+
+	key, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return "", gerrors.NewValidation("inventory export failed",
+			gerrors.FieldError{
+				Field:   "resume_token",
+				Message: "resume token is malformed",
+				Value:   token,
+			},
+		).WithCode(400).WithTextCode("INVALID_RESUME_TOKEN")
+	}
+	return string(key), nil
+}
+
+// listPager pages over a single List call for providers without native paging.
+type listPager struct {
+	lister  Lister
+	objects []ObjectInfo
+	loaded  bool
+}
+
+func (l *listPager) ListPage(ctx context.Context, prefix, startAfter string, limit int) ([]ObjectInfo, bool, error) {
+	if !l.loaded {
+		objects, err := l.lister.List(ctx, prefix)
+		if err != nil {
+			return nil, false, err
+		}
+		sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+		l.objects, l.loaded = objects, true
+	}
+
+	start := sort.Search(len(l.objects), func(i int) bool { return l.objects[i].Key > startAfter })
+	end := len(l.objects)
+	if limit > 0 && start+limit < end {
+		end = start + limit
+	}
+	return l.objects[start:end], end < len(l.objects), nil
+}
+
+type inventoryEncoder interface {
+	header() error
+	write(InventoryRecord) error
+	flush() error
+}
+
+func newInventoryEncoder(w io.Writer, format InventoryFormat) (inventoryEncoder, error) {
+	switch format {
+	case InventoryCSV:
+		return &csvInventoryEncoder{w: csv.NewWriter(w)}, nil
+	case InventoryJSON:
+		return &jsonInventoryEncoder{enc: json.NewEncoder(w)}, nil
+	}
+
+	return nil, gerrors.NewValidation("inventory export failed",
+		gerrors.FieldError{
+			Field:   "format",
+			Message: "unsupported inventory format",
+			Value:   format,
+		},
+	).WithCode(400).WithTextCode("INVALID_INVENTORY_FORMAT")
+}
+
+type csvInventoryEncoder struct {
+	w *csv.Writer
+}
+
+func (e *csvInventoryEncoder) header() error {
+	return e.w.Write([]string{"key", "size", "content_type", "checksum", "uploaded_at"})
+}
+
+func (e *csvInventoryEncoder) write(r InventoryRecord) error {
+	var uploadedAt string
+	if !r.UploadedAt.IsZero() {
+		uploadedAt = r.UploadedAt.Format(time.RFC3339)
+	}
+	return e.w.Write([]string{r.Key, strconv.FormatInt(r.Size, 10), r.ContentType, r.Checksum, uploadedAt})
+}
+
+func (e *csvInventoryEncoder) flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type jsonInventoryEncoder struct {
+	enc *json.Encoder
+}
+
+func (e *jsonInventoryEncoder) header() error { return nil }
+
+func (e *jsonInventoryEncoder) write(r InventoryRecord) error { return e.enc.Encode(r) }
+
+func (e *jsonInventoryEncoder) flush() error { return nil }
@@ -0,0 +1,80 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestExportInventoryResume(t *testing.T) {
+	ctx := context.Background()
+	manager := NewManager(WithProvider(NewFSProvider(t.TempDir())))
+
+	for _, key := range []string{"docs/c.txt", "docs/a.txt", "docs/b.pdf", "other/d.txt"} {
+		if _, err := manager.UploadFile(ctx, key, []byte(key)); err != nil {
+			t.Fatalf("UploadFile %s failed: %v", key, err)
+		}
+	}
+
+	var out bytes.Buffer
+	first, err := manager.ExportInventory(ctx, &out, InventoryCSV, InventoryOptions{Prefix: "docs/", Limit: 2, PageSize: 1})
+	if err != nil {
+		t.Fatalf("ExportInventory failed: %v", err)
+	}
+	if first.Exported != 2 || first.NextToken == "" {
+		t.Fatalf("expected partial export with token, got %#v", first)
+	}
+
+	second, err := manager.ExportInventory(ctx, &out, InventoryCSV, InventoryOptions{Prefix: "docs/", ResumeToken: first.NextToken})
+	if err != nil {
+		t.Fatalf("resumed ExportInventory failed: %v", err)
+	}
+	if second.Exported != 1 || second.NextToken != "" {
+		t.Fatalf("expected resumed export to finish, got %#v", second)
+	}
+
+	rows, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatalf("parse csv: %v", err)
+	}
+	if len(rows) != 4 || rows[0][0] != "key" {
+		t.Fatalf("expected a single header and three rows, got %v", rows)
+	}
+	if rows[1][0] != "docs/a.txt" || rows[2][0] != "docs/b.pdf" || rows[3][0] != "docs/c.txt" {
+		t.Fatalf("expected rows in key order, got %v", rows)
+	}
+	if rows[2][1] != "10" || rows[2][2] != "application/pdf" || rows[2][4] == "" {
+		t.Fatalf("unexpected record: %v", rows[2])
+	}
+}
+
+func TestExportInventoryJSON(t *testing.T) {
+	ctx := context.Background()
+	manager := NewManager(WithProvider(NewFSProvider(t.TempDir())))
+	if _, err := manager.UploadFile(ctx, "a.txt", []byte("hello")); err != nil {
+		t.Fatalf("UploadFile failed: %v", err)
+	}
+
+	var out bytes.Buffer
+	if _, err := manager.ExportInventory(ctx, &out, InventoryJSON, InventoryOptions{}); err != nil {
+		t.Fatalf("ExportInventory failed: %v", err)
+	}
+
+	var record InventoryRecord
+	if err := json.Unmarshal([]byte(strings.TrimSpace(out.String())), &record); err != nil {
+		t.Fatalf("parse json line: %v", err)
+	}
+	if record.Key != "a.txt" || record.Size != 5 || record.UploadedAt.IsZero() {
+		t.Fatalf("unexpected record: %#v", record)
+	}
+
+	if _, err := manager.ExportInventory(ctx, &out, "xml", InventoryOptions{}); err == nil {
+		t.Fatalf("expected error for unsupported format")
+	}
+	if _, err := manager.ExportInventory(ctx, &out, InventoryJSON, InventoryOptions{ResumeToken: "%%"}); err == nil {
+		t.Fatalf("expected error for malformed token")
+	}
+}
@@ -0,0 +1,116 @@
+package uploader
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"path"
+	"strings"
+)
+
+// KeyCodec maps storage keys to the keys handed out to clients, so users cannot enumerate or
+// guess other objects. Providers keep operating on the real keys.
+type KeyCodec interface {
+	// Encode returns the external form of key. It must be deterministic.
+	Encode(key string) string
+	// Decode returns the storage key for an external key. Keys that were not produced by Encode
+	// fail with ErrImageNotFound, so probing cannot tell them from missing objects.
+	Decode(external string) (string, error)
+}
+
+// WithKeyCodec sets the codec behind EncodeKey, DecodeKey and FileMeta.PublicKey.
+func WithKeyCodec(codec KeyCodec) Option {
+	return func(m *Manager) {
+		m.keyCodec = codec
+	}
+}
+
+// EncodeKey returns the external form of key, or key itself without a KeyCodec.
+func (m *Manager) EncodeKey(key string) string {
+	if m.keyCodec == nil {
+		return key
+	}
+	return m.keyCodec.Encode(key)
+}
+
+// DecodeKey returns the storage key for a key received from a client, or external itself
+// without a KeyCodec.
+func (m *Manager) DecodeKey(external string) (string, error) {
+	if m.keyCodec == nil {
+		return external, nil
+	}
+	return m.keyCodec.Decode(external)
+}
+
+// NewEncryptingKeyCodec returns a KeyCodec hiding keys entirely: the external key is the
+// AES-GCM encrypted key, base64url encoded, followed by the original extension. The nonce is
+// derived from the key, so a key always encodes the same way.
+func NewEncryptingKeyCodec(secret []byte) KeyCodec {
+	block, _ := aes.NewCipher(deriveCodecKey(secret, "encrypt"))
+	aead, _ := cipher.NewGCM(block)
+	return &encryptingKeyCodec{aead: aead, nonceKey: deriveCodecKey(secret, "nonce")}
+}
+
+// NewSigningKeyCodec returns a KeyCodec keeping keys readable but unguessable: the external key
+// is an HMAC tag followed by "/" and the key.
+func NewSigningKeyCodec(secret []byte) KeyCodec {
+	return &signingKeyCodec{secret: deriveCodecKey(secret, "sign")}
+}
+
+type encryptingKeyCodec struct {
+	aead     cipher.AEAD
+	nonceKey []byte
+}
+
+func (c *encryptingKeyCodec) Encode(key string) string {
+	nonce := codecMAC(c.nonceKey, key)[:c.aead.NonceSize()]
+	sealed := c.aead.Seal(nonce, nonce, []byte(key), nil)
+	return base64.RawURLEncoding.EncodeToString(sealed) + path.Ext(key)
+}
+
+func (c *encryptingKeyCodec) Decode(external string) (string, error) {
+	token, _, _ := strings.Cut(external, ".")
+	sealed, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(sealed) < c.aead.NonceSize() {
+		return "", ErrImageNotFound
+	}
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	key, err := c.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", ErrImageNotFound
+	}
+	return string(key), nil
+}
+
+type signingKeyCodec struct {
+	secret []byte
+}
+
+func (c *signingKeyCodec) Encode(key string) string {
+	return c.tag(key) + "/" + key
+}
+
+func (c *signingKeyCodec) Decode(external string) (string, error) {
+	tag, key, ok := strings.Cut(external, "/")
+	if !ok || !hmac.Equal([]byte(tag), []byte(c.tag(key))) {
+		return "", ErrImageNotFound
+	}
+	return key, nil
+}
+
+func (c *signingKeyCodec) tag(key string) string {
+	return base64.RawURLEncoding.EncodeToString(codecMAC(c.secret, key)[:16])
+}
+
+// deriveCodecKey derives a 32 byte key per purpose, so one secret can back several codecs.
+func deriveCodecKey(secret []byte, purpose string) []byte {
+	return codecMAC(secret, "key-codec:"+purpose)
+}
+
+func codecMAC(secret []byte, value string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
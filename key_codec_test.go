@@ -0,0 +1,156 @@
+package uploader
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestKeyCodecs(t *testing.T) {
+	secret := []byte("codec-secret")
+	codecs := map[string]KeyCodec{
+		"encrypting": NewEncryptingKeyCodec(secret),
+		"signing":    NewSigningKeyCodec(secret),
+	}
+
+	for name, codec := range codecs {
+		t.Run(name, func(t *testing.T) {
+			external := codec.Encode("users/42/avatar.png")
+			if external != codec.Encode("users/42/avatar.png") {
+				t.Fatalf("expected encoding to be deterministic")
+			}
+			if key, err := codec.Decode(external); err != nil || key != "users/42/avatar.png" {
+				t.Fatalf("Decode(%q) = %q, %v", external, key, err)
+			}
+
+			tampered := []byte(external)
+			tampered[2] ^= 1
+			for _, forged := range []string{"users/43/avatar.png", external[:len(external)-5] + "x.png", string(tampered), ""} {
+				if key, err := codec.Decode(forged); !errors.Is(err, ErrImageNotFound) {
+					t.Fatalf("expected forged key %q to be rejected, got %q, %v", forged, key, err)
+				}
+			}
+		})
+	}
+
+	encrypted := codecs["encrypting"].Encode("users/42/avatar.png")
+	if strings.Contains(encrypted, "users") || !strings.HasSuffix(encrypted, ".png") {
+		t.Fatalf("expected the key hidden and the extension kept, got %q", encrypted)
+	}
+	if other := NewEncryptingKeyCodec([]byte("other")); other.Encode("users/42/avatar.png") == encrypted {
+		t.Fatalf("expected different secrets to encode differently")
+	}
+}
+
+func TestManagerKeyCodec(t *testing.T) {
+	ctx := context.Background()
+	manager := NewManager(WithProvider(NewFSProvider(t.TempDir())), WithKeyCodec(NewSigningKeyCodec([]byte("s"))))
+
+	meta, err := manager.HandleFile(ctx, newTestFileHeader(t, "file", "a.png", "image/png", createTestPNG(2, 2)), "docs")
+	if err != nil {
+		t.Fatalf("HandleFile: %v", err)
+	}
+	if meta.PublicKey == "" || meta.PublicKey != manager.EncodeKey(meta.Name) {
+		t.Fatalf("expected PublicKey to be the encoded key, got %q", meta.PublicKey)
+	}
+	if key, err := manager.DecodeKey(meta.PublicKey); err != nil || key != meta.Name {
+		t.Fatalf("DecodeKey = %q, %v", key, err)
+	}
+
+	plain := NewManager(WithProvider(NewFSProvider(t.TempDir())))
+	if plain.EncodeKey("docs/a.txt") != "docs/a.txt" {
+		t.Fatalf("expected keys unchanged without a codec")
+	}
+}
+
+func TestFileMetaJSONHidesKeysBehindCodec(t *testing.T) {
+	ctx := context.Background()
+	manager := NewManager(WithProvider(NewFSProvider(t.TempDir())), WithKeyCodec(NewEncryptingKeyCodec([]byte("s"))))
+
+	sizes := []ThumbnailSize{{Name: "small", Width: 8, Height: 8, Fit: "cover"}}
+	image, err := manager.HandleImageWithThumbnails(ctx, newTestFileHeader(t, "file", "a.png", "image/png", createTestPNG(16, 16)), "users/42", sizes)
+	if err != nil {
+		t.Fatalf("HandleImageWithThumbnails: %v", err)
+	}
+
+	for name, value := range map[string]any{"file meta": image.FileMeta, "image meta": image} {
+		data, err := json.Marshal(value)
+		if err != nil {
+			t.Fatalf("%s: Marshal: %v", name, err)
+		}
+		for _, key := range []string{image.Name, image.Thumbnails["small"].Name} {
+			if stem := strings.TrimSuffix(path.Base(key), path.Ext(key)); strings.Contains(string(data), stem) {
+				t.Fatalf("%s: expected the storage key %q to stay out of %s", name, key, data)
+			}
+		}
+
+		var decoded ImageMeta
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("%s: Unmarshal: %v", name, err)
+		}
+		if decoded.Name != image.PublicKey || decoded.URL != "" || decoded.Ref != nil {
+			t.Fatalf("%s: expected name to carry the public key, got %+v", name, decoded.FileMeta)
+		}
+	}
+
+	data, _ := json.Marshal(image)
+	var decoded ImageMeta
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.Thumbnails["small"].Name != image.Thumbnails["small"].PublicKey {
+		t.Fatalf("expected encoded thumbnails in the image meta, got %s (%v)", data, err)
+	}
+
+	plain := &FileMeta{Name: "docs/a.txt", URL: "/docs/a.txt"}
+	if data, _ := json.Marshal(plain); !strings.Contains(string(data), `"name":"docs/a.txt"`) || !strings.Contains(string(data), `"url":"/docs/a.txt"`) {
+		t.Fatalf("expected keys unchanged without a codec, got %s", data)
+	}
+}
+
+func TestJSONHidesTransformedOriginalsAndResultsBehindCodec(t *testing.T) {
+	ctx := context.Background()
+	toJPEG := UploadTransformFunc(func(context.Context, []byte, string) ([]byte, string, error) {
+		return encodeTestJPEG(t, 8, 8, nil), "image/jpeg", nil
+	})
+	manager := NewManager(
+		WithProvider(NewFSProvider(t.TempDir())),
+		WithKeyCodec(NewEncryptingKeyCodec([]byte("s"))),
+		WithUploadTransforms(toJPEG),
+		WithKeepTransformedOriginals(),
+		WithCollisionPolicy(CollisionVersion),
+	)
+
+	meta, err := manager.HandleFile(ctx, newTestFileHeader(t, "file", "a.png", "image/png", createTestPNG(8, 8)), "secret-dir")
+	if err != nil {
+		t.Fatalf("HandleFile: %v", err)
+	}
+	if meta.Attributes["original_key"] == "" {
+		t.Fatalf("expected the transformed original to be recorded")
+	}
+	data, _ := json.Marshal(meta)
+	if strings.Contains(string(data), "secret-dir") || strings.Contains(string(data), "original_key") {
+		t.Fatalf("expected the storage keys to stay out of %s", data)
+	}
+
+	for range 2 {
+		if _, err := manager.UploadFileResult(ctx, "secret-dir/b.txt", []byte("b")); err != nil {
+			t.Fatalf("UploadFileResult: %v", err)
+		}
+	}
+	result, err := manager.UploadFileResult(ctx, "secret-dir/b.txt", []byte("c"))
+	if err != nil {
+		t.Fatalf("UploadFileResult: %v", err)
+	}
+	if result.PreviousVersion == "" || result.PublicKey != manager.EncodeKey(result.Key) {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	data, _ = json.Marshal(result)
+	if strings.Contains(string(data), "secret-dir") {
+		t.Fatalf("expected the storage keys to stay out of %s", data)
+	}
+	var decoded UploadResult
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.Key != result.PublicKey || decoded.PreviousVersion != manager.EncodeKey(result.PreviousVersion) {
+		t.Fatalf("expected encoded keys in the result, got %s (%v)", data, err)
+	}
+}
@@ -0,0 +1,95 @@
+package uploader
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// WithMaxKeyLength caps object keys at n bytes. Zero disables the check.
+func WithMaxKeyLength(n int) ValidatorOption {
+	return func(uv *Validator) {
+		uv.maxKeyLength = n
+	}
+}
+
+// WithMaxFilenameLength caps each key segment, and uploaded file names, at n bytes. Zero disables
+// the check.
+func WithMaxFilenameLength(n int) ValidatorOption {
+	return func(uv *Validator) {
+		uv.maxFilenameLength = n
+	}
+}
+
+// WithDisallowedUnicode replaces the character classes rejected in keys and file names. Call it
+// with no tables to accept any character.
+func WithDisallowedUnicode(tables ...*unicode.RangeTable) ValidatorOption {
+	return func(uv *Validator) {
+		uv.disallowedUnicode = tables
+	}
+}
+
+// WithReservedNames replaces the key segment names the validator rejects. Names match case
+// insensitively, with or without an extension. Call it with no names to disable the check.
+func WithReservedNames(names ...string) ValidatorOption {
+	return func(uv *Validator) {
+		uv.reservedNames = reservedNameSet(names)
+	}
+}
+
+func reservedNameSet(names []string) map[string]bool {
+	set := make(map[string]bool, len(names))
+	for _, name := range names {
+		set[strings.ToUpper(name)] = true
+	}
+	return set
+}
+
+// ValidateKey checks key against the length, character and reserved name rules before it is
+// handed to a provider.
+func (u *Validator) ValidateKey(key string) error {
+	if u.maxKeyLength > 0 && len(key) > u.maxKeyLength {
+		return u.fieldError("key", "KEY_TOO_LONG", key, map[string]any{"max_length": u.maxKeyLength})
+	}
+
+	for _, segment := range strings.Split(key, "/") {
+		if err := u.validateName("key", key, segment); err != nil {
+			return err
+		}
+
+		base, _, _ := strings.Cut(segment, ".")
+		if u.reservedNames[strings.ToUpper(strings.TrimSpace(base))] {
+			return u.fieldError("key", "RESERVED_FILENAME", key, map[string]any{"name": segment})
+		}
+	}
+
+	return nil
+}
+
+// validateName applies the filename length and character rules to name, reporting failures
+// against field with value.
+func (u *Validator) validateName(field, value, name string) error {
+	if u.maxFilenameLength > 0 && len(name) > u.maxFilenameLength {
+		return u.fieldError(field, "FILENAME_TOO_LONG", value, map[string]any{"max_length": u.maxFilenameLength})
+	}
+
+	if len(u.disallowedUnicode) == 0 {
+		return nil
+	}
+
+	for _, r := range name {
+		if unicode.IsOneOf(u.disallowedUnicode, r) {
+			return u.fieldError(field, "INVALID_CHARACTER", value, map[string]any{"character": fmt.Sprintf("%U", r)})
+		}
+	}
+
+	return nil
+}
+
+// validateKey applies the structural key checks and the validator's key policy.
+func (m *Manager) validateKey(key string) error {
+	if err := validateObjectKey(key); err != nil {
+		return err
+	}
+	return m.validator.ValidateKey(key)
+}
@@ -0,0 +1,177 @@
+package uploader
+
+import (
+	"regexp"
+	"strings"
+)
+
+// RedactedValue replaces log values removed by LogRedaction.
+const RedactedValue = "[REDACTED]"
+
+// LogRedaction selects what is removed from log lines before they reach the Logger. Arguments
+// are read as name/value pairs, as the manager and providers log them.
+type LogRedaction struct {
+	// PresignedURLs strips query strings, which carry presigned signatures and tokens, from URLs
+	// in logged strings and errors.
+	PresignedURLs bool
+	// Credentials replaces values named like secrets, tokens, passwords or signatures, and
+	// ScopedCredentials.
+	Credentials bool
+	// Metadata replaces user supplied attributes and metadata maps.
+	Metadata bool
+	// Fields lists further argument names to replace, compared case-insensitively.
+	Fields []string
+}
+
+// DefaultLogRedaction is applied when no other policy is configured.
+var DefaultLogRedaction = LogRedaction{PresignedURLs: true, Credentials: true, Metadata: true}
+
+// WithLogRedaction sets what the manager removes from its log lines, DefaultLogRedaction by
+// default. Pass LogRedaction{} to log everything. Providers redact with DefaultLogRedaction unless
+// their logger was built with NewRedactingLogger.
+func WithLogRedaction(redaction LogRedaction) Option {
+	return func(m *Manager) {
+		m.logRedaction = &redaction
+	}
+}
+
+// DebugLogger is implemented by loggers that accept verbose diagnostics, such as raw provider
+// responses. Loggers without it do not receive them.
+type DebugLogger interface {
+	Debug(msg string, args ...any)
+}
+
+// NewRedactingLogger returns a Logger applying redaction before handing lines to logger.
+func NewRedactingLogger(logger Logger, redaction LogRedaction) Logger {
+	if r, ok := logger.(*redactingLogger); ok {
+		logger = r.logger
+	}
+	return &redactingLogger{logger: logger, redaction: redaction}
+}
+
+// redactLogger applies the default policy unless logger already redacts.
+func redactLogger(logger Logger) Logger {
+	if _, ok := logger.(*redactingLogger); ok {
+		return logger
+	}
+	return NewRedactingLogger(logger, DefaultLogRedaction)
+}
+
+func logDebug(logger Logger, msg string, args ...any) {
+	if debug, ok := logger.(DebugLogger); ok {
+		debug.Debug(msg, args...)
+	}
+}
+
+type redactingLogger struct {
+	logger    Logger
+	redaction LogRedaction
+}
+
+func (l *redactingLogger) Info(msg string, args ...any) {
+	l.logger.Info(msg, l.redaction.apply(args)...)
+}
+
+func (l *redactingLogger) Error(msg string, args ...any) {
+	l.logger.Error(msg, l.redaction.apply(args)...)
+}
+
+func (l *redactingLogger) Debug(msg string, args ...any) {
+	logDebug(l.logger, msg, l.redaction.apply(args)...)
+}
+
+var (
+	urlQueryPattern = regexp.MustCompile(`(https?://[^\s?#"']+)\?[^\s#"']*`)
+
+	credentialNames = []string{"secret", "token", "password", "credential", "authorization", "signature", "access_key"}
+	metadataNames   = []string{"attributes", "metadata", "fields"}
+)
+
+// apply returns a copy of args with redacted values. Pairs are detected by a string name followed
+// by a value, so a leading error argument is left in place.
+func (r LogRedaction) apply(args []any) []any {
+	if !r.PresignedURLs && !r.Credentials && !r.Metadata && len(r.Fields) == 0 {
+		return args
+	}
+
+	out := make([]any, len(args))
+	copy(out, args)
+	for i := 0; i < len(out); i++ {
+		name, ok := out[i].(string)
+		if !ok || i+1 == len(out) {
+			out[i] = r.redactValue(out[i])
+			continue
+		}
+		i++
+		if r.redactsField(name, out[i]) {
+			out[i] = RedactedValue
+		} else {
+			out[i] = r.redactValue(out[i])
+		}
+	}
+	return out
+}
+
+func (r LogRedaction) redactsField(name string, value any) bool {
+	name = strings.ToLower(name)
+	for _, field := range r.Fields {
+		if strings.EqualFold(field, name) {
+			return true
+		}
+	}
+	if r.Credentials {
+		switch value.(type) {
+		case ScopedCredentials, *ScopedCredentials:
+			return true
+		}
+		if containsAny(name, credentialNames) {
+			return true
+		}
+	}
+	if r.Metadata {
+		if _, ok := value.(map[string]string); ok {
+			return true
+		}
+		if containsAny(name, metadataNames) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r LogRedaction) redactValue(value any) any {
+	if r.PresignedURLs {
+		return stripURLQueries(value)
+	}
+	return value
+}
+
+func containsAny(s string, parts []string) bool {
+	for _, part := range parts {
+		if strings.Contains(s, part) {
+			return true
+		}
+	}
+	return false
+}
+
+func stripURLQueries(value any) any {
+	switch v := value.(type) {
+	case string:
+		return urlQueryPattern.ReplaceAllString(v, "$1?"+RedactedValue)
+	case error:
+		if msg := v.Error(); urlQueryPattern.MatchString(msg) {
+			return &redactedError{err: v, msg: urlQueryPattern.ReplaceAllString(msg, "$1?"+RedactedValue)}
+		}
+	}
+	return value
+}
+
+// redactedError keeps the wrapped error reachable while printing the redacted message.
+type redactedError struct {
+	err error
+	msg string
+}
+
+func (e *redactedError) Error() string { return e.msg }
+func (e *redactedError) Unwrap() error { return e.err }
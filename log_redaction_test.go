@@ -0,0 +1,81 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+type debugLogger struct {
+	argsLogger
+	debug [][]any
+}
+
+func (l *debugLogger) Debug(msg string, args ...any) {
+	l.debug = append(l.debug, append([]any{msg}, args...))
+}
+
+func TestLogRedaction(t *testing.T) {
+	logger := &argsLogger{}
+	redacting := NewRedactingLogger(logger, DefaultLogRedaction)
+
+	wrapped := errors.New("GET https://bucket.s3.amazonaws.com/a.png?X-Amz-Signature=abc failed")
+	redacting.Error("presign failed", wrapped,
+		"url", "https://bucket.s3.amazonaws.com/a.png?X-Amz-Credential=AKIA&X-Amz-Signature=abc",
+		"session_token", "tok",
+		"credentials", &ScopedCredentials{SecretAccessKey: "secret"},
+		"attributes", map[string]string{"owner": "alice"},
+		"key", "a.png",
+	)
+
+	line := logger.lines[0]
+	if err, ok := line[1].(error); !ok || strings.Contains(err.Error(), "abc") || !errors.Is(err, wrapped) {
+		t.Fatalf("expected the error message redacted and still wrapped, got %v", line[1])
+	}
+	want := []any{"url", "https://bucket.s3.amazonaws.com/a.png?" + RedactedValue, "session_token", RedactedValue,
+		"credentials", RedactedValue, "attributes", RedactedValue, "key", "a.png"}
+	for i, v := range want {
+		if line[i+2] != v {
+			t.Fatalf("arg %d: expected %v, got %v", i, v, line[i+2])
+		}
+	}
+
+	NewRedactingLogger(logger, LogRedaction{Fields: []string{"Key"}}).Info("stored", "key", "a.png", "url", "https://x/a?sig=1")
+	if line := logger.lines[1]; line[2] != RedactedValue || line[4] != "https://x/a?sig=1" {
+		t.Fatalf("expected only the configured field redacted, got %v", line)
+	}
+}
+
+func TestManagerLogRedaction(t *testing.T) {
+	logger := &argsLogger{}
+	manager := NewManager(WithLogger(logger), WithLogRedaction(LogRedaction{}))
+	manager.log(context.Background()).Info("raw", "token", "tok")
+	if logger.lines[0][2] != "tok" {
+		t.Fatalf("expected redaction disabled, got %v", logger.lines[0])
+	}
+
+	manager = NewManager(WithLogger(logger))
+	manager.log(context.Background()).Info("default", "token", "tok")
+	if logger.lines[1][2] != RedactedValue {
+		t.Fatalf("expected default redaction, got %v", logger.lines[1])
+	}
+}
+
+func TestAWSProviderResponseLoggedAtDebug(t *testing.T) {
+	logger := &debugLogger{}
+	provider := &AWSProvider{client: &fakeS3Client{}, bucket: "bucket", logger: logger}
+	if _, err := provider.UploadFile(context.Background(), "a.txt", []byte("a")); err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	for _, line := range logger.lines {
+		if _, ok := line[len(line)-1].(*s3.PutObjectOutput); ok || line[0] == "upload image response" {
+			t.Fatalf("expected no response dump at info level, got %v", line)
+		}
+	}
+	if len(logger.debug) != 1 || logger.debug[0][0] != "upload image response" {
+		t.Fatalf("expected the response logged at debug level, got %v", logger.debug)
+	}
+}
@@ -0,0 +1,104 @@
+package uploader
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	gerrors "github.com/goliatone/go-errors"
+)
+
+// ValidationMessage describes one failed validator rule in a form front-ends can translate: Key
+// names the message and Params fills its template. Message is the rendered text that also appears
+// in the error's FieldError.
+type ValidationMessage struct {
+	Field   string         `json:"field"`
+	Key     string         `json:"key"`
+	Params  map[string]any `json:"params,omitempty"`
+	Message string         `json:"message"`
+}
+
+// MessageCatalog renders validation messages, typically in the request's language. It reports
+// false for keys it does not know, falling back to DefaultValidationMessages.
+type MessageCatalog interface {
+	Message(key string, params map[string]any) (string, bool)
+}
+
+// MessageCatalogFunc adapts a function to MessageCatalog.
+type MessageCatalogFunc func(key string, params map[string]any) (string, bool)
+
+func (f MessageCatalogFunc) Message(key string, params map[string]any) (string, bool) {
+	return f(key, params)
+}
+
+// DefaultValidationMessages are the English templates for validator messages, keyed by message
+// key. Placeholders in braces are replaced with the matching entry of ValidationMessage.Params.
+var DefaultValidationMessages = map[string]string{
+	"uploader.validation.file_too_large":          "file too large, max: {max_size} bytes",
+	"uploader.validation.file_too_small":          "file too small, min: {min_size} bytes",
+	"uploader.validation.empty_file":              "file is empty",
+	"uploader.validation.invalid_file_format":     "invalid format, allowed: {allowed}",
+	"uploader.validation.invalid_mime_type":       "invalid mime type, allowed: {allowed}",
+	"uploader.validation.mime_extension_mismatch": "mime type does not match extension {extension}, expected: {expected}",
+	"uploader.validation.invalid_file_content":    "invalid file content",
+	"uploader.validation.image_too_large":         "image too large, max: {max_width}x{max_height} pixels",
+	"uploader.validation.key_too_long":            "key too long, max: {max_length} bytes",
+	"uploader.validation.filename_too_long":       "file name too long, max: {max_length} bytes",
+	"uploader.validation.invalid_character":       "disallowed character {character}",
+	"uploader.validation.reserved_filename":       "reserved name: {name}",
+}
+
+// WithMessageCatalog renders validation messages through catalog.
+func WithMessageCatalog(catalog MessageCatalog) ValidatorOption {
+	return func(uv *Validator) {
+		uv.messages = catalog
+	}
+}
+
+// ValidationMessages returns the translatable messages attached to a validator error, one per
+// failing rule. It returns nil for other errors.
+func ValidationMessages(err error) []ValidationMessage {
+	var validationErr *gerrors.Error
+	if !errors.As(err, &validationErr) {
+		return nil
+	}
+	messages, _ := validationErr.Metadata["messages"].([]ValidationMessage)
+	return messages
+}
+
+// validationMessageKey derives the message key from a validator text code.
+func validationMessageKey(textCode string) string {
+	return "uploader.validation." + strings.ToLower(textCode)
+}
+
+// fieldError builds the validation error for a failed rule, rendering its message and recording
+// the key and params under the "messages" metadata entry.
+func (u *Validator) fieldError(field, textCode string, value any, params map[string]any) *gerrors.Error {
+	key := validationMessageKey(textCode)
+	message := u.renderMessage(key, params)
+
+	return gerrors.NewValidation("file validation failed",
+		gerrors.FieldError{
+			Field:   field,
+			Message: message,
+			Value:   value,
+		},
+	).WithCode(400).WithTextCode(textCode).
+		WithMetadata(map[string]any{
+			"messages": []ValidationMessage{{Field: field, Key: key, Params: params, Message: message}},
+		})
+}
+
+func (u *Validator) renderMessage(key string, params map[string]any) string {
+	if u.messages != nil {
+		if message, ok := u.messages.Message(key, params); ok {
+			return message
+		}
+	}
+
+	message := DefaultValidationMessages[key]
+	for name, value := range params {
+		message = strings.ReplaceAll(message, "{"+name+"}", fmt.Sprint(value))
+	}
+	return message
+}
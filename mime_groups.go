@@ -0,0 +1,95 @@
+package uploader
+
+import (
+	"mime"
+	"strings"
+)
+
+// extensionMimeTypes maps the extensions AllowMimeGroups knows about to their canonical MIME type.
+// Aliased extensions (.jpg/.jpeg, .tif/.tiff) share one type, so either spelling is accepted.
+var extensionMimeTypes = map[string]string{
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+	".gif":  "image/gif",
+	".webp": "image/webp",
+	".bmp":  "image/bmp",
+	".tif":  "image/tiff",
+	".tiff": "image/tiff",
+	".svg":  "image/svg+xml",
+	".heic": "image/heic",
+	".heif": "image/heif",
+	".avif": "image/avif",
+	".pdf":  "application/pdf",
+	".json": "application/json",
+	".zip":  "application/zip",
+	".txt":  "text/plain",
+	".csv":  "text/csv",
+	".mp3":  "audio/mpeg",
+	".mp4":  "video/mp4",
+	".webm": "video/webm",
+}
+
+// AllowMimeGroups replaces the allowed MIME types and extensions with ones derived from groups,
+// which are exact types ("application/pdf") or type wildcards ("image/*"). Wildcards expand to the
+// types in the package's extension table; exact types not in the table also accept the extensions
+// registered for them with the mime package. It enables WithExtensionMimeCheck, so a ".png" sent
+// as "image/jpeg" is rejected.
+func AllowMimeGroups(groups ...string) ValidatorOption {
+	normalized := make([]string, 0, len(groups))
+	for _, group := range groups {
+		if group = strings.ToLower(strings.TrimSpace(group)); group != "" {
+			normalized = append(normalized, group)
+		}
+	}
+
+	return func(uv *Validator) {
+		types := map[string]bool{}
+		formats := map[string]bool{}
+
+		for ext, contentType := range extensionMimeTypes {
+			if matchesContentType(normalized, contentType) {
+				types[contentType] = true
+				formats[ext] = true
+			}
+		}
+
+		for _, group := range normalized {
+			if strings.HasSuffix(group, "/*") || types[group] {
+				continue
+			}
+			types[group] = true
+			exts, _ := mime.ExtensionsByType(group)
+			for _, ext := range exts {
+				formats[strings.ToLower(ext)] = true
+			}
+		}
+
+		uv.allowedMimeTypes = types
+		uv.allowedImageFormats = formats
+		uv.checkExtensionMime = true
+	}
+}
+
+// WithExtensionMimeCheck rejects files whose extension belongs to a different MIME type than the
+// one they were sent with. Extensions with no known type are not checked.
+func WithExtensionMimeCheck(enabled bool) ValidatorOption {
+	return func(uv *Validator) {
+		uv.checkExtensionMime = enabled
+	}
+}
+
+// extensionMimeType returns the canonical type for ext, or "" when it is unknown.
+func extensionMimeType(ext string) string {
+	ext = strings.ToLower(ext)
+	if contentType, ok := extensionMimeTypes[ext]; ok {
+		return contentType
+	}
+	return mediaType(mime.TypeByExtension(ext))
+}
+
+// extensionMatchesMime reports whether a file with ext may carry contentType.
+func extensionMatchesMime(ext, contentType string) bool {
+	expected := extensionMimeType(ext)
+	return expected == "" || expected == mediaType(contentType)
+}
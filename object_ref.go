@@ -0,0 +1,77 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// StoredObjectRef identifies a stored object in provider neutral terms, so callers do not have to
+// guess what the provider's URL string means (an absolute disk path, a bucket relative key, ...).
+type StoredObjectRef struct {
+	// Provider is the ProviderName of the backend holding the object.
+	Provider string `json:"provider,omitempty"`
+	// Location is the bucket the object lives in. FSProvider only reports its base directory
+	// under FSURLAbsolutePath.
+	Location string `json:"location,omitempty"`
+	// Key is the logical key passed to the manager.
+	Key string `json:"key"`
+	// ProviderKey is the key inside Location, including any base path the provider adds.
+	ProviderKey string `json:"provider_key,omitempty"`
+	// PublicURL is the unsigned address of the object when the provider can build one. It only
+	// serves content for VisibilityPublicRead objects.
+	PublicURL string `json:"public_url,omitempty"`
+
+	presign func(ctx context.Context, expires time.Duration) (string, error)
+}
+
+// LocationDescriber is implemented by providers that can name the bucket or directory they
+// store objects in.
+type LocationDescriber interface {
+	StorageLocation() string
+}
+
+// SignedURL returns a presigned URL for the object valid for expires. It fails with
+// ErrProviderNotConfigured on refs that were decoded rather than returned by a Manager.
+func (r *StoredObjectRef) SignedURL(ctx context.Context, expires time.Duration) (string, error) {
+	if r == nil || r.presign == nil {
+		return "", ErrProviderNotConfigured
+	}
+	return r.presign(ctx, expires)
+}
+
+// Ref returns the reference of key on the provider that serves it for ctx. It does not check the
+// object exists.
+func (m *Manager) Ref(ctx context.Context, key string) (*StoredObjectRef, error) {
+	if err := m.ensureProvider(ctx); err != nil {
+		return nil, err
+	}
+	return m.objectRef(m.providerFor(ctx, key), key), nil
+}
+
+func (m *Manager) objectRef(provider Uploader, key string) *StoredObjectRef {
+	ref := &StoredObjectRef{
+		Provider:    providerName(provider),
+		Key:         key,
+		ProviderKey: key,
+		presign: func(ctx context.Context, expires time.Duration) (string, error) {
+			return m.presignURL(ctx, provider, key, expires)
+		},
+	}
+
+	if describer, ok := provider.(ProviderDescriber); ok {
+		ref.ProviderKey = describer.ProviderKey(key)
+	}
+	if locator, ok := provider.(LocationDescriber); ok {
+		ref.Location = locator.StorageLocation()
+	}
+	if builder, ok := provider.(PublicURLBuilder); ok {
+		url, err := builder.PublicURL(key)
+		if err == nil {
+			ref.PublicURL = url
+		} else if !errors.Is(err, ErrNotImplemented) {
+			m.log(context.Background()).Error("build public URL failed", err, "key", key)
+		}
+	}
+	return ref
+}
@@ -0,0 +1,77 @@
+package uploader
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestUploadFileResultRef(t *testing.T) {
+	ctx := context.Background()
+	base := t.TempDir()
+	var presigned []string
+	manager := NewManager(
+		WithProvider(NewFSProvider(base, WithFSURLPrefix("/files"))),
+		WithEventHandler(func(_ context.Context, event Event) {
+			if e, ok := event.(*PresignIssued); ok {
+				presigned = append(presigned, e.Key)
+			}
+		}),
+	)
+
+	result, err := manager.UploadFileResult(ctx, "docs/a.txt", []byte("a"))
+	if err != nil {
+		t.Fatalf("UploadFileResult: %v", err)
+	}
+
+	ref := result.Ref
+	if ref == nil {
+		t.Fatalf("expected ref on the result")
+	}
+	if ref.Provider != "fs" || ref.Location != "" || ref.Key != "docs/a.txt" ||
+		ref.ProviderKey != "docs/a.txt" || ref.PublicURL != "/files/docs/a.txt" {
+		t.Fatalf("unexpected ref %#v", ref)
+	}
+
+	url, err := ref.SignedURL(ctx, time.Minute)
+	if err != nil || url != "/files/docs/a.txt" {
+		t.Fatalf("unexpected signed URL %q (%v)", url, err)
+	}
+	if len(presigned) != 1 || presigned[0] != "docs/a.txt" {
+		t.Fatalf("expected presign event for the ref, got %v", presigned)
+	}
+
+	encoded, err := json.Marshal(ref)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var decoded StoredObjectRef
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if decoded.PublicURL != ref.PublicURL {
+		t.Fatalf("expected ref to round trip, got %#v", decoded)
+	}
+	if _, err := decoded.SignedURL(ctx, time.Minute); !errors.Is(err, ErrProviderNotConfigured) {
+		t.Fatalf("expected decoded ref to have no signer, got %v", err)
+	}
+}
+
+func TestHandleFileRef(t *testing.T) {
+	provider := &AWSProvider{client: &fakeS3Client{}, bucket: "bucket", basePath: "media", logger: &DefaultLogger{}}
+	manager := NewManager(WithProvider(provider))
+
+	file := newTestFileHeader(t, "file", "photo.png", "image/png", createTestPNG(4, 4))
+	meta, err := manager.HandleFile(context.Background(), file, "images")
+	if err != nil {
+		t.Fatalf("HandleFile: %v", err)
+	}
+
+	ref := meta.Ref
+	if ref == nil || ref.Provider != "aws" || ref.Location != "bucket" || ref.Key != meta.Name ||
+		ref.ProviderKey != "media/"+meta.Name || ref.PublicURL != "" {
+		t.Fatalf("unexpected ref %#v", ref)
+	}
+}
@@ -0,0 +1,46 @@
+package uploader
+
+import (
+	"context"
+	"fmt"
+)
+
+// PartialUploadPolicy decides what happens to the objects a multi-step upload already stored
+// when a later step fails.
+type PartialUploadPolicy string
+
+const (
+	// PartialCleanup deletes the objects stored before the failure (the default).
+	PartialCleanup PartialUploadPolicy = "cleanup"
+	// PartialKeep leaves them in place, for callers that resume or repair uploads themselves.
+	PartialKeep PartialUploadPolicy = "keep"
+)
+
+// WithPartialUploadPolicy sets how HandleFile, HandleImageWithThumbnails and post-processing
+// treat a failure after the original was stored: the original, preserved source and
+// derivatives stored so far are deleted with PartialCleanup or kept with PartialKeep.
+// MultiProvider has its own WithMultiPartialPolicy for mirror failures.
+func WithPartialUploadPolicy(policy PartialUploadPolicy) Option {
+	return func(m *Manager) {
+		m.partialPolicy = policy
+	}
+}
+
+// rollbackPartial applies the partial upload policy to keys after a failed step. Deletes run
+// on a context that outlives cancellation, since cancellation is a common cause of the failure.
+func (m *Manager) rollbackPartial(ctx context.Context, keys ...string) {
+	if m.partialPolicy == PartialKeep {
+		m.log(ctx).Info("keeping partial upload", "keys", keys)
+		return
+	}
+	m.cleanupFiles(context.WithoutCancel(ctx), keys...)
+}
+
+func validatePartialPolicy(policy PartialUploadPolicy) error {
+	switch policy {
+	case PartialCleanup, PartialKeep:
+		return nil
+	default:
+		return fmt.Errorf("unknown partial upload policy %q", policy)
+	}
+}
@@ -0,0 +1,89 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type failingSizeProcessor struct {
+	ImageProcessor
+	fail string
+}
+
+func (p failingSizeProcessor) Generate(ctx context.Context, source []byte, size ThumbnailSize, contentType string) ([]byte, string, error) {
+	if size.Name == p.fail {
+		return nil, "", errors.New("encode failed")
+	}
+	return p.ImageProcessor.Generate(ctx, source, size, contentType)
+}
+
+func TestHandleImageWithThumbnailsPartialPolicy(t *testing.T) {
+	sizes := []ThumbnailSize{
+		{Name: "small", Width: 8, Height: 8, Fit: "cover"},
+		{Name: "large", Width: 16, Height: 16, Fit: "cover"},
+	}
+
+	for _, tc := range []struct {
+		policy PartialUploadPolicy
+		left   int
+	}{
+		{policy: PartialCleanup, left: 0},
+		{policy: PartialKeep, left: 2},
+	} {
+		t.Run(string(tc.policy), func(t *testing.T) {
+			provider := newMemoryProvider()
+			manager := NewManager(
+				WithProvider(provider),
+				WithImageProcessor(failingSizeProcessor{ImageProcessor: NewLocalImageProcessor(), fail: "large"}),
+				WithPartialUploadPolicy(tc.policy),
+			)
+
+			file := newTestFileHeader(t, "file", "photo.png", "image/png", createTestPNG(32, 32))
+			if _, err := manager.HandleImageWithThumbnails(context.Background(), file, "images", sizes); err == nil {
+				t.Fatalf("expected thumbnail failure")
+			}
+			if len(provider.files) != tc.left {
+				t.Fatalf("expected %d objects left, got %v", tc.left, provider.files)
+			}
+		})
+	}
+}
+
+func TestMultiProviderPartialPolicy(t *testing.T) {
+	// A regular file as the mirror base makes every local write fail.
+	base := filepath.Join(t.TempDir(), "mirror")
+	if err := os.WriteFile(base, nil, 0o600); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	for _, tc := range []struct {
+		policy  PartialUploadPolicy
+		deleted bool
+	}{
+		{policy: PartialCleanup, deleted: true},
+		{policy: PartialKeep, deleted: false},
+	} {
+		t.Run(string(tc.policy), func(t *testing.T) {
+			var deleted []string
+			store := &mockProvider{deleteFunc: func(ctx context.Context, path string) error {
+				deleted = append(deleted, path)
+				return nil
+			}}
+			provider := NewMultiProvider(NewFSProvider(base), store, WithMultiPartialPolicy(tc.policy))
+
+			if _, err := provider.UploadFile(context.Background(), "docs/a.txt", []byte("a")); err == nil {
+				t.Fatalf("expected mirror failure")
+			}
+			if got := len(deleted) == 1 && deleted[0] == "docs/a.txt"; got != tc.deleted {
+				t.Fatalf("expected object store delete=%v, got %v", tc.deleted, deleted)
+			}
+		})
+	}
+
+	if err := NewMultiProvider(NewFSProvider(base), &mockProvider{}, WithMultiPartialPolicy("maybe")).Validate(context.Background()); err == nil {
+		t.Fatalf("expected unknown policy to fail validation")
+	}
+}
@@ -0,0 +1,229 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"os/exec"
+	"path"
+	"strings"
+)
+
+// PostProcessor derives artifacts from a stored upload, such as thumbnails, previews or
+// attributes. Processors are registered per content type in a PostProcessorRegistry.
+type PostProcessor interface {
+	PostProcess(ctx context.Context, in *PostProcessInput) error
+}
+
+// PostProcessorFunc adapts a function to PostProcessor.
+type PostProcessorFunc func(ctx context.Context, in *PostProcessInput) error
+
+func (f PostProcessorFunc) PostProcess(ctx context.Context, in *PostProcessInput) error {
+	return f(ctx, in)
+}
+
+// PostProcessInput is the stored upload handed to each processor of a chain. Processors add
+// attributes to Meta and store derivatives with StoreDerivative.
+type PostProcessInput struct {
+	Meta *FileMeta
+
+	manager     *Manager
+	content     []byte
+	derivatives []string
+}
+
+// Content returns the stored bytes. Spooled uploads do not keep them in memory; they are
+// downloaded once and shared by the rest of the chain.
+func (in *PostProcessInput) Content(ctx context.Context) ([]byte, error) {
+	if in.content != nil {
+		return in.content, nil
+	}
+	content, err := in.manager.GetFile(ctx, in.Meta.Name)
+	if err != nil {
+		return nil, fmt.Errorf("post-process: load %s: %w", in.Meta.Name, err)
+	}
+	in.content = content
+	return content, nil
+}
+
+// StoreDerivative stores content under the manager's thumbnail key for variant ("<base>__<variant><ext>"
+// by default), where ext follows contentType, and returns its key. Derivatives are deleted if a
+// later processor fails.
+func (in *PostProcessInput) StoreDerivative(ctx context.Context, variant string, content []byte, contentType string) (string, error) {
+	m := in.manager
+	ext := extensionForContentType(mediaType(contentType))
+	if ext == "" {
+		ext = path.Ext(in.Meta.Name)
+	}
+	key := m.thumbnailKeyTemplate().derivativeKey(in.Meta.Name, variant, ext)
+
+	if _, err := m.putFile(ctx, key, content, WithContentType(contentType), WithStorageClass(m.storageClass)); err != nil {
+		return "", err
+	}
+	in.derivatives = append(in.derivatives, key)
+	return key, nil
+}
+
+// SetAttribute records an attribute on the upload's FileMeta.
+func (in *PostProcessInput) SetAttribute(key, value string) {
+	in.Meta.Attributes = mergeAttributes(in.Meta.Attributes, map[string]string{key: value})
+}
+
+// PostProcessorRegistry maps content-type patterns to processor chains.
+type PostProcessorRegistry struct {
+	entries []postProcessorEntry
+}
+
+type postProcessorEntry struct {
+	pattern    string
+	processors []PostProcessor
+}
+
+// NewPostProcessorRegistry creates an empty registry.
+func NewPostProcessorRegistry() *PostProcessorRegistry {
+	return &PostProcessorRegistry{}
+}
+
+// Register appends processors to the chain for pattern, which is an exact type such as
+// "application/pdf", a type wildcard such as "image/*", or "*/*" for every upload.
+func (r *PostProcessorRegistry) Register(pattern string, processors ...PostProcessor) *PostProcessorRegistry {
+	r.entries = append(r.entries, postProcessorEntry{pattern: pattern, processors: processors})
+	return r
+}
+
+// Match returns the processors for contentType. When several patterns match, their chains run
+// in registration order.
+func (r *PostProcessorRegistry) Match(contentType string) []PostProcessor {
+	if r == nil {
+		return nil
+	}
+	contentType = mediaType(contentType)
+
+	var chain []PostProcessor
+	for _, entry := range r.entries {
+		if entry.pattern == "*/*" || matchesContentType([]string{entry.pattern}, contentType) {
+			chain = append(chain, entry.processors...)
+		}
+	}
+	return chain
+}
+
+// WithPostProcessors runs the matching registry chain for every file stored by HandleFile,
+// before upload callbacks. A failing processor fails the upload; the file and the derivatives
+// stored so far are handled by the partial upload policy.
+func WithPostProcessors(registry *PostProcessorRegistry) Option {
+	return func(m *Manager) {
+		m.postProcessors = registry
+	}
+}
+
+func (m *Manager) runPostProcessors(ctx context.Context, meta *FileMeta) error {
+	chain := m.postProcessors.Match(meta.ContentType)
+	if len(chain) == 0 {
+		return nil
+	}
+
+	in := &PostProcessInput{Meta: meta, manager: m, content: meta.Content}
+	for _, processor := range chain {
+		err := ctx.Err()
+		if err == nil {
+			err = processor.PostProcess(ctx, in)
+		}
+		if err != nil {
+			m.rollbackPartial(ctx, append(in.derivatives, meta.Name, meta.Attributes["original_key"])...)
+			return err
+		}
+	}
+	return nil
+}
+
+// ThumbnailPostProcessor renders sizes with the manager's ImageProcessor and records each key
+// as the "thumbnail_<size>" attribute.
+func ThumbnailPostProcessor(sizes ...ThumbnailSize) PostProcessor {
+	return PostProcessorFunc(func(ctx context.Context, in *PostProcessInput) error {
+		if err := ValidateThumbnailSizes(sizes); err != nil {
+			return err
+		}
+
+		source, err := in.Content(ctx)
+		if err != nil {
+			return err
+		}
+
+		m := in.manager
+		processor := m.ensureImageProcessor()
+		for _, size := range sizes {
+			thumb, contentType, err := generateThumbnail(ctx, processor, in.Meta.Name, source, size, in.Meta.ContentType)
+			if err != nil {
+				return err
+			}
+
+			key := m.ThumbnailKey(in.Meta.Name, size.Name)
+			if _, err := m.putFile(ctx, key, thumb, WithContentType(contentType), WithStorageClass(m.storageClass)); err != nil {
+				return err
+			}
+			in.derivatives = append(in.derivatives, key)
+			in.SetAttribute("thumbnail_"+size.Name, key)
+			m.emitThumbnailGenerated(ctx, in.Meta.Name, key, size.Name, contentType, thumb)
+		}
+		return nil
+	})
+}
+
+// BlurHashPostProcessor encodes the image as a BlurHash placeholder with the given component
+// counts (1-9 each; 4x3 is typical) and records it as the "blurhash" attribute.
+func BlurHashPostProcessor(xComponents, yComponents int) PostProcessor {
+	return PostProcessorFunc(func(ctx context.Context, in *PostProcessInput) error {
+		source, err := in.Content(ctx)
+		if err != nil {
+			return err
+		}
+
+		img, _, err := image.Decode(bytes.NewReader(source))
+		if err != nil {
+			return fmt.Errorf("blurhash: decode %s: %w", in.Meta.Name, err)
+		}
+
+		hash, err := EncodeBlurHash(img, xComponents, yComponents)
+		if err != nil {
+			return err
+		}
+		in.SetAttribute("blurhash", hash)
+		return nil
+	})
+}
+
+// CommandPostProcessor runs an external program that reads the upload on stdin and writes a
+// derivative of contentType to stdout, such as a PDF preview with
+// `pdftoppm -png -singlefile -r 72 - -` or a video poster with
+// `ffmpeg -i pipe:0 -frames:v 1 -f image2 -c:v mjpeg pipe:1`. The derivative key is recorded as
+// the variant attribute.
+func CommandPostProcessor(variant, contentType, name string, args ...string) PostProcessor {
+	return PostProcessorFunc(func(ctx context.Context, in *PostProcessInput) error {
+		source, err := in.Content(ctx)
+		if err != nil {
+			return err
+		}
+
+		var stdout, stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, name, args...)
+		cmd.Stdin = bytes.NewReader(source)
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("post-process %s: %s: %w: %s", variant, name, err, strings.TrimSpace(stderr.String()))
+		}
+		if stdout.Len() == 0 {
+			return fmt.Errorf("post-process %s: %s produced no output", variant, name)
+		}
+
+		key, err := in.StoreDerivative(ctx, variant, stdout.Bytes(), contentType)
+		if err != nil {
+			return err
+		}
+		in.SetAttribute(variant, key)
+		return nil
+	})
+}
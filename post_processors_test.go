@@ -0,0 +1,115 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"strings"
+	"testing"
+)
+
+func TestPostProcessorsRunByContentType(t *testing.T) {
+	ctx := context.Background()
+	provider := newMemoryProvider()
+
+	var seen []string
+	record := func(name string) PostProcessor {
+		return PostProcessorFunc(func(ctx context.Context, in *PostProcessInput) error {
+			seen = append(seen, name)
+			return nil
+		})
+	}
+
+	registry := NewPostProcessorRegistry().
+		Register("*/*", record("all")).
+		Register("image/*",
+			ThumbnailPostProcessor(ThumbnailSize{Name: "small", Width: 8, Height: 8, Fit: "cover"}),
+			BlurHashPostProcessor(4, 3),
+			record("image"),
+		).
+		Register("application/pdf", record("pdf")).
+		Register("image/png", CommandPostProcessor("copy", "image/png", "cat"))
+
+	manager := NewManager(WithProvider(provider), WithPostProcessors(registry))
+
+	file := newTestFileHeader(t, "file", "photo.png", "image/png", createTestPNG(32, 32))
+	meta, err := manager.HandleFile(ctx, file, "uploads")
+	if err != nil {
+		t.Fatalf("HandleFile: %v", err)
+	}
+
+	if strings.Join(seen, ",") != "all,image" {
+		t.Fatalf("unexpected processors: %v", seen)
+	}
+
+	thumb := meta.Attributes["thumbnail_small"]
+	if _, ok := provider.files[thumb]; !ok || !strings.HasSuffix(thumb, "__small.png") {
+		t.Fatalf("expected stored thumbnail, got %q", thumb)
+	}
+	if hash := meta.Attributes["blurhash"]; len(hash) != 4+2*4*3 {
+		t.Fatalf("unexpected blurhash %q", hash)
+	}
+	copied := meta.Attributes["copy"]
+	if string(provider.files[copied]) != string(provider.files[meta.Name]) {
+		t.Fatalf("expected command output stored as %q", copied)
+	}
+}
+
+func TestPostProcessorFailureCleansUp(t *testing.T) {
+	ctx := context.Background()
+	provider := newMemoryProvider()
+
+	registry := NewPostProcessorRegistry().Register("image/*",
+		ThumbnailPostProcessor(ThumbnailSize{Name: "small", Width: 8, Height: 8, Fit: "cover"}),
+		PostProcessorFunc(func(ctx context.Context, in *PostProcessInput) error {
+			return errors.New("preview failed")
+		}),
+	)
+
+	callbackRan := false
+	manager := NewManager(WithProvider(provider), WithPostProcessors(registry),
+		WithOnUploadComplete(func(ctx context.Context, meta *FileMeta) error {
+			callbackRan = true
+			return nil
+		}))
+
+	file := newTestFileHeader(t, "file", "photo.png", "image/png", createTestPNG(32, 32))
+	if _, err := manager.HandleFile(ctx, file, "uploads"); err == nil || err.Error() != "preview failed" {
+		t.Fatalf("expected processor error, got %v", err)
+	}
+	if callbackRan {
+		t.Fatalf("expected callbacks to be skipped")
+	}
+	if len(provider.files) != 0 || len(provider.deleted) != 2 {
+		t.Fatalf("expected upload and thumbnail deleted, files=%v deleted=%v", provider.files, provider.deleted)
+	}
+}
+
+func TestEncodeBlurHash(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 100, 50))
+	for y := 0; y < 50; y++ {
+		for x := 0; x < 100; x++ {
+			img.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+
+	hash, err := EncodeBlurHash(img, 1, 1)
+	if err != nil {
+		t.Fatalf("EncodeBlurHash: %v", err)
+	}
+	// Size flag, no AC maximum, then the DC value 0xff0000.
+	if hash != "00"+encodeBase83String(0xff0000, 4) {
+		t.Fatalf("unexpected hash %q", hash)
+	}
+
+	if _, err := EncodeBlurHash(img, 0, 3); err == nil {
+		t.Fatalf("expected component range error")
+	}
+}
+
+func encodeBase83String(value, length int) string {
+	var sb strings.Builder
+	encodeBase83(&sb, value, length)
+	return sb.String()
+}
@@ -0,0 +1,160 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	gerrors "github.com/goliatone/go-errors"
+)
+
+// DeletePrefixOptions guards a DeletePrefix call.
+type DeletePrefixOptions struct {
+	// DryRun reports the keys that would be removed without deleting anything.
+	DryRun bool
+	// MaxObjects is the most objects the caller expects to remove. When more match, nothing is
+	// deleted and the call fails with PREFIX_DELETE_LIMIT. Zero means
+	// DefaultDeletePrefixMaxObjects; a negative value disables the guard.
+	MaxObjects int
+}
+
+// DeletePrefixResult describes a prefix deletion. Keys lists every matched object; Deleted is
+// how many of them were removed, which stays zero for dry runs.
+type DeletePrefixResult struct {
+	Prefix   string        `json:"prefix"`
+	Keys     []string      `json:"keys"`
+	Deleted  int           `json:"deleted"`
+	DryRun   bool          `json:"dry_run,omitempty"`
+	Provider string        `json:"provider,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+func (r *DeletePrefixResult) Operation() string { return "delete_prefix" }
+
+// BatchDeleter is implemented by providers that can remove many objects at once. It returns how
+// many keys were removed; keys that no longer exist are not an error.
+type BatchDeleter interface {
+	DeleteFiles(ctx context.Context, keys []string) (int, error)
+}
+
+// DeletePrefix removes every object whose key starts with prefix, such as a user's folder when
+// the account is closed. Pass a trailing slash ("users/42/") to keep "users/420/" out of scope.
+// Nothing is deleted when more objects match than opts.MaxObjects allows or when any of them is
+// under a legal hold or retention period. Providers implementing BatchDeleter remove the objects
+// in bulk (S3 DeleteObjects, file removal with empty directory cleanup on disk); others are
+// deleted one key at a time.
+func (m *Manager) DeletePrefix(ctx context.Context, prefix string, opts DeletePrefixOptions) (*DeletePrefixResult, error) {
+	if !opts.DryRun {
+		if err := m.ensureWritable(); err != nil {
+			return nil, err
+		}
+	}
+
+	prefix = strings.TrimPrefix(prefix, "/")
+	if strings.TrimSpace(prefix) == "" {
+		return nil, gerrors.NewValidation("delete prefix validation failed",
+			gerrors.FieldError{
+				Field:   "prefix",
+				Message: "prefix is required; deleting every object is not supported",
+			},
+		).WithCode(400).WithTextCode("PREFIX_REQUIRED")
+	}
+	if strings.Contains(prefix, "..") {
+		return nil, ErrInvalidPath
+	}
+
+	if err := m.ensureProvider(ctx); err != nil {
+		return nil, err
+	}
+
+	provider := m.currentProvider()
+	lister, ok := provider.(Lister)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+
+	started := time.Now()
+	objects, err := observeCall(ctx, m, "list", prefix, func() ([]ObjectInfo, error) {
+		return lister.List(ctx, prefix)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DeletePrefixResult{
+		Prefix:   prefix,
+		Keys:     make([]string, 0, len(objects)),
+		DryRun:   opts.DryRun,
+		Provider: providerName(provider),
+	}
+	for _, object := range objects {
+		result.Keys = append(result.Keys, object.Key)
+	}
+
+	limit := opts.MaxObjects
+	if limit == 0 {
+		limit = DefaultDeletePrefixMaxObjects
+	}
+	if limit > 0 && len(result.Keys) > limit {
+		return nil, m.correlateError(ctx, gerrors.New("prefix matches more objects than confirmed", gerrors.CategoryConflict).
+			WithCode(409).
+			WithTextCode("PREFIX_DELETE_LIMIT").
+			WithMetadata(map[string]any{
+				"prefix":      prefix,
+				"matched":     len(result.Keys),
+				"max_objects": limit,
+			}))
+	}
+
+	for _, key := range result.Keys {
+		if err := m.checkRetention(ctx, key); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.DryRun || len(result.Keys) == 0 {
+		result.Duration = time.Since(started)
+		return result, nil
+	}
+
+	result.Deleted, err = observeCall(ctx, m, "delete_prefix", prefix, func() (int, error) {
+		return m.deleteKeys(ctx, provider, result.Keys)
+	})
+
+	result.Duration = time.Since(started)
+	if err != nil {
+		return result, err
+	}
+
+	for _, key := range result.Keys {
+		m.forgetRetention(ctx, key)
+		m.usageCache.forget(key)
+	}
+
+	m.emitResult(ctx, result)
+	return result, nil
+}
+
+func (m *Manager) deleteKeys(ctx context.Context, provider Uploader, keys []string) (int, error) {
+	if batch, ok := provider.(BatchDeleter); ok {
+		return batch.DeleteFiles(ctx, keys)
+	}
+
+	deleted := 0
+	var errs []error
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return deleted, errors.Join(append(errs, err)...)
+		}
+		err := provider.DeleteFile(ctx, key)
+		switch {
+		case err == nil:
+			deleted++
+		case !errors.Is(err, ErrImageNotFound):
+			errs = append(errs, fmt.Errorf("delete %s: %w", key, err))
+		}
+	}
+	return deleted, errors.Join(errs...)
+}
@@ -0,0 +1,102 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	gerrors "github.com/goliatone/go-errors"
+)
+
+func assertTextCode(t *testing.T, err error, code string) {
+	t.Helper()
+	var uerr *gerrors.Error
+	if !errors.As(err, &uerr) || uerr.TextCode != code {
+		t.Fatalf("expected %s error, got %v", code, err)
+	}
+}
+
+func TestManagerDeletePrefix(t *testing.T) {
+	ctx := context.Background()
+	base := t.TempDir()
+	manager := NewManager(WithProvider(NewFSProvider(base)))
+
+	for _, key := range []string{"users/42/a.txt", "users/42/albums/b.txt", "users/420/c.txt"} {
+		if _, err := manager.UploadFile(ctx, key, []byte("x")); err != nil {
+			t.Fatalf("UploadFile: %v", err)
+		}
+	}
+
+	_, err := manager.DeletePrefix(ctx, " ", DeletePrefixOptions{})
+	assertTextCode(t, err, "PREFIX_REQUIRED")
+
+	_, err = manager.DeletePrefix(ctx, "users/", DeletePrefixOptions{MaxObjects: 2})
+	assertTextCode(t, err, "PREFIX_DELETE_LIMIT")
+
+	dry, err := manager.DeletePrefix(ctx, "users/42/", DeletePrefixOptions{DryRun: true})
+	if err != nil || len(dry.Keys) != 2 || dry.Deleted != 0 {
+		t.Fatalf("unexpected dry run %#v (%v)", dry, err)
+	}
+	if _, err := os.Stat(filepath.Join(base, "users", "42", "a.txt")); err != nil {
+		t.Fatalf("expected dry run to keep files: %v", err)
+	}
+
+	result, err := manager.DeletePrefix(ctx, "users/42/", DeletePrefixOptions{})
+	if err != nil || result.Deleted != 2 {
+		t.Fatalf("unexpected result %#v (%v)", result, err)
+	}
+	if _, err := os.Stat(filepath.Join(base, "users", "42")); !os.IsNotExist(err) {
+		t.Fatalf("expected emptied folder removed, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(base, "users", "420", "c.txt")); err != nil {
+		t.Fatalf("expected sibling folder kept: %v", err)
+	}
+}
+
+func TestManagerDeletePrefixHonoursRetention(t *testing.T) {
+	ctx := context.Background()
+	manager := NewManager(WithProvider(NewFSProvider(t.TempDir())))
+
+	for _, key := range []string{"users/7/a.txt", "users/7/b.txt"} {
+		if _, err := manager.UploadFile(ctx, key, []byte("x")); err != nil {
+			t.Fatalf("UploadFile: %v", err)
+		}
+	}
+	if err := manager.SetHold(ctx, "users/7/b.txt", true); err != nil {
+		t.Fatalf("SetHold: %v", err)
+	}
+
+	_, err := manager.DeletePrefix(ctx, "users/7/", DeletePrefixOptions{})
+	assertRetained(t, err)
+	if _, err := manager.GetFile(ctx, "users/7/a.txt"); err != nil {
+		t.Fatalf("expected nothing deleted while an object is held: %v", err)
+	}
+}
+
+func TestAWSProviderDeleteFilesBatches(t *testing.T) {
+	client := &fakeS3Client{deleteObjectsErrors: []types.Error{
+		{Key: aws.String("media/k3"), Code: aws.String("AccessDenied"), Message: aws.String("denied")},
+	}}
+	provider := &AWSProvider{client: client, bucket: "bucket", basePath: "media", logger: &DefaultLogger{}}
+
+	keys := make([]string, s3DeleteBatchSize+1)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("k%d", i)
+	}
+
+	deleted, err := provider.DeleteFiles(context.Background(), keys)
+	if err == nil || deleted != len(keys)-1 {
+		t.Fatalf("expected one failed key reported, got %d (%v)", deleted, err)
+	}
+	if len(client.deleteObjectsInputs) != 2 || len(client.deleteObjectsInputs[1].Delete.Objects) != 1 {
+		t.Fatalf("expected keys split into two requests, got %d", len(client.deleteObjectsInputs))
+	}
+	if key := aws.ToString(client.deleteObjectsInputs[0].Delete.Objects[0].Key); key != "media/k0" {
+		t.Fatalf("expected base path applied, got %q", key)
+	}
+}
@@ -0,0 +1,118 @@
+package uploader
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PresignFailure records a path GetPresignedURLs could not sign.
+type PresignFailure struct {
+	Path string
+	Err  error
+}
+
+// BulkPresignError lists the paths GetPresignedURLs could not sign. The URLs of the other paths
+// are still returned alongside it.
+type BulkPresignError struct {
+	Failures []PresignFailure
+}
+
+func (e *BulkPresignError) Error() string {
+	parts := make([]string, 0, len(e.Failures))
+	for _, failure := range e.Failures {
+		parts = append(parts, fmt.Sprintf("%s: %v", failure.Path, failure.Err))
+	}
+	return fmt.Sprintf("presign failed for %d paths: %s", len(e.Failures), strings.Join(parts, "; "))
+}
+
+// Unwrap exposes the individual errors to errors.Is and errors.As.
+func (e *BulkPresignError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failures))
+	for _, failure := range e.Failures {
+		errs = append(errs, failure.Err)
+	}
+	return errs
+}
+
+// WithPresignWorkers bounds how many URLs GetPresignedURLs signs concurrently. Defaults to
+// DefaultPresignWorkers.
+func WithPresignWorkers(workers int) Option {
+	return func(m *Manager) {
+		m.presignWorkers = workers
+	}
+}
+
+// GetPresignedURLs presigns every path for expires, e.g. for a gallery page, and returns a map
+// of path to URL. Paths that fail are left out of the map and reported together in a
+// *BulkPresignError, so one missing object does not blank the whole page. Duplicate paths are
+// signed once.
+func (m *Manager) GetPresignedURLs(ctx context.Context, paths []string, expires time.Duration) (map[string]string, error) {
+	if err := m.ensureProvider(ctx); err != nil {
+		return nil, err
+	}
+
+	unique := make([]string, 0, len(paths))
+	seen := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		if !seen[path] {
+			seen[path] = true
+			unique = append(unique, path)
+		}
+	}
+
+	workers := m.presignWorkers
+	if workers <= 0 {
+		workers = DefaultPresignWorkers
+	}
+	if workers > len(unique) {
+		workers = len(unique)
+	}
+
+	urls := make(map[string]string, len(unique))
+	var failures []PresignFailure
+	queue := make(chan string)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range queue {
+				url, err := m.GetPresignedURL(ctx, path, expires)
+
+				mu.Lock()
+				if err != nil {
+					failures = append(failures, PresignFailure{Path: path, Err: err})
+				} else {
+					urls[path] = url
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, path := range unique {
+		if ctx.Err() != nil {
+			break
+		}
+		queue <- path
+	}
+	close(queue)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return urls, err
+	}
+	if len(failures) > 0 {
+		sort.Slice(failures, func(i, j int) bool {
+			return failures[i].Path < failures[j].Path
+		})
+		return urls, &BulkPresignError{Failures: failures}
+	}
+	return urls, nil
+}
@@ -0,0 +1,43 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestManagerGetPresignedURLs(t *testing.T) {
+	var active, peak atomic.Int32
+	provider := &mockProvider{getPresignedFunc: func(ctx context.Context, path string, expires time.Duration) (string, error) {
+		if n := active.Add(1); n > peak.Load() {
+			peak.Store(n)
+		}
+		defer active.Add(-1)
+		time.Sleep(5 * time.Millisecond)
+
+		if path == "missing.png" {
+			return "", ErrImageNotFound
+		}
+		return "https://cdn.example.com/" + path, nil
+	}}
+	manager := NewManager(WithProvider(provider), WithPresignWorkers(2))
+
+	paths := []string{"a.png", "b.png", "missing.png", "c.png", "a.png", "d.png"}
+	urls, err := manager.GetPresignedURLs(context.Background(), paths, time.Minute)
+
+	var bulkErr *BulkPresignError
+	if !errors.As(err, &bulkErr) || len(bulkErr.Failures) != 1 || bulkErr.Failures[0].Path != "missing.png" {
+		t.Fatalf("expected the missing path reported, got %v", err)
+	}
+	if !errors.Is(err, ErrImageNotFound) {
+		t.Fatalf("expected the failure cause to unwrap, got %v", err)
+	}
+	if len(urls) != 4 || urls["c.png"] != "https://cdn.example.com/c.png" {
+		t.Fatalf("expected the other paths signed once each, got %v", urls)
+	}
+	if peak.Load() > 2 {
+		t.Fatalf("expected at most 2 concurrent signatures, saw %d", peak.Load())
+	}
+}
@@ -0,0 +1,99 @@
+package uploader
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PresignedUploadKit bundles everything a browser client needs to upload directly to storage
+// and later confirm the upload with the API.
+type PresignedUploadKit struct {
+	Key               string         `json:"key"`
+	Post              *PresignedPost `json:"post"`
+	AllowedMimeTypes  []string       `json:"allowed_mime_types"`
+	MaxFileSize       int64          `json:"max_file_size"`
+	ContentType       string         `json:"content_type"`
+	ConfirmationToken string         `json:"confirmation_token"`
+	ExpiresAt         time.Time      `json:"expires_at"`
+}
+
+// CreatePresignedUploadKit creates a presigned post and signs a confirmation token bound to the
+// key and upload constraints. The token must be echoed back in PresignedUploadResult.
+func (m *Manager) CreatePresignedUploadKit(ctx context.Context, key string, opts ...UploadOption) (*PresignedUploadKit, error) {
+	if err := m.ensureWritable(); err != nil {
+		return nil, err
+	}
+
+	if len(m.confirmationSecret) == 0 {
+		return nil, ErrConfirmationSecretNotConfigured
+	}
+
+	post, err := m.CreatePresignedPost(ctx, key, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := &Metadata{}
+	for _, opt := range opts {
+		opt(meta)
+	}
+
+	expiresAt := post.Expiry.Add(m.presignPolicy.ConfirmationGrace)
+	maxSize := m.validator.MaxFileSize()
+
+	return &PresignedUploadKit{
+		Key:               key,
+		Post:              post,
+		AllowedMimeTypes:  m.validator.AllowedMimeTypes(),
+		MaxFileSize:       maxSize,
+		ContentType:       meta.ContentType,
+		ConfirmationToken: m.signConfirmationToken(key, meta.ContentType, maxSize, expiresAt),
+		ExpiresAt:         expiresAt,
+	}, nil
+}
+
+func (m *Manager) signConfirmationToken(key, contentType string, maxSize int64, expiresAt time.Time) string {
+	expiry := strconv.FormatInt(expiresAt.Unix(), 10)
+	sig := confirmationSignature(m.confirmationSecret, key, contentType, maxSize, expiry)
+	return expiry + "." + sig
+}
+
+func (m *Manager) verifyConfirmationToken(result *PresignedUploadResult) error {
+	if len(m.confirmationSecret) == 0 {
+		return nil
+	}
+
+	expiry, sig, ok := strings.Cut(result.ConfirmationToken, ".")
+	if !ok || expiry == "" || sig == "" {
+		return ErrInvalidConfirmationToken
+	}
+
+	expiresAt, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil {
+		return ErrInvalidConfirmationToken
+	}
+
+	expected := confirmationSignature(m.confirmationSecret, result.Key, result.ContentType, m.validator.MaxFileSize(), expiry)
+	if !hmac.Equal([]byte(expected), []byte(sig)) {
+		return ErrInvalidConfirmationToken
+	}
+
+	if m.now().After(time.Unix(expiresAt, 0)) {
+		return ErrConfirmationTokenExpired
+	}
+
+	return nil
+}
+
+func confirmationSignature(secret []byte, key, contentType string, maxSize int64, expiry string) string {
+	payload := fmt.Sprintf("%s\n%s\n%d\n%s", key, contentType, maxSize, expiry)
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(payload))
+	return hex.EncodeToString(h.Sum(nil))
+}
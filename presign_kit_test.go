@@ -0,0 +1,84 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestManagerCreatePresignedUploadKit(t *testing.T) {
+	ctx := context.Background()
+	provider := &stubPresignProvider{}
+
+	manager := NewManager(WithConfirmationSecret([]byte("secret")))
+	WithProvider(provider)(manager)
+
+	kit, err := manager.CreatePresignedUploadKit(ctx, "uploads/file.jpg", WithContentType("image/jpeg"))
+	if err != nil {
+		t.Fatalf("CreatePresignedUploadKit returned error: %v", err)
+	}
+
+	if kit.Post == nil || kit.ConfirmationToken == "" {
+		t.Fatalf("expected post and confirmation token to be populated")
+	}
+
+	if kit.MaxFileSize != manager.validator.MaxFileSize() {
+		t.Fatalf("expected max file size %d, got %d", manager.validator.MaxFileSize(), kit.MaxFileSize)
+	}
+
+	if len(kit.AllowedMimeTypes) == 0 {
+		t.Fatalf("expected allowed mime types")
+	}
+
+	_, err = manager.ConfirmPresignedUpload(ctx, &PresignedUploadResult{
+		Key:               "uploads/file.jpg",
+		Size:              1024,
+		ContentType:       "image/jpeg",
+		ConfirmationToken: kit.ConfirmationToken,
+	})
+	if err != nil {
+		t.Fatalf("ConfirmPresignedUpload returned error: %v", err)
+	}
+}
+
+func TestManagerConfirmPresignedUploadRejectsSpoofedToken(t *testing.T) {
+	ctx := context.Background()
+	provider := &stubPresignProvider{}
+
+	manager := NewManager(WithConfirmationSecret([]byte("secret")))
+	WithProvider(provider)(manager)
+
+	kit, err := manager.CreatePresignedUploadKit(ctx, "uploads/file.jpg", WithContentType("image/jpeg"))
+	if err != nil {
+		t.Fatalf("CreatePresignedUploadKit returned error: %v", err)
+	}
+
+	_, err = manager.ConfirmPresignedUpload(ctx, &PresignedUploadResult{
+		Key:               "uploads/other.jpg",
+		Size:              1024,
+		ContentType:       "image/jpeg",
+		ConfirmationToken: kit.ConfirmationToken,
+	})
+	if !errors.Is(err, ErrInvalidConfirmationToken) {
+		t.Fatalf("expected ErrInvalidConfirmationToken for mismatched key, got %v", err)
+	}
+
+	_, err = manager.ConfirmPresignedUpload(ctx, &PresignedUploadResult{
+		Key:         "uploads/file.jpg",
+		Size:        1024,
+		ContentType: "image/jpeg",
+	})
+	if !errors.Is(err, ErrInvalidConfirmationToken) {
+		t.Fatalf("expected ErrInvalidConfirmationToken for missing token, got %v", err)
+	}
+}
+
+func TestManagerCreatePresignedUploadKitRequiresSecret(t *testing.T) {
+	manager := NewManager()
+	WithProvider(&stubPresignProvider{})(manager)
+
+	_, err := manager.CreatePresignedUploadKit(context.Background(), "uploads/file.jpg", WithContentType("image/jpeg"))
+	if !errors.Is(err, ErrConfirmationSecretNotConfigured) {
+		t.Fatalf("expected ErrConfirmationSecretNotConfigured, got %v", err)
+	}
+}
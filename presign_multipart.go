@@ -0,0 +1,242 @@
+package uploader
+
+import (
+	"context"
+	"time"
+
+	gerrors "github.com/goliatone/go-errors"
+)
+
+// MaxPresignedChunkParts mirrors the S3 limit on parts per multipart upload.
+//
+// Deprecated: use MaxChunkParts or ChunkLimits.
+const MaxPresignedChunkParts = MaxChunkParts
+
+// PresignedChunkUploader is implemented by providers that can hand out presigned requests for
+// each part of a chunked session so browsers upload parts straight to storage.
+type PresignedChunkUploader interface {
+	ChunkedUploader
+	PresignChunkPart(ctx context.Context, session *ChunkSession, index int, ttl time.Duration) (*PresignedRequest, error)
+	PresignCompleteChunked(ctx context.Context, session *ChunkSession, ttl time.Duration) (*PresignedRequest, error)
+}
+
+// PresignedRequest describes a signed HTTP request the client can perform without credentials.
+type PresignedRequest struct {
+	URL     string            `json:"url"`
+	Method  string            `json:"method"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Expiry  time.Time         `json:"expiry"`
+}
+
+// PresignedChunkPart is the presigned request for a single part of a chunked session.
+type PresignedChunkPart struct {
+	Index int `json:"index"`
+	PresignedRequest
+}
+
+// PresignedChunkedUpload bundles the session and presigned requests required to finish a
+// chunked upload from the browser.
+type PresignedChunkedUpload struct {
+	SessionID string               `json:"session_id"`
+	Key       string               `json:"key"`
+	TotalSize int64                `json:"total_size"`
+	PartSize  int64                `json:"part_size"`
+	Parts     []PresignedChunkPart `json:"parts"`
+	Complete  *PresignedRequest    `json:"complete"`
+	Expiry    time.Time            `json:"expiry"`
+}
+
+// InitiatePresignedChunked starts a chunked session and presigns every part plus the completion
+// request so the payload never flows through the application server.
+func (m *Manager) InitiatePresignedChunked(ctx context.Context, key string, totalSize int64, opts ...UploadOption) (*PresignedChunkedUpload, error) {
+	if err := m.ensureWritable(); err != nil {
+		return nil, err
+	}
+
+	if err := m.validateKey(key); err != nil {
+		return nil, err
+	}
+
+	if err := m.ensureProvider(ctx); err != nil {
+		return nil, err
+	}
+
+	meta := &Metadata{}
+	for _, opt := range opts {
+		opt(meta)
+	}
+
+	presigner, err := m.presignedChunkProvider(withRouteInfo(ctx, meta.ContentType, totalSize), key)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl, err := m.presignedChunkTTL(meta.TTL)
+	if err != nil {
+		return nil, err
+	}
+
+	if totalSize > 0 && totalSize > m.validator.MaxFileSize() {
+		return nil, gerrors.NewValidation("presigned chunked upload validation failed",
+			gerrors.FieldError{
+				Field:   "total_size",
+				Message: "file size exceeds maximum allowed",
+				Value:   totalSize,
+			},
+		).WithCode(400).WithTextCode("FILE_TOO_LARGE")
+	}
+
+	// InitiateChunked enforces the part count and size limits before any request is presigned.
+	partCount := chunkPartCount(totalSize, m.chunkPartSize)
+	session, err := m.InitiateChunked(ctx, key, totalSize, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &PresignedChunkedUpload{
+		SessionID: session.ID,
+		Key:       session.Key,
+		TotalSize: session.TotalSize,
+		PartSize:  session.PartSize,
+		Parts:     make([]PresignedChunkPart, 0, partCount),
+	}
+
+	for idx := 0; idx < partCount; idx++ {
+		req, err := observeCall(ctx, m, "presign_chunk_part", session.Key, func() (*PresignedRequest, error) {
+			return presigner.PresignChunkPart(ctx, session, idx, ttl)
+		})
+		if err != nil {
+			m.abortPresignedChunked(ctx, presigner, session)
+			return nil, err
+		}
+		out.Parts = append(out.Parts, PresignedChunkPart{Index: idx, PresignedRequest: *req})
+		out.Expiry = req.Expiry
+	}
+
+	complete, err := observeCall(ctx, m, "presign_complete_chunked", session.Key, func() (*PresignedRequest, error) {
+		return presigner.PresignCompleteChunked(ctx, session, ttl)
+	})
+	if err != nil {
+		m.abortPresignedChunked(ctx, presigner, session)
+		return nil, err
+	}
+	out.Complete = complete
+
+	m.emitEvent(ctx, &PresignIssued{Key: out.Key, Kind: PresignKindChunked, ExpiresAt: out.Expiry})
+	return out, nil
+}
+
+// CompletePresignedChunked records the parts reported by the client (index and ETag) and
+// finalizes the session server side. Use it when the client does not call the presigned
+// completion request directly.
+func (m *Manager) CompletePresignedChunked(ctx context.Context, sessionID string, parts []ChunkPart) (*FileMeta, error) {
+	if err := m.ensureWritable(); err != nil {
+		return nil, err
+	}
+
+	if len(parts) == 0 {
+		return nil, gerrors.NewValidation("presigned chunked completion failed",
+			gerrors.FieldError{
+				Field:   "parts",
+				Message: "at least one part is required",
+			},
+		)
+	}
+
+	if _, err := m.getChunkSession(sessionID); err != nil {
+		return nil, err
+	}
+
+	for _, part := range parts {
+		if _, err := m.ensureChunkStore().AddPart(sessionID, part); err != nil {
+			return nil, err
+		}
+	}
+
+	return m.CompleteChunked(ctx, sessionID)
+}
+
+// PresignChunkParts issues fresh presigned requests for parts of an active session, for clients
+// whose original URLs expired or that resume an upload started elsewhere. Without indexes it
+// presigns every part not yet recorded on the session.
+func (m *Manager) PresignChunkParts(ctx context.Context, sessionID string, ttl time.Duration, indexes ...int) ([]PresignedChunkPart, error) {
+	if err := m.ensureWritable(); err != nil {
+		return nil, err
+	}
+
+	session, err := m.getChunkSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.State != ChunkSessionStateActive {
+		return nil, ErrChunkSessionClosed
+	}
+
+	ttl, err = m.presignedChunkTTL(ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	presigner, err := m.presignedChunkProvider(sessionRouteContext(ctx, session), session.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	partCount := chunkPartCount(session.TotalSize, session.PartSize)
+	if len(indexes) == 0 {
+		for idx := 0; idx < partCount; idx++ {
+			if _, ok := session.UploadedParts[idx]; !ok {
+				indexes = append(indexes, idx)
+			}
+		}
+	}
+
+	parts := make([]PresignedChunkPart, 0, len(indexes))
+	for _, idx := range indexes {
+		if idx < 0 || (partCount > 0 && idx >= partCount) {
+			return nil, ErrChunkPartOutOfRange
+		}
+
+		req, err := observeCall(ctx, m, "presign_chunk_part", session.Key, func() (*PresignedRequest, error) {
+			return presigner.PresignChunkPart(ctx, session, idx, ttl)
+		})
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, PresignedChunkPart{Index: idx, PresignedRequest: *req})
+	}
+
+	if len(parts) > 0 {
+		m.emitEvent(ctx, &PresignIssued{Key: session.Key, Kind: PresignKindChunked, ExpiresAt: parts[len(parts)-1].Expiry})
+	}
+	return parts, nil
+}
+
+func (m *Manager) presignedChunkTTL(ttl time.Duration) (time.Duration, error) {
+	return m.presignPolicy.lifetime(ttl, m.presignPolicy.ChunkTTL, "presigned chunked upload validation failed")
+}
+
+func (m *Manager) presignedChunkProvider(ctx context.Context, key string) (PresignedChunkUploader, error) {
+	if presigner, ok := m.providerFor(ctx, key).(PresignedChunkUploader); ok {
+		return presigner, nil
+	}
+	return nil, ErrNotImplemented
+}
+
+func (m *Manager) abortPresignedChunked(ctx context.Context, provider ChunkedUploader, session *ChunkSession) {
+	err := m.observeErr(ctx, "abort_chunked", session.Key, func() error {
+		return provider.AbortChunked(ctx, session)
+	})
+	if err != nil {
+		m.log(ctx).Error("abort presigned chunked upload failed", err, "session", session.ID)
+	}
+	m.ensureChunkStore().Delete(session.ID)
+}
+
+func chunkPartCount(totalSize, partSize int64) int {
+	if totalSize <= 0 || partSize <= 0 {
+		return 0
+	}
+	return int((totalSize + partSize - 1) / partSize)
+}
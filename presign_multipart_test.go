@@ -0,0 +1,143 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestManagerInitiatePresignedChunked(t *testing.T) {
+	ctx := context.Background()
+	provider := &stubPresignedChunkUploader{mockChunkUploader: newMockChunkUploader()}
+
+	manager := NewManager(WithChunkPartSize(4))
+	WithProvider(provider)(manager)
+
+	upload, err := manager.InitiatePresignedChunked(ctx, "videos/raw.mov", 10, WithContentType("image/png"))
+	if err != nil {
+		t.Fatalf("InitiatePresignedChunked returned error: %v", err)
+	}
+
+	if len(upload.Parts) != 3 {
+		t.Fatalf("expected 3 presigned parts, got %d", len(upload.Parts))
+	}
+
+	for idx, part := range upload.Parts {
+		if part.Index != idx || part.URL != fmt.Sprintf("https://example.com/part/%d", idx) {
+			t.Fatalf("unexpected presigned part %d: %#v", idx, part)
+		}
+	}
+
+	if upload.Complete == nil || upload.Complete.URL == "" {
+		t.Fatalf("expected presigned completion request")
+	}
+
+	parts := []ChunkPart{
+		{Index: 0, Size: 4, ETag: "etag-0"},
+		{Index: 1, Size: 4, ETag: "etag-1"},
+		{Index: 2, Size: 2, ETag: "etag-2"},
+	}
+
+	meta, err := manager.CompletePresignedChunked(ctx, upload.SessionID, parts)
+	if err != nil {
+		t.Fatalf("CompletePresignedChunked returned error: %v", err)
+	}
+
+	if meta.Name != "videos/raw.mov" {
+		t.Fatalf("unexpected meta name: %s", meta.Name)
+	}
+
+	if _, ok := manager.ensureChunkStore().Get(upload.SessionID); ok {
+		t.Fatalf("expected session to be removed after completion")
+	}
+}
+
+func TestManagerInitiatePresignedChunkedRequiresProviderSupport(t *testing.T) {
+	manager := NewManager()
+	WithProvider(newMockChunkUploader())(manager)
+
+	_, err := manager.InitiatePresignedChunked(context.Background(), "videos/raw.mov", 10)
+	if !errors.Is(err, ErrNotImplemented) {
+		t.Fatalf("expected ErrNotImplemented, got %v", err)
+	}
+}
+
+func TestManagerInitiatePresignedChunkedAbortsOnPresignFailure(t *testing.T) {
+	ctx := context.Background()
+	provider := &stubPresignedChunkUploader{
+		mockChunkUploader: newMockChunkUploader(),
+		partErr:           errors.New("presign failed"),
+	}
+
+	manager := NewManager(WithChunkPartSize(4))
+	WithProvider(provider)(manager)
+
+	if _, err := manager.InitiatePresignedChunked(ctx, "videos/raw.mov", 10); err == nil {
+		t.Fatalf("expected presign failure to bubble up")
+	}
+
+	if len(provider.aborted) != 1 {
+		t.Fatalf("expected session to be aborted after presign failure")
+	}
+}
+
+func TestManagerPresignChunkParts(t *testing.T) {
+	ctx := context.Background()
+	provider := &stubPresignedChunkUploader{mockChunkUploader: newMockChunkUploader()}
+
+	manager := NewManager(WithChunkPartSize(4))
+	WithProvider(provider)(manager)
+
+	upload, err := manager.InitiatePresignedChunked(ctx, "videos/raw.mov", 10)
+	if err != nil {
+		t.Fatalf("InitiatePresignedChunked returned error: %v", err)
+	}
+	if _, err := manager.ensureChunkStore().AddPart(upload.SessionID, ChunkPart{Index: 1, Size: 4, ETag: "etag-1"}); err != nil {
+		t.Fatalf("AddPart returned error: %v", err)
+	}
+
+	parts, err := manager.PresignChunkParts(ctx, upload.SessionID, time.Minute)
+	if err != nil {
+		t.Fatalf("PresignChunkParts returned error: %v", err)
+	}
+	if len(parts) != 2 || parts[0].Index != 0 || parts[1].Index != 2 {
+		t.Fatalf("expected missing parts 0 and 2, got %#v", parts)
+	}
+
+	if _, err := manager.PresignChunkParts(ctx, upload.SessionID, time.Minute, 3); !errors.Is(err, ErrChunkPartOutOfRange) {
+		t.Fatalf("expected ErrChunkPartOutOfRange, got %v", err)
+	}
+
+	if err := manager.AbortChunked(ctx, upload.SessionID); err != nil {
+		t.Fatalf("AbortChunked returned error: %v", err)
+	}
+	if _, err := manager.PresignChunkParts(ctx, upload.SessionID, time.Minute, 0); err == nil {
+		t.Fatalf("expected presigning an aborted session to fail")
+	}
+}
+
+type stubPresignedChunkUploader struct {
+	*mockChunkUploader
+	partErr error
+}
+
+func (s *stubPresignedChunkUploader) PresignChunkPart(_ context.Context, _ *ChunkSession, index int, ttl time.Duration) (*PresignedRequest, error) {
+	if s.partErr != nil {
+		return nil, s.partErr
+	}
+	return &PresignedRequest{
+		URL:    fmt.Sprintf("https://example.com/part/%d", index),
+		Method: "PUT",
+		Expiry: time.Now().Add(ttl),
+	}, nil
+}
+
+func (s *stubPresignedChunkUploader) PresignCompleteChunked(_ context.Context, session *ChunkSession, ttl time.Duration) (*PresignedRequest, error) {
+	return &PresignedRequest{
+		URL:    "https://example.com/complete/" + session.ID,
+		Method: "POST",
+		Expiry: time.Now().Add(ttl),
+	}, nil
+}
@@ -0,0 +1,124 @@
+package uploader
+
+import (
+	"time"
+
+	gerrors "github.com/goliatone/go-errors"
+)
+
+// PresignPolicy bounds the lifetimes of the presigned URLs, posts and chunk requests a Manager
+// issues. Zero durations take the DefaultPresignPolicy value, except MinTTL and ClockSkew.
+type PresignPolicy struct {
+	// MinTTL is the shortest lifetime signed; shorter requests are raised to it.
+	MinTTL time.Duration
+	// MaxTTL is the longest lifetime a caller may request. Longer requests fail validation
+	// instead of being shortened, so clients learn the URL will not last as long as asked.
+	MaxTTL time.Duration
+	// PostTTL is the lifetime of presigned posts created without Metadata.TTL.
+	PostTTL time.Duration
+	// ChunkTTL is the lifetime of presigned chunk part and completion requests created without
+	// a TTL.
+	ChunkTTL time.Duration
+	// URLTTL is the lifetime of download URLs requested with a zero expiry and of those the
+	// manager issues itself, such as ConfirmPresignedUpload's.
+	URLTTL time.Duration
+	// ConfirmationGrace extends confirmation tokens past the presigned post expiry so uploads
+	// that finish near the deadline can still be confirmed.
+	ConfirmationGrace time.Duration
+	// ClockSkew is added to every signed lifetime, so a storage service whose clock runs ahead of
+	// this host's does not reject URLs before their requested lifetime is up. Reported expiries
+	// include it.
+	ClockSkew time.Duration
+}
+
+// WithPresignPolicy replaces DefaultPresignPolicy. An invalid policy is logged and the default
+// is kept.
+func WithPresignPolicy(policy PresignPolicy) Option {
+	return func(m *Manager) {
+		policy = policy.withDefaults()
+		if err := policy.Validate(); err != nil {
+			m.logger.Error("ignoring presign policy", err)
+			return
+		}
+		m.presignPolicy = policy
+	}
+}
+
+// Validate reports durations that are negative, a MaxTTL below MinTTL, and default lifetimes
+// outside [MinTTL, MaxTTL]. Zero durations are checked as their DefaultPresignPolicy value.
+func (p PresignPolicy) Validate() error {
+	p = p.withDefaults()
+
+	var fields []gerrors.FieldError
+	for _, d := range []struct {
+		field string
+		value time.Duration
+	}{
+		{"min_ttl", p.MinTTL},
+		{"confirmation_grace", p.ConfirmationGrace},
+		{"clock_skew", p.ClockSkew},
+	} {
+		if d.value < 0 {
+			fields = append(fields, gerrors.FieldError{Field: d.field, Message: "must not be negative", Value: d.value})
+		}
+	}
+
+	if p.MaxTTL < p.MinTTL {
+		fields = append(fields, gerrors.FieldError{Field: "max_ttl", Message: "must not be below min_ttl", Value: p.MaxTTL})
+	}
+	for _, d := range []struct {
+		field string
+		value time.Duration
+	}{
+		{"post_ttl", p.PostTTL},
+		{"chunk_ttl", p.ChunkTTL},
+		{"url_ttl", p.URLTTL},
+	} {
+		if d.value < p.MinTTL || d.value > p.MaxTTL {
+			fields = append(fields, gerrors.FieldError{Field: d.field, Message: "must be between min_ttl and max_ttl", Value: d.value})
+		}
+	}
+
+	if len(fields) > 0 {
+		return gerrors.NewValidation("presign policy invalid", fields...)
+	}
+	return nil
+}
+
+func (p PresignPolicy) withDefaults() PresignPolicy {
+	if p.MaxTTL == 0 {
+		p.MaxTTL = DefaultPresignPolicy.MaxTTL
+	}
+	if p.PostTTL == 0 {
+		p.PostTTL = DefaultPresignPolicy.PostTTL
+	}
+	if p.ChunkTTL == 0 {
+		p.ChunkTTL = DefaultPresignPolicy.ChunkTTL
+	}
+	if p.URLTTL == 0 {
+		p.URLTTL = DefaultPresignPolicy.URLTTL
+	}
+	if p.ConfirmationGrace == 0 {
+		p.ConfirmationGrace = DefaultPresignPolicy.ConfirmationGrace
+	}
+	return p
+}
+
+// lifetime resolves a requested ttl to the lifetime to sign: zero takes fallback, short
+// requests are raised to MinTTL and ClockSkew is added. Requests above MaxTTL fail with a
+// validation error titled message.
+func (p PresignPolicy) lifetime(ttl, fallback time.Duration, message string) (time.Duration, error) {
+	if ttl <= 0 {
+		ttl = fallback
+	}
+	if ttl > p.MaxTTL {
+		return 0, gerrors.NewValidation(message,
+			gerrors.FieldError{
+				Field:   "ttl",
+				Message: "requested ttl exceeds maximum",
+				Value:   ttl,
+			},
+		)
+	}
+	return max(ttl, p.MinTTL) + p.ClockSkew, nil
+}
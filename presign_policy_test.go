@@ -0,0 +1,93 @@
+package uploader
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	gerrors "github.com/goliatone/go-errors"
+)
+
+type expiryRecordingProvider struct {
+	*memoryProvider
+	expires []time.Duration
+}
+
+func (p *expiryRecordingProvider) GetPresignedURL(ctx context.Context, path string, expires time.Duration) (string, error) {
+	p.expires = append(p.expires, expires)
+	return p.memoryProvider.GetPresignedURL(ctx, path, expires)
+}
+
+func TestPresignPolicyValidate(t *testing.T) {
+	if err := DefaultPresignPolicy.Validate(); err != nil {
+		t.Fatalf("expected the default policy to be valid: %v", err)
+	}
+	if err := (PresignPolicy{URLTTL: time.Minute}).Validate(); err != nil {
+		t.Fatalf("expected zero fields to take defaults: %v", err)
+	}
+
+	for name, policy := range map[string]PresignPolicy{
+		"negative skew":     {ClockSkew: -time.Second},
+		"max below min":     {MinTTL: time.Hour, MaxTTL: time.Minute},
+		"default above max": {MaxTTL: time.Hour, PostTTL: 2 * time.Hour},
+		"default below min": {MinTTL: 20 * time.Minute},
+	} {
+		if err := policy.Validate(); !gerrors.IsValidation(err) {
+			t.Fatalf("%s: expected a validation error, got %v", name, err)
+		}
+	}
+}
+
+func TestManagerPresignPolicy(t *testing.T) {
+	ctx := context.Background()
+	provider := &expiryRecordingProvider{memoryProvider: newMemoryProvider()}
+	manager := NewManager(
+		WithProvider(provider),
+		WithPresignPolicy(PresignPolicy{
+			MinTTL:    time.Minute,
+			MaxTTL:    time.Hour,
+			URLTTL:    5 * time.Minute,
+			ClockSkew: 30 * time.Second,
+		}),
+	)
+
+	for _, requested := range []time.Duration{0, time.Second, 10 * time.Minute} {
+		if _, err := manager.GetPresignedURL(ctx, "a.txt", requested); err != nil {
+			t.Fatalf("GetPresignedURL(%s): %v", requested, err)
+		}
+	}
+	want := []time.Duration{5*time.Minute + 30*time.Second, 90 * time.Second, 10*time.Minute + 30*time.Second}
+	for i, expires := range provider.expires {
+		if expires != want[i] {
+			t.Fatalf("expected signed lifetimes %v, got %v", want, provider.expires)
+		}
+	}
+
+	if _, err := manager.GetPresignedURL(ctx, "a.txt", 2*time.Hour); !gerrors.IsValidation(err) {
+		t.Fatalf("expected a lifetime above MaxTTL to be rejected, got %v", err)
+	}
+}
+
+func TestManagerPresignPolicyPosts(t *testing.T) {
+	ctx := context.Background()
+	provider := &stubPresignProvider{post: &PresignedPost{URL: "https://example.com/upload"}}
+	manager := NewManager(
+		WithProvider(provider),
+		WithPresignPolicy(PresignPolicy{PostTTL: 5 * time.Minute, ClockSkew: time.Minute}),
+	)
+
+	if _, err := manager.CreatePresignedPost(ctx, "uploads/a.jpg", WithContentType("image/jpeg")); err != nil {
+		t.Fatalf("CreatePresignedPost: %v", err)
+	}
+	if provider.meta.TTL != 6*time.Minute {
+		t.Fatalf("expected the post TTL padded by the clock skew, got %s", provider.meta.TTL)
+	}
+
+	invalid := NewManager(WithProvider(provider), WithPresignPolicy(PresignPolicy{MaxTTL: -time.Hour}))
+	if _, err := invalid.CreatePresignedPost(ctx, "uploads/a.jpg", WithContentType("image/jpeg")); err != nil {
+		t.Fatalf("CreatePresignedPost: %v", err)
+	}
+	if provider.meta.TTL != DefaultPresignPolicy.PostTTL {
+		t.Fatalf("expected an invalid policy to keep the default, got %s", provider.meta.TTL)
+	}
+}
@@ -0,0 +1,52 @@
+package uploader
+
+import (
+	"net/url"
+
+	gerrors "github.com/goliatone/go-errors"
+)
+
+// PostCallbackURLField is the presigned post field carrying WithCallbackURL. S3 stores it as
+// the callback-url user metadata of the object.
+const PostCallbackURLField = "x-amz-meta-callback-url"
+
+// WithSuccessRedirect makes storage redirect the browser to redirectURL once a presigned post
+// succeeds, instead of answering with 201. S3 appends the bucket, key and etag as query
+// parameters, so the page can confirm the upload. The URL is part of the signed policy.
+func WithSuccessRedirect(redirectURL string) UploadOption {
+	return func(m *Metadata) { m.SuccessRedirect = redirectURL }
+}
+
+// WithCallbackURL signs callbackURL into a presigned post as PostCallbackURLField. The browser
+// must send it unchanged, so the server can trust it when the upload is confirmed.
+func WithCallbackURL(callbackURL string) UploadOption {
+	return func(m *Metadata) { m.CallbackURL = callbackURL }
+}
+
+// validatePostURLs rejects redirect and callback URLs that are not absolute http(s) URLs.
+func validatePostURLs(meta *Metadata) error {
+	var fields []gerrors.FieldError
+	if meta.SuccessRedirect != "" && !isAbsoluteHTTPURL(meta.SuccessRedirect) {
+		fields = append(fields, gerrors.FieldError{
+			Field:   "success_action_redirect",
+			Message: "redirect must be an absolute http or https URL",
+			Value:   meta.SuccessRedirect,
+		})
+	}
+	if meta.CallbackURL != "" && !isAbsoluteHTTPURL(meta.CallbackURL) {
+		fields = append(fields, gerrors.FieldError{
+			Field:   "callback_url",
+			Message: "callback must be an absolute http or https URL",
+			Value:   meta.CallbackURL,
+		})
+	}
+	if len(fields) > 0 {
+		return gerrors.NewValidation("presigned post validation failed", fields...)
+	}
+	return nil
+}
+
+func isAbsoluteHTTPURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -0,0 +1,84 @@
+package uploader
+
+import (
+	"html/template"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PostFileField is the form field carrying the file in a presigned post. S3 ignores every field
+// sent after it, so it must be the last part of the request.
+const PostFileField = "file"
+
+// PostField is one form field of a presigned post.
+type PostField struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// PresignedPostDescriptor is a presigned post in the shape clients should follow: Fields in the
+// order they must be appended to the form, then the file under FileField.
+type PresignedPostDescriptor struct {
+	URL       string      `json:"url"`
+	Method    string      `json:"method"`
+	Fields    []PostField `json:"fields"`
+	FileField string      `json:"file_field"`
+	Expiry    time.Time   `json:"expiry"`
+}
+
+// OrderedFields returns the post fields with key first and the rest sorted by name, so forms
+// built from them are stable. Append the file after them.
+func (p *PresignedPost) OrderedFields() []PostField {
+	fields := make([]PostField, 0, len(p.Fields))
+	for name, value := range p.Fields {
+		fields = append(fields, PostField{Name: name, Value: value})
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		if fields[i].Name == "key" || fields[j].Name == "key" {
+			return fields[i].Name == "key"
+		}
+		return fields[i].Name < fields[j].Name
+	})
+	return fields
+}
+
+// Descriptor returns the post as a PresignedPostDescriptor, ready to be sent as JSON to clients
+// that build the form themselves.
+func (p *PresignedPost) Descriptor() PresignedPostDescriptor {
+	method := p.Method
+	if method == "" {
+		method = "POST"
+	}
+	return PresignedPostDescriptor{
+		URL:       p.URL,
+		Method:    method,
+		Fields:    p.OrderedFields(),
+		FileField: PostFileField,
+		Expiry:    p.Expiry,
+	}
+}
+
+var presignedPostForm = template.Must(template.New("presigned_post").Parse(
+	`<form action="{{ .URL }}" method="{{ .Method }}" enctype="multipart/form-data">
+{{- range .Fields }}
+  <input type="hidden" name="{{ .Name }}" value="{{ .Value }}">
+{{- end }}
+  <input type="file" name="{{ .FileField }}"{{ if .Accept }} accept="{{ .Accept }}"{{ end }}>
+  <button type="submit">Upload</button>
+</form>
+`))
+
+// HTMLForm renders the post as an HTML form with the hidden fields before the file input, as S3
+// requires. accept, when given, limits the file picker to those MIME types. Values are escaped.
+func (p *PresignedPost) HTMLForm(accept ...string) (template.HTML, error) {
+	var out strings.Builder
+	err := presignedPostForm.Execute(&out, struct {
+		PresignedPostDescriptor
+		Accept string
+	}{p.Descriptor(), strings.Join(accept, ",")})
+	if err != nil {
+		return "", err
+	}
+	return template.HTML(out.String()), nil
+}
@@ -0,0 +1,52 @@
+package uploader
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestPresignedPostForm(t *testing.T) {
+	post := &PresignedPost{
+		URL:    "https://bucket.s3.amazonaws.com/",
+		Method: "POST",
+		Fields: map[string]string{
+			"X-Amz-Signature": "sig",
+			"Policy":          "policy",
+			"key":             "uploads/a.png",
+			"acl":             `private"><script>`,
+		},
+	}
+
+	fields := post.OrderedFields()
+	var names []string
+	for _, field := range fields {
+		names = append(names, field.Name)
+	}
+	if strings.Join(names, ",") != "key,Policy,X-Amz-Signature,acl" {
+		t.Fatalf("unexpected field order %v", names)
+	}
+
+	raw, err := json.Marshal(post.Descriptor())
+	if err != nil {
+		t.Fatalf("marshal descriptor: %v", err)
+	}
+	if !strings.Contains(string(raw), `"fields":[{"name":"key","value":"uploads/a.png"}`) || !strings.Contains(string(raw), `"file_field":"file"`) {
+		t.Fatalf("unexpected descriptor %s", raw)
+	}
+
+	form, err := post.HTMLForm("image/png", "image/jpeg")
+	if err != nil {
+		t.Fatalf("HTMLForm: %v", err)
+	}
+	html := string(form)
+	if strings.Contains(html, "<script>") {
+		t.Fatalf("expected field values to be escaped, got %s", html)
+	}
+	if strings.LastIndex(html, `type="hidden"`) > strings.Index(html, `type="file"`) {
+		t.Fatalf("expected hidden fields before the file input, got %s", html)
+	}
+	if !strings.Contains(html, `accept="image/png,image/jpeg"`) || !strings.Contains(html, `action="https://bucket.s3.amazonaws.com/"`) {
+		t.Fatalf("unexpected form %s", html)
+	}
+}
@@ -0,0 +1,256 @@
+package uploader
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// DefaultProgressBuffer is how many updates a progress subscription queues before older ones are
+// dropped.
+const DefaultProgressBuffer = 16
+
+// ProgressState is the lifecycle stage reported by a Progress update.
+type ProgressState string
+
+const (
+	ProgressStarted   ProgressState = "started"
+	ProgressUploading ProgressState = "uploading"
+	ProgressCompleted ProgressState = "completed"
+	ProgressAborted   ProgressState = "aborted"
+	ProgressFailed    ProgressState = "failed"
+)
+
+// Done reports whether no further updates follow for the upload.
+func (s ProgressState) Done() bool {
+	return s == ProgressCompleted || s == ProgressAborted || s == ProgressFailed
+}
+
+// Progress is a snapshot of one upload. ID is the chunk session ID for chunked uploads, or the
+// ID passed to TrackReader. Total is zero when the size is unknown.
+type Progress struct {
+	ID        string        `json:"id"`
+	Key       string        `json:"key"`
+	State     ProgressState `json:"state"`
+	Bytes     int64         `json:"bytes"`
+	Total     int64         `json:"total"`
+	Parts     int           `json:"parts,omitempty"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+// Fraction returns Bytes/Total clamped to [0, 1], or 0 when Total is unknown.
+func (p Progress) Fraction() float64 {
+	if p.Total <= 0 {
+		return 0
+	}
+	fraction := float64(p.Bytes) / float64(p.Total)
+	if fraction > 1 {
+		return 1
+	}
+	return fraction
+}
+
+// ProgressBroker aggregates upload progress and fans it out to subscribers. Register
+// EventHandler() with WithEventHandler to follow chunked sessions, and wrap other transfers
+// with TrackReader. Subscribers that fall behind lose their oldest queued updates rather than
+// blocking uploads.
+type ProgressBroker struct {
+	mu          sync.Mutex
+	uploads     map[string]Progress
+	subscribers map[*progressSubscriber]struct{}
+	buffer      int
+	now         func() time.Time
+}
+
+type progressSubscriber struct {
+	id string
+	ch chan Progress
+}
+
+// ProgressBrokerOption configures a ProgressBroker.
+type ProgressBrokerOption func(*ProgressBroker)
+
+// WithProgressBuffer sets the per-subscription queue size, DefaultProgressBuffer by default.
+func WithProgressBuffer(size int) ProgressBrokerOption {
+	return func(b *ProgressBroker) {
+		if size > 0 {
+			b.buffer = size
+		}
+	}
+}
+
+// WithProgressClock sets the clock used to stamp updates.
+func WithProgressClock(now func() time.Time) ProgressBrokerOption {
+	return func(b *ProgressBroker) {
+		if now != nil {
+			b.now = now
+		}
+	}
+}
+
+// NewProgressBroker creates an empty broker.
+func NewProgressBroker(opts ...ProgressBrokerOption) *ProgressBroker {
+	b := &ProgressBroker{
+		uploads:     make(map[string]Progress),
+		subscribers: make(map[*progressSubscriber]struct{}),
+		buffer:      DefaultProgressBuffer,
+		now:         time.Now,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// EventHandler turns chunk lifecycle events into progress updates.
+func (b *ProgressBroker) EventHandler() EventHandler {
+	return func(_ context.Context, event Event) {
+		switch e := event.(type) {
+		case *ChunkSessionStarted:
+			b.Publish(Progress{ID: e.SessionID, Key: e.Key, State: ProgressStarted, Total: e.TotalSize})
+		case *ChunkCompleted:
+			b.Publish(Progress{
+				ID:    e.SessionID,
+				Key:   e.Key,
+				State: ProgressUploading,
+				Bytes: e.UploadedBytes,
+				Total: e.TotalSize,
+				Parts: e.Uploaded,
+			})
+		case *ChunkSessionCompleted:
+			b.finish(e.SessionID, e.Key, ProgressCompleted, e.Size)
+		case *ChunkSessionAborted:
+			b.finish(e.SessionID, e.Key, ProgressAborted, 0)
+		}
+	}
+}
+
+// Publish records p as the latest state of its upload and delivers it to subscribers. Finished
+// uploads are forgotten once delivered.
+func (b *ProgressBroker) Publish(p Progress) {
+	if p.UpdatedAt.IsZero() {
+		p.UpdatedAt = b.now()
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if p.State.Done() {
+		delete(b.uploads, p.ID)
+	} else {
+		b.uploads[p.ID] = p
+	}
+
+	for sub := range b.subscribers {
+		if sub.id == "" || sub.id == p.ID {
+			sub.send(p)
+		}
+	}
+}
+
+// Snapshot returns the latest update for an upload still in progress.
+func (b *ProgressBroker) Snapshot(id string) (Progress, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	p, ok := b.uploads[id]
+	return p, ok
+}
+
+// Subscribe delivers updates for the upload with the given ID, or for every upload when id is
+// empty. When the upload is already in progress its latest state is delivered first. Call the
+// returned function to unsubscribe; it closes the channel.
+func (b *ProgressBroker) Subscribe(id string) (<-chan Progress, func()) {
+	sub := &progressSubscriber{id: id, ch: make(chan Progress, b.buffer)}
+
+	b.mu.Lock()
+	if id != "" {
+		if p, ok := b.uploads[id]; ok {
+			sub.send(p)
+		}
+	}
+	b.subscribers[sub] = struct{}{}
+	b.mu.Unlock()
+
+	var once sync.Once
+	return sub.ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			delete(b.subscribers, sub)
+			b.mu.Unlock()
+			close(sub.ch)
+		})
+	}
+}
+
+// TrackReader reports progress for a transfer that is not a chunked session, such as a request
+// body passed to UploadStream. An update is published for every read; EOF completes the upload
+// and any other read error fails it.
+func (b *ProgressBroker) TrackReader(id, key string, total int64, r io.Reader) io.Reader {
+	b.Publish(Progress{ID: id, Key: key, State: ProgressStarted, Total: total})
+	return &progressReader{broker: b, r: r, progress: Progress{ID: id, Key: key, Total: total}}
+}
+
+func (b *ProgressBroker) finish(id, key string, state ProgressState, size int64) {
+	b.mu.Lock()
+	p, ok := b.uploads[id]
+	b.mu.Unlock()
+
+	if !ok {
+		p = Progress{ID: id, Key: key}
+	}
+	p.State, p.UpdatedAt = state, time.Time{}
+	if size > 0 {
+		p.Bytes, p.Total = size, size
+	}
+	b.Publish(p)
+}
+
+// send queues p, dropping the oldest queued update when the subscriber is behind. Callers hold
+// the broker lock, which serializes sends with unsubscribe.
+func (s *progressSubscriber) send(p Progress) {
+	select {
+	case s.ch <- p:
+		return
+	default:
+	}
+
+	select {
+	case <-s.ch:
+	default:
+	}
+	select {
+	case s.ch <- p:
+	default:
+	}
+}
+
+type progressReader struct {
+	broker   *ProgressBroker
+	r        io.Reader
+	progress Progress
+	done     bool
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if r.done {
+		return n, err
+	}
+
+	r.progress.Bytes += int64(n)
+	switch {
+	case err == io.EOF:
+		r.progress.State, r.done = ProgressCompleted, true
+	case err != nil:
+		r.progress.State, r.done = ProgressFailed, true
+	case n > 0:
+		r.progress.State = ProgressUploading
+	default:
+		return n, err
+	}
+
+	r.broker.Publish(r.progress)
+	return n, err
+}
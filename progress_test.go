@@ -0,0 +1,81 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestProgressBrokerFollowsChunkedSessions(t *testing.T) {
+	ctx := context.Background()
+	broker := NewProgressBroker()
+	manager := NewManager(WithProvider(newMemoryProvider()), WithEventHandler(broker.EventHandler()))
+
+	session, err := manager.InitiateChunked(ctx, "chunks/file.bin", 8)
+	if err != nil {
+		t.Fatalf("InitiateChunked: %v", err)
+	}
+
+	updates, unsubscribe := broker.Subscribe(session.ID)
+	defer unsubscribe()
+
+	if started := <-updates; started.State != ProgressStarted || started.Total != 8 {
+		t.Fatalf("expected current state on subscribe, got %#v", started)
+	}
+
+	if err := manager.UploadChunk(ctx, session.ID, 0, bytes.NewReader([]byte("abcd"))); err != nil {
+		t.Fatalf("UploadChunk: %v", err)
+	}
+	if err := manager.UploadChunk(ctx, session.ID, 1, bytes.NewReader([]byte("efgh"))); err != nil {
+		t.Fatalf("UploadChunk: %v", err)
+	}
+
+	first, second := <-updates, <-updates
+	if first.Bytes != 4 || second.Bytes != 8 || second.Parts != 2 || second.Fraction() != 1 {
+		t.Fatalf("unexpected chunk progress: %#v %#v", first, second)
+	}
+
+	if _, err := manager.CompleteChunked(ctx, session.ID); err != nil {
+		t.Fatalf("CompleteChunked: %v", err)
+	}
+	if done := <-updates; done.State != ProgressCompleted || done.Key != "chunks/file.bin" {
+		t.Fatalf("unexpected final update: %#v", done)
+	}
+	if _, ok := broker.Snapshot(session.ID); ok {
+		t.Fatalf("expected finished upload to be forgotten")
+	}
+}
+
+func TestProgressBrokerTrackReader(t *testing.T) {
+	broker := NewProgressBroker(WithProgressBuffer(2))
+	updates, unsubscribe := broker.Subscribe("")
+	defer unsubscribe()
+
+	r := broker.TrackReader("upload-1", "big.bin", 10, io.MultiReader(strings.NewReader("01234"), strings.NewReader("56789")))
+	if _, err := io.ReadAll(r); err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+
+	// Four updates were published into a two slot queue; the oldest are dropped.
+	last := <-updates
+	final := <-updates
+	if last.State != ProgressUploading || last.Bytes != 10 || final.State != ProgressCompleted || final.Bytes != 10 {
+		t.Fatalf("unexpected updates: %#v %#v", last, final)
+	}
+
+	failing := broker.TrackReader("upload-2", "broken.bin", 10, io.MultiReader(strings.NewReader("01"), errReader{}))
+	if _, err := io.ReadAll(failing); err == nil {
+		t.Fatalf("expected read error")
+	}
+	<-updates
+	if failed := <-updates; failed.ID != "upload-2" || failed.State != ProgressFailed {
+		t.Fatalf("expected failed update, got %#v", failed)
+	}
+}
+
+type errReader struct{}
+
+func (errReader) Read([]byte) (int, error) { return 0, errors.New("connection reset") }
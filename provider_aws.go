@@ -8,40 +8,67 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"net/url"
 	"path"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/aws/signer/v4"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
 	"github.com/goliatone/go-print"
 )
 
 var (
-	_ Uploader        = &AWSProvider{}
-	_ ChunkedUploader = &AWSProvider{}
+	_ Uploader               = &AWSProvider{}
+	_ ProviderDescriber      = &AWSProvider{}
+	_ Lister                 = &AWSProvider{}
+	_ ObjectPager            = &AWSProvider{}
+	_ ChunkedUploader        = &AWSProvider{}
+	_ PresignedChunkUploader = &AWSProvider{}
+	_ GarbageCollector       = &AWSProvider{}
+	_ ChunkLimiter           = &AWSProvider{}
+	_ StreamUploader         = &AWSProvider{}
+	_ FileStatter            = &AWSProvider{}
+	_ RangeReader            = &AWSProvider{}
+	_ ConditionalWriter      = &AWSProvider{}
+	_ PublicURLBuilder       = &AWSProvider{}
+	_ LocationDescriber      = &AWSProvider{}
+	_ BatchDeleter           = &AWSProvider{}
 )
 
 type s3API interface {
 	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
 	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error)
 	DeleteObject(ctx context.Context, params *s3.DeleteObjectInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+	DeleteObjects(ctx context.Context, params *s3.DeleteObjectsInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectsOutput, error)
+	ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
 	HeadBucket(ctx context.Context, params *s3.HeadBucketInput, optFns ...func(*s3.Options)) (*s3.HeadBucketOutput, error)
 	CreateMultipartUpload(ctx context.Context, params *s3.CreateMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error)
 	UploadPart(ctx context.Context, params *s3.UploadPartInput, optFns ...func(*s3.Options)) (*s3.UploadPartOutput, error)
 	CompleteMultipartUpload(ctx context.Context, params *s3.CompleteMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error)
 	AbortMultipartUpload(ctx context.Context, params *s3.AbortMultipartUploadInput, optFns ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error)
+	ListMultipartUploads(ctx context.Context, params *s3.ListMultipartUploadsInput, optFns ...func(*s3.Options)) (*s3.ListMultipartUploadsOutput, error)
+	CopyObject(ctx context.Context, params *s3.CopyObjectInput, optFns ...func(*s3.Options)) (*s3.CopyObjectOutput, error)
+	UploadPartCopy(ctx context.Context, params *s3.UploadPartCopyInput, optFns ...func(*s3.Options)) (*s3.UploadPartCopyOutput, error)
+	RestoreObject(ctx context.Context, params *s3.RestoreObjectInput, optFns ...func(*s3.Options)) (*s3.RestoreObjectOutput, error)
+	PutObjectLegalHold(ctx context.Context, params *s3.PutObjectLegalHoldInput, optFns ...func(*s3.Options)) (*s3.PutObjectLegalHoldOutput, error)
+	PutObjectRetention(ctx context.Context, params *s3.PutObjectRetentionInput, optFns ...func(*s3.Options)) (*s3.PutObjectRetentionOutput, error)
 	Options() s3.Options
 }
 
 type s3PresignClient interface {
 	PresignGetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.PresignOptions)) (*v4.PresignedHTTPRequest, error)
+	PresignUploadPart(ctx context.Context, params *s3.UploadPartInput, optFns ...func(*s3.PresignOptions)) (*v4.PresignedHTTPRequest, error)
 }
 
 const awsUploadIDKey = "aws_upload_id"
@@ -53,81 +80,258 @@ type AWSProvider struct {
 	presigner s3PresignClient
 	logger    Logger
 	now       func() time.Time
+	sts       stsAPI
+	roleARN   string
+	buffers   *BufferPool
+
+	requestOpts   S3RequestOptions
+	retentionMode types.ObjectLockRetentionMode
+	publicBaseURL string
+	optionErr     error
 }
 
-func NewAWSProvider(client *s3.Client, bucket string) *AWSProvider {
-	return &AWSProvider{
+func NewAWSProvider(client *s3.Client, bucket string, opts ...AWSProviderOption) *AWSProvider {
+	p := &AWSProvider{
 		client:    client,
 		bucket:    bucket,
 		logger:    &DefaultLogger{},
 		presigner: s3.NewPresignClient(client),
 		now:       time.Now,
 	}
+	p.apply(opts...)
+	return p
 }
 
+// Deprecated: pass WithAWSLogger to NewAWSProvider.
 func (p *AWSProvider) WithLogger(logger Logger) *AWSProvider {
-	p.logger = logger
+	p.apply(WithAWSLogger(logger))
+	return p
+}
+
+// WithBufferPool sets the pool used to read downloads and chunk payloads.
+//
+// Deprecated: pass WithAWSBufferPool to NewAWSProvider.
+func (p *AWSProvider) WithBufferPool(pool *BufferPool) *AWSProvider {
+	p.apply(WithAWSBufferPool(pool))
 	return p
 }
 
+// Deprecated: pass WithAWSBasePath to NewAWSProvider.
 func (p *AWSProvider) WithBasePath(basePath string) *AWSProvider {
-	p.basePath = basePath
+	p.apply(WithAWSBasePath(basePath))
 	return p
 }
 
 func (p *AWSProvider) UploadFile(ctx context.Context, path string, content []byte, opts ...UploadOption) (string, error) {
+	return p.UploadStream(ctx, path, bytes.NewReader(content), int64(len(content)), opts...)
+}
+
+// UploadStream puts r as a single object. Pass a seekable reader so the SDK can sign the payload
+// without buffering it.
+func (p *AWSProvider) UploadStream(ctx context.Context, path string, r io.Reader, size int64, opts ...UploadOption) (string, error) {
 	md := &Metadata{}
 	for _, opt := range opts {
 		opt(md)
 	}
 
-	p.logger.Info("upload image", "bucket", p.bucket, "path", path)
+	contextLogger(ctx, p.logger).Info("upload image", "bucket", p.bucket, "path", path)
 
-	res, err := p.client.PutObject(ctx, &s3.PutObjectInput{
-		Bucket:       aws.String(p.bucket),
-		Key:          p.getKey(path),
-		Body:         bytes.NewReader(content),
-		ContentType:  aws.String(md.ContentType),
-		CacheControl: aws.String(md.CacheControl),
-		ACL:          types.ObjectCannedACLPrivate,
-	})
+	input := &s3.PutObjectInput{
+		Bucket:        aws.String(p.bucket),
+		Key:           p.getKey(path),
+		Body:          r,
+		ContentLength: aws.Int64(size),
+		ContentType:   aws.String(md.ContentType),
+		CacheControl:  aws.String(md.CacheControl),
+		ACL:           cannedACL(md.resolvedVisibility()),
+	}
+
+	reqOpts := p.requestOptions(ctx, md)
+	input.RequestPayer = reqOpts.requestPayer()
+	input.ExpectedBucketOwner = reqOpts.bucketOwner()
+
+	if md.StorageClass != "" {
+		input.StorageClass = types.StorageClass(md.StorageClass)
+	}
+
+	if md.IfNotExists {
+		input.IfNoneMatch = aws.String("*")
+	}
+
+	res, err := p.client.PutObject(ctx, input, reqOpts.clientOptions()...)
 	if err != nil {
-		p.logger.Error("S3 upload failed", err)
+		if md.IfNotExists && isPreconditionFailure(err) {
+			return "", fmt.Errorf("%w: %w", ErrFileExists, err)
+		}
+		contextLogger(ctx, p.logger).Error("S3 upload failed", err)
 		return "", fmt.Errorf("failed to upload image: %w", err)
 	}
 
-	p.logger.Info("upload image", "res", print.MaybeHighlightJSON(res))
+	logDebug(contextLogger(ctx, p.logger), "upload image response", "res", print.MaybeHighlightJSON(res))
 
 	return p.getURL(path), nil
 }
 
 func (p *AWSProvider) GetFile(ctx context.Context, path string) ([]byte, error) {
+	reqOpts := p.requestOptions(ctx, nil)
 	out, err := p.client.GetObject(ctx, &s3.GetObjectInput{
-		Bucket: aws.String(p.bucket),
-		Key:    p.getKey(path),
-	})
+		Bucket:              aws.String(p.bucket),
+		Key:                 p.getKey(path),
+		RequestPayer:        reqOpts.requestPayer(),
+		ExpectedBucketOwner: reqOpts.bucketOwner(),
+	}, reqOpts.clientOptions()...)
 	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, fmt.Errorf("%w: %w", ErrImageNotFound, err)
+		}
+		var archived *types.InvalidObjectState
+		if errors.As(err, &archived) {
+			return nil, fmt.Errorf("%w: %w", ErrObjectArchived, err)
+		}
 		return nil, err
 	}
 	defer out.Body.Close()
 
-	buf := new(bytes.Buffer)
-	_, err = buf.ReadFrom(out.Body)
-	return buf.Bytes(), err
+	return p.buffers.ReadAll(out.Body)
+}
+
+// OpenRange streams the object body straight from GetObject, asking S3 for a byte range when
+// offset or length narrow the read.
+func (p *AWSProvider) OpenRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	reqOpts := p.requestOptions(ctx, nil)
+	input := &s3.GetObjectInput{
+		Bucket:              aws.String(p.bucket),
+		Key:                 p.getKey(path),
+		RequestPayer:        reqOpts.requestPayer(),
+		ExpectedBucketOwner: reqOpts.bucketOwner(),
+	}
+	switch {
+	case length > 0:
+		input.Range = aws.String(fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+	case length == 0:
+		// Still fetch one byte so missing keys are reported; the limit below discards it.
+		input.Range = aws.String(fmt.Sprintf("bytes=%d-%d", offset, offset))
+	case offset > 0:
+		input.Range = aws.String(fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	out, err := p.client.GetObject(ctx, input, reqOpts.clientOptions()...)
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, fmt.Errorf("%w: %w", ErrImageNotFound, err)
+		}
+		var archived *types.InvalidObjectState
+		if errors.As(err, &archived) {
+			return nil, fmt.Errorf("%w: %w", ErrObjectArchived, err)
+		}
+		return nil, err
+	}
+
+	return limitReadCloser(out.Body, length), nil
+}
+
+func (p *AWSProvider) StatFile(ctx context.Context, path string) (*ObjectInfo, error) {
+	reqOpts := p.requestOptions(ctx, nil)
+	out, err := p.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:              aws.String(p.bucket),
+		Key:                 p.getKey(path),
+		RequestPayer:        reqOpts.requestPayer(),
+		ExpectedBucketOwner: reqOpts.bucketOwner(),
+	}, reqOpts.clientOptions()...)
+	if err != nil {
+		var notFound *types.NotFound
+		if errors.As(err, &notFound) {
+			return nil, fmt.Errorf("%w: %w", ErrImageNotFound, err)
+		}
+		return nil, fmt.Errorf("aws provider: head object: %w", err)
+	}
+
+	return &ObjectInfo{
+		Key:          path,
+		Size:         aws.ToInt64(out.ContentLength),
+		ModTime:      aws.ToTime(out.LastModified),
+		ETag:         strings.Trim(aws.ToString(out.ETag), "\""),
+		StorageClass: string(out.StorageClass),
+		ContentType:  aws.ToString(out.ContentType),
+		CacheControl: aws.ToString(out.CacheControl),
+		LegalHold:    out.ObjectLockLegalHoldStatus == types.ObjectLockLegalHoldStatusOn,
+		RetainUntil:  aws.ToTime(out.ObjectLockRetainUntilDate),
+	}, nil
+}
+
+// ConditionalWrites reports that WithIfNotExists is enforced with an If-None-Match: * header.
+// S3 compatible stores that ignore the header should be wrapped so the manager falls back to
+// StatFile checks.
+func (p *AWSProvider) ConditionalWrites() bool {
+	return true
+}
+
+// isPreconditionFailure reports whether S3 rejected a conditional write because the object
+// exists (412) or a concurrent conditional write won (409).
+func isPreconditionFailure(err error) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	code := apiErr.ErrorCode()
+	return code == "PreconditionFailed" || code == "ConditionalRequestConflict"
 }
 
 func (p *AWSProvider) DeleteFile(ctx context.Context, path string) error {
+	reqOpts := p.requestOptions(ctx, nil)
 	_, err := p.client.DeleteObject(ctx, &s3.DeleteObjectInput{
-		Bucket: aws.String(p.bucket),
-		Key:    p.getKey(path),
-	})
+		Bucket:              aws.String(p.bucket),
+		Key:                 p.getKey(path),
+		RequestPayer:        reqOpts.requestPayer(),
+		ExpectedBucketOwner: reqOpts.bucketOwner(),
+	}, reqOpts.clientOptions()...)
 	return err
 }
 
+// s3DeleteBatchSize is the most keys a single DeleteObjects request accepts.
+const s3DeleteBatchSize = 1000
+
+// DeleteFiles removes keys with DeleteObjects, 1000 keys per request. Keys S3 fails to delete
+// are reported together in the returned error.
+func (p *AWSProvider) DeleteFiles(ctx context.Context, keys []string) (int, error) {
+	reqOpts := p.requestOptions(ctx, nil)
+	deleted := 0
+	var errs []error
+	for start := 0; start < len(keys); start += s3DeleteBatchSize {
+		batch := keys[start:min(start+s3DeleteBatchSize, len(keys))]
+		objects := make([]types.ObjectIdentifier, 0, len(batch))
+		for _, key := range batch {
+			objects = append(objects, types.ObjectIdentifier{Key: p.getKey(key)})
+		}
+
+		out, err := p.client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+			Bucket:              aws.String(p.bucket),
+			Delete:              &types.Delete{Objects: objects, Quiet: aws.Bool(true)},
+			RequestPayer:        reqOpts.requestPayer(),
+			ExpectedBucketOwner: reqOpts.bucketOwner(),
+		}, reqOpts.clientOptions()...)
+		if err != nil {
+			return deleted, errors.Join(append(errs, fmt.Errorf("aws provider: delete objects: %w", err))...)
+		}
+
+		deleted += len(batch) - len(out.Errors)
+		for _, failed := range out.Errors {
+			errs = append(errs, fmt.Errorf("aws provider: delete %s: %s: %s",
+				aws.ToString(failed.Key), aws.ToString(failed.Code), aws.ToString(failed.Message)))
+		}
+	}
+	return deleted, errors.Join(errs...)
+}
+
 func (p *AWSProvider) GetPresignedURL(ctx context.Context, path string, ttl time.Duration) (string, error) {
+	reqOpts := p.requestOptions(ctx, nil)
 	req, err := p.presigner.PresignGetObject(ctx, &s3.GetObjectInput{
-		Bucket: aws.String(p.bucket),
-		Key:    p.getKey(path),
+		Bucket:              aws.String(p.bucket),
+		Key:                 p.getKey(path),
+		RequestPayer:        reqOpts.requestPayer(),
+		ExpectedBucketOwner: reqOpts.bucketOwner(),
 	}, s3.WithPresignExpires(ttl))
 	if err != nil {
 		return "", err
@@ -135,6 +339,109 @@ func (p *AWSProvider) GetPresignedURL(ctx context.Context, path string, ttl time
 	return req.URL, nil
 }
 
+// List returns every object whose key starts with prefix, following pagination.
+func (p *AWSProvider) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	fullPrefix := p.listPrefix(prefix)
+	reqOpts := p.requestOptions(ctx, nil)
+
+	var out []ObjectInfo
+	var token *string
+	for {
+		res, err := p.client.ListObjectsV2(ctx, &s3.ListObjectsV2Input{
+			Bucket:              p.bucketPtr(),
+			Prefix:              aws.String(fullPrefix),
+			ContinuationToken:   token,
+			RequestPayer:        reqOpts.requestPayer(),
+			ExpectedBucketOwner: reqOpts.bucketOwner(),
+		}, reqOpts.clientOptions()...)
+		if err != nil {
+			return nil, fmt.Errorf("aws provider: list objects: %w", err)
+		}
+
+		for _, obj := range res.Contents {
+			out = append(out, p.objectInfo(obj))
+		}
+
+		if !aws.ToBool(res.IsTruncated) || res.NextContinuationToken == nil {
+			break
+		}
+		token = res.NextContinuationToken
+	}
+
+	return out, nil
+}
+
+// ListPage lists up to limit objects under prefix whose keys sort after startAfter, in S3 key
+// order.
+func (p *AWSProvider) ListPage(ctx context.Context, prefix, startAfter string, limit int) ([]ObjectInfo, bool, error) {
+	reqOpts := p.requestOptions(ctx, nil)
+	input := &s3.ListObjectsV2Input{
+		Bucket:              p.bucketPtr(),
+		Prefix:              aws.String(p.listPrefix(prefix)),
+		RequestPayer:        reqOpts.requestPayer(),
+		ExpectedBucketOwner: reqOpts.bucketOwner(),
+	}
+	if limit > 0 {
+		input.MaxKeys = aws.Int32(int32(limit))
+	}
+	if startAfter != "" {
+		input.StartAfter = aws.String(p.listPrefix(startAfter))
+	}
+
+	res, err := p.client.ListObjectsV2(ctx, input, reqOpts.clientOptions()...)
+	if err != nil {
+		return nil, false, fmt.Errorf("aws provider: list objects: %w", err)
+	}
+
+	out := make([]ObjectInfo, 0, len(res.Contents))
+	for _, obj := range res.Contents {
+		out = append(out, p.objectInfo(obj))
+	}
+	return out, aws.ToBool(res.IsTruncated), nil
+}
+
+func (p *AWSProvider) objectInfo(obj types.Object) ObjectInfo {
+	return ObjectInfo{
+		Key:          p.relativeKey(aws.ToString(obj.Key)),
+		Size:         aws.ToInt64(obj.Size),
+		ModTime:      aws.ToTime(obj.LastModified),
+		ETag:         strings.Trim(aws.ToString(obj.ETag), "\""),
+		StorageClass: string(obj.StorageClass),
+	}
+}
+
+func (p *AWSProvider) listPrefix(prefix string) string {
+	prefix = strings.TrimPrefix(prefix, "/")
+	if p.basePath == "" {
+		return prefix
+	}
+	return strings.TrimSuffix(p.basePath, "/") + "/" + prefix
+}
+
+func (p *AWSProvider) relativeKey(key string) string {
+	if p.basePath == "" {
+		return key
+	}
+	return strings.TrimPrefix(key, strings.TrimSuffix(p.basePath, "/")+"/")
+}
+
+// ChunkLimits reports the S3 multipart rules: at most 10k parts and 5 MiB non-final parts.
+func (p *AWSProvider) ChunkLimits() ChunkLimits {
+	return ChunkLimits{MaxParts: MaxChunkParts, MinPartSize: MinS3ChunkPartSize}
+}
+
+func (p *AWSProvider) ProviderName() string {
+	return "aws"
+}
+
+func (p *AWSProvider) ProviderKey(path string) string {
+	return aws.ToString(p.getKey(path))
+}
+
+func (p *AWSProvider) StorageLocation() string {
+	return p.bucket
+}
+
 func (p *AWSProvider) getKey(key string) *string {
 	if p.basePath == "" {
 		return aws.String(key)
@@ -149,14 +456,40 @@ func (p *AWSProvider) getURL(key string) string {
 		out = path.Join(p.basePath, key)
 	}
 
-	if out[1] != '/' {
+	if len(out) < 2 || out[1] != '/' {
 		out = "/" + out
 	}
 
 	return out
 }
 
+// PublicURL returns the unsigned URL of path under the base set with WithAWSPublicBaseURL. It
+// returns ErrNotImplemented when no base is configured.
+func (p *AWSProvider) PublicURL(path string) (string, error) {
+	if p.publicBaseURL == "" {
+		return "", ErrNotImplemented
+	}
+	key := (&url.URL{Path: aws.ToString(p.getKey(path))}).EscapedPath()
+	return joinSegments(p.publicBaseURL, key), nil
+}
+
+// cannedACL translates v into an S3 canned ACL; the provider default is private.
+func cannedACL(v Visibility) types.ObjectCannedACL {
+	switch v {
+	case VisibilityPublicRead:
+		return types.ObjectCannedACLPublicRead
+	case VisibilityAuthenticated:
+		return types.ObjectCannedACLAuthenticatedRead
+	default:
+		return types.ObjectCannedACLPrivate
+	}
+}
+
 func (p *AWSProvider) Validate(ctx context.Context) error {
+	if p.optionErr != nil {
+		return fmt.Errorf("aws provider: invalid option: %w", p.optionErr)
+	}
+
 	if p.client == nil {
 		return fmt.Errorf("aws provider: client not configured")
 	}
@@ -165,7 +498,11 @@ func (p *AWSProvider) Validate(ctx context.Context) error {
 		return fmt.Errorf("aws provider: bucket not configured")
 	}
 
-	_, err := p.client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(p.bucket)})
+	reqOpts := p.requestOptions(ctx, nil)
+	_, err := p.client.HeadBucket(ctx, &s3.HeadBucketInput{
+		Bucket:              aws.String(p.bucket),
+		ExpectedBucketOwner: reqOpts.bucketOwner(),
+	}, reqOpts.clientOptions()...)
 	if err != nil {
 		return fmt.Errorf("aws provider: head bucket: %w", err)
 	}
@@ -178,10 +515,13 @@ func (p *AWSProvider) InitiateChunked(ctx context.Context, session *ChunkSession
 		return nil, fmt.Errorf("aws provider: chunk session is nil")
 	}
 
+	reqOpts := p.requestOptions(ctx, session.Metadata)
 	input := &s3.CreateMultipartUploadInput{
-		Bucket: p.bucketPtr(),
-		Key:    p.getKey(session.Key),
-		ACL:    types.ObjectCannedACLPrivate,
+		Bucket:              p.bucketPtr(),
+		Key:                 p.getKey(session.Key),
+		ACL:                 cannedACL(session.Metadata.resolvedVisibility()),
+		RequestPayer:        reqOpts.requestPayer(),
+		ExpectedBucketOwner: reqOpts.bucketOwner(),
 	}
 
 	if session.Metadata != nil {
@@ -191,9 +531,12 @@ func (p *AWSProvider) InitiateChunked(ctx context.Context, session *ChunkSession
 		if session.Metadata.CacheControl != "" {
 			input.CacheControl = aws.String(session.Metadata.CacheControl)
 		}
+		if session.Metadata.StorageClass != "" {
+			input.StorageClass = types.StorageClass(session.Metadata.StorageClass)
+		}
 	}
 
-	resp, err := p.client.CreateMultipartUpload(ctx, input)
+	resp, err := p.client.CreateMultipartUpload(ctx, input, reqOpts.clientOptions()...)
 	if err != nil {
 		return nil, fmt.Errorf("aws provider: create multipart upload: %w", err)
 	}
@@ -216,19 +559,26 @@ func (p *AWSProvider) UploadChunk(ctx context.Context, session *ChunkSession, in
 		return ChunkPart{}, fmt.Errorf("aws provider: chunk payload is nil")
 	}
 
-	data, err := io.ReadAll(payload)
-	if err != nil {
+	// UploadPart is done with the body once it returns, so the buffer can be recycled.
+	buf := p.buffers.Get()
+	defer p.buffers.Put(buf)
+
+	if _, err := buf.ReadFrom(payload); err != nil {
 		return ChunkPart{}, fmt.Errorf("aws provider: read chunk payload: %w", err)
 	}
+	data := buf.Bytes()
 
 	partNumber := int32(index + 1)
+	reqOpts := p.requestOptions(ctx, session.Metadata)
 	resp, err := p.client.UploadPart(ctx, &s3.UploadPartInput{
-		Bucket:     p.bucketPtr(),
-		Key:        p.getKey(session.Key),
-		UploadId:   aws.String(uploadID),
-		PartNumber: aws.Int32(partNumber),
-		Body:       bytes.NewReader(data),
-	})
+		Bucket:              p.bucketPtr(),
+		Key:                 p.getKey(session.Key),
+		UploadId:            aws.String(uploadID),
+		PartNumber:          aws.Int32(partNumber),
+		Body:                bytes.NewReader(data),
+		RequestPayer:        reqOpts.requestPayer(),
+		ExpectedBucketOwner: reqOpts.bucketOwner(),
+	}, reqOpts.clientOptions()...)
 	if err != nil {
 		return ChunkPart{}, fmt.Errorf("aws provider: upload part: %w", err)
 	}
@@ -252,6 +602,7 @@ func (p *AWSProvider) CompleteChunked(ctx context.Context, session *ChunkSession
 		return nil, err
 	}
 
+	reqOpts := p.requestOptions(ctx, session.Metadata)
 	_, err = p.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
 		Bucket:   p.bucketPtr(),
 		Key:      p.getKey(session.Key),
@@ -259,7 +610,9 @@ func (p *AWSProvider) CompleteChunked(ctx context.Context, session *ChunkSession
 		MultipartUpload: &types.CompletedMultipartUpload{
 			Parts: completedParts,
 		},
-	})
+		RequestPayer:        reqOpts.requestPayer(),
+		ExpectedBucketOwner: reqOpts.bucketOwner(),
+	}, reqOpts.clientOptions()...)
 	if err != nil {
 		return nil, fmt.Errorf("aws provider: complete multipart upload: %w", err)
 	}
@@ -284,11 +637,14 @@ func (p *AWSProvider) AbortChunked(ctx context.Context, session *ChunkSession) e
 		return err
 	}
 
+	reqOpts := p.requestOptions(ctx, session.Metadata)
 	_, err = p.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
-		Bucket:   p.bucketPtr(),
-		Key:      p.getKey(session.Key),
-		UploadId: aws.String(uploadID),
-	})
+		Bucket:              p.bucketPtr(),
+		Key:                 p.getKey(session.Key),
+		UploadId:            aws.String(uploadID),
+		RequestPayer:        reqOpts.requestPayer(),
+		ExpectedBucketOwner: reqOpts.bucketOwner(),
+	}, reqOpts.clientOptions()...)
 	if err != nil {
 		return fmt.Errorf("aws provider: abort multipart upload: %w", err)
 	}
@@ -296,6 +652,137 @@ func (p *AWSProvider) AbortChunked(ctx context.Context, session *ChunkSession) e
 	return nil
 }
 
+// CollectGarbage aborts multipart uploads under the base path that were initiated before
+// olderThan, releasing the storage held by their parts.
+func (p *AWSProvider) CollectGarbage(ctx context.Context, olderThan time.Time) (int, error) {
+	var keyMarker, uploadMarker *string
+	aborted := 0
+	reqOpts := p.requestOptions(ctx, nil)
+
+	for {
+		res, err := p.client.ListMultipartUploads(ctx, &s3.ListMultipartUploadsInput{
+			Bucket:              p.bucketPtr(),
+			Prefix:              aws.String(p.listPrefix("")),
+			KeyMarker:           keyMarker,
+			UploadIdMarker:      uploadMarker,
+			RequestPayer:        reqOpts.requestPayer(),
+			ExpectedBucketOwner: reqOpts.bucketOwner(),
+		}, reqOpts.clientOptions()...)
+		if err != nil {
+			return aborted, fmt.Errorf("aws provider: list multipart uploads: %w", err)
+		}
+
+		for _, upload := range res.Uploads {
+			if !aws.ToTime(upload.Initiated).Before(olderThan) {
+				continue
+			}
+
+			_, err := p.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+				Bucket:              p.bucketPtr(),
+				Key:                 upload.Key,
+				UploadId:            upload.UploadId,
+				RequestPayer:        reqOpts.requestPayer(),
+				ExpectedBucketOwner: reqOpts.bucketOwner(),
+			}, reqOpts.clientOptions()...)
+			if err != nil {
+				return aborted, fmt.Errorf("aws provider: abort multipart upload: %w", err)
+			}
+			aborted++
+		}
+
+		if !aws.ToBool(res.IsTruncated) {
+			break
+		}
+		keyMarker, uploadMarker = res.NextKeyMarker, res.NextUploadIdMarker
+	}
+
+	return aborted, nil
+}
+
+func (p *AWSProvider) PresignChunkPart(ctx context.Context, session *ChunkSession, index int, ttl time.Duration) (*PresignedRequest, error) {
+	uploadID, err := p.getUploadID(session)
+	if err != nil {
+		return nil, err
+	}
+
+	if index < 0 {
+		return nil, ErrChunkPartOutOfRange
+	}
+
+	reqOpts := p.requestOptions(ctx, session.Metadata)
+	req, err := p.presigner.PresignUploadPart(ctx, &s3.UploadPartInput{
+		Bucket:              p.bucketPtr(),
+		Key:                 p.getKey(session.Key),
+		UploadId:            aws.String(uploadID),
+		PartNumber:          aws.Int32(int32(index + 1)),
+		RequestPayer:        reqOpts.requestPayer(),
+		ExpectedBucketOwner: reqOpts.bucketOwner(),
+	}, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return nil, fmt.Errorf("aws provider: presign upload part: %w", err)
+	}
+
+	return &PresignedRequest{
+		URL:     req.URL,
+		Method:  req.Method,
+		Headers: flattenHeader(req.SignedHeader),
+		Expiry:  p.timeNow().Add(ttl),
+	}, nil
+}
+
+// PresignCompleteChunked signs a CompleteMultipartUpload request. The SDK does not expose a
+// presigner for this operation so the request is built and signed with SigV4 directly; the
+// client must send the CompleteMultipartUpload XML body.
+func (p *AWSProvider) PresignCompleteChunked(ctx context.Context, session *ChunkSession, ttl time.Duration) (*PresignedRequest, error) {
+	uploadID, err := p.getUploadID(session)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := p.client.Options()
+	if opts.Credentials == nil {
+		return nil, fmt.Errorf("aws provider: credentials provider not configured")
+	}
+
+	creds, err := opts.Credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("aws provider: retrieve credentials: %w", err)
+	}
+
+	region := opts.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	endpoint, err := url.Parse(p.buildBucketEndpoint(region))
+	if err != nil {
+		return nil, fmt.Errorf("aws provider: parse bucket endpoint: %w", err)
+	}
+	endpoint.Path = "/" + aws.ToString(p.getKey(session.Key))
+	endpoint.RawQuery = url.Values{
+		"uploadId":      []string{uploadID},
+		"X-Amz-Expires": []string{strconv.FormatInt(int64(ttl/time.Second), 10)},
+	}.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("aws provider: build complete request: %w", err)
+	}
+
+	now := p.timeNow().UTC()
+	signedURL, signedHeader, err := v4.NewSigner().PresignHTTP(ctx, creds, req, "UNSIGNED-PAYLOAD", "s3", region, now)
+	if err != nil {
+		return nil, fmt.Errorf("aws provider: presign complete multipart upload: %w", err)
+	}
+
+	return &PresignedRequest{
+		URL:     signedURL,
+		Method:  http.MethodPost,
+		Headers: flattenHeader(signedHeader),
+		Expiry:  now.Add(ttl),
+	}, nil
+}
+
 func (p *AWSProvider) CreatePresignedPost(ctx context.Context, key string, metadata *Metadata) (*PresignedPost, error) {
 	if metadata == nil {
 		metadata = &Metadata{}
@@ -326,10 +813,7 @@ func (p *AWSProvider) CreatePresignedPost(ctx context.Context, key string, metad
 
 	algorithm := "AWS4-HMAC-SHA256"
 	amzDate := now.Format("20060102T150405Z")
-	acl := "private"
-	if metadata.Public {
-		acl = "public-read"
-	}
+	acl := string(cannedACL(metadata.resolvedVisibility()))
 
 	conditions := []any{
 		map[string]string{"bucket": p.bucket},
@@ -353,6 +837,16 @@ func (p *AWSProvider) CreatePresignedPost(ctx context.Context, key string, metad
 		conditions = append(conditions, map[string]string{"x-amz-security-token": creds.SessionToken})
 	}
 
+	if metadata.SuccessRedirect != "" {
+		conditions = append(conditions, map[string]string{"success_action_redirect": metadata.SuccessRedirect})
+	} else {
+		conditions = append(conditions, map[string]string{"success_action_status": "201"})
+	}
+
+	if metadata.CallbackURL != "" {
+		conditions = append(conditions, map[string]string{PostCallbackURLField: metadata.CallbackURL})
+	}
+
 	expiry := now.Add(metadata.TTL)
 
 	policyDoc := map[string]any{
@@ -370,14 +864,23 @@ func (p *AWSProvider) CreatePresignedPost(ctx context.Context, key string, metad
 	signature := hex.EncodeToString(hmacSHA256(signingKey, policyBase64))
 
 	fields := map[string]string{
-		"key":                   finalKey,
-		"acl":                   acl,
-		"Policy":                policyBase64,
-		"X-Amz-Algorithm":       algorithm,
-		"X-Amz-Credential":      credential,
-		"X-Amz-Date":            amzDate,
-		"X-Amz-Signature":       signature,
-		"success_action_status": "201",
+		"key":              finalKey,
+		"acl":              acl,
+		"Policy":           policyBase64,
+		"X-Amz-Algorithm":  algorithm,
+		"X-Amz-Credential": credential,
+		"X-Amz-Date":       amzDate,
+		"X-Amz-Signature":  signature,
+	}
+
+	// S3 ignores success_action_status when a redirect is given.
+	if metadata.SuccessRedirect != "" {
+		fields["success_action_redirect"] = metadata.SuccessRedirect
+	} else {
+		fields["success_action_status"] = "201"
+	}
+	if metadata.CallbackURL != "" {
+		fields[PostCallbackURLField] = metadata.CallbackURL
 	}
 
 	if metadata.ContentType != "" {
@@ -392,12 +895,22 @@ func (p *AWSProvider) CreatePresignedPost(ctx context.Context, key string, metad
 
 	endpoint := p.buildBucketEndpoint(region)
 
-	return &PresignedPost{
+	post := &PresignedPost{
 		URL:    endpoint,
 		Method: "POST",
 		Fields: fields,
 		Expiry: expiry,
-	}, nil
+	}
+
+	if p.sts != nil && p.roleARN != "" {
+		scoped, err := p.objectCredentials(ctx, key, metadata.TTL)
+		if err != nil {
+			return nil, err
+		}
+		post.Credentials = scoped
+	}
+
+	return post, nil
 }
 
 func (p *AWSProvider) bucketPtr() *string {
@@ -475,6 +988,17 @@ func (p *AWSProvider) timeNow() time.Time {
 	return time.Now()
 }
 
+func flattenHeader(h http.Header) map[string]string {
+	if len(h) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(h))
+	for k := range h {
+		out[k] = h.Get(k)
+	}
+	return out
+}
+
 func deriveSigningKey(secret, dateStamp, region string) []byte {
 	kDate := hmacSHA256([]byte("AWS4"+secret), dateStamp)
 	kRegion := hmacSHA256(kDate, region)
@@ -0,0 +1,108 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+var _ AppendOnlyProvider = &AWSProvider{}
+
+// AppendFile emulates appends, which S3 does not support, by rewriting the object. Objects
+// smaller than MinS3ChunkPartSize are read and put back with chunk added; larger ones are
+// concatenated server side by a multipart upload that copies the current object into its first
+// parts. Every step is conditional on the object's ETag, so concurrent appends fail with
+// ErrAppendConflict instead of dropping data. Content type, cache control and storage class are
+// kept; object ACLs are not.
+func (p *AWSProvider) AppendFile(ctx context.Context, path string, chunk []byte) (int64, error) {
+	info, err := p.StatFile(ctx, path)
+	if errors.Is(err, ErrImageNotFound) {
+		if _, err := p.UploadStream(ctx, path, bytes.NewReader(chunk), int64(len(chunk)), WithIfNotExists()); err != nil {
+			if errors.Is(err, ErrFileExists) {
+				return 0, fmt.Errorf("%w: %w", ErrAppendConflict, err)
+			}
+			return 0, err
+		}
+		return int64(len(chunk)), nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	contextLogger(ctx, p.logger).Info("append object", "bucket", p.bucket, "path", path, "size", info.Size, "chunk", len(chunk))
+
+	etag := aws.String(`"` + info.ETag + `"`)
+	if info.Size < MinS3ChunkPartSize {
+		err = p.appendByRewrite(ctx, path, info, etag, chunk)
+	} else {
+		err = p.appendByCopy(ctx, path, info, etag, chunk)
+	}
+	if isPreconditionFailure(err) {
+		return 0, fmt.Errorf("%w: %w", ErrAppendConflict, err)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return info.Size + int64(len(chunk)), nil
+}
+
+func (p *AWSProvider) appendByRewrite(ctx context.Context, path string, info *ObjectInfo, etag *string, chunk []byte) error {
+	reqOpts := p.requestOptions(ctx, nil)
+	out, err := p.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket:              p.bucketPtr(),
+		Key:                 p.getKey(path),
+		IfMatch:             etag,
+		RequestPayer:        reqOpts.requestPayer(),
+		ExpectedBucketOwner: reqOpts.bucketOwner(),
+	}, reqOpts.clientOptions()...)
+	if err != nil {
+		return fmt.Errorf("aws provider: append: get object: %w", err)
+	}
+	defer out.Body.Close()
+
+	buf := p.buffers.Get()
+	defer p.buffers.Put(buf)
+	if _, err := buf.ReadFrom(out.Body); err != nil {
+		return fmt.Errorf("aws provider: append: read object: %w", err)
+	}
+	buf.Write(chunk)
+
+	_, err = p.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:              p.bucketPtr(),
+		Key:                 p.getKey(path),
+		Body:                bytes.NewReader(buf.Bytes()),
+		ContentLength:       aws.Int64(int64(buf.Len())),
+		ContentType:         aws.String(info.ContentType),
+		CacheControl:        aws.String(info.CacheControl),
+		StorageClass:        types.StorageClass(info.StorageClass),
+		IfMatch:             etag,
+		RequestPayer:        reqOpts.requestPayer(),
+		ExpectedBucketOwner: reqOpts.bucketOwner(),
+	}, reqOpts.clientOptions()...)
+	if err != nil {
+		return fmt.Errorf("aws provider: append: put object: %w", err)
+	}
+	return nil
+}
+
+func (p *AWSProvider) appendByCopy(ctx context.Context, path string, info *ObjectInfo, etag *string, chunk []byte) error {
+	builder, err := p.newMultipartBuilder(ctx, path, &Metadata{
+		ContentType:  info.ContentType,
+		CacheControl: info.CacheControl,
+		StorageClass: info.StorageClass,
+	})
+	if err != nil {
+		return err
+	}
+	if err := builder.copyObject(path, info, false); err != nil {
+		builder.abort()
+		return err
+	}
+	builder.pending.Write(chunk)
+	return builder.complete(etag)
+}
@@ -0,0 +1,119 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+var _ Archiver = &AWSProvider{}
+
+// archivedStorageClasses need a restore before their objects can be read. GLACIER_IR is read
+// directly and is not listed.
+var archivedStorageClasses = map[types.StorageClass]bool{
+	types.StorageClassGlacier:     true,
+	types.StorageClassDeepArchive: true,
+}
+
+var restoreHeaderPattern = regexp.MustCompile(`ongoing-request="(true|false)"(?:,\s*expiry-date="([^"]+)")?`)
+
+// ArchiveObject copies the object onto itself with storageClass, keeping its metadata. S3 copies
+// in a single request only up to 5 GB; larger objects should be moved by a lifecycle rule.
+func (p *AWSProvider) ArchiveObject(ctx context.Context, path, storageClass string) error {
+	key := p.getKey(path)
+	reqOpts := p.requestOptions(ctx, nil)
+	_, err := p.client.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:                    p.bucketPtr(),
+		Key:                       key,
+		CopySource:                aws.String(p.bucket + "/" + url.PathEscape(aws.ToString(key))),
+		StorageClass:              types.StorageClass(storageClass),
+		MetadataDirective:         types.MetadataDirectiveCopy,
+		RequestPayer:              reqOpts.requestPayer(),
+		ExpectedBucketOwner:       reqOpts.bucketOwner(),
+		ExpectedSourceBucketOwner: reqOpts.bucketOwner(),
+	}, reqOpts.clientOptions()...)
+	if err != nil {
+		return p.archiveError("copy object", err)
+	}
+	return nil
+}
+
+// RestoreObject starts a Glacier restore keeping a readable copy for days. A restore already in
+// progress is not an error.
+func (p *AWSProvider) RestoreObject(ctx context.Context, path string, days int, tier RestoreTier) error {
+	reqOpts := p.requestOptions(ctx, nil)
+	_, err := p.client.RestoreObject(ctx, &s3.RestoreObjectInput{
+		Bucket: p.bucketPtr(),
+		Key:    p.getKey(path),
+		RestoreRequest: &types.RestoreRequest{
+			Days:                 aws.Int32(int32(days)),
+			GlacierJobParameters: &types.GlacierJobParameters{Tier: types.Tier(tier)},
+		},
+		RequestPayer:        reqOpts.requestPayer(),
+		ExpectedBucketOwner: reqOpts.bucketOwner(),
+	}, reqOpts.clientOptions()...)
+	if err != nil {
+		var apiErr interface{ ErrorCode() string }
+		if errors.As(err, &apiErr) && apiErr.ErrorCode() == "RestoreAlreadyInProgress" {
+			return nil
+		}
+		return p.archiveError("restore object", err)
+	}
+	return nil
+}
+
+// ArchiveStatus derives the archive state from the object's storage class and the x-amz-restore
+// header returned by HeadObject.
+func (p *AWSProvider) ArchiveStatus(ctx context.Context, path string) (*ArchiveStatus, error) {
+	reqOpts := p.requestOptions(ctx, nil)
+	out, err := p.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:              p.bucketPtr(),
+		Key:                 p.getKey(path),
+		RequestPayer:        reqOpts.requestPayer(),
+		ExpectedBucketOwner: reqOpts.bucketOwner(),
+	}, reqOpts.clientOptions()...)
+	if err != nil {
+		var notFound *types.NotFound
+		if errors.As(err, &notFound) {
+			return nil, fmt.Errorf("%w: %w", ErrImageNotFound, err)
+		}
+		return nil, fmt.Errorf("aws provider: head object: %w", err)
+	}
+
+	status := &ArchiveStatus{Key: path, State: ArchiveStateLive, StorageClass: string(out.StorageClass)}
+	if !archivedStorageClasses[out.StorageClass] {
+		return status, nil
+	}
+
+	status.State = ArchiveStateArchived
+	if match := restoreHeaderPattern.FindStringSubmatch(aws.ToString(out.Restore)); match != nil {
+		if match[1] == "true" {
+			status.State = ArchiveStateRestoring
+		} else {
+			status.State = ArchiveStateRestored
+			if expiry, err := http.ParseTime(match[2]); err == nil {
+				status.RestoreExpiry = expiry
+			}
+		}
+	}
+	return status, nil
+}
+
+func (p *AWSProvider) archiveError(op string, err error) error {
+	var noSuchKey *types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return fmt.Errorf("%w: %w", ErrImageNotFound, err)
+	}
+	var archived *types.InvalidObjectState
+	if errors.As(err, &archived) {
+		return fmt.Errorf("%w: %w", ErrObjectArchived, err)
+	}
+	return fmt.Errorf("aws provider: %s: %w", op, err)
+}
@@ -0,0 +1,273 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+var _ Composer = &AWSProvider{}
+
+// MaxS3CopyPartSize is the largest source range a single UploadPartCopy may copy.
+const MaxS3CopyPartSize int64 = 5 * 1024 * 1024 * 1024
+
+// ComposeObjects builds dst as a multipart upload whose parts are copied from srcs with
+// UploadPartCopy, so the data stays inside S3. Sources smaller than MinS3ChunkPartSize cannot
+// be parts of their own; they are downloaded and merged with their neighbours until the part is
+// large enough. Copies are pinned to the ETag each source had when composing started, and a
+// source modified meanwhile fails the call with ErrComposeConflict. Content type defaults to the
+// first source's.
+func (p *AWSProvider) ComposeObjects(ctx context.Context, dst string, srcs []string, opts ...UploadOption) (*UploadResult, error) {
+	md := &Metadata{}
+	for _, opt := range opts {
+		opt(md)
+	}
+
+	infos := make([]*ObjectInfo, len(srcs))
+	var total int64
+	for i, src := range srcs {
+		info, err := p.StatFile(ctx, src)
+		if err != nil {
+			return nil, err
+		}
+		infos[i], total = info, total+info.Size
+	}
+	if md.ContentType == "" {
+		md.ContentType = infos[0].ContentType
+	}
+
+	contextLogger(ctx, p.logger).Info("compose objects", "bucket", p.bucket, "path", dst, "sources", len(srcs), "size", total)
+
+	builder, err := p.newMultipartBuilder(ctx, dst, md)
+	if err != nil {
+		return nil, err
+	}
+	for i, src := range srcs {
+		if err := builder.copyObject(src, infos[i], i == len(srcs)-1); err != nil {
+			builder.abort()
+			return nil, composeError(err)
+		}
+	}
+	if err := builder.complete(nil); err != nil {
+		return nil, composeError(err)
+	}
+
+	return &UploadResult{Key: dst, URL: p.getURL(dst), Size: total, ContentType: md.ContentType}, nil
+}
+
+func composeError(err error) error {
+	if isPreconditionFailure(err) {
+		return fmt.Errorf("%w: %w", ErrComposeConflict, err)
+	}
+	return err
+}
+
+// multipartBuilder assembles an object from copied ranges and uploaded bytes, buffering data
+// until it fills a part of at least MinS3ChunkPartSize.
+type multipartBuilder struct {
+	p        *AWSProvider
+	ctx      context.Context
+	key      string
+	reqOpts  S3RequestOptions
+	uploadID *string
+	parts    []types.CompletedPart
+	pending  bytes.Buffer
+}
+
+func (p *AWSProvider) newMultipartBuilder(ctx context.Context, key string, md *Metadata) (*multipartBuilder, error) {
+	reqOpts := p.requestOptions(ctx, md)
+	input := &s3.CreateMultipartUploadInput{
+		Bucket:              p.bucketPtr(),
+		Key:                 p.getKey(key),
+		ACL:                 cannedACL(md.resolvedVisibility()),
+		ContentType:         aws.String(md.ContentType),
+		CacheControl:        aws.String(md.CacheControl),
+		RequestPayer:        reqOpts.requestPayer(),
+		ExpectedBucketOwner: reqOpts.bucketOwner(),
+	}
+	if md.StorageClass != "" {
+		input.StorageClass = types.StorageClass(md.StorageClass)
+	}
+
+	created, err := p.client.CreateMultipartUpload(ctx, input, reqOpts.clientOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("aws provider: create multipart upload: %w", err)
+	}
+	return &multipartBuilder{p: p, ctx: ctx, key: key, reqOpts: reqOpts, uploadID: created.UploadId}, nil
+}
+
+// copyObject adds the object src described by info. last allows its tail to be a part smaller
+// than MinS3ChunkPartSize.
+func (b *multipartBuilder) copyObject(src string, info *ObjectInfo, last bool) error {
+	etag := aws.String(`"` + info.ETag + `"`)
+	offset := int64(0)
+	if b.pending.Len() > 0 {
+		offset = min(MinS3ChunkPartSize-int64(b.pending.Len()), info.Size)
+		if err := b.readRange(src, etag, 0, offset); err != nil {
+			return err
+		}
+	}
+
+	remaining := info.Size - offset
+	switch {
+	case remaining == 0:
+		return nil
+	case remaining < MinS3ChunkPartSize && !last:
+		return b.readRange(src, etag, offset, info.Size)
+	}
+	for _, r := range copyPartRanges(offset, info.Size) {
+		if err := b.copyRange(src, etag, r[0], r[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// write buffers data, flushing it as a part once the buffer reaches MinS3ChunkPartSize.
+func (b *multipartBuilder) write(data []byte) error {
+	b.pending.Write(data)
+	if int64(b.pending.Len()) < MinS3ChunkPartSize {
+		return nil
+	}
+	return b.flush()
+}
+
+func (b *multipartBuilder) flush() error {
+	partNumber, err := b.nextPart()
+	if err != nil {
+		return err
+	}
+	out, err := b.p.client.UploadPart(b.ctx, &s3.UploadPartInput{
+		Bucket:              b.p.bucketPtr(),
+		Key:                 b.p.getKey(b.key),
+		UploadId:            b.uploadID,
+		PartNumber:          partNumber,
+		Body:                bytes.NewReader(b.pending.Bytes()),
+		RequestPayer:        b.reqOpts.requestPayer(),
+		ExpectedBucketOwner: b.reqOpts.bucketOwner(),
+	}, b.reqOpts.clientOptions()...)
+	if err != nil {
+		return fmt.Errorf("aws provider: upload part: %w", err)
+	}
+	b.parts = append(b.parts, types.CompletedPart{ETag: out.ETag, PartNumber: partNumber})
+	b.pending.Reset()
+	return nil
+}
+
+// readRange buffers bytes [start, end) of src.
+func (b *multipartBuilder) readRange(src string, etag *string, start, end int64) error {
+	if start >= end {
+		return nil
+	}
+	out, err := b.p.client.GetObject(b.ctx, &s3.GetObjectInput{
+		Bucket:              b.p.bucketPtr(),
+		Key:                 b.p.getKey(src),
+		Range:               aws.String(fmt.Sprintf("bytes=%d-%d", start, end-1)),
+		IfMatch:             etag,
+		RequestPayer:        b.reqOpts.requestPayer(),
+		ExpectedBucketOwner: b.reqOpts.bucketOwner(),
+	}, b.reqOpts.clientOptions()...)
+	if err != nil {
+		return fmt.Errorf("aws provider: get object: %w", err)
+	}
+	defer out.Body.Close()
+
+	data, err := b.p.buffers.ReadAll(out.Body)
+	if err != nil {
+		return fmt.Errorf("aws provider: read object: %w", err)
+	}
+	return b.write(data)
+}
+
+// copyRange adds the inclusive byte range [start, end] of src as a part.
+func (b *multipartBuilder) copyRange(src string, etag *string, start, end int64) error {
+	partNumber, err := b.nextPart()
+	if err != nil {
+		return err
+	}
+	out, err := b.p.client.UploadPartCopy(b.ctx, &s3.UploadPartCopyInput{
+		Bucket:                    b.p.bucketPtr(),
+		Key:                       b.p.getKey(b.key),
+		UploadId:                  b.uploadID,
+		PartNumber:                partNumber,
+		CopySource:                aws.String(b.p.bucket + "/" + url.PathEscape(aws.ToString(b.p.getKey(src)))),
+		CopySourceRange:           aws.String(fmt.Sprintf("bytes=%d-%d", start, end)),
+		CopySourceIfMatch:         etag,
+		RequestPayer:              b.reqOpts.requestPayer(),
+		ExpectedBucketOwner:       b.reqOpts.bucketOwner(),
+		ExpectedSourceBucketOwner: b.reqOpts.bucketOwner(),
+	}, b.reqOpts.clientOptions()...)
+	if err != nil {
+		return fmt.Errorf("aws provider: copy part: %w", err)
+	}
+	var partETag *string
+	if out.CopyPartResult != nil {
+		partETag = out.CopyPartResult.ETag
+	}
+	b.parts = append(b.parts, types.CompletedPart{ETag: partETag, PartNumber: partNumber})
+	return nil
+}
+
+func (b *multipartBuilder) nextPart() (*int32, error) {
+	if len(b.parts) >= MaxChunkParts {
+		return nil, fmt.Errorf("aws provider: object needs more than %d parts", MaxChunkParts)
+	}
+	return aws.Int32(int32(len(b.parts) + 1)), nil
+}
+
+// complete flushes buffered data and completes the upload, replacing the object only while it
+// still matches ifMatch when given. The upload is aborted on failure.
+func (b *multipartBuilder) complete(ifMatch *string) error {
+	var err error
+	if b.pending.Len() > 0 || len(b.parts) == 0 {
+		// An upload needs at least one part, even an empty one.
+		err = b.flush()
+	}
+	if err == nil {
+		_, err = b.p.client.CompleteMultipartUpload(b.ctx, &s3.CompleteMultipartUploadInput{
+			Bucket:              b.p.bucketPtr(),
+			Key:                 b.p.getKey(b.key),
+			UploadId:            b.uploadID,
+			MultipartUpload:     &types.CompletedMultipartUpload{Parts: b.parts},
+			IfMatch:             ifMatch,
+			RequestPayer:        b.reqOpts.requestPayer(),
+			ExpectedBucketOwner: b.reqOpts.bucketOwner(),
+		}, b.reqOpts.clientOptions()...)
+		if err != nil {
+			err = fmt.Errorf("aws provider: complete multipart upload: %w", err)
+		}
+	}
+	if err != nil {
+		b.abort()
+	}
+	return err
+}
+
+func (b *multipartBuilder) abort() {
+	_, _ = b.p.client.AbortMultipartUpload(context.WithoutCancel(b.ctx), &s3.AbortMultipartUploadInput{
+		Bucket:              b.p.bucketPtr(),
+		Key:                 b.p.getKey(b.key),
+		UploadId:            b.uploadID,
+		RequestPayer:        b.reqOpts.requestPayer(),
+		ExpectedBucketOwner: b.reqOpts.bucketOwner(),
+	}, b.reqOpts.clientOptions()...)
+}
+
+// copyPartRanges splits [offset, size) into inclusive ranges of at most MaxS3CopyPartSize. The
+// ranges are evened out so none falls under MinS3ChunkPartSize.
+func copyPartRanges(offset, size int64) [][2]int64 {
+	length := size - offset
+	count := (length + MaxS3CopyPartSize - 1) / MaxS3CopyPartSize
+	partSize := (length + count - 1) / count
+
+	ranges := make([][2]int64, 0, count)
+	for start := offset; start < size; start += partSize {
+		ranges = append(ranges, [2]int64{start, min(start+partSize, size) - 1})
+	}
+	return ranges
+}
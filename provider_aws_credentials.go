@@ -0,0 +1,145 @@
+package uploader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	gerrors "github.com/goliatone/go-errors"
+)
+
+// minAssumeRoleDuration is the shortest session STS accepts for AssumeRole.
+const minAssumeRoleDuration = 15 * time.Minute
+
+type stsAPI interface {
+	AssumeRole(ctx context.Context, params *sts.AssumeRoleInput, optFns ...func(*sts.Options)) (*sts.AssumeRoleOutput, error)
+}
+
+var _ CredentialScoper = &AWSProvider{}
+
+// WithScopedCredentials enables minting scoped credentials by assuming roleARN with a session
+// policy restricted to the upload key or prefix.
+//
+// Deprecated: pass WithAWSScopedCredentials to NewAWSProvider.
+func (p *AWSProvider) WithScopedCredentials(client *sts.Client, roleARN string) *AWSProvider {
+	p.apply(WithAWSScopedCredentials(client, roleARN))
+	return p
+}
+
+// ScopedCredentials mints credentials that can only write keys under prefix. The prefix covers
+// whole path segments: "users/1" grants "users/1/a.jpg" but not "users/10/a.jpg". A zero ttl
+// requests the shortest session STS issues, 15 minutes; shorter ttls fail validation, since STS
+// cannot mint credentials that expire sooner.
+func (p *AWSProvider) ScopedCredentials(ctx context.Context, prefix string, ttl time.Duration) (*ScopedCredentials, error) {
+	if p.sts == nil || p.roleARN == "" {
+		return nil, ErrNotImplemented
+	}
+
+	if strings.Contains(prefix, "..") {
+		return nil, ErrInvalidPath
+	}
+
+	scopedPrefix := strings.Trim(aws.ToString(p.getKey(prefix)), "/")
+	resource := path.Join(p.bucket, scopedPrefix) + "/*"
+
+	creds, err := p.assumeScopedRole(ctx, resource, ttl)
+	if err != nil {
+		return nil, err
+	}
+	creds.Prefix = scopedPrefix
+	return creds, nil
+}
+
+// objectCredentials mints credentials that can only write key itself, for presigned posts.
+func (p *AWSProvider) objectCredentials(ctx context.Context, key string, ttl time.Duration) (*ScopedCredentials, error) {
+	if strings.Contains(key, "..") {
+		return nil, ErrInvalidPath
+	}
+
+	scopedKey := strings.TrimPrefix(aws.ToString(p.getKey(key)), "/")
+	creds, err := p.assumeScopedRole(ctx, path.Join(p.bucket, scopedKey), ttl)
+	if err != nil {
+		return nil, err
+	}
+	creds.Key = scopedKey
+	return creds, nil
+}
+
+// assumeScopedRole assumes the configured role with a session policy allowing writes to the S3
+// resource, "bucket/key" or "bucket/prefix/*".
+func (p *AWSProvider) assumeScopedRole(ctx context.Context, resource string, ttl time.Duration) (*ScopedCredentials, error) {
+	if ttl <= 0 {
+		ttl = minAssumeRoleDuration
+	}
+	if ttl < minAssumeRoleDuration {
+		return nil, gerrors.NewValidation("scoped credentials ttl invalid",
+			gerrors.FieldError{
+				Field:   "ttl",
+				Message: fmt.Sprintf("must be at least %s, the shortest session STS issues", minAssumeRoleDuration),
+				Value:   ttl,
+			},
+		)
+	}
+
+	policy, err := scopedSessionPolicy(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := p.sts.AssumeRole(ctx, &sts.AssumeRoleInput{
+		RoleArn:         aws.String(p.roleARN),
+		RoleSessionName: aws.String(fmt.Sprintf("go-uploader-%d", p.timeNow().UnixNano())),
+		DurationSeconds: aws.Int32(int32(ttl / time.Second)),
+		Policy:          aws.String(policy),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("aws provider: assume role: %w", err)
+	}
+
+	if out.Credentials == nil {
+		return nil, fmt.Errorf("aws provider: assume role returned no credentials")
+	}
+
+	region := ""
+	if p.client != nil {
+		region = p.client.Options().Region
+	}
+
+	return &ScopedCredentials{
+		AccessKeyID:     aws.ToString(out.Credentials.AccessKeyId),
+		SecretAccessKey: aws.ToString(out.Credentials.SecretAccessKey),
+		SessionToken:    aws.ToString(out.Credentials.SessionToken),
+		Expiration:      aws.ToTime(out.Credentials.Expiration),
+		Bucket:          p.bucket,
+		Region:          region,
+	}, nil
+}
+
+func scopedSessionPolicy(resource string) (string, error) {
+	doc := map[string]any{
+		"Version": "2012-10-17",
+		"Statement": []map[string]any{
+			{
+				"Effect": "Allow",
+				"Action": []string{
+					"s3:PutObject",
+					"s3:AbortMultipartUpload",
+					"s3:ListMultipartUploadParts",
+				},
+				"Resource": "arn:aws:s3:::" + resource,
+			},
+		},
+	}
+
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return "", fmt.Errorf("aws provider: marshal session policy: %w", err)
+	}
+
+	return string(raw), nil
+}
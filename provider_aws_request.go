@@ -0,0 +1,109 @@
+package uploader
+
+import (
+	"context"
+	"maps"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// S3RequestOptions carries per-request settings needed by advanced bucket configurations. They
+// can be set for every call on the provider (WithAWSRequestOptions), per request through
+// the context (ContextWithS3RequestOptions) or per upload (WithS3RequestOptions); later sources
+// override earlier ones and headers are merged.
+type S3RequestOptions struct {
+	// RequestPayer acknowledges that the caller is charged for requests to a requester-pays bucket.
+	RequestPayer bool
+	// ExpectedBucketOwner makes S3 reject the request when the bucket belongs to another account.
+	ExpectedBucketOwner string
+	// Headers are added to every API call. They are not part of presigned requests.
+	Headers map[string]string
+}
+
+func (o S3RequestOptions) merge(override S3RequestOptions) S3RequestOptions {
+	out := o
+	out.RequestPayer = o.RequestPayer || override.RequestPayer
+	if override.ExpectedBucketOwner != "" {
+		out.ExpectedBucketOwner = override.ExpectedBucketOwner
+	}
+	if len(override.Headers) > 0 {
+		out.Headers = maps.Clone(o.Headers)
+		if out.Headers == nil {
+			out.Headers = make(map[string]string, len(override.Headers))
+		}
+		maps.Copy(out.Headers, override.Headers)
+	}
+	return out
+}
+
+func (o S3RequestOptions) requestPayer() types.RequestPayer {
+	if o.RequestPayer {
+		return types.RequestPayerRequester
+	}
+	return ""
+}
+
+func (o S3RequestOptions) bucketOwner() *string {
+	if o.ExpectedBucketOwner == "" {
+		return nil
+	}
+	return aws.String(o.ExpectedBucketOwner)
+}
+
+// clientOptions returns the per-operation option functions adding the custom headers.
+func (o S3RequestOptions) clientOptions() []func(*s3.Options) {
+	if len(o.Headers) == 0 {
+		return nil
+	}
+	return []func(*s3.Options){func(opts *s3.Options) {
+		for name, value := range o.Headers {
+			opts.APIOptions = append(opts.APIOptions, smithyhttp.SetHeaderValue(name, value))
+		}
+	}}
+}
+
+// WithS3RequestOptions applies S3 request settings to a single upload or chunked session.
+// Providers other than AWSProvider ignore them.
+func WithS3RequestOptions(opts S3RequestOptions) UploadOption {
+	return func(m *Metadata) { m.S3 = m.S3.merge(opts) }
+}
+
+type s3RequestOptionsContextKey struct{}
+
+// ContextWithS3RequestOptions attaches S3 request settings to ctx for calls that do not accept
+// upload options, such as GetFile, DeleteFile and GetPresignedURL.
+func ContextWithS3RequestOptions(ctx context.Context, opts S3RequestOptions) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, s3RequestOptionsContextKey{}, S3RequestOptionsFromContext(ctx).merge(opts))
+}
+
+// S3RequestOptionsFromContext returns the settings attached with ContextWithS3RequestOptions.
+func S3RequestOptionsFromContext(ctx context.Context) S3RequestOptions {
+	if ctx == nil {
+		return S3RequestOptions{}
+	}
+	opts, _ := ctx.Value(s3RequestOptionsContextKey{}).(S3RequestOptions)
+	return opts
+}
+
+// WithRequestOptions applies opts to every request the provider makes.
+//
+// Deprecated: pass WithAWSRequestOptions to NewAWSProvider.
+func (p *AWSProvider) WithRequestOptions(opts S3RequestOptions) *AWSProvider {
+	p.apply(WithAWSRequestOptions(opts))
+	return p
+}
+
+// requestOptions resolves the settings for one call from the provider defaults, ctx and md.
+func (p *AWSProvider) requestOptions(ctx context.Context, md *Metadata) S3RequestOptions {
+	opts := p.requestOpts.merge(S3RequestOptionsFromContext(ctx))
+	if md != nil {
+		opts = opts.merge(md.S3)
+	}
+	return opts
+}
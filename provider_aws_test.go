@@ -3,15 +3,23 @@ package uploader
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/signer/v4"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	ststypes "github.com/aws/aws-sdk-go-v2/service/sts/types"
+	"github.com/aws/smithy-go"
+	gerrors "github.com/goliatone/go-errors"
 )
 
 func TestAWSProviderValidate(t *testing.T) {
@@ -181,6 +189,55 @@ func TestAWSProviderCreatePresignedPost(t *testing.T) {
 	}
 }
 
+func TestAWSProviderCreatePresignedPostRedirect(t *testing.T) {
+	provider := NewAWSProvider(&s3.Client{}, "test-bucket")
+	provider.client = &fakeS3Client{
+		options: s3.Options{
+			Region: "us-east-1",
+			Credentials: aws.NewCredentialsCache(staticCredentialsProvider{
+				creds: aws.Credentials{AccessKeyID: "AKIA123456789", SecretAccessKey: "secret"},
+			}),
+		},
+	}
+	provider.now = func() time.Time { return time.Unix(1700000000, 0) }
+
+	post, err := provider.CreatePresignedPost(context.Background(), "uploads/test.jpg", &Metadata{
+		ContentType:     "image/jpeg",
+		TTL:             10 * time.Minute,
+		SuccessRedirect: "https://app.example.com/uploaded",
+		CallbackURL:     "https://api.example.com/hooks/upload",
+	})
+	if err != nil {
+		t.Fatalf("CreatePresignedPost returned error: %v", err)
+	}
+
+	if post.Fields["success_action_redirect"] != "https://app.example.com/uploaded" || post.Fields[PostCallbackURLField] != "https://api.example.com/hooks/upload" {
+		t.Fatalf("expected redirect and callback fields, got %v", post.Fields)
+	}
+	if _, ok := post.Fields["success_action_status"]; ok {
+		t.Fatalf("expected no success_action_status with a redirect")
+	}
+
+	raw, _ := base64.StdEncoding.DecodeString(post.Fields["Policy"])
+	var policy struct {
+		Conditions []any `json:"conditions"`
+	}
+	if err := json.Unmarshal(raw, &policy); err != nil {
+		t.Fatalf("decode policy: %v", err)
+	}
+	signed := map[string]any{}
+	for _, condition := range policy.Conditions {
+		if fields, ok := condition.(map[string]any); ok {
+			for name, value := range fields {
+				signed[name] = value
+			}
+		}
+	}
+	if signed["success_action_redirect"] != "https://app.example.com/uploaded" || signed[PostCallbackURLField] != "https://api.example.com/hooks/upload" {
+		t.Fatalf("expected redirect and callback in the signed policy, got %v", policy.Conditions)
+	}
+}
+
 type mockAWSProvider struct {
 	*AWSProvider
 	uploadFunc       func(ctx context.Context, path string, content []byte, opts ...UploadOption) (string, error)
@@ -396,13 +453,50 @@ type fakeS3Client struct {
 	abortCalled             bool
 	lastCompletedParts      []types.CompletedPart
 	options                 s3.Options
+	listPages               []*s3.ListObjectsV2Output
+	listInputs              []*s3.ListObjectsV2Input
+	multipartUploads        []types.MultipartUpload
+	abortedUploads          []string
+	putInputs               []*s3.PutObjectInput
+	putOptFns               [][]func(*s3.Options)
+	putErr                  error
+	getInputs               []*s3.GetObjectInput
+	headOutput              *s3.HeadObjectOutput
+	headErr                 error
+	getErr                  error
+	copyInputs              []*s3.CopyObjectInput
+	partCopyInputs          []*s3.UploadPartCopyInput
+	uploadPartBodies        [][]byte
+	restoreInputs           []*s3.RestoreObjectInput
+	restoreErr              error
+	legalHoldInputs         []*s3.PutObjectLegalHoldInput
+	retentionInputs         []*s3.PutObjectRetentionInput
+	createMultipartInputs   []*s3.CreateMultipartUploadInput
+	deleteObjectsInputs     []*s3.DeleteObjectsInput
+	deleteObjectsErrors     []types.Error
 }
 
-func (f *fakeS3Client) PutObject(context.Context, *s3.PutObjectInput, ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+func (f *fakeS3Client) PutObject(_ context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	f.putInputs = append(f.putInputs, params)
+	f.putOptFns = append(f.putOptFns, optFns)
+	if f.putErr != nil {
+		return nil, f.putErr
+	}
 	return &s3.PutObjectOutput{}, nil
 }
 
-func (f *fakeS3Client) GetObject(context.Context, *s3.GetObjectInput, ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+func (f *fakeS3Client) HeadObject(context.Context, *s3.HeadObjectInput, ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	if f.headErr != nil {
+		return nil, f.headErr
+	}
+	return f.headOutput, nil
+}
+
+func (f *fakeS3Client) GetObject(_ context.Context, params *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	f.getInputs = append(f.getInputs, params)
+	if f.getErr != nil {
+		return nil, f.getErr
+	}
 	return &s3.GetObjectOutput{
 		Body: io.NopCloser(bytes.NewReader([]byte("data"))),
 	}, nil
@@ -412,17 +506,36 @@ func (f *fakeS3Client) DeleteObject(context.Context, *s3.DeleteObjectInput, ...f
 	return &s3.DeleteObjectOutput{}, nil
 }
 
+func (f *fakeS3Client) DeleteObjects(_ context.Context, params *s3.DeleteObjectsInput, _ ...func(*s3.Options)) (*s3.DeleteObjectsOutput, error) {
+	f.deleteObjectsInputs = append(f.deleteObjectsInputs, params)
+	out := &s3.DeleteObjectsOutput{Errors: f.deleteObjectsErrors}
+	f.deleteObjectsErrors = nil
+	return out, nil
+}
+
+func (f *fakeS3Client) ListObjectsV2(_ context.Context, params *s3.ListObjectsV2Input, _ ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	f.listInputs = append(f.listInputs, params)
+	if len(f.listPages) == 0 {
+		return &s3.ListObjectsV2Output{}, nil
+	}
+	page := f.listPages[0]
+	f.listPages = f.listPages[1:]
+	return page, nil
+}
+
 func (f *fakeS3Client) HeadBucket(context.Context, *s3.HeadBucketInput, ...func(*s3.Options)) (*s3.HeadBucketOutput, error) {
 	return &s3.HeadBucketOutput{}, nil
 }
 
-func (f *fakeS3Client) CreateMultipartUpload(context.Context, *s3.CreateMultipartUploadInput, ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+func (f *fakeS3Client) CreateMultipartUpload(_ context.Context, params *s3.CreateMultipartUploadInput, _ ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	f.createMultipartInputs = append(f.createMultipartInputs, params)
 	return f.createMultipartOutput, nil
 }
 
 func (f *fakeS3Client) UploadPart(_ context.Context, params *s3.UploadPartInput, _ ...func(*s3.Options)) (*s3.UploadPartOutput, error) {
 	if params.Body != nil {
-		_, _ = io.ReadAll(params.Body)
+		body, _ := io.ReadAll(params.Body)
+		f.uploadPartBodies = append(f.uploadPartBodies, body)
 	}
 	return f.uploadPartOutput, nil
 }
@@ -434,11 +547,44 @@ func (f *fakeS3Client) CompleteMultipartUpload(_ context.Context, params *s3.Com
 	return f.completeMultipartOutput, nil
 }
 
-func (f *fakeS3Client) AbortMultipartUpload(context.Context, *s3.AbortMultipartUploadInput, ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+func (f *fakeS3Client) AbortMultipartUpload(_ context.Context, params *s3.AbortMultipartUploadInput, _ ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
 	f.abortCalled = true
+	f.abortedUploads = append(f.abortedUploads, aws.ToString(params.UploadId))
 	return f.abortMultipartOutput, nil
 }
 
+func (f *fakeS3Client) ListMultipartUploads(context.Context, *s3.ListMultipartUploadsInput, ...func(*s3.Options)) (*s3.ListMultipartUploadsOutput, error) {
+	return &s3.ListMultipartUploadsOutput{Uploads: f.multipartUploads}, nil
+}
+
+func (f *fakeS3Client) CopyObject(_ context.Context, params *s3.CopyObjectInput, _ ...func(*s3.Options)) (*s3.CopyObjectOutput, error) {
+	f.copyInputs = append(f.copyInputs, params)
+	return &s3.CopyObjectOutput{}, nil
+}
+
+func (f *fakeS3Client) UploadPartCopy(_ context.Context, params *s3.UploadPartCopyInput, _ ...func(*s3.Options)) (*s3.UploadPartCopyOutput, error) {
+	f.partCopyInputs = append(f.partCopyInputs, params)
+	return &s3.UploadPartCopyOutput{CopyPartResult: &types.CopyPartResult{ETag: aws.String(fmt.Sprintf("copy-%d", aws.ToInt32(params.PartNumber)))}}, nil
+}
+
+func (f *fakeS3Client) RestoreObject(_ context.Context, params *s3.RestoreObjectInput, _ ...func(*s3.Options)) (*s3.RestoreObjectOutput, error) {
+	f.restoreInputs = append(f.restoreInputs, params)
+	if f.restoreErr != nil {
+		return nil, f.restoreErr
+	}
+	return &s3.RestoreObjectOutput{}, nil
+}
+
+func (f *fakeS3Client) PutObjectLegalHold(_ context.Context, params *s3.PutObjectLegalHoldInput, _ ...func(*s3.Options)) (*s3.PutObjectLegalHoldOutput, error) {
+	f.legalHoldInputs = append(f.legalHoldInputs, params)
+	return &s3.PutObjectLegalHoldOutput{}, nil
+}
+
+func (f *fakeS3Client) PutObjectRetention(_ context.Context, params *s3.PutObjectRetentionInput, _ ...func(*s3.Options)) (*s3.PutObjectRetentionOutput, error) {
+	f.retentionInputs = append(f.retentionInputs, params)
+	return &s3.PutObjectRetentionOutput{}, nil
+}
+
 func (f *fakeS3Client) Options() s3.Options {
 	return f.options
 }
@@ -455,3 +601,384 @@ type staticCredentialsProvider struct {
 func (s staticCredentialsProvider) Retrieve(context.Context) (aws.Credentials, error) {
 	return s.creds, nil
 }
+
+func TestAWSProviderPresignChunkedRequests(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeS3Client{
+		options: s3.Options{
+			Region: "eu-west-1",
+			Credentials: aws.NewCredentialsCache(staticCredentialsProvider{
+				creds: aws.Credentials{
+					AccessKeyID:     "AKIA123456789",
+					SecretAccessKey: "secret",
+				},
+			}),
+		},
+	}
+
+	provider := &AWSProvider{
+		client:    client,
+		bucket:    "test-bucket",
+		logger:    &DefaultLogger{},
+		presigner: &fakePresignClient{},
+		now: func() time.Time {
+			return time.Unix(1700000000, 0)
+		},
+	}
+
+	session := &ChunkSession{
+		ID:           "aws-presign",
+		Key:          "chunks/big.bin",
+		ProviderData: map[string]any{awsUploadIDKey: "upload-123"},
+	}
+
+	part, err := provider.PresignChunkPart(ctx, session, 2, time.Minute)
+	if err != nil {
+		t.Fatalf("PresignChunkPart failed: %v", err)
+	}
+
+	if !strings.Contains(part.URL, "partNumber=3") {
+		t.Fatalf("expected part number 3 in presigned url, got %s", part.URL)
+	}
+
+	complete, err := provider.PresignCompleteChunked(ctx, session, time.Minute)
+	if err != nil {
+		t.Fatalf("PresignCompleteChunked failed: %v", err)
+	}
+
+	if complete.Method != "POST" {
+		t.Fatalf("expected POST method, got %s", complete.Method)
+	}
+
+	if !strings.HasPrefix(complete.URL, "https://test-bucket.s3.eu-west-1.amazonaws.com/chunks/big.bin?") {
+		t.Fatalf("unexpected completion url: %s", complete.URL)
+	}
+
+	if !strings.Contains(complete.URL, "uploadId=upload-123") || !strings.Contains(complete.URL, "X-Amz-Signature=") {
+		t.Fatalf("expected signed completion url with upload id, got %s", complete.URL)
+	}
+}
+
+type fakePresignClient struct{}
+
+func (fakePresignClient) PresignGetObject(_ context.Context, params *s3.GetObjectInput, _ ...func(*s3.PresignOptions)) (*v4.PresignedHTTPRequest, error) {
+	return &v4.PresignedHTTPRequest{
+		URL:    "https://example.com/" + aws.ToString(params.Key),
+		Method: "GET",
+	}, nil
+}
+
+func (fakePresignClient) PresignUploadPart(_ context.Context, params *s3.UploadPartInput, _ ...func(*s3.PresignOptions)) (*v4.PresignedHTTPRequest, error) {
+	return &v4.PresignedHTTPRequest{
+		URL:    fmt.Sprintf("https://example.com/%s?partNumber=%d&uploadId=%s", aws.ToString(params.Key), aws.ToInt32(params.PartNumber), aws.ToString(params.UploadId)),
+		Method: "PUT",
+	}, nil
+}
+
+func TestAWSProviderScopedCredentials(t *testing.T) {
+	ctx := context.Background()
+	expiration := time.Unix(1700003600, 0)
+	fakeSTS := &fakeSTSClient{
+		output: &sts.AssumeRoleOutput{
+			Credentials: &ststypes.Credentials{
+				AccessKeyId:     aws.String("ASIA123"),
+				SecretAccessKey: aws.String("scoped-secret"),
+				SessionToken:    aws.String("scoped-token"),
+				Expiration:      aws.Time(expiration),
+			},
+		},
+	}
+
+	provider := &AWSProvider{
+		client:   &fakeS3Client{options: s3.Options{Region: "eu-west-1"}},
+		bucket:   "test-bucket",
+		basePath: "tenant-a",
+		logger:   &DefaultLogger{},
+		sts:      fakeSTS,
+		roleARN:  "arn:aws:iam::123456789012:role/uploader",
+	}
+
+	if _, err := provider.ScopedCredentials(ctx, "uploads/", time.Minute); !gerrors.IsValidation(err) {
+		t.Fatalf("expected a ttl below the STS minimum to be rejected, got %v", err)
+	}
+
+	creds, err := provider.ScopedCredentials(ctx, "uploads", 0)
+	if err != nil {
+		t.Fatalf("ScopedCredentials failed: %v", err)
+	}
+
+	if creds.AccessKeyID != "ASIA123" || creds.SessionToken != "scoped-token" || !creds.Expiration.Equal(expiration) {
+		t.Fatalf("unexpected credentials: %#v", creds)
+	}
+
+	if creds.Prefix != "tenant-a/uploads" || creds.Region != "eu-west-1" {
+		t.Fatalf("unexpected scope: prefix=%s region=%s", creds.Prefix, creds.Region)
+	}
+
+	input := fakeSTS.lastInput
+	if aws.ToInt32(input.DurationSeconds) != int32(minAssumeRoleDuration/time.Second) {
+		t.Fatalf("expected a zero ttl to request the minimum duration, got %d", aws.ToInt32(input.DurationSeconds))
+	}
+
+	// The prefix must end at a segment boundary, so "uploads" does not grant "uploads-private/".
+	if !strings.Contains(aws.ToString(input.Policy), `"arn:aws:s3:::test-bucket/tenant-a/uploads/*"`) {
+		t.Fatalf("expected session policy scoped to the prefix segment, got %s", aws.ToString(input.Policy))
+	}
+}
+
+func TestAWSProviderPresignedPostCredentialsScopedToKey(t *testing.T) {
+	fakeSTS := &fakeSTSClient{
+		output: &sts.AssumeRoleOutput{
+			Credentials: &ststypes.Credentials{
+				AccessKeyId:     aws.String("ASIA123"),
+				SecretAccessKey: aws.String("scoped-secret"),
+				SessionToken:    aws.String("scoped-token"),
+				Expiration:      aws.Time(time.Unix(1700000900, 0)),
+			},
+		},
+	}
+	provider := &AWSProvider{
+		client: &fakeS3Client{options: s3.Options{
+			Region:      "eu-west-1",
+			Credentials: aws.NewCredentialsCache(staticCredentialsProvider{creds: aws.Credentials{AccessKeyID: "AKIA", SecretAccessKey: "secret"}}),
+		}},
+		bucket:   "test-bucket",
+		basePath: "tenant-a",
+		logger:   &DefaultLogger{},
+		sts:      fakeSTS,
+		roleARN:  "arn:aws:iam::123456789012:role/uploader",
+		now:      func() time.Time { return time.Unix(1700000000, 0) },
+	}
+
+	post, err := provider.CreatePresignedPost(context.Background(), "uploads/test.jpg", &Metadata{TTL: 15 * time.Minute})
+	if err != nil {
+		t.Fatalf("CreatePresignedPost returned error: %v", err)
+	}
+	if post.Credentials == nil || post.Credentials.Key != "tenant-a/uploads/test.jpg" || post.Credentials.Prefix != "" {
+		t.Fatalf("expected credentials scoped to the key, got %#v", post.Credentials)
+	}
+	if policy := aws.ToString(fakeSTS.lastInput.Policy); !strings.Contains(policy, `"arn:aws:s3:::test-bucket/tenant-a/uploads/test.jpg"`) {
+		t.Fatalf("expected session policy for the exact key, got %s", policy)
+	}
+
+	if _, err := provider.CreatePresignedPost(context.Background(), "uploads/test.jpg", &Metadata{TTL: 5 * time.Minute}); !gerrors.IsValidation(err) {
+		t.Fatalf("expected a post ttl below the STS minimum to be rejected, got %v", err)
+	}
+}
+
+func TestAWSProviderWithScopedCredentialsValidates(t *testing.T) {
+	provider := NewAWSProvider(&s3.Client{}, "bucket").WithScopedCredentials(nil, "")
+	if err := provider.Validate(context.Background()); err == nil || !strings.Contains(err.Error(), "sts client is nil") {
+		t.Fatalf("expected the deprecated builder to share the option validation, got %v", err)
+	}
+}
+
+func TestAWSProviderScopedCredentialsNotConfigured(t *testing.T) {
+	provider := &AWSProvider{bucket: "test-bucket"}
+
+	if _, err := provider.ScopedCredentials(context.Background(), "uploads/", time.Hour); !errors.Is(err, ErrNotImplemented) {
+		t.Fatalf("expected ErrNotImplemented, got %v", err)
+	}
+}
+
+type fakeSTSClient struct {
+	output    *sts.AssumeRoleOutput
+	lastInput *sts.AssumeRoleInput
+}
+
+func (f *fakeSTSClient) AssumeRole(_ context.Context, params *sts.AssumeRoleInput, _ ...func(*sts.Options)) (*sts.AssumeRoleOutput, error) {
+	f.lastInput = params
+	return f.output, nil
+}
+
+func TestAWSProviderList(t *testing.T) {
+	client := &fakeS3Client{
+		listPages: []*s3.ListObjectsV2Output{
+			{
+				Contents: []types.Object{
+					{Key: aws.String("uploads/images/a.png"), Size: aws.Int64(10), ETag: aws.String(`"etag-a"`)},
+				},
+				IsTruncated:           aws.Bool(true),
+				NextContinuationToken: aws.String("next"),
+			},
+			{
+				Contents: []types.Object{
+					{Key: aws.String("uploads/images/b.png"), Size: aws.Int64(20)},
+				},
+			},
+		},
+	}
+
+	provider := &AWSProvider{client: client, bucket: "test-bucket", basePath: "uploads"}
+
+	objects, err := provider.List(context.Background(), "images/")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+
+	if len(objects) != 2 || objects[0].Key != "images/a.png" || objects[1].Key != "images/b.png" {
+		t.Fatalf("unexpected objects: %#v", objects)
+	}
+
+	if objects[0].ETag != "etag-a" {
+		t.Fatalf("expected unquoted etag, got %s", objects[0].ETag)
+	}
+
+	if aws.ToString(client.listInputs[0].Prefix) != "uploads/images/" {
+		t.Fatalf("expected base path prefix, got %s", aws.ToString(client.listInputs[0].Prefix))
+	}
+
+	if aws.ToString(client.listInputs[1].ContinuationToken) != "next" {
+		t.Fatalf("expected continuation token on second page")
+	}
+}
+
+func TestAWSProviderListPage(t *testing.T) {
+	client := &fakeS3Client{
+		listPages: []*s3.ListObjectsV2Output{
+			{
+				Contents:    []types.Object{{Key: aws.String("uploads/images/c.png"), Size: aws.Int64(30)}},
+				IsTruncated: aws.Bool(true),
+			},
+		},
+	}
+
+	provider := &AWSProvider{client: client, bucket: "test-bucket", basePath: "uploads"}
+
+	objects, more, err := provider.ListPage(context.Background(), "images/", "images/b.png", 1)
+	if err != nil {
+		t.Fatalf("ListPage failed: %v", err)
+	}
+
+	if len(objects) != 1 || objects[0].Key != "images/c.png" || !more {
+		t.Fatalf("unexpected page: %#v more=%v", objects, more)
+	}
+
+	input := client.listInputs[0]
+	if aws.ToString(input.StartAfter) != "uploads/images/b.png" || aws.ToInt32(input.MaxKeys) != 1 {
+		t.Fatalf("expected start after and max keys, got %s %d", aws.ToString(input.StartAfter), aws.ToInt32(input.MaxKeys))
+	}
+}
+
+func TestAWSProviderCollectGarbage(t *testing.T) {
+	now := time.Now()
+	client := &fakeS3Client{
+		multipartUploads: []types.MultipartUpload{
+			{Key: aws.String("uploads/old.bin"), UploadId: aws.String("old"), Initiated: aws.Time(now.Add(-48 * time.Hour))},
+			{Key: aws.String("uploads/new.bin"), UploadId: aws.String("new"), Initiated: aws.Time(now)},
+		},
+		abortMultipartOutput: &s3.AbortMultipartUploadOutput{},
+	}
+
+	provider := &AWSProvider{client: client, bucket: "test-bucket", basePath: "uploads"}
+
+	aborted, err := provider.CollectGarbage(context.Background(), now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("CollectGarbage failed: %v", err)
+	}
+
+	if aborted != 1 || len(client.abortedUploads) != 1 || client.abortedUploads[0] != "old" {
+		t.Fatalf("expected only the stale upload to be aborted, got %d %v", aborted, client.abortedUploads)
+	}
+}
+
+func TestAWSProviderConditionalWrites(t *testing.T) {
+	client := &fakeS3Client{
+		putErr: &smithy.GenericAPIError{Code: "PreconditionFailed", Message: "At least one of the pre-conditions you specified did not hold"},
+	}
+	provider := &AWSProvider{client: client, bucket: "test-bucket", logger: &DefaultLogger{}}
+
+	_, err := provider.UploadFile(context.Background(), "a.png", []byte("data"), WithIfNotExists())
+	if !errors.Is(err, ErrFileExists) {
+		t.Fatalf("expected ErrFileExists, got %v", err)
+	}
+
+	if aws.ToString(client.putInputs[0].IfNoneMatch) != "*" {
+		t.Fatalf("expected If-None-Match header, got %v", client.putInputs[0].IfNoneMatch)
+	}
+}
+
+func TestAWSProviderStatFile(t *testing.T) {
+	client := &fakeS3Client{
+		headOutput: &s3.HeadObjectOutput{ContentLength: aws.Int64(42), ETag: aws.String("\"etag\"")},
+	}
+	provider := &AWSProvider{client: client, bucket: "test-bucket"}
+
+	info, err := provider.StatFile(context.Background(), "a.png")
+	if err != nil {
+		t.Fatalf("StatFile failed: %v", err)
+	}
+	if info.Key != "a.png" || info.Size != 42 || info.ETag != "etag" {
+		t.Fatalf("unexpected info: %#v", info)
+	}
+
+	client.headErr = &types.NotFound{}
+	if _, err := provider.StatFile(context.Background(), "missing.png"); !errors.Is(err, ErrImageNotFound) {
+		t.Fatalf("expected ErrImageNotFound, got %v", err)
+	}
+}
+
+func TestAWSProviderRequestOptions(t *testing.T) {
+	client := &fakeS3Client{}
+	provider := NewAWSProvider(&s3.Client{}, "shared-bucket").WithRequestOptions(S3RequestOptions{
+		ExpectedBucketOwner: "111111111111",
+		Headers:             map[string]string{"X-Gateway": "default"},
+	})
+	provider.client = client
+
+	ctx := ContextWithS3RequestOptions(context.Background(), S3RequestOptions{RequestPayer: true})
+	if _, err := provider.UploadFile(ctx, "a.txt", []byte("data"),
+		WithS3RequestOptions(S3RequestOptions{
+			ExpectedBucketOwner: "222222222222",
+			Headers:             map[string]string{"X-Tenant": "acme"},
+		}),
+	); err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+
+	put := client.putInputs[0]
+	if put.RequestPayer != types.RequestPayerRequester || aws.ToString(put.ExpectedBucketOwner) != "222222222222" {
+		t.Fatalf("unexpected put input: payer=%q owner=%q", put.RequestPayer, aws.ToString(put.ExpectedBucketOwner))
+	}
+
+	var opts s3.Options
+	for _, fn := range client.putOptFns[0] {
+		fn(&opts)
+	}
+	if len(opts.APIOptions) != 2 {
+		t.Fatalf("expected a header middleware per custom header, got %d", len(opts.APIOptions))
+	}
+
+	if _, err := provider.GetFile(context.Background(), "a.txt"); err != nil {
+		t.Fatalf("GetFile: %v", err)
+	}
+	get := client.getInputs[0]
+	if get.RequestPayer != "" || aws.ToString(get.ExpectedBucketOwner) != "111111111111" {
+		t.Fatalf("expected provider defaults only, got payer=%q owner=%q", get.RequestPayer, aws.ToString(get.ExpectedBucketOwner))
+	}
+}
+
+func TestAWSProviderOpenRangeHeader(t *testing.T) {
+	client := &fakeS3Client{}
+	provider := NewAWSProvider(&s3.Client{}, "test-bucket")
+	provider.client = client
+
+	ranges := []struct {
+		offset, length int64
+		want           string
+	}{
+		{0, -1, ""},
+		{5, -1, "bytes=5-"},
+		{2, 4, "bytes=2-5"},
+	}
+	for i, tt := range ranges {
+		rc, err := provider.OpenRange(context.Background(), "a.txt", tt.offset, tt.length)
+		if err != nil {
+			t.Fatalf("OpenRange: %v", err)
+		}
+		rc.Close()
+		if got := aws.ToString(client.getInputs[i].Range); got != tt.want {
+			t.Fatalf("OpenRange(%d, %d) range = %q, want %q", tt.offset, tt.length, got, tt.want)
+		}
+	}
+}
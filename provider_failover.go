@@ -0,0 +1,427 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	_ Uploader          = &FailoverProvider{}
+	_ ProviderDescriber = &FailoverProvider{}
+	_ ProviderValidator = &FailoverProvider{}
+	_ Lister            = &FailoverProvider{}
+	_ FileStatter       = &FailoverProvider{}
+	_ RangeReader       = &FailoverProvider{}
+	_ Replicator        = &FailoverProvider{}
+)
+
+// FailoverReadPolicy decides which providers FailoverProvider reads from.
+type FailoverReadPolicy string
+
+const (
+	// FailoverReadCascade tries every provider in order until one returns the object.
+	FailoverReadCascade FailoverReadPolicy = "cascade"
+	// FailoverReadPrimary only reads from the primary.
+	FailoverReadPrimary FailoverReadPolicy = "primary"
+)
+
+// FailoverWritePolicy decides where FailoverProvider writes when the primary fails.
+type FailoverWritePolicy string
+
+const (
+	// FailoverWriteFirstAvailable writes to the first provider that accepts the write. The
+	// others, including a primary that was down, are brought up to date asynchronously.
+	FailoverWriteFirstAvailable FailoverWritePolicy = "first_available"
+	// FailoverWritePrimary fails writes while the primary is unavailable.
+	FailoverWritePrimary FailoverWritePolicy = "primary"
+)
+
+// FailoverProvider spreads operations over an ordered list of providers so a deployment can
+// survive an outage of its primary store. Writes go to one provider and are copied to the rest
+// in the background ("catch-up"); copies that fail stay pending until CatchUp succeeds.
+type FailoverProvider struct {
+	logger    Logger
+	providers []Uploader
+	reads     FailoverReadPolicy
+	writes    FailoverWritePolicy
+	optionErr error
+
+	mu      sync.Mutex
+	pending map[string]failoverSync
+	seq     uint64
+	wg      sync.WaitGroup
+}
+
+// failoverSync records the providers a key still has to be copied to (or deleted from). seq
+// identifies the write it belongs to, so a stale catch-up never clears a newer one.
+type failoverSync struct {
+	source  int
+	targets []int
+	delete  bool
+	seq     uint64
+}
+
+// NewFailoverProvider creates a provider that prefers primary and falls back to fallbacks in
+// order. Use NewFailoverProviderWithOptions to configure it.
+func NewFailoverProvider(primary Uploader, fallbacks ...Uploader) *FailoverProvider {
+	return NewFailoverProviderWithOptions(primary, fallbacks)
+}
+
+// NewFailoverProviderWithOptions creates a provider that prefers primary and falls back to
+// fallbacks in order, configured by opts.
+func NewFailoverProviderWithOptions(primary Uploader, fallbacks []Uploader, opts ...FailoverProviderOption) *FailoverProvider {
+	p := &FailoverProvider{
+		logger:    &DefaultLogger{},
+		providers: append([]Uploader{primary}, fallbacks...),
+		reads:     FailoverReadCascade,
+		writes:    FailoverWriteFirstAvailable,
+		pending:   make(map[string]failoverSync),
+	}
+	p.apply(opts...)
+	return p
+}
+
+// Deprecated: pass WithFailoverLogger to NewFailoverProviderWithOptions.
+func (p *FailoverProvider) WithLogger(l Logger) *FailoverProvider {
+	p.apply(WithFailoverLogger(l))
+	return p
+}
+
+// WithReadPolicy sets how GetFile, StatFile and GetPresignedURL pick a provider.
+//
+// Deprecated: pass WithFailoverReadPolicy to NewFailoverProviderWithOptions.
+func (p *FailoverProvider) WithReadPolicy(policy FailoverReadPolicy) *FailoverProvider {
+	p.apply(WithFailoverReadPolicy(policy))
+	return p
+}
+
+// WithWritePolicy sets how UploadFile and DeleteFile react to a failing primary.
+//
+// Deprecated: pass WithFailoverWritePolicy to NewFailoverProviderWithOptions.
+func (p *FailoverProvider) WithWritePolicy(policy FailoverWritePolicy) *FailoverProvider {
+	p.apply(WithFailoverWritePolicy(policy))
+	return p
+}
+
+func (p *FailoverProvider) UploadFile(ctx context.Context, path string, content []byte, opts ...UploadOption) (string, error) {
+	var url string
+	source, err := p.write(ctx, path, func(provider Uploader) error {
+		var err error
+		url, err = provider.UploadFile(ctx, path, content, opts...)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	p.catchUp(ctx, path, failoverSync{source: source, targets: p.others(source)}, func(ctx context.Context, target Uploader) error {
+		_, err := target.UploadFile(ctx, path, content, mirrorOptions(opts)...)
+		return err
+	})
+	return url, nil
+}
+
+func (p *FailoverProvider) DeleteFile(ctx context.Context, path string) error {
+	source, err := p.write(ctx, path, func(provider Uploader) error {
+		return provider.DeleteFile(ctx, path)
+	})
+	if err != nil {
+		return err
+	}
+
+	p.catchUp(ctx, path, failoverSync{source: source, targets: p.others(source), delete: true}, func(ctx context.Context, target Uploader) error {
+		return target.DeleteFile(ctx, path)
+	})
+	return nil
+}
+
+func (p *FailoverProvider) GetFile(ctx context.Context, path string) ([]byte, error) {
+	var content []byte
+	err := p.read(ctx, "get", path, func(provider Uploader) error {
+		var err error
+		content, err = provider.GetFile(ctx, path)
+		return err
+	})
+	return content, err
+}
+
+func (p *FailoverProvider) GetPresignedURL(ctx context.Context, path string, expires time.Duration) (string, error) {
+	var url string
+	err := p.read(ctx, "presign", path, func(provider Uploader) error {
+		var err error
+		url, err = provider.GetPresignedURL(ctx, path, expires)
+		return err
+	})
+	return url, err
+}
+
+// StatFile follows the read policy, skipping providers that cannot stat.
+func (p *FailoverProvider) StatFile(ctx context.Context, path string) (*ObjectInfo, error) {
+	var info *ObjectInfo
+	err := p.read(ctx, "stat", path, func(provider Uploader) error {
+		statter, ok := provider.(FileStatter)
+		if !ok {
+			return ErrNotImplemented
+		}
+		var err error
+		info, err = statter.StatFile(ctx, path)
+		return err
+	})
+	return info, err
+}
+
+// OpenRange follows the read policy, skipping providers that cannot stream ranges.
+func (p *FailoverProvider) OpenRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	var rc io.ReadCloser
+	err := p.read(ctx, "get_range", path, func(provider Uploader) error {
+		reader, ok := provider.(RangeReader)
+		if !ok {
+			return ErrNotImplemented
+		}
+		var err error
+		rc, err = reader.OpenRange(ctx, path, offset, length)
+		return err
+	})
+	return rc, err
+}
+
+// List follows the read policy, skipping providers that cannot list.
+func (p *FailoverProvider) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	err := p.read(ctx, "list", prefix, func(provider Uploader) error {
+		lister, ok := provider.(Lister)
+		if !ok {
+			return ErrNotImplemented
+		}
+		var err error
+		objects, err = lister.List(ctx, prefix)
+		return err
+	})
+	return objects, err
+}
+
+// Validate succeeds when at least one provider is usable, so the application can start while
+// its primary is down. Failing providers are logged.
+func (p *FailoverProvider) Validate(ctx context.Context) error {
+	if p.optionErr != nil {
+		return fmt.Errorf("failover provider: invalid option: %w", p.optionErr)
+	}
+
+	var errs []error
+	for i, provider := range p.providers {
+		if provider == nil {
+			return fmt.Errorf("failover provider: provider %d not configured", i)
+		}
+		if err := validateOptional(ctx, provider); err != nil {
+			contextLogger(ctx, p.logger).Error("failover provider validation failed", err, "provider", describeProvider(provider, i))
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) == len(p.providers) {
+		return fmt.Errorf("failover provider: no provider available: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+func (p *FailoverProvider) ProviderName() string {
+	return "failover:" + describeProvider(p.providers[0], 0)
+}
+
+func (p *FailoverProvider) ProviderKey(path string) string {
+	if describer, ok := p.providers[0].(ProviderDescriber); ok {
+		return describer.ProviderKey(path)
+	}
+	return path
+}
+
+// Replicas names the fallback providers kept in sync with the primary.
+func (p *FailoverProvider) Replicas() []string {
+	out := make([]string, 0, len(p.providers)-1)
+	for i, provider := range p.providers[1:] {
+		out = append(out, describeProvider(provider, i+1))
+	}
+	return out
+}
+
+// Pending returns the keys whose catch-up copy or delete has not reached every provider yet.
+func (p *FailoverProvider) Pending() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	keys := make([]string, 0, len(p.pending))
+	for key := range p.pending {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// CatchUp retries pending copies and deletes, reading content from the provider that accepted
+// the original write. It returns how many keys are fully in sync afterwards.
+func (p *FailoverProvider) CatchUp(ctx context.Context) (int, error) {
+	p.mu.Lock()
+	work := make(map[string]failoverSync, len(p.pending))
+	for key, entry := range p.pending {
+		work[key] = entry
+	}
+	p.mu.Unlock()
+
+	synced := 0
+	var errs []error
+	for key, entry := range work {
+		if err := ctx.Err(); err != nil {
+			return synced, err
+		}
+
+		apply := func(ctx context.Context, target Uploader) error {
+			return target.DeleteFile(ctx, key)
+		}
+		if !entry.delete {
+			content, err := p.providers[entry.source].GetFile(ctx, key)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failover provider: read %s: %w", key, err))
+				continue
+			}
+			apply = func(ctx context.Context, target Uploader) error {
+				_, err := target.UploadFile(ctx, key, content)
+				return err
+			}
+		}
+
+		if err := p.sync(ctx, key, entry, apply); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		synced++
+	}
+
+	return synced, errors.Join(errs...)
+}
+
+// Wait blocks until in-flight background catch-up finishes, e.g. before shutdown.
+func (p *FailoverProvider) Wait() {
+	p.wg.Wait()
+}
+
+// write runs op against the primary or, when the write policy allows it, the first provider
+// that accepts it. It returns the index of that provider.
+func (p *FailoverProvider) write(ctx context.Context, path string, op func(Uploader) error) (int, error) {
+	var errs []error
+	for i, provider := range p.providers {
+		err := op(provider)
+		if err == nil {
+			if i > 0 {
+				contextLogger(ctx, p.logger).Info("failover provider wrote to fallback", "path", path, "provider", describeProvider(provider, i))
+			}
+			return i, nil
+		}
+
+		errs = append(errs, err)
+		if p.writes == FailoverWritePrimary || !shouldFailover(ctx, err) {
+			break
+		}
+		contextLogger(ctx, p.logger).Error("failover provider write failed", err, "path", path, "provider", describeProvider(provider, i))
+	}
+	return -1, errors.Join(errs...)
+}
+
+// read runs op against providers in order until one succeeds. Missing objects cascade too: the
+// key may only exist on a fallback until catch-up completes.
+func (p *FailoverProvider) read(ctx context.Context, op, path string, fn func(Uploader) error) error {
+	var lastErr error
+	for i, provider := range p.providers {
+		err := fn(provider)
+		if err == nil {
+			return nil
+		}
+
+		if lastErr == nil || !errors.Is(err, ErrNotImplemented) {
+			lastErr = err
+		}
+		if p.reads == FailoverReadPrimary || !shouldFailover(ctx, err) {
+			break
+		}
+		if !errors.Is(err, ErrImageNotFound) && !errors.Is(err, ErrNotImplemented) {
+			contextLogger(ctx, p.logger).Error("failover provider read failed", err, "operation", op, "path", path, "provider", describeProvider(provider, i))
+		}
+	}
+	return lastErr
+}
+
+// catchUp records entry as pending and applies it in the background with a context detached
+// from the request.
+func (p *FailoverProvider) catchUp(ctx context.Context, key string, entry failoverSync, apply func(context.Context, Uploader) error) {
+	if len(entry.targets) == 0 {
+		return
+	}
+
+	p.mu.Lock()
+	p.seq++
+	entry.seq = p.seq
+	p.pending[key] = entry
+	p.mu.Unlock()
+
+	ctx = context.WithoutCancel(ctx)
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		if err := p.sync(ctx, key, entry, apply); err != nil {
+			contextLogger(ctx, p.logger).Error("failover provider catch-up failed", err, "path", key)
+		}
+	}()
+}
+
+// sync applies entry to each target, keeping the targets that failed pending. A newer write to
+// the same key replaces the entry and is left alone.
+func (p *FailoverProvider) sync(ctx context.Context, key string, entry failoverSync, apply func(context.Context, Uploader) error) error {
+	var failed []int
+	var errs []error
+	for _, target := range entry.targets {
+		if err := apply(ctx, p.providers[target]); err != nil {
+			failed = append(failed, target)
+			errs = append(errs, fmt.Errorf("failover provider: sync %s to %s: %w", key, describeProvider(p.providers[target], target), err))
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if current, ok := p.pending[key]; ok && current.seq == entry.seq {
+		if len(failed) == 0 {
+			delete(p.pending, key)
+		} else {
+			entry.targets = failed
+			p.pending[key] = entry
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (p *FailoverProvider) others(source int) []int {
+	out := make([]int, 0, len(p.providers)-1)
+	for i := range p.providers {
+		if i != source {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// shouldFailover reports whether err is worth retrying on another provider. Conflicts and
+// cancelled requests would fail the same way everywhere.
+func shouldFailover(ctx context.Context, err error) bool {
+	return ctx.Err() == nil && !errors.Is(err, ErrFileExists)
+}
+
+func describeProvider(provider Uploader, index int) string {
+	if describer, ok := provider.(ProviderDescriber); ok {
+		return describer.ProviderName()
+	}
+	return fmt.Sprintf("provider-%d", index)
+}
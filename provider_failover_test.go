@@ -0,0 +1,136 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyStore is an in-memory provider that can be switched off to simulate an outage.
+type flakyStore struct {
+	mu    sync.Mutex
+	files map[string][]byte
+	down  bool
+}
+
+func newFlakyStore() *flakyStore {
+	return &flakyStore{files: make(map[string][]byte)}
+}
+
+func (s *flakyStore) setDown(down bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.down = down
+}
+
+func (s *flakyStore) has(path string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.files[path]
+	return ok
+}
+
+func (s *flakyStore) UploadFile(_ context.Context, path string, content []byte, _ ...UploadOption) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.down {
+		return "", errors.New("store unavailable")
+	}
+	s.files[path] = append([]byte(nil), content...)
+	return "/" + path, nil
+}
+
+func (s *flakyStore) GetFile(_ context.Context, path string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.down {
+		return nil, errors.New("store unavailable")
+	}
+	content, ok := s.files[path]
+	if !ok {
+		return nil, ErrImageNotFound
+	}
+	return content, nil
+}
+
+func (s *flakyStore) DeleteFile(_ context.Context, path string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.down {
+		return errors.New("store unavailable")
+	}
+	delete(s.files, path)
+	return nil
+}
+
+func (s *flakyStore) GetPresignedURL(_ context.Context, path string, _ time.Duration) (string, error) {
+	return "/" + path, nil
+}
+
+func TestFailoverProviderCatchUp(t *testing.T) {
+	ctx := context.Background()
+	primary, fallback := newFlakyStore(), newFlakyStore()
+	provider := NewFailoverProviderWithOptions(primary, []Uploader{fallback}, WithFailoverLogger(&mockLogger{}))
+
+	if _, err := provider.UploadFile(ctx, "a.txt", []byte("a")); err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	provider.Wait()
+	if !primary.has("a.txt") || !fallback.has("a.txt") {
+		t.Fatalf("expected write to reach both providers")
+	}
+
+	primary.setDown(true)
+	if _, err := provider.UploadFile(ctx, "b.txt", []byte("b")); err != nil {
+		t.Fatalf("expected write to fail over, got %v", err)
+	}
+	provider.Wait()
+
+	if pending := provider.Pending(); len(pending) != 1 || pending[0] != "b.txt" {
+		t.Fatalf("expected b.txt pending catch-up, got %v", pending)
+	}
+
+	content, err := provider.GetFile(ctx, "b.txt")
+	if err != nil || string(content) != "b" {
+		t.Fatalf("expected read to cascade to fallback, got %q (%v)", content, err)
+	}
+
+	primary.setDown(false)
+	synced, err := provider.CatchUp(ctx)
+	if err != nil || synced != 1 {
+		t.Fatalf("CatchUp: synced=%d err=%v", synced, err)
+	}
+	if !primary.has("b.txt") || len(provider.Pending()) != 0 {
+		t.Fatalf("expected primary to catch up")
+	}
+
+	if err := provider.DeleteFile(ctx, "a.txt"); err != nil {
+		t.Fatalf("DeleteFile: %v", err)
+	}
+	provider.Wait()
+	if primary.has("a.txt") || fallback.has("a.txt") {
+		t.Fatalf("expected delete to reach both providers")
+	}
+}
+
+func TestFailoverProviderPrimaryWritePolicy(t *testing.T) {
+	ctx := context.Background()
+	primary, fallback := newFlakyStore(), newFlakyStore()
+	provider := NewFailoverProvider(primary, fallback).
+		WithLogger(&mockLogger{}).
+		WithWritePolicy(FailoverWritePrimary)
+
+	primary.setDown(true)
+	if _, err := provider.UploadFile(ctx, "a.txt", []byte("a")); err == nil {
+		t.Fatalf("expected write to fail while primary is down")
+	}
+	if fallback.has("a.txt") {
+		t.Fatalf("expected fallback to stay untouched")
+	}
+
+	if err := provider.Validate(ctx); err != nil {
+		t.Fatalf("expected validation to pass with a healthy fallback: %v", err)
+	}
+}
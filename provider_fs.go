@@ -1,7 +1,10 @@
 package uploader
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -14,46 +17,114 @@ import (
 )
 
 var (
-	_ Uploader        = &FSProvider{}
-	_ ChunkedUploader = &FSProvider{}
-	_ PresignedPoster = &FSProvider{}
+	_ Uploader          = &FSProvider{}
+	_ ChunkedUploader   = &FSProvider{}
+	_ PresignedPoster   = &FSProvider{}
+	_ ProviderDescriber = &FSProvider{}
+	_ Lister            = &FSProvider{}
+	_ GarbageCollector  = &FSProvider{}
+	_ StreamUploader    = &FSProvider{}
+	_ LocalFileUploader = &FSProvider{}
+	_ FileStatter       = &FSProvider{}
+	_ ConditionalWriter = &FSProvider{}
+	_ RangeReader       = &FSProvider{}
+	_ PublicURLBuilder  = &FSProvider{}
+	_ LocationDescriber = &FSProvider{}
+	_ BatchDeleter      = &FSProvider{}
+)
+
+// legacyChunkDirName is the directory older releases staged chunks in, inside base. It is still
+// hidden from List so upgraded deployments do not expose leftover parts.
+const legacyChunkDirName = ".chunks"
+
+// FSURLPolicy controls the URL FSProvider reports for stored files from uploads, completed
+// chunked sessions and GetPresignedURL.
+type FSURLPolicy string
+
+const (
+	// FSURLPrefixed joins the key to the WithFSURLPrefix prefix, "/" when none is set. It is the
+	// default.
+	FSURLPrefixed FSURLPolicy = "prefixed"
+	// FSURLRelative reports the key itself, for callers that build URLs on their own.
+	FSURLRelative FSURLPolicy = "relative"
+	// FSURLAbsolutePath reports the file's path on disk, as older releases did. It exposes the
+	// server's directory layout, so keep it to internal tooling.
+	FSURLAbsolutePath FSURLPolicy = "absolute_path"
 )
 
 type FSProvider struct {
 	root      fs.FS
 	base      string
+	chunkRoot string
 	urlPrefix string
+	urlPolicy FSURLPolicy
 	logger    Logger
+	etags     *fsETagCache
+	optionErr error
+}
+
+func NewFSProvider(base string, opts ...FSProviderOption) *FSProvider {
+	p := &FSProvider{
+		root:      os.DirFS(base),
+		base:      base,
+		chunkRoot: defaultChunkRoot(base),
+		urlPolicy: FSURLPrefixed,
+		logger:    &DefaultLogger{},
+		etags:     &fsETagCache{},
+	}
+	p.apply(opts...)
+	return p
 }
 
-func NewFSProvider(base string) *FSProvider {
-	return &FSProvider{
-		root:   os.DirFS(base),
-		base:   base,
-		logger: &DefaultLogger{},
+// defaultChunkRoot stages chunks in a directory under os.TempDir named after base, so providers
+// for different base directories never collect each other's sessions.
+func defaultChunkRoot(base string) string {
+	if abs, err := filepath.Abs(base); err == nil {
+		base = abs
 	}
+	sum := sha256.Sum256([]byte(base))
+	return filepath.Join(os.TempDir(), "go-uploader-chunks", hex.EncodeToString(sum[:8]))
 }
 
+// Deprecated: pass WithFSLogger to NewFSProvider.
 func (p *FSProvider) WithLogger(l Logger) *FSProvider {
-	p.logger = l
+	p.apply(WithFSLogger(l))
 	return p
 }
 
+// Deprecated: pass WithFSRoot to NewFSProvider.
 func (p *FSProvider) WithFS(f fs.FS) *FSProvider {
-	p.root = f
+	p.apply(WithFSRoot(f))
 	return p
 }
 
-func (p *FSProvider) WithURLPrefix(prefix string) *FSProvider {
-	if !strings.HasSuffix(prefix, "/") {
-		prefix = prefix + "/"
+// WithChunkDir sets where chunk parts are staged before assembly; it defaults to a directory
+// under os.TempDir derived from base. An empty dir keeps the current one.
+//
+// Deprecated: pass WithFSChunkDir to NewFSProvider.
+func (p *FSProvider) WithChunkDir(dir string) *FSProvider {
+	if dir != "" {
+		p.apply(WithFSChunkDir(dir))
 	}
+	return p
+}
 
-	p.urlPrefix = prefix
+// Deprecated: pass WithFSURLPrefix to NewFSProvider.
+func (p *FSProvider) WithURLPrefix(prefix string) *FSProvider {
+	p.apply(WithFSURLPrefix(prefix))
 	return p
 }
 
 func (p *FSProvider) UploadFile(ctx context.Context, path string, content []byte, opts ...UploadOption) (string, error) {
+	md := &Metadata{}
+	for _, opt := range opts {
+		opt(md)
+	}
+
+	if md.IfNotExists {
+		return p.UploadStream(ctx, path, bytes.NewReader(content), int64(len(content)), opts...)
+	}
+
 	fullPath := filepath.Join(p.base, filepath.Clean(path))
 	dir := filepath.Dir(fullPath)
 
@@ -61,11 +132,126 @@ func (p *FSProvider) UploadFile(ctx context.Context, path string, content []byte
 		return "", fmt.Errorf("%w: %w", ErrPermissionDenied, err)
 	}
 
-	if err := os.WriteFile(fullPath, content, 0644); err != nil {
+	mode := fileMode(md.resolvedVisibility())
+	if err := os.WriteFile(fullPath, content, mode); err != nil {
+		return "", fmt.Errorf("%w: %s", ErrPermissionDenied, err)
+	}
+	// WriteFile keeps the mode of a file it overwrites, and the umask narrows a new one.
+	if err := os.Chmod(fullPath, mode); err != nil {
+		return "", fmt.Errorf("fs provider: chmod %s: %w", path, err)
+	}
+	p.remember(filepath.Clean(path), content)
+
+	return p.url(path), nil
+}
+
+// UploadStream writes r to path without buffering it; a partially written file is removed when
+// the copy fails.
+func (p *FSProvider) UploadStream(ctx context.Context, path string, r io.Reader, size int64, opts ...UploadOption) (string, error) {
+	md := &Metadata{}
+	for _, opt := range opts {
+		opt(md)
+	}
+
+	fullPath := filepath.Join(p.base, filepath.Clean(path))
+	p.etags.forget(filepath.Clean(path))
+
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrPermissionDenied, err)
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if md.IfNotExists {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_EXCL
+	}
+
+	mode := fileMode(md.resolvedVisibility())
+	file, err := os.OpenFile(fullPath, flags, mode)
+	if errors.Is(err, fs.ErrExist) {
+		return "", ErrFileExists
+	}
+	if err != nil {
 		return "", fmt.Errorf("%w: %s", ErrPermissionDenied, err)
 	}
 
-	return fullPath, nil
+	if _, err := io.Copy(file, r); err != nil {
+		_ = file.Close()
+		_ = os.Remove(fullPath)
+		return "", fmt.Errorf("fs provider: stream upload: %w", err)
+	}
+
+	if err := file.Close(); err != nil {
+		_ = os.Remove(fullPath)
+		return "", fmt.Errorf("fs provider: stream upload: %w", err)
+	}
+
+	if err := os.Chmod(fullPath, mode); err != nil {
+		return "", fmt.Errorf("fs provider: chmod %s: %w", path, err)
+	}
+
+	return p.url(path), nil
+}
+
+// UploadLocalFile places srcPath at path without reading it: the file is renamed when
+// WithMoveSource is set, hard-linked otherwise, and copied when neither works (e.g. the source
+// lives on another device). With WithIfNotExists moves link and unlink instead, since a rename
+// would replace the destination.
+func (p *FSProvider) UploadLocalFile(ctx context.Context, path, srcPath string, opts ...UploadOption) (string, error) {
+	md := &Metadata{}
+	for _, opt := range opts {
+		opt(md)
+	}
+
+	fullPath := filepath.Join(p.base, filepath.Clean(path))
+	p.etags.forget(filepath.Clean(path))
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrPermissionDenied, err)
+	}
+
+	if !md.IfNotExists {
+		if err := os.Remove(fullPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("fs provider: replace %s: %w", path, err)
+		}
+	}
+
+	place := os.Link
+	if md.MoveSource && !md.IfNotExists {
+		place = os.Rename
+	}
+
+	if err := place(srcPath, fullPath); err == nil {
+		if err := os.Chmod(fullPath, fileMode(md.resolvedVisibility())); err != nil {
+			return "", fmt.Errorf("fs provider: chmod %s: %w", path, err)
+		}
+		if md.MoveSource && md.IfNotExists {
+			_ = os.Remove(srcPath)
+		}
+		return p.url(path), nil
+	} else if errors.Is(err, fs.ErrExist) {
+		return "", ErrFileExists
+	}
+
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return "", fmt.Errorf("fs provider: open source: %w", err)
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return "", fmt.Errorf("fs provider: stat source: %w", err)
+	}
+
+	url, err := p.UploadStream(ctx, path, src, info.Size(), opts...)
+	if err != nil {
+		return "", err
+	}
+
+	if md.MoveSource {
+		_ = os.Remove(srcPath)
+	}
+
+	return url, nil
 }
 
 func (p *FSProvider) GetFile(ctx context.Context, path string) ([]byte, error) {
@@ -86,8 +272,74 @@ func (p *FSProvider) GetFile(ctx context.Context, path string) ([]byte, error) {
 	return data, nil
 }
 
+func (p *FSProvider) StatFile(ctx context.Context, path string) (*ObjectInfo, error) {
+	cleanPath := filepath.Clean(path)
+	info, err := fs.Stat(p.root, cleanPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrImageNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fs provider: stat %s: %w", path, err)
+	}
+
+	if info.IsDir() {
+		return nil, ErrImageNotFound
+	}
+
+	etag, err := p.contentETag(cleanPath, info)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ObjectInfo{
+		Key:     filepath.ToSlash(cleanPath),
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+		ETag:    etag,
+	}, nil
+}
+
+// OpenRange opens the file and seeks to offset, so only the requested bytes are read from disk.
+func (p *FSProvider) OpenRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	cleanPath := filepath.Clean(path)
+	f, err := p.root.Open(cleanPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrImageNotFound
+	}
+	if errors.Is(err, fs.ErrPermission) {
+		return nil, ErrPermissionDenied
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fs provider: open %s: %w", path, err)
+	}
+	if info, err := f.Stat(); err != nil || info.IsDir() {
+		f.Close()
+		return nil, ErrImageNotFound
+	}
+
+	if offset > 0 {
+		if seeker, ok := f.(io.Seeker); ok {
+			_, err = seeker.Seek(offset, io.SeekStart)
+		} else {
+			_, err = io.CopyN(io.Discard, f, offset)
+		}
+		if err != nil && err != io.EOF {
+			f.Close()
+			return nil, fmt.Errorf("fs provider: seek %s: %w", path, err)
+		}
+	}
+
+	return limitReadCloser(f, length), nil
+}
+
+// ConditionalWrites reports that WithIfNotExists is enforced with exclusive creates.
+func (p *FSProvider) ConditionalWrites() bool {
+	return true
+}
+
 func (p *FSProvider) DeleteFile(ctx context.Context, path string) error {
 	fullPath := filepath.Join(p.base, filepath.Clean(path))
+	p.etags.forget(filepath.Clean(path))
 	err := os.Remove(fullPath)
 	if errors.Is(err, os.ErrNotExist) {
 		return ErrImageNotFound
@@ -102,6 +354,40 @@ func (p *FSProvider) DeleteFile(ctx context.Context, path string) error {
 	return nil
 }
 
+// DeleteFiles removes keys and then every directory the removal left empty, so deleting a
+// folder's contents also deletes the folder. The provider base directory is always kept.
+func (p *FSProvider) DeleteFiles(ctx context.Context, keys []string) (int, error) {
+	deleted := 0
+	dirs := make(map[string]bool)
+	var errs []error
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		err := p.DeleteFile(ctx, key)
+		switch {
+		case err == nil:
+			deleted++
+			dirs[filepath.Dir(filepath.Join(p.base, filepath.Clean(key)))] = true
+		case !errors.Is(err, ErrImageNotFound):
+			errs = append(errs, fmt.Errorf("fs provider: delete %s: %w", key, err))
+		}
+	}
+
+	base := filepath.Clean(p.base)
+	for dir := range dirs {
+		// os.Remove fails on non-empty directories, which ends the walk up.
+		for dir != base && strings.HasPrefix(dir, base+string(filepath.Separator)) {
+			if err := os.Remove(dir); err != nil {
+				break
+			}
+			dir = filepath.Dir(dir)
+		}
+	}
+	return deleted, errors.Join(errs...)
+}
+
 func (p *FSProvider) GetPresignedURL(ctx context.Context, path string, _ time.Duration) (string, error) {
 	if _, err := fs.Stat(p.root, filepath.Clean(path)); err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
@@ -110,10 +396,116 @@ func (p *FSProvider) GetPresignedURL(ctx context.Context, path string, _ time.Du
 		return "", err
 	}
 
+	return p.url(path), nil
+}
+
+// url reports the URL of path under the provider's URL policy.
+func (p *FSProvider) url(path string) string {
+	switch p.urlPolicy {
+	case FSURLRelative:
+		return strings.TrimPrefix(filepath.ToSlash(filepath.Clean(path)), "/")
+	case FSURLAbsolutePath:
+		return filepath.Join(p.base, filepath.Clean(path))
+	default:
+		return joinSegments(p.urlPrefix, filepath.ToSlash(filepath.Clean(path)))
+	}
+}
+
+// PublicURL returns the URL of path under the prefix set with WithFSURLPrefix, without checking
+// the file exists. It returns ErrNotImplemented when no prefix is configured.
+func (p *FSProvider) PublicURL(path string) (string, error) {
+	if p.urlPrefix == "" {
+		return "", ErrNotImplemented
+	}
 	return joinSegments(p.urlPrefix, path), nil
 }
 
+// List walks the provider root and returns every file whose key starts with prefix.
+func (p *FSProvider) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	prefix = strings.TrimPrefix(filepath.ToSlash(prefix), "/")
+	if strings.Contains(prefix, "..") {
+		return nil, ErrInvalidPath
+	}
+
+	start := "."
+	if idx := strings.LastIndex(prefix, "/"); idx > 0 {
+		start = prefix[:idx]
+	}
+
+	chunkKey := p.chunkDirKey()
+
+	var out []ObjectInfo
+	err := fs.WalkDir(p.root, start, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && name == start {
+				return fs.SkipAll
+			}
+			return err
+		}
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if name == legacyChunkDirName || (chunkKey != "" && name == chunkKey) {
+				return fs.SkipDir
+			}
+			if name != start && !strings.HasPrefix(name+"/", prefix) && !strings.HasPrefix(prefix, name+"/") {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if !strings.HasPrefix(name, prefix) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		out = append(out, ObjectInfo{
+			Key:     name,
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fs provider: list: %w", err)
+	}
+
+	return out, nil
+}
+
+func (p *FSProvider) ProviderName() string {
+	return "fs"
+}
+
+// ProviderKey returns path relative to the base directory. Only FSURLAbsolutePath reports the
+// path on disk, so FileMeta and StoredObjectRef do not leak the directory layout.
+func (p *FSProvider) ProviderKey(path string) string {
+	if p.urlPolicy == FSURLAbsolutePath {
+		return filepath.Join(p.base, filepath.Clean(path))
+	}
+	return strings.TrimPrefix(filepath.ToSlash(filepath.Clean(path)), "/")
+}
+
+// StorageLocation reports the base directory under FSURLAbsolutePath and nothing otherwise.
+func (p *FSProvider) StorageLocation() string {
+	if p.urlPolicy == FSURLAbsolutePath {
+		return p.base
+	}
+	return ""
+}
+
 func (p *FSProvider) Validate(ctx context.Context) error {
+	if p.optionErr != nil {
+		return fmt.Errorf("fs provider: invalid option: %w", p.optionErr)
+	}
+
 	if p.base == "" {
 		return fmt.Errorf("fs provider: base path not configured")
 	}
@@ -144,6 +536,9 @@ func (p *FSProvider) Validate(ctx context.Context) error {
 	return nil
 }
 
+// InitiateChunked stages parts in the chunk directory keyed by session ID, so instances can share
+// sessions only when they share the chunk directory. The default is derived from base and local
+// to the host, so multi-instance deployments must point WithFSChunkDir at a shared directory.
 func (p *FSProvider) InitiateChunked(_ context.Context, session *ChunkSession) (*ChunkSession, error) {
 	if session == nil {
 		return nil, fmt.Errorf("fs provider: chunk session is nil")
@@ -218,6 +613,10 @@ func (p *FSProvider) CompleteChunked(_ context.Context, session *ChunkSession) (
 	}
 	defer dest.Close()
 
+	if err := dest.Chmod(fileMode(session.Metadata.resolvedVisibility())); err != nil {
+		return nil, fmt.Errorf("fs provider: chmod destination file: %w", err)
+	}
+
 	indexes := make([]int, 0, len(session.UploadedParts))
 	for idx := range session.UploadedParts {
 		indexes = append(indexes, idx)
@@ -239,7 +638,7 @@ func (p *FSProvider) CompleteChunked(_ context.Context, session *ChunkSession) (
 		Name:         session.Key,
 		OriginalName: session.Key,
 		Size:         session.TotalSize,
-		URL:          fullPath,
+		URL:          p.url(session.Key),
 	}, nil
 }
 
@@ -251,6 +650,66 @@ func (p *FSProvider) AbortChunked(_ context.Context, session *ChunkSession) erro
 	return os.RemoveAll(p.chunkDir(session.ID))
 }
 
+// CollectGarbage removes chunk directories that have not been modified since olderThan, both in
+// the chunk directory and in the legacy ".chunks" directory inside base.
+func (p *FSProvider) CollectGarbage(ctx context.Context, olderThan time.Time) (int, error) {
+	removed := 0
+	for _, root := range p.chunkRoots() {
+		n, err := collectChunkDirs(ctx, root, olderThan)
+		removed += n
+		if err != nil {
+			return removed, err
+		}
+	}
+	return removed, nil
+}
+
+// chunkRoots returns the chunk directory and, when it is a different directory, the legacy one.
+func (p *FSProvider) chunkRoots() []string {
+	legacy := filepath.Join(p.base, legacyChunkDirName)
+	if filepath.Clean(p.chunkRoot) == legacy {
+		return []string{p.chunkRoot}
+	}
+	return []string{p.chunkRoot, legacy}
+}
+
+func collectChunkDirs(ctx context.Context, root string, olderThan time.Time) (int, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("fs provider: read chunk root: %w", err)
+	}
+
+	removed := 0
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return removed, err
+		}
+
+		if !entry.IsDir() {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return removed, fmt.Errorf("fs provider: stat chunk directory: %w", err)
+		}
+
+		if !info.ModTime().Before(olderThan) {
+			continue
+		}
+
+		if err := os.RemoveAll(filepath.Join(root, entry.Name())); err != nil {
+			return removed, fmt.Errorf("fs provider: remove chunk directory: %w", err)
+		}
+		removed++
+	}
+
+	return removed, nil
+}
+
 func (p *FSProvider) CreatePresignedPost(context.Context, string, *Metadata) (*PresignedPost, error) {
 	return nil, ErrNotImplemented
 }
@@ -266,7 +725,26 @@ func joinSegments(prefix, path string) string {
 }
 
 func (p *FSProvider) chunkDir(sessionID string) string {
-	return filepath.Join(p.base, ".chunks", sessionID)
+	return filepath.Join(p.chunkRoot, sessionID)
+}
+
+// chunkDirKey returns the chunk staging directory relative to base (slash separated) when it
+// lives inside base, or an empty string otherwise.
+func (p *FSProvider) chunkDirKey() string {
+	base, err := filepath.Abs(p.base)
+	if err != nil {
+		return ""
+	}
+	chunkRoot, err := filepath.Abs(p.chunkRoot)
+	if err != nil {
+		return ""
+	}
+
+	rel, err := filepath.Rel(base, chunkRoot)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return ""
+	}
+	return filepath.ToSlash(rel)
 }
 
 func (p *FSProvider) chunkFilePath(sessionID string, index int) string {
@@ -0,0 +1,107 @@
+package uploader
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"sync"
+	"time"
+)
+
+// fsETagCache remembers content hashes per file version, so StatFile hashes a file once per
+// change rather than on every call. Entries are keyed by path and invalidated by size or
+// modification time changes. A nil cache disables content ETags.
+type fsETagCache struct {
+	mu      sync.Mutex
+	entries map[string]fsETag
+}
+
+type fsETag struct {
+	size    int64
+	modTime time.Time
+	etag    string
+}
+
+// WithFSContentETags controls whether StatFile reports a SHA-256 content hash as the ETag. It is
+// on by default; the hash matches FileMeta.Checksum and is computed once per file version.
+// Disable it when stats of large files must not read their content.
+func WithFSContentETags(enabled bool) FSProviderOption {
+	return func(p *FSProvider) error {
+		if !enabled {
+			p.etags = nil
+		} else if p.etags == nil {
+			p.etags = &fsETagCache{}
+		}
+		return nil
+	}
+}
+
+func (c *fsETagCache) get(path string, info fs.FileInfo) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[path]
+	if !ok || entry.size != info.Size() || !entry.modTime.Equal(info.ModTime()) {
+		return "", false
+	}
+	return entry.etag, true
+}
+
+func (c *fsETagCache) put(path string, info fs.FileInfo, etag string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries == nil {
+		c.entries = make(map[string]fsETag)
+	}
+	c.entries[path] = fsETag{size: info.Size(), modTime: info.ModTime(), etag: etag}
+}
+
+func (c *fsETagCache) forget(path string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	delete(c.entries, path)
+	c.mu.Unlock()
+}
+
+// remember records content as the current version of path, sparing the next StatFile a read.
+func (p *FSProvider) remember(path string, content []byte) {
+	if p.etags == nil {
+		return
+	}
+	info, err := fs.Stat(p.root, path)
+	if err != nil {
+		p.etags.forget(path)
+		return
+	}
+	p.etags.put(path, info, checksumSHA256(content))
+}
+
+// contentETag returns the SHA-256 of path, or "" when content ETags are disabled.
+func (p *FSProvider) contentETag(path string, info fs.FileInfo) (string, error) {
+	if p.etags == nil {
+		return "", nil
+	}
+	if etag, ok := p.etags.get(path, info); ok {
+		return etag, nil
+	}
+
+	file, err := p.root.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("fs provider: etag %s: %w", path, err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("fs provider: etag %s: %w", path, err)
+	}
+
+	etag := hex.EncodeToString(hash.Sum(nil))
+	p.etags.put(path, info, etag)
+	return etag, nil
+}
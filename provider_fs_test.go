@@ -3,6 +3,7 @@ package uploader
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"os"
 	"path/filepath"
@@ -89,11 +90,12 @@ func TestFSProviderUploadFile(t *testing.T) {
 			t.Fatalf("UploadFile failed: %v", err)
 		}
 
-		expectedPath := filepath.Join(tmpDir, path)
-		if url != expectedPath {
-			t.Errorf("Expected URL '%s', got '%s'", expectedPath, url)
+		if url != "/test.jpg" {
+			t.Errorf("Expected URL '/test.jpg', got '%s'", url)
 		}
 
+		expectedPath := filepath.Join(tmpDir, path)
+
 		savedContent, err := os.ReadFile(expectedPath)
 		if err != nil {
 			t.Fatalf("Failed to read saved file: %v", err)
@@ -293,11 +295,141 @@ func TestFSProviderAbortChunked(t *testing.T) {
 		t.Fatalf("AbortChunked failed: %v", err)
 	}
 
-	if _, err := os.Stat(filepath.Join(tmpDir, ".chunks", session.ID)); !os.IsNotExist(err) {
+	if _, err := os.Stat(provider.chunkDir(session.ID)); !os.IsNotExist(err) {
 		t.Fatalf("expected chunk directory to be removed")
 	}
 }
 
+func TestFSProviderDefaultChunkDirPerBase(t *testing.T) {
+	a, b := t.TempDir(), t.TempDir()
+	if NewFSProvider(a).chunkRoot == NewFSProvider(b).chunkRoot {
+		t.Fatalf("expected providers for different bases to stage chunks apart")
+	}
+	if NewFSProvider(a).chunkRoot != NewFSProvider(a+"/").chunkRoot {
+		t.Fatalf("expected providers for the same base to share the chunk directory")
+	}
+	if !strings.HasPrefix(NewFSProvider(a).chunkRoot, os.TempDir()) {
+		t.Fatalf("expected the default chunk directory under os.TempDir, got %s", NewFSProvider(a).chunkRoot)
+	}
+}
+
+func TestFSProviderCollectGarbageSweepsLegacyChunkDir(t *testing.T) {
+	ctx := context.Background()
+	base := t.TempDir()
+	provider := NewFSProvider(base, WithFSChunkDir(t.TempDir()))
+
+	legacy := filepath.Join(base, legacyChunkDirName, "abandoned")
+	if err := os.MkdirAll(legacy, 0o755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(legacy, "00000000.part"), []byte("part"), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	old := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(legacy, old, old); err != nil {
+		t.Fatalf("Chtimes failed: %v", err)
+	}
+
+	removed, err := provider.CollectGarbage(ctx, time.Now().Add(-24*time.Hour))
+	if err != nil || removed != 1 {
+		t.Fatalf("CollectGarbage = %d, %v", removed, err)
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Fatalf("expected the legacy chunk directory to be collected")
+	}
+}
+
+func TestFSProviderCollectGarbage(t *testing.T) {
+	ctx := context.Background()
+	tmpDir := t.TempDir()
+	provider := NewFSProvider(t.TempDir()).WithChunkDir(tmpDir)
+
+	for _, id := range []string{"stale", "fresh"} {
+		if _, err := provider.InitiateChunked(ctx, &ChunkSession{ID: id, Key: "chunks/" + id}); err != nil {
+			t.Fatalf("InitiateChunked failed: %v", err)
+		}
+	}
+
+	old := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(filepath.Join(tmpDir, "stale"), old, old); err != nil {
+		t.Fatalf("Chtimes failed: %v", err)
+	}
+
+	removed, err := provider.CollectGarbage(ctx, time.Now().Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("CollectGarbage failed: %v", err)
+	}
+
+	if removed != 1 {
+		t.Fatalf("expected 1 removed directory, got %d", removed)
+	}
+
+	if _, err := os.Stat(filepath.Join(tmpDir, "stale")); !os.IsNotExist(err) {
+		t.Fatalf("expected stale chunk directory to be removed")
+	}
+
+	if _, err := os.Stat(filepath.Join(tmpDir, "fresh")); err != nil {
+		t.Fatalf("expected fresh chunk directory to remain: %v", err)
+	}
+}
+
+func TestFSProviderUploadLocalFile(t *testing.T) {
+	ctx := context.Background()
+	base := t.TempDir()
+	provider := NewFSProvider(base)
+
+	writeSource := func(content string) string {
+		t.Helper()
+		src := filepath.Join(t.TempDir(), "source.bin")
+		if err := os.WriteFile(src, []byte(content), 0600); err != nil {
+			t.Fatalf("write source: %v", err)
+		}
+		return src
+	}
+
+	t.Run("hard links by default", func(t *testing.T) {
+		src := writeSource("linked")
+		if _, err := provider.UploadLocalFile(ctx, "docs/linked.txt", src); err != nil {
+			t.Fatalf("UploadLocalFile failed: %v", err)
+		}
+
+		srcInfo, _ := os.Stat(src)
+		destInfo, err := os.Stat(filepath.Join(base, "docs", "linked.txt"))
+		if err != nil {
+			t.Fatalf("stat dest: %v", err)
+		}
+		if !os.SameFile(srcInfo, destInfo) {
+			t.Fatalf("expected destination to be a hard link of the source")
+		}
+	})
+
+	t.Run("moves when allowed", func(t *testing.T) {
+		src := writeSource("moved")
+		if _, err := provider.UploadLocalFile(ctx, "docs/moved.txt", src, WithMoveSource()); err != nil {
+			t.Fatalf("UploadLocalFile failed: %v", err)
+		}
+
+		if _, err := os.Stat(src); !os.IsNotExist(err) {
+			t.Fatalf("expected source to be consumed, got %v", err)
+		}
+	})
+
+	t.Run("replaces existing objects", func(t *testing.T) {
+		if _, err := provider.UploadFile(ctx, "docs/replaced.txt", []byte("old")); err != nil {
+			t.Fatalf("UploadFile failed: %v", err)
+		}
+
+		if _, err := provider.UploadLocalFile(ctx, "docs/replaced.txt", writeSource("new")); err != nil {
+			t.Fatalf("UploadLocalFile failed: %v", err)
+		}
+
+		content, err := provider.GetFile(ctx, "docs/replaced.txt")
+		if err != nil || string(content) != "new" {
+			t.Fatalf("expected replaced content, got %q (%v)", content, err)
+		}
+	})
+}
+
 func TestFSProviderGetPresignedURL(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "fs-provider-test")
 	if err != nil {
@@ -480,3 +612,108 @@ func TestFSProviderInterface(t *testing.T) {
 	var _ Uploader = &FSProvider{}
 	var _ ProviderValidator = &FSProvider{}
 }
+
+func TestFSProviderStatFileETag(t *testing.T) {
+	ctx := context.Background()
+	base := t.TempDir()
+	provider := NewFSProvider(base)
+
+	if _, err := provider.UploadFile(ctx, "docs/a.txt", []byte("hello")); err != nil {
+		t.Fatalf("UploadFile failed: %v", err)
+	}
+
+	info, err := provider.StatFile(ctx, "docs/a.txt")
+	if err != nil {
+		t.Fatalf("StatFile failed: %v", err)
+	}
+	if info.ETag != checksumSHA256([]byte("hello")) {
+		t.Fatalf("expected content hash ETag, got %q", info.ETag)
+	}
+
+	// Rewrite the file behind the provider's back; the size change invalidates the cached hash.
+	if err := os.WriteFile(filepath.Join(base, "docs", "a.txt"), []byte("hello, world"), 0644); err != nil {
+		t.Fatalf("rewrite file: %v", err)
+	}
+	if info, err = provider.StatFile(ctx, "docs/a.txt"); err != nil || info.ETag != checksumSHA256([]byte("hello, world")) {
+		t.Fatalf("expected refreshed ETag, got %#v (%v)", info, err)
+	}
+
+	plain := NewFSProvider(base, WithFSContentETags(false))
+	if info, err = plain.StatFile(ctx, "docs/a.txt"); err != nil || info.ETag != "" {
+		t.Fatalf("expected no ETag when disabled, got %#v (%v)", info, err)
+	}
+}
+
+func TestFSProviderURLPolicy(t *testing.T) {
+	ctx := context.Background()
+	base := t.TempDir()
+
+	for _, tc := range []struct {
+		opts []FSProviderOption
+		want string
+	}{
+		{opts: nil, want: "/docs/a.txt"},
+		{opts: []FSProviderOption{WithFSURLPrefix("https://cdn.example.com/files")}, want: "https://cdn.example.com/files/docs/a.txt"},
+		{opts: []FSProviderOption{WithFSURLPolicy(FSURLRelative)}, want: "docs/a.txt"},
+		{opts: []FSProviderOption{WithFSURLPolicy(FSURLAbsolutePath)}, want: filepath.Join(base, "docs", "a.txt")},
+	} {
+		provider := NewFSProvider(base, append(tc.opts, WithFSChunkDir(t.TempDir()))...)
+
+		uploaded, err := provider.UploadFile(ctx, "docs/a.txt", []byte("a"))
+		if err != nil {
+			t.Fatalf("UploadFile failed: %v", err)
+		}
+		presigned, err := provider.GetPresignedURL(ctx, "docs/a.txt", time.Minute)
+		if err != nil {
+			t.Fatalf("GetPresignedURL failed: %v", err)
+		}
+
+		session := &ChunkSession{ID: "s1", Key: "docs/a.txt", TotalSize: 1, UploadedParts: map[int]ChunkPart{}}
+		if _, err := provider.InitiateChunked(ctx, session); err != nil {
+			t.Fatalf("InitiateChunked failed: %v", err)
+		}
+		part, err := provider.UploadChunk(ctx, session, 0, bytes.NewReader([]byte("a")))
+		if err != nil {
+			t.Fatalf("UploadChunk failed: %v", err)
+		}
+		session.UploadedParts[0] = part
+		completed, err := provider.CompleteChunked(ctx, session)
+		if err != nil {
+			t.Fatalf("CompleteChunked failed: %v", err)
+		}
+
+		if uploaded != tc.want || presigned != tc.want || completed.URL != tc.want {
+			t.Fatalf("expected %q everywhere, got upload %q, presign %q, complete %q", tc.want, uploaded, presigned, completed.URL)
+		}
+	}
+
+	if err := NewFSProvider(base, WithFSURLPolicy("host")).Validate(ctx); err == nil {
+		t.Fatalf("expected unknown URL policy to fail validation")
+	}
+}
+
+func TestFSProviderFileMetaHidesDiskPaths(t *testing.T) {
+	ctx := context.Background()
+	base := t.TempDir()
+	manager := NewManager(WithProvider(NewFSProvider(base)))
+
+	meta, err := manager.HandleFile(ctx, newTestFileHeader(t, "file", "photo.png", "image/png", createTestPNG(4, 4)), "images")
+	if err != nil {
+		t.Fatalf("HandleFile failed: %v", err)
+	}
+	encoded, err := json.Marshal(meta)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if strings.Contains(string(encoded), base) {
+		t.Fatalf("expected no disk path in %s", encoded)
+	}
+	if meta.ProviderKey != meta.Name || meta.Ref.ProviderKey != meta.Name || meta.Ref.Location != "" {
+		t.Fatalf("expected base-relative keys, got %q and %#v", meta.ProviderKey, meta.Ref)
+	}
+
+	internal := NewFSProvider(base, WithFSURLPolicy(FSURLAbsolutePath))
+	if internal.ProviderKey("images/a.png") != filepath.Join(base, "images", "a.png") || internal.StorageLocation() != base {
+		t.Fatalf("expected FSURLAbsolutePath to report disk paths")
+	}
+}
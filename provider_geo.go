@@ -0,0 +1,339 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	_ Uploader          = &GeoProvider{}
+	_ ProviderDescriber = &GeoProvider{}
+	_ ProviderValidator = &GeoProvider{}
+	_ Lister            = &GeoProvider{}
+	_ FileStatter       = &GeoProvider{}
+	_ Replicator        = &GeoProvider{}
+)
+
+// GeoReadInfo describes where GetFileWithInfo served a read from.
+type GeoReadInfo struct {
+	// Region is the replica region that served the read, empty when the primary did.
+	Region string
+	// FromReplica reports whether the content came from a replica, which may lag the primary.
+	FromReplica bool
+	// RecentWrite reports that the key was written within the replication lag window, so the
+	// read went to the primary to observe the latest version.
+	RecentWrite bool
+}
+
+type geoRegionContextKey struct{}
+type geoPrimaryReadContextKey struct{}
+
+// ContextWithRegion selects the replica region GeoProvider reads from for this request, e.g.
+// the region closest to the client.
+func ContextWithRegion(ctx context.Context, region string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, geoRegionContextKey{}, region)
+}
+
+// ContextWithPrimaryRead makes GeoProvider read from the primary, for callers that must see
+// their own writes regardless of replication lag.
+func ContextWithPrimaryRead(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, geoPrimaryReadContextKey{}, true)
+}
+
+// GeoProvider writes to a primary bucket and serves reads from the replica closest to the
+// caller. Storage-level replication (e.g. S3 cross-region replication) keeps replicas in sync;
+// the provider only routes. Replicas that fail are skipped for a cooldown, and keys written
+// within the replication lag window are read from the primary.
+type GeoProvider struct {
+	logger   Logger
+	primary  Uploader
+	replicas map[string]Uploader
+	region   string
+	lag      time.Duration
+	cooldown time.Duration
+	now      func() time.Time
+
+	optionErr error
+
+	mu          sync.Mutex
+	unhealthy   map[string]time.Time
+	recentWrite map[string]time.Time
+}
+
+// NewGeoProvider creates a provider writing to primary and reading from replicas, keyed by
+// region.
+func NewGeoProvider(primary Uploader, replicas map[string]Uploader, opts ...GeoProviderOption) *GeoProvider {
+	p := &GeoProvider{
+		logger:      &DefaultLogger{},
+		primary:     primary,
+		replicas:    replicas,
+		lag:         DefaultGeoReplicationLag,
+		cooldown:    DefaultGeoUnhealthyCooldown,
+		now:         time.Now,
+		unhealthy:   make(map[string]time.Time),
+		recentWrite: make(map[string]time.Time),
+	}
+	p.apply(opts...)
+	return p
+}
+
+// Deprecated: pass WithGeoLogger to NewGeoProvider.
+func (p *GeoProvider) WithLogger(l Logger) *GeoProvider {
+	p.apply(WithGeoLogger(l))
+	return p
+}
+
+// WithRegion sets the region used when the request context does not carry one.
+//
+// Deprecated: pass WithGeoRegion to NewGeoProvider.
+func (p *GeoProvider) WithRegion(region string) *GeoProvider {
+	p.apply(WithGeoRegion(region))
+	return p
+}
+
+// WithReplicationLag sets how long after a write reads of that key go to the primary. Zero
+// always reads from replicas.
+//
+// Deprecated: pass WithGeoReplicationLag to NewGeoProvider.
+func (p *GeoProvider) WithReplicationLag(lag time.Duration) *GeoProvider {
+	p.apply(WithGeoReplicationLag(lag))
+	return p
+}
+
+// WithUnhealthyCooldown sets how long a failing replica is skipped before it is tried again.
+//
+// Deprecated: pass WithGeoUnhealthyCooldown to NewGeoProvider.
+func (p *GeoProvider) WithUnhealthyCooldown(cooldown time.Duration) *GeoProvider {
+	p.apply(WithGeoUnhealthyCooldown(cooldown))
+	return p
+}
+
+func (p *GeoProvider) UploadFile(ctx context.Context, path string, content []byte, opts ...UploadOption) (string, error) {
+	url, err := p.primary.UploadFile(ctx, path, content, opts...)
+	if err != nil {
+		return "", err
+	}
+	p.recordWrite(path)
+	return url, nil
+}
+
+func (p *GeoProvider) DeleteFile(ctx context.Context, path string) error {
+	if err := p.primary.DeleteFile(ctx, path); err != nil {
+		return err
+	}
+	p.recordWrite(path)
+	return nil
+}
+
+func (p *GeoProvider) GetFile(ctx context.Context, path string) ([]byte, error) {
+	content, _, err := p.GetFileWithInfo(ctx, path)
+	return content, err
+}
+
+// GetFileWithInfo is GetFile reporting which store served the read, so callers can tell when
+// content may lag the primary.
+func (p *GeoProvider) GetFileWithInfo(ctx context.Context, path string) ([]byte, *GeoReadInfo, error) {
+	region, replica, info := p.pickReplica(ctx, path)
+	if replica != nil {
+		content, err := replica.GetFile(ctx, path)
+		if err == nil {
+			return content, info, nil
+		}
+		p.replicaFailed(ctx, region, path, err)
+	}
+
+	content, err := p.primary.GetFile(ctx, path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return content, &GeoReadInfo{RecentWrite: info.RecentWrite}, nil
+}
+
+// GetPresignedURL signs against the nearest healthy replica so downloads stay in region.
+func (p *GeoProvider) GetPresignedURL(ctx context.Context, path string, expires time.Duration) (string, error) {
+	region, replica, _ := p.pickReplica(ctx, path)
+	if replica != nil {
+		url, err := replica.GetPresignedURL(ctx, path, expires)
+		if err == nil {
+			return url, nil
+		}
+		p.replicaFailed(ctx, region, path, err)
+	}
+	return p.primary.GetPresignedURL(ctx, path, expires)
+}
+
+// StatFile asks the primary, which is the source of truth.
+func (p *GeoProvider) StatFile(ctx context.Context, path string) (*ObjectInfo, error) {
+	statter, ok := p.primary.(FileStatter)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+	return statter.StatFile(ctx, path)
+}
+
+// List asks the primary, which is the source of truth.
+func (p *GeoProvider) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	lister, ok := p.primary.(Lister)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+	return lister.List(ctx, prefix)
+}
+
+// Validate requires a working primary. Failing replicas are logged and skipped until their
+// cooldown expires.
+func (p *GeoProvider) Validate(ctx context.Context) error {
+	if p.optionErr != nil {
+		return fmt.Errorf("geo provider: invalid option: %w", p.optionErr)
+	}
+	if p.primary == nil {
+		return fmt.Errorf("geo provider: primary not configured")
+	}
+
+	if err := validateOptional(ctx, p.primary); err != nil {
+		return fmt.Errorf("geo provider: primary validation failed: %w", err)
+	}
+
+	p.CheckHealth(ctx)
+	return nil
+}
+
+// CheckHealth validates every replica and returns the regions currently considered unhealthy.
+// Run it periodically to bring recovered replicas back before their cooldown ends.
+func (p *GeoProvider) CheckHealth(ctx context.Context) []string {
+	for region, replica := range p.replicas {
+		if err := validateOptional(ctx, replica); err != nil {
+			contextLogger(ctx, p.logger).Error("geo provider replica unhealthy", err, "region", region)
+			p.markUnhealthy(region)
+			continue
+		}
+		p.mu.Lock()
+		delete(p.unhealthy, region)
+		p.mu.Unlock()
+	}
+	return p.UnhealthyRegions()
+}
+
+// UnhealthyRegions lists the replicas skipped by reads, sorted by region.
+func (p *GeoProvider) UnhealthyRegions() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	var out []string
+	for region, until := range p.unhealthy {
+		if now.Before(until) {
+			out = append(out, region)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func (p *GeoProvider) ProviderName() string {
+	if describer, ok := p.primary.(ProviderDescriber); ok {
+		return "geo:" + describer.ProviderName()
+	}
+	return "geo"
+}
+
+func (p *GeoProvider) ProviderKey(path string) string {
+	if describer, ok := p.primary.(ProviderDescriber); ok {
+		return describer.ProviderKey(path)
+	}
+	return path
+}
+
+// Replicas lists the replica regions, sorted.
+func (p *GeoProvider) Replicas() []string {
+	out := make([]string, 0, len(p.replicas))
+	for region := range p.replicas {
+		out = append(out, region)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// pickReplica returns the replica to read path from, or nil when the read must go to the
+// primary. info reports whether a recent write forced the primary.
+func (p *GeoProvider) pickReplica(ctx context.Context, path string) (string, Uploader, *GeoReadInfo) {
+	region, _ := ctx.Value(geoRegionContextKey{}).(string)
+	if region == "" {
+		region = p.region
+	}
+
+	if primaryRead, _ := ctx.Value(geoPrimaryReadContextKey{}).(bool); primaryRead {
+		return "", nil, &GeoReadInfo{}
+	}
+
+	if p.writtenRecently(path) {
+		return "", nil, &GeoReadInfo{RecentWrite: true}
+	}
+
+	replica, ok := p.replicas[region]
+	if !ok || replica == nil || p.isUnhealthy(region) {
+		return "", nil, &GeoReadInfo{}
+	}
+	return region, replica, &GeoReadInfo{Region: region, FromReplica: true}
+}
+
+// replicaFailed falls back to the primary. Missing objects are expected while replication
+// catches up; other errors take the replica out of rotation.
+func (p *GeoProvider) replicaFailed(ctx context.Context, region, path string, err error) {
+	if errors.Is(err, ErrImageNotFound) || ctx.Err() != nil {
+		return
+	}
+	contextLogger(ctx, p.logger).Error("geo provider replica read failed", err, "region", region, "path", path)
+	p.markUnhealthy(region)
+}
+
+func (p *GeoProvider) markUnhealthy(region string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.unhealthy[region] = p.now().Add(p.cooldown)
+}
+
+func (p *GeoProvider) isUnhealthy(region string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	until, ok := p.unhealthy[region]
+	return ok && p.now().Before(until)
+}
+
+func (p *GeoProvider) recordWrite(path string) {
+	if p.lag <= 0 {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	for key, at := range p.recentWrite {
+		if now.Sub(at) >= p.lag {
+			delete(p.recentWrite, key)
+		}
+	}
+	p.recentWrite[path] = now
+}
+
+func (p *GeoProvider) writtenRecently(path string) bool {
+	if p.lag <= 0 {
+		return false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	at, ok := p.recentWrite[path]
+	return ok && p.now().Sub(at) < p.lag
+}
@@ -0,0 +1,66 @@
+package uploader
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestGeoProviderReadsNearestReplica(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	primary, eu, us := newFlakyStore(), newFlakyStore(), newFlakyStore()
+	provider := NewGeoProvider(primary, map[string]Uploader{"eu": eu, "us": us}).
+		WithLogger(&mockLogger{}).
+		WithRegion("eu").
+		WithReplicationLag(time.Minute)
+	provider.now = func() time.Time { return now }
+
+	if _, err := provider.UploadFile(ctx, "a.txt", []byte("primary")); err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	if eu.has("a.txt") {
+		t.Fatalf("expected writes to go to the primary only")
+	}
+
+	// Replication has not caught up yet: recent writes read from the primary.
+	content, info, err := provider.GetFileWithInfo(ctx, "a.txt")
+	if err != nil || string(content) != "primary" || !info.RecentWrite || info.FromReplica {
+		t.Fatalf("expected primary read for recent write, got %q %#v (%v)", content, info, err)
+	}
+
+	eu.files["a.txt"] = []byte("eu")
+	us.files["a.txt"] = []byte("us")
+	now = now.Add(2 * time.Minute)
+
+	content, info, err = provider.GetFileWithInfo(ctx, "a.txt")
+	if err != nil || string(content) != "eu" || info.Region != "eu" || !info.FromReplica {
+		t.Fatalf("expected eu replica read, got %q %#v (%v)", content, info, err)
+	}
+
+	content, err = provider.GetFile(ContextWithRegion(ctx, "us"), "a.txt")
+	if err != nil || string(content) != "us" {
+		t.Fatalf("expected context region to pick us replica, got %q (%v)", content, err)
+	}
+
+	content, err = provider.GetFile(ContextWithPrimaryRead(ctx), "a.txt")
+	if err != nil || string(content) != "primary" {
+		t.Fatalf("expected forced primary read, got %q (%v)", content, err)
+	}
+
+	eu.setDown(true)
+	content, err = provider.GetFile(ctx, "a.txt")
+	if err != nil || string(content) != "primary" {
+		t.Fatalf("expected failover to primary, got %q (%v)", content, err)
+	}
+	if regions := provider.UnhealthyRegions(); len(regions) != 1 || regions[0] != "eu" {
+		t.Fatalf("expected eu marked unhealthy, got %v", regions)
+	}
+
+	eu.setDown(false)
+	now = now.Add(DefaultGeoUnhealthyCooldown)
+	if content, _ := provider.GetFile(ctx, "a.txt"); string(content) != "eu" {
+		t.Fatalf("expected eu back in rotation after cooldown, got %q", content)
+	}
+}
@@ -4,31 +4,70 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"sync"
 	"time"
 )
 
 var (
-	_ Uploader        = &MultiProvider{}
-	_ ChunkedUploader = &MultiProvider{}
-	_ PresignedPoster = &MultiProvider{}
+	_ Uploader               = &MultiProvider{}
+	_ ChunkedUploader        = &MultiProvider{}
+	_ PresignedPoster        = &MultiProvider{}
+	_ PresignedChunkUploader = &MultiProvider{}
+	_ ProviderDescriber      = &MultiProvider{}
+	_ Lister                 = &MultiProvider{}
+	_ GarbageCollector       = &MultiProvider{}
+	_ ChunkLimiter           = &MultiProvider{}
+	_ StreamUploader         = &MultiProvider{}
+	_ FileStatter            = &MultiProvider{}
+	_ ConditionalWriter      = &MultiProvider{}
+	_ Replicator             = &MultiProvider{}
 )
 
 type MultiProvider struct {
 	logger      Logger
 	local       *FSProvider
 	objectStore Uploader
+	buffers     *BufferPool
+	hedgeDelay  time.Duration
+	partial     PartialUploadPolicy
+	staleAfter  time.Duration
+	optionErr   error
+
+	refreshMu  sync.Mutex
+	refreshing map[string]bool
+	refreshes  sync.WaitGroup
 }
 
-func NewMultiProvider(local *FSProvider, objectStore Uploader) *MultiProvider {
-	return &MultiProvider{
+func NewMultiProvider(local *FSProvider, objectStore Uploader, opts ...MultiProviderOption) *MultiProvider {
+	p := &MultiProvider{
 		local:       local,
 		logger:      &DefaultLogger{},
 		objectStore: objectStore,
 	}
+	p.apply(opts...)
+	return p
 }
 
+// Deprecated: pass WithMultiLogger to NewMultiProvider.
 func (p *MultiProvider) WithLogger(l Logger) *MultiProvider {
-	p.logger = l
+	p.apply(WithMultiLogger(l))
+	return p
+}
+
+// WithBufferPool sets the pool used when a stream has to be buffered.
+//
+// Deprecated: pass WithMultiBufferPool to NewMultiProvider.
+func (p *MultiProvider) WithBufferPool(pool *BufferPool) *MultiProvider {
+	p.apply(WithMultiBufferPool(pool))
+	return p
+}
+
+// WithHedgeDelay makes GetFile also ask the object store when the local read has not finished
+// within delay, returning whichever succeeds first. Zero keeps reads strictly sequential.
+//
+// Deprecated: pass WithMultiHedgeDelay to NewMultiProvider.
+func (p *MultiProvider) WithHedgeDelay(delay time.Duration) *MultiProvider {
+	p.apply(WithMultiHedgeDelay(delay))
 	return p
 }
 
@@ -39,14 +78,69 @@ func (m *MultiProvider) UploadFile(ctx context.Context, path string, content []b
 		return "", err
 	}
 
-	if _, err := m.local.UploadFile(ctx, path, content, opts...); err != nil {
+	if _, err := m.local.UploadFile(ctx, path, content, mirrorOptions(opts)...); err != nil {
+		return "", m.rollbackObjectStore(ctx, path, err)
+	}
+
+	return url, nil
+}
+
+// rollbackObjectStore applies the partial upload policy after the object store accepted path but
+// the local mirror did not, and returns the mirror error.
+func (m *MultiProvider) rollbackObjectStore(ctx context.Context, path string, err error) error {
+	if m.partial == PartialKeep {
+		contextLogger(ctx, m.logger).Info("keeping object store copy after mirror failure", "path", path)
+		return err
+	}
+	if delErr := m.objectStore.DeleteFile(context.WithoutCancel(ctx), path); delErr != nil {
+		contextLogger(ctx, m.logger).Error("multi provider rollback failed", delErr, "path", path)
+	}
+	return err
+}
+
+// mirrorOptions lets mirrored copies replace stale files: conditional writes are decided by the
+// store that accepted the write.
+func mirrorOptions(opts []UploadOption) []UploadOption {
+	return append(opts[:len(opts):len(opts)], func(md *Metadata) { md.IfNotExists = false })
+}
+
+// UploadStream streams r to both providers when r can be rewound and the object store supports
+// streaming; otherwise the content is buffered and handed to UploadFile.
+func (m *MultiProvider) UploadStream(ctx context.Context, path string, r io.Reader, size int64, opts ...UploadOption) (string, error) {
+	seeker, seekable := r.(io.ReadSeeker)
+	streamer, streams := m.objectStore.(StreamUploader)
+	if !seekable || !streams {
+		content, err := m.buffers.ReadAll(r)
+		if err != nil {
+			return "", fmt.Errorf("multi provider: read stream: %w", err)
+		}
+		return m.UploadFile(ctx, path, content, opts...)
+	}
+
+	url, err := streamer.UploadStream(ctx, path, seeker, size, opts...)
+	if err != nil {
 		return "", err
 	}
 
+	if _, err := seeker.Seek(0, io.SeekStart); err != nil {
+		return "", m.rollbackObjectStore(ctx, path, fmt.Errorf("multi provider: rewind stream: %w", err))
+	}
+
+	if _, err := m.local.UploadStream(ctx, path, seeker, size, mirrorOptions(opts)...); err != nil {
+		return "", m.rollbackObjectStore(ctx, path, err)
+	}
+
 	return url, nil
 }
 
 func (m *MultiProvider) GetFile(ctx context.Context, path string) ([]byte, error) {
+	if m.staleAfter > 0 {
+		return m.staleGetFile(ctx, path)
+	}
+	if m.hedgeDelay > 0 {
+		return m.hedgedGetFile(ctx, path)
+	}
+
 	img, err := m.local.GetFile(ctx, path)
 	if err == nil {
 		return img, nil
@@ -54,6 +148,64 @@ func (m *MultiProvider) GetFile(ctx context.Context, path string) ([]byte, error
 	return m.objectStore.GetFile(ctx, path)
 }
 
+type getFileResult struct {
+	content []byte
+	err     error
+	remote  bool
+}
+
+// hedgedGetFile starts the local read and, if it fails or is still running after hedgeDelay,
+// the object store read. The first successful result wins and the other read is cancelled.
+func (m *MultiProvider) hedgedGetFile(ctx context.Context, path string) ([]byte, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Buffered for both reads so the loser never blocks after we return.
+	results := make(chan getFileResult, 2)
+	go func() {
+		content, err := m.local.GetFile(ctx, path)
+		results <- getFileResult{content: content, err: err}
+	}()
+
+	hedge := time.NewTimer(m.hedgeDelay)
+	defer hedge.Stop()
+
+	pending, remoteStarted := 1, false
+	startRemote := func() {
+		if remoteStarted {
+			return
+		}
+		remoteStarted = true
+		pending++
+		go func() {
+			content, err := m.objectStore.GetFile(ctx, path)
+			results <- getFileResult{content: content, err: err, remote: true}
+		}()
+	}
+
+	var lastErr error
+	for pending > 0 {
+		select {
+		case <-hedge.C:
+			contextLogger(ctx, m.logger).Info("hedging multi provider read", "path", path, "delay", m.hedgeDelay)
+			startRemote()
+		case res := <-results:
+			pending--
+			if res.err == nil {
+				return res.content, nil
+			}
+			if res.remote || lastErr == nil {
+				lastErr = res.err
+			}
+			startRemote()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	return nil, lastErr
+}
+
 func (m *MultiProvider) DeleteFile(ctx context.Context, path string) error {
 	m.local.DeleteFile(ctx, path)
 	return m.objectStore.DeleteFile(ctx, path)
@@ -63,7 +215,67 @@ func (m *MultiProvider) GetPresignedURL(ctx context.Context, path string, expire
 	return m.objectStore.GetPresignedURL(ctx, path, expires)
 }
 
+// StatFile asks the object store, which is the source of truth for stored objects, and falls
+// back to the local copy when the store cannot stat.
+func (m *MultiProvider) StatFile(ctx context.Context, path string) (*ObjectInfo, error) {
+	if statter, ok := m.objectStore.(FileStatter); ok {
+		return statter.StatFile(ctx, path)
+	}
+	return m.local.StatFile(ctx, path)
+}
+
+// ConditionalWrites follows the object store, since it decides whether a write goes ahead.
+func (m *MultiProvider) ConditionalWrites() bool {
+	writer, ok := m.objectStore.(ConditionalWriter)
+	return ok && writer.ConditionalWrites()
+}
+
+// List delegates to the object store, which is the source of truth for stored objects.
+func (m *MultiProvider) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	if m.objectStore == nil {
+		return nil, fmt.Errorf("multi provider: object store not configured")
+	}
+
+	lister, ok := m.objectStore.(Lister)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+
+	return lister.List(ctx, prefix)
+}
+
+func (m *MultiProvider) ProviderName() string {
+	if describer, ok := m.objectStore.(ProviderDescriber); ok {
+		return "multi:" + describer.ProviderName()
+	}
+	return "multi"
+}
+
+// Replicas reports the local mirror written alongside the object store.
+func (m *MultiProvider) Replicas() []string {
+	return []string{m.local.ProviderName()}
+}
+
+// ChunkLimits reports the object store limits since it receives the parts.
+func (m *MultiProvider) ChunkLimits() ChunkLimits {
+	if limiter, ok := m.objectStore.(ChunkLimiter); ok {
+		return limiter.ChunkLimits()
+	}
+	return ChunkLimits{MaxParts: MaxChunkParts}
+}
+
+func (m *MultiProvider) ProviderKey(path string) string {
+	if describer, ok := m.objectStore.(ProviderDescriber); ok {
+		return describer.ProviderKey(path)
+	}
+	return path
+}
+
 func (m *MultiProvider) Validate(ctx context.Context) error {
+	if m.optionErr != nil {
+		return fmt.Errorf("multi provider: invalid option: %w", m.optionErr)
+	}
+
 	if m.local == nil {
 		return fmt.Errorf("multi provider: local provider not configured")
 	}
@@ -115,11 +327,11 @@ func (m *MultiProvider) CompleteChunked(ctx context.Context, session *ChunkSessi
 	// sync to local storage for caching
 	content, err := m.objectStore.GetFile(ctx, session.Key)
 	if err != nil {
-		return nil, fmt.Errorf("multi provider: fetch completed file: %w", err)
+		return nil, m.rollbackObjectStore(ctx, session.Key, fmt.Errorf("multi provider: fetch completed file: %w", err))
 	}
 
 	if _, err := m.local.UploadFile(ctx, session.Key, content, WithContentType(meta.ContentType)); err != nil {
-		return nil, fmt.Errorf("multi provider: sync to local storage: %w", err)
+		return nil, m.rollbackObjectStore(ctx, session.Key, fmt.Errorf("multi provider: sync to local storage: %w", err))
 	}
 
 	return meta, nil
@@ -143,6 +355,38 @@ func (m *MultiProvider) CreatePresignedPost(ctx context.Context, key string, met
 	return presigner.CreatePresignedPost(ctx, key, metadata)
 }
 
+func (m *MultiProvider) PresignChunkPart(ctx context.Context, session *ChunkSession, index int, ttl time.Duration) (*PresignedRequest, error) {
+	presigner, err := m.presignedChunkObjectStore()
+	if err != nil {
+		return nil, err
+	}
+
+	return presigner.PresignChunkPart(ctx, session, index, ttl)
+}
+
+func (m *MultiProvider) PresignCompleteChunked(ctx context.Context, session *ChunkSession, ttl time.Duration) (*PresignedRequest, error) {
+	presigner, err := m.presignedChunkObjectStore()
+	if err != nil {
+		return nil, err
+	}
+
+	return presigner.PresignCompleteChunked(ctx, session, ttl)
+}
+
+// CollectGarbage delegates to the object store, which owns chunked sessions.
+func (m *MultiProvider) CollectGarbage(ctx context.Context, olderThan time.Time) (int, error) {
+	if m.objectStore == nil {
+		return 0, fmt.Errorf("multi provider: object store not configured")
+	}
+
+	collector, ok := m.objectStore.(GarbageCollector)
+	if !ok {
+		return 0, ErrNotImplemented
+	}
+
+	return collector.CollectGarbage(ctx, olderThan)
+}
+
 func validateOptional(ctx context.Context, provider Uploader) error {
 	validator, ok := provider.(ProviderValidator)
 	if !ok {
@@ -181,3 +425,16 @@ func (m *MultiProvider) presignedObjectStore() (PresignedPoster, error) {
 
 	return presigner, nil
 }
+
+func (m *MultiProvider) presignedChunkObjectStore() (PresignedChunkUploader, error) {
+	if m.objectStore == nil {
+		return nil, fmt.Errorf("multi provider: object store not configured")
+	}
+
+	presigner, ok := m.objectStore.(PresignedChunkUploader)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+
+	return presigner, nil
+}
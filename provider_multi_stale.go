@@ -0,0 +1,103 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"time"
+)
+
+// staleGetFile serves path from the local copy and revalidates copies older than staleAfter in
+// the background. Only a local miss waits for the object store.
+func (m *MultiProvider) staleGetFile(ctx context.Context, path string) ([]byte, error) {
+	modTime, err := m.local.modTime(path)
+	if err != nil {
+		return m.fetchAndCache(ctx, path)
+	}
+
+	content, err := m.local.GetFile(ctx, path)
+	if err != nil {
+		return m.fetchAndCache(ctx, path)
+	}
+
+	if time.Since(modTime) > m.staleAfter {
+		m.revalidate(ctx, path, modTime)
+	}
+	return content, nil
+}
+
+// fetchAndCache reads path from the object store and stores it locally. A failed local write is
+// logged; the caller still gets the content.
+func (m *MultiProvider) fetchAndCache(ctx context.Context, path string) ([]byte, error) {
+	content, err := m.objectStore.GetFile(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := m.local.UploadFile(ctx, path, content); err != nil {
+		contextLogger(ctx, m.logger).Error("multi provider cache write failed", err, "path", path)
+	}
+	return content, nil
+}
+
+// revalidate refreshes the local copy of path in the background with a context detached from the
+// request. A path is refreshed by one goroutine at a time; an object removed from the object
+// store is removed locally too. The refresh is dropped when the copy read at served changes in
+// the meantime, so it never overwrites a newer upload.
+func (m *MultiProvider) revalidate(ctx context.Context, path string, served time.Time) {
+	m.refreshMu.Lock()
+	if m.refreshing[path] {
+		m.refreshMu.Unlock()
+		return
+	}
+	if m.refreshing == nil {
+		m.refreshing = make(map[string]bool)
+	}
+	m.refreshing[path] = true
+	m.refreshMu.Unlock()
+
+	ctx = context.WithoutCancel(ctx)
+	m.refreshes.Add(1)
+	go func() {
+		defer func() {
+			m.refreshMu.Lock()
+			delete(m.refreshing, path)
+			m.refreshMu.Unlock()
+			m.refreshes.Done()
+		}()
+
+		content, err := m.objectStore.GetFile(ctx, path)
+		if current, statErr := m.local.modTime(path); statErr != nil || !current.Equal(served) {
+			return
+		}
+		switch {
+		case errors.Is(err, ErrImageNotFound):
+			if err := m.local.DeleteFile(ctx, path); err != nil && !errors.Is(err, ErrImageNotFound) {
+				contextLogger(ctx, m.logger).Error("multi provider stale copy removal failed", err, "path", path)
+			}
+		case err != nil:
+			contextLogger(ctx, m.logger).Error("multi provider revalidation failed", err, "path", path)
+		default:
+			if _, err := m.local.UploadFile(ctx, path, content); err != nil {
+				contextLogger(ctx, m.logger).Error("multi provider cache write failed", err, "path", path)
+			}
+		}
+	}()
+}
+
+// Wait blocks until in-flight background revalidations finish, e.g. before shutdown.
+func (m *MultiProvider) Wait() {
+	m.refreshes.Wait()
+}
+
+// modTime reports when the file at path was last written, without hashing it as StatFile does.
+func (p *FSProvider) modTime(path string) (time.Time, error) {
+	info, err := fs.Stat(p.root, filepath.Clean(path))
+	if err != nil {
+		return time.Time{}, err
+	}
+	if info.IsDir() {
+		return time.Time{}, ErrImageNotFound
+	}
+	return info.ModTime(), nil
+}
@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"testing"
+	"testing/fstest"
 	"time"
 )
 
@@ -232,6 +234,134 @@ func TestMultiProviderGetFile(t *testing.T) {
 	})
 }
 
+type slowFS struct {
+	fs.FS
+	delay time.Duration
+}
+
+func (s slowFS) Open(name string) (fs.File, error) {
+	time.Sleep(s.delay)
+	return s.FS.Open(name)
+}
+
+func TestMultiProviderHedgedGetFile(t *testing.T) {
+	local := fstest.MapFS{"test.jpg": {Data: []byte("local content")}}
+
+	t.Run("object store wins when local is slow", func(t *testing.T) {
+		localProvider := NewFSProvider(t.TempDir()).WithFS(slowFS{FS: local, delay: 200 * time.Millisecond})
+		objectStore := &mockProvider{
+			getFunc: func(ctx context.Context, path string) ([]byte, error) {
+				return []byte("remote content"), nil
+			},
+		}
+
+		provider := NewMultiProvider(localProvider, objectStore).WithHedgeDelay(10 * time.Millisecond)
+
+		content, err := provider.GetFile(context.Background(), "test.jpg")
+		if err != nil {
+			t.Fatalf("GetFile failed: %v", err)
+		}
+		if string(content) != "remote content" {
+			t.Fatalf("expected hedged read to win, got %q", content)
+		}
+	})
+
+	t.Run("fast local read skips object store", func(t *testing.T) {
+		localProvider := NewFSProvider(t.TempDir()).WithFS(local)
+		objectStore := &mockProvider{
+			getFunc: func(ctx context.Context, path string) ([]byte, error) {
+				t.Error("object store should not be called when local answers within the budget")
+				return nil, nil
+			},
+		}
+
+		provider := NewMultiProvider(localProvider, objectStore).WithHedgeDelay(time.Second)
+
+		content, err := provider.GetFile(context.Background(), "test.jpg")
+		if err != nil || string(content) != "local content" {
+			t.Fatalf("expected local content, got %q (%v)", content, err)
+		}
+	})
+
+	t.Run("slow local wins when object store fails", func(t *testing.T) {
+		localProvider := NewFSProvider(t.TempDir()).WithFS(slowFS{FS: local, delay: 50 * time.Millisecond})
+		objectStore := &mockProvider{
+			getFunc: func(ctx context.Context, path string) ([]byte, error) {
+				return nil, errors.New("object store error")
+			},
+		}
+
+		provider := NewMultiProvider(localProvider, objectStore).WithHedgeDelay(time.Millisecond)
+
+		content, err := provider.GetFile(context.Background(), "test.jpg")
+		if err != nil || string(content) != "local content" {
+			t.Fatalf("expected local content, got %q (%v)", content, err)
+		}
+	})
+
+	t.Run("reports object store error when both fail", func(t *testing.T) {
+		localProvider := NewFSProvider(t.TempDir())
+		objectStore := &mockProvider{
+			getFunc: func(ctx context.Context, path string) ([]byte, error) {
+				return nil, errors.New("object store error")
+			},
+		}
+
+		provider := NewMultiProvider(localProvider, objectStore).WithHedgeDelay(time.Second)
+
+		if _, err := provider.GetFile(context.Background(), "missing.jpg"); err == nil || err.Error() != "object store error" {
+			t.Fatalf("expected object store error, got %v", err)
+		}
+	})
+}
+
+func TestMultiProviderStaleWhileRevalidate(t *testing.T) {
+	ctx := context.Background()
+	remote := newMemoryProvider()
+	dir := t.TempDir()
+	provider := NewMultiProvider(NewFSProvider(dir), remote, WithMultiStaleWhileRevalidate(time.Minute))
+
+	remote.UploadFile(ctx, "doc.txt", []byte("v1"))
+	if content, err := provider.GetFile(ctx, "doc.txt"); err != nil || string(content) != "v1" {
+		t.Fatalf("expected local miss to read the object store, got %q (%v)", content, err)
+	}
+	if cached, err := os.ReadFile(filepath.Join(dir, "doc.txt")); err != nil || string(cached) != "v1" {
+		t.Fatalf("expected miss to be cached locally, got %q (%v)", cached, err)
+	}
+
+	remote.UploadFile(ctx, "doc.txt", []byte("v2"))
+	if content, _ := provider.GetFile(ctx, "doc.txt"); string(content) != "v1" {
+		t.Fatalf("expected fresh local copy to be served, got %q", content)
+	}
+	provider.Wait()
+	if cached, _ := os.ReadFile(filepath.Join(dir, "doc.txt")); string(cached) != "v1" {
+		t.Fatalf("expected fresh copy not to be revalidated, got %q", cached)
+	}
+
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(filepath.Join(dir, "doc.txt"), old, old)
+	if content, _ := provider.GetFile(ctx, "doc.txt"); string(content) != "v1" {
+		t.Fatalf("expected stale copy to be served immediately, got %q", content)
+	}
+	provider.Wait()
+	if content, _ := provider.GetFile(ctx, "doc.txt"); string(content) != "v2" {
+		t.Fatalf("expected stale copy to be refreshed, got %q", content)
+	}
+
+	remote.DeleteFile(ctx, "doc.txt")
+	os.Chtimes(filepath.Join(dir, "doc.txt"), old, old)
+	provider.GetFile(ctx, "doc.txt")
+	provider.Wait()
+	if _, err := os.Stat(filepath.Join(dir, "doc.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected copy of a removed object to be dropped, got %v", err)
+	}
+
+	invalid := NewMultiProvider(NewFSProvider(dir), remote, WithMultiStaleWhileRevalidate(-time.Second))
+	if err := invalid.Validate(ctx); err == nil {
+		t.Fatalf("expected negative max age to be rejected")
+	}
+}
+
 func TestMultiProviderChunkedLifecycle(t *testing.T) {
 	ctx := context.Background()
 	localDir := t.TempDir()
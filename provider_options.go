@@ -0,0 +1,466 @@
+package uploader
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// AWSProviderOption configures an AWSProvider in NewAWSProvider. Invalid values are reported by
+// Validate, and therefore by the manager's WithProvider.
+type AWSProviderOption func(*AWSProvider) error
+
+// FSProviderOption configures an FSProvider in NewFSProvider. Invalid values are reported by
+// Validate.
+type FSProviderOption func(*FSProvider) error
+
+// MultiProviderOption configures a MultiProvider in NewMultiProvider. Invalid values are
+// reported by Validate.
+type MultiProviderOption func(*MultiProvider) error
+
+// FailoverProviderOption configures a FailoverProvider in NewFailoverProviderWithOptions.
+// Invalid values are reported by Validate.
+type FailoverProviderOption func(*FailoverProvider) error
+
+// ShardedProviderOption configures a ShardedProvider in NewShardedProviderWithOptions. Invalid
+// values are reported by Validate.
+type ShardedProviderOption func(*ShardedProvider) error
+
+// TeeProviderOption configures a TeeProvider in NewTeeProvider. Invalid values are reported by
+// Validate.
+type TeeProviderOption func(*TeeProvider) error
+
+// TieredProviderOption configures a TieredProvider in NewTieredProviderWithOptions. Invalid
+// values are reported by Validate.
+type TieredProviderOption func(*TieredProvider) error
+
+// GeoProviderOption configures a GeoProvider in NewGeoProvider. Invalid values are reported by
+// Validate.
+type GeoProviderOption func(*GeoProvider) error
+
+func WithAWSLogger(l Logger) AWSProviderOption {
+	return func(p *AWSProvider) error {
+		if l == nil {
+			return errors.New("logger is nil")
+		}
+		p.logger = l
+		return nil
+	}
+}
+
+// WithAWSBasePath prefixes every key with basePath, which must not contain ".." segments.
+func WithAWSBasePath(basePath string) AWSProviderOption {
+	return func(p *AWSProvider) error {
+		if err := validateBasePath(basePath); err != nil {
+			return err
+		}
+		p.basePath = basePath
+		return nil
+	}
+}
+
+// WithAWSBufferPool sets the pool used to read downloads and chunk payloads. Nil selects
+// DefaultBufferPool.
+func WithAWSBufferPool(pool *BufferPool) AWSProviderOption {
+	return func(p *AWSProvider) error {
+		p.buffers = pool
+		return nil
+	}
+}
+
+// WithAWSScopedCredentials enables temporary credentials for presigned posts, minted by
+// assuming roleARN.
+func WithAWSScopedCredentials(client *sts.Client, roleARN string) AWSProviderOption {
+	return func(p *AWSProvider) error {
+		if client == nil {
+			return errors.New("sts client is nil")
+		}
+		if !strings.HasPrefix(roleARN, "arn:") {
+			return fmt.Errorf("invalid role ARN %q", roleARN)
+		}
+		p.sts = client
+		p.roleARN = roleARN
+		return nil
+	}
+}
+
+// WithAWSRequestOptions applies opts to every request the provider makes.
+func WithAWSRequestOptions(opts S3RequestOptions) AWSProviderOption {
+	return func(p *AWSProvider) error {
+		p.requestOpts = opts
+		return nil
+	}
+}
+
+// WithAWSObjectLock enables SetHold and SetRetention through S3 Object Lock. The bucket must
+// have Object Lock enabled. mode is "GOVERNANCE" (privileged users may shorten retention) or
+// "COMPLIANCE" (nobody can).
+func WithAWSObjectLock(mode string) AWSProviderOption {
+	return func(p *AWSProvider) error {
+		switch lock := types.ObjectLockRetentionMode(mode); lock {
+		case types.ObjectLockRetentionModeGovernance, types.ObjectLockRetentionModeCompliance:
+			p.retentionMode = lock
+			return nil
+		default:
+			return fmt.Errorf("aws provider: unknown object lock mode %q", mode)
+		}
+	}
+}
+
+// WithAWSPublicBaseURL sets the URL public-read objects are served from, such as
+// "https://bucket.s3.eu-west-1.amazonaws.com" or a CDN domain, so Manager.URLFor can hand out
+// unsigned links for them.
+func WithAWSPublicBaseURL(baseURL string) AWSProviderOption {
+	return func(p *AWSProvider) error {
+		u, err := url.Parse(baseURL)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid public base URL %q", baseURL)
+		}
+		p.publicBaseURL = baseURL
+		return nil
+	}
+}
+
+func (p *AWSProvider) apply(opts ...AWSProviderOption) {
+	for _, opt := range opts {
+		if err := opt(p); err != nil {
+			p.optionErr = errors.Join(p.optionErr, err)
+		}
+	}
+}
+
+func WithFSLogger(l Logger) FSProviderOption {
+	return func(p *FSProvider) error {
+		if l == nil {
+			return errors.New("logger is nil")
+		}
+		p.logger = l
+		return nil
+	}
+}
+
+// WithFSRoot replaces the fs.FS used for reads, e.g. with an embedded or in-memory filesystem.
+func WithFSRoot(root fs.FS) FSProviderOption {
+	return func(p *FSProvider) error {
+		if root == nil {
+			return errors.New("root filesystem is nil")
+		}
+		p.root = root
+		return nil
+	}
+}
+
+// WithFSChunkDir sets where chunk parts are staged before assembly. Keep it outside any publicly
+// served directory.
+func WithFSChunkDir(dir string) FSProviderOption {
+	return func(p *FSProvider) error {
+		if dir == "" {
+			return errors.New("chunk dir is empty")
+		}
+		p.chunkRoot = dir
+		return nil
+	}
+}
+
+// WithFSURLPrefix sets the prefix of the URLs returned for stored files.
+func WithFSURLPrefix(prefix string) FSProviderOption {
+	return func(p *FSProvider) error {
+		if !strings.HasSuffix(prefix, "/") {
+			prefix = prefix + "/"
+		}
+		p.urlPrefix = prefix
+		return nil
+	}
+}
+
+// WithFSURLPolicy selects the URL the provider reports for stored files; FSURLPrefixed by default.
+func WithFSURLPolicy(policy FSURLPolicy) FSProviderOption {
+	return func(p *FSProvider) error {
+		switch policy {
+		case FSURLPrefixed, FSURLRelative, FSURLAbsolutePath:
+			p.urlPolicy = policy
+			return nil
+		default:
+			return fmt.Errorf("unknown url policy %q", policy)
+		}
+	}
+}
+
+func (p *FSProvider) apply(opts ...FSProviderOption) {
+	for _, opt := range opts {
+		if err := opt(p); err != nil {
+			p.optionErr = errors.Join(p.optionErr, err)
+		}
+	}
+}
+
+func WithMultiLogger(l Logger) MultiProviderOption {
+	return func(p *MultiProvider) error {
+		if l == nil {
+			return errors.New("logger is nil")
+		}
+		p.logger = l
+		return nil
+	}
+}
+
+// WithMultiBufferPool sets the pool used when a stream has to be buffered. Nil selects
+// DefaultBufferPool.
+func WithMultiBufferPool(pool *BufferPool) MultiProviderOption {
+	return func(p *MultiProvider) error {
+		p.buffers = pool
+		return nil
+	}
+}
+
+// WithMultiHedgeDelay makes GetFile also ask the object store when the local read has not
+// finished within delay, returning whichever succeeds first. Zero keeps reads sequential.
+func WithMultiHedgeDelay(delay time.Duration) MultiProviderOption {
+	return func(p *MultiProvider) error {
+		if delay < 0 {
+			return fmt.Errorf("hedge delay must not be negative, got %s", delay)
+		}
+		p.hedgeDelay = delay
+		return nil
+	}
+}
+
+// WithMultiPartialPolicy sets what happens to the object store copy when the local mirror write
+// fails: PartialCleanup (the default) deletes it so both stores agree, PartialKeep leaves it.
+func WithMultiPartialPolicy(policy PartialUploadPolicy) MultiProviderOption {
+	return func(p *MultiProvider) error {
+		if err := validatePartialPolicy(policy); err != nil {
+			return err
+		}
+		p.partial = policy
+		return nil
+	}
+}
+
+// WithMultiStaleWhileRevalidate makes GetFile serve the local copy right away and, when it is
+// older than maxAge, refresh it from the object store in the background. Local misses are read
+// from the object store and cached. It takes precedence over WithMultiHedgeDelay; zero disables
+// it.
+func WithMultiStaleWhileRevalidate(maxAge time.Duration) MultiProviderOption {
+	return func(p *MultiProvider) error {
+		if maxAge < 0 {
+			return fmt.Errorf("stale max age must not be negative, got %s", maxAge)
+		}
+		p.staleAfter = maxAge
+		return nil
+	}
+}
+
+func (p *MultiProvider) apply(opts ...MultiProviderOption) {
+	for _, opt := range opts {
+		if err := opt(p); err != nil {
+			p.optionErr = errors.Join(p.optionErr, err)
+		}
+	}
+}
+
+func WithFailoverLogger(l Logger) FailoverProviderOption {
+	return func(p *FailoverProvider) error {
+		if l == nil {
+			return errors.New("logger is nil")
+		}
+		p.logger = l
+		return nil
+	}
+}
+
+// WithFailoverReadPolicy sets how GetFile, StatFile and GetPresignedURL pick a provider.
+func WithFailoverReadPolicy(policy FailoverReadPolicy) FailoverProviderOption {
+	return func(p *FailoverProvider) error {
+		switch policy {
+		case FailoverReadCascade, FailoverReadPrimary:
+		default:
+			return fmt.Errorf("unknown read policy %q", policy)
+		}
+		p.reads = policy
+		return nil
+	}
+}
+
+// WithFailoverWritePolicy sets how UploadFile and DeleteFile react to a failing primary.
+func WithFailoverWritePolicy(policy FailoverWritePolicy) FailoverProviderOption {
+	return func(p *FailoverProvider) error {
+		switch policy {
+		case FailoverWriteFirstAvailable, FailoverWritePrimary:
+		default:
+			return fmt.Errorf("unknown write policy %q", policy)
+		}
+		p.writes = policy
+		return nil
+	}
+}
+
+func (p *FailoverProvider) apply(opts ...FailoverProviderOption) {
+	for _, opt := range opts {
+		if err := opt(p); err != nil {
+			p.optionErr = errors.Join(p.optionErr, err)
+		}
+	}
+}
+
+func WithShardedLogger(l Logger) ShardedProviderOption {
+	return func(p *ShardedProvider) error {
+		if l == nil {
+			return errors.New("logger is nil")
+		}
+		p.logger = l
+		return nil
+	}
+}
+
+// WithShardPrefixRule routes every key starting with prefix to the named shard, bypassing the
+// hash ring. The longest matching prefix wins.
+func WithShardPrefixRule(prefix, shard string) ShardedProviderOption {
+	return func(p *ShardedProvider) error {
+		if prefix == "" {
+			return errors.New("prefix rule prefix is empty")
+		}
+		p.rules = append(p.rules, shardPrefixRule{prefix: prefix, shard: shard})
+		sort.SliceStable(p.rules, func(i, j int) bool {
+			return len(p.rules[i].prefix) > len(p.rules[j].prefix)
+		})
+		return nil
+	}
+}
+
+func (p *ShardedProvider) apply(opts ...ShardedProviderOption) {
+	for _, opt := range opts {
+		if err := opt(p); err != nil {
+			p.optionErr = errors.Join(p.optionErr, err)
+		}
+	}
+}
+
+func WithTeeLogger(l Logger) TeeProviderOption {
+	return func(p *TeeProvider) error {
+		if l == nil {
+			return errors.New("logger is nil")
+		}
+		p.logger = l
+		return nil
+	}
+}
+
+// WithTeeRetry sets how often a copy is attempted and the wait before the second attempt, which
+// doubles after each failure.
+func WithTeeRetry(attempts int, backoff time.Duration) TeeProviderOption {
+	return func(p *TeeProvider) error {
+		if attempts < 1 {
+			return fmt.Errorf("retry attempts must be at least 1, got %d", attempts)
+		}
+		if backoff < 0 {
+			return fmt.Errorf("retry backoff must not be negative, got %s", backoff)
+		}
+		p.attempts = attempts
+		p.backoff = backoff
+		return nil
+	}
+}
+
+// WithTeeQueueSize bounds the copies running or waiting to retry at once. Uploads beyond it are
+// recorded as pending without being copied, so a slow sink cannot pile up memory.
+func WithTeeQueueSize(size int) TeeProviderOption {
+	return func(p *TeeProvider) error {
+		if size < 1 {
+			return fmt.Errorf("queue size must be at least 1, got %d", size)
+		}
+		p.queueSize = size
+		return nil
+	}
+}
+
+func (p *TeeProvider) apply(opts ...TeeProviderOption) {
+	for _, opt := range opts {
+		if err := opt(p); err != nil {
+			p.optionErr = errors.Join(p.optionErr, err)
+		}
+	}
+}
+
+func WithTieredLogger(l Logger) TieredProviderOption {
+	return func(p *TieredProvider) error {
+		if l == nil {
+			return errors.New("logger is nil")
+		}
+		p.logger = l
+		return nil
+	}
+}
+
+func (p *TieredProvider) apply(opts ...TieredProviderOption) {
+	for _, opt := range opts {
+		if err := opt(p); err != nil {
+			p.optionErr = errors.Join(p.optionErr, err)
+		}
+	}
+}
+
+func WithGeoLogger(l Logger) GeoProviderOption {
+	return func(p *GeoProvider) error {
+		if l == nil {
+			return errors.New("logger is nil")
+		}
+		p.logger = l
+		return nil
+	}
+}
+
+// WithGeoRegion sets the region used when the request context does not carry one.
+func WithGeoRegion(region string) GeoProviderOption {
+	return func(p *GeoProvider) error {
+		p.region = region
+		return nil
+	}
+}
+
+// WithGeoReplicationLag sets how long after a write reads of that key go to the primary. Zero
+// always reads from replicas.
+func WithGeoReplicationLag(lag time.Duration) GeoProviderOption {
+	return func(p *GeoProvider) error {
+		if lag < 0 {
+			return fmt.Errorf("replication lag must not be negative, got %s", lag)
+		}
+		p.lag = lag
+		return nil
+	}
+}
+
+// WithGeoUnhealthyCooldown sets how long a failing replica is skipped before it is tried again.
+func WithGeoUnhealthyCooldown(cooldown time.Duration) GeoProviderOption {
+	return func(p *GeoProvider) error {
+		if cooldown < 0 {
+			return fmt.Errorf("unhealthy cooldown must not be negative, got %s", cooldown)
+		}
+		p.cooldown = cooldown
+		return nil
+	}
+}
+
+func (p *GeoProvider) apply(opts ...GeoProviderOption) {
+	for _, opt := range opts {
+		if err := opt(p); err != nil {
+			p.optionErr = errors.Join(p.optionErr, err)
+		}
+	}
+}
+
+func validateBasePath(basePath string) error {
+	for _, segment := range strings.Split(basePath, "/") {
+		if segment == ".." {
+			return fmt.Errorf("base path %q must not contain .. segments", basePath)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package uploader
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+func TestProviderFunctionalOptions(t *testing.T) {
+	logger := &mockLogger{}
+	pool := NewBufferPool(1024)
+
+	aws := NewAWSProvider(&s3.Client{}, "bucket",
+		WithAWSLogger(logger),
+		WithAWSBasePath("uploads"),
+		WithAWSBufferPool(pool),
+		WithAWSRequestOptions(S3RequestOptions{RequestPayer: true}),
+	)
+	if aws.logger != logger || aws.basePath != "uploads" || aws.buffers != pool || !aws.requestOpts.RequestPayer || aws.optionErr != nil {
+		t.Fatalf("aws options not applied: %#v", aws)
+	}
+
+	root := fstest.MapFS{}
+	local := NewFSProvider(t.TempDir(),
+		WithFSLogger(logger),
+		WithFSRoot(root),
+		WithFSChunkDir(t.TempDir()),
+		WithFSURLPrefix("/static"),
+	)
+	if local.logger != logger || local.urlPrefix != "/static/" || local.optionErr != nil {
+		t.Fatalf("fs options not applied: %#v", local)
+	}
+	if err := local.Validate(context.Background()); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	multi := NewMultiProvider(local, &mockProvider{}, WithMultiLogger(logger), WithMultiHedgeDelay(time.Millisecond))
+	if multi.logger != logger || multi.hedgeDelay != time.Millisecond || multi.optionErr != nil {
+		t.Fatalf("multi options not applied: %#v", multi)
+	}
+
+	failover := NewFailoverProviderWithOptions(local, []Uploader{&mockProvider{}},
+		WithFailoverLogger(logger),
+		WithFailoverReadPolicy(FailoverReadPrimary),
+		WithFailoverWritePolicy(FailoverWritePrimary),
+	)
+	if failover.logger != logger || failover.reads != FailoverReadPrimary || failover.writes != FailoverWritePrimary || failover.optionErr != nil {
+		t.Fatalf("failover options not applied: %#v", failover)
+	}
+
+	sharded := NewShardedProviderWithOptions([]Shard{{Name: "a", Provider: local}},
+		WithShardedLogger(logger),
+		WithShardPrefixRule("a/", "a"),
+		WithShardPrefixRule("a/b/", "a"),
+	)
+	if sharded.logger != logger || len(sharded.rules) != 2 || sharded.rules[0].prefix != "a/b/" || sharded.optionErr != nil {
+		t.Fatalf("sharded options not applied: %#v", sharded)
+	}
+
+	geo := NewGeoProvider(local, map[string]Uploader{"eu": &mockProvider{}},
+		WithGeoLogger(logger),
+		WithGeoRegion("eu"),
+		WithGeoReplicationLag(time.Second),
+		WithGeoUnhealthyCooldown(time.Minute),
+	)
+	if geo.logger != logger || geo.region != "eu" || geo.lag != time.Second || geo.cooldown != time.Minute || geo.optionErr != nil {
+		t.Fatalf("geo options not applied: %#v", geo)
+	}
+}
+
+func TestProviderOptionValidation(t *testing.T) {
+	ctx := context.Background()
+
+	aws := NewAWSProvider(&s3.Client{}, "bucket", WithAWSBasePath("uploads/../secrets"), WithAWSLogger(nil))
+	err := aws.Validate(ctx)
+	if err == nil || !strings.Contains(err.Error(), "invalid option") || !strings.Contains(err.Error(), "logger is nil") {
+		t.Fatalf("expected option errors from Validate, got %v", err)
+	}
+
+	local := NewFSProvider(t.TempDir(), WithFSChunkDir(""))
+	if err := local.Validate(ctx); err == nil || !strings.Contains(err.Error(), "chunk dir is empty") {
+		t.Fatalf("expected chunk dir error, got %v", err)
+	}
+
+	multi := NewMultiProvider(NewFSProvider(t.TempDir()), &mockProvider{}, WithMultiHedgeDelay(-time.Second))
+	if err := multi.Validate(ctx); err == nil || !strings.Contains(err.Error(), "hedge delay") {
+		t.Fatalf("expected hedge delay error, got %v", err)
+	}
+
+	failover := NewFailoverProviderWithOptions(&mockProvider{}, nil, WithFailoverReadPolicy("nearest"))
+	if err := failover.Validate(ctx); err == nil || !strings.Contains(err.Error(), "unknown read policy") {
+		t.Fatalf("expected read policy error, got %v", err)
+	}
+
+	sharded := NewShardedProviderWithOptions([]Shard{{Name: "a", Provider: &mockProvider{}}}, WithShardPrefixRule("", "a"))
+	if err := sharded.Validate(ctx); err == nil || !strings.Contains(err.Error(), "prefix is empty") {
+		t.Fatalf("expected prefix rule error, got %v", err)
+	}
+
+	geo := NewGeoProvider(&mockProvider{}, nil, WithGeoReplicationLag(-time.Second), WithGeoUnhealthyCooldown(-time.Second))
+	if err := geo.Validate(ctx); err == nil || !strings.Contains(err.Error(), "replication lag") || !strings.Contains(err.Error(), "unhealthy cooldown") {
+		t.Fatalf("expected geo duration errors, got %v", err)
+	}
+
+	// Deprecated builders share the option validation.
+	legacy := NewFSProvider(t.TempDir()).WithFS(nil)
+	if err := legacy.Validate(ctx); err == nil {
+		t.Fatalf("expected builder to record invalid root")
+	}
+	if err := NewFailoverProvider(&mockProvider{}).WithWritePolicy("all").Validate(ctx); err == nil {
+		t.Fatalf("expected builder to record invalid write policy")
+	}
+}
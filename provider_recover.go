@@ -0,0 +1,36 @@
+package uploader
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+)
+
+// observeCall runs call as an observed provider operation (see Manager.observe). A panic inside
+// the provider is recovered, logged with its stack and returned as ErrProviderPanic, so a faulty
+// custom provider fails the request instead of crashing the service, and the call is still
+// released for SwapProvider.
+func observeCall[T any](ctx context.Context, m *Manager, op, key string, call func() (T, error)) (result T, err error) {
+	done := m.observe(op, key)
+	defer func() {
+		if r := recover(); r != nil {
+			err = m.providerPanic(ctx, op, key, r)
+		}
+		done(err)
+	}()
+	return call()
+}
+
+// observeErr is observeCall for provider calls that only return an error.
+func (m *Manager) observeErr(ctx context.Context, op, key string, call func() error) error {
+	_, err := observeCall(ctx, m, op, key, func() (struct{}, error) {
+		return struct{}{}, call()
+	})
+	return err
+}
+
+func (m *Manager) providerPanic(ctx context.Context, op, key string, recovered any) error {
+	err := fmt.Errorf("%w: %s %q: %v", ErrProviderPanic, op, key, recovered)
+	m.log(ctx).Error("provider panicked", err, "operation", op, "key", key, "stack", string(debug.Stack()))
+	return err
+}
@@ -0,0 +1,50 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type panickingProvider struct {
+	*memoryProvider
+}
+
+func (p *panickingProvider) GetFile(ctx context.Context, path string) ([]byte, error) {
+	panic("corrupt index")
+}
+
+func TestProviderPanicRecovered(t *testing.T) {
+	ctx := context.Background()
+	logger := &argsLogger{}
+	manager := NewManager(WithProvider(&panickingProvider{newMemoryProvider()}), WithLogger(logger))
+
+	_, err := manager.GetFile(ctx, "a.txt")
+	if !errors.Is(err, ErrProviderPanic) || !strings.Contains(err.Error(), "corrupt index") {
+		t.Fatalf("expected ErrProviderPanic, got %v", err)
+	}
+	assertTextCode(t, err, "PROVIDER_PANIC")
+
+	var stack string
+	for _, line := range logger.lines {
+		if line[0] != "provider panicked" {
+			continue
+		}
+		for i := 1; i+1 < len(line); i++ {
+			if line[i] == "stack" {
+				stack, _ = line[i+1].(string)
+			}
+		}
+	}
+	if !strings.Contains(stack, "panickingProvider") {
+		t.Fatalf("expected the stack logged, got %v", logger.lines)
+	}
+
+	swapCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if err := manager.SwapProvider(swapCtx, newMemoryProvider()); err != nil {
+		t.Fatalf("expected the panicked call released for SwapProvider, got %v", err)
+	}
+}
@@ -0,0 +1,404 @@
+package uploader
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"mime"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	_ Uploader          = &ShardedProvider{}
+	_ ProviderDescriber = &ShardedProvider{}
+	_ ProviderValidator = &ShardedProvider{}
+	_ Lister            = &ShardedProvider{}
+	_ FileStatter       = &ShardedProvider{}
+	_ RangeReader       = &ShardedProvider{}
+	_ ChunkedUploader   = &ShardedProvider{}
+	_ GarbageCollector  = &ShardedProvider{}
+)
+
+// Shard is one backend of a ShardedProvider. Weight scales its share of hashed keys; zero counts
+// as one.
+type Shard struct {
+	Name     string
+	Provider Uploader
+	Weight   int
+}
+
+// ShardedProvider routes keys across several backends (typically buckets) so no single one has
+// to hold every object. Prefix rules pin key ranges to a shard; every other key is placed on a
+// consistent hash ring, so adding a shard only moves the keys it takes over.
+type ShardedProvider struct {
+	logger Logger
+	shards map[string]Shard
+	order  []string
+	rules  []shardPrefixRule
+	ring   []shardRingPoint
+
+	optionErr error
+}
+
+type shardPrefixRule struct {
+	prefix string
+	shard  string
+}
+
+type shardRingPoint struct {
+	hash  uint64
+	shard string
+}
+
+// NewShardedProvider creates a provider routing keys over shards. Use
+// NewShardedProviderWithOptions to configure it.
+func NewShardedProvider(shards ...Shard) *ShardedProvider {
+	return NewShardedProviderWithOptions(shards)
+}
+
+// NewShardedProviderWithOptions creates a provider routing keys over shards, configured by opts.
+func NewShardedProviderWithOptions(shards []Shard, opts ...ShardedProviderOption) *ShardedProvider {
+	p := &ShardedProvider{
+		logger: &DefaultLogger{},
+		shards: make(map[string]Shard, len(shards)),
+	}
+	for _, shard := range shards {
+		p.shards[shard.Name] = shard
+		p.order = append(p.order, shard.Name)
+	}
+	p.buildRing()
+	p.apply(opts...)
+	return p
+}
+
+// Deprecated: pass WithShardedLogger to NewShardedProviderWithOptions.
+func (p *ShardedProvider) WithLogger(l Logger) *ShardedProvider {
+	p.apply(WithShardedLogger(l))
+	return p
+}
+
+// WithPrefixRule routes every key starting with prefix to the named shard, bypassing the hash
+// ring. The longest matching prefix wins.
+//
+// Deprecated: pass WithShardPrefixRule to NewShardedProviderWithOptions.
+func (p *ShardedProvider) WithPrefixRule(prefix, shard string) *ShardedProvider {
+	p.apply(WithShardPrefixRule(prefix, shard))
+	return p
+}
+
+func (p *ShardedProvider) buildRing() {
+	p.ring = p.ring[:0]
+	for _, name := range p.order {
+		weight := p.shards[name].Weight
+		if weight <= 0 {
+			weight = 1
+		}
+		for i := 0; i < weight*DefaultShardVirtualNodes; i++ {
+			p.ring = append(p.ring, shardRingPoint{hash: shardHash(name + "#" + strconv.Itoa(i)), shard: name})
+		}
+	}
+	sort.Slice(p.ring, func(i, j int) bool {
+		return p.ring[i].hash < p.ring[j].hash
+	})
+}
+
+// ShardFor returns the name of the shard that owns key.
+func (p *ShardedProvider) ShardFor(key string) string {
+	for _, rule := range p.rules {
+		if strings.HasPrefix(key, rule.prefix) {
+			return rule.shard
+		}
+	}
+
+	if len(p.ring) == 0 {
+		return ""
+	}
+	h := shardHash(key)
+	idx := sort.Search(len(p.ring), func(i int) bool { return p.ring[i].hash >= h })
+	if idx == len(p.ring) {
+		idx = 0
+	}
+	return p.ring[idx].shard
+}
+
+func (p *ShardedProvider) route(key string) (Uploader, error) {
+	name := p.ShardFor(key)
+	shard, ok := p.shards[name]
+	if !ok || shard.Provider == nil {
+		return nil, fmt.Errorf("sharded provider: shard %q not configured", name)
+	}
+	return shard.Provider, nil
+}
+
+func (p *ShardedProvider) UploadFile(ctx context.Context, path string, content []byte, opts ...UploadOption) (string, error) {
+	provider, err := p.route(path)
+	if err != nil {
+		return "", err
+	}
+	return provider.UploadFile(ctx, path, content, opts...)
+}
+
+func (p *ShardedProvider) GetFile(ctx context.Context, path string) ([]byte, error) {
+	provider, err := p.route(path)
+	if err != nil {
+		return nil, err
+	}
+	return provider.GetFile(ctx, path)
+}
+
+func (p *ShardedProvider) DeleteFile(ctx context.Context, path string) error {
+	provider, err := p.route(path)
+	if err != nil {
+		return err
+	}
+	return provider.DeleteFile(ctx, path)
+}
+
+func (p *ShardedProvider) GetPresignedURL(ctx context.Context, path string, expires time.Duration) (string, error) {
+	provider, err := p.route(path)
+	if err != nil {
+		return "", err
+	}
+	return provider.GetPresignedURL(ctx, path, expires)
+}
+
+func (p *ShardedProvider) StatFile(ctx context.Context, path string) (*ObjectInfo, error) {
+	provider, err := p.route(path)
+	if err != nil {
+		return nil, err
+	}
+	statter, ok := provider.(FileStatter)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+	return statter.StatFile(ctx, path)
+}
+
+func (p *ShardedProvider) OpenRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	provider, err := p.route(path)
+	if err != nil {
+		return nil, err
+	}
+	reader, ok := provider.(RangeReader)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+	return reader.OpenRange(ctx, path, offset, length)
+}
+
+// List merges the listings of every shard, sorted by key.
+func (p *ShardedProvider) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var out []ObjectInfo
+	for _, name := range p.order {
+		objects, err := p.listShard(ctx, name, prefix)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, objects...)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Key < out[j].Key
+	})
+	return out, nil
+}
+
+func (p *ShardedProvider) listShard(ctx context.Context, name, prefix string) ([]ObjectInfo, error) {
+	lister, ok := p.shards[name].Provider.(Lister)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+	objects, err := lister.List(ctx, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("sharded provider: list %s: %w", name, err)
+	}
+	return objects, nil
+}
+
+func (p *ShardedProvider) InitiateChunked(ctx context.Context, session *ChunkSession) (*ChunkSession, error) {
+	chunked, err := p.chunkedShard(session)
+	if err != nil {
+		return nil, err
+	}
+	return chunked.InitiateChunked(ctx, session)
+}
+
+func (p *ShardedProvider) UploadChunk(ctx context.Context, session *ChunkSession, index int, payload io.Reader) (ChunkPart, error) {
+	chunked, err := p.chunkedShard(session)
+	if err != nil {
+		return ChunkPart{}, err
+	}
+	return chunked.UploadChunk(ctx, session, index, payload)
+}
+
+func (p *ShardedProvider) CompleteChunked(ctx context.Context, session *ChunkSession) (*FileMeta, error) {
+	chunked, err := p.chunkedShard(session)
+	if err != nil {
+		return nil, err
+	}
+	return chunked.CompleteChunked(ctx, session)
+}
+
+func (p *ShardedProvider) AbortChunked(ctx context.Context, session *ChunkSession) error {
+	chunked, err := p.chunkedShard(session)
+	if err != nil {
+		return err
+	}
+	return chunked.AbortChunked(ctx, session)
+}
+
+func (p *ShardedProvider) chunkedShard(session *ChunkSession) (ChunkedUploader, error) {
+	if session == nil {
+		return nil, fmt.Errorf("sharded provider: chunk session is nil")
+	}
+	provider, err := p.route(session.Key)
+	if err != nil {
+		return nil, err
+	}
+	chunked, ok := provider.(ChunkedUploader)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+	return chunked, nil
+}
+
+// CollectGarbage runs on every shard that supports it and returns the combined count.
+func (p *ShardedProvider) CollectGarbage(ctx context.Context, olderThan time.Time) (int, error) {
+	total := 0
+	for _, name := range p.order {
+		collector, ok := p.shards[name].Provider.(GarbageCollector)
+		if !ok {
+			continue
+		}
+		n, err := collector.CollectGarbage(ctx, olderThan)
+		total += n
+		if err != nil {
+			return total, fmt.Errorf("sharded provider: collect garbage on %s: %w", name, err)
+		}
+	}
+	return total, nil
+}
+
+func (p *ShardedProvider) Validate(ctx context.Context) error {
+	if p.optionErr != nil {
+		return fmt.Errorf("sharded provider: invalid option: %w", p.optionErr)
+	}
+	if len(p.shards) == 0 {
+		return fmt.Errorf("sharded provider: no shards configured")
+	}
+
+	for _, rule := range p.rules {
+		if _, ok := p.shards[rule.shard]; !ok {
+			return fmt.Errorf("sharded provider: prefix rule %q targets unknown shard %q", rule.prefix, rule.shard)
+		}
+	}
+
+	for _, name := range p.order {
+		provider := p.shards[name].Provider
+		if provider == nil {
+			return fmt.Errorf("sharded provider: shard %q not configured", name)
+		}
+		if err := validateOptional(ctx, provider); err != nil {
+			return fmt.Errorf("sharded provider: shard %q validation failed: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (p *ShardedProvider) ProviderName() string {
+	return "sharded"
+}
+
+func (p *ShardedProvider) ProviderKey(path string) string {
+	provider, err := p.route(path)
+	if err != nil {
+		return path
+	}
+	if describer, ok := provider.(ProviderDescriber); ok {
+		return describer.ProviderKey(path)
+	}
+	return path
+}
+
+// ShardMove describes an object stored on a shard other than the one that owns it now.
+type ShardMove struct {
+	Key  string `json:"key"`
+	From string `json:"from"`
+	To   string `json:"to"`
+	Err  error  `json:"-"`
+}
+
+// ShardRebalanceResult summarizes a Rebalance run. Failed moves carry their error and leave the
+// object on its old shard.
+type ShardRebalanceResult struct {
+	Scanned  int
+	Moves    []ShardMove
+	Failures []ShardMove
+}
+
+// Rebalance moves objects under prefix to the shard that owns them after shards or prefix rules
+// changed. Each object is copied before it is deleted from its old shard. With dryRun the moves
+// are only reported.
+func (p *ShardedProvider) Rebalance(ctx context.Context, prefix string, dryRun bool) (*ShardRebalanceResult, error) {
+	result := &ShardRebalanceResult{}
+	for _, name := range p.order {
+		objects, err := p.listShard(ctx, name, prefix)
+		if err != nil {
+			return result, err
+		}
+		result.Scanned += len(objects)
+
+		for _, obj := range objects {
+			if err := ctx.Err(); err != nil {
+				return result, err
+			}
+
+			owner := p.ShardFor(obj.Key)
+			if owner == name {
+				continue
+			}
+
+			move := ShardMove{Key: obj.Key, From: name, To: owner}
+			if !dryRun {
+				move.Err = p.moveObject(ctx, move)
+			}
+			if move.Err != nil {
+				contextLogger(ctx, p.logger).Error("shard rebalance move failed", move.Err, "key", move.Key, "from", move.From, "to", move.To)
+				result.Failures = append(result.Failures, move)
+				continue
+			}
+			result.Moves = append(result.Moves, move)
+		}
+	}
+	return result, nil
+}
+
+func (p *ShardedProvider) moveObject(ctx context.Context, move ShardMove) error {
+	from, to := p.shards[move.From].Provider, p.shards[move.To].Provider
+	if to == nil {
+		return fmt.Errorf("sharded provider: shard %q not configured", move.To)
+	}
+
+	content, err := from.GetFile(ctx, move.Key)
+	if err != nil {
+		return fmt.Errorf("read: %w", err)
+	}
+	if _, err := to.UploadFile(ctx, move.Key, content, WithContentType(mime.TypeByExtension(path.Ext(move.Key)))); err != nil {
+		return fmt.Errorf("write: %w", err)
+	}
+	if err := from.DeleteFile(ctx, move.Key); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+	return nil
+}
+
+func shardHash(value string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(value))
+	return h.Sum64()
+}
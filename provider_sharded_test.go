@@ -0,0 +1,76 @@
+package uploader
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestShardedProviderRoutingAndRebalance(t *testing.T) {
+	ctx := context.Background()
+	dirs := map[string]string{"a": t.TempDir(), "b": t.TempDir(), "c": t.TempDir()}
+
+	provider := NewShardedProviderWithOptions([]Shard{
+		{Name: "a", Provider: NewFSProvider(dirs["a"])},
+		{Name: "b", Provider: NewFSProvider(dirs["b"])},
+	}, WithShardPrefixRule("avatars/", "b"))
+
+	if err := provider.Validate(ctx); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	keys := []string{"avatars/1.txt", "avatars/2.txt"}
+	for i := 0; i < 40; i++ {
+		keys = append(keys, fmt.Sprintf("docs/%d.txt", i))
+	}
+
+	used := map[string]int{}
+	for _, key := range keys {
+		if _, err := provider.UploadFile(ctx, key, []byte(key)); err != nil {
+			t.Fatalf("UploadFile %s: %v", key, err)
+		}
+		shard := provider.ShardFor(key)
+		used[shard]++
+		if _, err := os.Stat(filepath.Join(dirs[shard], key)); err != nil {
+			t.Fatalf("expected %s on shard %s: %v", key, shard, err)
+		}
+	}
+	if provider.ShardFor("avatars/1.txt") != "b" || used["a"] == 0 {
+		t.Fatalf("expected prefix rule and hashing to both place keys, got %v", used)
+	}
+
+	objects, err := provider.List(ctx, "")
+	if err != nil || len(objects) != len(keys) {
+		t.Fatalf("expected merged listing of %d keys, got %d (%v)", len(keys), len(objects), err)
+	}
+
+	grown := NewShardedProviderWithOptions([]Shard{
+		{Name: "a", Provider: NewFSProvider(dirs["a"])},
+		{Name: "b", Provider: NewFSProvider(dirs["b"])},
+		{Name: "c", Provider: NewFSProvider(dirs["c"])},
+	}, WithShardPrefixRule("avatars/", "b"))
+
+	plan, err := grown.Rebalance(ctx, "", true)
+	if err != nil || len(plan.Moves) == 0 || plan.Scanned != len(keys) {
+		t.Fatalf("expected dry run to plan moves, got %#v (%v)", plan, err)
+	}
+	for _, move := range plan.Moves {
+		if move.To != "c" {
+			t.Fatalf("expected keys to move only to the new shard, got %#v", move)
+		}
+	}
+
+	result, err := grown.Rebalance(ctx, "", false)
+	if err != nil || len(result.Failures) != 0 || len(result.Moves) != len(plan.Moves) {
+		t.Fatalf("unexpected rebalance result %#v (%v)", result, err)
+	}
+
+	for _, key := range keys {
+		content, err := grown.GetFile(ctx, key)
+		if err != nil || string(content) != key {
+			t.Fatalf("expected %s readable after rebalance, got %q (%v)", key, content, err)
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	_ Uploader          = &TeeProvider{}
+	_ ProviderDescriber = &TeeProvider{}
+	_ ProviderValidator = &TeeProvider{}
+	_ Lister            = &TeeProvider{}
+	_ FileStatter       = &TeeProvider{}
+	_ ChunkedUploader   = &TeeProvider{}
+	_ Replicator        = &TeeProvider{}
+)
+
+const (
+	// DefaultTeeAttempts is how often TeeProvider tries a copy before leaving it pending.
+	DefaultTeeAttempts = 3
+	// DefaultTeeBackoff is the wait before the second attempt; it doubles after each failure.
+	DefaultTeeBackoff = time.Second
+	// DefaultTeeQueueSize bounds the copies TeeProvider runs or retries at once.
+	DefaultTeeQueueSize = 64
+)
+
+// TeeProvider writes to a primary provider and copies every successful upload to a sink, such
+// as a backup bucket or a data lake, in the background. The sink never slows down or fails the
+// write: copies are retried with backoff, and copies that still fail, or that do not fit the
+// queue, stay pending until Retry succeeds. Reads, presigned URLs and deletes only use the
+// primary, so the sink keeps objects deleted from it.
+type TeeProvider struct {
+	logger    Logger
+	primary   Uploader
+	sink      Uploader
+	attempts  int
+	backoff   time.Duration
+	queueSize int
+	optionErr error
+
+	mu      sync.Mutex
+	pending map[string]uint64
+	seq     uint64
+	queued  int
+	wg      sync.WaitGroup
+}
+
+// NewTeeProvider creates a provider writing to primary and mirroring uploads to sink.
+func NewTeeProvider(primary, sink Uploader, opts ...TeeProviderOption) *TeeProvider {
+	p := &TeeProvider{
+		logger:    &DefaultLogger{},
+		primary:   primary,
+		sink:      sink,
+		attempts:  DefaultTeeAttempts,
+		backoff:   DefaultTeeBackoff,
+		queueSize: DefaultTeeQueueSize,
+		pending:   make(map[string]uint64),
+	}
+	p.apply(opts...)
+	return p
+}
+
+// Deprecated: pass WithTeeLogger to NewTeeProvider.
+func (p *TeeProvider) WithLogger(l Logger) *TeeProvider {
+	p.apply(WithTeeLogger(l))
+	return p
+}
+
+// WithRetry sets how often a copy is attempted and the wait before the second attempt, which
+// doubles after each failure. Values below one attempt are raised to one.
+//
+// Deprecated: pass WithTeeRetry to NewTeeProvider.
+func (p *TeeProvider) WithRetry(attempts int, backoff time.Duration) *TeeProvider {
+	p.apply(WithTeeRetry(max(attempts, 1), backoff))
+	return p
+}
+
+// WithQueueSize bounds the copies running or waiting to retry at once. Values below one are
+// raised to one.
+//
+// Deprecated: pass WithTeeQueueSize to NewTeeProvider.
+func (p *TeeProvider) WithQueueSize(size int) *TeeProvider {
+	p.apply(WithTeeQueueSize(max(size, 1)))
+	return p
+}
+
+func (p *TeeProvider) UploadFile(ctx context.Context, path string, content []byte, opts ...UploadOption) (string, error) {
+	url, err := p.primary.UploadFile(ctx, path, content, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	p.tee(ctx, path, func(ctx context.Context) error {
+		_, err := p.sink.UploadFile(ctx, path, content, mirrorOptions(opts)...)
+		return err
+	})
+	return url, nil
+}
+
+func (p *TeeProvider) GetFile(ctx context.Context, path string) ([]byte, error) {
+	return p.primary.GetFile(ctx, path)
+}
+
+// DeleteFile deletes from the primary only; the sink keeps its copy.
+func (p *TeeProvider) DeleteFile(ctx context.Context, path string) error {
+	return p.primary.DeleteFile(ctx, path)
+}
+
+func (p *TeeProvider) GetPresignedURL(ctx context.Context, path string, expires time.Duration) (string, error) {
+	return p.primary.GetPresignedURL(ctx, path, expires)
+}
+
+func (p *TeeProvider) StatFile(ctx context.Context, path string) (*ObjectInfo, error) {
+	statter, ok := p.primary.(FileStatter)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+	return statter.StatFile(ctx, path)
+}
+
+func (p *TeeProvider) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	lister, ok := p.primary.(Lister)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+	return lister.List(ctx, prefix)
+}
+
+func (p *TeeProvider) InitiateChunked(ctx context.Context, session *ChunkSession) (*ChunkSession, error) {
+	chunked, err := p.chunkedPrimary()
+	if err != nil {
+		return nil, err
+	}
+	return chunked.InitiateChunked(ctx, session)
+}
+
+func (p *TeeProvider) UploadChunk(ctx context.Context, session *ChunkSession, index int, payload io.Reader) (ChunkPart, error) {
+	chunked, err := p.chunkedPrimary()
+	if err != nil {
+		return ChunkPart{}, err
+	}
+	return chunked.UploadChunk(ctx, session, index, payload)
+}
+
+// CompleteChunked completes the upload on the primary and copies the assembled object, read
+// back from the primary, to the sink.
+func (p *TeeProvider) CompleteChunked(ctx context.Context, session *ChunkSession) (*FileMeta, error) {
+	chunked, err := p.chunkedPrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := chunked.CompleteChunked(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+
+	p.tee(ctx, session.Key, func(ctx context.Context) error {
+		return p.copyFromPrimary(ctx, session.Key)
+	})
+	return meta, nil
+}
+
+func (p *TeeProvider) AbortChunked(ctx context.Context, session *ChunkSession) error {
+	chunked, err := p.chunkedPrimary()
+	if err != nil {
+		return err
+	}
+	return chunked.AbortChunked(ctx, session)
+}
+
+func (p *TeeProvider) chunkedPrimary() (ChunkedUploader, error) {
+	chunked, ok := p.primary.(ChunkedUploader)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+	return chunked, nil
+}
+
+// Validate checks both providers, so a misconfigured sink is caught at startup rather than by
+// copies piling up as pending.
+func (p *TeeProvider) Validate(ctx context.Context) error {
+	if p.optionErr != nil {
+		return fmt.Errorf("tee provider: invalid option: %w", p.optionErr)
+	}
+	if p.primary == nil {
+		return fmt.Errorf("tee provider: primary not configured")
+	}
+	if p.sink == nil {
+		return fmt.Errorf("tee provider: sink not configured")
+	}
+	if err := validateOptional(ctx, p.primary); err != nil {
+		return fmt.Errorf("tee provider: primary validation failed: %w", err)
+	}
+	if err := validateOptional(ctx, p.sink); err != nil {
+		return fmt.Errorf("tee provider: sink validation failed: %w", err)
+	}
+	return nil
+}
+
+func (p *TeeProvider) ProviderName() string {
+	return "tee:" + describeProvider(p.primary, 0)
+}
+
+func (p *TeeProvider) ProviderKey(path string) string {
+	if describer, ok := p.primary.(ProviderDescriber); ok {
+		return describer.ProviderKey(path)
+	}
+	return path
+}
+
+// Replicas names the sink uploads are copied to.
+func (p *TeeProvider) Replicas() []string {
+	return []string{describeProvider(p.sink, 1)}
+}
+
+// Pending returns the keys whose latest upload has not reached the sink yet.
+func (p *TeeProvider) Pending() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	keys := make([]string, 0, len(p.pending))
+	for key := range p.pending {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Retry copies pending keys to the sink, reading their content from the primary. Keys the
+// primary no longer holds are dropped. It returns how many keys were copied.
+func (p *TeeProvider) Retry(ctx context.Context) (int, error) {
+	p.mu.Lock()
+	work := make(map[string]uint64, len(p.pending))
+	for key, seq := range p.pending {
+		work[key] = seq
+	}
+	p.mu.Unlock()
+
+	copied := 0
+	var errs []error
+	for key, seq := range work {
+		if err := ctx.Err(); err != nil {
+			return copied, err
+		}
+
+		err := p.copyFromPrimary(ctx, key)
+		switch {
+		case err == nil:
+			copied++
+			p.settle(key, seq)
+		case errors.Is(err, ErrImageNotFound):
+			p.settle(key, seq)
+		default:
+			errs = append(errs, err)
+		}
+	}
+
+	return copied, errors.Join(errs...)
+}
+
+// Wait blocks until in-flight background copies finish, e.g. before shutdown.
+func (p *TeeProvider) Wait() {
+	p.wg.Wait()
+}
+
+// copyFromPrimary copies key from the primary to the sink, carrying over the content type and
+// cache control the primary reports.
+func (p *TeeProvider) copyFromPrimary(ctx context.Context, key string) error {
+	var opts []UploadOption
+	info, err := p.StatFile(ctx, key)
+	switch {
+	case err == nil:
+		if info.ContentType != "" {
+			opts = append(opts, WithContentType(info.ContentType))
+		}
+		if info.CacheControl != "" {
+			opts = append(opts, WithCacheControl(info.CacheControl))
+		}
+	case !errors.Is(err, ErrNotImplemented):
+		return fmt.Errorf("tee provider: stat %s: %w", key, err)
+	}
+
+	content, err := p.primary.GetFile(ctx, key)
+	if err != nil {
+		return fmt.Errorf("tee provider: read %s: %w", key, err)
+	}
+	if _, err := p.sink.UploadFile(ctx, key, content, opts...); err != nil {
+		return fmt.Errorf("tee provider: copy %s: %w", key, err)
+	}
+	return nil
+}
+
+// tee records key as pending and runs apply in the background with a context detached from the
+// request, retrying with backoff. When the queue is full the key is only recorded.
+func (p *TeeProvider) tee(ctx context.Context, key string, apply func(context.Context) error) {
+	p.mu.Lock()
+	p.seq++
+	seq := p.seq
+	p.pending[key] = seq
+	if p.queued >= p.queueSize {
+		p.mu.Unlock()
+		contextLogger(ctx, p.logger).Info("tee provider queue full, copy left pending", "path", key)
+		return
+	}
+	p.queued++
+	p.mu.Unlock()
+
+	ctx = context.WithoutCancel(ctx)
+	p.wg.Add(1)
+	go func() {
+		defer func() {
+			p.mu.Lock()
+			p.queued--
+			p.mu.Unlock()
+			p.wg.Done()
+		}()
+
+		backoff := p.backoff
+		var err error
+		for attempt := 1; attempt <= p.attempts; attempt++ {
+			if err = apply(ctx); err == nil {
+				p.settle(key, seq)
+				return
+			}
+			if attempt < p.attempts {
+				time.Sleep(backoff)
+				backoff *= 2
+			}
+		}
+		contextLogger(ctx, p.logger).Error("tee provider copy failed", err, "path", key, "attempts", p.attempts)
+	}()
+}
+
+// settle clears key unless a newer upload replaced the entry seq belongs to.
+func (p *TeeProvider) settle(key string, seq uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.pending[key] == seq {
+		delete(p.pending, key)
+	}
+}
@@ -0,0 +1,142 @@
+package uploader
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTeeProvider(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("copies uploads to the sink", func(t *testing.T) {
+		primary, sink := newFlakyStore(), newFlakyStore()
+		provider := NewTeeProvider(primary, sink, WithTeeLogger(nopLogger{}))
+
+		if _, err := provider.UploadFile(ctx, "a.txt", []byte("a")); err != nil {
+			t.Fatalf("UploadFile: %v", err)
+		}
+		provider.Wait()
+
+		if !primary.has("a.txt") || !sink.has("a.txt") || len(provider.Pending()) != 0 {
+			t.Fatalf("expected the upload in both stores, pending %v", provider.Pending())
+		}
+
+		if err := provider.DeleteFile(ctx, "a.txt"); err != nil {
+			t.Fatalf("DeleteFile: %v", err)
+		}
+		if primary.has("a.txt") || !sink.has("a.txt") {
+			t.Fatalf("expected delete to leave the sink copy in place")
+		}
+	})
+
+	t.Run("sink outage does not fail the write", func(t *testing.T) {
+		primary, sink := newFlakyStore(), newFlakyStore()
+		provider := NewTeeProvider(primary, sink, WithTeeLogger(nopLogger{}), WithTeeRetry(2, time.Millisecond))
+
+		sink.setDown(true)
+		if _, err := provider.UploadFile(ctx, "b.txt", []byte("b")); err != nil {
+			t.Fatalf("expected write to succeed while the sink is down, got %v", err)
+		}
+		provider.Wait()
+		if got := provider.Pending(); !reflect.DeepEqual(got, []string{"b.txt"}) {
+			t.Fatalf("expected failed copy to stay pending, got %v", got)
+		}
+
+		sink.setDown(false)
+		if copied, err := provider.Retry(ctx); err != nil || copied != 1 {
+			t.Fatalf("Retry = %d, %v", copied, err)
+		}
+		if !sink.has("b.txt") || len(provider.Pending()) != 0 {
+			t.Fatalf("expected retry to copy the pending key")
+		}
+	})
+
+	t.Run("full queue leaves copies pending", func(t *testing.T) {
+		primary, sink := newFlakyStore(), newFlakyStore()
+		provider := NewTeeProvider(primary, sink, WithTeeLogger(nopLogger{}), WithTeeRetry(1, 0), WithTeeQueueSize(1))
+
+		provider.mu.Lock()
+		provider.queued = 1
+		provider.mu.Unlock()
+		provider.UploadFile(ctx, "c.txt", []byte("c"))
+		if sink.has("c.txt") || !reflect.DeepEqual(provider.Pending(), []string{"c.txt"}) {
+			t.Fatalf("expected copy beyond the queue to be recorded only, pending %v", provider.Pending())
+		}
+
+		primary.DeleteFile(ctx, "c.txt")
+		if copied, err := provider.Retry(ctx); err != nil || copied != 0 || len(provider.Pending()) != 0 {
+			t.Fatalf("expected keys gone from the primary to be dropped, got %d, %v, pending %v", copied, err, provider.Pending())
+		}
+	})
+	t.Run("retried copies keep the primary's metadata", func(t *testing.T) {
+		primary, sink := newMetaStore(), newMetaStore()
+		provider := NewTeeProvider(primary, sink, WithTeeLogger(nopLogger{}), WithTeeRetry(1, 0))
+
+		sink.setDown(true)
+		if _, err := provider.UploadFile(ctx, "d.css", []byte("d"), WithContentType("text/css"), WithCacheControl("max-age=60")); err != nil {
+			t.Fatalf("UploadFile: %v", err)
+		}
+		provider.Wait()
+
+		sink.setDown(false)
+		if copied, err := provider.Retry(ctx); err != nil || copied != 1 {
+			t.Fatalf("Retry = %d, %v", copied, err)
+		}
+		if md := sink.meta["d.css"]; md.ContentType != "text/css" || md.CacheControl != "max-age=60" {
+			t.Fatalf("expected the retried copy to keep its metadata, got %+v", md)
+		}
+	})
+}
+
+func TestTeeProviderOptionValidation(t *testing.T) {
+	ctx := context.Background()
+
+	provider := NewTeeProvider(newFlakyStore(), newFlakyStore(), WithTeeRetry(0, -time.Second), WithTeeQueueSize(0))
+	err := provider.Validate(ctx)
+	if err == nil || !strings.Contains(err.Error(), "retry attempts") || !strings.Contains(err.Error(), "queue size") {
+		t.Fatalf("expected option errors from Validate, got %v", err)
+	}
+
+	legacy := NewTeeProvider(newFlakyStore(), newFlakyStore()).WithRetry(0, 0).WithQueueSize(0)
+	if err := legacy.Validate(ctx); err != nil || legacy.attempts != 1 || legacy.queueSize != 1 {
+		t.Fatalf("expected deprecated builders to raise values to one, got %d, %d, %v", legacy.attempts, legacy.queueSize, err)
+	}
+}
+
+// metaStore is a flakyStore that keeps upload metadata and reports it from StatFile.
+type metaStore struct {
+	*flakyStore
+	meta map[string]Metadata
+}
+
+func newMetaStore() *metaStore {
+	return &metaStore{flakyStore: newFlakyStore(), meta: make(map[string]Metadata)}
+}
+
+func (s *metaStore) UploadFile(ctx context.Context, path string, content []byte, opts ...UploadOption) (string, error) {
+	url, err := s.flakyStore.UploadFile(ctx, path, content)
+	if err != nil {
+		return "", err
+	}
+	md := Metadata{}
+	for _, opt := range opts {
+		opt(&md)
+	}
+	s.mu.Lock()
+	s.meta[path] = md
+	s.mu.Unlock()
+	return url, nil
+}
+
+func (s *metaStore) StatFile(_ context.Context, path string) (*ObjectInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	md, ok := s.meta[path]
+	if !ok {
+		return nil, ErrImageNotFound
+	}
+	return &ObjectInfo{Key: path, Size: int64(len(s.files[path])), ContentType: md.ContentType, CacheControl: md.CacheControl}, nil
+}
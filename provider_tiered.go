@@ -0,0 +1,345 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+var (
+	_ Uploader          = &TieredProvider{}
+	_ ProviderDescriber = &TieredProvider{}
+	_ ProviderValidator = &TieredProvider{}
+	_ Lister            = &TieredProvider{}
+	_ FileStatter       = &TieredProvider{}
+	_ RangeReader       = &TieredProvider{}
+	_ StreamUploader    = &TieredProvider{}
+	_ ChunkedUploader   = &TieredProvider{}
+)
+
+// SizeTier is one backend of a TieredProvider, receiving objects of at most MaxSize bytes.
+type SizeTier struct {
+	Name     string
+	MaxSize  int64
+	Provider Uploader
+}
+
+// TieredProvider routes each write by size: small objects go to the first tier whose MaxSize
+// fits (an FS or database-backed provider), everything larger to the overflow provider (object
+// storage). Reads try the tiers from smallest to largest, so callers never need to know where an
+// object landed. Rewriting a key in another tier removes the copy the old tier held.
+type TieredProvider struct {
+	logger   Logger
+	tiers    []SizeTier
+	overflow SizeTier
+	buffers  *BufferPool
+
+	optionErr error
+}
+
+// NewTieredProvider creates a provider storing objects larger than every tier in overflow. Use
+// NewTieredProviderWithOptions to configure it.
+func NewTieredProvider(overflow Uploader, tiers ...SizeTier) *TieredProvider {
+	return NewTieredProviderWithOptions(overflow, tiers)
+}
+
+// NewTieredProviderWithOptions creates a provider storing objects larger than every tier in
+// overflow, configured by opts.
+func NewTieredProviderWithOptions(overflow Uploader, tiers []SizeTier, opts ...TieredProviderOption) *TieredProvider {
+	sorted := append([]SizeTier(nil), tiers...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].MaxSize < sorted[j].MaxSize
+	})
+
+	p := &TieredProvider{
+		logger:   &DefaultLogger{},
+		tiers:    sorted,
+		overflow: SizeTier{Name: "overflow", MaxSize: -1, Provider: overflow},
+		buffers:  DefaultBufferPool,
+	}
+	p.apply(opts...)
+	return p
+}
+
+// Deprecated: pass WithTieredLogger to NewTieredProviderWithOptions.
+func (p *TieredProvider) WithLogger(l Logger) *TieredProvider {
+	p.apply(WithTieredLogger(l))
+	return p
+}
+
+// TierFor returns the name of the tier an object of size bytes is written to.
+func (p *TieredProvider) TierFor(size int64) string {
+	return p.tierFor(size).Name
+}
+
+func (p *TieredProvider) tierFor(size int64) SizeTier {
+	for _, tier := range p.tiers {
+		if size <= tier.MaxSize {
+			return tier
+		}
+	}
+	return p.overflow
+}
+
+// all returns the tiers in read order, overflow last.
+func (p *TieredProvider) all() []SizeTier {
+	return append(p.tiers[:len(p.tiers):len(p.tiers)], p.overflow)
+}
+
+func (p *TieredProvider) UploadFile(ctx context.Context, path string, content []byte, opts ...UploadOption) (string, error) {
+	tier := p.tierFor(int64(len(content)))
+	url, err := tier.Provider.UploadFile(ctx, path, content, opts...)
+	if err != nil {
+		return "", err
+	}
+	p.evict(ctx, path, tier.Name)
+	return url, nil
+}
+
+// UploadStream streams r to the tier chosen by size; tiers without StreamUploader receive the
+// buffered content.
+func (p *TieredProvider) UploadStream(ctx context.Context, path string, r io.Reader, size int64, opts ...UploadOption) (string, error) {
+	tier := p.tierFor(size)
+	streamer, ok := tier.Provider.(StreamUploader)
+	if !ok {
+		content, err := p.buffers.ReadAll(r)
+		if err != nil {
+			return "", fmt.Errorf("tiered provider: read stream: %w", err)
+		}
+		return p.UploadFile(ctx, path, content, opts...)
+	}
+
+	url, err := streamer.UploadStream(ctx, path, r, size, opts...)
+	if err != nil {
+		return "", err
+	}
+	p.evict(ctx, path, tier.Name)
+	return url, nil
+}
+
+// evict removes path from every tier except keep, so reads never find a stale copy first.
+func (p *TieredProvider) evict(ctx context.Context, path, keep string) {
+	for _, tier := range p.all() {
+		if tier.Name == keep {
+			continue
+		}
+		if err := tier.Provider.DeleteFile(ctx, path); err != nil && !errors.Is(err, ErrImageNotFound) {
+			contextLogger(ctx, p.logger).Error("tiered provider: evict stale copy failed", err, "tier", tier.Name, "key", path)
+		}
+	}
+}
+
+func (p *TieredProvider) GetFile(ctx context.Context, path string) ([]byte, error) {
+	var content []byte
+	err := p.read(func(tier SizeTier) error {
+		var err error
+		content, err = tier.Provider.GetFile(ctx, path)
+		return err
+	})
+	return content, err
+}
+
+// DeleteFile deletes path from every tier. It returns ErrImageNotFound only when no tier held it.
+func (p *TieredProvider) DeleteFile(ctx context.Context, path string) error {
+	found := false
+	var errs []error
+	for _, tier := range p.all() {
+		err := tier.Provider.DeleteFile(ctx, path)
+		switch {
+		case err == nil:
+			found = true
+		case !errors.Is(err, ErrImageNotFound):
+			errs = append(errs, fmt.Errorf("tiered provider: delete from %s: %w", tier.Name, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	if !found {
+		return ErrImageNotFound
+	}
+	return nil
+}
+
+func (p *TieredProvider) GetPresignedURL(ctx context.Context, path string, expires time.Duration) (string, error) {
+	tier, err := p.locate(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	return tier.Provider.GetPresignedURL(ctx, path, expires)
+}
+
+func (p *TieredProvider) StatFile(ctx context.Context, path string) (*ObjectInfo, error) {
+	var info *ObjectInfo
+	err := p.read(func(tier SizeTier) error {
+		if statter, ok := tier.Provider.(FileStatter); ok {
+			var err error
+			info, err = statter.StatFile(ctx, path)
+			return err
+		}
+		content, err := tier.Provider.GetFile(ctx, path)
+		if err != nil {
+			return err
+		}
+		info = &ObjectInfo{Key: path, Size: int64(len(content))}
+		return nil
+	})
+	return info, err
+}
+
+func (p *TieredProvider) OpenRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error) {
+	tier, err := p.locate(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	reader, ok := tier.Provider.(RangeReader)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+	return reader.OpenRange(ctx, path, offset, length)
+}
+
+// List merges the listings of every tier, sorted by key.
+func (p *TieredProvider) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var out []ObjectInfo
+	for _, tier := range p.all() {
+		lister, ok := tier.Provider.(Lister)
+		if !ok {
+			return nil, ErrNotImplemented
+		}
+		objects, err := lister.List(ctx, prefix)
+		if err != nil {
+			return nil, fmt.Errorf("tiered provider: list %s: %w", tier.Name, err)
+		}
+		out = append(out, objects...)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Key < out[j].Key
+	})
+	return out, nil
+}
+
+func (p *TieredProvider) InitiateChunked(ctx context.Context, session *ChunkSession) (*ChunkSession, error) {
+	chunked, err := p.chunkedTier(session)
+	if err != nil {
+		return nil, err
+	}
+	return chunked.InitiateChunked(ctx, session)
+}
+
+func (p *TieredProvider) UploadChunk(ctx context.Context, session *ChunkSession, index int, payload io.Reader) (ChunkPart, error) {
+	chunked, err := p.chunkedTier(session)
+	if err != nil {
+		return ChunkPart{}, err
+	}
+	return chunked.UploadChunk(ctx, session, index, payload)
+}
+
+func (p *TieredProvider) CompleteChunked(ctx context.Context, session *ChunkSession) (*FileMeta, error) {
+	chunked, err := p.chunkedTier(session)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := chunked.CompleteChunked(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+	p.evict(ctx, session.Key, p.TierFor(session.TotalSize))
+	return meta, nil
+}
+
+func (p *TieredProvider) AbortChunked(ctx context.Context, session *ChunkSession) error {
+	chunked, err := p.chunkedTier(session)
+	if err != nil {
+		return err
+	}
+	return chunked.AbortChunked(ctx, session)
+}
+
+// chunkedTier routes a session by its declared size, like any other write.
+func (p *TieredProvider) chunkedTier(session *ChunkSession) (ChunkedUploader, error) {
+	if session == nil {
+		return nil, fmt.Errorf("tiered provider: chunk session is nil")
+	}
+	chunked, ok := p.tierFor(session.TotalSize).Provider.(ChunkedUploader)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+	return chunked, nil
+}
+
+func (p *TieredProvider) Validate(ctx context.Context) error {
+	if p.optionErr != nil {
+		return fmt.Errorf("tiered provider: invalid option: %w", p.optionErr)
+	}
+	if p.overflow.Provider == nil {
+		return fmt.Errorf("tiered provider: overflow provider not configured")
+	}
+
+	seen := map[string]bool{p.overflow.Name: true}
+	for _, tier := range p.tiers {
+		if tier.Provider == nil {
+			return fmt.Errorf("tiered provider: tier %q not configured", tier.Name)
+		}
+		if tier.MaxSize <= 0 {
+			return fmt.Errorf("tiered provider: tier %q needs a positive max size", tier.Name)
+		}
+		if seen[tier.Name] {
+			return fmt.Errorf("tiered provider: duplicate tier name %q", tier.Name)
+		}
+		seen[tier.Name] = true
+	}
+
+	for _, tier := range p.all() {
+		if err := validateOptional(ctx, tier.Provider); err != nil {
+			return fmt.Errorf("tiered provider: tier %q validation failed: %w", tier.Name, err)
+		}
+	}
+	return nil
+}
+
+func (p *TieredProvider) ProviderName() string {
+	return "tiered"
+}
+
+func (p *TieredProvider) ProviderKey(path string) string {
+	return path
+}
+
+// read runs fn against each tier in order until one finds the object. Errors other than
+// ErrImageNotFound stop the cascade, so an outage is not reported as a missing object.
+func (p *TieredProvider) read(fn func(SizeTier) error) error {
+	for _, tier := range p.all() {
+		err := fn(tier)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, ErrImageNotFound) {
+			return err
+		}
+	}
+	return ErrImageNotFound
+}
+
+// locate returns the tier holding path, using StatFile where the tier supports it.
+func (p *TieredProvider) locate(ctx context.Context, path string) (SizeTier, error) {
+	var found SizeTier
+	err := p.read(func(tier SizeTier) error {
+		var err error
+		if statter, ok := tier.Provider.(FileStatter); ok {
+			_, err = statter.StatFile(ctx, path)
+		} else {
+			_, err = tier.Provider.GetFile(ctx, path)
+		}
+		if err == nil {
+			found = tier
+		}
+		return err
+	})
+	return found, err
+}
@@ -0,0 +1,97 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTieredProviderRoutesBySize(t *testing.T) {
+	ctx := context.Background()
+	small, large := newMemoryProvider(), newMemoryProvider()
+	provider := NewTieredProviderWithOptions(large, []SizeTier{{Name: "inline", MaxSize: 4, Provider: small}}, WithTieredLogger(nopLogger{}))
+	manager := NewManager(WithProvider(provider))
+
+	if _, err := manager.UploadFile(ctx, "docs/a.txt", []byte("tiny")); err != nil {
+		t.Fatalf("UploadFile small: %v", err)
+	}
+	if _, err := manager.UploadFile(ctx, "docs/b.txt", []byte("larger")); err != nil {
+		t.Fatalf("UploadFile large: %v", err)
+	}
+	if _, ok := small.files["docs/a.txt"]; !ok {
+		t.Fatalf("expected small file in the inline tier")
+	}
+	if _, ok := large.files["docs/b.txt"]; !ok {
+		t.Fatalf("expected large file in the overflow tier")
+	}
+
+	for key, want := range map[string]string{"docs/a.txt": "tiny", "docs/b.txt": "larger"} {
+		got, err := manager.GetFile(ctx, key)
+		if err != nil || string(got) != want {
+			t.Fatalf("GetFile(%s) = %q, %v", key, got, err)
+		}
+	}
+
+	// Growing past the threshold moves the object and drops the stale small copy.
+	if _, err := manager.UploadFile(ctx, "docs/a.txt", []byte("grown")); err != nil {
+		t.Fatalf("UploadFile grown: %v", err)
+	}
+	if _, ok := small.files["docs/a.txt"]; ok {
+		t.Fatalf("expected stale copy evicted from the inline tier")
+	}
+	if got, _ := manager.GetFile(ctx, "docs/a.txt"); string(got) != "grown" {
+		t.Fatalf("expected rewritten content, got %q", got)
+	}
+
+	if err := manager.DeleteFile(ctx, "docs/a.txt"); err != nil {
+		t.Fatalf("DeleteFile: %v", err)
+	}
+	if _, err := manager.GetFile(ctx, "docs/a.txt"); !errors.Is(err, ErrImageNotFound) {
+		t.Fatalf("expected ErrImageNotFound after delete, got %v", err)
+	}
+}
+
+func TestTieredProviderHandleFileAndChunked(t *testing.T) {
+	ctx := context.Background()
+	small, large := NewFSProvider(t.TempDir()), newMemoryProvider()
+	provider := NewTieredProvider(large, SizeTier{Name: "inline", MaxSize: 64, Provider: small})
+	manager := NewManager(WithProvider(provider))
+
+	file := newTestFileHeader(t, "file", "photo.png", "image/png", createTestPNG(32, 32))
+	meta, err := manager.HandleFile(ctx, file, "images")
+	if err != nil {
+		t.Fatalf("HandleFile: %v", err)
+	}
+	if _, ok := large.files[meta.Name]; !ok || provider.TierFor(meta.Size) != "overflow" {
+		t.Fatalf("expected the image in the overflow tier")
+	}
+
+	session, err := manager.InitiateChunked(ctx, "chunks/small.bin", 4)
+	if err != nil {
+		t.Fatalf("InitiateChunked: %v", err)
+	}
+	if err := manager.UploadChunk(ctx, session.ID, 0, bytes.NewReader([]byte("abcd"))); err != nil {
+		t.Fatalf("UploadChunk: %v", err)
+	}
+	if _, err := manager.CompleteChunked(ctx, session.ID); err != nil {
+		t.Fatalf("CompleteChunked: %v", err)
+	}
+	if got, err := small.GetFile(ctx, "chunks/small.bin"); err != nil || string(got) != "abcd" {
+		t.Fatalf("expected the small session assembled in the inline tier, got %q (%v)", got, err)
+	}
+}
+
+func TestTieredProviderValidate(t *testing.T) {
+	ctx := context.Background()
+	if err := NewTieredProvider(newMemoryProvider(), SizeTier{Name: "inline", MaxSize: 0, Provider: newMemoryProvider()}).Validate(ctx); err == nil {
+		t.Fatalf("expected zero max size to fail validation")
+	}
+	if err := NewTieredProviderWithOptions(newMemoryProvider(), nil, WithTieredLogger(nil)).Validate(ctx); err == nil || !strings.Contains(err.Error(), "logger is nil") {
+		t.Fatalf("expected option error from Validate, got %v", err)
+	}
+	if err := NewTieredProvider(nil).Validate(ctx); err == nil {
+		t.Fatalf("expected missing overflow provider to fail validation")
+	}
+}
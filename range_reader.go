@@ -0,0 +1,85 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+)
+
+// RangeReader is implemented by providers that can stream an object, or a byte range of it,
+// without buffering it in memory. length < 0 reads to the end of the object. Callers must close
+// the returned reader; cancelling ctx aborts the underlying transfer.
+type RangeReader interface {
+	OpenRange(ctx context.Context, path string, offset, length int64) (io.ReadCloser, error)
+}
+
+// OpenRange streams length bytes of the object stored under key starting at offset, or the rest
+// of the object when length < 0. Providers without RangeReader fall back to GetFile, so the
+// stream is only memory-bounded when the provider supports ranged reads.
+func (m *Manager) OpenRange(ctx context.Context, key string, offset, length int64) (io.ReadCloser, error) {
+	if err := m.ensureProvider(ctx); err != nil {
+		return nil, err
+	}
+
+	provider := m.providerFor(ctx, key)
+	if reader, ok := provider.(RangeReader); ok {
+		rc, err := observeCall(ctx, m, "get_range", key, func() (io.ReadCloser, error) {
+			return reader.OpenRange(ctx, key, offset, length)
+		})
+		// Wrapping providers implement RangeReader even when the store behind them does not.
+		if !errors.Is(err, ErrNotImplemented) {
+			if err != nil {
+				return nil, err
+			}
+			return &countingReadCloser{ReadCloser: rc, count: func(n int) {
+				m.recordTransfer("get_range", 0, int64(n))
+			}}, nil
+		}
+	}
+
+	content, err := m.GetFile(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(sliceRange(content, offset, length))), nil
+}
+
+func sliceRange(content []byte, offset, length int64) []byte {
+	size := int64(len(content))
+	if offset > size {
+		offset = size
+	}
+	end := size
+	if length >= 0 && offset+length < size {
+		end = offset + length
+	}
+	return content[offset:end]
+}
+
+// limitedReadCloser caps reads from a ranged stream while closing the underlying source.
+type limitedReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func limitReadCloser(rc io.ReadCloser, length int64) io.ReadCloser {
+	if length < 0 {
+		return rc
+	}
+	return limitedReadCloser{Reader: io.LimitReader(rc, length), Closer: rc}
+}
+
+// countingReadCloser reports every read to count, so streamed bytes show up in Stats.
+type countingReadCloser struct {
+	io.ReadCloser
+	count func(n int)
+}
+
+func (r *countingReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.count(n)
+	}
+	return n, err
+}
@@ -0,0 +1,48 @@
+package uploader
+
+import (
+	"context"
+	"io"
+	"testing"
+)
+
+func TestManagerOpenRange(t *testing.T) {
+	ctx := context.Background()
+
+	providers := map[string]Uploader{
+		"fs":       NewFSProvider(t.TempDir()),
+		"fallback": newMemoryProvider(),
+	}
+
+	for name, provider := range providers {
+		manager := NewManager(WithProvider(provider))
+		if _, err := manager.UploadFile(ctx, "docs/a.txt", []byte("hello world")); err != nil {
+			t.Fatalf("%s: UploadFile failed: %v", name, err)
+		}
+
+		ranges := []struct {
+			offset, length int64
+			want           string
+		}{
+			{0, -1, "hello world"},
+			{6, -1, "world"},
+			{1, 4, "ello"},
+			{6, 100, "world"},
+		}
+		for _, tt := range ranges {
+			rc, err := manager.OpenRange(ctx, "docs/a.txt", tt.offset, tt.length)
+			if err != nil {
+				t.Fatalf("%s: OpenRange(%d, %d) failed: %v", name, tt.offset, tt.length, err)
+			}
+			got, err := io.ReadAll(rc)
+			rc.Close()
+			if err != nil || string(got) != tt.want {
+				t.Fatalf("%s: OpenRange(%d, %d) = %q, %v; want %q", name, tt.offset, tt.length, got, err, tt.want)
+			}
+		}
+
+		if _, err := manager.OpenRange(ctx, "docs/missing.txt", 0, -1); err == nil {
+			t.Fatalf("%s: expected error for missing key", name)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	gerrors "github.com/goliatone/go-errors"
+)
+
+// RateLimit caps what a single client identity may upload. A zero field is not enforced.
+type RateLimit struct {
+	UploadsPerMinute int
+	BytesPerHour     int64
+}
+
+// IdentityFunc extracts the client identity (user ID, API key, remote address) from the request
+// context. Calls with an empty identity are not rate limited.
+type IdentityFunc func(ctx context.Context) string
+
+type rateLimitExemptContextKey struct{}
+
+// WithRateLimit limits uploads per client identity with token buckets that start full and refill
+// continuously, so a client may burst up to the whole allowance and then proceeds at the
+// configured rate. Uploads, multipart files, staged files, chunked sessions (by their declared
+// size) and presigned posts (counted without bytes) all draw from the buckets. Rejected calls fail
+// with a RATE_LIMITED error (HTTP 429); RetryAfter reports when to try again.
+func WithRateLimit(identity IdentityFunc, limit RateLimit) Option {
+	return func(m *Manager) {
+		if identity == nil || (limit.UploadsPerMinute <= 0 && limit.BytesPerHour <= 0) {
+			m.rateLimiter = nil
+			return
+		}
+		m.rateLimiter = &rateLimiter{
+			identity: identity,
+			limit:    limit,
+			clients:  make(map[string]*clientAllowance),
+		}
+	}
+}
+
+// RetryAfter reports how long the client should wait before retrying a call rejected by the
+// rate limiter or throttled by the spam heuristics.
+func RetryAfter(err error) (time.Duration, bool) {
+	var uerr *gerrors.Error
+	if !errors.As(err, &uerr) || uerr.Category != gerrors.CategoryRateLimit {
+		return 0, false
+	}
+	seconds, ok := uerr.Metadata["retry_after_seconds"].(int)
+	if !ok {
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+// checkRateLimit spends one upload and size bytes from the caller's allowance.
+func (m *Manager) checkRateLimit(ctx context.Context, size int64) error {
+	if m.rateLimiter == nil || ctx.Value(rateLimitExemptContextKey{}) != nil {
+		return nil
+	}
+
+	identity := m.rateLimiter.identity(ctx)
+	if identity == "" {
+		return nil
+	}
+
+	limit, wait, ok := m.rateLimiter.allow(identity, size, m.now())
+	if ok {
+		return nil
+	}
+
+	return m.correlateError(ctx, gerrors.New("upload rate limit exceeded", gerrors.CategoryRateLimit).
+		WithCode(429).
+		WithTextCode("RATE_LIMITED").
+		WithMetadata(map[string]any{
+			"identity":            identity,
+			"limit":               limit,
+			"retry_after_seconds": int(math.Ceil(wait.Seconds())),
+		}))
+}
+
+// withoutRateLimit marks ctx so nested calls do not count an upload the caller already paid for.
+func withoutRateLimit(ctx context.Context) context.Context {
+	return context.WithValue(ctx, rateLimitExemptContextKey{}, true)
+}
+
+type rateLimiter struct {
+	identity IdentityFunc
+	limit    RateLimit
+
+	mu        sync.Mutex
+	clients   map[string]*clientAllowance
+	lastPrune time.Time
+}
+
+type clientAllowance struct {
+	uploads float64
+	bytes   float64
+	last    time.Time
+}
+
+// allow refills the identity's buckets and spends from them. When either bucket is short it
+// returns the exhausted limit and how long until it holds enough, spending nothing.
+func (l *rateLimiter) allow(identity string, size int64, now time.Time) (string, time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.prune(now)
+
+	uploadCap := float64(l.limit.UploadsPerMinute)
+	byteCap := float64(l.limit.BytesPerHour)
+
+	client, ok := l.clients[identity]
+	if !ok {
+		client = &clientAllowance{uploads: uploadCap, bytes: byteCap, last: now}
+		l.clients[identity] = client
+	}
+
+	if elapsed := now.Sub(client.last); elapsed > 0 {
+		client.uploads = math.Min(uploadCap, client.uploads+elapsed.Minutes()*uploadCap)
+		client.bytes = math.Min(byteCap, client.bytes+elapsed.Hours()*byteCap)
+		client.last = now
+	}
+
+	if uploadCap > 0 && client.uploads < 1 {
+		return "uploads_per_minute", time.Duration((1 - client.uploads) / uploadCap * float64(time.Minute)), false
+	}
+
+	if byteCap > 0 && client.bytes < float64(size) {
+		// A request larger than the whole allowance waits for a full bucket and is then
+		// rejected again; report the refill time anyway so clients back off.
+		need := math.Min(float64(size), byteCap)
+		return "bytes_per_hour", time.Duration((need - client.bytes) / byteCap * float64(time.Hour)), false
+	}
+
+	if uploadCap > 0 {
+		client.uploads--
+	}
+	if byteCap > 0 {
+		client.bytes -= float64(size)
+	}
+	return "", 0, true
+}
+
+// prune drops identities idle for an hour, by which time their buckets are full again.
+func (l *rateLimiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < time.Hour {
+		return
+	}
+	l.lastPrune = now
+
+	for identity, client := range l.clients {
+		if now.Sub(client.last) >= time.Hour {
+			delete(l.clients, identity)
+		}
+	}
+}
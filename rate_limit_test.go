@@ -0,0 +1,91 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	gerrors "github.com/goliatone/go-errors"
+)
+
+type clientIDContextKey struct{}
+
+func clientID(ctx context.Context) string {
+	id, _ := ctx.Value(clientIDContextKey{}).(string)
+	return id
+}
+
+func assertRateLimited(t *testing.T, err error, limit string) {
+	t.Helper()
+	var uerr *gerrors.Error
+	if !errors.As(err, &uerr) || uerr.TextCode != "RATE_LIMITED" || uerr.Code != 429 {
+		t.Fatalf("expected RATE_LIMITED error, got %v", err)
+	}
+	if uerr.Metadata["limit"] != limit {
+		t.Fatalf("expected %s limit, got %v", limit, uerr.Metadata["limit"])
+	}
+}
+
+func TestRateLimitUploadsPerMinute(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	manager := NewManager(
+		WithProvider(newMemoryProvider()),
+		WithClock(func() time.Time { return now }),
+		WithRateLimit(clientID, RateLimit{UploadsPerMinute: 2}),
+	)
+
+	alice := context.WithValue(context.Background(), clientIDContextKey{}, "alice")
+	bob := context.WithValue(context.Background(), clientIDContextKey{}, "bob")
+
+	for i := 0; i < 2; i++ {
+		if _, err := manager.UploadFile(alice, "docs/a.txt", []byte("a")); err != nil {
+			t.Fatalf("UploadFile %d: %v", i, err)
+		}
+	}
+
+	_, err := manager.UploadFile(alice, "docs/a.txt", []byte("a"))
+	assertRateLimited(t, err, "uploads_per_minute")
+	if wait, ok := RetryAfter(err); !ok || wait != 30*time.Second {
+		t.Fatalf("expected 30s retry after, got %v (%v)", wait, ok)
+	}
+
+	if _, err := manager.UploadFile(bob, "docs/b.txt", []byte("b")); err != nil {
+		t.Fatalf("expected other identity unaffected: %v", err)
+	}
+	if _, err := manager.UploadFile(context.Background(), "docs/c.txt", []byte("c")); err != nil {
+		t.Fatalf("expected anonymous call unaffected: %v", err)
+	}
+
+	now = now.Add(30 * time.Second)
+	if _, err := manager.UploadFile(alice, "docs/a.txt", []byte("a")); err != nil {
+		t.Fatalf("expected allowance refilled: %v", err)
+	}
+}
+
+func TestRateLimitBytesPerHour(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	manager := NewManager(
+		WithProvider(newMemoryProvider()),
+		WithClock(func() time.Time { return now }),
+		WithRateLimit(clientID, RateLimit{BytesPerHour: 10}),
+	)
+	ctx := context.WithValue(context.Background(), clientIDContextKey{}, "alice")
+
+	if _, err := manager.InitiateChunked(ctx, "chunks/file.bin", 8); err != nil {
+		t.Fatalf("InitiateChunked: %v", err)
+	}
+
+	_, err := manager.UploadFile(ctx, "docs/a.txt", []byte("abcd"))
+	assertRateLimited(t, err, "bytes_per_hour")
+
+	// Staging counts once: committing must not spend the allowance again.
+	now = now.Add(24 * time.Minute)
+	staged, err := manager.StageFile(ctx, "docs/a.txt", []byte("abcd"))
+	if err != nil {
+		t.Fatalf("StageFile: %v", err)
+	}
+	if _, err := manager.CommitFile(ctx, staged.Token); err != nil {
+		t.Fatalf("CommitFile: %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestManagerReadOnly(t *testing.T) {
+	ctx := context.Background()
+	manager := NewManager(WithProvider(NewFSProvider(t.TempDir())))
+
+	if _, err := manager.UploadFile(ctx, "docs/a.txt", []byte("a")); err != nil {
+		t.Fatalf("UploadFile failed: %v", err)
+	}
+
+	manager.SetReadOnly(true)
+	if !manager.ReadOnly() {
+		t.Fatalf("expected manager to report read-only")
+	}
+
+	if _, err := manager.UploadFile(ctx, "docs/b.txt", []byte("b")); !errors.Is(err, ErrServiceReadOnly) {
+		t.Fatalf("expected ErrServiceReadOnly from UploadFile, got %v", err)
+	}
+
+	if err := manager.DeleteFile(ctx, "docs/a.txt"); !errors.Is(err, ErrServiceReadOnly) {
+		t.Fatalf("expected ErrServiceReadOnly from DeleteFile, got %v", err)
+	}
+
+	if _, err := manager.InitiateChunked(ctx, "docs/big.bin", 10); !errors.Is(err, ErrServiceReadOnly) {
+		t.Fatalf("expected ErrServiceReadOnly from InitiateChunked, got %v", err)
+	}
+
+	if _, err := manager.HandleFile(ctx, newTestFileHeader(t, "file", "a.png", "image/png", createTestPNG(2, 2)), "images"); !errors.Is(err, ErrServiceReadOnly) {
+		t.Fatalf("expected ErrServiceReadOnly from HandleFile, got %v", err)
+	}
+
+	if content, err := manager.GetFile(ctx, "docs/a.txt"); err != nil || string(content) != "a" {
+		t.Fatalf("expected reads to keep working, got %q (%v)", content, err)
+	}
+
+	if objects, err := manager.List(ctx, "docs/"); err != nil || len(objects) != 1 {
+		t.Fatalf("expected listing to keep working, got %v (%v)", objects, err)
+	}
+
+	manager.SetReadOnly(false)
+	if _, err := manager.UploadFile(ctx, "docs/b.txt", []byte("b")); err != nil {
+		t.Fatalf("expected writes after leaving read-only mode, got %v", err)
+	}
+}
+
+func TestWithReadOnly(t *testing.T) {
+	manager := NewManager(WithProvider(&mockUploader{}), WithReadOnly(true))
+
+	if _, err := manager.UploadFile(context.Background(), "a.txt", []byte("a")); !errors.Is(err, ErrServiceReadOnly) {
+		t.Fatalf("expected ErrServiceReadOnly, got %v", err)
+	}
+}
@@ -0,0 +1,325 @@
+package uploader
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ManifestEntry is an object the application expects to find in storage. Size and Checksum are
+// optional; Checksum is the SHA-256 hex digest recorded in FileMeta.Checksum or the provider ETag
+// exported by ExportInventory.
+type ManifestEntry struct {
+	Key      string `json:"key"`
+	Size     int64  `json:"size,omitempty"`
+	Checksum string `json:"checksum,omitempty"`
+}
+
+// ReconcileIssue classifies a reconciliation finding.
+type ReconcileIssue string
+
+const (
+	// ReconcileMissing flags manifest entries with no stored object.
+	ReconcileMissing ReconcileIssue = "missing"
+	// ReconcileSizeMismatch flags objects whose size differs from the manifest.
+	ReconcileSizeMismatch ReconcileIssue = "size_mismatch"
+	// ReconcileChecksumMismatch flags objects whose content hash differs from the manifest.
+	ReconcileChecksumMismatch ReconcileIssue = "checksum_mismatch"
+	// ReconcileUnexpected flags stored objects absent from the manifest (see WithFlagExtras).
+	ReconcileUnexpected ReconcileIssue = "unexpected"
+	// ReconcileError records a key that could not be checked.
+	ReconcileError ReconcileIssue = "error"
+)
+
+// ReconcileFinding is a single discrepancy between the manifest and storage.
+type ReconcileFinding struct {
+	Key              string         `json:"key"`
+	Issue            ReconcileIssue `json:"issue"`
+	ExpectedSize     int64          `json:"expected_size,omitempty"`
+	ActualSize       int64          `json:"actual_size,omitempty"`
+	ExpectedChecksum string         `json:"expected_checksum,omitempty"`
+	ActualChecksum   string         `json:"actual_checksum,omitempty"`
+	Error            string         `json:"error,omitempty"`
+}
+
+// ReconcileReport is the machine-readable outcome of a reconciliation run.
+type ReconcileReport struct {
+	Prefix     string             `json:"prefix,omitempty"`
+	Expected   int                `json:"expected"`
+	Matched    int                `json:"matched"`
+	Findings   []ReconcileFinding `json:"findings"`
+	StartedAt  time.Time          `json:"started_at"`
+	FinishedAt time.Time          `json:"finished_at"`
+}
+
+// OK reports whether every manifest entry matched and nothing unexpected was found.
+func (r *ReconcileReport) OK() bool {
+	return len(r.Findings) == 0
+}
+
+// Reconciler verifies that the objects an application expects, for example after restoring a
+// backup, exist in storage with the recorded size and checksum.
+type Reconciler struct {
+	manager         *Manager
+	prefix          string
+	flagExtras      bool
+	verifyChecksums bool
+}
+
+// ReconcilerOption configures a Reconciler.
+type ReconcilerOption func(*Reconciler)
+
+// WithReconcilePrefix limits the storage listing, and the extras check, to prefix.
+func WithReconcilePrefix(prefix string) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.prefix = prefix
+	}
+}
+
+// WithFlagExtras reports stored objects under the prefix that the manifest does not mention. The
+// provider must implement Lister.
+func WithFlagExtras(flag bool) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.flagExtras = flag
+	}
+}
+
+// WithChecksumVerification compares manifest checksums. A checksum equal to the listed ETag
+// matches without a download; otherwise the object is downloaded and its SHA-256 compared.
+func WithChecksumVerification(verify bool) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.verifyChecksums = verify
+	}
+}
+
+// NewReconciler creates a Reconciler that reads through manager.
+func NewReconciler(manager *Manager, opts ...ReconcilerOption) *Reconciler {
+	r := &Reconciler{manager: manager}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Reconcile checks every manifest entry. Providers implementing Lister are listed once under the
+// prefix; entries outside it, and every entry on other providers, are checked one by one with
+// StatFile or a download. Per-key failures are reported as findings; the returned error is only
+// set when the run itself could not proceed.
+func (r *Reconciler) Reconcile(ctx context.Context, manifest []ManifestEntry) (*ReconcileReport, error) {
+	m := r.manager
+	if err := m.ensureProvider(ctx); err != nil {
+		return nil, err
+	}
+
+	report := &ReconcileReport{
+		Prefix:    r.prefix,
+		Expected:  len(manifest),
+		StartedAt: m.now(),
+		Findings:  []ReconcileFinding{},
+	}
+
+	var listed map[string]ObjectInfo
+	if _, ok := m.currentProvider().(Lister); ok {
+		objects, err := m.List(ctx, r.prefix)
+		if err != nil {
+			return nil, fmt.Errorf("reconcile: list %q: %w", r.prefix, err)
+		}
+		listed = make(map[string]ObjectInfo, len(objects))
+		for _, obj := range objects {
+			listed[obj.Key] = obj
+		}
+	} else if r.flagExtras {
+		return nil, ErrNotImplemented
+	}
+
+	expected := make(map[string]struct{}, len(manifest))
+	for _, entry := range manifest {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		expected[entry.Key] = struct{}{}
+
+		if finding, ok := r.check(ctx, entry, listed); ok {
+			report.Findings = append(report.Findings, finding)
+			continue
+		}
+		report.Matched++
+	}
+
+	if r.flagExtras {
+		var extras []ReconcileFinding
+		for key, obj := range listed {
+			if _, ok := expected[key]; !ok {
+				extras = append(extras, ReconcileFinding{
+					Key:        key,
+					Issue:      ReconcileUnexpected,
+					ActualSize: obj.Size,
+				})
+			}
+		}
+		sort.Slice(extras, func(i, j int) bool { return extras[i].Key < extras[j].Key })
+		report.Findings = append(report.Findings, extras...)
+	}
+
+	report.FinishedAt = m.now()
+	return report, nil
+}
+
+// check returns a finding when entry does not match storage.
+func (r *Reconciler) check(ctx context.Context, entry ManifestEntry, listed map[string]ObjectInfo) (ReconcileFinding, bool) {
+	finding := ReconcileFinding{
+		Key:              entry.Key,
+		ExpectedSize:     entry.Size,
+		ExpectedChecksum: entry.Checksum,
+	}
+
+	obj, err := r.lookup(ctx, entry.Key, listed)
+	if errors.Is(err, ErrImageNotFound) {
+		finding.Issue = ReconcileMissing
+		return finding, true
+	}
+	if err != nil {
+		finding.Issue, finding.Error = ReconcileError, err.Error()
+		return finding, true
+	}
+
+	finding.ActualSize = obj.Size
+	if entry.Size > 0 && obj.Size != entry.Size {
+		finding.Issue = ReconcileSizeMismatch
+		return finding, true
+	}
+
+	if !r.verifyChecksums || entry.Checksum == "" || strings.EqualFold(obj.ETag, entry.Checksum) {
+		return finding, false
+	}
+
+	content, err := r.manager.GetFile(ctx, entry.Key)
+	if err != nil {
+		finding.Issue, finding.Error = ReconcileError, err.Error()
+		return finding, true
+	}
+
+	if actual := checksumSHA256(content); !strings.EqualFold(actual, entry.Checksum) {
+		finding.Issue, finding.ActualChecksum = ReconcileChecksumMismatch, actual
+		return finding, true
+	}
+
+	return finding, false
+}
+
+func (r *Reconciler) lookup(ctx context.Context, key string, listed map[string]ObjectInfo) (*ObjectInfo, error) {
+	if listed != nil && strings.HasPrefix(key, r.prefix) {
+		obj, ok := listed[key]
+		if !ok {
+			return nil, ErrImageNotFound
+		}
+		return &obj, nil
+	}
+
+	m := r.manager
+	if statter, ok := m.currentProvider().(FileStatter); ok {
+		return observeCall(ctx, m, "stat", key, func() (*ObjectInfo, error) {
+			return statter.StatFile(ctx, key)
+		})
+	}
+
+	content, err := m.GetFile(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return &ObjectInfo{Key: key, Size: int64(len(content))}, nil
+}
+
+// ReadManifest parses a manifest written in an inventory format: CSV with a header row naming at
+// least a "key" column, or JSON Lines. Output of ExportInventory can be read back directly.
+func ReadManifest(r io.Reader, format InventoryFormat) ([]ManifestEntry, error) {
+	switch format {
+	case InventoryCSV:
+		return readCSVManifest(r)
+	case InventoryJSON:
+		return readJSONManifest(r)
+	}
+	return nil, fmt.Errorf("read manifest: unsupported format %q", format)
+}
+
+func readCSVManifest(r io.Reader) ([]ManifestEntry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read manifest: header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	keyCol, ok := columns["key"]
+	if !ok {
+		return nil, errors.New("read manifest: missing key column")
+	}
+	field := func(row []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	var entries []ManifestEntry
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read manifest: line %d: %w", line, err)
+		}
+		if keyCol >= len(row) || row[keyCol] == "" {
+			return nil, fmt.Errorf("read manifest: line %d: missing key", line)
+		}
+
+		entry := ManifestEntry{Key: row[keyCol], Checksum: field(row, "checksum")}
+		if size := field(row, "size"); size != "" {
+			if entry.Size, err = strconv.ParseInt(size, 10, 64); err != nil {
+				return nil, fmt.Errorf("read manifest: line %d: size: %w", line, err)
+			}
+		}
+		entries = append(entries, entry)
+	}
+}
+
+func readJSONManifest(r io.Reader) ([]ManifestEntry, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var entries []ManifestEntry
+	for line := 1; scanner.Scan(); line++ {
+		raw := strings.TrimSpace(scanner.Text())
+		if raw == "" {
+			continue
+		}
+
+		var entry ManifestEntry
+		if err := json.Unmarshal([]byte(raw), &entry); err != nil {
+			return nil, fmt.Errorf("read manifest: line %d: %w", line, err)
+		}
+		if entry.Key == "" {
+			return nil, fmt.Errorf("read manifest: line %d: missing key", line)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,103 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestReconcilerReport(t *testing.T) {
+	ctx := context.Background()
+	manager := NewManager(WithProvider(NewFSProvider(t.TempDir())))
+
+	for key, content := range map[string]string{
+		"backup/a.txt":     "alpha",
+		"backup/b.txt":     "bravo",
+		"backup/c.txt":     "charlie",
+		"backup/extra.txt": "x",
+	} {
+		if _, err := manager.UploadFile(ctx, key, []byte(content)); err != nil {
+			t.Fatalf("UploadFile %s failed: %v", key, err)
+		}
+	}
+
+	manifest := []ManifestEntry{
+		{Key: "backup/a.txt", Size: 5, Checksum: checksumSHA256([]byte("alpha"))},
+		{Key: "backup/b.txt", Size: 9},
+		{Key: "backup/c.txt", Checksum: checksumSHA256([]byte("changed"))},
+		{Key: "backup/missing.txt"},
+	}
+
+	report, err := NewReconciler(manager,
+		WithReconcilePrefix("backup/"),
+		WithFlagExtras(true),
+		WithChecksumVerification(true),
+	).Reconcile(ctx, manifest)
+	if err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+
+	if report.OK() || report.Expected != 4 || report.Matched != 1 {
+		t.Fatalf("unexpected report totals: %#v", report)
+	}
+
+	issues := map[string]ReconcileIssue{}
+	for _, finding := range report.Findings {
+		issues[finding.Key] = finding.Issue
+	}
+
+	want := map[string]ReconcileIssue{
+		"backup/b.txt":       ReconcileSizeMismatch,
+		"backup/c.txt":       ReconcileChecksumMismatch,
+		"backup/missing.txt": ReconcileMissing,
+		"backup/extra.txt":   ReconcileUnexpected,
+	}
+	for key, issue := range want {
+		if issues[key] != issue {
+			t.Fatalf("expected %s for %s, got %q (%#v)", issue, key, issues[key], report.Findings)
+		}
+	}
+	if len(report.Findings) != len(want) {
+		t.Fatalf("unexpected findings: %#v", report.Findings)
+	}
+}
+
+func TestReconcilerFromInventory(t *testing.T) {
+	ctx := context.Background()
+	manager := NewManager(WithProvider(NewFSProvider(t.TempDir())))
+	if _, err := manager.UploadFile(ctx, "docs/a.txt", []byte("hello")); err != nil {
+		t.Fatalf("UploadFile failed: %v", err)
+	}
+
+	for _, format := range []InventoryFormat{InventoryCSV, InventoryJSON} {
+		var inventory bytes.Buffer
+		if _, err := manager.ExportInventory(ctx, &inventory, format, InventoryOptions{}); err != nil {
+			t.Fatalf("ExportInventory %s failed: %v", format, err)
+		}
+
+		manifest, err := ReadManifest(&inventory, format)
+		if err != nil {
+			t.Fatalf("ReadManifest %s failed: %v", format, err)
+		}
+		if len(manifest) != 1 || manifest[0].Key != "docs/a.txt" || manifest[0].Size != 5 {
+			t.Fatalf("unexpected %s manifest: %#v", format, manifest)
+		}
+
+		report, err := NewReconciler(manager).Reconcile(ctx, manifest)
+		if err != nil || !report.OK() {
+			t.Fatalf("expected clean report, got %#v (%v)", report, err)
+		}
+	}
+
+	if _, err := ReadManifest(strings.NewReader("size\n1\n"), InventoryCSV); err == nil {
+		t.Fatalf("expected error for manifest without key column")
+	}
+}
+
+func TestReconcilerExtrasRequireLister(t *testing.T) {
+	manager := NewManager(WithProvider(&mockUploader{}))
+	if _, err := NewReconciler(manager, WithFlagExtras(true)).Reconcile(context.Background(), nil); err != ErrNotImplemented {
+		t.Fatalf("expected ErrNotImplemented, got %v", err)
+	}
+}
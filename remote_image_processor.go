@@ -0,0 +1,185 @@
+package uploader
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// SourceImageProcessor is implemented by processors that render thumbnails from the stored
+// original, addressed by key, instead of from its bytes. The manager prefers it over Generate
+// and skips loading the original when regenerating thumbnails.
+type SourceImageProcessor interface {
+	GenerateFromSource(ctx context.Context, key string, size ThumbnailSize, contentType string) ([]byte, string, error)
+}
+
+// SourceURLFunc maps a stored key to the URL an external image service fetches it from, e.g.
+// "s3://bucket/" + key for imgproxy or a public HTTP URL.
+type SourceURLFunc func(key string) string
+
+// RemoteImageProcessor offloads resizing to an external service such as imgproxy or Thumbor.
+// Build one with NewImgproxyProcessor or NewThumborProcessor.
+type RemoteImageProcessor struct {
+	client    *http.Client
+	sourceURL SourceURLFunc
+	buildURL  func(source string, size ThumbnailSize) string
+}
+
+var (
+	_ ImageProcessor       = &RemoteImageProcessor{}
+	_ SourceImageProcessor = &RemoteImageProcessor{}
+)
+
+// NewImgproxyProcessor signs imgproxy URLs with the hex encoded key and salt from the imgproxy
+// configuration (IMGPROXY_KEY, IMGPROXY_SALT). Empty values produce unsigned URLs.
+func NewImgproxyProcessor(baseURL, key, salt string, sourceURL SourceURLFunc) (*RemoteImageProcessor, error) {
+	keyBytes, err := hex.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("imgproxy processor: decode key: %w", err)
+	}
+
+	saltBytes, err := hex.DecodeString(salt)
+	if err != nil {
+		return nil, fmt.Errorf("imgproxy processor: decode salt: %w", err)
+	}
+
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	return &RemoteImageProcessor{
+		client:    http.DefaultClient,
+		sourceURL: sourceURL,
+		buildURL: func(source string, size ThumbnailSize) string {
+			path := fmt.Sprintf("/%s/%s", imgproxyOptions(size), base64.RawURLEncoding.EncodeToString([]byte(source)))
+			return baseURL + "/" + signImgproxyPath(keyBytes, saltBytes, path) + path
+		},
+	}, nil
+}
+
+// NewThumborProcessor signs Thumbor URLs with the server SECURITY_KEY. An empty key produces
+// "unsafe" URLs.
+func NewThumborProcessor(baseURL, securityKey string, sourceURL SourceURLFunc) *RemoteImageProcessor {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	return &RemoteImageProcessor{
+		client:    http.DefaultClient,
+		sourceURL: sourceURL,
+		buildURL: func(source string, size ThumbnailSize) string {
+			path := thumborOptions(size) + source
+			return baseURL + "/" + signThumborPath(securityKey, path) + "/" + path
+		},
+	}
+}
+
+// WithHTTPClient sets the client used to call the image service.
+func (p *RemoteImageProcessor) WithHTTPClient(client *http.Client) *RemoteImageProcessor {
+	if client != nil {
+		p.client = client
+	}
+	return p
+}
+
+// Generate is not supported: external services fetch the original themselves, so thumbnails are
+// rendered through GenerateFromSource.
+func (p *RemoteImageProcessor) Generate(context.Context, []byte, ThumbnailSize, string) ([]byte, string, error) {
+	return nil, "", ErrNotImplemented
+}
+
+func (p *RemoteImageProcessor) GenerateFromSource(ctx context.Context, key string, size ThumbnailSize, contentType string) ([]byte, string, error) {
+	if p.sourceURL == nil {
+		return nil, "", fmt.Errorf("remote image processor: source URL resolver not configured")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.buildURL(p.sourceURL(key), size), nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("remote image processor: build request: %w", err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("remote image processor: request %s: %w", size.Name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("remote image processor: %s: unexpected status %d", size.Name, resp.StatusCode)
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("remote image processor: read %s: %w", size.Name, err)
+	}
+
+	if responseType := resp.Header.Get("Content-Type"); responseType != "" {
+		contentType = responseType
+	}
+	return content, contentType, nil
+}
+
+// imgproxyOptions mirrors the local processor fits: contain pads to the exact canvas. Only the
+// encoding quality is forwarded; the remaining encoder settings belong to the imgproxy config.
+func imgproxyOptions(size ThumbnailSize) string {
+	var options string
+	switch strings.ToLower(size.Fit) {
+	case "cover", "outside":
+		options = fmt.Sprintf("rs:fill:%d:%d", size.Width, size.Height)
+	case "fill":
+		options = fmt.Sprintf("rs:force:%d:%d", size.Width, size.Height)
+	default:
+		options = fmt.Sprintf("rs:fit:%d:%d/ex:1", size.Width, size.Height)
+	}
+
+	if size.Encoding.Quality > 0 {
+		options += fmt.Sprintf("/q:%d", size.Encoding.Quality)
+	}
+	return options
+}
+
+func signImgproxyPath(key, salt []byte, path string) string {
+	if len(key) == 0 {
+		return "insecure"
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(salt)
+	mac.Write([]byte(path))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func thumborOptions(size ThumbnailSize) string {
+	var options string
+	var filters []string
+	switch strings.ToLower(size.Fit) {
+	case "cover", "outside":
+		options = fmt.Sprintf("%dx%d/", size.Width, size.Height)
+	case "fill":
+		options = fmt.Sprintf("%dx%d/", size.Width, size.Height)
+		filters = append(filters, "stretch()")
+	default:
+		options = fmt.Sprintf("fit-in/%dx%d/", size.Width, size.Height)
+		filters = append(filters, "fill(transparent)")
+	}
+
+	if size.Encoding.Quality > 0 {
+		filters = append(filters, fmt.Sprintf("quality(%d)", size.Encoding.Quality))
+	}
+
+	if len(filters) > 0 {
+		options += "filters:" + strings.Join(filters, ":") + "/"
+	}
+	return options
+}
+
+func signThumborPath(securityKey, path string) string {
+	if securityKey == "" {
+		return "unsafe"
+	}
+
+	mac := hmac.New(sha1.New, []byte(securityKey))
+	mac.Write([]byte(path))
+	return base64.URLEncoding.EncodeToString(mac.Sum(nil))
+}
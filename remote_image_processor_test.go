@@ -0,0 +1,125 @@
+package uploader
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestImgproxyProcessorSignsRequests(t *testing.T) {
+	key, salt := "6b6579", "73616c74" // "key", "salt"
+	thumb := createTestPNG(8, 8)
+
+	var requested string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.Path
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(thumb)
+	}))
+	defer server.Close()
+
+	processor, err := NewImgproxyProcessor(server.URL, key, salt, func(key string) string { return "s3://bucket/" + key })
+	if err != nil {
+		t.Fatalf("NewImgproxyProcessor failed: %v", err)
+	}
+
+	out, contentType, err := processor.GenerateFromSource(context.Background(), "images/a.jpg", ThumbnailSize{Name: "small", Width: 80, Height: 60, Fit: "cover"}, "image/jpeg")
+	if err != nil {
+		t.Fatalf("GenerateFromSource failed: %v", err)
+	}
+
+	if contentType != "image/png" || len(out) != len(thumb) {
+		t.Fatalf("unexpected response %s (%d bytes)", contentType, len(out))
+	}
+
+	path := "/rs:fill:80:60/" + base64.RawURLEncoding.EncodeToString([]byte("s3://bucket/images/a.jpg"))
+	mac := hmac.New(sha256.New, []byte("key"))
+	mac.Write([]byte("salt" + path))
+	if expected := "/" + base64.RawURLEncoding.EncodeToString(mac.Sum(nil)) + path; requested != expected {
+		t.Fatalf("expected request %s, got %s", expected, requested)
+	}
+}
+
+func TestImgproxyProcessorRejectsInvalidKey(t *testing.T) {
+	if _, err := NewImgproxyProcessor("http://imgproxy", "not-hex", "", nil); err == nil {
+		t.Fatalf("expected error for invalid key")
+	}
+}
+
+func TestThumborProcessorURLs(t *testing.T) {
+	var requested string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.Path
+		w.Write([]byte("thumb"))
+	}))
+	defer server.Close()
+
+	processor := NewThumborProcessor(server.URL, "", func(key string) string { return "cdn.example.com/" + key })
+
+	if _, _, err := processor.GenerateFromSource(context.Background(), "a.png", ThumbnailSize{Name: "box", Width: 40, Height: 40, Fit: "contain"}, "image/png"); err != nil {
+		t.Fatalf("GenerateFromSource failed: %v", err)
+	}
+
+	if requested != "/unsafe/fit-in/40x40/filters:fill(transparent)/cdn.example.com/a.png" {
+		t.Fatalf("unexpected thumbor path %s", requested)
+	}
+
+	size := ThumbnailSize{Name: "hq", Width: 40, Height: 40, Fit: "fill", Encoding: ImageEncoding{Quality: 90}}
+	if _, _, err := processor.GenerateFromSource(context.Background(), "a.png", size, "image/png"); err != nil {
+		t.Fatalf("GenerateFromSource failed: %v", err)
+	}
+
+	if requested != "/unsafe/40x40/filters:stretch():quality(90)/cdn.example.com/a.png" {
+		t.Fatalf("unexpected thumbor path %s", requested)
+	}
+}
+
+func TestRemoteImageProcessorReportsErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	processor := NewThumborProcessor(server.URL, "secret", func(key string) string { return key })
+	if _, _, err := processor.GenerateFromSource(context.Background(), "a.png", ThumbnailSize{Name: "s", Width: 1, Height: 1}, "image/png"); err == nil || !strings.Contains(err.Error(), "502") {
+		t.Fatalf("expected status error, got %v", err)
+	}
+
+	if _, _, err := processor.Generate(context.Background(), []byte("x"), ThumbnailSize{}, ""); err != ErrNotImplemented {
+		t.Fatalf("expected ErrNotImplemented from Generate, got %v", err)
+	}
+}
+
+func TestHandleImageWithThumbnailsUsesRemoteProcessor(t *testing.T) {
+	var sources []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(createTestPNG(4, 4))
+	}))
+	defer server.Close()
+
+	processor := NewThumborProcessor(server.URL, "", func(key string) string {
+		sources = append(sources, key)
+		return "origin/" + key
+	})
+
+	manager := NewManager(WithProvider(&mockUploader{}), WithImageProcessor(processor))
+	meta, err := manager.HandleImageWithThumbnails(context.Background(), newTestFileHeader(t, "file", "a.png", "image/png", createTestPNG(20, 20)), "images",
+		[]ThumbnailSize{{Name: "small", Width: 4, Height: 4, Fit: "cover"}})
+	if err != nil {
+		t.Fatalf("HandleImageWithThumbnails failed: %v", err)
+	}
+
+	if len(sources) != 1 || sources[0] != meta.Name {
+		t.Fatalf("expected remote render from stored original %s, got %v", meta.Name, sources)
+	}
+
+	if thumb := meta.Thumbnails["small"]; thumb == nil || thumb.Width != 4 {
+		t.Fatalf("unexpected thumbnail meta: %#v", thumb)
+	}
+}
@@ -0,0 +1,205 @@
+package uploader
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// UploadConstraints restrict what may be stored under a reserved key. Zero values leave the
+// validator as the only limit. ContentTypes entries are exact MIME types or type wildcards
+// ("image/*").
+type UploadConstraints struct {
+	ContentTypes []string `json:"content_types,omitempty"`
+	MaxSize      int64    `json:"max_size,omitempty"`
+}
+
+// UploadReservation grants the holder of Token one upload under Key until ExpiresAt.
+type UploadReservation struct {
+	Token       string            `json:"token"`
+	Key         string            `json:"key"`
+	Constraints UploadConstraints `json:"constraints"`
+	ExpiresAt   time.Time         `json:"expires_at"`
+}
+
+// ReservationStore persists upload reservations. Get reports false for unknown or expired
+// tokens.
+type ReservationStore interface {
+	Get(ctx context.Context, token string) (*UploadReservation, bool, error)
+	Put(ctx context.Context, reservation *UploadReservation) error
+	Delete(ctx context.Context, token string) error
+}
+
+// WithReservationStore sets the store backing ReserveUpload. Use a shared store when several
+// instances serve the same clients.
+func WithReservationStore(store ReservationStore) Option {
+	return func(m *Manager) {
+		m.reservationStore = store
+	}
+}
+
+// WithReservation attaches a token from ReserveUpload to an upload, presigned post or chunked
+// session. The operation fails unless it targets the reserved key within the constraints.
+func WithReservation(token string) UploadOption {
+	return func(m *Metadata) { m.ReservationToken = token }
+}
+
+// ReserveUpload reserves key for a single upload within ttl (DefaultReservationTTL when ttl <= 0).
+// The returned token has to accompany the upload: WithReservation for UploadFile,
+// UploadLocalFile, CreatePresignedPost and InitiateChunked, and
+// PresignedUploadResult.ReservationToken for ConfirmPresignedUpload. Presigning and initiating
+// only check the token; the upload, confirmation or chunked completion that stores the object
+// consumes it.
+func (m *Manager) ReserveUpload(ctx context.Context, key string, constraints UploadConstraints, ttl time.Duration) (*UploadReservation, error) {
+	if err := m.ensureWritable(); err != nil {
+		return nil, err
+	}
+
+	if err := m.validateKey(key); err != nil {
+		return nil, err
+	}
+
+	if ttl <= 0 {
+		ttl = DefaultReservationTTL
+	}
+
+	token, err := newReservationToken()
+	if err != nil {
+		return nil, err
+	}
+
+	reservation := &UploadReservation{
+		Token:       token,
+		Key:         key,
+		Constraints: constraints,
+		ExpiresAt:   m.now().Add(ttl),
+	}
+
+	if err := m.ensureReservationStore().Put(ctx, reservation); err != nil {
+		return nil, err
+	}
+
+	return reservation, nil
+}
+
+// checkReservation verifies that an upload of size bytes of contentType under key is covered by
+// token. A size <= 0 skips the size check for callers that do not know it yet.
+func (m *Manager) checkReservation(ctx context.Context, token, key, contentType string, size int64) error {
+	reservation, ok, err := m.ensureReservationStore().Get(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	if !ok || m.now().After(reservation.ExpiresAt) {
+		return ErrInvalidReservation
+	}
+
+	if reservation.Key != key {
+		return ErrReservationMismatch
+	}
+
+	limits := reservation.Constraints
+	if len(limits.ContentTypes) > 0 && !matchesContentType(limits.ContentTypes, contentType) {
+		return ErrReservationMismatch
+	}
+
+	if limits.MaxSize > 0 && size > limits.MaxSize {
+		return ErrReservationMismatch
+	}
+
+	return nil
+}
+
+// consumeReservation drops token once the reserved upload has been stored.
+func (m *Manager) consumeReservation(ctx context.Context, token string) {
+	if token == "" {
+		return
+	}
+	if err := m.ensureReservationStore().Delete(ctx, token); err != nil {
+		m.log(ctx).Error("consume upload reservation failed", err)
+	}
+}
+
+func (m *Manager) ensureReservationStore() ReservationStore {
+	m.reservationOnce.Do(func() {
+		if m.reservationStore == nil {
+			store := NewMemoryReservationStore()
+			if m.clock != nil {
+				store.timeNowFn = m.clock
+			}
+			m.reservationStore = store
+		}
+	})
+	return m.reservationStore
+}
+
+func newReservationToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// MemoryReservationStore is an in-process ReservationStore.
+type MemoryReservationStore struct {
+	mu           sync.Mutex
+	reservations map[string]UploadReservation
+	timeNowFn    func() time.Time
+}
+
+// NewMemoryReservationStore creates an empty in-memory store.
+func NewMemoryReservationStore() *MemoryReservationStore {
+	return &MemoryReservationStore{
+		reservations: make(map[string]UploadReservation),
+		timeNowFn: func() time.Time {
+			return time.Now()
+		},
+	}
+}
+
+func (s *MemoryReservationStore) Get(_ context.Context, token string) (*UploadReservation, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reservation, ok := s.reservations[token]
+	if !ok {
+		return nil, false, nil
+	}
+
+	if s.timeNowFn().After(reservation.ExpiresAt) {
+		delete(s.reservations, token)
+		return nil, false, nil
+	}
+
+	return &reservation, true, nil
+}
+
+func (s *MemoryReservationStore) Put(_ context.Context, reservation *UploadReservation) error {
+	if reservation == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.timeNowFn()
+	s.reservations[reservation.Token] = *reservation
+
+	for token, entry := range s.reservations {
+		if now.After(entry.ExpiresAt) {
+			delete(s.reservations, token)
+		}
+	}
+
+	return nil
+}
+
+func (s *MemoryReservationStore) Delete(_ context.Context, token string) error {
+	s.mu.Lock()
+	delete(s.reservations, token)
+	s.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,86 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestReserveUploadEnforcesConstraints(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	manager := NewManager(
+		WithProvider(newMemoryProvider()),
+		WithClock(func() time.Time { return now }),
+	)
+
+	reservation, err := manager.ReserveUpload(ctx, "avatars/u1.png", UploadConstraints{
+		ContentTypes: []string{"image/*"},
+		MaxSize:      10,
+	}, time.Minute)
+	if err != nil {
+		t.Fatalf("ReserveUpload failed: %v", err)
+	}
+	if reservation.Token == "" || !reservation.ExpiresAt.Equal(now.Add(time.Minute)) {
+		t.Fatalf("unexpected reservation: %#v", reservation)
+	}
+
+	token := WithReservation(reservation.Token)
+	cases := []struct {
+		name    string
+		key     string
+		content []byte
+		ctype   string
+	}{
+		{"other key", "avatars/u2.png", []byte("png"), "image/png"},
+		{"content type", "avatars/u1.png", []byte("png"), "application/pdf"},
+		{"size", "avatars/u1.png", []byte("this is too large"), "image/png"},
+	}
+	for _, tc := range cases {
+		if _, err := manager.UploadFile(ctx, tc.key, tc.content, WithContentType(tc.ctype), token); !errors.Is(err, ErrReservationMismatch) {
+			t.Fatalf("%s: expected ErrReservationMismatch, got %v", tc.name, err)
+		}
+	}
+
+	if _, err := manager.UploadFile(ctx, "avatars/u1.png", []byte("png"), WithContentType("image/png"), token); err != nil {
+		t.Fatalf("reserved upload failed: %v", err)
+	}
+
+	if _, err := manager.UploadFile(ctx, "avatars/u1.png", []byte("png"), WithContentType("image/png"), token); !errors.Is(err, ErrInvalidReservation) {
+		t.Fatalf("expected consumed reservation to be rejected, got %v", err)
+	}
+
+	expiring, err := manager.ReserveUpload(ctx, "avatars/u3.png", UploadConstraints{}, time.Minute)
+	if err != nil {
+		t.Fatalf("ReserveUpload failed: %v", err)
+	}
+	now = now.Add(2 * time.Minute)
+	if _, err := manager.UploadFile(ctx, "avatars/u3.png", []byte("png"), WithReservation(expiring.Token)); !errors.Is(err, ErrInvalidReservation) {
+		t.Fatalf("expected expired reservation to be rejected, got %v", err)
+	}
+}
+
+func TestReserveUploadPresignedConfirmation(t *testing.T) {
+	ctx := context.Background()
+	manager := NewManager(WithProvider(&mockUploader{}))
+
+	reservation, err := manager.ReserveUpload(ctx, "avatars/me.png", UploadConstraints{ContentTypes: []string{"image/*"}}, 0)
+	if err != nil {
+		t.Fatalf("ReserveUpload failed: %v", err)
+	}
+
+	swapped := &PresignedUploadResult{Key: "avatars/other.png", ContentType: "image/png", Size: 4, ReservationToken: reservation.Token}
+	if _, err := manager.ConfirmPresignedUpload(ctx, swapped); !errors.Is(err, ErrReservationMismatch) {
+		t.Fatalf("expected confirmation under another key to fail, got %v", err)
+	}
+
+	result := &PresignedUploadResult{Key: "avatars/me.png", ContentType: "image/png", Size: 4, ReservationToken: reservation.Token}
+	if _, err := manager.ConfirmPresignedUpload(ctx, result); err != nil {
+		t.Fatalf("ConfirmPresignedUpload failed: %v", err)
+	}
+
+	if _, err := manager.ConfirmPresignedUpload(ctx, result); !errors.Is(err, ErrInvalidReservation) {
+		t.Fatalf("expected reservation to be single use, got %v", err)
+	}
+}
@@ -0,0 +1,181 @@
+package uploader
+
+import (
+	"context"
+	"time"
+)
+
+// OperationResult is the typed outcome of a mutating operation, delivered to ResultSink.
+type OperationResult interface {
+	Operation() string
+}
+
+// UploadResult describes a stored object. Key may differ from the requested key when a
+// collision policy picked another one.
+type UploadResult struct {
+	Key          string        `json:"key"`
+	URL          string        `json:"url"`
+	Size         int64         `json:"size"`
+	ContentType  string        `json:"content_type,omitempty"`
+	Provider     string        `json:"provider,omitempty"`
+	Duration     time.Duration `json:"duration"`
+	Retries      int           `json:"retries"`
+	ReplicatedTo []string      `json:"replicated_to,omitempty"`
+	// PreviousVersion is the key the replaced object was copied to under CollisionVersion.
+	PreviousVersion string `json:"previous_version,omitempty"`
+	// Ref identifies the stored object without relying on the provider's URL format.
+	Ref *StoredObjectRef `json:"ref,omitempty"`
+	// PublicKey is the key to hand to clients when a KeyCodec is configured. It replaces Key in
+	// the JSON encoding, which then omits the fields embedding the storage key.
+	PublicKey string `json:"public_key,omitempty"`
+
+	publicPreviousVersion string
+}
+
+func (r *UploadResult) Operation() string { return "upload" }
+
+// DeleteResult describes a removed object.
+type DeleteResult struct {
+	Key          string        `json:"key"`
+	Provider     string        `json:"provider,omitempty"`
+	Duration     time.Duration `json:"duration"`
+	Retries      int           `json:"retries"`
+	ReplicatedTo []string      `json:"replicated_to,omitempty"`
+}
+
+func (r *DeleteResult) Operation() string { return "delete" }
+
+// Replicator is implemented by providers that write every object to several stores.
+// Replicas names the stores mirroring the primary one.
+type Replicator interface {
+	Replicas() []string
+}
+
+// ResultSink receives the outcome of every successful upload and delete, e.g. for audit logs.
+// It runs synchronously, so slow sinks should hand results off. CorrelationIDFromContext(ctx)
+// returns the ID of the request that caused the operation.
+type ResultSink func(ctx context.Context, result OperationResult)
+
+// WithResultSink registers a sink for operation results.
+func WithResultSink(sink ResultSink) Option {
+	return func(m *Manager) {
+		m.resultSink = sink
+	}
+}
+
+// UploadFileResult is UploadFile returning the full outcome instead of the URL.
+func (m *Manager) UploadFileResult(ctx context.Context, path string, content []byte, opts ...UploadOption) (*UploadResult, error) {
+	if err := m.ensureWritable(); err != nil {
+		return nil, err
+	}
+
+	if err := m.validateKey(path); err != nil {
+		return nil, err
+	}
+
+	if err := m.ensureProvider(ctx); err != nil {
+		return nil, err
+	}
+
+	md := &Metadata{}
+	for _, opt := range opts {
+		opt(md)
+	}
+	ctx = withRouteInfo(ctx, md.ContentType, int64(len(content)))
+
+	if md.ReservationToken != "" {
+		if err := m.checkReservation(ctx, md.ReservationToken, path, md.ContentType, int64(len(content))); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := m.checkRateLimit(ctx, int64(len(content))); err != nil {
+		return nil, err
+	}
+
+	started := time.Now()
+	result, err := m.storeWithCollisionPolicy(ctx, path, func(key string, opts ...UploadOption) (string, error) {
+		return m.putFile(ctx, key, content, opts...)
+	}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	m.consumeReservation(ctx, md.ReservationToken)
+
+	result.Size = int64(len(content))
+	result.ContentType = md.ContentType
+	m.recordUpload(ctx, result, started)
+	return result, nil
+}
+
+// DeleteFileResult is DeleteFile returning the full outcome.
+func (m *Manager) DeleteFileResult(ctx context.Context, path string) (*DeleteResult, error) {
+	if err := m.ensureWritable(); err != nil {
+		return nil, err
+	}
+
+	if err := m.ensureProvider(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := m.checkRetention(ctx, path); err != nil {
+		return nil, err
+	}
+
+	started := time.Now()
+	provider := m.providerFor(ctx, path)
+	err := m.observeErr(ctx, "delete", path, func() error {
+		return provider.DeleteFile(ctx, path)
+	})
+	if err != nil {
+		return nil, err
+	}
+	m.forgetRetention(ctx, path)
+
+	result := &DeleteResult{
+		Key:          path,
+		Provider:     providerName(provider),
+		Duration:     time.Since(started),
+		ReplicatedTo: replicas(provider),
+	}
+	m.usageCache.forget(path)
+	m.emitResult(ctx, result)
+	return result, nil
+}
+
+// recordUpload fills the provider facts of result and hands it to the sink.
+func (m *Manager) recordUpload(ctx context.Context, result *UploadResult, started time.Time) {
+	provider := m.providerFor(withRouteInfo(ctx, result.ContentType, result.Size), result.Key)
+	result.Provider = providerName(provider)
+	result.Duration = time.Since(started)
+	result.ReplicatedTo = replicas(provider)
+	result.Ref = m.objectRef(provider, result.Key)
+	if m.keyCodec != nil {
+		result.PublicKey = m.keyCodec.Encode(result.Key)
+		if result.PreviousVersion != "" {
+			result.publicPreviousVersion = m.keyCodec.Encode(result.PreviousVersion)
+		}
+	}
+	m.usageCache.forget(result.Key)
+	m.emitResult(ctx, result)
+}
+
+func (m *Manager) emitResult(ctx context.Context, result OperationResult) {
+	if m.resultSink != nil {
+		m.resultSink(m.correlationContext(ctx), result)
+	}
+}
+
+func providerName(provider Uploader) string {
+	if describer, ok := provider.(ProviderDescriber); ok {
+		return describer.ProviderName()
+	}
+	return ""
+}
+
+func replicas(provider Uploader) []string {
+	if replicator, ok := provider.(Replicator); ok {
+		return replicator.Replicas()
+	}
+	return nil
+}
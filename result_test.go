@@ -0,0 +1,70 @@
+package uploader
+
+import (
+	"context"
+	"testing"
+)
+
+func TestUploadFileResult(t *testing.T) {
+	ctx := context.Background()
+
+	var results []OperationResult
+	manager := NewManager(
+		WithProvider(NewFSProvider(t.TempDir())),
+		WithCollisionPolicy(CollisionSuffix),
+		WithResultSink(func(_ context.Context, result OperationResult) {
+			results = append(results, result)
+		}),
+	)
+
+	if _, err := manager.UploadFile(ctx, "docs/a.txt", []byte("first")); err != nil {
+		t.Fatalf("UploadFile failed: %v", err)
+	}
+
+	result, err := manager.UploadFileResult(ctx, "docs/a.txt", []byte("second"), WithContentType("text/plain"))
+	if err != nil {
+		t.Fatalf("UploadFileResult failed: %v", err)
+	}
+
+	if result.Key != "docs/a-1.txt" || result.Retries != 1 || result.Size != 6 || result.ContentType != "text/plain" || result.Provider != "fs" {
+		t.Fatalf("unexpected result: %#v", result)
+	}
+
+	deleted, err := manager.DeleteFileResult(ctx, result.Key)
+	if err != nil {
+		t.Fatalf("DeleteFileResult failed: %v", err)
+	}
+	if deleted.Key != "docs/a-1.txt" || deleted.Provider != "fs" {
+		t.Fatalf("unexpected delete result: %#v", deleted)
+	}
+
+	if len(results) != 3 || results[0].Operation() != "upload" || results[2].Operation() != "delete" {
+		t.Fatalf("expected sink to see every operation, got %#v", results)
+	}
+}
+
+func TestUploadFileResultReportsVersionAndReplicas(t *testing.T) {
+	ctx := context.Background()
+	local := NewFSProvider(t.TempDir())
+	manager := NewManager(
+		WithProvider(NewMultiProvider(local, NewFSProvider(t.TempDir()))),
+		WithCollisionPolicy(CollisionVersion),
+	)
+
+	if _, err := manager.UploadFile(ctx, "a.txt", []byte("first")); err != nil {
+		t.Fatalf("UploadFile failed: %v", err)
+	}
+
+	result, err := manager.UploadFileResult(ctx, "a.txt", []byte("second"))
+	if err != nil {
+		t.Fatalf("UploadFileResult failed: %v", err)
+	}
+
+	if result.PreviousVersion == "" || result.Provider != "multi:fs" {
+		t.Fatalf("unexpected result: %#v", result)
+	}
+
+	if len(result.ReplicatedTo) != 1 || result.ReplicatedTo[0] != "fs" {
+		t.Fatalf("expected local replica, got %v", result.ReplicatedTo)
+	}
+}
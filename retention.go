@@ -0,0 +1,224 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	gerrors "github.com/goliatone/go-errors"
+)
+
+// Retention is the compliance state of an object: a legal hold freezes it until released, a
+// retention date until that date passes.
+type Retention struct {
+	LegalHold   bool      `json:"legal_hold"`
+	RetainUntil time.Time `json:"retain_until,omitempty"`
+}
+
+// Locked reports whether the object may not be deleted at now.
+func (r Retention) Locked(now time.Time) bool {
+	return r.LegalHold || now.Before(r.RetainUntil)
+}
+
+// RetentionController is implemented by providers that enforce retention natively, such as S3
+// Object Lock. When RetentionEnabled reports true, their StatFile reports ObjectInfo.LegalHold
+// and ObjectInfo.RetainUntil; otherwise the manager falls back to its RetentionStore.
+type RetentionController interface {
+	RetentionEnabled() bool
+	SetLegalHold(ctx context.Context, path string, hold bool) error
+	SetRetention(ctx context.Context, path string, until time.Time) error
+}
+
+// RetentionStore keeps retention for providers without native support. Get returns the zero
+// Retention for keys it does not know.
+type RetentionStore interface {
+	GetRetention(ctx context.Context, key string) (Retention, error)
+	PutRetention(ctx context.Context, key string, retention Retention) error
+	DeleteRetention(ctx context.Context, key string) error
+}
+
+// WithRetentionStore keeps holds and retention dates for providers without native retention in
+// store. The default in-memory store is lost on restart; compliance deployments should persist
+// it (SQL, Redis) or use a provider implementing RetentionController.
+func WithRetentionStore(store RetentionStore) Option {
+	return func(m *Manager) {
+		m.retentionStore = store
+	}
+}
+
+// SetHold places or releases a legal hold on key. While held, DeleteFile fails with
+// ErrObjectRetained.
+func (m *Manager) SetHold(ctx context.Context, key string, hold bool) error {
+	return m.updateRetention(ctx, key, "set_hold",
+		func(controller RetentionController) error { return controller.SetLegalHold(ctx, key, hold) },
+		func(current Retention) (Retention, error) {
+			current.LegalHold = hold
+			return current, nil
+		},
+	)
+}
+
+// SetRetention keeps key from being deleted until the given time. An active retention date can
+// only be extended.
+func (m *Manager) SetRetention(ctx context.Context, key string, until time.Time) error {
+	return m.updateRetention(ctx, key, "set_retention",
+		func(controller RetentionController) error { return controller.SetRetention(ctx, key, until) },
+		func(current Retention) (Retention, error) {
+			if until.Before(current.RetainUntil) && m.now().Before(current.RetainUntil) {
+				return current, retainedError(current)
+			}
+			current.RetainUntil = until
+			return current, nil
+		},
+	)
+}
+
+// Retention reports the legal hold and retention date of key.
+func (m *Manager) Retention(ctx context.Context, key string) (Retention, error) {
+	if err := m.ensureProvider(ctx); err != nil {
+		return Retention{}, err
+	}
+
+	if _, ok := m.retentionController(ctx, key); ok {
+		info, err := m.StatFile(ctx, key)
+		if err != nil {
+			return Retention{}, err
+		}
+		return Retention{LegalHold: info.LegalHold, RetainUntil: info.RetainUntil}, nil
+	}
+
+	return m.ensureRetentionStore().GetRetention(ctx, key)
+}
+
+func (m *Manager) updateRetention(ctx context.Context, key, op string, native func(RetentionController) error, update func(Retention) (Retention, error)) error {
+	if err := m.ensureWritable(); err != nil {
+		return err
+	}
+
+	if err := m.validateKey(key); err != nil {
+		return err
+	}
+
+	if err := m.ensureProvider(ctx); err != nil {
+		return err
+	}
+
+	return m.observeErr(ctx, op, key, func() error {
+		if controller, ok := m.retentionController(ctx, key); ok {
+			return native(controller)
+		}
+		return m.updateStoredRetention(ctx, key, update)
+	})
+}
+
+func (m *Manager) updateStoredRetention(ctx context.Context, key string, update func(Retention) (Retention, error)) error {
+	// Only existing objects can be held.
+	if _, err := m.StatFile(ctx, key); err != nil {
+		return err
+	}
+
+	store := m.ensureRetentionStore()
+	current, err := store.GetRetention(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	next, err := update(current)
+	if err != nil {
+		return err
+	}
+	return store.PutRetention(ctx, key, next)
+}
+
+// checkRetention fails when key is held or retained. Missing objects pass so the provider
+// reports them as usual.
+func (m *Manager) checkRetention(ctx context.Context, key string) error {
+	retention, err := m.Retention(ctx, key)
+	if errors.Is(err, ErrImageNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if retention.Locked(m.now()) {
+		return m.correlateError(ctx, retainedError(retention))
+	}
+	return nil
+}
+
+// forgetRetention drops the stored retention of a deleted key.
+func (m *Manager) forgetRetention(ctx context.Context, key string) {
+	if _, native := m.retentionController(ctx, key); native {
+		return
+	}
+	if err := m.ensureRetentionStore().DeleteRetention(ctx, key); err != nil {
+		m.log(ctx).Error("forget retention failed", err, "key", key)
+	}
+}
+
+// retentionController returns the provider for key when it enforces retention itself.
+func (m *Manager) retentionController(ctx context.Context, key string) (RetentionController, bool) {
+	controller, ok := m.providerFor(ctx, key).(RetentionController)
+	if !ok || !controller.RetentionEnabled() {
+		return nil, false
+	}
+	return controller, true
+}
+
+func (m *Manager) ensureRetentionStore() RetentionStore {
+	m.retentionOnce.Do(func() {
+		if m.retentionStore == nil {
+			m.retentionStore = NewMemoryRetentionStore()
+		}
+	})
+	return m.retentionStore
+}
+
+func retainedError(retention Retention) error {
+	meta := map[string]any{"legal_hold": retention.LegalHold}
+	if !retention.RetainUntil.IsZero() {
+		meta["retain_until"] = retention.RetainUntil
+	}
+	return gerrors.New("object is under legal hold or retention", gerrors.CategoryConflict).
+		WithCode(409).
+		WithTextCode("OBJECT_RETAINED").
+		WithMetadata(meta)
+}
+
+// MemoryRetentionStore is an in-process RetentionStore.
+type MemoryRetentionStore struct {
+	mu      sync.RWMutex
+	entries map[string]Retention
+}
+
+// NewMemoryRetentionStore creates an empty in-memory store.
+func NewMemoryRetentionStore() *MemoryRetentionStore {
+	return &MemoryRetentionStore{entries: make(map[string]Retention)}
+}
+
+func (s *MemoryRetentionStore) GetRetention(_ context.Context, key string) (Retention, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.entries[key], nil
+}
+
+func (s *MemoryRetentionStore) PutRetention(_ context.Context, key string, retention Retention) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if retention == (Retention{}) {
+		delete(s.entries, key)
+		return nil
+	}
+	s.entries[key] = retention
+	return nil
+}
+
+func (s *MemoryRetentionStore) DeleteRetention(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+	return nil
+}
@@ -0,0 +1,116 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	gerrors "github.com/goliatone/go-errors"
+)
+
+func assertRetained(t *testing.T, err error) {
+	t.Helper()
+	var uerr *gerrors.Error
+	if !errors.As(err, &uerr) || uerr.TextCode != "OBJECT_RETAINED" {
+		t.Fatalf("expected OBJECT_RETAINED error, got %v", err)
+	}
+}
+
+func TestManagerLegalHoldWithStore(t *testing.T) {
+	ctx := context.Background()
+	provider := newMemoryProvider()
+	provider.files["contracts/a.pdf"] = []byte("pdf")
+	manager := NewManager(WithProvider(provider))
+
+	if err := manager.SetHold(ctx, "contracts/missing.pdf", true); err == nil {
+		t.Fatalf("expected holding a missing object to fail")
+	}
+	if err := manager.SetHold(ctx, "contracts/a.pdf", true); err != nil {
+		t.Fatalf("SetHold: %v", err)
+	}
+
+	info, err := manager.StatFile(ctx, "contracts/a.pdf")
+	if err != nil || !info.LegalHold {
+		t.Fatalf("expected hold surfaced by StatFile, got %#v (%v)", info, err)
+	}
+	assertRetained(t, manager.DeleteFile(ctx, "contracts/a.pdf"))
+	if _, ok := provider.files["contracts/a.pdf"]; !ok {
+		t.Fatalf("expected held object kept")
+	}
+
+	if err := manager.SetHold(ctx, "contracts/a.pdf", false); err != nil {
+		t.Fatalf("release hold: %v", err)
+	}
+	if err := manager.DeleteFile(ctx, "contracts/a.pdf"); err != nil {
+		t.Fatalf("DeleteFile after release: %v", err)
+	}
+}
+
+func TestManagerRetentionWithStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	provider := newMemoryProvider()
+	provider.files["contracts/a.pdf"] = []byte("pdf")
+	store := NewMemoryRetentionStore()
+	manager := NewManager(WithProvider(provider), WithRetentionStore(store), WithClock(func() time.Time { return now }))
+
+	until := now.Add(time.Hour)
+	if err := manager.SetRetention(ctx, "contracts/a.pdf", until); err != nil {
+		t.Fatalf("SetRetention: %v", err)
+	}
+	assertRetained(t, manager.SetRetention(ctx, "contracts/a.pdf", now.Add(time.Minute)))
+	assertRetained(t, manager.DeleteFile(ctx, "contracts/a.pdf"))
+
+	retention, err := manager.Retention(ctx, "contracts/a.pdf")
+	if err != nil || !retention.RetainUntil.Equal(until) {
+		t.Fatalf("unexpected retention %#v (%v)", retention, err)
+	}
+
+	now = until
+	if err := manager.DeleteFile(ctx, "contracts/a.pdf"); err != nil {
+		t.Fatalf("DeleteFile after expiry: %v", err)
+	}
+	if got, _ := store.GetRetention(ctx, "contracts/a.pdf"); got != (Retention{}) {
+		t.Fatalf("expected retention forgotten after delete, got %#v", got)
+	}
+}
+
+func TestAWSObjectLock(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeS3Client{headOutput: &s3.HeadObjectOutput{
+		ObjectLockLegalHoldStatus: types.ObjectLockLegalHoldStatusOn,
+		ObjectLockRetainUntilDate: aws.Time(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)),
+	}}
+	provider := &AWSProvider{client: client, bucket: "bucket", logger: &DefaultLogger{}}
+	if err := WithAWSObjectLock("COMPLIANCE")(provider); err != nil {
+		t.Fatalf("WithAWSObjectLock: %v", err)
+	}
+	manager := NewManager(WithProvider(provider))
+
+	if err := manager.SetHold(ctx, "contracts/a.pdf", true); err != nil {
+		t.Fatalf("SetHold: %v", err)
+	}
+	if status := client.legalHoldInputs[0].LegalHold.Status; status != types.ObjectLockLegalHoldStatusOn {
+		t.Fatalf("unexpected legal hold status %s", status)
+	}
+	if err := manager.SetRetention(ctx, "contracts/a.pdf", time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("SetRetention: %v", err)
+	}
+	if mode := client.retentionInputs[0].Retention.Mode; mode != types.ObjectLockRetentionModeCompliance {
+		t.Fatalf("unexpected retention mode %s", mode)
+	}
+
+	info, err := manager.StatFile(ctx, "contracts/a.pdf")
+	if err != nil || !info.LegalHold || info.RetainUntil.IsZero() {
+		t.Fatalf("expected object lock surfaced by StatFile, got %#v (%v)", info, err)
+	}
+	assertRetained(t, manager.DeleteFile(ctx, "contracts/a.pdf"))
+
+	if err := WithAWSObjectLock("forever")(&AWSProvider{}); err == nil {
+		t.Fatalf("expected unknown mode to fail")
+	}
+}
@@ -0,0 +1,71 @@
+package uploader
+
+import "context"
+
+// ProviderRouter picks the provider that stores key. Returning nil falls back to the provider set
+// with WithProvider. Routers see the caller's context, so tenants can be routed by values the
+// application attaches; RouteInfoFromContext adds the content type and size the manager knows.
+//
+// Routers must be deterministic for a key: reads, deletes and every step of a chunked session
+// route again and must land on the provider that received the write.
+type ProviderRouter func(ctx context.Context, key string) Uploader
+
+// RouteInfo describes the object being written when the router runs. Size is zero when the
+// manager does not know it upfront (presigned posts) and both fields are empty on reads.
+type RouteInfo struct {
+	ContentType string
+	Size        int64
+}
+
+type routeInfoContextKey struct{}
+
+// WithProviderRouter routes each operation to the provider returned by router. Capabilities are
+// checked against the routed provider, so a route to a backend without ChunkedUploader fails
+// chunked uploads with ErrNotImplemented while other routes keep working. Routed providers are
+// not validated by the manager; List, CollectGarbage and chunk session listings use the default
+// provider.
+func WithProviderRouter(router ProviderRouter) Option {
+	return func(m *Manager) {
+		m.router = router
+	}
+}
+
+// RouteInfoFromContext returns the object facts the manager attached before calling the router.
+func RouteInfoFromContext(ctx context.Context) (RouteInfo, bool) {
+	if ctx == nil {
+		return RouteInfo{}, false
+	}
+	info, ok := ctx.Value(routeInfoContextKey{}).(RouteInfo)
+	return info, ok
+}
+
+func withRouteInfo(ctx context.Context, contentType string, size int64) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, routeInfoContextKey{}, RouteInfo{ContentType: contentType, Size: size})
+}
+
+// sessionRouteContext routes chunked session steps with the facts recorded at initiation so every
+// step resolves to the same provider.
+func sessionRouteContext(ctx context.Context, session *ChunkSession) context.Context {
+	var contentType string
+	if session.Metadata != nil {
+		contentType = session.Metadata.ContentType
+	}
+	return withRouteInfo(ctx, contentType, session.TotalSize)
+}
+
+// providerFor resolves the provider for key: the derivative provider for derivative keys, then the
+// router, falling back to the default provider.
+func (m *Manager) providerFor(ctx context.Context, key string) Uploader {
+	if provider, ok := m.derivativeProviderFor(key); ok {
+		return provider
+	}
+	if m.router != nil {
+		if provider := m.router(ctx, key); provider != nil {
+			return provider
+		}
+	}
+	return m.currentProvider()
+}
@@ -0,0 +1,85 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type tenantCtxKey struct{}
+
+func TestProviderRouter(t *testing.T) {
+	defaults := newMemoryProvider()
+	videos := newMemoryProvider()
+	tenant := newMemoryProvider()
+	legacy := &mockUploader{}
+
+	var seen []RouteInfo
+	manager := NewManager(
+		WithProvider(defaults),
+		WithProviderRouter(func(ctx context.Context, key string) Uploader {
+			if info, ok := RouteInfoFromContext(ctx); ok {
+				seen = append(seen, info)
+				if strings.HasPrefix(info.ContentType, "video/") {
+					return videos
+				}
+			}
+			if ctx.Value(tenantCtxKey{}) == "acme" {
+				return tenant
+			}
+			if strings.HasPrefix(key, "legacy/") {
+				return legacy
+			}
+			return nil
+		}),
+	)
+
+	ctx := context.Background()
+	if _, err := manager.UploadFile(ctx, "clips/intro.mp4", []byte("video"), WithContentType("video/mp4")); err != nil {
+		t.Fatalf("UploadFile video failed: %v", err)
+	}
+	if _, ok := videos.files["clips/intro.mp4"]; !ok {
+		t.Fatalf("expected video to be routed to the video provider")
+	}
+	if len(seen) == 0 || seen[0].Size != 5 {
+		t.Fatalf("expected router to see the upload size, got %#v", seen)
+	}
+
+	acme := context.WithValue(ctx, tenantCtxKey{}, "acme")
+	if _, err := manager.UploadFile(acme, "avatars/a.txt", []byte("acme"), WithContentType("text/plain")); err != nil {
+		t.Fatalf("UploadFile tenant failed: %v", err)
+	}
+	if content, err := manager.GetFile(acme, "avatars/a.txt"); err != nil || string(content) != "acme" {
+		t.Fatalf("expected tenant read to hit tenant provider, got %q (%v)", content, err)
+	}
+	if _, ok := defaults.files["avatars/a.txt"]; ok {
+		t.Fatalf("expected tenant upload to skip the default provider")
+	}
+
+	if _, err := manager.UploadFile(ctx, "avatars/b.txt", []byte("b"), WithContentType("text/plain")); err != nil {
+		t.Fatalf("UploadFile default failed: %v", err)
+	}
+	if _, ok := defaults.files["avatars/b.txt"]; !ok {
+		t.Fatalf("expected unrouted upload to use the default provider")
+	}
+
+	session, err := manager.InitiateChunked(ctx, "clips/long.mp4", 6, WithContentType("video/mp4"))
+	if err != nil {
+		t.Fatalf("InitiateChunked failed: %v", err)
+	}
+	if err := manager.UploadChunk(ctx, session.ID, 0, bytes.NewReader([]byte("chunks"))); err != nil {
+		t.Fatalf("UploadChunk failed: %v", err)
+	}
+	if _, err := manager.CompleteChunked(ctx, session.ID); err != nil {
+		t.Fatalf("CompleteChunked failed: %v", err)
+	}
+	if string(videos.files["clips/long.mp4"]) != "chunks" {
+		t.Fatalf("expected every chunked step to route to the video provider")
+	}
+
+	if _, err := manager.InitiateChunked(ctx, "legacy/archive.bin", 10); !errors.Is(err, ErrNotImplemented) {
+		t.Fatalf("expected ErrNotImplemented for route without chunk support, got %v", err)
+	}
+}
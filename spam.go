@@ -0,0 +1,245 @@
+package uploader
+
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	gerrors "github.com/goliatone/go-errors"
+)
+
+// SpamSignal names a heuristic that flagged an upload.
+type SpamSignal string
+
+const (
+	// SpamDoubleExtension flags names that hide an executable behind another extension, such as
+	// photo.jpg.exe.
+	SpamDoubleExtension SpamSignal = "double_extension"
+	// SpamDuplicateBurst flags one identity sending the same content repeatedly.
+	SpamDuplicateBurst SpamSignal = "duplicate_burst"
+	// SpamHashFlood flags the same content arriving repeatedly from any number of identities.
+	SpamHashFlood SpamSignal = "hash_flood"
+)
+
+// SpamAction is what a SpamPolicy decides for a flagged upload.
+type SpamAction int
+
+const (
+	// SpamAllow stores the upload anyway.
+	SpamAllow SpamAction = iota
+	// SpamThrottle rejects the upload with a retryable SPAM_THROTTLED error (HTTP 429).
+	SpamThrottle
+	// SpamReject rejects the upload with SPAM_REJECTED (HTTP 403).
+	SpamReject
+)
+
+// SpamHeuristics configures WithSpamHeuristics. Zero thresholds disable their heuristic.
+type SpamHeuristics struct {
+	// DuplicateBurst flags an identity once it sends the same content more than this many times
+	// within Window.
+	DuplicateBurst int
+	// HashFlood flags content once it is uploaded more than this many times within Window,
+	// whoever sends it.
+	HashFlood int
+	// Window is how far back repeats are counted, DefaultSpamWindow when zero.
+	Window time.Duration
+	// DoubleExtensions flags file names ending in an executable extension after another one.
+	DoubleExtensions bool
+}
+
+// SpamCheck describes a flagged upload to the SpamPolicy.
+type SpamCheck struct {
+	Identity    string
+	Filename    string
+	ContentType string
+	Size        int64
+	Checksum    string
+	Signals     []SpamSignal
+}
+
+// SpamPolicy decides what happens to an upload that raised at least one signal.
+type SpamPolicy func(ctx context.Context, check *SpamCheck) SpamAction
+
+// DefaultSpamPolicy rejects disguised executables and throttles repeated content.
+func DefaultSpamPolicy(_ context.Context, check *SpamCheck) SpamAction {
+	for _, signal := range check.Signals {
+		if signal == SpamDoubleExtension {
+			return SpamReject
+		}
+	}
+	return SpamThrottle
+}
+
+// WithSpamHeuristics screens multipart uploads (HandleFile, HandleImageWithThumbnails) for spam,
+// aimed at public forms. Every attempt is counted, so a client that keeps flooding stays flagged
+// until it pauses for a window. identity may be nil, which disables DuplicateBurst; policy
+// defaults to DefaultSpamPolicy. Counters live in memory, per Manager.
+func WithSpamHeuristics(identity IdentityFunc, heuristics SpamHeuristics, policy SpamPolicy) Option {
+	return func(m *Manager) {
+		if heuristics.Window <= 0 {
+			heuristics.Window = DefaultSpamWindow
+		}
+		if policy == nil {
+			policy = DefaultSpamPolicy
+		}
+		m.spamGuard = &spamGuard{
+			identity:   identity,
+			heuristics: heuristics,
+			policy:     policy,
+			hashes:     make(map[string]*hashActivity),
+		}
+	}
+}
+
+// screenUpload runs the spam heuristics for a multipart file. checksum is only called when a
+// content heuristic is enabled.
+func (m *Manager) screenUpload(ctx context.Context, filename, contentType string, size int64, checksum func() string) error {
+	guard := m.spamGuard
+	if guard == nil {
+		return nil
+	}
+
+	check := &SpamCheck{Filename: filename, ContentType: contentType, Size: size}
+	if guard.identity != nil {
+		check.Identity = guard.identity(ctx)
+	}
+
+	if guard.heuristics.DoubleExtensions && hasDoubleExtension(filename) {
+		check.Signals = append(check.Signals, SpamDoubleExtension)
+	}
+	if guard.heuristics.DuplicateBurst > 0 || guard.heuristics.HashFlood > 0 {
+		check.Checksum = checksum()
+		check.Signals = append(check.Signals, guard.record(check.Identity, check.Checksum, m.now())...)
+	}
+
+	if len(check.Signals) == 0 {
+		return nil
+	}
+
+	action := guard.policy(ctx, check)
+	m.log(ctx).Info("upload flagged as spam", "filename", filename, "identity", check.Identity, "signals", check.Signals, "action", action)
+
+	signals := make([]string, len(check.Signals))
+	for i, signal := range check.Signals {
+		signals[i] = string(signal)
+	}
+
+	switch action {
+	case SpamThrottle:
+		return m.correlateError(ctx, gerrors.New("upload throttled as suspected spam", gerrors.CategoryRateLimit).
+			WithCode(429).
+			WithTextCode("SPAM_THROTTLED").
+			WithMetadata(map[string]any{
+				"signals":             signals,
+				"retry_after_seconds": int(math.Ceil(guard.heuristics.Window.Seconds())),
+			}))
+	case SpamReject:
+		return m.correlateError(ctx, gerrors.New("upload rejected as suspected spam", gerrors.CategoryAuthz).
+			WithCode(403).
+			WithTextCode("SPAM_REJECTED").
+			WithMetadata(map[string]any{
+				"signals": signals,
+			}))
+	default:
+		return nil
+	}
+}
+
+type spamGuard struct {
+	identity   IdentityFunc
+	heuristics SpamHeuristics
+	policy     SpamPolicy
+
+	mu        sync.Mutex
+	hashes    map[string]*hashActivity
+	lastPrune time.Time
+}
+
+// hashActivity holds recent upload times of one content hash, overall and per identity.
+type hashActivity struct {
+	seen       []time.Time
+	byIdentity map[string][]time.Time
+}
+
+// record counts an upload of checksum and returns the repeat heuristics it trips.
+func (g *spamGuard) record(identity, checksum string, now time.Time) []SpamSignal {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	since := now.Add(-g.heuristics.Window)
+	g.prune(now, since)
+
+	activity, ok := g.hashes[checksum]
+	if !ok {
+		activity = &hashActivity{byIdentity: make(map[string][]time.Time)}
+		g.hashes[checksum] = activity
+	}
+
+	var signals []SpamSignal
+
+	activity.seen = append(recentTimes(activity.seen, since), now)
+	if limit := g.heuristics.HashFlood; limit > 0 && len(activity.seen) > limit {
+		signals = append(signals, SpamHashFlood)
+	}
+
+	if identity != "" {
+		sent := append(recentTimes(activity.byIdentity[identity], since), now)
+		activity.byIdentity[identity] = sent
+		if limit := g.heuristics.DuplicateBurst; limit > 0 && len(sent) > limit {
+			signals = append(signals, SpamDuplicateBurst)
+		}
+	}
+
+	return signals
+}
+
+// prune forgets hashes not seen within the window, at most once per window.
+func (g *spamGuard) prune(now, since time.Time) {
+	if now.Sub(g.lastPrune) < g.heuristics.Window {
+		return
+	}
+	g.lastPrune = now
+
+	for checksum, activity := range g.hashes {
+		if n := len(activity.seen); n == 0 || activity.seen[n-1].Before(since) {
+			delete(g.hashes, checksum)
+			continue
+		}
+		for identity, sent := range activity.byIdentity {
+			if sent[len(sent)-1].Before(since) {
+				delete(activity.byIdentity, identity)
+			}
+		}
+	}
+}
+
+// recentTimes drops the times before since; times are in ascending order.
+func recentTimes(times []time.Time, since time.Time) []time.Time {
+	for len(times) > 0 && times[0].Before(since) {
+		times = times[1:]
+	}
+	return times
+}
+
+// executableExtensions are extensions that run when opened on common desktops.
+var executableExtensions = map[string]bool{
+	".apk": true, ".app": true, ".bat": true, ".cmd": true, ".com": true, ".cpl": true,
+	".dll": true, ".exe": true, ".hta": true, ".jar": true, ".js": true, ".jse": true,
+	".lnk": true, ".msi": true, ".pif": true, ".ps1": true, ".reg": true, ".scr": true,
+	".sh": true, ".vbe": true, ".vbs": true, ".wsf": true,
+}
+
+// hasDoubleExtension reports names like "photo.jpg.exe": an executable extension following
+// another extension. Trailing dots and spaces, which Windows strips, are ignored.
+func hasDoubleExtension(filename string) bool {
+	name := strings.ToLower(strings.TrimRight(filepath.Base(filename), ". "))
+	ext := filepath.Ext(name)
+	if !executableExtensions[ext] {
+		return false
+	}
+	inner := strings.TrimRight(strings.TrimSuffix(name, ext), ". ")
+	return filepath.Ext(inner) != ""
+}
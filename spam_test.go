@@ -0,0 +1,98 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	gerrors "github.com/goliatone/go-errors"
+)
+
+func assertSpamError(t *testing.T, err error, textCode string) {
+	t.Helper()
+	var uerr *gerrors.Error
+	if !errors.As(err, &uerr) || uerr.TextCode != textCode {
+		t.Fatalf("expected %s error, got %v", textCode, err)
+	}
+}
+
+func TestHasDoubleExtension(t *testing.T) {
+	for name, want := range map[string]bool{
+		"photo.jpg.exe":     true,
+		"Invoice.PDF.scr":   true,
+		"photo.jpg.exe. . ": true,
+		"dir/photo.png.js":  true,
+		"photo.exe":         false,
+		"photo.jpg":         false,
+		"archive.tar.gz":    false,
+		".exe":              false,
+	} {
+		if got := hasDoubleExtension(name); got != want {
+			t.Errorf("hasDoubleExtension(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestSpamHeuristicsRejectDoubleExtension(t *testing.T) {
+	provider := newMemoryProvider()
+	manager := NewManager(
+		WithProvider(provider),
+		// A loose extension allowlist would otherwise let the executable through.
+		WithValidator(NewValidator(WithAllowedImageFormats(map[string]bool{".png": true, ".exe": true}))),
+		WithSpamHeuristics(nil, SpamHeuristics{DoubleExtensions: true}, nil),
+	)
+
+	file := newTestFileHeader(t, "file", "photo.png.exe", "image/png", createTestPNG(8, 8))
+	_, err := manager.HandleFile(context.Background(), file, "uploads")
+	assertSpamError(t, err, "SPAM_REJECTED")
+	if len(provider.files) != 0 {
+		t.Fatalf("expected nothing stored, got %v", provider.files)
+	}
+}
+
+func TestSpamHeuristicsRepeatedContent(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	var checks []*SpamCheck
+	manager := NewManager(
+		WithProvider(newMemoryProvider()),
+		WithClock(func() time.Time { return now }),
+		WithSpamHeuristics(clientID, SpamHeuristics{DuplicateBurst: 1, HashFlood: 2, Window: time.Minute},
+			func(ctx context.Context, check *SpamCheck) SpamAction {
+				checks = append(checks, check)
+				return DefaultSpamPolicy(ctx, check)
+			}),
+	)
+
+	alice := context.WithValue(context.Background(), clientIDContextKey{}, "alice")
+	bob := context.WithValue(context.Background(), clientIDContextKey{}, "bob")
+	carol := context.WithValue(context.Background(), clientIDContextKey{}, "carol")
+	content := createTestPNG(8, 8)
+	upload := func(ctx context.Context) error {
+		_, err := manager.HandleFile(ctx, newTestFileHeader(t, "file", "photo.png", "image/png", content), "uploads")
+		return err
+	}
+
+	if err := upload(alice); err != nil {
+		t.Fatalf("first upload: %v", err)
+	}
+	err := upload(alice)
+	assertSpamError(t, err, "SPAM_THROTTLED")
+	if wait, ok := RetryAfter(err); !ok || wait != time.Minute {
+		t.Fatalf("expected retry after the window, got %v (%v)", wait, ok)
+	}
+	if len(checks) != 1 || len(checks[0].Signals) != 1 || checks[0].Signals[0] != SpamDuplicateBurst {
+		t.Fatalf("expected duplicate burst signal, got %#v", checks)
+	}
+
+	err = upload(bob)
+	assertSpamError(t, err, "SPAM_THROTTLED")
+	if last := checks[len(checks)-1]; last.Identity != "bob" || last.Signals[0] != SpamHashFlood || last.Checksum == "" {
+		t.Fatalf("expected hash flood signal, got %#v", last)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if err := upload(carol); err != nil {
+		t.Fatalf("expected counters to expire with the window: %v", err)
+	}
+}
@@ -0,0 +1,220 @@
+package uploader
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"image"
+	"io"
+	"mime/multipart"
+	"os"
+	"time"
+)
+
+// WithSpoolThreshold makes HandleFile stream files larger than size straight from the multipart
+// spool to providers implementing StreamUploader, keeping peak memory flat. Spooled uploads
+// return FileMeta without Content. A size <= 0 disables spooling.
+func WithSpoolThreshold(size int64) Option {
+	return func(m *Manager) {
+		m.spoolThreshold = size
+	}
+}
+
+// shouldSpool reports whether a file of size uploaded under path is streamed from the spool. The
+// final key is only known after hashing, so the decision routes on path.
+func (m *Manager) shouldSpool(ctx context.Context, path string, size int64) bool {
+	if m.spoolThreshold <= 0 || size <= m.spoolThreshold {
+		return false
+	}
+	_, ok := m.providerFor(ctx, path).(StreamUploader)
+	return ok
+}
+
+// handleSpooledFile mirrors handleFile without reading the payload into memory. The spool is read
+// twice (checksum, then upload) so providers receive a seekable reader, which S3 needs to sign
+// the request.
+func (m *Manager) handleSpooledFile(ctx context.Context, file *multipart.FileHeader, src multipart.File, path, contentType string, triggerCallback bool) (*FileMeta, error) {
+	size := file.Size
+
+	head := make([]byte, 512)
+	n, err := src.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	if err := m.validator.validateContent(contentType, size, head[:n], io.NewSectionReader(src, 0, size)); err != nil {
+		return nil, m.correlateError(ctx, err)
+	}
+
+	hash := sha256.New()
+	if _, err := m.buffers.Copy(hash, io.NewSectionReader(src, 0, size)); err != nil {
+		return nil, err
+	}
+	checksum := hex.EncodeToString(hash.Sum(nil))
+
+	if err := m.screenUpload(ctx, file.Filename, contentType, size, func() string { return checksum }); err != nil {
+		return nil, err
+	}
+
+	name, err := m.objectName(file, path, checksum)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.validator.ValidateKey(name); err != nil {
+		return nil, m.correlateError(ctx, err)
+	}
+
+	ctx = withRouteInfo(ctx, contentType, size)
+	opts, err := m.visibilityOptions(ctx, &Metadata{}, []UploadOption{WithContentType(contentType),
+		WithStorageClass(m.storageClass), WithCacheControl(m.storedFileCacheControl(contentType))})
+	if err != nil {
+		return nil, err
+	}
+
+	spool, onDisk := src.(*os.File)
+	store := func(key string, opts ...UploadOption) (string, error) {
+		url, err := observeCall(ctx, m, "upload", key, func() (string, error) {
+			provider := m.providerFor(ctx, key)
+			if linker, ok := provider.(LocalFileUploader); ok && onDisk {
+				// The multipart form owns the spool file, so it is linked rather than moved.
+				return linker.UploadLocalFile(ctx, key, spool.Name(), opts...)
+			}
+			if streamer, ok := provider.(StreamUploader); ok {
+				return streamer.UploadStream(ctx, key, m.throttle(ctx, io.NewSectionReader(src, 0, size)), size, opts...)
+			}
+			content, err := m.buffers.ReadAll(io.NewSectionReader(src, 0, size))
+			if err != nil {
+				return "", err
+			}
+			return provider.UploadFile(ctx, key, content, opts...)
+		})
+		if err == nil {
+			recordWrite(ctx, key)
+			m.recordTransfer("upload", size, 0)
+		}
+		return url, err
+	}
+
+	started := time.Now()
+	result, err := m.storeGenerated(ctx, name, store, opts...)
+	if err != nil {
+		return nil, err
+	}
+	name = result.Key
+
+	meta := &FileMeta{
+		ContentType:  contentType,
+		Name:         name,
+		OriginalName: file.Filename,
+		Size:         size,
+		URL:          result.URL,
+		Checksum:     checksum,
+	}
+
+	if cfg, _, err := image.DecodeConfig(io.NewSectionReader(src, 0, size)); err == nil {
+		meta.Width, meta.Height = cfg.Width, cfg.Height
+	}
+
+	m.attachAttributes(ctx, meta)
+	m.enrichFileMeta(ctx, meta, nil, m.storageClass)
+
+	if triggerCallback {
+		if err := m.runPostProcessors(ctx, meta); err != nil {
+			return nil, err
+		}
+		if err := m.maybeRunCallback(ctx, CallbackOperationUpload, meta); err != nil {
+			return nil, err
+		}
+	}
+
+	result.Size, result.ContentType = size, contentType
+	m.recordUpload(ctx, result, started)
+	return meta, nil
+}
+
+// UploadLocalFile stores the file at srcPath under path. Providers implementing LocalFileUploader
+// place it without copying; others receive a stream or, as a last resort, the buffered content.
+func (m *Manager) UploadLocalFile(ctx context.Context, path, srcPath string, opts ...UploadOption) (string, error) {
+	if err := m.ensureWritable(); err != nil {
+		return "", err
+	}
+
+	if err := m.validateKey(path); err != nil {
+		return "", err
+	}
+
+	if err := m.ensureProvider(ctx); err != nil {
+		return "", err
+	}
+
+	info, err := os.Stat(srcPath)
+	if err != nil {
+		return "", err
+	}
+
+	md := &Metadata{}
+	for _, opt := range opts {
+		opt(md)
+	}
+	if opts, err = m.visibilityOptions(ctx, md, opts); err != nil {
+		return "", err
+	}
+	ctx = withRouteInfo(ctx, md.ContentType, info.Size())
+
+	if md.ReservationToken != "" {
+		if err := m.checkReservation(ctx, md.ReservationToken, path, md.ContentType, info.Size()); err != nil {
+			return "", err
+		}
+	}
+
+	started := time.Now()
+	result, err := m.storeWithCollisionPolicy(ctx, path, func(key string, opts ...UploadOption) (string, error) {
+		return m.storeLocalFile(ctx, key, srcPath, info.Size(), opts...)
+	}, opts...)
+	if err != nil {
+		return "", err
+	}
+	m.consumeReservation(ctx, md.ReservationToken)
+
+	result.Size, result.ContentType = info.Size(), md.ContentType
+	m.recordUpload(ctx, result, started)
+	return result.URL, nil
+}
+
+func (m *Manager) storeLocalFile(ctx context.Context, path, srcPath string, size int64, opts ...UploadOption) (string, error) {
+	url, err := observeCall(ctx, m, "upload", path, func() (string, error) {
+		return m.uploadLocalFile(ctx, path, srcPath, opts...)
+	})
+	if err == nil {
+		m.recordTransfer("upload", size, 0)
+	}
+	return url, err
+}
+
+func (m *Manager) uploadLocalFile(ctx context.Context, path, srcPath string, opts ...UploadOption) (string, error) {
+	provider := m.providerFor(ctx, path)
+	if linker, ok := provider.(LocalFileUploader); ok {
+		return linker.UploadLocalFile(ctx, path, srcPath, opts...)
+	}
+
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	if streamer, ok := provider.(StreamUploader); ok {
+		info, err := src.Stat()
+		if err != nil {
+			return "", err
+		}
+		return streamer.UploadStream(ctx, path, m.throttle(ctx, src), info.Size(), opts...)
+	}
+
+	content, err := m.buffers.ReadAll(src)
+	if err != nil {
+		return "", err
+	}
+	return provider.UploadFile(ctx, path, content, opts...)
+}
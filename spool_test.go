@@ -0,0 +1,159 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHandleFileSpoolsLargeFiles(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	manager := NewManager(WithProvider(NewFSProvider(dir)), WithSpoolThreshold(64))
+
+	png := createTestPNG(32, 24)
+	meta, err := manager.HandleFile(ctx, newTestFileHeader(t, "file", "photo.png", "image/png", png), "images")
+	if err != nil {
+		t.Fatalf("HandleFile failed: %v", err)
+	}
+
+	if meta.Content != nil {
+		t.Fatalf("expected spooled upload to omit content")
+	}
+
+	sum := sha256.Sum256(png)
+	if meta.Checksum != hex.EncodeToString(sum[:]) {
+		t.Fatalf("unexpected checksum %q", meta.Checksum)
+	}
+
+	if meta.Width != 32 || meta.Height != 24 || meta.Size != int64(len(png)) {
+		t.Fatalf("unexpected meta: %#v", meta)
+	}
+
+	stored, err := os.ReadFile(filepath.Join(dir, meta.ProviderKey))
+	if err != nil || !bytes.Equal(stored, png) {
+		t.Fatalf("expected stored file to match upload (%v)", err)
+	}
+}
+
+func TestHandleFileSpoolValidatesWholeFile(t *testing.T) {
+	ctx := context.Background()
+	png := createTestPNG(200, 200)
+	if len(png) <= 512 {
+		t.Fatalf("expected a test image larger than the sniffed head, got %d bytes", len(png))
+	}
+
+	manager := NewManager(
+		WithProvider(NewFSProvider(t.TempDir())),
+		WithSpoolThreshold(64),
+		WithValidator(NewValidator(WithMinFileSize(600))),
+	)
+	meta, err := manager.HandleFile(ctx, newTestFileHeader(t, "file", "photo.png", "image/png", png), "images")
+	if err != nil {
+		t.Fatalf("expected the minimum size to be checked against the whole file: %v", err)
+	}
+	if meta.Content != nil {
+		t.Fatalf("expected the upload to be spooled")
+	}
+
+	manager = NewManager(
+		WithProvider(NewFSProvider(t.TempDir())),
+		WithSpoolThreshold(64),
+		WithValidator(NewValidator(WithMinFileSize(int64(len(png))+1))),
+	)
+	_, err = manager.HandleFile(ctx, newTestFileHeader(t, "file", "photo.png", "image/png", png), "images")
+	assertTextCode(t, err, "FILE_TOO_SMALL")
+
+	manager = NewManager(
+		WithProvider(NewFSProvider(t.TempDir())),
+		WithSpoolThreshold(64),
+		WithValidator(NewValidator(WithMaxImageDimensions(100, 100))),
+	)
+	_, err = manager.HandleFile(ctx, newTestFileHeader(t, "file", "photo.png", "image/png", png), "images")
+	assertTextCode(t, err, "IMAGE_TOO_LARGE")
+}
+
+func TestHandleFileBelowSpoolThresholdKeepsContent(t *testing.T) {
+	manager := NewManager(WithProvider(NewFSProvider(t.TempDir())), WithSpoolThreshold(1<<20))
+
+	png := createTestPNG(4, 4)
+	meta, err := manager.HandleFile(context.Background(), newTestFileHeader(t, "file", "photo.png", "image/png", png), "images")
+	if err != nil {
+		t.Fatalf("HandleFile failed: %v", err)
+	}
+
+	if !bytes.Equal(meta.Content, png) {
+		t.Fatalf("expected buffered upload to keep content")
+	}
+}
+
+func TestHandleFileSpoolFallsBackWithoutStreamUploader(t *testing.T) {
+	provider := &mockUploader{}
+	manager := NewManager(WithProvider(provider), WithSpoolThreshold(1))
+
+	png := createTestPNG(4, 4)
+	meta, err := manager.HandleFile(context.Background(), newTestFileHeader(t, "file", "photo.png", "image/png", png), "images")
+	if err != nil {
+		t.Fatalf("HandleFile failed: %v", err)
+	}
+
+	if !bytes.Equal(meta.Content, png) {
+		t.Fatalf("expected fallback to buffer content")
+	}
+}
+
+func TestHandleFileLinksDiskSpool(t *testing.T) {
+	buf := &bytes.Buffer{}
+	writer := multipart.NewWriter(buf)
+	part, err := writer.CreateFormFile("file", "photo.png")
+	if err != nil {
+		t.Fatalf("CreateFormFile: %v", err)
+	}
+	png := createTestPNG(16, 16)
+	part.Write(png)
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/", buf)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	if err := req.ParseMultipartForm(1); err != nil {
+		t.Fatalf("ParseMultipartForm: %v", err)
+	}
+	defer req.MultipartForm.RemoveAll()
+
+	fh := req.MultipartForm.File["file"][0]
+	fh.Header.Set("Content-Type", "image/png")
+
+	spool, err := fh.Open()
+	if err != nil {
+		t.Fatalf("open spool: %v", err)
+	}
+	defer spool.Close()
+
+	spoolFile, ok := spool.(*os.File)
+	if !ok {
+		t.Skip("multipart form kept the file in memory")
+	}
+
+	dir := t.TempDir()
+	manager := NewManager(WithProvider(NewFSProvider(dir)), WithSpoolThreshold(1))
+	meta, err := manager.HandleFile(context.Background(), fh, "images")
+	if err != nil {
+		t.Fatalf("HandleFile failed: %v", err)
+	}
+
+	spoolInfo, _ := spoolFile.Stat()
+	storedInfo, err := os.Stat(filepath.Join(dir, meta.ProviderKey))
+	if err != nil {
+		t.Fatalf("stat stored file: %v", err)
+	}
+	if !os.SameFile(spoolInfo, storedInfo) {
+		t.Fatalf("expected stored file to be linked to the multipart spool")
+	}
+}
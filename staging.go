@@ -0,0 +1,183 @@
+package uploader
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// StagedFile is an upload held under the staging prefix until CommitFile publishes it under Key
+// or DiscardFile drops it. The token carries the final key, so any instance sharing the provider
+// can commit it.
+type StagedFile struct {
+	Token       string `json:"token"`
+	Key         string `json:"key"`
+	StagingKey  string `json:"staging_key"`
+	URL         string `json:"url"`
+	Size        int64  `json:"size"`
+	ContentType string `json:"content_type,omitempty"`
+}
+
+// WithStagingPrefix sets the key prefix StageFile writes under, DefaultStagingPrefix by default.
+// Keep it out of public paths; provider lifecycle rules or PurgeStagedFiles can expire leftovers.
+func WithStagingPrefix(prefix string) Option {
+	return func(m *Manager) {
+		if prefix != "" {
+			m.stagingPrefix = strings.TrimSuffix(prefix, "/") + "/"
+		}
+	}
+}
+
+// StageFile stores content for key under the staging prefix without publishing it. Commit it
+// with CommitFile once the application's own transaction succeeds, or drop it with DiscardFile.
+func (m *Manager) StageFile(ctx context.Context, key string, content []byte, opts ...UploadOption) (*StagedFile, error) {
+	if err := m.ensureWritable(); err != nil {
+		return nil, err
+	}
+
+	if err := m.validateKey(key); err != nil {
+		return nil, err
+	}
+
+	if err := m.ensureProvider(ctx); err != nil {
+		return nil, err
+	}
+
+	id, err := newStagingID()
+	if err != nil {
+		return nil, err
+	}
+
+	md := &Metadata{}
+	for _, opt := range opts {
+		opt(md)
+	}
+	contentType := md.ContentType
+	if contentType == "" {
+		contentType = detectContentType(key, content)
+	}
+
+	if err := m.checkRateLimit(ctx, int64(len(content))); err != nil {
+		return nil, err
+	}
+
+	token := id + "/" + key
+	staged := &StagedFile{
+		Token:       token,
+		Key:         key,
+		StagingKey:  m.stagingKey(token),
+		Size:        int64(len(content)),
+		ContentType: contentType,
+	}
+
+	staged.URL, err = m.putFile(ctx, staged.StagingKey, content, append([]UploadOption{WithContentType(contentType)}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	return staged, nil
+}
+
+// CommitFile publishes a staged upload under its final key, applying the collision policy and
+// opts as UploadFile would, then removes the staged copy. The content type is derived from the
+// key and content unless opts set one.
+func (m *Manager) CommitFile(ctx context.Context, token string, opts ...UploadOption) (*UploadResult, error) {
+	if err := m.ensureWritable(); err != nil {
+		return nil, err
+	}
+
+	key, err := parseStagingToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	stagingKey := m.stagingKey(token)
+	content, err := m.GetFile(ctx, stagingKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// StageFile already counted the upload against the rate limit.
+	opts = append([]UploadOption{WithContentType(detectContentType(key, content))}, opts...)
+	result, err := m.UploadFileResult(withoutRateLimit(ctx), key, content, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// The upload is published; a staged copy left behind is only garbage.
+	m.cleanupFiles(ctx, stagingKey)
+	return result, nil
+}
+
+// DiscardFile deletes a staged upload that will not be committed.
+func (m *Manager) DiscardFile(ctx context.Context, token string) error {
+	if err := m.ensureWritable(); err != nil {
+		return err
+	}
+
+	if _, err := parseStagingToken(token); err != nil {
+		return err
+	}
+	return m.DeleteFile(ctx, m.stagingKey(token))
+}
+
+// PurgeStagedFiles deletes staged uploads last modified before olderThan, such as those whose
+// application transaction never finished. It requires a provider implementing Lister and returns
+// how many objects were removed.
+func (m *Manager) PurgeStagedFiles(ctx context.Context, olderThan time.Time) (int, error) {
+	if err := m.ensureWritable(); err != nil {
+		return 0, err
+	}
+
+	objects, err := m.List(ctx, m.stagingKey(""))
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, obj := range objects {
+		if err := ctx.Err(); err != nil {
+			return removed, err
+		}
+		if obj.ModTime.IsZero() || !obj.ModTime.Before(olderThan) {
+			continue
+		}
+		if err := m.DeleteFile(ctx, obj.Key); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+func (m *Manager) stagingKey(token string) string {
+	prefix := m.stagingPrefix
+	if prefix == "" {
+		prefix = DefaultStagingPrefix
+	}
+	return prefix + token
+}
+
+// parseStagingToken returns the final key carried by a StageFile token.
+func parseStagingToken(token string) (string, error) {
+	id, key, ok := strings.Cut(token, "/")
+	if !ok || len(id) != 32 || key == "" {
+		return "", ErrInvalidStagingToken
+	}
+	if _, err := hex.DecodeString(id); err != nil {
+		return "", ErrInvalidStagingToken
+	}
+	if err := validateObjectKey(key); err != nil {
+		return "", ErrInvalidStagingToken
+	}
+	return key, nil
+}
+
+func newStagingID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,94 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gerrors "github.com/goliatone/go-errors"
+)
+
+func TestStageCommitAndDiscard(t *testing.T) {
+	ctx := context.Background()
+	provider := newMemoryProvider()
+	manager := NewManager(WithProvider(provider), WithStagingPrefix("pending"))
+
+	staged, err := manager.StageFile(ctx, "docs/report.txt", []byte("draft"))
+	if err != nil {
+		t.Fatalf("StageFile: %v", err)
+	}
+	if staged.StagingKey != "pending/"+staged.Token || staged.Key != "docs/report.txt" {
+		t.Fatalf("unexpected staged file: %#v", staged)
+	}
+	if _, ok := provider.files["docs/report.txt"]; ok {
+		t.Fatalf("expected staged upload to stay unpublished")
+	}
+
+	result, err := manager.CommitFile(ctx, staged.Token)
+	if err != nil {
+		t.Fatalf("CommitFile: %v", err)
+	}
+	if result.Key != "docs/report.txt" || string(provider.files["docs/report.txt"]) != "draft" {
+		t.Fatalf("expected committed file, got %#v", result)
+	}
+	if _, ok := provider.files[staged.StagingKey]; ok {
+		t.Fatalf("expected staged copy removed after commit")
+	}
+
+	discarded, err := manager.StageFile(ctx, "docs/other.txt", []byte("draft"))
+	if err != nil {
+		t.Fatalf("StageFile: %v", err)
+	}
+	if err := manager.DiscardFile(ctx, discarded.Token); err != nil {
+		t.Fatalf("DiscardFile: %v", err)
+	}
+	if len(provider.files) != 1 {
+		t.Fatalf("expected only the committed file, got %v", provider.files)
+	}
+}
+
+func TestCommitFileRejectsInvalidToken(t *testing.T) {
+	manager := NewManager(WithProvider(newMemoryProvider()))
+
+	for _, token := range []string{"", "docs/report.txt", "0123456789abcdef0123456789abcdef/../secret"} {
+		_, err := manager.CommitFile(context.Background(), token)
+		var gerr *gerrors.Error
+		if !errors.As(err, &gerr) || gerr.TextCode != "INVALID_STAGING_TOKEN" {
+			t.Fatalf("token %q: expected INVALID_STAGING_TOKEN, got %v", token, err)
+		}
+	}
+}
+
+func TestPurgeStagedFiles(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	manager := NewManager(WithProvider(NewFSProvider(dir)))
+
+	stale, err := manager.StageFile(ctx, "docs/stale.txt", []byte("old"))
+	if err != nil {
+		t.Fatalf("StageFile: %v", err)
+	}
+	fresh, err := manager.StageFile(ctx, "docs/fresh.txt", []byte("new"))
+	if err != nil {
+		t.Fatalf("StageFile: %v", err)
+	}
+
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, stale.StagingKey), old, old); err != nil {
+		t.Fatalf("Chtimes: %v", err)
+	}
+
+	removed, err := manager.PurgeStagedFiles(ctx, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("PurgeStagedFiles: %v", err)
+	}
+	if removed != 1 {
+		t.Fatalf("expected one purged file, got %d", removed)
+	}
+	if _, err := manager.CommitFile(ctx, fresh.Token); err != nil {
+		t.Fatalf("expected fresh staged file to survive: %v", err)
+	}
+}
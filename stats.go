@@ -0,0 +1,205 @@
+package uploader
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// WithSlowOperationThreshold logs every provider call that takes longer than threshold with its
+// key, provider and duration. Zero disables slow-operation logging; Stats is collected either way.
+func WithSlowOperationThreshold(threshold time.Duration) Option {
+	return func(m *Manager) {
+		m.slowThreshold = threshold
+	}
+}
+
+// LatencyBucket counts calls that finished within UpperBound and above the previous bucket.
+// The last bucket has a zero UpperBound and collects everything slower.
+type LatencyBucket struct {
+	UpperBound time.Duration `json:"upper_bound"`
+	Count      int64         `json:"count"`
+}
+
+// OperationStats aggregates the provider calls made for one operation since the manager was
+// created.
+type OperationStats struct {
+	Provider  string          `json:"provider"`
+	Operation string          `json:"operation"`
+	Count     int64           `json:"count"`
+	Errors    int64           `json:"errors"`
+	Slow      int64           `json:"slow"`
+	BytesIn   int64           `json:"bytes_in"`
+	BytesOut  int64           `json:"bytes_out"`
+	Total     time.Duration   `json:"total"`
+	Max       time.Duration   `json:"max"`
+	Latency   []LatencyBucket `json:"latency"`
+}
+
+// Mean returns the average call duration.
+func (s OperationStats) Mean() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Count)
+}
+
+// Stats returns cumulative latency statistics per provider and operation, sorted by provider
+// then operation.
+func (m *Manager) Stats() []OperationStats {
+	m.stats.mu.Lock()
+	defer m.stats.mu.Unlock()
+
+	out := make([]OperationStats, 0, len(m.stats.ops))
+	for _, entry := range m.stats.ops {
+		snapshot := *entry
+		snapshot.Latency = append([]LatencyBucket(nil), entry.Latency...)
+		out = append(out, snapshot)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Provider != out[j].Provider {
+			return out[i].Provider < out[j].Provider
+		}
+		return out[i].Operation < out[j].Operation
+	})
+	return out
+}
+
+// ProviderStats sums the provider calls made through the manager per provider, for debugging
+// and /debug endpoints. Uploads counts "upload", "append", "compose" and "complete_chunked"
+// calls, Downloads "get" and "get_range" calls and Deletes "delete" and "delete_prefix" calls;
+// Requests and Errors cover every operation. BytesIn is what was written to the provider and
+// BytesOut what was read back from it.
+type ProviderStats struct {
+	Provider  string `json:"provider"`
+	Requests  int64  `json:"requests"`
+	Uploads   int64  `json:"uploads"`
+	Downloads int64  `json:"downloads"`
+	Deletes   int64  `json:"deletes"`
+	Errors    int64  `json:"errors"`
+	BytesIn   int64  `json:"bytes_in"`
+	BytesOut  int64  `json:"bytes_out"`
+}
+
+// ProviderStats returns the counters of Stats rolled up per provider, sorted by provider.
+func (m *Manager) ProviderStats() []ProviderStats {
+	var out []ProviderStats
+	for _, op := range m.Stats() {
+		if len(out) == 0 || out[len(out)-1].Provider != op.Provider {
+			out = append(out, ProviderStats{Provider: op.Provider})
+		}
+		entry := &out[len(out)-1]
+		entry.Requests += op.Count
+		entry.Errors += op.Errors
+		entry.BytesIn += op.BytesIn
+		entry.BytesOut += op.BytesOut
+		switch op.Operation {
+		case "upload", "append", "compose", "complete_chunked":
+			entry.Uploads += op.Count
+		case "get", "get_range":
+			entry.Downloads += op.Count
+		case "delete", "delete_prefix":
+			entry.Deletes += op.Count
+		}
+	}
+	return out
+}
+
+type operationStats struct {
+	mu  sync.Mutex
+	ops map[string]*OperationStats
+}
+
+func (s *operationStats) record(provider, op string, elapsed time.Duration, failed, slow bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := s.entry(provider, op)
+	entry.Count++
+	entry.Total += elapsed
+	if elapsed > entry.Max {
+		entry.Max = elapsed
+	}
+	if failed {
+		entry.Errors++
+	}
+	if slow {
+		entry.Slow++
+	}
+
+	for i := range entry.Latency {
+		if bound := entry.Latency[i].UpperBound; bound == 0 || elapsed <= bound {
+			entry.Latency[i].Count++
+			break
+		}
+	}
+}
+
+// transfer adds bytes written to and read from the provider to an operation.
+func (s *operationStats) transfer(provider, op string, in, out int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := s.entry(provider, op)
+	entry.BytesIn += in
+	entry.BytesOut += out
+}
+
+func (s *operationStats) entry(provider, op string) *OperationStats {
+	if s.ops == nil {
+		s.ops = make(map[string]*OperationStats)
+	}
+
+	id := provider + "\x00" + op
+	entry, ok := s.ops[id]
+	if !ok {
+		entry = &OperationStats{Provider: provider, Operation: op, Latency: newLatencyBuckets()}
+		s.ops[id] = entry
+	}
+	return entry
+}
+
+func newLatencyBuckets() []LatencyBucket {
+	buckets := make([]LatencyBucket, 0, len(DefaultLatencyBuckets)+1)
+	for _, bound := range DefaultLatencyBuckets {
+		buckets = append(buckets, LatencyBucket{UpperBound: bound})
+	}
+	return append(buckets, LatencyBucket{})
+}
+
+// observe times a provider call and counts it as in flight for SwapProvider. Call the returned
+// function with the call's error once it returns; observeCall does both and recovers panics.
+// The call is credited to the provider active when it started, even if SwapProvider replaced it
+// meanwhile.
+func (m *Manager) observe(op, key string) func(error) {
+	release, active := m.trackProvider()
+	provider := statsProviderName(active)
+	started := time.Now()
+	return func(err error) {
+		release()
+		elapsed := time.Since(started)
+
+		slow := m.slowThreshold > 0 && elapsed > m.slowThreshold
+		if slow {
+			m.log(context.Background()).Info("slow provider operation", "provider", provider, "operation", op, "key", key, "duration", elapsed)
+		}
+
+		m.stats.record(provider, op, elapsed, err != nil, slow)
+	}
+}
+
+// recordTransfer counts bytes moved by a successful provider call of op, under the active
+// provider.
+func (m *Manager) recordTransfer(op string, in, out int64) {
+	m.stats.transfer(statsProviderName(m.currentProvider()), op, in, out)
+}
+
+func statsProviderName(provider Uploader) string {
+	if name := providerName(provider); name != "" {
+		return name
+	}
+	return fmt.Sprintf("%T", provider)
+}
@@ -0,0 +1,103 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestManagerStatsAndSlowOperations(t *testing.T) {
+	ctx := context.Background()
+	logger := &mockLogger{}
+	provider := &mockUploader{
+		uploadFunc: func(_ context.Context, path string, _ []byte, _ ...UploadOption) (string, error) {
+			if path == "slow.txt" {
+				time.Sleep(20 * time.Millisecond)
+			}
+			return path, nil
+		},
+		getFunc: func(context.Context, string) ([]byte, error) {
+			return nil, errors.New("boom")
+		},
+	}
+
+	manager := NewManager(WithProvider(provider), WithLogger(logger), WithSlowOperationThreshold(10*time.Millisecond))
+
+	for _, key := range []string{"fast.txt", "slow.txt"} {
+		if _, err := manager.UploadFile(ctx, key, []byte("data")); err != nil {
+			t.Fatalf("UploadFile failed: %v", err)
+		}
+	}
+	if _, err := manager.GetFile(ctx, "missing.txt"); err == nil {
+		t.Fatalf("expected GetFile error")
+	}
+
+	stats := manager.Stats()
+	if len(stats) != 2 || stats[0].Operation != "get" || stats[1].Operation != "upload" {
+		t.Fatalf("unexpected stats: %#v", stats)
+	}
+
+	get, upload := stats[0], stats[1]
+	if get.Count != 1 || get.Errors != 1 {
+		t.Fatalf("unexpected get stats: %#v", get)
+	}
+
+	if upload.Count != 2 || upload.Slow != 1 || upload.Max < 20*time.Millisecond || upload.Mean() <= 0 {
+		t.Fatalf("unexpected upload stats: %#v", upload)
+	}
+
+	var bucketed int64
+	for _, bucket := range upload.Latency {
+		bucketed += bucket.Count
+	}
+	if bucketed != 2 || len(upload.Latency) != len(DefaultLatencyBuckets)+1 {
+		t.Fatalf("unexpected histogram: %#v", upload.Latency)
+	}
+
+	slowLogs := 0
+	for _, msg := range logger.infoMessages {
+		if msg == "slow provider operation" {
+			slowLogs++
+		}
+	}
+	if slowLogs != 1 {
+		t.Fatalf("expected one slow operation log, got %v", logger.infoMessages)
+	}
+}
+
+func TestManagerProviderStats(t *testing.T) {
+	ctx := context.Background()
+	manager := NewManager(WithProvider(NewFSProvider(t.TempDir())))
+
+	if _, err := manager.UploadFile(ctx, "a.txt", []byte("hello world")); err != nil {
+		t.Fatalf("UploadFile failed: %v", err)
+	}
+	if _, err := manager.GetFile(ctx, "a.txt"); err != nil {
+		t.Fatalf("GetFile failed: %v", err)
+	}
+	rc, err := manager.OpenRange(ctx, "a.txt", 6, 5)
+	if err != nil {
+		t.Fatalf("OpenRange failed: %v", err)
+	}
+	if _, err := io.ReadAll(rc); err != nil {
+		t.Fatalf("read range: %v", err)
+	}
+	rc.Close()
+	if _, err := manager.GetFile(ctx, "missing.txt"); err == nil {
+		t.Fatalf("expected GetFile error")
+	}
+	if err := manager.DeleteFile(ctx, "a.txt"); err != nil {
+		t.Fatalf("DeleteFile failed: %v", err)
+	}
+
+	stats := manager.ProviderStats()
+	if len(stats) != 1 {
+		t.Fatalf("expected a single provider, got %#v", stats)
+	}
+	want := ProviderStats{Provider: stats[0].Provider, Requests: 5, Uploads: 1, Downloads: 3, Deletes: 1, Errors: 1, BytesIn: 11, BytesOut: 16}
+	if stats[0] != want || want.Provider == "" {
+		t.Fatalf("expected %#v, got %#v", want, stats[0])
+	}
+}
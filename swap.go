@@ -0,0 +1,156 @@
+package uploader
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// providerState holds the active provider together with its validation result and the number of
+// provider calls still running against it, so SwapProvider can wait for them to finish.
+type providerState struct {
+	provider Uploader
+
+	mu        sync.Mutex
+	err       error
+	validated bool
+	inflight  int
+	idle      chan struct{}
+}
+
+func (s *providerState) validate(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.err = validateProviderOf(ctx, s.provider)
+	s.validated = s.err == nil
+	return s.err
+}
+
+func (s *providerState) ensure(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+
+	if s.validated {
+		return nil
+	}
+
+	if err := validateProviderOf(ctx, s.provider); err != nil {
+		s.err = err
+		return err
+	}
+
+	s.validated = true
+	return nil
+}
+
+func (s *providerState) acquire() {
+	s.mu.Lock()
+	s.inflight++
+	s.mu.Unlock()
+}
+
+func (s *providerState) release() {
+	s.mu.Lock()
+	s.inflight--
+	if s.inflight == 0 && s.idle != nil {
+		close(s.idle)
+		s.idle = nil
+	}
+	s.mu.Unlock()
+}
+
+// drain blocks until every call acquired on s has been released or ctx is done.
+func (s *providerState) drain(ctx context.Context) error {
+	s.mu.Lock()
+	if s.inflight == 0 {
+		s.mu.Unlock()
+		return nil
+	}
+	if s.idle == nil {
+		s.idle = make(chan struct{})
+	}
+	idle := s.idle
+	s.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func validateProviderOf(ctx context.Context, p Uploader) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	validator, ok := p.(ProviderValidator)
+	if !ok {
+		return nil
+	}
+
+	return validator.Validate(ctx)
+}
+
+func (m *Manager) currentProvider() Uploader {
+	state := m.backend.Load()
+	if state == nil {
+		return nil
+	}
+	return state.provider
+}
+
+// trackProvider counts a provider call against the active provider and returns the function that
+// releases it, along with that provider. The state is re-checked after acquiring so a call racing
+// a swap is never counted against a provider that is already draining.
+func (m *Manager) trackProvider() (func(), Uploader) {
+	for {
+		state := m.backend.Load()
+		if state == nil {
+			return func() {}, nil
+		}
+
+		state.acquire()
+		if m.backend.Load() == state {
+			return state.release, state.provider
+		}
+		state.release()
+	}
+}
+
+// SwapProvider replaces the active provider without restarting the manager. The new provider is
+// validated first; on success it is switched in atomically, so calls that start afterwards use it,
+// and SwapProvider then waits for calls still running against the previous provider to finish.
+// ctx only bounds that wait: when it expires the swap has already happened and ctx.Err() is
+// returned so callers know the old provider may still be in use.
+func (m *Manager) SwapProvider(ctx context.Context, p Uploader) error {
+	if p == nil {
+		return ErrProviderNotConfigured
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if err := validateProviderOf(ctx, p); err != nil {
+		return fmt.Errorf("swap provider: %w", err)
+	}
+
+	prev := m.backend.Swap(&providerState{provider: p, validated: true})
+	m.log(ctx).Info("provider swapped", "provider", fmt.Sprintf("%T", p))
+	if prev == nil {
+		return nil
+	}
+
+	if err := prev.drain(ctx); err != nil {
+		return fmt.Errorf("swap provider: drain previous provider: %w", err)
+	}
+
+	return nil
+}
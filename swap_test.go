@@ -0,0 +1,134 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSwapProviderDrainsInFlightCalls(t *testing.T) {
+	ctx := context.Background()
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+
+	old := &namedUploader{name: "old", mockUploader: &mockUploader{
+		getFunc: func(ctx context.Context, path string) ([]byte, error) {
+			close(started)
+			<-unblock
+			return []byte("old"), nil
+		},
+	}}
+	next := &namedUploader{name: "new", mockUploader: &mockUploader{
+		getFunc: func(ctx context.Context, path string) ([]byte, error) {
+			return []byte("new"), nil
+		},
+	}}
+
+	manager := NewManager(WithProvider(old))
+
+	getDone := make(chan []byte, 1)
+	go func() {
+		content, _ := manager.GetFile(ctx, "a.txt")
+		getDone <- content
+	}()
+	<-started
+
+	swapDone := make(chan error, 1)
+	go func() {
+		swapDone <- manager.SwapProvider(ctx, next)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for manager.currentProvider() != next {
+		if time.Now().After(deadline) {
+			t.Fatalf("provider was not switched")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if content, err := manager.GetFile(ctx, "b.txt"); err != nil || string(content) != "new" {
+		t.Fatalf("expected new provider to serve calls after swap, got %q (%v)", content, err)
+	}
+
+	select {
+	case err := <-swapDone:
+		t.Fatalf("swap returned before in-flight call finished: %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(unblock)
+	if content := <-getDone; string(content) != "old" {
+		t.Fatalf("expected in-flight call to finish on old provider, got %q", content)
+	}
+	if err := <-swapDone; err != nil {
+		t.Fatalf("SwapProvider failed: %v", err)
+	}
+
+	counts := map[string]int64{}
+	for _, stats := range manager.ProviderStats() {
+		counts[stats.Provider] = stats.Downloads
+	}
+	if counts["old"] != 1 || counts["new"] != 1 {
+		t.Fatalf("expected each call credited to the provider it started on, got %v", counts)
+	}
+}
+
+type namedUploader struct {
+	*mockUploader
+	name string
+}
+
+func (u *namedUploader) ProviderName() string { return u.name }
+
+func (u *namedUploader) ProviderKey(path string) string { return path }
+
+func TestSwapProviderRejectsInvalidProvider(t *testing.T) {
+	current := &mockUploader{}
+	manager := NewManager(WithProvider(current))
+
+	invalid := &mockUploader{
+		shouldValidate: true,
+		validateFunc: func(ctx context.Context) error {
+			return errors.New("bucket missing")
+		},
+	}
+
+	if err := manager.SwapProvider(context.Background(), invalid); err == nil {
+		t.Fatalf("expected validation error")
+	}
+	if manager.currentProvider() != current {
+		t.Fatalf("expected current provider to remain active")
+	}
+
+	if err := manager.SwapProvider(context.Background(), nil); !errors.Is(err, ErrProviderNotConfigured) {
+		t.Fatalf("expected ErrProviderNotConfigured, got %v", err)
+	}
+}
+
+func TestSwapProviderDrainTimeout(t *testing.T) {
+	unblock := make(chan struct{})
+	started := make(chan struct{})
+	manager := NewManager(WithProvider(&mockUploader{
+		getFunc: func(ctx context.Context, path string) ([]byte, error) {
+			close(started)
+			<-unblock
+			return nil, nil
+		},
+	}))
+	defer close(unblock)
+
+	go manager.GetFile(context.Background(), "slow.txt")
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	next := &mockUploader{}
+	if err := manager.SwapProvider(ctx, next); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected drain deadline error, got %v", err)
+	}
+	if manager.currentProvider() != next {
+		t.Fatalf("expected swap to be kept after drain timeout")
+	}
+}
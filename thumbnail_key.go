@@ -0,0 +1,160 @@
+package uploader
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// ThumbnailKeyTemplate names derivatives of an upload. {key} is the original key, {base} the
+// original key without its extension, {variant} the thumbnail size or derivative name, {ext} the
+// extension including the dot and {format} the extension without it. {variant} is required, as
+// is one of {key} or {base}.
+//
+// {ext} and {format} follow the derivative's content type when it has one of its own, so a WebP
+// rendition of a.png stored through PostProcessInput.StoreDerivative ends in .webp.
+type ThumbnailKeyTemplate string
+
+const (
+	// DefaultThumbnailKeyTemplate stores derivatives next to the original: images/a.png becomes
+	// images/a__small.png.
+	DefaultThumbnailKeyTemplate ThumbnailKeyTemplate = "{base}__{variant}{ext}"
+	// DerivativesFolderKeyTemplate keeps derivatives under their own prefix, one folder per
+	// original: images/a.png becomes derivatives/images/a.png/small.png. Bucket lifecycle rules
+	// can then target derivatives/ without touching originals.
+	DerivativesFolderKeyTemplate ThumbnailKeyTemplate = "derivatives/{key}/{variant}.{format}"
+)
+
+// Validate reports whether the template can name distinct derivatives.
+func (t ThumbnailKeyTemplate) Validate() error {
+	s := string(t)
+	if !strings.Contains(s, "{variant}") || (!strings.Contains(s, "{base}") && !strings.Contains(s, "{key}")) {
+		return fmt.Errorf("thumbnail key template %q must contain {variant} and one of {key} or {base}", t)
+	}
+	return nil
+}
+
+// Key returns the key of the variant derivative of name.
+func (t ThumbnailKeyTemplate) Key(name, variant string) string {
+	return t.derivativeKey(name, variant, path.Ext(name))
+}
+
+// derivativeKey returns the key of the variant derivative of name stored with extension ext,
+// which may differ from the original's.
+func (t ThumbnailKeyTemplate) derivativeKey(name, variant, ext string) string {
+	base := strings.TrimSuffix(name, path.Ext(name))
+	if base == "" {
+		base = name
+	}
+	return strings.NewReplacer(
+		"{key}", name,
+		"{base}", base,
+		"{variant}", variant,
+		"{ext}", ext,
+		"{format}", strings.TrimPrefix(ext, "."),
+	).Replace(string(t))
+}
+
+// pattern matches the keys the template produces. Only the first occurrence of each placeholder
+// is captured.
+func (t ThumbnailKeyTemplate) pattern() *regexp.Regexp {
+	pattern := regexp.QuoteMeta(string(t))
+	pattern = strings.Replace(pattern, `\{key\}`, `(?P<key>.+)`, 1)
+	pattern = strings.Replace(pattern, `\{base\}`, `(?P<base>.+)`, 1)
+	pattern = strings.Replace(pattern, `\{ext\}`, `(?P<ext>(?:\.[^./]*)?)`, 1)
+	pattern = strings.Replace(pattern, `\{format\}`, `(?P<format>[^./]*)`, 1)
+	pattern = strings.Replace(pattern, `\{variant\}`, `(?P<variant>[^/]+?)`, 1)
+	pattern = strings.NewReplacer(
+		`\{key\}`, `.+`,
+		`\{base\}`, `.+`,
+		`\{variant\}`, `[^/]+?`,
+		`\{ext\}`, `(?:\.[^./]*)?`,
+		`\{format\}`, `[^./]*`,
+	).Replace(pattern)
+	return regexp.MustCompile("(?s)^" + pattern + "$")
+}
+
+// thumbnailOriginal returns the key of the original a derivative key was built from, when key
+// matches a template pattern. Without {key} the original is rebuilt from {base} and {ext}, or
+// {format} when the template has no {ext}.
+func thumbnailOriginal(pattern *regexp.Regexp, key string) (string, bool) {
+	original, _, ok := parseDerivativeKey(pattern, key)
+	return original, ok
+}
+
+// parseDerivativeKey is thumbnailOriginal also returning the variant named in key.
+func parseDerivativeKey(pattern *regexp.Regexp, key string) (original, variant string, ok bool) {
+	match := pattern.FindStringSubmatch(key)
+	if match == nil {
+		return "", "", false
+	}
+	groups := make(map[string]string, 5)
+	for i, name := range pattern.SubexpNames() {
+		if name != "" {
+			groups[name] = match[i]
+		}
+	}
+	if original, ok := groups["key"]; ok {
+		return original, groups["variant"], true
+	}
+	ext, ok := groups["ext"]
+	if !ok && groups["format"] != "" {
+		ext = "." + groups["format"]
+	}
+	return groups["base"] + ext, groups["variant"], true
+}
+
+// BuildThumbnailKey returns the key DefaultThumbnailKeyTemplate gives the variant derivative of
+// name. Use Manager.ThumbnailKey when the manager has its own template.
+func BuildThumbnailKey(name, variant string) string {
+	return DefaultThumbnailKeyTemplate.Key(name, variant)
+}
+
+// WithThumbnailKeyTemplate changes how thumbnails and other derivatives are named, e.g.
+// DerivativesFolderKeyTemplate or "thumbs/{variant}/{base}{ext}" to keep them out of the
+// originals' folders. An invalid
+// template is logged and the default is kept.
+func WithThumbnailKeyTemplate(template ThumbnailKeyTemplate) Option {
+	return func(m *Manager) {
+		if err := template.Validate(); err != nil {
+			m.logger.Error("ignoring thumbnail key template", err)
+			return
+		}
+		m.thumbnailKeys = template
+	}
+}
+
+// ThumbnailKey returns the key the manager stores the variant derivative of name under.
+func (m *Manager) ThumbnailKey(name, variant string) string {
+	return m.thumbnailKeyTemplate().Key(name, variant)
+}
+
+func (m *Manager) thumbnailKeyTemplate() ThumbnailKeyTemplate {
+	if m.thumbnailKeys == "" {
+		return DefaultThumbnailKeyTemplate
+	}
+	return m.thumbnailKeys
+}
+
+// ThumbnailURL returns the URL of the named thumbnail, or fallback when it was not generated.
+func (im *ImageMeta) ThumbnailURL(name, fallback string) string {
+	if im == nil {
+		return fallback
+	}
+	if thumb, ok := im.Thumbnails[name]; ok && thumb != nil && thumb.URL != "" {
+		return thumb.URL
+	}
+	return fallback
+}
+
+// ThumbnailKey returns the storage key of the named thumbnail, or "" when it was not generated.
+func (im *ImageMeta) ThumbnailKey(name string) string {
+	if im == nil {
+		return ""
+	}
+	if thumb, ok := im.Thumbnails[name]; ok && thumb != nil {
+		return thumb.Name
+	}
+	return ""
+}
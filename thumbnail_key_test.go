@@ -0,0 +1,104 @@
+package uploader
+
+import (
+	"context"
+	"testing"
+)
+
+func TestThumbnailKeyTemplate(t *testing.T) {
+	if key := BuildThumbnailKey("images/a.png", "small"); key != "images/a__small.png" {
+		t.Fatalf("unexpected default key %q", key)
+	}
+
+	template := ThumbnailKeyTemplate("thumbs/{variant}/{base}{ext}")
+	key := template.Key("images/a.png", "small")
+	if key != "thumbs/small/images/a.png" {
+		t.Fatalf("unexpected templated key %q", key)
+	}
+	if original, ok := thumbnailOriginal(template.pattern(), key); !ok || original != "images/a.png" {
+		t.Fatalf("expected key to map back to the original, got %q %v", original, ok)
+	}
+
+	if err := ThumbnailKeyTemplate("{base}{ext}").Validate(); err == nil {
+		t.Fatalf("expected template without {variant} rejected")
+	}
+	if err := ThumbnailKeyTemplate("thumbs/{variant}{ext}").Validate(); err == nil {
+		t.Fatalf("expected template without {key} or {base} rejected")
+	}
+	manager := NewManager(WithThumbnailKeyTemplate("{base}{ext}"))
+	if key := manager.ThumbnailKey("a.png", "small"); key != "a__small.png" {
+		t.Fatalf("expected invalid template ignored, got %q", key)
+	}
+}
+
+func TestDerivativesFolderKeyTemplate(t *testing.T) {
+	ctx := context.Background()
+	template := DerivativesFolderKeyTemplate
+	key := template.Key("images/a.png", "small")
+	if key != "derivatives/images/a.png/small.png" {
+		t.Fatalf("unexpected derivative key %q", key)
+	}
+	if original, ok := thumbnailOriginal(template.pattern(), key); !ok || original != "images/a.png" {
+		t.Fatalf("expected key to map back to the original, got %q %v", original, ok)
+	}
+
+	byFormat := ThumbnailKeyTemplate("thumbs/{base}/{variant}.{format}")
+	if original, ok := thumbnailOriginal(byFormat.pattern(), "thumbs/images/a/small.png"); !ok || original != "images/a.png" {
+		t.Fatalf("expected {format} to rebuild the original extension, got %q %v", original, ok)
+	}
+
+	jobs := planThumbnailJobs([]ObjectInfo{
+		{Key: "images/a.png"},
+		{Key: "derivatives/images/a.png/small.png"},
+	}, nil, []ThumbnailSize{{Name: "small"}, {Name: "large"}}, template, false)
+	if len(jobs) != 1 || jobs[0].original.Key != "images/a.png" || len(jobs[0].sizes) != 1 || jobs[0].sizes[0].Name != "large" {
+		t.Fatalf("unexpected jobs %#v", jobs)
+	}
+
+	provider := newMemoryProvider()
+	registry := NewPostProcessorRegistry().Register("image/*",
+		PostProcessorFunc(func(ctx context.Context, in *PostProcessInput) error {
+			_, err := in.StoreDerivative(ctx, "preview", []byte("webp"), "image/webp")
+			return err
+		}),
+	)
+	manager := NewManager(WithProvider(provider), WithPostProcessors(registry), WithThumbnailKeyTemplate(template))
+
+	file := newTestFileHeader(t, "file", "photo.png", "image/png", createTestPNG(4, 4))
+	meta, err := manager.HandleFile(ctx, file, "images")
+	if err != nil {
+		t.Fatalf("HandleFile: %v", err)
+	}
+	want := "derivatives/" + meta.Name + "/preview.webp"
+	if _, ok := provider.files[want]; !ok {
+		t.Fatalf("expected derivative stored as %q", want)
+	}
+}
+
+func TestImageMetaThumbnailHelpers(t *testing.T) {
+	ctx := context.Background()
+	provider := newMemoryProvider()
+	manager := NewManager(WithProvider(provider), WithThumbnailKeyTemplate("{base}@{variant}{ext}"))
+
+	file := newTestFileHeader(t, "file", "photo.png", "image/png", createTestPNG(32, 32))
+	meta, err := manager.HandleImageWithThumbnails(ctx, file, "images", []ThumbnailSize{
+		{Name: "small", Width: 8, Height: 8, Fit: "cover"},
+	})
+	if err != nil {
+		t.Fatalf("HandleImageWithThumbnails: %v", err)
+	}
+
+	key := meta.ThumbnailKey("small")
+	if key != manager.ThumbnailKey(meta.Name, "small") {
+		t.Fatalf("expected thumbnail stored under the template key, got %q", key)
+	}
+	if _, ok := provider.files[key]; !ok {
+		t.Fatalf("expected %q stored", key)
+	}
+	if url := meta.ThumbnailURL("small", "/placeholder.png"); url != meta.Thumbnails["small"].URL {
+		t.Fatalf("unexpected thumbnail URL %q", url)
+	}
+	if url := meta.ThumbnailURL("large", "/placeholder.png"); url != "/placeholder.png" {
+		t.Fatalf("expected fallback for a missing size, got %q", url)
+	}
+}
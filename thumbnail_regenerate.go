@@ -0,0 +1,219 @@
+package uploader
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// RegenerateThumbnailsOptions tunes a thumbnail backfill run.
+type RegenerateThumbnailsOptions struct {
+	// Workers bounds how many originals are processed concurrently. Defaults to
+	// DefaultThumbnailWorkers.
+	Workers int
+	// Force regenerates derivatives even when they exist and are newer than the original.
+	Force bool
+	// Progress, when set, is invoked after each original is processed. Calls are serialized.
+	Progress func(ThumbnailProgress)
+}
+
+// ThumbnailProgress reports the outcome of a single original during a backfill run.
+type ThumbnailProgress struct {
+	Key       string
+	Processed int
+	Total     int
+	Generated []string
+	Err       error
+}
+
+// ThumbnailFailure records an original whose derivatives could not be regenerated.
+type ThumbnailFailure struct {
+	Key string
+	Err error
+}
+
+// RegenerateThumbnailsResult summarizes a backfill run.
+type RegenerateThumbnailsResult struct {
+	Scanned   int
+	Processed int
+	Generated int
+	Skipped   int
+	Failures  []ThumbnailFailure
+}
+
+type thumbnailJob struct {
+	original ObjectInfo
+	sizes    []ThumbnailSize
+	// contentType overrides the type derived from the original key extension.
+	contentType string
+}
+
+// RegenerateThumbnails walks originals under prefix and (re)creates derivatives that are missing
+// or older than their original. Individual failures are collected in the result so one corrupt
+// image does not abort the whole run; only listing and context errors are returned.
+func (m *Manager) RegenerateThumbnails(ctx context.Context, prefix string, sizes []ThumbnailSize, opts RegenerateThumbnailsOptions) (*RegenerateThumbnailsResult, error) {
+	if err := m.ensureWritable(); err != nil {
+		return nil, err
+	}
+
+	if err := ValidateThumbnailSizes(sizes); err != nil {
+		return nil, err
+	}
+
+	objects, err := m.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	derivatives, err := m.listDerivatives(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RegenerateThumbnailsResult{Scanned: len(objects)}
+	jobs := planThumbnailJobs(objects, derivatives, sizes, m.thumbnailKeyTemplate(), opts.Force)
+	result.Skipped = len(objects) - len(jobs)
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = DefaultThumbnailWorkers
+	}
+	if workers > len(jobs) {
+		workers = len(jobs)
+	}
+
+	queue := make(chan thumbnailJob)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				generated, err := m.regenerateThumbnails(ctx, job)
+
+				mu.Lock()
+				result.Processed++
+				result.Generated += len(generated)
+				if err != nil {
+					result.Failures = append(result.Failures, ThumbnailFailure{Key: job.original.Key, Err: err})
+				}
+				if opts.Progress != nil {
+					opts.Progress(ThumbnailProgress{
+						Key:       job.original.Key,
+						Processed: result.Processed,
+						Total:     len(jobs),
+						Generated: generated,
+						Err:       err,
+					})
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, job := range jobs {
+		if ctx.Err() != nil {
+			break
+		}
+		queue <- job
+	}
+	close(queue)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+func (m *Manager) regenerateThumbnails(ctx context.Context, job thumbnailJob) ([]string, error) {
+	contentType := job.contentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(path.Ext(job.original.Key))
+	}
+	processor := m.ensureImageProcessor()
+
+	var source []byte
+	if _, remote := processor.(SourceImageProcessor); !remote {
+		var err error
+		if source, err = m.GetFile(ctx, job.original.Key); err != nil {
+			return nil, err
+		}
+	}
+
+	var generated []string
+	for _, size := range job.sizes {
+		if err := ctx.Err(); err != nil {
+			return generated, err
+		}
+
+		thumbBytes, thumbContentType, err := generateThumbnail(ctx, processor, job.original.Key, source, size, contentType)
+		if err != nil {
+			return generated, fmt.Errorf("generate %s: %w", size.Name, err)
+		}
+
+		thumbKey := m.ThumbnailKey(job.original.Key, size.Name)
+		if _, err := m.putFile(ctx, thumbKey, thumbBytes, WithContentType(thumbContentType), WithStorageClass(m.storageClass)); err != nil {
+			return generated, fmt.Errorf("upload %s: %w", size.Name, err)
+		}
+		m.emitThumbnailGenerated(ctx, job.original.Key, thumbKey, size.Name, thumbContentType, thumbBytes)
+		generated = append(generated, thumbKey)
+	}
+
+	return generated, nil
+}
+
+// planThumbnailJobs picks image originals from a listing and the sizes each one needs. A key is
+// treated as a derivative when it matches the template for another listed key. derivatives holds
+// the listing of a separate derivative provider, if any.
+func planThumbnailJobs(objects, derivatives []ObjectInfo, sizes []ThumbnailSize, template ThumbnailKeyTemplate, force bool) []thumbnailJob {
+	pattern := template.pattern()
+	byKey := make(map[string]ObjectInfo, len(objects)+len(derivatives))
+	for _, obj := range derivatives {
+		byKey[obj.Key] = obj
+	}
+	for _, obj := range objects {
+		byKey[obj.Key] = obj
+	}
+
+	var jobs []thumbnailJob
+	for _, obj := range objects {
+		if isThumbnailKey(pattern, obj.Key, byKey) || !isImageKey(obj.Key) {
+			continue
+		}
+
+		var needed []ThumbnailSize
+		for _, size := range sizes {
+			thumb, ok := byKey[template.Key(obj.Key, size.Name)]
+			if force || !ok || thumb.ModTime.Before(obj.ModTime) {
+				needed = append(needed, size)
+			}
+		}
+
+		if len(needed) > 0 {
+			jobs = append(jobs, thumbnailJob{original: obj, sizes: needed})
+		}
+	}
+
+	return jobs
+}
+
+func isThumbnailKey(pattern *regexp.Regexp, key string, existing map[string]ObjectInfo) bool {
+	original, ok := thumbnailOriginal(pattern, key)
+	if !ok || original == key {
+		return false
+	}
+	_, ok = existing[original]
+	return ok
+}
+
+func isImageKey(key string) bool {
+	return strings.HasPrefix(mime.TypeByExtension(path.Ext(key)), "image/")
+}
@@ -0,0 +1,84 @@
+package uploader
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRegenerateThumbnails(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	manager := NewManager(WithProvider(NewFSProvider(dir)))
+
+	png := createTestPNG(20, 20)
+	for _, key := range []string{"images/a.png", "images/b.png", "images/a__small.png"} {
+		if _, err := manager.UploadFile(ctx, key, png); err != nil {
+			t.Fatalf("UploadFile %s failed: %v", key, err)
+		}
+	}
+	if _, err := manager.UploadFile(ctx, "images/broken.png", []byte("not an image")); err != nil {
+		t.Fatalf("UploadFile failed: %v", err)
+	}
+	if _, err := manager.UploadFile(ctx, "images/readme.txt", []byte("text")); err != nil {
+		t.Fatalf("UploadFile failed: %v", err)
+	}
+
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "images", "a.png"), past, past); err != nil {
+		t.Fatalf("Chtimes failed: %v", err)
+	}
+
+	sizes := []ThumbnailSize{
+		{Name: "small", Width: 8, Height: 8, Fit: "cover"},
+		{Name: "medium", Width: 12, Height: 12, Fit: "contain"},
+	}
+
+	var mu sync.Mutex
+	var progress []ThumbnailProgress
+	result, err := manager.RegenerateThumbnails(ctx, "images/", sizes, RegenerateThumbnailsOptions{
+		Workers: 2,
+		Progress: func(p ThumbnailProgress) {
+			mu.Lock()
+			progress = append(progress, p)
+			mu.Unlock()
+		},
+	})
+	if err != nil {
+		t.Fatalf("RegenerateThumbnails failed: %v", err)
+	}
+
+	if result.Scanned != 5 || result.Processed != 3 || result.Skipped != 2 {
+		t.Fatalf("unexpected counts: %#v", result)
+	}
+
+	if result.Generated != 3 {
+		t.Fatalf("expected 3 generated derivatives, got %d", result.Generated)
+	}
+
+	if len(result.Failures) != 1 || result.Failures[0].Key != "images/broken.png" {
+		t.Fatalf("expected broken image failure, got %#v", result.Failures)
+	}
+
+	if len(progress) != 3 || progress[2].Processed != 3 || progress[2].Total != 3 {
+		t.Fatalf("unexpected progress reports: %#v", progress)
+	}
+
+	for _, key := range []string{"images/a__medium.png", "images/b__small.png", "images/b__medium.png"} {
+		if _, err := os.Stat(filepath.Join(dir, key)); err != nil {
+			t.Fatalf("expected derivative %s: %v", key, err)
+		}
+	}
+}
+
+func TestRegenerateThumbnailsRequiresLister(t *testing.T) {
+	manager := NewManager(WithProvider(&mockUploader{}))
+
+	_, err := manager.RegenerateThumbnails(context.Background(), "", []ThumbnailSize{{Name: "small", Width: 8, Height: 8, Fit: "cover"}}, RegenerateThumbnailsOptions{})
+	if err != ErrNotImplemented {
+		t.Fatalf("expected ErrNotImplemented, got %v", err)
+	}
+}
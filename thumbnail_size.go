@@ -22,6 +22,8 @@ type ThumbnailSize struct {
 	Width  int
 	Height int
 	Fit    string
+	// Encoding overrides the processor encoder options for this derivative.
+	Encoding ImageEncoding
 }
 
 // ValidateThumbnailSizes ensures the configured derivatives are viable.
@@ -100,6 +102,10 @@ func ValidateThumbnailSizes(sizes []ThumbnailSize) error {
 				},
 			)
 		}
+
+		if err := validateImageEncoding(fieldPrefix+".encoding", size.Encoding); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -43,6 +43,27 @@ func TestValidateThumbnailSizes(t *testing.T) {
 			},
 			expectErr: true,
 		},
+		{
+			name: "valid encoding",
+			sizes: []ThumbnailSize{
+				{Name: "hq", Width: 100, Height: 100, Fit: "cover", Encoding: ImageEncoding{Quality: 95, ChromaSubsampling: "4:4:4"}},
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid quality",
+			sizes: []ThumbnailSize{
+				{Name: "bad-quality", Width: 100, Height: 100, Fit: "cover", Encoding: ImageEncoding{Quality: 120}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid subsampling",
+			sizes: []ThumbnailSize{
+				{Name: "bad-chroma", Width: 100, Height: 100, Fit: "cover", Encoding: ImageEncoding{ChromaSubsampling: "4:1:1"}},
+			},
+			expectErr: true,
+		},
 	}
 
 	for _, tc := range cases {
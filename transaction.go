@@ -0,0 +1,124 @@
+package uploader
+
+import (
+	"context"
+	"mime/multipart"
+	"slices"
+	"sync"
+)
+
+// UploadTx stores files on behalf of a WithTransaction function. Every object written through
+// it, including thumbnails, derivatives and preserved originals, is deleted if the function
+// fails. Deletes are deferred until the function succeeds.
+type UploadTx interface {
+	UploadFile(ctx context.Context, path string, content []byte, opts ...UploadOption) (string, error)
+	HandleFile(ctx context.Context, file *multipart.FileHeader, path string) (*FileMeta, error)
+	HandleImageWithThumbnails(ctx context.Context, file *multipart.FileHeader, path string, sizes []ThumbnailSize) (*ImageMeta, error)
+	DeleteFile(ctx context.Context, path string) error
+	// Keys returns the objects written so far, in write order.
+	Keys() []string
+}
+
+type uploadTxContextKey struct{}
+
+type uploadTx struct {
+	manager *Manager
+
+	mu      sync.Mutex
+	written []string
+	deletes []string
+}
+
+var _ UploadTx = &uploadTx{}
+
+// WithTransaction runs fn with an UploadTx, giving best-effort atomicity between database writes
+// and object storage: when fn returns an error or panics, the objects it stored are deleted and
+// its deletes are dropped. Run the database transaction inside fn so its outcome decides both.
+// Objects replaced in place cannot be restored; use CollisionVersion or CollisionError to keep
+// them.
+func (m *Manager) WithTransaction(ctx context.Context, fn func(tx UploadTx) error) (err error) {
+	if err := m.ensureWritable(); err != nil {
+		return err
+	}
+
+	tx := &uploadTx{manager: m}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.rollback(ctx)
+			panic(r)
+		}
+	}()
+
+	if err = fn(tx); err != nil {
+		tx.rollback(ctx)
+		return err
+	}
+
+	tx.mu.Lock()
+	deletes := slices.Clone(tx.deletes)
+	tx.mu.Unlock()
+	m.cleanupFiles(ctx, deletes...)
+	return nil
+}
+
+func (t *uploadTx) UploadFile(ctx context.Context, path string, content []byte, opts ...UploadOption) (string, error) {
+	return t.manager.UploadFile(t.track(ctx), path, content, opts...)
+}
+
+func (t *uploadTx) HandleFile(ctx context.Context, file *multipart.FileHeader, path string) (*FileMeta, error) {
+	return t.manager.HandleFile(t.track(ctx), file, path)
+}
+
+func (t *uploadTx) HandleImageWithThumbnails(ctx context.Context, file *multipart.FileHeader, path string, sizes []ThumbnailSize) (*ImageMeta, error) {
+	return t.manager.HandleImageWithThumbnails(t.track(ctx), file, path, sizes)
+}
+
+func (t *uploadTx) DeleteFile(_ context.Context, path string) error {
+	if err := validateObjectKey(path); err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.deletes = append(t.deletes, path)
+	return nil
+}
+
+func (t *uploadTx) Keys() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return slices.Clone(t.written)
+}
+
+func (t *uploadTx) track(ctx context.Context) context.Context {
+	return context.WithValue(ctx, uploadTxContextKey{}, t)
+}
+
+func (t *uploadTx) rollback(ctx context.Context) {
+	t.mu.Lock()
+	keys := slices.Clone(t.written)
+	t.mu.Unlock()
+
+	slices.Reverse(keys)
+	t.manager.cleanupFiles(context.WithoutCancel(ctx), keys...)
+}
+
+// recordWrite notes key on the transaction ctx belongs to, if any, so it can be rolled back.
+func recordWrite(ctx context.Context, key string) {
+	t, _ := ctx.Value(uploadTxContextKey{}).(*uploadTx)
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.written = append(t.written, key)
+}
+
+// withoutUploadTx detaches ctx from its transaction for writes that must survive a rollback.
+func withoutUploadTx(ctx context.Context) context.Context {
+	if t, _ := ctx.Value(uploadTxContextKey{}).(*uploadTx); t == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, uploadTxContextKey{}, (*uploadTx)(nil))
+}
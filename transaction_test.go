@@ -0,0 +1,94 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestWithTransactionRollsBackOnError(t *testing.T) {
+	ctx := context.Background()
+	provider := newMemoryProvider()
+	provider.files["docs/old.txt"] = []byte("keep")
+	manager := NewManager(WithProvider(provider))
+
+	errDB := errors.New("insert failed")
+	var written []string
+	err := manager.WithTransaction(ctx, func(tx UploadTx) error {
+		if _, err := tx.UploadFile(ctx, "docs/new.txt", []byte("new")); err != nil {
+			return err
+		}
+		file := newTestFileHeader(t, "file", "photo.png", "image/png", createTestPNG(32, 32))
+		sizes := []ThumbnailSize{{Name: "small", Width: 8, Height: 8, Fit: "cover"}}
+		if _, err := tx.HandleImageWithThumbnails(ctx, file, "images", sizes); err != nil {
+			return err
+		}
+		if err := tx.DeleteFile(ctx, "docs/old.txt"); err != nil {
+			return err
+		}
+		written = tx.Keys()
+		return errDB
+	})
+	if !errors.Is(err, errDB) {
+		t.Fatalf("expected function error, got %v", err)
+	}
+
+	if len(written) != 3 {
+		t.Fatalf("expected upload, image and thumbnail tracked, got %v", written)
+	}
+	if len(provider.files) != 1 || string(provider.files["docs/old.txt"]) != "keep" {
+		t.Fatalf("expected only the untouched file left, got %v", provider.files)
+	}
+}
+
+func TestWithTransactionAppliesDeletesOnSuccess(t *testing.T) {
+	ctx := context.Background()
+	provider := newMemoryProvider()
+	provider.files["docs/old.txt"] = []byte("old")
+	manager := NewManager(WithProvider(provider))
+
+	err := manager.WithTransaction(ctx, func(tx UploadTx) error {
+		if _, err := tx.UploadFile(ctx, "docs/new.txt", []byte("new")); err != nil {
+			return err
+		}
+		return tx.DeleteFile(ctx, "docs/old.txt")
+	})
+	if err != nil {
+		t.Fatalf("WithTransaction: %v", err)
+	}
+
+	if _, ok := provider.files["docs/old.txt"]; ok {
+		t.Fatalf("expected deferred delete applied")
+	}
+	if string(provider.files["docs/new.txt"]) != "new" {
+		t.Fatalf("expected committed upload kept")
+	}
+}
+
+func TestWithTransactionRollsBackOnPanicAndKeepsVersions(t *testing.T) {
+	ctx := context.Background()
+	provider := newMemoryProvider()
+	provider.files["docs/report.txt"] = []byte("v1")
+	manager := NewManager(WithProvider(provider), WithCollisionPolicy(CollisionVersion))
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected panic to propagate")
+		}
+		if len(provider.files) != 1 {
+			t.Fatalf("expected only the version copy left, got %v", provider.files)
+		}
+		for key, content := range provider.files {
+			if key == "docs/report.txt" || string(content) != "v1" {
+				t.Fatalf("expected version of the replaced file, got %s=%q", key, content)
+			}
+		}
+	}()
+
+	_ = manager.WithTransaction(ctx, func(tx UploadTx) error {
+		if _, err := tx.UploadFile(ctx, "docs/report.txt", []byte("v2")); err != nil {
+			return err
+		}
+		panic("boom")
+	})
+}
@@ -0,0 +1,95 @@
+package uploader
+
+import (
+	"context"
+	"mime"
+	"mime/multipart"
+	"path/filepath"
+	"strings"
+)
+
+// UploadTransform rewrites uploaded content before HandleFile stores it, e.g. to normalize image
+// formats. It returns the content and content type to store; returning the input unchanged is a
+// no-op.
+type UploadTransform interface {
+	Transform(ctx context.Context, content []byte, contentType string) ([]byte, string, error)
+}
+
+// UploadTransformFunc adapts a function to UploadTransform.
+type UploadTransformFunc func(ctx context.Context, content []byte, contentType string) ([]byte, string, error)
+
+func (f UploadTransformFunc) Transform(ctx context.Context, content []byte, contentType string) ([]byte, string, error) {
+	return f(ctx, content, contentType)
+}
+
+// WithKeepTransformedOriginals also stores the untransformed upload whenever a transform changes
+// its content type, under the same key with the original extension. Its key is recorded in the
+// "original_key" attribute.
+func WithKeepTransformedOriginals() Option {
+	return func(m *Manager) {
+		m.keepOriginals = true
+	}
+}
+
+// WithUploadTransforms registers pre-storage transforms that HandleFile and
+// HandleImageWithThumbnails run in order after validation. Uploads with transforms are always
+// buffered, so they bypass WithSpoolThreshold.
+func WithUploadTransforms(transforms ...UploadTransform) Option {
+	return func(m *Manager) {
+		m.transforms = append(m.transforms, transforms...)
+	}
+}
+
+// applyTransforms runs the registered transforms. The returned header reflects the stored
+// content: its size, and a filename extension matching the new content type so generated keys
+// stay truthful.
+func (m *Manager) applyTransforms(ctx context.Context, file *multipart.FileHeader, content []byte, contentType string) (*multipart.FileHeader, []byte, string, error) {
+	original := contentType
+	for _, transform := range m.transforms {
+		var err error
+		if content, contentType, err = transform.Transform(ctx, content, contentType); err != nil {
+			return nil, nil, "", err
+		}
+	}
+
+	if contentType == original && int64(len(content)) == file.Size {
+		return file, content, contentType, nil
+	}
+
+	stored := *file
+	stored.Size = int64(len(content))
+	if contentType != original {
+		if ext := extensionForContentType(contentType); ext != "" {
+			stored.Filename = strings.TrimSuffix(file.Filename, filepath.Ext(file.Filename)) + ext
+		}
+	}
+	return &stored, content, contentType, nil
+}
+
+// storeTransformedOriginal uploads the pre-transform content next to the stored key.
+func (m *Manager) storeTransformedOriginal(ctx context.Context, file *multipart.FileHeader, storedKey string, content []byte, contentType string) (string, error) {
+	key := strings.TrimSuffix(storedKey, filepath.Ext(storedKey)) + strings.ToLower(filepath.Ext(file.Filename))
+	if _, err := m.putFile(ctx, key, content, WithContentType(contentType), WithStorageClass(m.storageClass)); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+var preferredExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+	"image/heic": ".heic",
+	"image/heif": ".heif",
+}
+
+func extensionForContentType(contentType string) string {
+	if ext, ok := preferredExtensions[contentType]; ok {
+		return ext
+	}
+	if exts, err := mime.ExtensionsByType(contentType); err == nil && len(exts) > 0 {
+		return exts[0]
+	}
+	return ""
+}
@@ -1,23 +1,44 @@
 package uploader
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"mime/multipart"
-	"path"
+	"regexp"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	gerrors "github.com/goliatone/go-errors"
-	"github.com/google/uuid"
 )
 
 type Metadata struct {
 	ContentType  string
 	CacheControl string
-	Public       bool
-	TTL          time.Duration
+	// Deprecated: use Visibility. WithVisibility keeps Public in sync for providers that still
+	// read it.
+	Public         bool
+	TTL            time.Duration
+	StorageClass   string
+	Attributes     map[string]string
+	IdempotencyKey string
+	MoveSource     bool
+	IfNotExists    bool
+	S3             S3RequestOptions
+	// ReservationToken ties the upload to a ReserveUpload reservation.
+	ReservationToken string
+	// AbortOnCancel aborts a chunked session when the InitiateChunked context ends first.
+	AbortOnCancel bool
+	// Visibility is the access level of the stored object; empty keeps the provider default.
+	Visibility Visibility
+	// SuccessRedirect is where storage sends the browser after a presigned post succeeds.
+	SuccessRedirect string
+	// CallbackURL is signed into a presigned post and stored with the object, for the app to
+	// notify once the upload lands.
+	CallbackURL string
 }
 
 type UploadOption func(*Metadata)
@@ -30,14 +51,34 @@ func WithCacheControl(c string) UploadOption {
 	return func(m *Metadata) { m.CacheControl = c }
 }
 
+// Deprecated: use WithVisibility(VisibilityPublicRead) or WithVisibility(VisibilityPrivate).
 func WithPublicAccess(a bool) UploadOption {
-	return func(m *Metadata) { m.Public = a }
+	if a {
+		return WithVisibility(VisibilityPublicRead)
+	}
+	return WithVisibility(VisibilityPrivate)
 }
 
 func WithTTL(ttl time.Duration) UploadOption {
 	return func(m *Metadata) { m.TTL = ttl }
 }
 
+func WithStorageClass(class string) UploadOption {
+	return func(m *Metadata) { m.StorageClass = class }
+}
+
+// WithMoveSource tells LocalFileUploader implementations they may consume the source file, e.g.
+// by renaming it into place. Only use it for temp files the caller owns.
+func WithMoveSource() UploadOption {
+	return func(m *Metadata) { m.MoveSource = true }
+}
+
+// WithIfNotExists asks providers implementing ConditionalWriter to fail with ErrFileExists
+// instead of replacing an existing object.
+func WithIfNotExists() UploadOption {
+	return func(m *Metadata) { m.IfNotExists = true }
+}
+
 type Uploader interface {
 	UploadFile(ctx context.Context, path string, content []byte, opts ...UploadOption) (string, error)
 	GetFile(ctx context.Context, path string) ([]byte, error)
@@ -49,6 +90,13 @@ type ProviderValidator interface {
 	Validate(context.Context) error
 }
 
+type Lister interface {
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+}
+
+// ChunkedUploader is implemented by providers that assemble objects from parts. Continuation
+// state belongs in the session (ProviderData and UploadedParts), never in provider memory, so any
+// instance holding the session can accept the next part.
 type ChunkedUploader interface {
 	InitiateChunked(ctx context.Context, session *ChunkSession) (*ChunkSession, error)
 	UploadChunk(ctx context.Context, session *ChunkSession, index int, payload io.Reader) (ChunkPart, error)
@@ -60,27 +108,115 @@ type PresignedPoster interface {
 	CreatePresignedPost(ctx context.Context, key string, metadata *Metadata) (*PresignedPost, error)
 }
 
+type CredentialScoper interface {
+	ScopedCredentials(ctx context.Context, prefix string, ttl time.Duration) (*ScopedCredentials, error)
+}
+
+// GarbageCollector is implemented by providers that can reclaim storage left behind by
+// abandoned chunked uploads (stale chunk directories, incomplete multipart uploads).
+type GarbageCollector interface {
+	CollectGarbage(ctx context.Context, olderThan time.Time) (int, error)
+}
+
+// StreamUploader is implemented by providers that can store content read from r without
+// buffering the whole payload in memory. size is the exact number of bytes r will yield.
+type StreamUploader interface {
+	UploadStream(ctx context.Context, path string, r io.Reader, size int64, opts ...UploadOption) (string, error)
+}
+
+// LocalFileUploader is implemented by providers that can store a file already on local disk
+// without copying it through memory, typically by renaming or hard-linking it.
+type LocalFileUploader interface {
+	UploadLocalFile(ctx context.Context, path, srcPath string, opts ...UploadOption) (string, error)
+}
+
+// FileStatter is implemented by providers that can describe an object without downloading it.
+// StatFile returns ErrImageNotFound for missing keys.
+type FileStatter interface {
+	StatFile(ctx context.Context, path string) (*ObjectInfo, error)
+}
+
+// ConditionalWriter is implemented by providers that honor WithIfNotExists atomically, so the
+// existence check and the write cannot race with another upload.
+type ConditionalWriter interface {
+	ConditionalWrites() bool
+}
+
 type ImageProcessor interface {
 	Generate(ctx context.Context, source []byte, size ThumbnailSize, contentType string) ([]byte, string, error)
 }
 
 type UploadCallback func(ctx context.Context, meta *FileMeta) error
 
-var _ Uploader = &Manager{}
+var (
+	_ Uploader = &Manager{}
+	_ Lister   = &Manager{}
+)
 
 type Manager struct {
-	logger           Logger
-	provider         Uploader
-	validator        *Validator
-	chunkStore       *ChunkSessionStore
-	chunkPartSize    int64
-	imageProcessor   ImageProcessor
-	callback         UploadCallback
-	callbackMode     CallbackMode
-	callbackExecutor CallbackExecutor
-	providerErr      error
-	validated        bool
-	validateCtx      context.Context
+	logger             Logger
+	backend            atomic.Pointer[providerState]
+	router             ProviderRouter
+	usageSource        UsageReporter
+	usageCache         *usageCache
+	validator          *Validator
+	chunkStore         *ChunkSessionStore
+	chunkPartSize      int64
+	imageProcessor     ImageProcessor
+	callback           UploadCallback
+	callbacks          []registeredCallback
+	callbackMode       CallbackMode
+	callbackExecutor   CallbackExecutor
+	validateCtx        context.Context
+	confirmationSecret []byte
+	storageClass       string
+	idempotencyStore   IdempotencyStore
+	idempotencyTTL     time.Duration
+	reservationStore   ReservationStore
+	reservationOnce    sync.Once
+	retentionStore     RetentionStore
+	retentionOnce      sync.Once
+	idempotency        idempotencyLocks
+	chunkLimits        *ChunkLimits
+	spoolThreshold     int64
+	buffers            *BufferPool
+	readOnly           atomic.Bool
+	bandwidthLimit     int64
+	cachePolicy        CachePolicy
+	nameStrategy       NameStrategy
+	transforms         []UploadTransform
+	keepOriginals      bool
+	collisionPolicy    CollisionPolicy
+	resultSink         ResultSink
+	eventHandlers      []EventHandler
+	slowThreshold      time.Duration
+	stats              operationStats
+	clock              func() time.Time
+	idGenerator        func() string
+	extractor          ContentExtractor
+	onExtracted        ExtractionCallback
+	completionThumbs   []ThumbnailSize
+	completionThumbMax int64
+	postProcessors     *PostProcessorRegistry
+	stagingPrefix      string
+	partialPolicy      PartialUploadPolicy
+	chunkMaxLifetime   time.Duration
+	rateLimiter        *rateLimiter
+	spamGuard          *spamGuard
+	archiveClass       string
+	restoreDays        int
+	restoreTier        RestoreTier
+	visibility         Visibility
+	presignWorkers     int
+	thumbnailKeys      ThumbnailKeyTemplate
+	derivativeProvider Uploader
+	derivativeOnce     sync.Once
+	derivativePattern  *regexp.Regexp
+	correlationKey     any
+	logRedaction       *LogRedaction
+	keyCodec           KeyCodec
+	imageFormats       []ImageFormat
+	presignPolicy      PresignPolicy
 }
 
 type Option func(m *Manager)
@@ -93,21 +229,15 @@ func WithLogger(l Logger) Option {
 
 func WithProvider(p Uploader) Option {
 	return func(m *Manager) {
-		m.provider = p
-		m.validated = false
-		m.providerErr = nil
+		state := &providerState{provider: p}
+		m.backend.Store(state)
 
 		ctx := m.validateCtx
 		if ctx == nil {
 			ctx = context.Background()
 		}
 
-		if err := m.validateProvider(ctx); err != nil {
-			m.providerErr = err
-			return
-		}
-
-		m.validated = true
+		state.validate(ctx)
 	}
 }
 
@@ -169,6 +299,29 @@ func WithCallbackExecutor(exec CallbackExecutor) Option {
 	}
 }
 
+func WithConfirmationSecret(secret []byte) Option {
+	return func(m *Manager) {
+		m.confirmationSecret = append([]byte(nil), secret...)
+	}
+}
+
+func WithDefaultStorageClass(class string) Option {
+	return func(m *Manager) {
+		m.storageClass = class
+	}
+}
+
+// WithIdempotencyStore sets the store backing idempotency keys and how long records are kept.
+// A ttl <= 0 falls back to DefaultIdempotencyTTL.
+func WithIdempotencyStore(store IdempotencyStore, ttl time.Duration) Option {
+	return func(m *Manager) {
+		if store != nil {
+			m.idempotencyStore = store
+		}
+		m.idempotencyTTL = ttl
+	}
+}
+
 func NewManager(opts ...Option) *Manager {
 	m := &Manager{
 		logger:           &DefaultLogger{},
@@ -179,22 +332,51 @@ func NewManager(opts ...Option) *Manager {
 		imageProcessor:   NewLocalImageProcessor(),
 		callbackMode:     CallbackModeBestEffort,
 		callbackExecutor: syncCallbackExecutor{},
+		presignPolicy:    DefaultPresignPolicy,
 	}
 
 	for _, opt := range opts {
 		opt(m)
 	}
+	m.bindChunkStoreClock()
 
 	return m
 }
 
 type FileMeta struct {
-	Content      []byte `json:"content"`
-	ContentType  string `json:"content_type"`
-	Name         string `json:"name"`
-	OriginalName string `json:"original_name"`
-	Size         int64  `json:"size"`
-	URL          string `json:"url"`
+	Content         []byte            `json:"content"`
+	ContentType     string            `json:"content_type"`
+	Name            string            `json:"name"`
+	OriginalName    string            `json:"original_name"`
+	Size            int64             `json:"size"`
+	URL             string            `json:"url"`
+	Attributes      map[string]string `json:"attributes,omitempty"`
+	Width           int               `json:"width,omitempty"`
+	Height          int               `json:"height,omitempty"`
+	Checksum        string            `json:"checksum,omitempty"`
+	StorageProvider string            `json:"storage_provider,omitempty"`
+	StorageClass    string            `json:"storage_class,omitempty"`
+	ProviderKey     string            `json:"provider_key,omitempty"`
+	UploadedAt      time.Time         `json:"uploaded_at"`
+	// Ref identifies the stored object without relying on the provider's URL format.
+	Ref *StoredObjectRef `json:"ref,omitempty"`
+	// PublicKey is the key to hand to clients when a KeyCodec is configured. It replaces Name in
+	// the JSON encoding, which then omits the fields embedding the storage key.
+	PublicKey string `json:"public_key,omitempty"`
+}
+
+// ObjectInfo describes a stored object returned by Lister implementations. Keys are relative to
+// the provider root and use forward slashes.
+type ObjectInfo struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	ModTime      time.Time `json:"mod_time"`
+	ETag         string    `json:"etag,omitempty"`
+	StorageClass string    `json:"storage_class,omitempty"`
+	ContentType  string    `json:"content_type,omitempty"`
+	CacheControl string    `json:"cache_control,omitempty"`
+	LegalHold    bool      `json:"legal_hold,omitempty"`
+	RetainUntil  time.Time `json:"retain_until,omitempty"`
 }
 
 type ImageMeta struct {
@@ -203,21 +385,80 @@ type ImageMeta struct {
 }
 
 type PresignedPost struct {
-	URL    string            `json:"url"`
-	Method string            `json:"method"`
-	Fields map[string]string `json:"fields"`
-	Expiry time.Time         `json:"expiry"`
+	URL         string             `json:"url"`
+	Method      string             `json:"method"`
+	Fields      map[string]string  `json:"fields"`
+	Expiry      time.Time          `json:"expiry"`
+	Credentials *ScopedCredentials `json:"credentials,omitempty"`
+}
+
+// ScopedCredentials are temporary credentials limited to writes under Prefix in Bucket, or, for
+// the credentials of a presigned post, to Key alone.
+type ScopedCredentials struct {
+	AccessKeyID     string    `json:"access_key_id"`
+	SecretAccessKey string    `json:"secret_access_key"`
+	SessionToken    string    `json:"session_token"`
+	Expiration      time.Time `json:"expiration"`
+	Bucket          string    `json:"bucket"`
+	Prefix          string    `json:"prefix"`
+	Key             string    `json:"key,omitempty"`
+	Region          string    `json:"region"`
 }
 
 type PresignedUploadResult struct {
-	Key          string
-	OriginalName string
-	Size         int64
-	ContentType  string
-	Metadata     map[string]string
+	Key               string
+	OriginalName      string
+	Size              int64
+	ContentType       string
+	Metadata          map[string]string
+	ConfirmationToken string
+	ReservationToken  string
 }
 
 func (m *Manager) InitiateChunked(ctx context.Context, key string, totalSize int64, opts ...UploadOption) (*ChunkSession, error) {
+	if err := m.ensureWritable(); err != nil {
+		return nil, err
+	}
+
+	if err := m.validateKey(key); err != nil {
+		return nil, err
+	}
+
+	probe := &Metadata{IdempotencyKey: IdempotencyKeyFromContext(ctx)}
+	for _, opt := range opts {
+		opt(probe)
+	}
+
+	if probe.IdempotencyKey == "" {
+		return m.initiateChunked(ctx, key, totalSize, opts...)
+	}
+
+	// Only a session that is still live is replayed; once it completes or expires the key
+	// starts a new session.
+	idemKey := "chunk:" + probe.IdempotencyKey
+	unlock := m.idempotency.lock(idemKey)
+	defer unlock()
+
+	record, err := m.lookupIdempotent(ctx, idemKey)
+	if err != nil {
+		return nil, err
+	}
+	if record != nil && record.SessionID != "" {
+		if session, ok := m.ensureChunkStore().Get(record.SessionID); ok && session.State != ChunkSessionStateAborted {
+			return session, nil
+		}
+	}
+
+	session, err := m.initiateChunked(ctx, key, totalSize, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	m.storeIdempotent(ctx, idemKey, &IdempotencyRecord{SessionID: session.ID})
+	return session, nil
+}
+
+func (m *Manager) initiateChunked(ctx context.Context, key string, totalSize int64, opts ...UploadOption) (*ChunkSession, error) {
 	if key == "" {
 		return nil, ErrInvalidPath
 	}
@@ -236,18 +477,38 @@ func (m *Manager) InitiateChunked(ctx context.Context, key string, totalSize int
 		return nil, err
 	}
 
-	chunkProvider, err := m.chunkedProvider()
+	meta := &Metadata{}
+	for _, opt := range opts {
+		opt(meta)
+	}
+
+	if meta.ReservationToken != "" {
+		if err := m.checkReservation(ctx, meta.ReservationToken, key, meta.ContentType, totalSize); err != nil {
+			return nil, err
+		}
+	}
+
+	chunkProvider, err := m.chunkedProvider(withRouteInfo(ctx, meta.ContentType, totalSize), key)
 	if err != nil {
 		return nil, err
 	}
 
-	meta := &Metadata{}
-	for _, opt := range opts {
-		opt(meta)
+	if err := validateChunkPlan(m.resolveChunkLimits(chunkProvider), totalSize, m.chunkPartSize); err != nil {
+		return nil, err
+	}
+
+	if err := m.checkRateLimit(ctx, totalSize); err != nil {
+		return nil, err
+	}
+
+	meta.Attributes = mergeAttributes(AttributesFromContext(ctx), meta.Attributes)
+	m.applyCachePolicy(meta)
+	if err := m.applyVisibility(ctx, meta); err != nil {
+		return nil, err
 	}
 
 	session := &ChunkSession{
-		ID:        uuid.NewString(),
+		ID:        m.newID(),
 		Key:       key,
 		TotalSize: totalSize,
 		PartSize:  m.chunkPartSize,
@@ -258,7 +519,10 @@ func (m *Manager) InitiateChunked(ctx context.Context, key string, totalSize int
 		session.ProviderData = make(map[string]any)
 	}
 
-	if _, err := chunkProvider.InitiateChunked(ctx, session); err != nil {
+	_, err = observeCall(ctx, m, "initiate_chunked", key, func() (*ChunkSession, error) {
+		return chunkProvider.InitiateChunked(ctx, session)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -267,10 +531,25 @@ func (m *Manager) InitiateChunked(ctx context.Context, key string, totalSize int
 		return nil, err
 	}
 
+	if meta.AbortOnCancel {
+		m.AbortChunkedOnCancel(ctx, stored.ID)
+	}
+
+	m.emitEvent(ctx, &ChunkSessionStarted{
+		SessionID: stored.ID,
+		Key:       stored.Key,
+		TotalSize: stored.TotalSize,
+		PartSize:  stored.PartSize,
+		ExpiresAt: stored.ExpiresAt,
+	})
 	return stored, nil
 }
 
 func (m *Manager) UploadChunk(ctx context.Context, sessionID string, index int, payload io.Reader) error {
+	if err := m.ensureWritable(); err != nil {
+		return err
+	}
+
 	if index < 0 {
 		return ErrChunkPartOutOfRange
 	}
@@ -288,56 +567,133 @@ func (m *Manager) UploadChunk(ctx context.Context, sessionID string, index int,
 		return err
 	}
 
-	chunkProvider, err := m.chunkedProvider()
+	session, err := m.getChunkSession(sessionID)
 	if err != nil {
 		return err
 	}
 
-	session, err := m.getChunkSession(sessionID)
+	if session.State != ChunkSessionStateActive {
+		return ErrChunkSessionClosed
+	}
+
+	chunkProvider, err := m.chunkedProvider(sessionRouteContext(ctx, session), session.Key)
 	if err != nil {
 		return err
 	}
 
-	part, err := chunkProvider.UploadChunk(ctx, session, index, payload)
+	limits := m.resolveChunkLimits(chunkProvider)
+	if err := validateChunkPart(limits, session, index, 0); err != nil {
+		return err
+	}
+
+	part, err := observeCall(ctx, m, "upload_chunk", session.Key, func() (ChunkPart, error) {
+		return chunkProvider.UploadChunk(ctx, session, index, m.throttle(ctx, payload))
+	})
 	if err != nil {
 		return err
 	}
+	m.recordTransfer("upload_chunk", part.Size, 0)
 
 	if part.Index != index {
 		part.Index = index
 	}
 
-	_, err = m.ensureChunkStore().AddPart(sessionID, part)
-	return err
+	if err := validateChunkPart(limits, session, index, part.Size); err != nil {
+		return err
+	}
+
+	updated, err := m.ensureChunkStore().AddPart(sessionID, part)
+	if err != nil {
+		return err
+	}
+
+	var uploadedBytes int64
+	for _, uploaded := range updated.UploadedParts {
+		uploadedBytes += uploaded.Size
+	}
+
+	m.emitEvent(ctx, &ChunkCompleted{
+		SessionID:     sessionID,
+		Key:           session.Key,
+		Index:         index,
+		Size:          part.Size,
+		Uploaded:      len(updated.UploadedParts),
+		UploadedBytes: uploadedBytes,
+		TotalSize:     session.TotalSize,
+	})
+	return nil
 }
 
 func (m *Manager) CompleteChunked(ctx context.Context, sessionID string) (*FileMeta, error) {
+	if err := m.ensureWritable(); err != nil {
+		return nil, err
+	}
+
 	if err := m.ensureProvider(ctx); err != nil {
 		return nil, err
 	}
 
-	chunkProvider, err := m.chunkedProvider()
+	session, err := m.getChunkSession(sessionID)
 	if err != nil {
 		return nil, err
 	}
 
-	session, err := m.getChunkSession(sessionID)
+	chunkProvider, err := m.chunkedProvider(sessionRouteContext(ctx, session), session.Key)
 	if err != nil {
 		return nil, err
 	}
 
-	meta, err := chunkProvider.CompleteChunked(ctx, session)
+	if err := validateChunkParts(m.resolveChunkLimits(chunkProvider), session); err != nil {
+		return nil, err
+	}
+
+	var reservation string
+	if session.Metadata != nil && session.Metadata.ReservationToken != "" {
+		reservation = session.Metadata.ReservationToken
+		if err := m.checkReservation(ctx, reservation, session.Key, session.Metadata.ContentType, session.TotalSize); err != nil {
+			return nil, err
+		}
+	}
+
+	store := m.ensureChunkStore()
+	session, err = store.Transition(sessionID, session.Version, ChunkSessionStateCompleting)
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := observeCall(ctx, m, "complete_chunked", session.Key, func() (*FileMeta, error) {
+		return chunkProvider.CompleteChunked(ctx, session)
+	})
 	if err != nil {
+		if _, rollbackErr := store.Transition(sessionID, session.Version, ChunkSessionStateActive); rollbackErr != nil {
+			m.log(ctx).Error("rollback chunk session failed", rollbackErr, "session", sessionID)
+		}
 		return nil, err
 	}
 
-	if _, err := m.ensureChunkStore().MarkCompleted(sessionID); err != nil {
+	var sessionAttrs map[string]string
+	if session.Metadata != nil {
+		sessionAttrs = session.Metadata.Attributes
+	}
+	m.attachAttributes(ctx, meta, sessionAttrs)
+
+	storageClass := m.storageClass
+	if session.Metadata != nil && session.Metadata.StorageClass != "" {
+		storageClass = session.Metadata.StorageClass
+	}
+	m.enrichFileMeta(ctx, meta, nil, storageClass)
+
+	if _, err := store.Transition(sessionID, session.Version, ChunkSessionStateCompleted); err != nil {
 		return nil, err
 	}
 
-	m.ensureChunkStore().Delete(sessionID)
+	store.Delete(sessionID)
+	m.usageCache.forget(session.Key)
+	m.consumeReservation(ctx, reservation)
+	m.emitEvent(ctx, &ChunkSessionCompleted{SessionID: sessionID, Key: session.Key, Size: meta.Size})
+	m.warmCompletionThumbnails(ctx, meta)
 
-	if err := m.maybeRunCallback(ctx, meta); err != nil {
+	if err := m.maybeRunCallback(ctx, CallbackOperationChunked, meta); err != nil {
 		return nil, err
 	}
 
@@ -345,12 +701,11 @@ func (m *Manager) CompleteChunked(ctx context.Context, sessionID string) (*FileM
 }
 
 func (m *Manager) AbortChunked(ctx context.Context, sessionID string) error {
-	if err := m.ensureProvider(ctx); err != nil {
+	if err := m.ensureWritable(); err != nil {
 		return err
 	}
 
-	chunkProvider, err := m.chunkedProvider()
-	if err != nil {
+	if err := m.ensureProvider(ctx); err != nil {
 		return err
 	}
 
@@ -359,29 +714,34 @@ func (m *Manager) AbortChunked(ctx context.Context, sessionID string) error {
 		return err
 	}
 
-	if err := chunkProvider.AbortChunked(ctx, session); err != nil {
+	chunkProvider, err := m.chunkedProvider(sessionRouteContext(ctx, session), session.Key)
+	if err != nil {
 		return err
 	}
 
-	if _, err := m.ensureChunkStore().MarkAborted(sessionID); err != nil {
+	// Claim the session first so an in-flight completion or upload cannot race the abort. The
+	// aborted session is kept until it expires so ListChunkSessions can report it.
+	if _, err := m.ensureChunkStore().Transition(sessionID, session.Version, ChunkSessionStateAborted); err != nil {
 		return err
 	}
 
-	m.ensureChunkStore().Delete(sessionID)
-	return nil
+	err = m.observeErr(ctx, "abort_chunked", session.Key, func() error {
+		return chunkProvider.AbortChunked(ctx, session)
+	})
+	m.emitEvent(ctx, &ChunkSessionAborted{SessionID: sessionID, Key: session.Key})
+	return err
 }
 
 func (m *Manager) CreatePresignedPost(ctx context.Context, key string, opts ...UploadOption) (*PresignedPost, error) {
-	if err := validateObjectKey(key); err != nil {
+	if err := m.ensureWritable(); err != nil {
 		return nil, err
 	}
 
-	if err := m.ensureProvider(ctx); err != nil {
+	if err := m.validateKey(key); err != nil {
 		return nil, err
 	}
 
-	presigner, err := m.presignedProvider()
-	if err != nil {
+	if err := m.ensureProvider(ctx); err != nil {
 		return nil, err
 	}
 
@@ -389,6 +749,25 @@ func (m *Manager) CreatePresignedPost(ctx context.Context, key string, opts ...U
 	for _, opt := range opts {
 		opt(meta)
 	}
+	m.applyCachePolicy(meta)
+	if err := m.applyVisibility(ctx, meta); err != nil {
+		return nil, err
+	}
+
+	if meta.ReservationToken != "" {
+		if err := m.checkReservation(ctx, meta.ReservationToken, key, meta.ContentType, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validatePostURLs(meta); err != nil {
+		return nil, err
+	}
+
+	presigner, err := m.presignedProvider(withRouteInfo(ctx, meta.ContentType, 0), key)
+	if err != nil {
+		return nil, err
+	}
 
 	if meta.ContentType == "" {
 		return nil, gerrors.NewValidation("presigned post validation failed",
@@ -409,26 +788,33 @@ func (m *Manager) CreatePresignedPost(ctx context.Context, key string, opts ...U
 		)
 	}
 
-	ttl := meta.TTL
-	if ttl <= 0 {
-		ttl = DefaultPresignedPostTTL
+	ttl, err := m.presignPolicy.lifetime(meta.TTL, m.presignPolicy.PostTTL, "presigned post validation failed")
+	if err != nil {
+		return nil, err
 	}
 
-	if ttl > MaxPresignedPostTTL {
-		return nil, gerrors.NewValidation("presigned post validation failed",
-			gerrors.FieldError{
-				Field:   "ttl",
-				Message: "requested ttl exceeds maximum",
-				Value:   ttl,
-			},
-		)
+	// The size is only known once storage receives the post, so it counts as an upload alone.
+	if err := m.checkRateLimit(ctx, 0); err != nil {
+		return nil, err
 	}
 
 	meta.TTL = ttl
-	return presigner.CreatePresignedPost(ctx, key, meta)
+	post, err := observeCall(ctx, m, "presigned_post", key, func() (*PresignedPost, error) {
+		return presigner.CreatePresignedPost(ctx, key, meta)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	m.emitEvent(ctx, &PresignIssued{Key: key, Kind: PresignKindPost, ExpiresAt: post.Expiry})
+	return post, nil
 }
 
 func (m *Manager) ConfirmPresignedUpload(ctx context.Context, result *PresignedUploadResult) (*FileMeta, error) {
+	if err := m.ensureWritable(); err != nil {
+		return nil, err
+	}
+
 	if result == nil {
 		return nil, gerrors.NewValidation("presigned upload confirmation failed",
 			gerrors.FieldError{
@@ -438,10 +824,20 @@ func (m *Manager) ConfirmPresignedUpload(ctx context.Context, result *PresignedU
 		)
 	}
 
-	if err := validateObjectKey(result.Key); err != nil {
+	if err := m.validateKey(result.Key); err != nil {
+		return nil, err
+	}
+
+	if err := m.verifyConfirmationToken(result); err != nil {
 		return nil, err
 	}
 
+	if result.ReservationToken != "" {
+		if err := m.checkReservation(ctx, result.ReservationToken, result.Key, result.ContentType, result.Size); err != nil {
+			return nil, err
+		}
+	}
+
 	if result.ContentType != "" && !m.validator.IsAllowedMimeType(result.ContentType) {
 		return nil, gerrors.NewValidation("presigned upload confirmation failed",
 			gerrors.FieldError{
@@ -466,7 +862,9 @@ func (m *Manager) ConfirmPresignedUpload(ctx context.Context, result *PresignedU
 		return nil, err
 	}
 
-	url, err := m.provider.GetPresignedURL(ctx, result.Key, DefaultPresignedURLTTL)
+	url, err := observeCall(ctx, m, "presign_url", result.Key, func() (string, error) {
+		return m.providerFor(withRouteInfo(ctx, result.ContentType, result.Size), result.Key).GetPresignedURL(ctx, result.Key, m.presignPolicy.URLTTL+m.presignPolicy.ClockSkew)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -478,19 +876,123 @@ func (m *Manager) ConfirmPresignedUpload(ctx context.Context, result *PresignedU
 		ContentType:  result.ContentType,
 		URL:          url,
 	}
+	m.attachAttributes(ctx, meta, result.Metadata)
+	m.enrichFileMeta(ctx, meta, nil, m.storageClass)
+	m.usageCache.forget(meta.Name)
+	m.consumeReservation(ctx, result.ReservationToken)
+	m.warmCompletionThumbnails(ctx, meta)
 
-	if err := m.maybeRunCallback(ctx, meta); err != nil {
+	if err := m.maybeRunCallback(ctx, CallbackOperationPresigned, meta); err != nil {
 		return nil, err
 	}
 
 	return meta, nil
 }
 
+func (m *Manager) IssueScopedCredentials(ctx context.Context, prefix string, ttl time.Duration) (*ScopedCredentials, error) {
+	if err := m.ensureWritable(); err != nil {
+		return nil, err
+	}
+
+	if err := m.ensureProvider(ctx); err != nil {
+		return nil, err
+	}
+
+	scoper, ok := m.providerFor(ctx, prefix).(CredentialScoper)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+
+	return observeCall(ctx, m, "scoped_credentials", prefix, func() (*ScopedCredentials, error) {
+		return scoper.ScopedCredentials(ctx, prefix, ttl)
+	})
+}
+
+// HandleFile validates and stores file under path. When ctx carries an idempotency key (see
+// ContextWithIdempotencyKey) a retried call returns the original FileMeta, without Content.
 func (m *Manager) HandleFile(ctx context.Context, file *multipart.FileHeader, path string) (*FileMeta, error) {
-	return m.handleFile(ctx, file, path, true)
+	if err := m.ensureWritable(); err != nil {
+		return nil, err
+	}
+
+	key := IdempotencyKeyFromContext(ctx)
+	if key == "" {
+		return m.handleFile(ctx, file, path, true, true)
+	}
+
+	idemKey := "file:" + key
+	unlock := m.idempotency.lock(idemKey)
+	defer unlock()
+
+	record, err := m.lookupIdempotent(ctx, idemKey)
+	if err != nil {
+		return nil, err
+	}
+	if record != nil && record.FileMeta != nil {
+		return idempotentFileMeta(record.FileMeta), nil
+	}
+
+	meta, err := m.handleFile(ctx, file, path, true, true)
+	if err != nil {
+		return nil, err
+	}
+
+	m.storeIdempotent(ctx, idemKey, &IdempotencyRecord{FileMeta: idempotentFileMeta(meta)})
+	return meta, nil
+}
+
+// openUpload opens the multipart part, reporting malformed or missing parts as a bad request.
+func openUpload(file *multipart.FileHeader) (multipart.File, error) {
+	src, err := file.Open()
+	if err == nil && src == nil {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return nil, gerrors.Wrap(err, gerrors.CategoryBadInput, "failed to open uploaded file").
+			WithCode(400).
+			WithTextCode("FILE_OPEN_FAILED").
+			WithMetadata(map[string]any{
+				"filename": file.Filename,
+			})
+	}
+	return src, nil
+}
+
+// readUpload reads at most one byte past the validator's max size, so a part whose declared
+// size understates its content is rejected without buffering the rest of it.
+func (m *Manager) readUpload(file *multipart.FileHeader, src io.Reader) ([]byte, error) {
+	limit := m.validator.MaxFileSize()
+	content, err := m.buffers.ReadAll(io.LimitReader(src, limit+1))
+	if err != nil {
+		return nil, gerrors.Wrap(err, gerrors.CategoryBadInput, "failed to read uploaded file").
+			WithCode(400).
+			WithTextCode("FILE_READ_FAILED").
+			WithMetadata(map[string]any{
+				"filename": file.Filename,
+			})
+	}
+
+	if int64(len(content)) > limit {
+		return nil, gerrors.NewValidation("file validation failed",
+			gerrors.FieldError{
+				Field:   "file_size",
+				Message: fmt.Sprintf("file too large, max: %d bytes", limit),
+				Value:   file.Size,
+			},
+		).WithCode(400).WithTextCode("FILE_TOO_LARGE").
+			WithMetadata(map[string]any{
+				"filename":      file.Filename,
+				"declared_size": file.Size,
+				"max_size":      limit,
+			})
+	}
+
+	return content, nil
 }
 
-func (m *Manager) handleFile(ctx context.Context, file *multipart.FileHeader, path string, triggerCallback bool) (*FileMeta, error) {
+// handleFile stores an uploaded file. allowSpool lets large files stream from the multipart
+// spool instead of being buffered; callers that need FileMeta.Content must pass false.
+func (m *Manager) handleFile(ctx context.Context, file *multipart.FileHeader, path string, triggerCallback, allowSpool bool) (*FileMeta, error) {
 	if file == nil {
 		return nil, gerrors.New("file not found", gerrors.CategoryNotFound).
 			WithCode(404).
@@ -500,11 +1002,11 @@ func (m *Manager) handleFile(ctx context.Context, file *multipart.FileHeader, pa
 			})
 	}
 
-	if err := m.validator.ValidateFile(file); err != nil {
+	if err := m.checkRateLimit(ctx, file.Size); err != nil {
 		return nil, err
 	}
 
-	fileBuff, err := file.Open()
+	fileBuff, err := openUpload(file)
 	if err != nil {
 		return nil, err
 	}
@@ -512,51 +1014,110 @@ func (m *Manager) handleFile(ctx context.Context, file *multipart.FileHeader, pa
 		_ = fb.Close()
 	}(fileBuff)
 
-	var url string
+	contentType := resolveContentType(ctx, file, fileBuff)
+	if err := m.validator.validateFile(file, contentType); err != nil {
+		return nil, m.correlateError(ctx, err)
+	}
+
 	var name string
 	var content []byte
-	contentType := file.Header["Content-Type"][0]
 
-	if content, err = io.ReadAll(fileBuff); err != nil {
+	if allowSpool && len(m.transforms) == 0 {
+		if m.shouldSpool(withRouteInfo(ctx, contentType, file.Size), path, file.Size) {
+			return m.handleSpooledFile(ctx, file, fileBuff, path, contentType, triggerCallback)
+		}
+	}
+
+	if content, err = m.readUpload(file, fileBuff); err != nil {
 		return nil, err
 	}
 
-	if err := m.validator.ValidateFileContent(content); err != nil {
+	if err := m.validator.validateContent(contentType, int64(len(content)), content, bytes.NewReader(content)); err != nil {
+		return nil, m.correlateError(ctx, err)
+	}
+
+	if err := m.screenUpload(ctx, file.Filename, contentType, int64(len(content)), func() string { return checksumSHA256(content) }); err != nil {
 		return nil, err
 	}
 
-	if name, err = m.validator.RandomName(file, path); err != nil {
+	stored, original, originalType := file, content, contentType
+	if len(m.transforms) > 0 {
+		if stored, content, contentType, err = m.applyTransforms(ctx, file, content, contentType); err != nil {
+			return nil, err
+		}
+	}
+
+	var checksum string
+	if m.contentAddressed() {
+		checksum = checksumSHA256(content)
+	}
+
+	if name, err = m.objectName(stored, path, checksum); err != nil {
 		return nil, err
 	}
 
-	if url, err = m.UploadFile(ctx, name, content, WithContentType(contentType)); err != nil {
+	if err := m.validator.ValidateKey(name); err != nil {
+		return nil, m.correlateError(ctx, err)
+	}
+
+	ctx = withRouteInfo(ctx, contentType, int64(len(content)))
+	started := time.Now()
+	store := func(key string, opts ...UploadOption) (string, error) {
+		return m.putFile(ctx, key, content, opts...)
+	}
+	result, err := m.storeGenerated(ctx, name, store, WithContentType(contentType), WithStorageClass(m.storageClass),
+		WithCacheControl(m.storedFileCacheControl(contentType)))
+	if err != nil {
 		return nil, err
 	}
+	name = result.Key
+
+	var originalKey string
+	if m.keepOriginals && contentType != originalType {
+		if originalKey, err = m.storeTransformedOriginal(ctx, file, name, original, originalType); err != nil {
+			m.rollbackPartial(ctx, name)
+			return nil, err
+		}
+	}
 
 	meta := &FileMeta{
 		Content:      content,
 		ContentType:  contentType,
 		Name:         name,
 		OriginalName: file.Filename,
-		Size:         file.Size,
-		URL:          url,
+		Size:         stored.Size,
+		URL:          result.URL,
 	}
+	m.attachAttributes(ctx, meta)
+	if originalKey != "" {
+		meta.Attributes = mergeAttributes(meta.Attributes, map[string]string{"original_key": originalKey})
+	}
+	m.enrichFileMeta(ctx, meta, content, m.storageClass)
 
 	if triggerCallback {
-		if err := m.maybeRunCallback(ctx, meta); err != nil {
+		if err := m.runPostProcessors(ctx, meta); err != nil {
+			return nil, err
+		}
+		if err := m.maybeRunCallback(ctx, CallbackOperationUpload, meta); err != nil {
 			return nil, err
 		}
 	}
 
+	result.Size, result.ContentType = meta.Size, contentType
+	m.recordUpload(ctx, result, started)
 	return meta, nil
 }
 
 func (m *Manager) HandleImageWithThumbnails(ctx context.Context, file *multipart.FileHeader, path string, sizes []ThumbnailSize) (*ImageMeta, error) {
+	if err := m.ensureWritable(); err != nil {
+		return nil, err
+	}
+
 	if err := ValidateThumbnailSizes(sizes); err != nil {
 		return nil, err
 	}
 
-	baseMeta, err := m.handleFile(ctx, file, path, false)
+	baseMeta, err := m.handleFile(ctx, file, path, false, false)
 	if err != nil {
 		return nil, err
 	}
@@ -567,30 +1128,40 @@ func (m *Manager) HandleImageWithThumbnails(ctx context.Context, file *multipart
 
 	processor := m.ensureImageProcessor()
 	thumbnails := make(map[string]*FileMeta, len(sizes))
+	stored := []string{baseMeta.Name, baseMeta.Attributes["original_key"]}
+	fail := func(err error) (*ImageMeta, error) {
+		m.rollbackPartial(ctx, stored...)
+		return nil, err
+	}
 
 	for _, size := range sizes {
 		if err := ctx.Err(); err != nil {
-			return nil, err
+			return fail(err)
 		}
 
-		thumbBytes, thumbContentType, err := processor.Generate(ctx, baseMeta.Content, size, baseMeta.ContentType)
+		thumbBytes, thumbContentType, err := generateThumbnail(ctx, processor, baseMeta.Name, baseMeta.Content, size, baseMeta.ContentType)
 		if err != nil {
-			return nil, err
+			return fail(err)
 		}
 
-		thumbName := buildThumbnailKey(baseMeta.Name, size.Name)
-		thumbURL, err := m.UploadFile(ctx, thumbName, thumbBytes, WithContentType(thumbContentType))
+		thumbName := m.ThumbnailKey(baseMeta.Name, size.Name)
+		thumbURL, err := m.putFile(ctx, thumbName, thumbBytes, WithContentType(thumbContentType), WithStorageClass(m.storageClass))
 		if err != nil {
-			return nil, err
+			return fail(err)
 		}
+		stored = append(stored, thumbName)
 
-		thumbnails[size.Name] = &FileMeta{
+		thumbMeta := &FileMeta{
 			ContentType:  thumbContentType,
 			Name:         thumbName,
 			OriginalName: fmt.Sprintf("%s__%s", baseMeta.OriginalName, size.Name),
 			Size:         int64(len(thumbBytes)),
 			URL:          thumbURL,
+			Attributes:   mergeAttributes(baseMeta.Attributes),
 		}
+		m.enrichFileMeta(ctx, thumbMeta, thumbBytes, m.storageClass)
+		thumbnails[size.Name] = thumbMeta
+		m.emitThumbnailGenerated(ctx, baseMeta.Name, thumbName, size.Name, thumbContentType, thumbBytes)
 	}
 
 	imageMeta := &ImageMeta{
@@ -598,7 +1169,7 @@ func (m *Manager) HandleImageWithThumbnails(ctx context.Context, file *multipart
 		Thumbnails: thumbnails,
 	}
 
-	if err := m.maybeRunCallback(ctx, baseMeta); err != nil {
+	if err := m.maybeRunCallback(ctx, CallbackOperationImage, baseMeta); err != nil {
 		thumbKeys := make([]string, 0, len(thumbnails))
 		for _, thumb := range thumbnails {
 			if thumb != nil {
@@ -613,11 +1184,44 @@ func (m *Manager) HandleImageWithThumbnails(ctx context.Context, file *multipart
 }
 
 func (m *Manager) UploadFile(ctx context.Context, path string, content []byte, opts ...UploadOption) (string, error) {
-	if err := m.ensureProvider(ctx); err != nil {
+	result, err := m.UploadFileResult(ctx, path, content, opts...)
+	if err != nil {
+		return "", err
+	}
+	return result.URL, nil
+}
+
+// putFile writes content to path, replacing any existing object, after applying the cache
+// policy and bandwidth limit.
+func (m *Manager) putFile(ctx context.Context, path string, content []byte, opts ...UploadOption) (string, error) {
+	md := &Metadata{}
+	for _, opt := range opts {
+		opt(md)
+	}
+	if md.CacheControl == "" {
+		if value := m.cacheControlFor(md.ContentType); value != "" {
+			opts = append(opts, WithCacheControl(value))
+		}
+	}
+	opts, err := m.visibilityOptions(ctx, md, opts)
+	if err != nil {
 		return "", err
 	}
 
-	return m.provider.UploadFile(ctx, path, content, opts...)
+	ctx = withRouteInfo(ctx, md.ContentType, int64(len(content)))
+	provider := m.providerFor(ctx, path)
+
+	url, err := observeCall(ctx, m, "upload", path, func() (string, error) {
+		if url, throttled, err := m.uploadThrottled(ctx, provider, path, content, opts...); throttled {
+			return url, err
+		}
+		return provider.UploadFile(ctx, path, content, opts...)
+	})
+	if err == nil {
+		recordWrite(ctx, path)
+		m.recordTransfer("upload", int64(len(content)), 0)
+	}
+	return url, err
 }
 
 func (m *Manager) GetFile(ctx context.Context, path string) ([]byte, error) {
@@ -625,15 +1229,18 @@ func (m *Manager) GetFile(ctx context.Context, path string) ([]byte, error) {
 		return nil, err
 	}
 
-	return m.provider.GetFile(ctx, path)
+	content, err := observeCall(ctx, m, "get", path, func() ([]byte, error) {
+		return m.providerFor(ctx, path).GetFile(ctx, path)
+	})
+	if err == nil {
+		m.recordTransfer("get", 0, int64(len(content)))
+	}
+	return content, err
 }
 
 func (m *Manager) DeleteFile(ctx context.Context, path string) error {
-	if err := m.ensureProvider(ctx); err != nil {
-		return err
-	}
-
-	return m.provider.DeleteFile(ctx, path)
+	_, err := m.DeleteFileResult(ctx, path)
+	return err
 }
 
 func (m *Manager) GetPresignedURL(ctx context.Context, path string, expires time.Duration) (string, error) {
@@ -641,62 +1248,83 @@ func (m *Manager) GetPresignedURL(ctx context.Context, path string, expires time
 		return "", err
 	}
 
-	return m.provider.GetPresignedURL(ctx, path, expires)
+	return m.presignURL(ctx, m.providerFor(ctx, path), path, expires)
 }
 
-func (m *Manager) ensureProvider(ctx context.Context) error {
-	if m.provider == nil {
-		return ErrProviderNotConfigured
+// presignURL presigns path on provider, which the caller resolved for its routing context.
+func (m *Manager) presignURL(ctx context.Context, provider Uploader, path string, expires time.Duration) (string, error) {
+	expires, err := m.presignPolicy.lifetime(expires, m.presignPolicy.URLTTL, "presigned url validation failed")
+	if err != nil {
+		return "", err
 	}
 
-	if m.providerErr != nil {
-		return m.providerErr
+	url, err := observeCall(ctx, m, "presign_url", path, func() (string, error) {
+		return provider.GetPresignedURL(ctx, path, expires)
+	})
+	if err != nil {
+		return "", err
 	}
 
-	if m.validated {
-		return nil
+	m.emitEvent(ctx, &PresignIssued{Key: path, Kind: PresignKindURL, ExpiresAt: m.now().Add(expires)})
+	return url, nil
+}
+
+func (m *Manager) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	if err := m.ensureProvider(ctx); err != nil {
+		return nil, err
 	}
 
-	if err := m.validateProvider(ctx); err != nil {
-		m.providerErr = err
-		return err
+	lister, ok := m.currentProvider().(Lister)
+	if !ok {
+		return nil, ErrNotImplemented
 	}
 
-	m.validated = true
-	return nil
+	return observeCall(ctx, m, "list", prefix, func() ([]ObjectInfo, error) {
+		return lister.List(ctx, prefix)
+	})
 }
 
-func (m *Manager) validateProvider(ctx context.Context) error {
-	if ctx == nil {
-		ctx = context.Background()
+// CollectGarbage removes incomplete chunked uploads started before olderThan and returns how
+// many were reclaimed.
+func (m *Manager) CollectGarbage(ctx context.Context, olderThan time.Time) (int, error) {
+	if err := m.ensureWritable(); err != nil {
+		return 0, err
+	}
+
+	if err := m.ensureProvider(ctx); err != nil {
+		return 0, err
 	}
 
-	validator, ok := m.provider.(ProviderValidator)
+	collector, ok := m.currentProvider().(GarbageCollector)
 	if !ok {
-		return nil
+		return 0, ErrNotImplemented
 	}
 
-	return validator.Validate(ctx)
+	return observeCall(ctx, m, "collect_garbage", "", func() (int, error) {
+		return collector.CollectGarbage(ctx, olderThan)
+	})
 }
 
-func (m *Manager) ValidateProvider(ctx context.Context) error {
-	if m.provider == nil {
+func (m *Manager) ensureProvider(ctx context.Context) error {
+	state := m.backend.Load()
+	if state == nil || state.provider == nil {
 		return ErrProviderNotConfigured
 	}
 
-	if err := m.validateProvider(ctx); err != nil {
-		m.providerErr = err
-		m.validated = false
-		return err
+	return state.ensure(ctx)
+}
+
+func (m *Manager) ValidateProvider(ctx context.Context) error {
+	state := m.backend.Load()
+	if state == nil || state.provider == nil {
+		return ErrProviderNotConfigured
 	}
 
-	m.providerErr = nil
-	m.validated = true
-	return nil
+	return state.validate(ctx)
 }
 
-func (m *Manager) chunkedProvider() (ChunkedUploader, error) {
-	provider, ok := m.provider.(ChunkedUploader)
+func (m *Manager) chunkedProvider(ctx context.Context, key string) (ChunkedUploader, error) {
+	provider, ok := m.providerFor(ctx, key).(ChunkedUploader)
 	if !ok {
 		return nil, ErrNotImplemented
 	}
@@ -706,6 +1334,7 @@ func (m *Manager) chunkedProvider() (ChunkedUploader, error) {
 func (m *Manager) ensureChunkStore() *ChunkSessionStore {
 	if m.chunkStore == nil {
 		m.chunkStore = NewChunkSessionStore(DefaultChunkSessionTTL)
+		m.bindChunkStoreClock()
 	}
 	return m.chunkStore
 }
@@ -723,8 +1352,8 @@ func (m *Manager) getChunkSession(id string) (*ChunkSession, error) {
 	return session, nil
 }
 
-func (m *Manager) presignedProvider() (PresignedPoster, error) {
-	if presigner, ok := m.provider.(PresignedPoster); ok {
+func (m *Manager) presignedProvider(ctx context.Context, key string) (PresignedPoster, error) {
+	if presigner, ok := m.providerFor(ctx, key).(PresignedPoster); ok {
 		return presigner, nil
 	}
 	return nil, ErrNotImplemented
@@ -746,6 +1375,15 @@ func validateObjectKey(key string) error {
 	return nil
 }
 
+// generateThumbnail renders size from the stored original, preferring SourceImageProcessor so
+// external services can fetch the original themselves.
+func generateThumbnail(ctx context.Context, processor ImageProcessor, key string, source []byte, size ThumbnailSize, contentType string) ([]byte, string, error) {
+	if remote, ok := processor.(SourceImageProcessor); ok {
+		return remote.GenerateFromSource(ctx, key, size, contentType)
+	}
+	return processor.Generate(ctx, source, size, contentType)
+}
+
 func (m *Manager) ensureImageProcessor() ImageProcessor {
 	if m.imageProcessor == nil {
 		m.imageProcessor = NewLocalImageProcessor()
@@ -753,15 +1391,6 @@ func (m *Manager) ensureImageProcessor() ImageProcessor {
 	return m.imageProcessor
 }
 
-func buildThumbnailKey(name, variant string) string {
-	ext := path.Ext(name)
-	base := strings.TrimSuffix(name, ext)
-	if base == "" {
-		base = name
-	}
-	return fmt.Sprintf("%s__%s%s", base, variant, ext)
-}
-
 func (m *Manager) ensureCallbackExecutor() CallbackExecutor {
 	if m.callbackExecutor == nil {
 		m.callbackExecutor = syncCallbackExecutor{}
@@ -769,43 +1398,30 @@ func (m *Manager) ensureCallbackExecutor() CallbackExecutor {
 	return m.callbackExecutor
 }
 
-func (m *Manager) maybeRunCallback(ctx context.Context, meta *FileMeta) error {
-	if m.callback == nil || meta == nil {
-		return nil
-	}
-
-	exec := m.ensureCallbackExecutor()
-	if m.callbackMode == CallbackModeStrict {
-		if _, ok := exec.(*AsyncCallbackExecutor); ok {
-			m.logger.Info("async callback executor cannot enforce strict mode; treating as best effort")
-		}
-	}
-
-	start := time.Now()
-	err := exec.Execute(ctx, m.callback, meta)
-	if err != nil {
-		m.logger.Error("upload callback failed", err, "key", meta.Name)
-		if m.callbackMode == CallbackModeStrict {
-			m.cleanupFiles(ctx, meta.Name)
-			return fmt.Errorf("upload callback failed: %w", err)
-		}
-		return nil
+// maybeRunCallback runs the post-upload hooks for a stored file: the upload callbacks and, once
+// it succeeds, content extraction.
+func (m *Manager) maybeRunCallback(ctx context.Context, op CallbackOperation, meta *FileMeta) error {
+	if err := m.runUploadCallback(ctx, op, meta); err != nil {
+		return err
 	}
 
-	m.logger.Info("upload callback completed", "key", meta.Name, "duration", time.Since(start))
+	m.maybeExtractContent(ctx, meta)
 	return nil
 }
 
 func (m *Manager) cleanupFiles(ctx context.Context, keys ...string) {
-	if m.provider == nil {
+	if m.currentProvider() == nil {
 		return
 	}
 	for _, key := range keys {
 		if key == "" {
 			continue
 		}
-		if err := m.provider.DeleteFile(ctx, key); err != nil {
-			m.logger.Error("cleanup file failed", err, "key", key)
+		err := m.observeErr(ctx, "delete", key, func() error {
+			return m.providerFor(ctx, key).DeleteFile(ctx, key)
+		})
+		if err != nil {
+			m.log(ctx).Error("cleanup file failed", err, "key", key)
 		}
 	}
 }
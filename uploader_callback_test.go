@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"sort"
+	"strings"
 	"testing"
 	"time"
 )
@@ -168,7 +169,7 @@ func (p *memoryProvider) GetFile(ctx context.Context, path string) ([]byte, erro
 	if data, ok := p.files[path]; ok {
 		return append([]byte(nil), data...), nil
 	}
-	return nil, errors.New("not found")
+	return nil, ErrImageNotFound
 }
 
 func (p *memoryProvider) DeleteFile(ctx context.Context, path string) error {
@@ -225,3 +226,88 @@ func (p *memoryProvider) AbortChunked(ctx context.Context, session *ChunkSession
 	delete(p.sessions, session.ID)
 	return nil
 }
+
+type callbackCtxKey struct{}
+
+func TestAsyncCallbackExecutorDetachesContext(t *testing.T) {
+	parent, cancel := context.WithCancel(context.WithValue(context.Background(), callbackCtxKey{}, "req-1"))
+
+	type observed struct {
+		err         error
+		value       any
+		hasDeadline bool
+	}
+	results := make(chan observed, 1)
+	release := make(chan struct{})
+	cb := func(ctx context.Context, meta *FileMeta) error {
+		<-release
+		_, hasDeadline := ctx.Deadline()
+		results <- observed{err: ctx.Err(), value: ctx.Value(callbackCtxKey{}), hasDeadline: hasDeadline}
+		return nil
+	}
+
+	exec := NewAsyncCallbackExecutor(&mockLogger{}).WithTimeout(time.Minute)
+	if err := exec.Execute(parent, cb, &FileMeta{Name: "a.txt"}); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	cancel()
+	close(release)
+
+	got := <-results
+	if got.err != nil {
+		t.Fatalf("expected callback context to survive parent cancellation, got %v", got.err)
+	}
+	if got.value != "req-1" || !got.hasDeadline {
+		t.Fatalf("expected parent values and own deadline, got %#v", got)
+	}
+
+	exec = NewAsyncCallbackExecutor(&mockLogger{}).WithTimeout(0).WithParentValues(false)
+	release = make(chan struct{})
+	close(release)
+	if err := exec.Execute(parent, cb, &FileMeta{Name: "a.txt"}); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	got = <-results
+	if got.value != nil || got.hasDeadline {
+		t.Fatalf("expected bare background context, got %#v", got)
+	}
+}
+
+func TestMultipleCallbacksOrderingAndFilters(t *testing.T) {
+	ctx := context.Background()
+	provider := newMemoryProvider()
+
+	var order []string
+	record := func(name string, err error) UploadCallback {
+		return func(ctx context.Context, meta *FileMeta) error {
+			order = append(order, name)
+			return err
+		}
+	}
+
+	manager := NewManager(
+		WithProvider(provider),
+		WithCallbackMode(CallbackModeStrict),
+		WithOnUploadComplete(record("primary", nil)),
+		WithUploadCallback(record("late", errors.New("late failed")), WithCallbackPriority(-1)),
+		WithUploadCallback(record("early", errors.New("early failed")), WithCallbackPriority(10)),
+		WithUploadCallback(record("docs", nil), WithCallbackFilter(CallbackFilter{PathPrefix: "docs/"})),
+		WithUploadCallback(record("images", nil), WithCallbackFilter(CallbackFilter{ContentTypes: []string{"image/*"}})),
+		WithUploadCallback(record("chunked", nil), WithCallbackFilter(CallbackFilter{Operations: []CallbackOperation{CallbackOperationChunked}})),
+	)
+
+	header := newTestFileHeader(t, "file", "sample.png", "image/png", createTestPNG(10, 10))
+	_, err := manager.HandleFile(ctx, header, "images")
+	if err == nil {
+		t.Fatalf("expected strict mode to surface callback errors")
+	}
+	if !strings.Contains(err.Error(), "early failed") || !strings.Contains(err.Error(), "late failed") {
+		t.Fatalf("expected aggregated callback errors, got %v", err)
+	}
+
+	want := []string{"early", "primary", "images", "late"}
+	if fmt.Sprint(order) != fmt.Sprint(want) {
+		t.Fatalf("expected callbacks %v, got %v", want, order)
+	}
+}
@@ -78,6 +78,80 @@ func TestManagerChunkedAbort(t *testing.T) {
 	}
 }
 
+func TestManagerCompleteChunkedBlocksConcurrentMutations(t *testing.T) {
+	ctx := context.Background()
+	provider := &blockingChunkUploader{
+		mockChunkUploader: newMockChunkUploader(),
+		started:           make(chan struct{}),
+		release:           make(chan struct{}),
+	}
+	manager := NewManager(WithProvider(provider))
+
+	session, err := manager.InitiateChunked(ctx, "race.bin", 5)
+	if err != nil {
+		t.Fatalf("InitiateChunked failed: %v", err)
+	}
+
+	if err := manager.UploadChunk(ctx, session.ID, 0, bytes.NewReader([]byte("12345"))); err != nil {
+		t.Fatalf("UploadChunk failed: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := manager.CompleteChunked(ctx, session.ID)
+		done <- err
+	}()
+	<-provider.started
+
+	if err := manager.AbortChunked(ctx, session.ID); !errors.Is(err, ErrChunkSessionClosed) {
+		t.Fatalf("expected abort to be rejected while completing, got %v", err)
+	}
+
+	if err := manager.UploadChunk(ctx, session.ID, 1, bytes.NewReader([]byte("x"))); !errors.Is(err, ErrChunkSessionClosed) {
+		t.Fatalf("expected upload to be rejected while completing, got %v", err)
+	}
+
+	close(provider.release)
+	if err := <-done; err != nil {
+		t.Fatalf("CompleteChunked failed: %v", err)
+	}
+
+	if provider.isAborted(session.ID) {
+		t.Fatalf("expected provider abort to be skipped")
+	}
+}
+
+func TestManagerCompleteChunkedRollsBackOnFailure(t *testing.T) {
+	ctx := context.Background()
+	provider := &blockingChunkUploader{
+		mockChunkUploader: newMockChunkUploader(),
+		failures:          1,
+	}
+	manager := NewManager(WithProvider(provider))
+
+	session, err := manager.InitiateChunked(ctx, "retry.bin", 5)
+	if err != nil {
+		t.Fatalf("InitiateChunked failed: %v", err)
+	}
+
+	if err := manager.UploadChunk(ctx, session.ID, 0, bytes.NewReader([]byte("12345"))); err != nil {
+		t.Fatalf("UploadChunk failed: %v", err)
+	}
+
+	if _, err := manager.CompleteChunked(ctx, session.ID); err == nil {
+		t.Fatalf("expected provider failure")
+	}
+
+	stored, ok := manager.ensureChunkStore().Get(session.ID)
+	if !ok || stored.State != ChunkSessionStateActive {
+		t.Fatalf("expected session to roll back to active, got %#v", stored)
+	}
+
+	if _, err := manager.CompleteChunked(ctx, session.ID); err != nil {
+		t.Fatalf("expected retry to succeed, got %v", err)
+	}
+}
+
 func TestManagerChunkedRequiresProviderSupport(t *testing.T) {
 	ctx := context.Background()
 	manager := NewManager()
@@ -89,6 +163,25 @@ func TestManagerChunkedRequiresProviderSupport(t *testing.T) {
 	}
 }
 
+type blockingChunkUploader struct {
+	*mockChunkUploader
+	started  chan struct{}
+	release  chan struct{}
+	failures int
+}
+
+func (b *blockingChunkUploader) CompleteChunked(ctx context.Context, session *ChunkSession) (*FileMeta, error) {
+	if b.failures > 0 {
+		b.failures--
+		return nil, fmt.Errorf("provider unavailable")
+	}
+	if b.started != nil {
+		close(b.started)
+		<-b.release
+	}
+	return b.mockChunkUploader.CompleteChunked(ctx, session)
+}
+
 type stubUploader struct{}
 
 func (s *stubUploader) UploadFile(ctx context.Context, path string, content []byte, opts ...UploadOption) (string, error) {
@@ -0,0 +1,264 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	gerrors "github.com/goliatone/go-errors"
+)
+
+var (
+	_ fs.FS         = &UploaderFS{}
+	_ fs.ReadDirFS  = &UploaderFS{}
+	_ fs.StatFS     = &UploaderFS{}
+	_ fs.ReadFileFS = &UploaderFS{}
+)
+
+// UploaderFS exposes any Uploader as a read-only fs.FS. Directory operations require the
+// provider to implement Lister; without it only direct file access works.
+type UploaderFS struct {
+	provider Uploader
+	ctx      context.Context
+}
+
+// NewUploaderFS wraps provider so it can be mounted by http.FS, template.ParseFS, etc.
+func NewUploaderFS(provider Uploader) *UploaderFS {
+	return &UploaderFS{
+		provider: provider,
+		ctx:      context.Background(),
+	}
+}
+
+// WithContext sets the context used for provider calls issued through the fs.FS methods.
+func (u *UploaderFS) WithContext(ctx context.Context) *UploaderFS {
+	if ctx != nil {
+		u.ctx = ctx
+	}
+	return u
+}
+
+func (u *UploaderFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	if name == "." {
+		return u.openDir(name)
+	}
+
+	content, err := u.provider.GetFile(u.ctx, name)
+	if err == nil {
+		return &uploaderFile{
+			Reader: bytes.NewReader(content),
+			info: uploaderFileInfo{
+				name:    path.Base(name),
+				size:    int64(len(content)),
+				modTime: u.modTime(name),
+			},
+		}, nil
+	}
+
+	if dir, dirErr := u.openDir(name); dirErr == nil {
+		return dir, nil
+	}
+
+	return nil, &fs.PathError{Op: "open", Path: name, Err: toFSError(err)}
+}
+
+func (u *UploaderFS) ReadFile(name string) ([]byte, error) {
+	if !fs.ValidPath(name) || name == "." {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: fs.ErrInvalid}
+	}
+
+	content, err := u.provider.GetFile(u.ctx, name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readfile", Path: name, Err: toFSError(err)}
+	}
+
+	return content, nil
+}
+
+func (u *UploaderFS) Stat(name string) (fs.FileInfo, error) {
+	f, err := u.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return f.Stat()
+}
+
+func (u *UploaderFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrInvalid}
+	}
+
+	entries, err := u.readDir(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: err}
+	}
+
+	if len(entries) == 0 && name != "." {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return entries, nil
+}
+
+func (u *UploaderFS) openDir(name string) (fs.File, error) {
+	entries, err := u.readDir(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(entries) == 0 && name != "." {
+		return nil, fs.ErrNotExist
+	}
+
+	return &uploaderDir{
+		info:    uploaderFileInfo{name: path.Base(name), dir: true},
+		entries: entries,
+	}, nil
+}
+
+// modTime resolves the modification time through Lister when available so Stat results
+// match directory listings (and http.FileServer can emit Last-Modified).
+func (u *UploaderFS) modTime(name string) time.Time {
+	lister, ok := u.provider.(Lister)
+	if !ok {
+		return time.Time{}
+	}
+
+	objects, err := lister.List(u.ctx, name)
+	if err != nil {
+		return time.Time{}
+	}
+
+	for _, obj := range objects {
+		if obj.Key == name {
+			return obj.ModTime
+		}
+	}
+
+	return time.Time{}
+}
+
+func (u *UploaderFS) readDir(name string) ([]fs.DirEntry, error) {
+	lister, ok := u.provider.(Lister)
+	if !ok {
+		return nil, ErrNotImplemented
+	}
+
+	prefix := ""
+	if name != "." {
+		prefix = name + "/"
+	}
+
+	objects, err := lister.List(u.ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var entries []fs.DirEntry
+	for _, obj := range objects {
+		rest := strings.TrimPrefix(obj.Key, prefix)
+		if rest == "" {
+			continue
+		}
+
+		child, _, isDir := strings.Cut(rest, "/")
+		if seen[child] {
+			continue
+		}
+		seen[child] = true
+
+		info := uploaderFileInfo{name: child, dir: isDir}
+		if !isDir {
+			info.size = obj.Size
+			info.modTime = obj.ModTime
+		}
+		entries = append(entries, fs.FileInfoToDirEntry(info))
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+
+	return entries, nil
+}
+
+func toFSError(err error) error {
+	if errors.Is(err, ErrImageNotFound) || gerrors.IsNotFound(err) {
+		return fs.ErrNotExist
+	}
+	if errors.Is(err, ErrPermissionDenied) {
+		return fs.ErrPermission
+	}
+	return err
+}
+
+type uploaderFileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	dir     bool
+}
+
+func (i uploaderFileInfo) Name() string       { return i.name }
+func (i uploaderFileInfo) Size() int64        { return i.size }
+func (i uploaderFileInfo) ModTime() time.Time { return i.modTime }
+func (i uploaderFileInfo) IsDir() bool        { return i.dir }
+func (i uploaderFileInfo) Sys() any           { return nil }
+
+func (i uploaderFileInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0o555
+	}
+	return 0o444
+}
+
+type uploaderFile struct {
+	*bytes.Reader
+	info uploaderFileInfo
+}
+
+func (f *uploaderFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *uploaderFile) Close() error               { return nil }
+
+type uploaderDir struct {
+	info    uploaderFileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *uploaderDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *uploaderDir) Close() error               { return nil }
+
+func (d *uploaderDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: fs.ErrInvalid}
+}
+
+func (d *uploaderDir) ReadDir(count int) ([]fs.DirEntry, error) {
+	remaining := d.entries[d.offset:]
+	if count <= 0 {
+		d.offset = len(d.entries)
+		return remaining, nil
+	}
+
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+
+	if count > len(remaining) {
+		count = len(remaining)
+	}
+	d.offset += count
+	return remaining[:count], nil
+}
@@ -0,0 +1,128 @@
+package uploader
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestUploaderFSOverFSProvider(t *testing.T) {
+	ctx := context.Background()
+	provider := NewFSProvider(t.TempDir())
+
+	files := map[string]string{
+		"index.html":          "<html></html>",
+		"images/a.png":        "png-a",
+		"images/thumbs/a.png": "thumb-a",
+		"docs/readme.txt":     "readme",
+	}
+	for name, content := range files {
+		if _, err := provider.UploadFile(ctx, name, []byte(content)); err != nil {
+			t.Fatalf("UploadFile %s: %v", name, err)
+		}
+	}
+
+	fsys := NewUploaderFS(provider)
+	if err := fstest.TestFS(fsys, "index.html", "images/a.png", "images/thumbs/a.png", "docs/readme.txt"); err != nil {
+		t.Fatalf("fstest: %v", err)
+	}
+
+	data, err := fs.ReadFile(fsys, "images/a.png")
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(data) != "png-a" {
+		t.Fatalf("unexpected content: %s", data)
+	}
+
+	if _, err := fsys.Open("missing.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected fs.ErrNotExist, got %v", err)
+	}
+}
+
+func TestUploaderFSWithoutLister(t *testing.T) {
+	fsys := NewUploaderFS(&mockUploader{})
+
+	data, err := fs.ReadFile(fsys, "file.txt")
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if string(data) != "mock file content" {
+		t.Fatalf("unexpected content: %s", data)
+	}
+
+	if _, err := fs.ReadDir(fsys, "."); err == nil {
+		t.Fatalf("expected ReadDir to fail without Lister support")
+	}
+}
+
+func TestFSProviderList(t *testing.T) {
+	ctx := context.Background()
+	provider := NewFSProvider(t.TempDir())
+
+	for _, name := range []string{"images/a.png", "images/b.png", "imagery/c.png", "docs/d.txt"} {
+		if _, err := provider.UploadFile(ctx, name, []byte("x")); err != nil {
+			t.Fatalf("UploadFile %s: %v", name, err)
+		}
+	}
+
+	objects, err := provider.List(ctx, "images/")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(objects) != 2 {
+		t.Fatalf("expected 2 objects under images/, got %d", len(objects))
+	}
+
+	objects, err = provider.List(ctx, "imag")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(objects) != 3 {
+		t.Fatalf("expected 3 objects matching imag, got %d", len(objects))
+	}
+
+	objects, err = provider.List(ctx, "missing/")
+	if err != nil || len(objects) != 0 {
+		t.Fatalf("expected empty listing for missing prefix, got %v (%v)", objects, err)
+	}
+}
+
+func TestFSProviderListSkipsChunkDirs(t *testing.T) {
+	ctx := context.Background()
+	base := t.TempDir()
+	provider := NewFSProvider(base).WithChunkDir(filepath.Join(base, "tmp", "parts"))
+
+	if _, err := provider.UploadFile(ctx, "docs/a.txt", []byte("x")); err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+
+	session := &ChunkSession{ID: "s1", Key: "docs/b.bin"}
+	if _, err := provider.InitiateChunked(ctx, session); err != nil {
+		t.Fatalf("InitiateChunked: %v", err)
+	}
+	if _, err := provider.UploadChunk(ctx, session, 0, bytes.NewReader([]byte("part"))); err != nil {
+		t.Fatalf("UploadChunk: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Join(base, ".chunks", "old"), 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(base, ".chunks", "old", "00000000.part"), []byte("x"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	objects, err := provider.List(ctx, "")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+
+	if len(objects) != 1 || objects[0].Key != "docs/a.txt" {
+		t.Fatalf("expected chunk directories to be hidden, got %#v", objects)
+	}
+}
@@ -58,7 +58,7 @@ func TestHandleImageWithThumbnailsValidation(t *testing.T) {
 	}
 }
 
-func newTestFileHeader(t *testing.T, field, filename, contentType string, data []byte) *multipart.FileHeader {
+func newTestFileHeader(t testing.TB, field, filename, contentType string, data []byte) *multipart.FileHeader {
 	t.Helper()
 	buf := &bytes.Buffer{}
 	writer := multipart.NewWriter(buf)
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"testing"
 	"time"
+
+	gerrors "github.com/goliatone/go-errors"
 )
 
 func TestManagerCreatePresignedPost(t *testing.T) {
@@ -57,6 +59,30 @@ func TestManagerCreatePresignedPostProviderRequirement(t *testing.T) {
 	}
 }
 
+func TestManagerCreatePresignedPostRedirect(t *testing.T) {
+	ctx := context.Background()
+	provider := &stubPresignProvider{}
+	manager := NewManager()
+	WithProvider(provider)(manager)
+
+	_, err := manager.CreatePresignedPost(ctx, "uploads/file.jpg", WithContentType("image/jpeg"),
+		WithSuccessRedirect("https://app.example.com/uploaded"),
+		WithCallbackURL("https://api.example.com/hooks/upload"),
+	)
+	if err != nil {
+		t.Fatalf("CreatePresignedPost returned error: %v", err)
+	}
+	if provider.meta.SuccessRedirect != "https://app.example.com/uploaded" || provider.meta.CallbackURL != "https://api.example.com/hooks/upload" {
+		t.Fatalf("expected provider to receive redirect and callback, got %+v", provider.meta)
+	}
+
+	for _, opt := range []UploadOption{WithSuccessRedirect("/uploaded"), WithCallbackURL("javascript:alert(1)")} {
+		if _, err := manager.CreatePresignedPost(ctx, "uploads/file.jpg", WithContentType("image/jpeg"), opt); !gerrors.IsValidation(err) {
+			t.Fatalf("expected validation error, got %v", err)
+		}
+	}
+}
+
 func TestManagerConfirmPresignedUpload(t *testing.T) {
 	ctx := context.Background()
 	provider := &stubPresignProvider{
@@ -97,7 +97,7 @@ func TestManagerWithOptions(t *testing.T) {
 		WithValidator(mockValidator),
 	)
 
-	if manager.provider != mockUploader {
+	if manager.currentProvider() != mockUploader {
 		t.Error("Provider not set correctly")
 	}
 
@@ -352,6 +352,45 @@ func TestManagerHandleFile(t *testing.T) {
 			t.Errorf("Expected validation error, got %v", err)
 		}
 	})
+
+	t.Run("content larger than declared size", func(t *testing.T) {
+		content := append([]byte{0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A}, bytes.Repeat([]byte("x"), 64)...)
+		fileHeader := createMultipartFileHeader("test.png", "image/png", content)
+		fileHeader.Size = 8
+
+		uploads := 0
+		manager := NewManager(
+			WithProvider(&mockUploader{
+				uploadFunc: func(ctx context.Context, path string, fileContent []byte, opts ...UploadOption) (string, error) {
+					uploads++
+					return path, nil
+				},
+			}),
+			WithValidator(NewValidator(WithUploadMaxFileSize(32))),
+		)
+
+		_, err := manager.HandleFile(context.Background(), fileHeader, "uploads")
+		if !gerrors.IsValidation(err) || !strings.Contains(err.Error(), "file validation failed") {
+			t.Fatalf("Expected size validation error, got %v", err)
+		}
+		if uploads != 0 {
+			t.Fatalf("Expected oversized content not to be stored, got %d uploads", uploads)
+		}
+	})
+
+	t.Run("unreadable part", func(t *testing.T) {
+		fileHeader := &multipart.FileHeader{
+			Filename: "test.png",
+			Header:   textproto.MIMEHeader{"Content-Type": {"image/png"}},
+		}
+
+		manager := NewManager(WithProvider(&mockUploader{}))
+
+		_, err := manager.HandleFile(context.Background(), fileHeader, "uploads")
+		if !gerrors.IsCategory(err, gerrors.CategoryBadInput) {
+			t.Fatalf("Expected bad input error, got %v", err)
+		}
+	})
 }
 
 func TestUploadOptions(t *testing.T) {
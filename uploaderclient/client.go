@@ -0,0 +1,423 @@
+// Package uploaderclient pushes large local files through the chunked upload API served by
+// uploaderhttp.ChunkedHandler. It splits files into the part size the server picks, retries
+// failed parts, resumes interrupted sessions and confirms completion, for CLIs and worker
+// services uploading large artifacts.
+package uploaderclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/goliatone/go-uploader"
+	"github.com/goliatone/go-uploader/uploaderhttp"
+)
+
+// DefaultRetries is how many times a failed request is retried before the upload gives up.
+const DefaultRetries = 3
+
+// DefaultBackoff is the wait before the first retry; it doubles after every attempt.
+const DefaultBackoff = 500 * time.Millisecond
+
+// Client talks to a ChunkedHandler mounted at a base URL.
+type Client struct {
+	baseURL  string
+	http     *http.Client
+	header   http.Header
+	retries  int
+	backoff  time.Duration
+	progress func(sent, total int64)
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sets the client used for every request, http.DefaultClient by default.
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) {
+		c.http = client
+	}
+}
+
+// WithHeader adds a header, such as Authorization, to requests sent to the chunked API. It is
+// not sent to presigned storage URLs.
+func WithHeader(key, value string) Option {
+	return func(c *Client) {
+		c.header.Add(key, value)
+	}
+}
+
+// WithRetries sets how many times a failed request is retried, DefaultRetries by default.
+func WithRetries(n int) Option {
+	return func(c *Client) {
+		c.retries = n
+	}
+}
+
+// WithBackoff sets the wait before the first retry, DefaultBackoff by default.
+func WithBackoff(d time.Duration) Option {
+	return func(c *Client) {
+		c.backoff = d
+	}
+}
+
+// WithProgress registers a callback invoked after every stored part with the bytes sent so far.
+func WithProgress(fn func(sent, total int64)) Option {
+	return func(c *Client) {
+		c.progress = fn
+	}
+}
+
+// New creates a client for the chunked API mounted at baseURL.
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		http:    http.DefaultClient,
+		header:  make(http.Header),
+		retries: DefaultRetries,
+		backoff: DefaultBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Upload describes the object to create from a local file.
+type Upload struct {
+	Key         string
+	ContentType string
+	Attributes  map[string]string
+	// SessionID resumes an earlier session: parts the server already stored are skipped.
+	SessionID string
+}
+
+// Error is a failure reported by the chunked API, decoded from its JSON error body.
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("uploader: %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// UploadError reports a failed upload together with its session, so the caller can resume it
+// with Upload.SessionID or release it with Abort.
+type UploadError struct {
+	SessionID string
+	Err       error
+}
+
+func (e *UploadError) Error() string {
+	return fmt.Sprintf("upload session %s: %v", e.SessionID, e.Err)
+}
+
+func (e *UploadError) Unwrap() error { return e.Err }
+
+// UploadFile uploads the file at path through the handler, one part at a time.
+func (c *Client) UploadFile(ctx context.Context, path string, upload Upload) (*uploader.FileMeta, error) {
+	file, size, err := openFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var session *uploader.ChunkSessionInfo
+	if upload.SessionID != "" {
+		session, err = c.Status(ctx, upload.SessionID)
+	} else {
+		session = &uploader.ChunkSessionInfo{}
+		err = c.call(ctx, http.MethodPost, "/", initiateRequest(upload, size), session)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if session.TotalSize != size {
+		return nil, &UploadError{SessionID: session.ID, Err: fmt.Errorf("session expects %d bytes, file has %d", session.TotalSize, size)}
+	}
+
+	stored := make(map[int]bool, len(session.Parts))
+	for _, index := range session.Parts {
+		stored[index] = true
+	}
+
+	var sent int64
+	for index, offset := range partOffsets(size, session.PartSize) {
+		length := min(session.PartSize, size-offset)
+		if !stored[index] {
+			partPath := fmt.Sprintf("/%s/parts/%d", url.PathEscape(session.ID), index)
+			err := c.retry(ctx, func() error {
+				return c.do(ctx, http.MethodPut, partPath, io.NewSectionReader(file, offset, length), length, nil)
+			})
+			if err != nil {
+				return nil, &UploadError{SessionID: session.ID, Err: err}
+			}
+		}
+		sent += length
+		c.reportProgress(sent, size)
+	}
+
+	meta := &uploader.FileMeta{}
+	if err := c.call(ctx, http.MethodPost, "/"+url.PathEscape(session.ID)+"/complete", nil, meta); err != nil {
+		return nil, &UploadError{SessionID: session.ID, Err: err}
+	}
+	return meta, nil
+}
+
+// UploadFilePresigned uploads the file at path straight to storage with presigned part
+// requests, then reports the part ETags to the handler to complete the session. Presigned
+// sessions cannot be resumed; a failed upload is aborted.
+func (c *Client) UploadFilePresigned(ctx context.Context, path string, upload Upload) (*uploader.FileMeta, error) {
+	file, size, err := openFile(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	presigned := &uploader.PresignedChunkedUpload{}
+	if err := c.call(ctx, http.MethodPost, "/presigned", initiateRequest(upload, size), presigned); err != nil {
+		return nil, err
+	}
+
+	fail := func(err error) (*uploader.FileMeta, error) {
+		_ = c.Abort(context.WithoutCancel(ctx), presigned.SessionID)
+		return nil, &UploadError{SessionID: presigned.SessionID, Err: err}
+	}
+
+	offsets := partOffsets(size, presigned.PartSize)
+	if len(offsets) != len(presigned.Parts) {
+		return fail(fmt.Errorf("server presigned %d parts, file needs %d", len(presigned.Parts), len(offsets)))
+	}
+
+	complete := uploaderhttp.CompleteChunkedRequest{Parts: make([]uploaderhttp.CompletedPart, 0, len(offsets))}
+	var sent int64
+	for _, part := range presigned.Parts {
+		if part.Index < 0 || part.Index >= len(offsets) {
+			return fail(fmt.Errorf("server presigned unknown part %d", part.Index))
+		}
+		offset := offsets[part.Index]
+		length := min(presigned.PartSize, size-offset)
+
+		var etag string
+		err := c.retry(ctx, func() error {
+			var err error
+			etag, err = c.putPresigned(ctx, part.PresignedRequest, io.NewSectionReader(file, offset, length), length)
+			return err
+		})
+		if err != nil {
+			return fail(fmt.Errorf("part %d: %w", part.Index, err))
+		}
+
+		complete.Parts = append(complete.Parts, uploaderhttp.CompletedPart{Index: part.Index, ETag: etag, Size: length})
+		sent += length
+		c.reportProgress(sent, size)
+	}
+
+	meta := &uploader.FileMeta{}
+	if err := c.call(ctx, http.MethodPost, "/"+url.PathEscape(presigned.SessionID)+"/complete", complete, meta); err != nil {
+		return nil, &UploadError{SessionID: presigned.SessionID, Err: err}
+	}
+	return meta, nil
+}
+
+// Status reports a session, including the indexes of the parts already stored.
+func (c *Client) Status(ctx context.Context, sessionID string) (*uploader.ChunkSessionInfo, error) {
+	info := &uploader.ChunkSessionInfo{}
+	if err := c.call(ctx, http.MethodGet, "/"+url.PathEscape(sessionID), nil, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// Extend keeps a session alive for ttl from now, or for the server's session TTL when ttl <= 0.
+// Call it periodically during very slow transfers.
+func (c *Client) Extend(ctx context.Context, sessionID string, ttl time.Duration) (*uploader.ChunkSessionInfo, error) {
+	info := &uploader.ChunkSessionInfo{}
+	req := uploaderhttp.ExtendChunkedRequest{TTLSeconds: int64(ttl / time.Second)}
+	if err := c.call(ctx, http.MethodPost, "/"+url.PathEscape(sessionID)+"/extend", req, info); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// Abort releases a session and the parts it stored.
+func (c *Client) Abort(ctx context.Context, sessionID string) error {
+	return c.call(ctx, http.MethodDelete, "/"+url.PathEscape(sessionID), nil, nil)
+}
+
+// call sends a JSON request to the chunked API with retries and decodes the response into out.
+func (c *Client) call(ctx context.Context, method, path string, in, out any) error {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return err
+		}
+	}
+
+	return c.retry(ctx, func() error {
+		var r io.Reader
+		if body != nil {
+			r = bytes.NewReader(body)
+		}
+		return c.do(ctx, method, path, r, int64(len(body)), out)
+	})
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body io.Reader, size int64, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	for key, values := range c.header {
+		req.Header[key] = values
+	}
+	switch {
+	case method == http.MethodPut:
+		req.Header.Set("Content-Type", "application/octet-stream")
+	case body != nil:
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return decodeError(resp)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (c *Client) putPresigned(ctx context.Context, presigned uploader.PresignedRequest, body io.Reader, size int64) (string, error) {
+	method := presigned.Method
+	if method == "" {
+		method = http.MethodPut
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, presigned.URL, body)
+	if err != nil {
+		return "", err
+	}
+	req.ContentLength = size
+	for key, value := range presigned.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return "", decodeError(resp)
+	}
+	return resp.Header.Get("ETag"), nil
+}
+
+// retry runs fn until it succeeds, fails with a non-retryable error, or runs out of attempts,
+// doubling the wait between attempts.
+func (c *Client) retry(ctx context.Context, fn func() error) error {
+	wait := c.backoff
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= c.retries || !retryable(ctx, err) {
+			return err
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		wait *= 2
+	}
+}
+
+// retryable reports whether err is a transport failure or a server side status worth retrying.
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusRequestTimeout, http.StatusTooManyRequests:
+			return true
+		}
+		return apiErr.StatusCode >= http.StatusInternalServerError
+	}
+	return true
+}
+
+func (c *Client) reportProgress(sent, total int64) {
+	if c.progress != nil {
+		c.progress(sent, total)
+	}
+}
+
+func decodeError(resp *http.Response) error {
+	apiErr := &Error{StatusCode: resp.StatusCode, Code: http.StatusText(resp.StatusCode)}
+
+	var body uploaderhttp.ErrorResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&body); err == nil && body.Error.Code != "" {
+		apiErr.Code, apiErr.Message = body.Error.Code, body.Error.Message
+	}
+	return apiErr
+}
+
+func initiateRequest(upload Upload, size int64) uploaderhttp.InitiateChunkedRequest {
+	return uploaderhttp.InitiateChunkedRequest{
+		Key:         upload.Key,
+		TotalSize:   size,
+		ContentType: upload.ContentType,
+		Attributes:  upload.Attributes,
+	}
+}
+
+func openFile(path string) (*os.File, int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, 0, err
+	}
+	return file, info.Size(), nil
+}
+
+// partOffsets returns the start offset of every part of a size byte file.
+func partOffsets(size, partSize int64) []int64 {
+	if partSize <= 0 {
+		partSize = size
+	}
+
+	var offsets []int64
+	for offset := int64(0); offset < size; offset += partSize {
+		offsets = append(offsets, offset)
+	}
+	return offsets
+}
@@ -0,0 +1,199 @@
+package uploaderclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/goliatone/go-uploader"
+	"github.com/goliatone/go-uploader/uploaderhttp"
+)
+
+const testContent = "hello chunked world"
+
+func writeTestFile(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "artifact.bin")
+	if err := os.WriteFile(path, []byte(testContent), 0o600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	return path
+}
+
+func newChunkedServer(t *testing.T, middleware func(http.Handler) http.Handler) (*uploader.Manager, *httptest.Server) {
+	t.Helper()
+
+	manager := uploader.NewManager(
+		uploader.WithProvider(uploader.NewFSProvider(t.TempDir())),
+		uploader.WithChunkPartSize(5),
+	)
+
+	handler := uploaderhttp.ChunkedHandler(manager)
+	if middleware != nil {
+		handler = middleware(handler)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/chunks/", http.StripPrefix("/chunks", handler))
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return manager, server
+}
+
+func TestClientUploadFileRetriesParts(t *testing.T) {
+	var puts, failures atomic.Int32
+	manager, server := newChunkedServer(t, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPut {
+				puts.Add(1)
+				if strings.HasSuffix(r.URL.Path, "/parts/1") && failures.Add(1) == 1 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	var lastSent, lastTotal int64
+	client := New(server.URL+"/chunks/",
+		WithBackoff(time.Millisecond),
+		WithProgress(func(sent, total int64) { lastSent, lastTotal = sent, total }),
+	)
+
+	meta, err := client.UploadFile(context.Background(), writeTestFile(t), Upload{Key: "artifacts/build.bin"})
+	if err != nil {
+		t.Fatalf("UploadFile failed: %v", err)
+	}
+	if meta.Name != "artifacts/build.bin" || meta.Size != int64(len(testContent)) {
+		t.Fatalf("unexpected meta: %#v", meta)
+	}
+	if puts.Load() != 5 || lastSent != int64(len(testContent)) || lastTotal != lastSent {
+		t.Fatalf("expected 4 parts plus one retry, got %d puts, progress %d/%d", puts.Load(), lastSent, lastTotal)
+	}
+
+	stored, err := manager.GetFile(context.Background(), "artifacts/build.bin")
+	if err != nil || string(stored) != testContent {
+		t.Fatalf("unexpected stored content %q: %v", stored, err)
+	}
+}
+
+func TestClientUploadFileResumes(t *testing.T) {
+	var puts atomic.Int32
+	manager, server := newChunkedServer(t, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPut {
+				puts.Add(1)
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	ctx := context.Background()
+	session, err := manager.InitiateChunked(ctx, "artifacts/resumed.bin", int64(len(testContent)))
+	if err != nil {
+		t.Fatalf("InitiateChunked: %v", err)
+	}
+	if err := manager.UploadChunk(ctx, session.ID, 0, strings.NewReader(testContent[:5])); err != nil {
+		t.Fatalf("UploadChunk: %v", err)
+	}
+
+	client := New(server.URL + "/chunks")
+	if _, err := client.UploadFile(ctx, writeTestFile(t), Upload{SessionID: session.ID}); err != nil {
+		t.Fatalf("UploadFile failed: %v", err)
+	}
+	if puts.Load() != 3 {
+		t.Fatalf("expected only the missing parts to be sent, got %d", puts.Load())
+	}
+
+	stored, err := manager.GetFile(ctx, "artifacts/resumed.bin")
+	if err != nil || string(stored) != testContent {
+		t.Fatalf("unexpected stored content %q: %v", stored, err)
+	}
+}
+
+func TestClientUploadFileReportsSession(t *testing.T) {
+	_, server := newChunkedServer(t, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPut {
+				uploaderhttp.WriteError(w, uploader.ErrChunkPartOutOfRange)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	client := New(server.URL+"/chunks", WithBackoff(time.Millisecond))
+	_, err := client.UploadFile(context.Background(), writeTestFile(t), Upload{Key: "artifacts/broken.bin"})
+
+	var uploadErr *UploadError
+	var apiErr *Error
+	if !errors.As(err, &uploadErr) || uploadErr.SessionID == "" || !errors.As(err, &apiErr) || apiErr.Code != "CHUNK_PART_OUT_OF_RANGE" {
+		t.Fatalf("expected session error with API code, got %v", err)
+	}
+
+	status, err := client.Status(context.Background(), uploadErr.SessionID)
+	if err != nil || status.Key != "artifacts/broken.bin" || len(status.Parts) != 0 {
+		t.Fatalf("unexpected status %#v: %v", status, err)
+	}
+	extended, err := client.Extend(context.Background(), uploadErr.SessionID, 2*time.Hour)
+	if err != nil || !extended.ExpiresAt.After(status.ExpiresAt) {
+		t.Fatalf("unexpected extension %#v: %v", extended, err)
+	}
+	if err := client.Abort(context.Background(), uploadErr.SessionID); err != nil {
+		t.Fatalf("Abort failed: %v", err)
+	}
+}
+
+func TestClientUploadFilePresigned(t *testing.T) {
+	var server *httptest.Server
+	var completed uploaderhttp.CompleteChunkedRequest
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/presigned", func(w http.ResponseWriter, r *http.Request) {
+		var req uploaderhttp.InitiateChunkedRequest
+		json.NewDecoder(r.Body).Decode(&req)
+
+		upload := uploader.PresignedChunkedUpload{SessionID: "s1", Key: req.Key, TotalSize: req.TotalSize, PartSize: 10}
+		for i := 0; i < 2; i++ {
+			upload.Parts = append(upload.Parts, uploader.PresignedChunkPart{
+				Index:            i,
+				PresignedRequest: uploader.PresignedRequest{URL: server.URL + "/storage/" + string(rune('a'+i)), Method: http.MethodPut},
+			})
+		}
+		json.NewEncoder(w).Encode(upload)
+	})
+	mux.HandleFunc("PUT /storage/{part}", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("API headers must not leak to storage")
+		}
+		w.Header().Set("ETag", `"etag-`+r.PathValue("part")+`"`)
+	})
+	mux.HandleFunc("POST /api/s1/complete", func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&completed)
+		json.NewEncoder(w).Encode(uploader.FileMeta{Name: "artifacts/direct.bin", Size: int64(len(testContent))})
+	})
+	server = httptest.NewServer(mux)
+	defer server.Close()
+
+	client := New(server.URL+"/api", WithHeader("Authorization", "Bearer token"))
+	meta, err := client.UploadFilePresigned(context.Background(), writeTestFile(t), Upload{Key: "artifacts/direct.bin"})
+	if err != nil {
+		t.Fatalf("UploadFilePresigned failed: %v", err)
+	}
+	if meta.Name != "artifacts/direct.bin" || len(completed.Parts) != 2 {
+		t.Fatalf("unexpected result %#v %#v", meta, completed)
+	}
+	if part := completed.Parts[1]; part.Index != 1 || part.ETag != `"etag-b"` || part.Size != 9 {
+		t.Fatalf("unexpected completed part %#v", part)
+	}
+}
@@ -0,0 +1,203 @@
+package uploaderhttp
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	gerrors "github.com/goliatone/go-errors"
+	"github.com/goliatone/go-uploader"
+)
+
+// DefaultAccessCookieName is the cookie AccessSigner issues and Middleware reads.
+const DefaultAccessCookieName = "uploader_access"
+
+// AccessTokenQueryParam is the query parameter Middleware accepts when no cookie is sent.
+const AccessTokenQueryParam = "access_token"
+
+var (
+	ErrInvalidAccessToken = gerrors.New("invalid access token", gerrors.CategoryAuthz).
+				WithCode(http.StatusForbidden).
+				WithTextCode("INVALID_ACCESS_TOKEN")
+
+	ErrAccessTokenExpired = gerrors.New("access token expired", gerrors.CategoryAuthz).
+				WithCode(http.StatusForbidden).
+				WithTextCode("ACCESS_TOKEN_EXPIRED")
+)
+
+// AccessSigner issues tokens granting time-limited read access to every key under a prefix, so a
+// private gallery can be served from a static mount with one cookie instead of a presigned URL
+// per image.
+type AccessSigner struct {
+	secret     []byte
+	cookieName string
+	cookiePath string
+	now        func() time.Time
+	manager    *uploader.Manager
+}
+
+// AccessSignerOption configures an AccessSigner.
+type AccessSignerOption func(*AccessSigner)
+
+// WithAccessCookieName sets the cookie name, DefaultAccessCookieName by default.
+func WithAccessCookieName(name string) AccessSignerOption {
+	return func(s *AccessSigner) {
+		s.cookieName = name
+	}
+}
+
+// WithAccessCookiePath scopes the cookie to path, "/" by default. Set it to the mount point of
+// the protected handler so the cookie is not sent elsewhere.
+func WithAccessCookiePath(path string) AccessSignerOption {
+	return func(s *AccessSigner) {
+		s.cookiePath = path
+	}
+}
+
+// WithAccessClock sets the clock used to stamp and check expiry.
+func WithAccessClock(now func() time.Time) AccessSignerOption {
+	return func(s *AccessSigner) {
+		s.now = now
+	}
+}
+
+// WithAccessManager makes Middleware decode the request path with manager.DecodeKey before
+// checking it, as FileHandler does. Set it when the manager has a KeyCodec: tokens name storage
+// key prefixes, which encoded paths would never match.
+func WithAccessManager(manager *uploader.Manager) AccessSignerOption {
+	return func(s *AccessSigner) {
+		s.manager = manager
+	}
+}
+
+// NewAccessSigner creates a signer using secret as the HMAC key.
+func NewAccessSigner(secret []byte, opts ...AccessSignerOption) *AccessSigner {
+	s := &AccessSigner{
+		secret:     append([]byte(nil), secret...),
+		cookieName: DefaultAccessCookieName,
+		cookiePath: "/",
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Token returns a token granting read access to prefix and the keys under it until the returned
+// expiry. The prefix covers whole path segments: "users/1" grants "users/1/a.jpg" but not
+// "users/10/a.jpg".
+func (s *AccessSigner) Token(prefix string, ttl time.Duration) (string, time.Time) {
+	expiresAt := s.now().Add(ttl)
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(accessPrefix(prefix)))
+	expiry := strconv.FormatInt(expiresAt.Unix(), 10)
+	return encoded + "." + expiry + "." + s.sign(encoded, expiry), expiresAt
+}
+
+// Cookie wraps Token in an HttpOnly, Secure, SameSite=Lax cookie that expires with the token.
+func (s *AccessSigner) Cookie(prefix string, ttl time.Duration) *http.Cookie {
+	token, expiresAt := s.Token(prefix, ttl)
+	return &http.Cookie{
+		Name:     s.cookieName,
+		Value:    token,
+		Path:     s.cookiePath,
+		Expires:  expiresAt,
+		MaxAge:   int(ttl.Seconds()),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// Verify checks that token is authentic, unexpired and covers key.
+func (s *AccessSigner) Verify(token, key string) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return ErrInvalidAccessToken
+	}
+	encoded, expiry, sig := parts[0], parts[1], parts[2]
+
+	if !hmac.Equal([]byte(s.sign(encoded, expiry)), []byte(sig)) {
+		return ErrInvalidAccessToken
+	}
+
+	prefix, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return ErrInvalidAccessToken
+	}
+
+	expiresAt, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil {
+		return ErrInvalidAccessToken
+	}
+	if s.now().After(time.Unix(expiresAt, 0)) {
+		return ErrAccessTokenExpired
+	}
+
+	// Traversal segments could step outside the prefix once a provider cleans the key.
+	if strings.Contains(key, "..") || !coversKey(accessPrefix(string(prefix)), key) {
+		return ErrInvalidAccessToken
+	}
+
+	return nil
+}
+
+// accessPrefix ends a non-empty prefix with "/" so it only matches whole path segments.
+func accessPrefix(prefix string) string {
+	if prefix == "" || strings.HasSuffix(prefix, "/") {
+		return prefix
+	}
+	return prefix + "/"
+}
+
+// coversKey reports whether key is the segment prefix names or lies under it. prefix is
+// normalized by accessPrefix.
+func coversKey(prefix, key string) bool {
+	return strings.HasPrefix(key, prefix) || key+"/" == prefix
+}
+
+// Middleware only lets requests through when the access cookie, or the access_token query
+// parameter, covers the requested key. Like FileHandler it reads the key from the request path
+// without its leading slash, so mount both under the same http.StripPrefix. With a KeyCodec,
+// configure WithAccessManager so the path is decoded first.
+func (s *AccessSigner) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get(AccessTokenQueryParam)
+		if cookie, err := r.Cookie(s.cookieName); err == nil {
+			token = cookie.Value
+		}
+
+		if token == "" {
+			WriteError(w, ErrInvalidAccessToken)
+			return
+		}
+
+		key := strings.TrimPrefix(r.URL.Path, "/")
+		if s.manager != nil {
+			decoded, err := s.manager.DecodeKey(key)
+			if err != nil {
+				WriteError(w, err)
+				return
+			}
+			key = decoded
+		}
+
+		if err := s.Verify(token, key); err != nil {
+			WriteError(w, err)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *AccessSigner) sign(prefix, expiry string) string {
+	h := hmac.New(sha256.New, s.secret)
+	h.Write([]byte(prefix + "\n" + expiry))
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,114 @@
+package uploaderhttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/goliatone/go-uploader"
+)
+
+func TestAccessSignerMiddleware(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	signer := NewAccessSigner([]byte("secret"), WithAccessClock(func() time.Time { return now }))
+
+	manager := uploader.NewManager(uploader.WithProvider(uploader.NewFSProvider(t.TempDir())))
+	for _, key := range []string{"galleries/1/a.txt", "galleries/2/b.txt"} {
+		if _, err := manager.UploadFile(context.Background(), key, []byte(key)); err != nil {
+			t.Fatalf("UploadFile failed: %v", err)
+		}
+	}
+	handler := http.StripPrefix("/files/", signer.Middleware(FileHandler(manager)))
+
+	cookie := signer.Cookie("galleries/1/", time.Hour)
+	get := func(target string, cookie *http.Cookie) int {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := get("/files/galleries/1/a.txt", cookie); code != http.StatusOK {
+		t.Fatalf("expected access under prefix, got %d", code)
+	}
+	if code := get("/files/galleries/2/b.txt", cookie); code != http.StatusForbidden {
+		t.Fatalf("expected other prefix to be denied, got %d", code)
+	}
+	if code := get("/files/galleries/1/../2/b.txt", cookie); code != http.StatusForbidden {
+		t.Fatalf("expected traversal to be denied, got %d", code)
+	}
+	if code := get("/files/galleries/1/a.txt", nil); code != http.StatusForbidden {
+		t.Fatalf("expected request without token to be denied, got %d", code)
+	}
+
+	token, _ := signer.Token("galleries/2/", time.Minute)
+	if code := get("/files/galleries/2/b.txt?access_token="+token, nil); code != http.StatusOK {
+		t.Fatalf("expected query token to grant access, got %d", code)
+	}
+
+	forged := NewAccessSigner([]byte("other")).Cookie("galleries/", time.Hour)
+	if code := get("/files/galleries/1/a.txt", forged); code != http.StatusForbidden {
+		t.Fatalf("expected forged token to be denied, got %d", code)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if err := signer.Verify(cookie.Value, "galleries/1/a.txt"); err != ErrAccessTokenExpired {
+		t.Fatalf("expected expired token, got %v", err)
+	}
+}
+
+func TestAccessSignerPrefixSegments(t *testing.T) {
+	signer := NewAccessSigner([]byte("secret"))
+
+	for _, prefix := range []string{"users/1", "users/1/"} {
+		token, _ := signer.Token(prefix, time.Hour)
+		for _, key := range []string{"users/1", "users/1/a.jpg", "users/1/albums/b.jpg"} {
+			if err := signer.Verify(token, key); err != nil {
+				t.Fatalf("expected %q to grant %q, got %v", prefix, key, err)
+			}
+		}
+		for _, key := range []string{"users/10/a.jpg", "users/1-private/a.jpg", "users/1.jpg", "users/"} {
+			if err := signer.Verify(token, key); err != ErrInvalidAccessToken {
+				t.Fatalf("expected %q to deny sibling %q, got %v", prefix, key, err)
+			}
+		}
+	}
+}
+
+func TestAccessSignerMiddlewareDecodesKeys(t *testing.T) {
+	manager := uploader.NewManager(
+		uploader.WithProvider(uploader.NewFSProvider(t.TempDir())),
+		uploader.WithKeyCodec(uploader.NewEncryptingKeyCodec([]byte("codec"))),
+	)
+	for _, key := range []string{"galleries/1/a.txt", "galleries/2/b.txt"} {
+		if _, err := manager.UploadFile(context.Background(), key, []byte(key)); err != nil {
+			t.Fatalf("UploadFile failed: %v", err)
+		}
+	}
+
+	signer := NewAccessSigner([]byte("secret"), WithAccessManager(manager))
+	handler := http.StripPrefix("/files/", signer.Middleware(FileHandler(manager)))
+	cookie := signer.Cookie("galleries/1", time.Hour)
+	get := func(key string) int {
+		req := httptest.NewRequest(http.MethodGet, "/files/"+key, nil)
+		req.AddCookie(cookie)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := get(manager.EncodeKey("galleries/1/a.txt")); code != http.StatusOK {
+		t.Fatalf("expected the encoded key under the prefix to be served, got %d", code)
+	}
+	if code := get(manager.EncodeKey("galleries/2/b.txt")); code != http.StatusForbidden {
+		t.Fatalf("expected the encoded key outside the prefix to be denied, got %d", code)
+	}
+	if code := get("galleries/1/a.txt"); code != http.StatusNotFound {
+		t.Fatalf("expected a plain key that does not decode to be a 404, got %d", code)
+	}
+}
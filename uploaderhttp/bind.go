@@ -0,0 +1,281 @@
+package uploaderhttp
+
+import (
+	"context"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	gerrors "github.com/goliatone/go-errors"
+	"github.com/goliatone/go-uploader"
+)
+
+// DefaultMaxFormMemory is how much of a multipart body Bind keeps in memory; larger file parts
+// are spooled to temporary files by net/http.
+const DefaultMaxFormMemory = 32 << 20
+
+// Visibility values accepted in the visibility field.
+const (
+	VisibilityPrivate       = "private"
+	VisibilityPublic        = "public"
+	VisibilityAuthenticated = "authenticated"
+)
+
+// UploadRequest holds the files and declared metadata fields of an upload form, so handlers in
+// any framework can hand them to the manager the same way.
+type UploadRequest struct {
+	Files      []*multipart.FileHeader
+	Path       string
+	Tags       []string
+	Visibility string
+	// Fields holds the extra fields declared with WithBindFields that were present in the form.
+	Fields map[string]string
+}
+
+// BindOption configures Bind and BindForm.
+type BindOption func(*binder)
+
+type binder struct {
+	fileField         string
+	pathField         string
+	tagsField         string
+	visibilityField   string
+	extraFields       []string
+	defaultVisibility string
+	maxMemory         int64
+}
+
+// WithFileField sets the form field files are read from, "file" by default.
+func WithFileField(name string) BindOption {
+	return func(b *binder) {
+		b.fileField = name
+	}
+}
+
+// WithPathField sets the form field holding the destination path, "path" by default.
+func WithPathField(name string) BindOption {
+	return func(b *binder) {
+		b.pathField = name
+	}
+}
+
+// WithTagsField sets the form field holding tags, "tags" by default. Tags may be sent as repeated
+// fields, comma separated, or both.
+func WithTagsField(name string) BindOption {
+	return func(b *binder) {
+		b.tagsField = name
+	}
+}
+
+// WithVisibilityField sets the form field holding the visibility, "visibility" by default.
+func WithVisibilityField(name string) BindOption {
+	return func(b *binder) {
+		b.visibilityField = name
+	}
+}
+
+// WithDefaultVisibility sets the visibility used when the form does not send one,
+// VisibilityPrivate by default.
+func WithDefaultVisibility(visibility string) BindOption {
+	return func(b *binder) {
+		b.defaultVisibility = visibility
+	}
+}
+
+// WithBindFields declares extra form fields to copy into UploadRequest.Fields.
+func WithBindFields(names ...string) BindOption {
+	return func(b *binder) {
+		b.extraFields = append(b.extraFields, names...)
+	}
+}
+
+// WithMaxFormMemory sets how much of the body Bind parses into memory, DefaultMaxFormMemory by
+// default. It does not limit the body size; wrap the body with http.MaxBytesReader for that.
+func WithMaxFormMemory(bytes int64) BindOption {
+	return func(b *binder) {
+		b.maxMemory = bytes
+	}
+}
+
+func newBinder(opts []BindOption) *binder {
+	b := &binder{
+		fileField:         "file",
+		pathField:         "path",
+		tagsField:         "tags",
+		visibilityField:   "visibility",
+		defaultVisibility: VisibilityPrivate,
+		maxMemory:         DefaultMaxFormMemory,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// Bind parses the multipart body of r, unless it was already parsed, and binds it with
+// BindForm. Bodies that are not multipart are rejected with INVALID_FORM, and bodies cut short
+// by http.MaxBytesReader with REQUEST_TOO_LARGE.
+func Bind(r *http.Request, opts ...BindOption) (*UploadRequest, error) {
+	b := newBinder(opts)
+
+	if r.MultipartForm == nil {
+		if err := r.ParseMultipartForm(b.maxMemory); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				return nil, gerrors.Wrap(err, gerrors.CategoryBadInput, "request body too large").
+					WithCode(http.StatusRequestEntityTooLarge).
+					WithTextCode("REQUEST_TOO_LARGE")
+			}
+			return nil, gerrors.Wrap(err, gerrors.CategoryBadInput, "invalid multipart form").
+				WithCode(http.StatusBadRequest).
+				WithTextCode("INVALID_FORM")
+		}
+	}
+
+	return b.bind(r.MultipartForm)
+}
+
+// BindForm binds an already parsed form, such as the one returned by fiber's
+// Ctx.MultipartForm. The file field must carry at least one file; the path may not contain ".."
+// segments, and the visibility must be VisibilityPublic, VisibilityPrivate or
+// VisibilityAuthenticated.
+func BindForm(form *multipart.Form, opts ...BindOption) (*UploadRequest, error) {
+	return newBinder(opts).bind(form)
+}
+
+func (b *binder) bind(form *multipart.Form) (*UploadRequest, error) {
+	if form == nil {
+		form = &multipart.Form{}
+	}
+
+	req := &UploadRequest{
+		Files:      form.File[b.fileField],
+		Path:       strings.TrimPrefix(strings.TrimSpace(formValue(form, b.pathField)), "/"),
+		Tags:       splitTags(form.Value[b.tagsField]),
+		Visibility: strings.ToLower(strings.TrimSpace(formValue(form, b.visibilityField))),
+	}
+
+	if len(req.Files) == 0 {
+		return nil, bindError(b.fileField, "MISSING_FILE", "file is required", nil)
+	}
+
+	for _, segment := range strings.Split(req.Path, "/") {
+		if segment == ".." {
+			return nil, bindError(b.pathField, "INVALID_PATH", "path may not contain '..' segments", req.Path)
+		}
+	}
+
+	switch req.Visibility {
+	case "":
+		req.Visibility = b.defaultVisibility
+	case VisibilityPublic, VisibilityPrivate, VisibilityAuthenticated:
+	default:
+		return nil, bindError(b.visibilityField, "INVALID_VISIBILITY", "visibility must be public, private or authenticated", req.Visibility)
+	}
+
+	for _, name := range b.extraFields {
+		if values, ok := form.Value[name]; ok && len(values) > 0 {
+			if req.Fields == nil {
+				req.Fields = make(map[string]string)
+			}
+			req.Fields[name] = values[0]
+		}
+	}
+
+	return req, nil
+}
+
+// File returns the first uploaded file.
+func (r *UploadRequest) File() *multipart.FileHeader {
+	if len(r.Files) == 0 {
+		return nil
+	}
+	return r.Files[0]
+}
+
+// Attributes returns the extra fields, the visibility and the comma joined tags as upload
+// attributes.
+func (r *UploadRequest) Attributes() map[string]string {
+	attrs := make(map[string]string, len(r.Fields)+2)
+	for k, v := range r.Fields {
+		attrs[k] = v
+	}
+	if r.Visibility != "" {
+		attrs["visibility"] = r.Visibility
+	}
+	if len(r.Tags) > 0 {
+		attrs["tags"] = strings.Join(r.Tags, ",")
+	}
+	return attrs
+}
+
+// Context attaches Attributes and the visibility to ctx, so Manager.HandleFile records them on
+// the FileMeta it returns and stores the file with the requested access level.
+func (r *UploadRequest) Context(ctx context.Context) context.Context {
+	ctx = uploader.ContextWithAttributes(ctx, r.Attributes())
+	if v, err := uploader.ParseVisibility(r.Visibility); err == nil {
+		ctx = uploader.ContextWithVisibility(ctx, v)
+	}
+	return ctx
+}
+
+// UploadOptions maps the request onto options for Manager.UploadFile: the visibility and
+// Attributes.
+func (r *UploadRequest) UploadOptions() []uploader.UploadOption {
+	opts := []uploader.UploadOption{uploader.WithAttributes(r.Attributes())}
+	if v, err := uploader.ParseVisibility(r.Visibility); err == nil {
+		opts = append(opts, uploader.WithVisibility(v))
+	}
+	return opts
+}
+
+// HandleFiles stores every file with Manager.HandleFile under Path, attaching Attributes. It
+// stops at the first failure and returns the files stored so far with the error.
+func (r *UploadRequest) HandleFiles(ctx context.Context, manager *uploader.Manager) ([]*uploader.FileMeta, error) {
+	ctx = r.Context(ctx)
+
+	metas := make([]*uploader.FileMeta, 0, len(r.Files))
+	for _, file := range r.Files {
+		meta, err := manager.HandleFile(ctx, file, r.Path)
+		if err != nil {
+			return metas, err
+		}
+		metas = append(metas, meta)
+	}
+	return metas, nil
+}
+
+func formValue(form *multipart.Form, name string) string {
+	if values := form.Value[name]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// splitTags flattens repeated and comma separated tag values, dropping blanks and duplicates.
+func splitTags(values []string) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	for _, value := range values {
+		for _, tag := range strings.Split(value, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "" || seen[tag] {
+				continue
+			}
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func bindError(field, textCode, message string, value any) error {
+	return gerrors.NewValidation("invalid upload form",
+		gerrors.FieldError{
+			Field:   field,
+			Message: message,
+			Value:   value,
+		},
+	).WithCode(http.StatusBadRequest).WithTextCode(textCode)
+}
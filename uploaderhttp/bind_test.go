@@ -0,0 +1,191 @@
+package uploaderhttp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	gerrors "github.com/goliatone/go-errors"
+	"github.com/goliatone/go-uploader"
+)
+
+func newUploadRequest(t *testing.T, fields map[string][]string, files map[string]string) *http.Request {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for name, values := range fields {
+		for _, value := range values {
+			if err := writer.WriteField(name, value); err != nil {
+				t.Fatalf("WriteField: %v", err)
+			}
+		}
+	}
+	for filename, content := range files {
+		part, err := writer.CreateFormFile("file", filename)
+		if err != nil {
+			t.Fatalf("CreateFormFile: %v", err)
+		}
+		part.Write([]byte(content))
+	}
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func testPNG(t *testing.T) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 2, 2))); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	return buf.String()
+}
+
+func TestBind(t *testing.T) {
+	req := newUploadRequest(t, map[string][]string{
+		"path":       {"/docs"},
+		"tags":       {"a, b", "b", "c"},
+		"visibility": {"Public"},
+		"album":      {"summer"},
+		"ignored":    {"x"},
+	}, map[string]string{"a.png": testPNG(t)})
+
+	bound, err := Bind(req, WithBindFields("album"))
+	if err != nil {
+		t.Fatalf("Bind failed: %v", err)
+	}
+
+	if bound.Path != "docs" || bound.Visibility != VisibilityPublic || strings.Join(bound.Tags, ",") != "a,b,c" {
+		t.Fatalf("unexpected binding: %#v", bound)
+	}
+	if bound.File() == nil || bound.File().Filename != "a.png" || len(bound.Fields) != 1 || bound.Fields["album"] != "summer" {
+		t.Fatalf("unexpected files or fields: %#v", bound)
+	}
+
+	attrs := bound.Attributes()
+	if attrs["tags"] != "a,b,c" || attrs["visibility"] != "public" || attrs["album"] != "summer" {
+		t.Fatalf("unexpected attributes: %#v", attrs)
+	}
+
+	manager := uploader.NewManager(uploader.WithProvider(uploader.NewFSProvider(t.TempDir())))
+	metas, err := bound.HandleFiles(context.Background(), manager)
+	if err != nil {
+		t.Fatalf("HandleFiles failed: %v", err)
+	}
+	if len(metas) != 1 || !strings.HasPrefix(metas[0].Name, "docs/") || metas[0].Attributes["album"] != "summer" {
+		t.Fatalf("unexpected stored files: %#v", metas)
+	}
+}
+
+func TestBindRejectsInvalidForms(t *testing.T) {
+	tests := []struct {
+		fields map[string][]string
+		files  map[string]string
+		code   string
+	}{
+		{map[string][]string{"path": {"docs"}}, nil, "MISSING_FILE"},
+		{map[string][]string{"path": {"docs/../etc"}}, map[string]string{"a.txt": "x"}, "INVALID_PATH"},
+		{map[string][]string{"visibility": {"shared"}}, map[string]string{"a.txt": "x"}, "INVALID_VISIBILITY"},
+	}
+	for _, tt := range tests {
+		_, err := Bind(newUploadRequest(t, tt.fields, tt.files))
+		var bindErr *gerrors.Error
+		if !errors.As(err, &bindErr) || bindErr.TextCode != tt.code || ErrorToStatus(err) != http.StatusBadRequest {
+			t.Fatalf("expected %s, got %v", tt.code, err)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader("{}"))
+	req.Header.Set("Content-Type", "application/json")
+	var bindErr *gerrors.Error
+	if _, err := Bind(req); !errors.As(err, &bindErr) || bindErr.TextCode != "INVALID_FORM" {
+		t.Fatalf("expected INVALID_FORM, got %v", err)
+	}
+}
+
+func TestBindFormDefaults(t *testing.T) {
+	form := &multipart.Form{
+		Value: map[string][]string{"folder": {"docs"}},
+		File:  map[string][]*multipart.FileHeader{"upload": {{Filename: "a.txt"}}},
+	}
+
+	bound, err := BindForm(form, WithFileField("upload"), WithPathField("folder"))
+	if err != nil {
+		t.Fatalf("BindForm failed: %v", err)
+	}
+	if bound.Path != "docs" || bound.Visibility != VisibilityPrivate || bound.Tags != nil {
+		t.Fatalf("unexpected binding: %#v", bound)
+	}
+}
+
+func TestBindAuthenticatedVisibility(t *testing.T) {
+	bound, err := Bind(newUploadRequest(t, map[string][]string{"visibility": {"authenticated"}}, map[string]string{"a.txt": "x"}))
+	if err != nil {
+		t.Fatalf("Bind failed: %v", err)
+	}
+
+	md := &uploader.Metadata{}
+	for _, opt := range bound.UploadOptions() {
+		opt(md)
+	}
+	if md.Visibility != uploader.VisibilityAuthenticated {
+		t.Fatalf("expected authenticated upload option, got %q", md.Visibility)
+	}
+}
+
+func FuzzBind(f *testing.F) {
+	const boundary = "fuzzboundary"
+	form := func(path, visibility, filename string) string {
+		return "--" + boundary + "\r\n" +
+			"Content-Disposition: form-data; name=\"path\"\r\n\r\n" + path + "\r\n" +
+			"--" + boundary + "\r\n" +
+			"Content-Disposition: form-data; name=\"visibility\"\r\n\r\n" + visibility + "\r\n" +
+			"--" + boundary + "\r\n" +
+			"Content-Disposition: form-data; name=\"file\"; filename=\"" + filename + "\"\r\n" +
+			"Content-Type: image/png\r\n\r\npng\r\n" +
+			"--" + boundary + "--\r\n"
+	}
+	f.Add(form("avatars", "public", "a.png"))
+	f.Add(form("a/../../etc", "private", "b.png"))
+	f.Add(form(" /..", "PUBLIC ", "../c.png"))
+	f.Add("--" + boundary + "\r\nContent-Disposition: form-data; name=\"file\"\r\n\r\n")
+	f.Add("")
+
+	f.Fuzz(func(t *testing.T, body string) {
+		req := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader(body))
+		req.Header.Set("Content-Type", "multipart/form-data; boundary="+boundary)
+
+		bound, err := Bind(req, WithMaxFormMemory(1<<16))
+		if err != nil {
+			var structured *gerrors.Error
+			if !errors.As(err, &structured) {
+				t.Fatalf("expected a structured error, got %v", err)
+			}
+			return
+		}
+		for _, segment := range strings.Split(bound.Path, "/") {
+			if segment == ".." {
+				t.Fatalf("bound path %q contains a '..' segment", bound.Path)
+			}
+		}
+		switch bound.Visibility {
+		case VisibilityPublic, VisibilityPrivate, VisibilityAuthenticated:
+		default:
+			t.Fatalf("bound unexpected visibility %q", bound.Visibility)
+		}
+		if bound.File() == nil {
+			t.Fatalf("bound request without a file")
+		}
+	})
+}
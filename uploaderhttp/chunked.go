@@ -0,0 +1,198 @@
+package uploaderhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	gerrors "github.com/goliatone/go-errors"
+	"github.com/goliatone/go-uploader"
+)
+
+// InitiateChunkedRequest is the JSON body ChunkedHandler accepts to start a session.
+type InitiateChunkedRequest struct {
+	Key         string            `json:"key"`
+	TotalSize   int64             `json:"total_size"`
+	ContentType string            `json:"content_type,omitempty"`
+	Attributes  map[string]string `json:"attributes,omitempty"`
+}
+
+// CompleteChunkedRequest is the optional JSON body of a completion. Clients that uploaded parts
+// with presigned requests report them here; parts uploaded through the handler are already known.
+type CompleteChunkedRequest struct {
+	Parts []CompletedPart `json:"parts,omitempty"`
+}
+
+// ExtendChunkedRequest is the optional JSON body of a session extension. TTLSeconds <= 0 keeps
+// the session TTL.
+type ExtendChunkedRequest struct {
+	TTLSeconds int64 `json:"ttl_seconds,omitempty"`
+}
+
+// CompletedPart identifies a part uploaded with a presigned request by the ETag storage returned.
+type CompletedPart struct {
+	Index int    `json:"index"`
+	ETag  string `json:"etag"`
+	Size  int64  `json:"size,omitempty"`
+}
+
+// ChunkedHandler exposes chunked uploads as a small JSON API, relative to where it is mounted:
+//
+//	POST   /                      start a session (InitiateChunkedRequest) -> ChunkSessionInfo
+//	POST   /presigned             start a presigned session -> PresignedChunkedUpload
+//	GET    /{session}             session status, including stored part indexes
+//	PUT    /{session}/parts/{n}   upload part n from the request body
+//	POST   /{session}/extend      push out the expiry (ExtendChunkedRequest) -> ChunkSessionInfo
+//	POST   /{session}/complete    assemble the object (CompleteChunkedRequest) -> FileMeta
+//	DELETE /{session}             abort the session
+//
+// Mount it under http.StripPrefix. Failures are written with WriteError.
+func ChunkedHandler(manager *uploader.Manager) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /{$}", func(w http.ResponseWriter, r *http.Request) {
+		req, ok := decodeInitiate(w, r)
+		if !ok {
+			return
+		}
+
+		session, err := manager.InitiateChunked(r.Context(), req.Key, req.TotalSize, req.uploadOptions()...)
+		if err != nil {
+			WriteError(w, err)
+			return
+		}
+
+		info, err := manager.ChunkSession(r.Context(), session.ID)
+		if err != nil {
+			WriteError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, info)
+	})
+
+	mux.HandleFunc("POST /presigned", func(w http.ResponseWriter, r *http.Request) {
+		req, ok := decodeInitiate(w, r)
+		if !ok {
+			return
+		}
+
+		upload, err := manager.InitiatePresignedChunked(r.Context(), req.Key, req.TotalSize, req.uploadOptions()...)
+		if err != nil {
+			WriteError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, upload)
+	})
+
+	mux.HandleFunc("GET /{session}", func(w http.ResponseWriter, r *http.Request) {
+		info, err := manager.ChunkSession(r.Context(), r.PathValue("session"))
+		if err != nil {
+			WriteError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, info)
+	})
+
+	mux.HandleFunc("PUT /{session}/parts/{index}", func(w http.ResponseWriter, r *http.Request) {
+		index, err := strconv.Atoi(r.PathValue("index"))
+		if err != nil {
+			WriteError(w, uploader.ErrChunkPartOutOfRange)
+			return
+		}
+
+		if err := manager.UploadChunk(r.Context(), r.PathValue("session"), index, r.Body); err != nil {
+			WriteError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc("POST /{session}/extend", func(w http.ResponseWriter, r *http.Request) {
+		var req ExtendChunkedRequest
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				WriteError(w, errInvalidJSON(err))
+				return
+			}
+		}
+
+		info, err := manager.ExtendChunkSession(r.Context(), r.PathValue("session"), time.Duration(req.TTLSeconds)*time.Second)
+		if err != nil {
+			WriteError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, info)
+	})
+
+	mux.HandleFunc("POST /{session}/complete", func(w http.ResponseWriter, r *http.Request) {
+		var req CompleteChunkedRequest
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				WriteError(w, errInvalidJSON(err))
+				return
+			}
+		}
+
+		var (
+			meta *uploader.FileMeta
+			err  error
+		)
+		if len(req.Parts) > 0 {
+			parts := make([]uploader.ChunkPart, 0, len(req.Parts))
+			for _, part := range req.Parts {
+				parts = append(parts, uploader.ChunkPart{Index: part.Index, ETag: part.ETag, Size: part.Size})
+			}
+			meta, err = manager.CompletePresignedChunked(r.Context(), r.PathValue("session"), parts)
+		} else {
+			meta, err = manager.CompleteChunked(r.Context(), r.PathValue("session"))
+		}
+		if err != nil {
+			WriteError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, meta)
+	})
+
+	mux.HandleFunc("DELETE /{session}", func(w http.ResponseWriter, r *http.Request) {
+		if err := manager.AbortChunked(r.Context(), r.PathValue("session")); err != nil {
+			WriteError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	return mux
+}
+
+func (req *InitiateChunkedRequest) uploadOptions() []uploader.UploadOption {
+	var opts []uploader.UploadOption
+	if req.ContentType != "" {
+		opts = append(opts, uploader.WithContentType(req.ContentType))
+	}
+	if len(req.Attributes) > 0 {
+		opts = append(opts, uploader.WithAttributes(req.Attributes))
+	}
+	return opts
+}
+
+func decodeInitiate(w http.ResponseWriter, r *http.Request) (*InitiateChunkedRequest, bool) {
+	var req InitiateChunkedRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, errInvalidJSON(err))
+		return nil, false
+	}
+	return &req, true
+}
+
+func errInvalidJSON(err error) error {
+	return gerrors.Wrap(err, gerrors.CategoryBadInput, "invalid JSON body").
+		WithCode(http.StatusBadRequest).
+		WithTextCode("INVALID_JSON")
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,66 @@
+package uploaderhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/goliatone/go-uploader"
+)
+
+func TestChunkedHandler(t *testing.T) {
+	manager := uploader.NewManager(
+		uploader.WithProvider(uploader.NewFSProvider(t.TempDir())),
+		uploader.WithChunkPartSize(4),
+	)
+	handler := http.StripPrefix("/chunks", ChunkedHandler(manager))
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := serve(http.MethodPost, "/chunks/", `{"key":"videos/a.bin","total_size":6,"attributes":{"owner":"42"}}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("initiate failed: %d %s", rec.Code, rec.Body.String())
+	}
+	var session uploader.ChunkSessionInfo
+	if err := json.NewDecoder(rec.Body).Decode(&session); err != nil || session.PartSize != 4 || session.Attributes["owner"] != "42" {
+		t.Fatalf("unexpected session %#v: %v", session, err)
+	}
+
+	if rec := serve(http.MethodPut, "/chunks/"+session.ID+"/parts/0", "abcd"); rec.Code != http.StatusNoContent {
+		t.Fatalf("upload part failed: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := serve(http.MethodPut, "/chunks/"+session.ID+"/parts/x", "ef"); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for a bad index, got %d", rec.Code)
+	}
+
+	rec = serve(http.MethodGet, "/chunks/"+session.ID, "")
+	if err := json.NewDecoder(rec.Body).Decode(&session); err != nil || len(session.Parts) != 1 || session.UploadedBytes != 4 {
+		t.Fatalf("unexpected status %#v: %v", session, err)
+	}
+
+	expiresAt := session.ExpiresAt
+	rec = serve(http.MethodPost, "/chunks/"+session.ID+"/extend", `{"ttl_seconds":7200}`)
+	if err := json.NewDecoder(rec.Body).Decode(&session); err != nil || rec.Code != http.StatusOK || !session.ExpiresAt.After(expiresAt) {
+		t.Fatalf("unexpected extension %d %#v: %v", rec.Code, session, err)
+	}
+
+	if rec := serve(http.MethodDelete, "/chunks/"+session.ID, ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("abort failed: %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := serve(http.MethodPost, "/chunks/"+session.ID+"/complete", ""); rec.Code == http.StatusOK {
+		t.Fatalf("expected aborted session not to complete")
+	}
+
+	if rec := serve(http.MethodPost, "/chunks/", "{"); rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "INVALID_JSON") {
+		t.Fatalf("expected INVALID_JSON, got %d %s", rec.Code, rec.Body.String())
+	}
+	if rec := serve(http.MethodGet, "/chunks/missing", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown session, got %d", rec.Code)
+	}
+}
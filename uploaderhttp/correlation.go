@@ -0,0 +1,29 @@
+package uploaderhttp
+
+import (
+	"net/http"
+
+	"github.com/goliatone/go-uploader"
+)
+
+// DefaultCorrelationHeader is the request header CorrelationMiddleware reads by default.
+const DefaultCorrelationHeader = "X-Request-ID"
+
+// CorrelationMiddleware stores the value of header, DefaultCorrelationHeader when empty, as the
+// correlation ID of the request context, so manager and provider logs, events and errors can be
+// traced back to the request. The ID is echoed on the response. Requests without the header pass
+// through unchanged.
+func CorrelationMiddleware(header string) func(http.Handler) http.Handler {
+	if header == "" {
+		header = DefaultCorrelationHeader
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if id := r.Header.Get(header); id != "" {
+				w.Header().Set(header, id)
+				r = r.WithContext(uploader.ContextWithCorrelationID(r.Context(), id))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
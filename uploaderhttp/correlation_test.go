@@ -0,0 +1,28 @@
+package uploaderhttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/goliatone/go-uploader"
+)
+
+func TestCorrelationMiddleware(t *testing.T) {
+	var seen string
+	handler := CorrelationMiddleware("")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = uploader.CorrelationIDFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", nil)
+	req.Header.Set(DefaultCorrelationHeader, "req-42")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if seen != "req-42" {
+		t.Fatalf("expected correlation ID in the context, got %q", seen)
+	}
+	if got := rec.Header().Get(DefaultCorrelationHeader); got != "req-42" {
+		t.Fatalf("expected correlation ID echoed, got %q", got)
+	}
+}
@@ -0,0 +1,114 @@
+// Package uploaderhttp exposes go-uploader over net/http: error responses with a stable JSON
+// shape, upload form binding, a chunked upload API and handlers for serving stored files.
+package uploaderhttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	gerrors "github.com/goliatone/go-errors"
+	"github.com/goliatone/go-uploader"
+)
+
+// ErrorResponse is the JSON body written by WriteError.
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+// ErrorBody describes a failed request. Code is the package text code (FILE_TOO_LARGE,
+// IMAGE_NOT_FOUND, ...) clients should branch on; Message is for humans. Fields and Messages are
+// only set for validation errors.
+type ErrorBody struct {
+	Status   int                          `json:"status"`
+	Code     string                       `json:"code"`
+	Category string                       `json:"category,omitempty"`
+	Message  string                       `json:"message"`
+	Fields   []gerrors.FieldError         `json:"fields,omitempty"`
+	Messages []uploader.ValidationMessage `json:"messages,omitempty"`
+}
+
+var categoryStatus = map[gerrors.Category]int{
+	gerrors.CategoryValidation:       http.StatusBadRequest,
+	gerrors.CategoryBadInput:         http.StatusBadRequest,
+	gerrors.CategoryAuth:             http.StatusUnauthorized,
+	gerrors.CategoryAuthz:            http.StatusForbidden,
+	gerrors.CategoryNotFound:         http.StatusNotFound,
+	gerrors.CategoryConflict:         http.StatusConflict,
+	gerrors.CategoryRateLimit:        http.StatusTooManyRequests,
+	gerrors.CategoryMethodNotAllowed: http.StatusMethodNotAllowed,
+	gerrors.CategoryOperation:        http.StatusServiceUnavailable,
+	gerrors.CategoryExternal:         http.StatusBadGateway,
+}
+
+// ErrorToStatus returns the HTTP status for err: the code set on a package error, then its
+// category, then 504 for deadlines, 408 for canceled requests and 500 for anything else.
+func ErrorToStatus(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+
+	var uerr *gerrors.Error
+	if errors.As(err, &uerr) {
+		if uerr.Code >= 400 && uerr.Code < 600 {
+			return uerr.Code
+		}
+		if status, ok := categoryStatus[uerr.Category]; ok {
+			return status
+		}
+	}
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, context.Canceled):
+		return http.StatusRequestTimeout
+	}
+
+	return http.StatusInternalServerError
+}
+
+// NewErrorResponse builds the body WriteError sends for err. Server errors keep their code but
+// replace the message with the status text so internal details are not leaked.
+func NewErrorResponse(err error) ErrorResponse {
+	status := ErrorToStatus(err)
+	body := ErrorBody{
+		Status:  status,
+		Code:    "INTERNAL_ERROR",
+		Message: http.StatusText(status),
+	}
+
+	var uerr *gerrors.Error
+	if errors.As(err, &uerr) {
+		if uerr.TextCode != "" {
+			body.Code = uerr.TextCode
+		}
+		body.Category = string(uerr.Category)
+		if status < http.StatusInternalServerError {
+			body.Message = uerr.Message
+		}
+		body.Fields = uerr.ValidationErrors
+		body.Messages = uploader.ValidationMessages(err)
+	} else if status < http.StatusInternalServerError {
+		body.Code = "REQUEST_FAILED"
+		body.Message = err.Error()
+	}
+
+	return ErrorResponse{Error: body}
+}
+
+// WriteError writes err as a JSON ErrorResponse with the status from ErrorToStatus.
+func WriteError(w http.ResponseWriter, err error) {
+	response := NewErrorResponse(err)
+
+	if wait, ok := uploader.RetryAfter(err); ok {
+		w.Header().Set("Retry-After", strconv.Itoa(int(wait/time.Second)))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(response.Error.Status)
+	_ = json.NewEncoder(w).Encode(response)
+}
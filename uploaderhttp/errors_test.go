@@ -0,0 +1,94 @@
+package uploaderhttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	gerrors "github.com/goliatone/go-errors"
+	"github.com/goliatone/go-uploader"
+)
+
+func TestErrorToStatus(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{nil, http.StatusOK},
+		{uploader.ErrImageNotFound, http.StatusNotFound},
+		{fmt.Errorf("wrapped: %w", uploader.ErrFileExists), http.StatusConflict},
+		{uploader.ErrServiceReadOnly, http.StatusServiceUnavailable},
+		{gerrors.New("limited", gerrors.CategoryRateLimit), http.StatusTooManyRequests},
+		{context.DeadlineExceeded, http.StatusGatewayTimeout},
+		{errors.New("boom"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		if status := ErrorToStatus(tt.err); status != tt.status {
+			t.Errorf("ErrorToStatus(%v) = %d, want %d", tt.err, status, tt.status)
+		}
+	}
+}
+
+func TestWriteErrorValidation(t *testing.T) {
+	validator := uploader.NewValidator(uploader.WithUploadMaxFileSize(4))
+	err := validator.ValidateFileContent([]byte("too large"))
+
+	rec := httptest.NewRecorder()
+	WriteError(rec, err)
+
+	if rec.Code != http.StatusBadRequest || rec.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("unexpected response: %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+
+	var response ErrorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	body := response.Error
+	if body.Code != "FILE_TOO_LARGE" || body.Category != "validation" || len(body.Fields) != 1 || body.Fields[0].Field != "file_size" {
+		t.Fatalf("unexpected body: %#v", body)
+	}
+	if len(body.Messages) != 1 || body.Messages[0].Key != "uploader.validation.file_too_large" {
+		t.Fatalf("expected message keys, got %#v", body.Messages)
+	}
+}
+
+func TestWriteErrorHidesInternalDetails(t *testing.T) {
+	rec := httptest.NewRecorder()
+	WriteError(rec, errors.New("dial tcp 10.0.0.1:5432: connection refused"))
+
+	var response ErrorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if rec.Code != http.StatusInternalServerError || response.Error.Code != "INTERNAL_ERROR" || response.Error.Message != "Internal Server Error" {
+		t.Fatalf("unexpected response: %d %#v", rec.Code, response.Error)
+	}
+}
+
+func TestWriteErrorRateLimited(t *testing.T) {
+	identity := func(context.Context) string { return "client" }
+	manager := uploader.NewManager(
+		uploader.WithProvider(uploader.NewFSProvider(t.TempDir())),
+		uploader.WithRateLimit(identity, uploader.RateLimit{UploadsPerMinute: 1}),
+	)
+
+	ctx := context.Background()
+	if _, err := manager.UploadFile(ctx, "docs/a.txt", []byte("a")); err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	_, err := manager.UploadFile(ctx, "docs/a.txt", []byte("a"))
+
+	rec := httptest.NewRecorder()
+	WriteError(rec, err)
+
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") == "" {
+		t.Fatalf("unexpected response: %d retry-after=%q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+}
@@ -0,0 +1,129 @@
+package uploaderhttp
+
+import (
+	"bytes"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	gerrors "github.com/goliatone/go-errors"
+	"github.com/goliatone/go-uploader"
+)
+
+// ServeFile writes the object stored under key, using its stored Content-Type, Cache-Control,
+// ETag and modification time as response headers. Conditional requests that still match are
+// answered with 304 Not Modified before the content is downloaded; Range and HEAD requests are
+// handled by http.ServeContent. Failures are written with WriteError.
+func ServeFile(w http.ResponseWriter, r *http.Request, manager *uploader.Manager, key string) {
+	info, err := manager.StatFile(r.Context(), key)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	setFileHeaders(w.Header(), info)
+	if notModified(r, info) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	content, err := manager.GetFile(r.Context(), key)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	http.ServeContent(w, r, path.Base(key), info.ModTime, bytes.NewReader(content))
+}
+
+// FileHandler serves GET and HEAD requests with ServeFile, using the request path without its
+// leading slash as the key. Mount it under http.StripPrefix to serve a key prefix. With a
+// KeyCodec the path is an external key, decoded with Manager.DecodeKey.
+func FileHandler(manager *uploader.Manager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !allowReadMethods(w, r) {
+			return
+		}
+
+		key, err := requestKey(r, manager)
+		if err != nil {
+			WriteError(w, err)
+			return
+		}
+		ServeFile(w, r, manager, key)
+	})
+}
+
+// requestKey returns the storage key named by the request path, decoded with the manager's
+// KeyCodec when one is configured.
+func requestKey(r *http.Request, manager *uploader.Manager) (string, error) {
+	return manager.DecodeKey(strings.TrimPrefix(r.URL.Path, "/"))
+}
+
+// allowReadMethods answers anything but GET and HEAD with 405 and reports whether to continue.
+func allowReadMethods(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return true
+	}
+	w.Header().Set("Allow", "GET, HEAD")
+	WriteError(w, gerrors.New("method not allowed", gerrors.CategoryMethodNotAllowed).
+		WithCode(http.StatusMethodNotAllowed).
+		WithTextCode("METHOD_NOT_ALLOWED"))
+	return false
+}
+
+func setFileHeaders(h http.Header, info *uploader.ObjectInfo) {
+	contentType := info.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	h.Set("Content-Type", contentType)
+	h.Set("X-Content-Type-Options", "nosniff")
+
+	if info.CacheControl != "" {
+		h.Set("Cache-Control", info.CacheControl)
+	}
+	if info.ETag != "" {
+		h.Set("ETag", quoteETag(info.ETag))
+	}
+	if !info.ModTime.IsZero() {
+		h.Set("Last-Modified", info.ModTime.UTC().Format(http.TimeFormat))
+	}
+}
+
+// notModified evaluates If-None-Match, or If-Modified-Since when no entity tag was sent, the
+// way RFC 9110 orders them for GET and HEAD.
+func notModified(r *http.Request, info *uploader.ObjectInfo) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		return info.ETag != "" && etagListMatches(match, quoteETag(info.ETag))
+	}
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil || info.ModTime.IsZero() {
+		return false
+	}
+	return !info.ModTime.Truncate(time.Second).After(since)
+}
+
+// etagListMatches reports whether the If-None-Match list contains etag, comparing weakly.
+func etagListMatches(list, etag string) bool {
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+func quoteETag(etag string) string {
+	if strings.HasPrefix(etag, "\"") || strings.HasPrefix(etag, "W/\"") {
+		return etag
+	}
+	return "\"" + etag + "\""
+}
@@ -0,0 +1,119 @@
+package uploaderhttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/goliatone/go-uploader"
+)
+
+func TestFileHandlerHeaders(t *testing.T) {
+	manager := uploader.NewManager(
+		uploader.WithProvider(uploader.NewFSProvider(t.TempDir())),
+		uploader.WithCachePolicy(uploader.CachePolicy{ByContentType: map[string]string{"text/*": "public, max-age=60"}}),
+	)
+	if _, err := manager.UploadFile(context.Background(), "docs/a.txt", []byte("hello")); err != nil {
+		t.Fatalf("UploadFile failed: %v", err)
+	}
+
+	handler := http.StripPrefix("/files/", FileHandler(manager))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/files/docs/a.txt", nil))
+
+	if rec.Code != http.StatusOK || rec.Body.String() != "hello" {
+		t.Fatalf("unexpected response: %d %q", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/plain; charset=utf-8" {
+		t.Fatalf("expected stored content type, got %q", ct)
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc != "public, max-age=60" {
+		t.Fatalf("expected cache policy header, got %q", cc)
+	}
+
+	lastModified, etag := rec.Header().Get("Last-Modified"), rec.Header().Get("ETag")
+	if lastModified == "" {
+		t.Fatalf("expected Last-Modified header")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/files/docs/a.txt", nil)
+	req.Header.Set("If-Modified-Since", lastModified)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Fatalf("expected 304, got %d", rec.Code)
+	}
+
+	if etag == "" {
+		t.Fatalf("expected ETag header")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/files/docs/a.txt", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified || rec.Header().Get("ETag") != etag {
+		t.Fatalf("expected 304 for matching ETag, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/files/docs/a.txt", nil)
+	req.Header.Set("If-Modified-Since", time.Unix(0, 0).UTC().Format(http.TimeFormat))
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected stale copy to be refreshed, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/files/docs/missing.txt", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/files/docs/a.txt", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", rec.Code)
+	}
+}
+
+func TestFileHandlerKeyCodec(t *testing.T) {
+	manager := uploader.NewManager(
+		uploader.WithProvider(uploader.NewFSProvider(t.TempDir())),
+		uploader.WithKeyCodec(uploader.NewEncryptingKeyCodec([]byte("secret"))),
+	)
+	if _, err := manager.UploadFile(context.Background(), "docs/a.txt", []byte("hello")); err != nil {
+		t.Fatalf("UploadFile failed: %v", err)
+	}
+
+	handler := http.StripPrefix("/files/", FileHandler(manager))
+	for path, status := range map[string]int{
+		"/files/" + manager.EncodeKey("docs/a.txt"): http.StatusOK,
+		"/files/docs/a.txt":                         http.StatusNotFound,
+	} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != status {
+			t.Fatalf("GET %s: expected %d, got %d", path, status, rec.Code)
+		}
+	}
+}
+
+func TestNotModifiedETag(t *testing.T) {
+	info := &uploader.ObjectInfo{ETag: "abc123"}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("If-None-Match", `"other", W/"abc123"`)
+	if !notModified(req, info) {
+		t.Fatalf("expected weak match on entity tag")
+	}
+
+	req.Header.Set("If-None-Match", `"other"`)
+	req.Header.Set("If-Modified-Since", time.Now().UTC().Format(http.TimeFormat))
+	if notModified(req, info) {
+		t.Fatalf("expected If-None-Match to take precedence over If-Modified-Since")
+	}
+}
@@ -0,0 +1,69 @@
+package uploaderhttp
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/goliatone/go-uploader"
+)
+
+// ProgressKeepAlive is how often ProgressHandler writes an SSE comment while no update arrives,
+// so proxies do not close idle streams.
+const ProgressKeepAlive = 15 * time.Second
+
+// ProgressHandler streams broker updates as Server-Sent Events. The upload is selected with the
+// "id" query parameter (a chunk session ID or a TrackReader ID); without it every upload is
+// streamed. Each update is sent as a "progress" event whose data is the JSON encoded
+// uploader.Progress. A stream for a single upload ends after its final update.
+func ProgressHandler(broker *uploader.ProgressBroker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !allowReadMethods(w, r) {
+			return
+		}
+
+		id := r.URL.Query().Get("id")
+		updates, unsubscribe := broker.Subscribe(id)
+		defer unsubscribe()
+
+		h := w.Header()
+		h.Set("Content-Type", "text/event-stream")
+		h.Set("Cache-Control", "no-cache")
+		h.Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodHead {
+			return
+		}
+
+		controller := http.NewResponseController(w)
+		_ = controller.Flush()
+
+		keepAlive := time.NewTicker(ProgressKeepAlive)
+		defer keepAlive.Stop()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-keepAlive.C:
+				if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+					return
+				}
+			case progress := <-updates:
+				data, err := json.Marshal(progress)
+				if err != nil {
+					return
+				}
+				if _, err := fmt.Fprintf(w, "event: progress\ndata: %s\n\n", data); err != nil {
+					return
+				}
+				if id != "" && progress.State.Done() {
+					_ = controller.Flush()
+					return
+				}
+			}
+			_ = controller.Flush()
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package uploaderhttp
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/goliatone/go-uploader"
+)
+
+func TestProgressHandlerStreamsUntilDone(t *testing.T) {
+	broker := uploader.NewProgressBroker()
+	broker.Publish(uploader.Progress{ID: "s1", Key: "a.bin", State: uploader.ProgressUploading, Bytes: 4, Total: 8})
+
+	server := httptest.NewServer(ProgressHandler(broker))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "?id=s1")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+
+	events := bufio.NewScanner(resp.Body)
+	next := func() uploader.Progress {
+		t.Helper()
+		for events.Scan() {
+			data, ok := strings.CutPrefix(events.Text(), "data: ")
+			if !ok {
+				continue
+			}
+			var progress uploader.Progress
+			if err := json.Unmarshal([]byte(data), &progress); err != nil {
+				t.Fatalf("decode event: %v", err)
+			}
+			return progress
+		}
+		t.Fatalf("stream ended: %v", events.Err())
+		return uploader.Progress{}
+	}
+
+	if current := next(); current.Bytes != 4 || current.State != uploader.ProgressUploading {
+		t.Fatalf("expected current state first, got %#v", current)
+	}
+
+	broker.Publish(uploader.Progress{ID: "other", State: uploader.ProgressUploading})
+	broker.Publish(uploader.Progress{ID: "s1", Key: "a.bin", State: uploader.ProgressCompleted, Bytes: 8, Total: 8})
+
+	if done := next(); done.ID != "s1" || done.State != uploader.ProgressCompleted {
+		t.Fatalf("unexpected final event: %#v", done)
+	}
+	for events.Scan() {
+		if events.Text() != "" {
+			t.Fatalf("expected stream to end after the final update, got %q", events.Text())
+		}
+	}
+}
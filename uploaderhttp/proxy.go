@@ -0,0 +1,193 @@
+package uploaderhttp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	gerrors "github.com/goliatone/go-errors"
+	"github.com/goliatone/go-uploader"
+)
+
+// proxyChunkSize bounds how much of the provider stream a request holds in memory; each chunk is
+// written and flushed before the next one is read, so a slow client slows the provider read.
+const proxyChunkSize = 32 * 1024
+
+var errRangeNotSatisfiable = gerrors.New("requested range not satisfiable", gerrors.CategoryBadInput).
+	WithCode(http.StatusRequestedRangeNotSatisfiable).
+	WithTextCode("RANGE_NOT_SATISFIABLE")
+
+// ProxyFile streams the object stored under key from the provider to the client without
+// buffering it whole, unlike ServeFile. Headers and conditional requests are handled as in
+// ServeFile; a single byte range is answered with 206 Partial Content, honoring If-Range, and
+// multi-range requests are served in full. The provider read is tied to the request context,
+// so it is cancelled as soon as the client disconnects.
+func ProxyFile(w http.ResponseWriter, r *http.Request, manager *uploader.Manager, key string) {
+	info, err := manager.StatFile(r.Context(), key)
+	if err != nil {
+		WriteError(w, err)
+		return
+	}
+
+	h := w.Header()
+	setFileHeaders(h, info)
+	h.Set("Accept-Ranges", "bytes")
+	if notModified(r, info) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	offset, length, status := int64(0), info.Size, http.StatusOK
+	if spec := r.Header.Get("Range"); spec != "" && ifRangeMatches(r, info) {
+		start, n, ranged, ok := parseRange(spec, info.Size)
+		if !ok {
+			h.Set("Content-Range", fmt.Sprintf("bytes */%d", info.Size))
+			WriteError(w, errRangeNotSatisfiable)
+			return
+		}
+		if ranged {
+			offset, length, status = start, n, http.StatusPartialContent
+			h.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, start+n-1, info.Size))
+		}
+	}
+
+	if r.Method == http.MethodHead {
+		h.Set("Content-Length", strconv.FormatInt(length, 10))
+		w.WriteHeader(status)
+		return
+	}
+
+	body, err := manager.OpenRange(r.Context(), key, offset, length)
+	if err != nil {
+		h.Del("Content-Range")
+		WriteError(w, err)
+		return
+	}
+	defer body.Close()
+
+	h.Set("Content-Length", strconv.FormatInt(length, 10))
+	w.WriteHeader(status)
+
+	// Once the status is sent a failure can only be signalled by cutting the response short,
+	// which the declared Content-Length lets the client detect.
+	_ = copyChunks(r.Context(), w, body)
+}
+
+// ProxyHandler serves GET and HEAD requests with ProxyFile, using the request path without its
+// leading slash as the key. Mount it under http.StripPrefix to serve a key prefix. With a
+// KeyCodec the path is an external key, as for FileHandler.
+func ProxyHandler(manager *uploader.Manager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !allowReadMethods(w, r) {
+			return
+		}
+
+		key, err := requestKey(r, manager)
+		if err != nil {
+			WriteError(w, err)
+			return
+		}
+		ProxyFile(w, r, manager, key)
+	})
+}
+
+// copyChunks copies src to w one chunk at a time, flushing after each write and stopping as soon
+// as ctx is done or a write fails.
+func copyChunks(ctx context.Context, w http.ResponseWriter, src io.Reader) error {
+	controller := http.NewResponseController(w)
+
+	buf := uploader.DefaultBufferPool.Get()
+	defer uploader.DefaultBufferPool.Put(buf)
+	buf.Grow(proxyChunkSize)
+	chunk := buf.AvailableBuffer()[:proxyChunkSize]
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		n, err := src.Read(chunk)
+		if n > 0 {
+			if _, werr := w.Write(chunk[:n]); werr != nil {
+				return werr
+			}
+			if ferr := controller.Flush(); ferr != nil && ferr != http.ErrNotSupported {
+				return ferr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// parseRange parses a single "bytes=" range against size. Other units and multi-range requests
+// report ranged false so the whole object is served, which RFC 9110 allows; malformed or
+// unsatisfiable ranges report ok false.
+func parseRange(spec string, size int64) (start, length int64, ranged, ok bool) {
+	spec, found := strings.CutPrefix(spec, "bytes=")
+	if !found || strings.Contains(spec, ",") {
+		return 0, size, false, true
+	}
+
+	first, last, found := strings.Cut(strings.TrimSpace(spec), "-")
+	if !found {
+		return 0, 0, false, false
+	}
+
+	if first == "" {
+		suffix, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || suffix <= 0 || size == 0 {
+			return 0, 0, false, false
+		}
+		if suffix > size {
+			suffix = size
+		}
+		return size - suffix, suffix, true, true
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 || start >= size {
+		return 0, 0, false, false
+	}
+
+	end := size - 1
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < start {
+			return 0, 0, false, false
+		}
+		if end >= size {
+			end = size - 1
+		}
+	}
+
+	return start, end - start + 1, true, true
+}
+
+// ifRangeMatches reports whether a Range request may be served partially: If-Range must be
+// absent, strongly match the ETag, or equal the modification time.
+func ifRangeMatches(r *http.Request, info *uploader.ObjectInfo) bool {
+	condition := r.Header.Get("If-Range")
+	if condition == "" {
+		return true
+	}
+
+	if strings.HasPrefix(condition, "\"") || strings.HasPrefix(condition, "W/") {
+		etag := quoteETag(info.ETag)
+		return info.ETag != "" && !strings.HasPrefix(condition, "W/") && !strings.HasPrefix(etag, "W/") && condition == etag
+	}
+
+	since, err := http.ParseTime(condition)
+	if err != nil || info.ModTime.IsZero() {
+		return false
+	}
+	return info.ModTime.Truncate(time.Second).Equal(since)
+}
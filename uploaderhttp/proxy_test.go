@@ -0,0 +1,110 @@
+package uploaderhttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/goliatone/go-uploader"
+)
+
+func TestProxyHandlerRanges(t *testing.T) {
+	manager := uploader.NewManager(uploader.WithProvider(uploader.NewFSProvider(t.TempDir())))
+	if _, err := manager.UploadFile(context.Background(), "docs/a.txt", []byte("hello world")); err != nil {
+		t.Fatalf("UploadFile failed: %v", err)
+	}
+
+	handler := http.StripPrefix("/files/", ProxyHandler(manager))
+	serve := func(method, rangeHeader, ifRange string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/files/docs/a.txt", nil)
+		if rangeHeader != "" {
+			req.Header.Set("Range", rangeHeader)
+		}
+		if ifRange != "" {
+			req.Header.Set("If-Range", ifRange)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := serve(http.MethodGet, "", "")
+	if rec.Code != http.StatusOK || rec.Body.String() != "hello world" || rec.Header().Get("Accept-Ranges") != "bytes" {
+		t.Fatalf("unexpected full response: %d %q", rec.Code, rec.Body.String())
+	}
+	etag := rec.Header().Get("ETag")
+
+	tests := []struct {
+		rangeHeader, ifRange string
+		status               int
+		body, contentRange   string
+	}{
+		{"bytes=0-4", "", http.StatusPartialContent, "hello", "bytes 0-4/11"},
+		{"bytes=6-", "", http.StatusPartialContent, "world", "bytes 6-10/11"},
+		{"bytes=-3", "", http.StatusPartialContent, "rld", "bytes 8-10/11"},
+		{"bytes=6-100", etag, http.StatusPartialContent, "world", "bytes 6-10/11"},
+		{"bytes=0-4", `"stale"`, http.StatusOK, "hello world", ""},
+		{"bytes=0-1,4-5", "", http.StatusOK, "hello world", ""},
+		{"bytes=20-", "", http.StatusRequestedRangeNotSatisfiable, "", "bytes */11"},
+	}
+	for _, tt := range tests {
+		rec := serve(http.MethodGet, tt.rangeHeader, tt.ifRange)
+		if rec.Code != tt.status || rec.Header().Get("Content-Range") != tt.contentRange {
+			t.Fatalf("Range %q: got %d %q", tt.rangeHeader, rec.Code, rec.Header().Get("Content-Range"))
+		}
+		if tt.body != "" && rec.Body.String() != tt.body {
+			t.Fatalf("Range %q: body %q, want %q", tt.rangeHeader, rec.Body.String(), tt.body)
+		}
+	}
+
+	rec = serve(http.MethodHead, "bytes=0-4", "")
+	if rec.Code != http.StatusPartialContent || rec.Body.Len() != 0 || rec.Header().Get("Content-Length") != "5" {
+		t.Fatalf("unexpected HEAD response: %d %q", rec.Code, rec.Header().Get("Content-Length"))
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/files/docs/a.txt", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified {
+		t.Fatalf("expected 304, got %d", rec.Code)
+	}
+}
+
+func TestProxyFileStopsWhenClientGoesAway(t *testing.T) {
+	manager := uploader.NewManager(uploader.WithProvider(uploader.NewFSProvider(t.TempDir())))
+	if _, err := manager.UploadFile(context.Background(), "a.txt", []byte("hello")); err != nil {
+		t.Fatalf("UploadFile failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	rec := httptest.NewRecorder()
+	ProxyFile(rec, httptest.NewRequest(http.MethodGet, "/a.txt", nil).WithContext(ctx), manager, "a.txt")
+	if rec.Body.Len() != 0 {
+		t.Fatalf("expected no body after the client went away, got %q", rec.Body.String())
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		spec          string
+		start, length int64
+		ranged, ok    bool
+	}{
+		{"bytes=0-0", 0, 1, true, true},
+		{"bytes=-20", 0, 10, true, true},
+		{"items=0-1", 0, 10, false, true},
+		{"bytes=5-2", 0, 0, false, false},
+		{"bytes=abc", 0, 0, false, false},
+		{"bytes=-0", 0, 0, false, false},
+	}
+	for _, tt := range tests {
+		start, length, ranged, ok := parseRange(tt.spec, 10)
+		if start != tt.start || length != tt.length || ranged != tt.ranged || ok != tt.ok {
+			t.Errorf("parseRange(%q) = %d, %d, %v, %v", tt.spec, start, length, ranged, ok)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package uploader
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Usage is the number of objects and bytes stored under a prefix.
+type Usage struct {
+	Prefix     string    `json:"prefix"`
+	Objects    int64     `json:"objects"`
+	Bytes      int64     `json:"bytes"`
+	ComputedAt time.Time `json:"computed_at"`
+	// Cached reports whether the figures came from the usage cache.
+	Cached bool `json:"cached"`
+}
+
+// UsageReporter is implemented by providers or metadata stores that keep per-prefix totals, so
+// Usage does not have to list every object.
+type UsageReporter interface {
+	Usage(ctx context.Context, prefix string) (*Usage, error)
+}
+
+// WithUsageSource answers Usage from source, e.g. the application's metadata store, instead of
+// the provider.
+func WithUsageSource(source UsageReporter) Option {
+	return func(m *Manager) {
+		m.usageSource = source
+	}
+}
+
+// WithUsageCache keeps Usage results for ttl. Uploads and deletes made through the manager drop
+// cached entries whose prefix covers the key; writes made elsewhere show up once ttl expires. A
+// ttl <= 0 disables caching.
+func WithUsageCache(ttl time.Duration) Option {
+	return func(m *Manager) {
+		if ttl <= 0 {
+			m.usageCache = nil
+			return
+		}
+		m.usageCache = &usageCache{ttl: ttl, entries: make(map[string]Usage)}
+	}
+}
+
+// Usage aggregates the objects stored under prefix. Totals come from the WithUsageSource store,
+// then from a provider implementing UsageReporter, and otherwise from listing the prefix, which
+// walks every object and returns ErrNotImplemented when the provider is not a Lister.
+func (m *Manager) Usage(ctx context.Context, prefix string) (*Usage, error) {
+	if cached, ok := m.usageCache.get(prefix, m.now()); ok {
+		return cached, nil
+	}
+
+	usage, err := m.computeUsage(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	usage.Prefix = prefix
+	if usage.ComputedAt.IsZero() {
+		usage.ComputedAt = m.now()
+	}
+	m.usageCache.put(*usage)
+	return usage, nil
+}
+
+func (m *Manager) computeUsage(ctx context.Context, prefix string) (*Usage, error) {
+	if m.usageSource != nil {
+		return m.usageSource.Usage(ctx, prefix)
+	}
+
+	if err := m.ensureProvider(ctx); err != nil {
+		return nil, err
+	}
+
+	if reporter, ok := m.currentProvider().(UsageReporter); ok {
+		return observeCall(ctx, m, "usage", prefix, func() (*Usage, error) {
+			return reporter.Usage(ctx, prefix)
+		})
+	}
+
+	objects, err := m.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &Usage{}
+	for _, obj := range objects {
+		usage.Objects++
+		usage.Bytes += obj.Size
+	}
+	return usage, nil
+}
+
+// usageCache holds Usage results per prefix. A nil cache is valid and never hits.
+type usageCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]Usage
+}
+
+func (c *usageCache) get(prefix string, now time.Time) (*Usage, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[prefix]
+	if !ok {
+		return nil, false
+	}
+
+	if now.Sub(entry.ComputedAt) >= c.ttl {
+		delete(c.entries, prefix)
+		return nil, false
+	}
+
+	entry.Cached = true
+	return &entry, true
+}
+
+func (c *usageCache) put(usage Usage) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	c.entries[usage.Prefix] = usage
+	c.mu.Unlock()
+}
+
+// forget drops every cached prefix that covers key.
+func (c *usageCache) forget(key string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	for prefix := range c.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.entries, prefix)
+		}
+	}
+	c.mu.Unlock()
+}
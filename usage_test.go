@@ -0,0 +1,80 @@
+package uploader
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestManagerUsage(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	manager := NewManager(
+		WithProvider(NewFSProvider(dir)),
+		WithClock(func() time.Time { return now }),
+		WithUsageCache(time.Minute),
+	)
+
+	for key, content := range map[string]string{"docs/a.txt": "hello", "docs/b.txt": "hi", "other/c.txt": "x"} {
+		if _, err := manager.UploadFile(ctx, key, []byte(content)); err != nil {
+			t.Fatalf("UploadFile %s failed: %v", key, err)
+		}
+	}
+
+	usage, err := manager.Usage(ctx, "docs/")
+	if err != nil {
+		t.Fatalf("Usage failed: %v", err)
+	}
+	if usage.Objects != 2 || usage.Bytes != 7 || usage.Cached || usage.Prefix != "docs/" {
+		t.Fatalf("unexpected usage: %#v", usage)
+	}
+
+	// Files written behind the manager's back are only seen once the cache expires.
+	if err := os.WriteFile(filepath.Join(dir, "docs", "d.txt"), []byte("abc"), 0o644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+	if usage, _ = manager.Usage(ctx, "docs/"); !usage.Cached || usage.Objects != 2 {
+		t.Fatalf("expected cached usage, got %#v", usage)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if usage, _ = manager.Usage(ctx, "docs/"); usage.Cached || usage.Objects != 3 || usage.Bytes != 10 {
+		t.Fatalf("expected refreshed usage after ttl, got %#v", usage)
+	}
+
+	if _, err := manager.UploadFile(ctx, "docs/e.txt", []byte("four")); err != nil {
+		t.Fatalf("UploadFile failed: %v", err)
+	}
+	if usage, _ = manager.Usage(ctx, "docs/"); usage.Cached || usage.Objects != 4 || usage.Bytes != 14 {
+		t.Fatalf("expected upload to invalidate cached usage, got %#v", usage)
+	}
+}
+
+type staticUsageSource struct {
+	calls int
+}
+
+func (s *staticUsageSource) Usage(ctx context.Context, prefix string) (*Usage, error) {
+	s.calls++
+	return &Usage{Objects: 42, Bytes: 1024}, nil
+}
+
+func TestManagerUsageSource(t *testing.T) {
+	source := &staticUsageSource{}
+	manager := NewManager(WithProvider(&mockUploader{}), WithUsageSource(source))
+
+	usage, err := manager.Usage(context.Background(), "tenant/")
+	if err != nil {
+		t.Fatalf("Usage failed: %v", err)
+	}
+	if usage.Objects != 42 || usage.Bytes != 1024 || usage.Prefix != "tenant/" || source.calls != 1 {
+		t.Fatalf("unexpected usage from source: %#v", usage)
+	}
+
+	if _, err := NewManager(WithProvider(&mockUploader{})).Usage(context.Background(), ""); err != ErrNotImplemented {
+		t.Fatalf("expected ErrNotImplemented without lister, got %v", err)
+	}
+}
@@ -1,12 +1,17 @@
 package uploader
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"mime/multipart"
+	"net/http"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
 	gerrors "github.com/goliatone/go-errors"
 )
@@ -45,10 +50,31 @@ func getAllowedMsg(options map[string]bool) string {
 	return strings.Join(out, ",")
 }
 
+func sortedAllowed(options map[string]bool) []string {
+	out := make([]string, 0, len(options))
+	for k, v := range options {
+		if v {
+			out = append(out, k)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
 type Validator struct {
 	maxFileSize         int64
+	minFileSize         int64
+	maxImageWidth       int
+	maxImageHeight      int
+	aggregateErrors     bool
+	messages            MessageCatalog
 	allowedMimeTypes    map[string]bool
 	allowedImageFormats map[string]bool
+	checkExtensionMime  bool
+	maxKeyLength        int
+	maxFilenameLength   int
+	disallowedUnicode   []*unicode.RangeTable
+	reservedNames       map[string]bool
 }
 
 type ValidatorOption func(*Validator)
@@ -59,6 +85,14 @@ func WithUploadMaxFileSize(size int64) ValidatorOption {
 	}
 }
 
+// WithMinFileSize rejects files smaller than size bytes. Empty files are rejected by default; pass
+// 0 to accept them.
+func WithMinFileSize(size int64) ValidatorOption {
+	return func(uv *Validator) {
+		uv.minFileSize = size
+	}
+}
+
 func WithAllowedMimeTypes(types map[string]bool) ValidatorOption {
 	return func(uv *Validator) {
 		uv.allowedMimeTypes = types
@@ -74,8 +108,13 @@ func WithAllowedImageFormats(formats map[string]bool) ValidatorOption {
 func NewValidator(opts ...ValidatorOption) *Validator {
 	u := &Validator{
 		maxFileSize:         DefaultMaxFileSize,
+		minFileSize:         DefaultMinFileSize,
 		allowedMimeTypes:    AllowedImageMimeTypes,
 		allowedImageFormats: AllowedImageFormats,
+		maxKeyLength:        DefaultMaxKeyLength,
+		maxFilenameLength:   DefaultMaxFilenameLength,
+		disallowedUnicode:   DefaultDisallowedUnicode,
+		reservedNames:       reservedNameSet(DefaultReservedNames),
 	}
 
 	for _, opt := range opts {
@@ -86,82 +125,136 @@ func NewValidator(opts ...ValidatorOption) *Validator {
 }
 
 func (u *Validator) ValidateFile(file *multipart.FileHeader) error {
+	return u.validateFile(file, file.Header.Get("Content-Type"))
+}
 
-	if file.Size > u.maxFileSize {
-		return gerrors.NewValidation("file validation failed",
-			gerrors.FieldError{
-				Field:   "file_size",
-				Message: fmt.Sprintf("file too large, max: %d bytes", u.maxFileSize),
-				Value:   file.Size,
-			},
-		).WithCode(400).WithTextCode("FILE_TOO_LARGE").
-			WithMetadata(map[string]any{
-				"filename":     file.Filename,
-				"file_size":    file.Size,
-				"max_size":     u.maxFileSize,
-				"content_type": file.Header.Get("Content-Type"),
-			})
-	}
-
+// validateFile checks file as if its content type were contentType, which HandleFile resolves
+// from the header, the extension or the content.
+func (u *Validator) validateFile(file *multipart.FileHeader, contentType string) error {
 	ext := strings.ToLower(filepath.Ext(file.Filename))
-	if !u.allowedImageFormats[ext] {
-		return gerrors.NewValidation("file validation failed",
-			gerrors.FieldError{
-				Field:   "file_format",
-				Message: fmt.Sprintf("invalid format, allowed: %s", getAllowedMsg(u.allowedImageFormats)),
-				Value:   ext,
-			},
-		).WithCode(400).WithTextCode("INVALID_FILE_FORMAT").
-			WithMetadata(map[string]any{
-				"filename":        file.Filename,
-				"file_extension":  ext,
-				"allowed_formats": getAllowedMsg(u.allowedImageFormats),
-			})
-	}
 
-	if !u.allowedMimeTypes[file.Header.Get("Content-Type")] {
-		return gerrors.NewValidation("file validation failed",
-			gerrors.FieldError{
-				Field:   "content_type",
-				Message: fmt.Sprintf("invalid mime type, allowed: %s", getAllowedMsg(u.allowedMimeTypes)),
-				Value:   file.Header.Get("Content-Type"),
-			},
-		).WithCode(400).WithTextCode("INVALID_MIME_TYPE").
-			WithMetadata(map[string]any{
-				"filename":      file.Filename,
-				"content_type":  file.Header.Get("Content-Type"),
-				"allowed_types": getAllowedMsg(u.allowedMimeTypes),
+	return u.check(
+		func() error {
+			if file.Size <= u.maxFileSize {
+				return nil
+			}
+			return u.fieldError("file_size", "FILE_TOO_LARGE", file.Size, map[string]any{"max_size": u.maxFileSize}).
+				WithMetadata(map[string]any{
+					"filename":     file.Filename,
+					"file_size":    file.Size,
+					"max_size":     u.maxFileSize,
+					"content_type": contentType,
+				})
+		},
+		func() error {
+			return u.validateMinSize(file.Size, file.Filename)
+		},
+		func() error {
+			return u.validateName("filename", file.Filename, file.Filename)
+		},
+		func() error {
+			if u.allowedImageFormats[ext] {
+				return nil
+			}
+			return u.fieldError("file_format", "INVALID_FILE_FORMAT", ext, map[string]any{"allowed": getAllowedMsg(u.allowedImageFormats)}).
+				WithMetadata(map[string]any{
+					"filename":        file.Filename,
+					"file_extension":  ext,
+					"allowed_formats": getAllowedMsg(u.allowedImageFormats),
+				})
+		},
+		func() error {
+			if u.allowedMimeTypes[contentType] {
+				return nil
+			}
+			return u.fieldError("content_type", "INVALID_MIME_TYPE", contentType, map[string]any{"allowed": getAllowedMsg(u.allowedMimeTypes)}).
+				WithMetadata(map[string]any{
+					"filename":      file.Filename,
+					"content_type":  contentType,
+					"allowed_types": getAllowedMsg(u.allowedMimeTypes),
+				})
+		},
+		func() error {
+			if !u.checkExtensionMime || extensionMatchesMime(ext, contentType) {
+				return nil
+			}
+			return u.fieldError("content_type", "MIME_EXTENSION_MISMATCH", contentType, map[string]any{
+				"extension": ext,
+				"expected":  extensionMimeType(ext),
+			}).WithMetadata(map[string]any{
+				"filename":       file.Filename,
+				"file_extension": ext,
+				"content_type":   contentType,
 			})
-	}
-
-	return nil
+		},
+	)
 }
 
+// ValidateFileContent checks content's size, signature and image dimensions. Content must start
+// with the signature of a known image or PDF format, unless it sniffs as an allowed type with no
+// known signature, such as text/plain.
 func (u *Validator) ValidateFileContent(content []byte) error {
-	if len(content) > int(u.maxFileSize) {
-		return gerrors.NewValidation("file validation failed",
-			gerrors.FieldError{
-				Field:   "file_size",
-				Message: fmt.Sprintf("file too large, max: %d bytes", u.maxFileSize),
-				Value:   len(content),
-			},
-		).WithCode(400).WithTextCode("FILE_TOO_LARGE")
+	contentType := mediaType(http.DetectContentType(content))
+	return u.validateContent(contentType, int64(len(content)), content, bytes.NewReader(content))
+}
+
+// validateContent checks a file of size bytes sent as contentType without holding it in memory:
+// head holds its first bytes for the signature check and r reads the whole file for the
+// dimension check. Allowed types without a known signature pass the signature check only when
+// head sniffs as a compatible type, so a client cannot store binary or HTML content by declaring
+// it as, say, image/svg+xml.
+func (u *Validator) validateContent(contentType string, size int64, head []byte, r io.Reader) error {
+	return u.check(
+		func() error {
+			return u.validateMinSize(size, "")
+		},
+		func() error {
+			if size <= u.maxFileSize {
+				return nil
+			}
+			return u.fieldError("file_size", "FILE_TOO_LARGE", size, map[string]any{"max_size": u.maxFileSize})
+		},
+		func() error {
+			if isValidFileContent(head) || (u.allowedMimeTypes[contentType] && !signedMimeTypes[contentType] && sniffedAs(head, contentType)) {
+				return nil
+			}
+			return u.fieldError("file_content", "INVALID_FILE_CONTENT", "binary_data", nil)
+		},
+		func() error {
+			return u.validateDimensions(r)
+		},
+	)
+}
+
+// validateMinSize rejects empty files with EMPTY_FILE and files under the minimum size with
+// FILE_TOO_SMALL.
+func (u *Validator) validateMinSize(size int64, filename string) error {
+	if u.minFileSize <= 0 || size >= u.minFileSize {
+		return nil
 	}
 
-	if !isValidFileContent(content) {
-		return gerrors.NewValidation("file validation failed",
-			gerrors.FieldError{
-				Field:   "file_content",
-				Message: "invalid file content",
-				Value:   "binary_data",
-			},
-		).WithCode(400).WithTextCode("INVALID_FILE_CONTENT")
+	textCode := "FILE_TOO_SMALL"
+	if size == 0 {
+		textCode = "EMPTY_FILE"
 	}
 
-	return nil
+	err := u.fieldError("file_size", textCode, size, map[string]any{"min_size": u.minFileSize})
+	if filename != "" {
+		err = err.WithMetadata(map[string]any{
+			"filename":  filename,
+			"file_size": size,
+			"min_size":  u.minFileSize,
+		})
+	}
+	return err
 }
 
 func (u *Validator) RandomName(file *multipart.FileHeader, paths ...string) (string, error) {
+	return timestampName(file, time.Now(), paths...)
+}
+
+// timestampName names the file after now in microseconds, keeping its extension.
+func timestampName(file *multipart.FileHeader, now time.Time, paths ...string) (string, error) {
 	ext := filepath.Ext(file.Filename)
 	if ext == "" {
 		return "", gerrors.NewValidation("file validation failed",
@@ -173,7 +266,7 @@ func (u *Validator) RandomName(file *multipart.FileHeader, paths ...string) (str
 		).WithCode(400).WithTextCode("FILE_EXTENSION_NOT_FOUND")
 	}
 
-	randomName := strconv.FormatInt(time.Now().UnixMicro(), 10)
+	randomName := strconv.FormatInt(now.UnixMicro(), 10)
 	imageName := randomName + ext
 	if len(paths) > 0 && paths[0] != "" {
 		return paths[0] + "/" + imageName, nil
@@ -193,7 +286,15 @@ func (u *Validator) MaxFileSize() int64 {
 	return u.maxFileSize
 }
 
+func (u *Validator) AllowedMimeTypes() []string {
+	return sortedAllowed(u.allowedMimeTypes)
+}
+
 func ValidateFile(file *multipart.FileHeader) error {
+	if err := (&Validator{minFileSize: DefaultMinFileSize}).validateMinSize(file.Size, file.Filename); err != nil {
+		return err
+	}
+
 	max := DefaultMaxFileSize
 	if file.Size > max {
 		return gerrors.NewValidation("file validation failed",
@@ -260,6 +361,55 @@ var magicNumbers = map[string][]byte{
 	"png":  {0x89, 0x50, 0x4E, 0x47},
 	"jpeg": {0xFF, 0xD8, 0xFF},
 	"webp": {0x52, 0x49, 0x46, 0x46},
+	// TIFF files start with a little- or big-endian byte order mark.
+	"tiff_le": {0x49, 0x49, 0x2A, 0x00},
+	"tiff_be": {0x4D, 0x4D, 0x00, 0x2A},
+	"pdf":     {0x25, 0x50, 0x44, 0x46, 0x2D},
+}
+
+// signedMimeTypes are the types isValidFileContent recognizes. Content sent as one of them must
+// carry its signature; other allowed types, such as text, have none to check.
+var signedMimeTypes = map[string]bool{
+	"image/bmp":       true,
+	"image/gif":       true,
+	"image/png":       true,
+	"image/jpeg":      true,
+	"image/webp":      true,
+	"image/tiff":      true,
+	"image/heic":      true,
+	"image/heif":      true,
+	"application/pdf": true,
+}
+
+// sniffedAs reports whether head, sniffed with http.DetectContentType, is compatible with
+// contentType. Text sniffs as text/plain or text/xml whatever its format, so it agrees with any
+// textual type of the same family; HTML only agrees with text/html, and binary content only with
+// the exact type it sniffs as.
+func sniffedAs(head []byte, contentType string) bool {
+	sniffed := mediaType(http.DetectContentType(head))
+	switch sniffed {
+	case contentType:
+		return true
+	case "text/plain":
+		return isTextualType(contentType)
+	case "text/xml":
+		return isXMLType(contentType)
+	}
+	return false
+}
+
+func isTextualType(contentType string) bool {
+	if contentType == "text/html" {
+		return false
+	}
+	return strings.HasPrefix(contentType, "text/") ||
+		contentType == "application/json" ||
+		strings.HasSuffix(contentType, "+json") ||
+		isXMLType(contentType)
+}
+
+func isXMLType(contentType string) bool {
+	return contentType == "text/xml" || contentType == "application/xml" || strings.HasSuffix(contentType, "+xml")
 }
 
 func isValidFileContent(content []byte) bool {
@@ -273,7 +423,8 @@ func isValidFileContent(content []byte) bool {
 			return true
 		}
 	}
-	return false
+	// HEIC carries its signature after the box size, so it cannot be matched as a prefix.
+	return IsHEIC(content)
 }
 
 func compareBytes(a, b []byte) bool {
@@ -2,6 +2,8 @@ package uploader
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"mime/multipart"
 	"net/textproto"
 	"strings"
@@ -604,3 +606,270 @@ func TestValidatorOptions(t *testing.T) {
 		}
 	})
 }
+
+func TestAllowMimeGroups(t *testing.T) {
+	validator := NewValidator(AllowMimeGroups("image/*", "Application/PDF"))
+
+	tests := []struct {
+		filename    string
+		contentType string
+		textCode    string
+	}{
+		{"photo.jpg", "image/jpeg", ""},
+		{"photo.jpeg", "image/jpeg", ""},
+		{"scan.tif", "image/tiff", ""},
+		{"scan.tiff", "image/tiff", ""},
+		{"doc.pdf", "application/pdf", ""},
+		{"photo.png", "image/jpeg", "MIME_EXTENSION_MISMATCH"},
+		{"notes.txt", "text/plain", "INVALID_FILE_FORMAT"},
+		{"doc.pdf", "text/plain", "INVALID_MIME_TYPE"},
+	}
+
+	for _, tt := range tests {
+		err := validator.ValidateFile(createTestFileHeader(tt.filename, tt.contentType, 10, []byte("x")))
+		if tt.textCode == "" {
+			if err != nil {
+				t.Errorf("%s as %s: unexpected error %v", tt.filename, tt.contentType, err)
+			}
+			continue
+		}
+
+		var validationErr *gerrors.Error
+		if !errors.As(err, &validationErr) || validationErr.TextCode != tt.textCode {
+			t.Errorf("%s as %s: expected %s, got %v", tt.filename, tt.contentType, tt.textCode, err)
+		}
+	}
+
+	if !validator.IsAllowedMimeType("image/webp") || validator.IsAllowedMimeType("image/pdf") {
+		t.Errorf("unexpected allowed types: %v", validator.AllowedMimeTypes())
+	}
+}
+
+func TestHandleFileAllowMimeGroupsWithoutSignatures(t *testing.T) {
+	ctx := context.Background()
+	manager := NewManager(
+		WithProvider(newMemoryProvider()),
+		WithValidator(NewValidator(AllowMimeGroups("text/*", "application/json", "image/png"))),
+	)
+
+	for _, tt := range []struct {
+		filename    string
+		contentType string
+		content     []byte
+	}{
+		{"notes.txt", "text/plain", []byte("meeting notes")},
+		{"rows.csv", "text/csv", []byte("a,b\n1,2\n")},
+		{"data.json", "application/json", []byte(`{"ok":true}`)},
+	} {
+		meta, err := manager.HandleFile(ctx, newTestFileHeader(t, "file", tt.filename, tt.contentType, tt.content), "docs")
+		if err != nil {
+			t.Fatalf("%s: HandleFile: %v", tt.filename, err)
+		}
+		if meta.ContentType != tt.contentType {
+			t.Fatalf("%s: expected content type %s, got %s", tt.filename, tt.contentType, meta.ContentType)
+		}
+	}
+
+	_, err := manager.HandleFile(ctx, newTestFileHeader(t, "file", "photo.png", "image/png", []byte("not a png")), "docs")
+	assertTextCode(t, err, "INVALID_FILE_CONTENT")
+}
+
+func TestUnsignedTypesMustSniffAsDeclared(t *testing.T) {
+	ctx := context.Background()
+	svg := []byte(`<svg xmlns="http://www.w3.org/2000/svg" width="1" height="1"/>`)
+	forged := map[string][]byte{
+		"binary": []byte("MZ\x90\x00\x03\x00\x00\x00<script>alert(1)</script>"),
+		"html":   []byte("<html><script>alert(1)</script></html>"),
+	}
+
+	for name, manager := range map[string]*Manager{
+		"in memory": NewManager(WithProvider(NewFSProvider(t.TempDir()))),
+		"spooled":   NewManager(WithProvider(NewFSProvider(t.TempDir())), WithSpoolThreshold(1)),
+	} {
+		if _, err := manager.HandleFile(ctx, newTestFileHeader(t, "file", "logo.svg", "image/svg+xml", svg), "docs"); err != nil {
+			t.Fatalf("%s: expected a real svg to be accepted: %v", name, err)
+		}
+		for kind, content := range forged {
+			_, err := manager.HandleFile(ctx, newTestFileHeader(t, "file", "evil.svg", "image/svg+xml", content), "docs")
+			if err == nil {
+				t.Fatalf("%s: expected %s content declared as svg to be rejected", name, kind)
+			}
+			assertTextCode(t, err, "INVALID_FILE_CONTENT")
+		}
+	}
+
+	manager := NewManager(WithProvider(NewFSProvider(t.TempDir())))
+	archive := buildZip(t, []archiveEntry{
+		{name: "logo.svg", data: svg},
+		{name: "binary.svg", data: forged["binary"]},
+		{name: "page.svg", data: forged["html"]},
+	})
+	if _, err := manager.UploadFile(ctx, "batch.zip", archive); err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	result, err := manager.ExtractStored(ctx, "batch.zip", "out", ExtractOptions{SkipInvalid: true})
+	if err != nil {
+		t.Fatalf("ExtractStored: %v", err)
+	}
+	if len(result.Keys) != 1 || result.Keys[0] != "out/logo.svg" || len(result.Skipped) != 2 {
+		t.Fatalf("expected only the real svg to be extracted, got %v, skipped %v", result.Keys, result.Skipped)
+	}
+}
+
+func TestValidatorValidateKey(t *testing.T) {
+	validator := NewValidator(WithMaxKeyLength(64), WithMaxFilenameLength(16))
+
+	tests := []struct {
+		key      string
+		textCode string
+	}{
+		{"uploads/2024/photo.png", ""},
+		{"uploads/" + strings.Repeat("a", 60), "KEY_TOO_LONG"},
+		{"uploads/" + strings.Repeat("a", 17), "FILENAME_TOO_LONG"},
+		{"uploads/photo\u202egnp.exe", "INVALID_CHARACTER"},
+		{"uploads/line\nbreak.png", "INVALID_CHARACTER"},
+		{"uploads/con.png", "RESERVED_FILENAME"},
+		{"LPT1/photo.png", "RESERVED_FILENAME"},
+		{"uploads/console.png", ""},
+	}
+
+	for _, tt := range tests {
+		err := validator.ValidateKey(tt.key)
+		if tt.textCode == "" {
+			if err != nil {
+				t.Errorf("%q: unexpected error %v", tt.key, err)
+			}
+			continue
+		}
+
+		var validationErr *gerrors.Error
+		if !errors.As(err, &validationErr) || validationErr.TextCode != tt.textCode {
+			t.Errorf("%q: expected %s, got %v", tt.key, tt.textCode, err)
+		}
+	}
+
+	relaxed := NewValidator(WithReservedNames(), WithDisallowedUnicode())
+	if err := relaxed.ValidateKey("uploads/con\u202e.png"); err != nil {
+		t.Errorf("expected relaxed validator to accept key, got %v", err)
+	}
+
+	manager := NewManager(WithProvider(&mockUploader{}))
+	if _, err := manager.UploadFile(context.Background(), "uploads/nul.txt", []byte("x")); !gerrors.IsValidation(err) {
+		t.Errorf("expected UploadFile to reject reserved key, got %v", err)
+	}
+}
+
+func TestValidatorMinFileSize(t *testing.T) {
+	png := []byte{0x89, 0x50, 0x4E, 0x47, 0x0D, 0x0A, 0x1A, 0x0A}
+
+	textCode := func(err error) string {
+		var validationErr *gerrors.Error
+		if errors.As(err, &validationErr) {
+			return validationErr.TextCode
+		}
+		return ""
+	}
+
+	validator := NewValidator()
+	if code := textCode(validator.ValidateFile(createTestFileHeader("empty.png", "image/png", 0, nil))); code != "EMPTY_FILE" {
+		t.Errorf("expected EMPTY_FILE, got %q", code)
+	}
+	if code := textCode(validator.ValidateFileContent(nil)); code != "EMPTY_FILE" {
+		t.Errorf("expected EMPTY_FILE for content, got %q", code)
+	}
+	if code := textCode(ValidateFile(createTestFileHeader("empty.png", "image/png", 0, nil))); code != "EMPTY_FILE" {
+		t.Errorf("expected package ValidateFile to reject empty files, got %q", code)
+	}
+
+	strict := NewValidator(WithMinFileSize(16))
+	if code := textCode(strict.ValidateFile(createTestFileHeader("small.png", "image/png", 8, png))); code != "FILE_TOO_SMALL" {
+		t.Errorf("expected FILE_TOO_SMALL, got %q", code)
+	}
+
+	lenient := NewValidator(WithMinFileSize(0))
+	if err := lenient.ValidateFile(createTestFileHeader("empty.png", "image/png", 0, nil)); err != nil {
+		t.Errorf("expected empty file to be accepted, got %v", err)
+	}
+}
+
+func TestValidatorAggregatedErrors(t *testing.T) {
+	validator := NewValidator(WithUploadMaxFileSize(4), WithAggregatedErrors(true))
+
+	err := validator.ValidateFile(createTestFileHeader("notes.txt", "text/plain", 10, []byte("0123456789")))
+	var validationErr *gerrors.Error
+	if !errors.As(err, &validationErr) || validationErr.TextCode != "VALIDATION_FAILED" {
+		t.Fatalf("expected aggregated validation error, got %v", err)
+	}
+
+	fields := map[string]bool{}
+	for _, field := range validationErr.ValidationErrors {
+		fields[field.Field] = true
+	}
+	for _, field := range []string{"file_size", "file_format", "content_type"} {
+		if !fields[field] {
+			t.Errorf("expected %s in %v", field, validationErr.ValidationErrors)
+		}
+	}
+
+	err = validator.ValidateFile(createTestFileHeader("photo.png", "image/png", 10, nil))
+	if !errors.As(err, &validationErr) || validationErr.TextCode != "FILE_TOO_LARGE" {
+		t.Fatalf("expected a single failure to keep its code, got %v", err)
+	}
+
+	firstOnly := NewValidator(WithUploadMaxFileSize(4))
+	err = firstOnly.ValidateFile(createTestFileHeader("notes.txt", "text/plain", 10, nil))
+	if !errors.As(err, &validationErr) || len(validationErr.ValidationErrors) != 1 {
+		t.Fatalf("expected first failure only, got %v", err)
+	}
+}
+
+func TestValidatorMaxImageDimensions(t *testing.T) {
+	validator := NewValidator(WithMaxImageDimensions(8, 0))
+
+	if err := validator.ValidateFileContent(createTestPNG(8, 100)); err != nil {
+		t.Fatalf("expected image within bounds to pass, got %v", err)
+	}
+
+	var validationErr *gerrors.Error
+	err := validator.ValidateFileContent(createTestPNG(9, 4))
+	if !errors.As(err, &validationErr) || validationErr.TextCode != "IMAGE_TOO_LARGE" {
+		t.Fatalf("expected IMAGE_TOO_LARGE, got %v", err)
+	}
+}
+
+func TestValidatorMessageCatalog(t *testing.T) {
+	file := createTestFileHeader("photo.png", "image/png", 10, nil)
+
+	err := NewValidator(WithUploadMaxFileSize(4)).ValidateFile(file)
+	messages := ValidationMessages(err)
+	if len(messages) != 1 {
+		t.Fatalf("expected one message, got %v", messages)
+	}
+	if messages[0].Key != "uploader.validation.file_too_large" || messages[0].Params["max_size"] != int64(4) {
+		t.Fatalf("unexpected message: %#v", messages[0])
+	}
+	if messages[0].Message != "file too large, max: 4 bytes" || !strings.Contains(err.Error(), "file validation failed") {
+		t.Fatalf("expected default English message, got %q", messages[0].Message)
+	}
+
+	spanish := MessageCatalogFunc(func(key string, params map[string]any) (string, bool) {
+		if key == "uploader.validation.file_too_large" {
+			return "archivo demasiado grande", true
+		}
+		return "", false
+	})
+	err = NewValidator(WithUploadMaxFileSize(4), WithMessageCatalog(spanish), WithAggregatedErrors(true)).
+		ValidateFile(createTestFileHeader("notes.txt", "image/png", 10, nil))
+
+	messages = ValidationMessages(err)
+	if len(messages) != 2 || messages[0].Message != "archivo demasiado grande" {
+		t.Fatalf("expected translated and fallback messages, got %#v", messages)
+	}
+	if messages[1].Key != "uploader.validation.invalid_file_format" || !strings.HasPrefix(messages[1].Message, "invalid format") {
+		t.Fatalf("expected fallback for unknown key, got %#v", messages[1])
+	}
+
+	if ValidationMessages(errors.New("boom")) != nil {
+		t.Fatalf("expected no messages for plain errors")
+	}
+}
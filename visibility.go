@@ -0,0 +1,161 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"time"
+)
+
+// Visibility is a storage agnostic access level for stored objects. Providers translate it: S3
+// canned ACLs, file permissions on disk, and which URL Manager.URLFor hands out.
+type Visibility string
+
+const (
+	// VisibilityPrivate objects are only readable by the owner and through presigned URLs.
+	VisibilityPrivate Visibility = "private"
+	// VisibilityPublicRead objects are readable by anyone holding their URL.
+	VisibilityPublicRead Visibility = "public-read"
+	// VisibilityAuthenticated objects are readable by authenticated principals (any AWS account on
+	// S3, the file's group on disk).
+	VisibilityAuthenticated Visibility = "authenticated"
+)
+
+// ParseVisibility accepts a Visibility value, plus "public" for VisibilityPublicRead and
+// "authenticated-read" for VisibilityAuthenticated.
+func ParseVisibility(value string) (Visibility, error) {
+	switch v := Visibility(strings.ToLower(strings.TrimSpace(value))); v {
+	case VisibilityPrivate, VisibilityPublicRead, VisibilityAuthenticated:
+		return v, nil
+	case "public":
+		return VisibilityPublicRead, nil
+	case "authenticated-read":
+		return VisibilityAuthenticated, nil
+	default:
+		return "", fmt.Errorf("unknown visibility %q", value)
+	}
+}
+
+// WithVisibility sets the access level of the stored object. Without it providers keep their
+// default: private on S3, world readable files on disk.
+func WithVisibility(v Visibility) UploadOption {
+	return func(m *Metadata) {
+		m.Visibility = v
+		m.Public = v == VisibilityPublicRead
+	}
+}
+
+// WithDefaultVisibility sets the visibility of uploads, chunked sessions and presigned posts that
+// do not choose one with WithVisibility or ContextWithVisibility.
+func WithDefaultVisibility(v Visibility) Option {
+	return func(m *Manager) {
+		m.visibility = v
+	}
+}
+
+type visibilityContextKey struct{}
+
+// ContextWithVisibility returns a copy of ctx that stores uploads with visibility v, including
+// the objects HandleFile names itself. WithVisibility on a call still takes precedence.
+func ContextWithVisibility(ctx context.Context, v Visibility) context.Context {
+	return context.WithValue(ctx, visibilityContextKey{}, v)
+}
+
+// resolvedVisibility returns the visibility md asks for, honouring the deprecated Public flag.
+// It is empty when the provider default applies.
+func (md *Metadata) resolvedVisibility() Visibility {
+	if md == nil {
+		return ""
+	}
+	if md.Visibility != "" {
+		return md.Visibility
+	}
+	if md.Public {
+		return VisibilityPublicRead
+	}
+	return ""
+}
+
+// visibilityFor returns the visibility for an upload that did not set one: the context value,
+// then the manager default.
+func (m *Manager) visibilityFor(ctx context.Context) Visibility {
+	if v, ok := ctx.Value(visibilityContextKey{}).(Visibility); ok && v != "" {
+		return v
+	}
+	return m.visibility
+}
+
+// applyVisibility resolves the visibility of md, falling back to visibilityFor when the caller
+// did not set one, and rejects values no provider knows how to translate.
+func (m *Manager) applyVisibility(ctx context.Context, md *Metadata) error {
+	v := md.resolvedVisibility()
+	if v == "" {
+		v = m.visibilityFor(ctx)
+	}
+	if v == "" {
+		return nil
+	}
+
+	switch v {
+	case VisibilityPrivate, VisibilityPublicRead, VisibilityAuthenticated:
+		WithVisibility(v)(md)
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown visibility %q", ErrInvalidVisibility, v)
+	}
+}
+
+// visibilityOptions applies the visibility to md and appends it to opts for the provider.
+func (m *Manager) visibilityOptions(ctx context.Context, md *Metadata, opts []UploadOption) ([]UploadOption, error) {
+	if err := m.applyVisibility(ctx, md); err != nil {
+		return nil, err
+	}
+	if md.Visibility != "" {
+		opts = append(opts, WithVisibility(md.Visibility))
+	}
+	return opts, nil
+}
+
+// PublicURLBuilder is implemented by providers that can address objects without signing, such as
+// a static file server or a CDN in front of a bucket.
+type PublicURLBuilder interface {
+	PublicURL(path string) (string, error)
+}
+
+// URLFor returns a URL for path suited to its visibility: the unsigned public URL for
+// VisibilityPublicRead objects when the provider can build one, and a presigned URL valid for
+// expires otherwise.
+func (m *Manager) URLFor(ctx context.Context, path string, v Visibility, expires time.Duration) (string, error) {
+	if v != VisibilityPublicRead {
+		return m.GetPresignedURL(ctx, path, expires)
+	}
+
+	if err := m.ensureProvider(ctx); err != nil {
+		return "", err
+	}
+
+	builder, ok := m.providerFor(ctx, path).(PublicURLBuilder)
+	if !ok {
+		return m.GetPresignedURL(ctx, path, expires)
+	}
+
+	url, err := builder.PublicURL(path)
+	if errors.Is(err, ErrNotImplemented) {
+		return m.GetPresignedURL(ctx, path, expires)
+	}
+	return url, err
+}
+
+// fileMode translates v into the permissions of a stored file.
+func fileMode(v Visibility) fs.FileMode {
+	switch v {
+	case VisibilityPrivate:
+		return 0o600
+	case VisibilityAuthenticated:
+		return 0o640
+	default:
+		return 0o644
+	}
+}
@@ -0,0 +1,129 @@
+package uploader
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+func TestParseVisibility(t *testing.T) {
+	for input, want := range map[string]Visibility{
+		"private":            VisibilityPrivate,
+		"Public":             VisibilityPublicRead,
+		"public-read":        VisibilityPublicRead,
+		"authenticated":      VisibilityAuthenticated,
+		"authenticated-read": VisibilityAuthenticated,
+	} {
+		if got, err := ParseVisibility(input); err != nil || got != want {
+			t.Fatalf("ParseVisibility(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	if _, err := ParseVisibility("world"); err == nil {
+		t.Fatalf("expected unknown visibility rejected")
+	}
+}
+
+func TestManagerVisibilityFSPermissions(t *testing.T) {
+	ctx := context.Background()
+	base := t.TempDir()
+	manager := NewManager(
+		WithProvider(NewFSProvider(base, WithFSURLPrefix("/files"))),
+		WithDefaultVisibility(VisibilityAuthenticated),
+	)
+
+	assertMode := func(want fs.FileMode) {
+		t.Helper()
+		info, err := os.Stat(filepath.Join(base, "docs", "a.txt"))
+		if err != nil {
+			t.Fatalf("Stat: %v", err)
+		}
+		if got := info.Mode().Perm(); got != want {
+			t.Fatalf("expected mode %v, got %v", want, got)
+		}
+	}
+
+	if _, err := manager.UploadFile(ctx, "docs/a.txt", []byte("a")); err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	assertMode(0o640)
+
+	if _, err := manager.UploadFile(ctx, "docs/a.txt", []byte("a"), WithVisibility(VisibilityPrivate)); err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	assertMode(0o600)
+
+	if _, err := manager.UploadFile(ContextWithVisibility(ctx, VisibilityPublicRead), "docs/a.txt", []byte("a")); err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	assertMode(0o644)
+
+	url, err := manager.URLFor(ctx, "docs/a.txt", VisibilityPublicRead, 0)
+	if err != nil || url != "/files/docs/a.txt" {
+		t.Fatalf("expected public URL, got %q (%v)", url, err)
+	}
+
+	_, err = manager.UploadFile(ctx, "docs/b.txt", []byte("b"), WithVisibility("world"))
+	if !errors.Is(err, ErrInvalidVisibility) {
+		t.Fatalf("expected ErrInvalidVisibility, got %v", err)
+	}
+}
+
+func TestAWSProviderVisibilityACL(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeS3Client{
+		createMultipartOutput: &s3.CreateMultipartUploadOutput{UploadId: aws.String("upload-1")},
+	}
+	provider := &AWSProvider{client: client, bucket: "bucket", logger: &DefaultLogger{}}
+
+	if _, err := provider.UploadFile(ctx, "a.txt", []byte("a")); err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	if _, err := provider.UploadFile(ctx, "b.txt", []byte("b"), WithVisibility(VisibilityAuthenticated)); err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	if _, err := provider.UploadFile(ctx, "c.txt", []byte("c"), WithPublicAccess(true)); err != nil {
+		t.Fatalf("UploadFile: %v", err)
+	}
+	for i, want := range []types.ObjectCannedACL{
+		types.ObjectCannedACLPrivate,
+		types.ObjectCannedACLAuthenticatedRead,
+		types.ObjectCannedACLPublicRead,
+	} {
+		if got := client.putInputs[i].ACL; got != want {
+			t.Fatalf("put %d: expected ACL %q, got %q", i, want, got)
+		}
+	}
+
+	session := &ChunkSession{ID: "s1", Key: "big.bin", Metadata: &Metadata{Visibility: VisibilityPublicRead}}
+	if _, err := provider.InitiateChunked(ctx, session); err != nil {
+		t.Fatalf("InitiateChunked: %v", err)
+	}
+	if got := client.createMultipartInputs[0].ACL; got != types.ObjectCannedACLPublicRead {
+		t.Fatalf("expected public-read multipart upload, got %q", got)
+	}
+}
+
+func TestAWSProviderPublicURL(t *testing.T) {
+	provider := &AWSProvider{bucket: "bucket", basePath: "media", logger: &DefaultLogger{}}
+	if _, err := provider.PublicURL("a b.png"); !errors.Is(err, ErrNotImplemented) {
+		t.Fatalf("expected ErrNotImplemented without a base URL, got %v", err)
+	}
+
+	provider.apply(WithAWSPublicBaseURL("https://cdn.example.com"))
+	url, err := provider.PublicURL("a b.png")
+	if err != nil || url != "https://cdn.example.com/media/a%20b.png" {
+		t.Fatalf("unexpected public URL %q (%v)", url, err)
+	}
+
+	provider.apply(WithAWSPublicBaseURL("cdn.example.com"))
+	if provider.optionErr == nil {
+		t.Fatalf("expected base URL without scheme rejected")
+	}
+}